@@ -3,6 +3,8 @@ package main
 import (
 	"flag"
 	"log"
+	"os"
+	"path/filepath"
 
 	"github.com/BurntSushi/toml"
 
@@ -19,7 +21,8 @@ func init() {
 	flag.StringVar(&configPath, "config-path", "configs/apiserver.toml", "path to config file")
 }
 
-// main func. Starts server with config settings
+// main func. Starts server with config settings. The migrate command
+// migrates the schema of the DB instead, e.g. apiserver migrate up.
 func main() {
 	// Parsing flags
 	flag.Parse()
@@ -30,6 +33,24 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	// Looking for achievements next to the config file by default
+	if config.AchievementsPath == "" {
+		config.AchievementsPath = filepath.Join(filepath.Dir(configPath), "achievements.toml")
+	}
+	// Looking for the text filter config next to the config file too
+	if config.TextFilterPath == "" {
+		config.TextFilterPath = filepath.Join(filepath.Dir(configPath), "textfilter.toml")
+	}
+	// Running the command if there's one
+	if args := flag.Args(); len(args) > 0 {
+		if args[0] != "migrate" {
+			log.Fatalf("unknown command %q", args[0])
+		}
+		if err := apiserver.Migrate(config, args[1:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	// Starting server with our config
 	if err := apiserver.Start(config); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,117 @@
+// Package achievement implements achievements engine. Achievements are
+// defined declaratively in a TOML file and evaluated over user events.
+package achievement
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/BurntSushi/toml"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var (
+	codeFormat        = regexp.MustCompile("^[a-z0-9_]+$")
+	errDuplicateCode  = errors.New("duplicate achievement code")
+	errOneCriterion   = errors.New("achievement needs either event with count or member_days")
+	errMemberDaysOnly = errors.New("member_days achievements can't have event, game_id or count")
+)
+
+// Definition object. Describes an achievement and its criterion: either
+// Count events of the type (of the game, if GameID isn't zero), or
+// being a member for MemberDays days.
+type Definition struct {
+	Code        string `toml:"code" json:"code"`
+	Name        string `toml:"name" json:"name"`
+	Description string `toml:"description" json:"description"`
+	Event       string `toml:"event" json:"-"`
+	GameID      int    `toml:"game_id" json:"game_id,omitempty"`
+	Count       int    `toml:"count" json:"-"`
+	MemberDays  int    `toml:"member_days" json:"-"`
+}
+
+// Validate func. Validating definition for code, name and criterion
+func (d *Definition) Validate() error {
+	return validation.ValidateStruct(
+		d,
+		validation.Field(&d.Code, validation.Required, validation.Match(codeFormat)),
+		validation.Field(&d.Name, validation.Required),
+		validation.Field(&d.Event, validation.In(
+			model.EventLogin,
+			model.EventMatchPlayed,
+			model.EventMatchWon,
+			model.EventReviewWritten,
+			model.EventTournamentWon,
+		)),
+		validation.Field(&d.GameID, validation.Min(0)),
+		validation.Field(&d.Count, validation.Min(0)),
+		validation.Field(&d.MemberDays, validation.Min(0), validation.By(d.oneCriterion)),
+	)
+}
+
+// Target func. Returns the progress needed to unlock the achievement
+func (d *Definition) Target() int {
+	if d.MemberDays > 0 {
+		return d.MemberDays
+	}
+
+	return d.Count
+}
+
+// Fingerprint func. Returns a string that changes whenever the criterion
+// of the achievement changes, so the progress has to be re-evaluated
+func (d *Definition) Fingerprint() string {
+	return fmt.Sprintf("event=%s;game_id=%d;count=%d;member_days=%d", d.Event, d.GameID, d.Count, d.MemberDays)
+}
+
+// matches func. Checks that the event counts for the achievement
+func (d *Definition) matches(e *model.UserEvent) bool {
+	return d.Event == e.Type && (d.GameID == 0 || d.GameID == e.GameID)
+}
+
+// oneCriterion func. Validation rule that checks that the definition
+// has exactly one criterion
+func (d *Definition) oneCriterion(interface{}) error {
+	if d.MemberDays > 0 {
+		if d.Event != "" || d.GameID != 0 || d.Count != 0 {
+			return errMemberDaysOnly
+		}
+		return nil
+	}
+
+	if d.Event == "" || d.Count < 1 {
+		return errOneCriterion
+	}
+
+	return nil
+}
+
+// LoadFile func. Decodes achievement definitions from the TOML file
+// and validates them. A missing file means there are no achievements.
+func LoadFile(path string) ([]*Definition, error) {
+	file := struct {
+		Achievements []*Definition `toml:"achievement"`
+	}{}
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	codes := map[string]bool{}
+	for _, d := range file.Achievements {
+		if err := d.Validate(); err != nil {
+			return nil, fmt.Errorf("achievement %q: %w", d.Code, err)
+		}
+		if codes[d.Code] {
+			return nil, fmt.Errorf("achievement %q: %w", d.Code, errDuplicateCode)
+		}
+		codes[d.Code] = true
+	}
+
+	return file.Achievements, nil
+}
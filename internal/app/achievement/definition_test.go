@@ -0,0 +1,130 @@
+package achievement_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/achievement"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefinition_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		d       *achievement.Definition
+		isValid bool
+	}{
+		{
+			name:    "event",
+			d:       &achievement.Definition{Code: "critic", Name: "Critic", Event: model.EventReviewWritten, Count: 5},
+			isValid: true,
+		},
+		{
+			name:    "event of game",
+			d:       &achievement.Definition{Code: "chess_master", Name: "Chess master", Event: model.EventMatchWon, GameID: 1, Count: 10},
+			isValid: true,
+		},
+		{
+			name:    "membership",
+			d:       &achievement.Definition{Code: "one_year", Name: "Old-timer", MemberDays: 365},
+			isValid: true,
+		},
+		{
+			name:    "invalid code",
+			d:       &achievement.Definition{Code: "Critic!", Name: "Critic", Event: model.EventReviewWritten, Count: 5},
+			isValid: false,
+		},
+		{
+			name:    "unknown event",
+			d:       &achievement.Definition{Code: "critic", Name: "Critic", Event: "review_read", Count: 5},
+			isValid: false,
+		},
+		{
+			name:    "event without count",
+			d:       &achievement.Definition{Code: "critic", Name: "Critic", Event: model.EventReviewWritten},
+			isValid: false,
+		},
+		{
+			name:    "no criterion",
+			d:       &achievement.Definition{Code: "critic", Name: "Critic"},
+			isValid: false,
+		},
+		{
+			name:    "two criteria",
+			d:       &achievement.Definition{Code: "critic", Name: "Critic", Event: model.EventReviewWritten, Count: 5, MemberDays: 365},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.d.Validate())
+			} else {
+				assert.Error(t, tc.d.Validate())
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "achievements")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	write := func(content string) string {
+		path := filepath.Join(dir, "achievements.toml")
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	definitions, err := achievement.LoadFile(filepath.Join(dir, "missing.toml"))
+	assert.NoError(t, err)
+	assert.Empty(t, definitions)
+
+	definitions, err = achievement.LoadFile(write(`
+[[achievement]]
+code = "critic"
+name = "Critic"
+event = "review_written"
+count = 5
+
+[[achievement]]
+code = "one_year"
+name = "Old-timer"
+member_days = 365
+`))
+	assert.NoError(t, err)
+	assert.Len(t, definitions, 2)
+	assert.Equal(t, 5, definitions[0].Target())
+	assert.Equal(t, 365, definitions[1].Target())
+
+	_, err = achievement.LoadFile(write(`
+[[achievement]]
+code = "critic"
+name = "Critic"
+event = "review_written"
+count = 5
+
+[[achievement]]
+code = "critic"
+name = "Critic"
+event = "review_written"
+count = 10
+`))
+	assert.Error(t, err)
+
+	_, err = achievement.LoadFile(write(`
+[[achievement]]
+code = "critic"
+`))
+	assert.Error(t, err)
+}
+
+func TestLoadFile_Configs(t *testing.T) {
+	definitions, err := achievement.LoadFile("../../../configs/achievements.toml")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, definitions)
+}
@@ -0,0 +1,148 @@
+package achievement
+
+import (
+	"context"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// day is the length of a membership day
+const day = 24 * time.Hour
+
+// Engine object. Records user events and evaluates achievement
+// definitions over them.
+type Engine struct {
+	definitions []*Definition
+	store       store.Store
+}
+
+// NewEngine func. Constructor for Engine
+func NewEngine(definitions []*Definition, store store.Store) *Engine {
+	return &Engine{
+		definitions: definitions,
+		store:       store,
+	}
+}
+
+// Definitions func. Returns all achievement definitions
+func (e *Engine) Definitions() []*Definition {
+	return e.definitions
+}
+
+// Definition func. Returns the definition with the code, or nil
+func (e *Engine) Definition(code string) *Definition {
+	for _, d := range e.definitions {
+		if d.Code == code {
+			return d
+		}
+	}
+
+	return nil
+}
+
+// Record func. Saves the event and evaluates achievements it counts for
+// and membership achievements of the user. Returns achievements the
+// event unlocked.
+func (e *Engine) Record(ctx context.Context, ev *model.UserEvent) ([]*Definition, error) {
+	if err := e.store.Achievement().CreateEvent(ctx, ev); err != nil {
+		return nil, err
+	}
+
+	progress, err := e.store.Achievement().FindProgress(ctx, ev.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	unlocked := map[string]bool{}
+	for _, p := range progress {
+		unlocked[p.Code] = p.Unlocked()
+	}
+
+	var u *model.User
+	result := []*Definition{}
+	for _, d := range e.definitions {
+		if unlocked[d.Code] {
+			continue
+		}
+
+		var value int
+		switch {
+		case d.MemberDays > 0:
+			if u == nil {
+				if u, err = e.store.User().Find(ctx, ev.UserID); err != nil {
+					return nil, err
+				}
+			}
+			value = int(ev.CreatedAt.Sub(u.CreatedAt) / day)
+		case d.matches(ev):
+			if value, err = e.store.Achievement().CountEvents(ctx, ev.UserID, d.Event, d.GameID); err != nil {
+				return nil, err
+			}
+		default:
+			continue
+		}
+
+		p := &model.AchievementProgress{
+			UserID:   ev.UserID,
+			Code:     d.Code,
+			Progress: value,
+			Target:   d.Target(),
+		}
+		if err := e.store.Achievement().SaveProgress(ctx, p); err != nil {
+			return nil, err
+		}
+		if p.Unlocked() {
+			result = append(result, d)
+		}
+	}
+
+	return result, nil
+}
+
+// Sync func. Re-evaluates achievements that are new or whose criteria
+// changed since the last evaluation from the whole history of events.
+// Membership achievements are unlocked for users registered long enough
+// ago, others get their progress with their next event.
+func (e *Engine) Sync(ctx context.Context, now time.Time) error {
+	for _, d := range e.definitions {
+		fingerprint, err := e.store.Achievement().Evaluated(ctx, d.Code)
+		if err != nil {
+			return err
+		}
+		if fingerprint == d.Fingerprint() {
+			continue
+		}
+
+		counts := map[int]int{}
+		if d.MemberDays > 0 {
+			ids, err := e.store.User().FindRegisteredBefore(ctx, now.Add(-time.Duration(d.MemberDays)*day))
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				counts[id] = d.MemberDays
+			}
+		} else if counts, err = e.store.Achievement().CountEventsByUser(ctx, d.Event, d.GameID); err != nil {
+			return err
+		}
+
+		for userID, value := range counts {
+			if err := e.store.Achievement().SaveProgress(ctx, &model.AchievementProgress{
+				UserID:   userID,
+				Code:     d.Code,
+				Progress: value,
+				Target:   d.Target(),
+			}); err != nil {
+				return err
+			}
+		}
+
+		if err := e.store.Achievement().SetEvaluated(ctx, d.Code, d.Fingerprint()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
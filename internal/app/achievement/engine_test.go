@@ -0,0 +1,91 @@
+package achievement_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/achievement"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine_Record(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	u.CreatedAt = time.Now().Add(-400 * 24 * time.Hour)
+	s.User().Create(context.Background(), u)
+
+	e := achievement.NewEngine([]*achievement.Definition{
+		{Code: "first_win", Name: "First blood", Event: model.EventMatchWon, Count: 1},
+		{Code: "chess_wins", Name: "Chess player", Event: model.EventMatchWon, GameID: 1, Count: 2},
+		{Code: "one_year", Name: "Old-timer", MemberDays: 365},
+	}, s)
+
+	unlocked, err := e.Record(context.Background(), &model.UserEvent{UserID: u.ID, Type: model.EventMatchWon, GameID: 2})
+	assert.NoError(t, err)
+	assert.Len(t, unlocked, 2)
+	assert.Equal(t, "first_win", unlocked[0].Code)
+	assert.Equal(t, "one_year", unlocked[1].Code)
+
+	unlocked, err = e.Record(context.Background(), &model.UserEvent{UserID: u.ID, Type: model.EventMatchWon, GameID: 1})
+	assert.NoError(t, err)
+	assert.Empty(t, unlocked)
+
+	unlocked, err = e.Record(context.Background(), &model.UserEvent{UserID: u.ID, Type: model.EventMatchWon, GameID: 1})
+	assert.NoError(t, err)
+	assert.Len(t, unlocked, 1)
+	assert.Equal(t, "chess_wins", unlocked[0].Code)
+
+	progress, err := s.Achievement().FindProgress(context.Background(), u.ID)
+	assert.NoError(t, err)
+	assert.Len(t, progress, 3)
+	for _, p := range progress {
+		assert.True(t, p.Unlocked())
+		assert.Equal(t, p.Target, p.Progress)
+	}
+
+	_, err = e.Record(context.Background(), &model.UserEvent{UserID: u.ID + 1, Type: model.EventLogin})
+	assert.Error(t, err)
+}
+
+func TestEngine_Sync(t *testing.T) {
+	s := teststore.New()
+	u1 := model.TestUser(t)
+	u1.CreatedAt = time.Now().Add(-400 * 24 * time.Hour)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+
+	// History recorded before the achievements were added
+	old := achievement.NewEngine(nil, s)
+	for i := 0; i < 3; i++ {
+		old.Record(context.Background(), &model.UserEvent{UserID: u2.ID, Type: model.EventReviewWritten, GameID: 1})
+	}
+	old.Record(context.Background(), &model.UserEvent{UserID: u1.ID, Type: model.EventReviewWritten, GameID: 1})
+
+	critic := &achievement.Definition{Code: "critic", Name: "Critic", Event: model.EventReviewWritten, Count: 3}
+	e := achievement.NewEngine([]*achievement.Definition{
+		critic,
+		{Code: "one_year", Name: "Old-timer", MemberDays: 365},
+	}, s)
+	assert.NoError(t, e.Sync(context.Background(), time.Now()))
+
+	progress, _ := s.Achievement().FindProgress(context.Background(), u1.ID)
+	assert.Len(t, progress, 2)
+	assert.Equal(t, 1, progress[0].Progress)
+	assert.False(t, progress[0].Unlocked())
+	assert.True(t, progress[1].Unlocked())
+
+	progress, _ = s.Achievement().FindProgress(context.Background(), u2.ID)
+	assert.Len(t, progress, 1)
+	assert.True(t, progress[0].Unlocked())
+
+	// Changed criterion is re-evaluated, unchanged ones aren't
+	critic.Count = 1
+	assert.NoError(t, e.Sync(context.Background(), time.Now()))
+	progress, _ = s.Achievement().FindProgress(context.Background(), u1.ID)
+	assert.True(t, progress[0].Unlocked())
+}
@@ -0,0 +1,143 @@
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/logging"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/sirupsen/logrus"
+)
+
+// achievementView object. Describes the achievement with user's progress
+type achievementView struct {
+	Code        string     `json:"code"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Progress    int        `json:"progress"`
+	Target      int        `json:"target"`
+	UnlockedAt  *time.Time `json:"unlocked_at,omitempty"`
+}
+
+// handleUsersProfile func. Handler func that returns public profile of
+// the user with unlocked achievements
+func (s *server) handleUsersProfile() http.HandlerFunc {
+	type response struct {
+		ID           int                `json:"id"`
+		CreatedAt    time.Time          `json:"created_at"`
+		Achievements []*achievementView `json:"achievements"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Checking that the user exists and isn't hidden from the viewer
+		u, err := s.store.User().Find(r.Context(), id)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		if hidden, err := s.isHidden(r, s.sessionUserID(r), id); err != nil || hidden {
+			s.hiddenError(w, r, err)
+			return
+		}
+
+		views, err := s.achievementViews(r, id, true)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, &response{
+			ID:           u.ID,
+			CreatedAt:    u.CreatedAt,
+			Achievements: views,
+		})
+	}
+}
+
+// handleAchievementsProgress func. Handler func that returns all
+// achievements with progress of the actual user
+func (s *server) handleAchievementsProgress() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		views, err := s.achievementViews(r, u.ID, false)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, views)
+	}
+}
+
+// achievementViews func. Returns achievements in definition order with
+// progress of the user. Achievements removed from definitions aren't shown.
+func (s *server) achievementViews(r *http.Request, userID int, unlockedOnly bool) ([]*achievementView, error) {
+	progress, err := s.store.Achievement().FindProgress(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byCode := map[string]*model.AchievementProgress{}
+	for _, p := range progress {
+		byCode[p.Code] = p
+	}
+
+	views := []*achievementView{}
+	for _, d := range s.achievements.Definitions() {
+		v := &achievementView{
+			Code:        d.Code,
+			Name:        d.Name,
+			Description: d.Description,
+			Target:      d.Target(),
+		}
+		if p, ok := byCode[d.Code]; ok {
+			v.Progress = p.Progress
+			v.UnlockedAt = p.UnlockedAt
+		}
+		if unlockedOnly && v.UnlockedAt == nil {
+			continue
+		}
+		views = append(views, v)
+	}
+
+	return views, nil
+}
+
+// recordEvent func. Records the user event for achievements. Failing to
+// record an event doesn't fail the request, the error is only logged.
+func (s *server) recordEvent(r *http.Request, e *model.UserEvent) {
+	unlocked, err := s.achievements.Record(r.Context(), e)
+	if err != nil {
+		logging.FromContext(r.Context()).WithFields(logrus.Fields{
+			"user_id": e.UserID,
+			"event":   e.Type,
+		}).Errorf("recording event: %v", err)
+		return
+	}
+
+	for _, d := range unlocked {
+		logging.FromContext(r.Context()).WithFields(logrus.Fields{
+			"user_id": e.UserID,
+		}).Infof("achievement %s unlocked", d.Code)
+		s.notify(r, &model.Notification{
+			UserID: e.UserID,
+			Type:   model.NotificationAchievement,
+			Text:   fmt.Sprintf("Achievement unlocked: %s", d.Name),
+			Link:   "/private/me/achievements",
+		})
+		s.recordActivity(r, &model.Activity{
+			UserID: e.UserID,
+			Type:   model.ActivityAchievementUnlocked,
+			GameID: d.GameID,
+			Text:   "unlocked achievement " + d.Name,
+			Link:   fmt.Sprintf("/users/%d", e.UserID),
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/sessions"
+
+	"github.com/GShamian/tavern-of-games/internal/app/achievement"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleAchievements(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	store.User().Create(context.Background(), u)
+	blocked := model.TestUser(t)
+	blocked.Email = "user2@example.org"
+	store.User().Create(context.Background(), blocked)
+	store.Friendship().Block(context.Background(), u.ID, blocked.ID)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	s.achievements = achievement.NewEngine([]*achievement.Definition{
+		{Code: "first_review", Name: "First review", Event: model.EventReviewWritten, Count: 1},
+		{Code: "critic", Name: "Critic", Event: model.EventReviewWritten, Count: 5},
+	}, store)
+
+	b := &bytes.Buffer{}
+	json.NewEncoder(b).Encode(map[string]interface{}{"score": 9})
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/private/games/%d/review", g.ID), b)
+	req.Header.Set("Cookie", testCookie(t, secretKey, u.ID))
+	s.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	testCases := []struct {
+		name         string
+		viewer       *model.User
+		path         string
+		expectedCode int
+		expectedLen  int
+	}{
+		{
+			name:         "profile",
+			path:         fmt.Sprintf("/users/%d", u.ID),
+			expectedCode: http.StatusOK,
+			expectedLen:  1,
+		},
+		{
+			name:         "profile for blocked viewer",
+			viewer:       blocked,
+			path:         fmt.Sprintf("/users/%d", u.ID),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "unknown profile",
+			path:         fmt.Sprintf("/users/%d", blocked.ID+1),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "progress",
+			viewer:       u,
+			path:         "/private/me/achievements",
+			expectedCode: http.StatusOK,
+			expectedLen:  2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.viewer != nil {
+				req.Header.Set("Cookie", testCookie(t, secretKey, tc.viewer.ID))
+			}
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+
+			body := struct {
+				Achievements []*achievementView `json:"achievements"`
+			}{}
+			if tc.viewer != nil {
+				json.NewDecoder(rec.Body).Decode(&body.Achievements)
+			} else {
+				json.NewDecoder(rec.Body).Decode(&body)
+			}
+			assert.Len(t, body.Achievements, tc.expectedLen)
+			assert.Equal(t, "first_review", body.Achievements[0].Code)
+			assert.NotNil(t, body.Achievements[0].UnlockedAt)
+		})
+	}
+}
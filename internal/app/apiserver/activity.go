@@ -0,0 +1,271 @@
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/atom"
+	"github.com/GShamian/tavern-of-games/internal/app/logging"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/sirupsen/logrus"
+)
+
+// Timelines of feeds are merged from activities of friends on read and
+// cached. New activities of friends show up once the timeline expires.
+const (
+	timelineSize = 200
+	timelineTTL  = time.Minute
+)
+
+// handleFeed func. Handler func that returns a page of activities of
+// friends of the actual user, newest first. Older pages are requested
+// with before query parameter set to the cursor returned with the page,
+// there are no more pages if it's missing. Library activities are shown
+// if the library of the friend is visible to the user.
+func (s *server) handleFeed() http.HandlerFunc {
+	type response struct {
+		Activities []*model.Activity `json:"activities"`
+		Before     int               `json:"before,omitempty"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Parsing cursor and page size
+		q, err := parseListQuery(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		before, err := parseCursor(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Paging through the cached timeline first
+		timeline, err := s.timeline(r, u.ID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		page := []*model.Activity{}
+		for _, a := range timeline {
+			if len(page) == q.Limit {
+				break
+			}
+			if before == 0 || a.ID < before {
+				page = append(page, a)
+			}
+		}
+		// Pages older than the timeline are merged on every read
+		if len(page) < q.Limit && len(timeline) == timelineSize {
+			cursor := timeline[len(timeline)-1].ID
+			if before != 0 && before < cursor {
+				cursor = before
+			}
+
+			older, err := s.friendActivities(r, u.ID, cursor, q.Limit-len(page))
+			if err != nil {
+				s.storeError(w, r, err)
+				return
+			}
+			page = append(page, older...)
+		}
+
+		res := &response{Activities: page}
+		if len(page) == q.Limit {
+			res.Before = page[len(page)-1].ID
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, res)
+	}
+}
+
+// handleUserActivityFeed func. Handler func that returns Atom feed of
+// the public activity of the user, i.e. library activities are left out
+// unless the library of the user is public.
+func (s *server) handleUserActivityFeed() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Checking that the user exists and isn't hidden from the viewer
+		u, err := s.store.User().Find(r.Context(), id)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		if hidden, err := s.isHidden(r, s.sessionUserID(r), id); err != nil || hidden {
+			s.hiddenError(w, r, err)
+			return
+		}
+
+		activities, err := s.store.Activity().FindByUsers(r.Context(), []int{id}, 0, defaultListLimit)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		if activities, err = s.visibleActivities(r, 0, activities); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		base := baseURL(r)
+		author := fmt.Sprintf("User %d", u.ID)
+		f := &atom.Feed{
+			ID:      fmt.Sprintf("tag:tavern-of-games,2020:users/%d/activity", u.ID),
+			Title:   fmt.Sprintf("Activity of user %d", u.ID),
+			Link:    fmt.Sprintf("%s/users/%d", base, u.ID),
+			Author:  author,
+			Updated: u.CreatedAt,
+		}
+		for _, a := range activities {
+			e := &atom.Entry{
+				ID:      fmt.Sprintf("tag:tavern-of-games,2020:activities/%d", a.ID),
+				Title:   author + " " + a.Text,
+				Updated: a.CreatedAt,
+			}
+			if a.Link != "" {
+				e.Link = base + a.Link
+			}
+			f.Entries = append(f.Entries, e)
+		}
+		if len(activities) > 0 {
+			f.Updated = activities[0].CreatedAt
+		}
+
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		if _, err := f.WriteTo(w); err != nil {
+			logging.FromContext(r.Context()).Errorf("writing activity feed: %v", err)
+		}
+	}
+}
+
+// recordActivity func. Records the activity of the user for feeds of
+// friends. Failing to record an activity doesn't fail the request, the
+// error is only logged.
+func (s *server) recordActivity(r *http.Request, a *model.Activity) {
+	if err := s.store.Activity().Create(r.Context(), a); err != nil {
+		logging.FromContext(r.Context()).WithFields(logrus.Fields{
+			"user_id":  a.UserID,
+			"activity": a.Type,
+		}).Errorf("recording activity: %v", err)
+	}
+}
+
+// recordStartedPlaying func. Records that the user started playing the
+// game of the library entry
+func (s *server) recordStartedPlaying(r *http.Request, e *model.LibraryEntry) {
+	s.recordActivity(r, &model.Activity{
+		UserID: e.UserID,
+		Type:   model.ActivityStartedPlaying,
+		GameID: e.GameID,
+		Text:   "started playing " + s.gameTitle(r, e.GameID),
+		Link:   fmt.Sprintf("/users/%d/library", e.UserID),
+	})
+}
+
+// timeline func. Returns the cached timeline of the user's feed, or
+// merges and caches it if it isn't cached
+func (s *server) timeline(r *http.Request, userID int) ([]*model.Activity, error) {
+	now := time.Now()
+	if activities, ok := s.timelines.Get(userID, now); ok {
+		return activities, nil
+	}
+
+	activities, err := s.friendActivities(r, userID, 0, timelineSize)
+	if err != nil {
+		return nil, err
+	}
+	s.timelines.Set(userID, activities, now)
+
+	return activities, nil
+}
+
+// friendActivities func. Merges the page of activities of friends of
+// the user older than the activity with beforeID. Activities the user
+// can't see are left out, so the page can be shorter than the limit.
+func (s *server) friendActivities(r *http.Request, userID, beforeID, limit int) ([]*model.Activity, error) {
+	friendships, err := s.store.Friendship().FindByUser(r.Context(), userID, model.FriendshipAccepted)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(friendships))
+	for _, f := range friendships {
+		id := f.Other(userID)
+		hidden, err := s.isHidden(r, userID, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if !hidden {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return []*model.Activity{}, nil
+	}
+
+	activities, err := s.store.Activity().FindByUsers(r.Context(), ids, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.visibleActivities(r, userID, activities)
+}
+
+// visibleActivities func. Leaves out library activities of users whose
+// libraries the viewer can't see. Zero viewerID means an anonymous viewer.
+func (s *server) visibleActivities(r *http.Request, viewerID int, activities []*model.Activity) ([]*model.Activity, error) {
+	visible := make(map[int]bool)
+	result := make([]*model.Activity, 0, len(activities))
+	for _, a := range activities {
+		if a.Library() {
+			v, ok := visible[a.UserID]
+			if !ok {
+				var err error
+				if v, err = s.libraryVisible(r, viewerID, a.UserID); err != nil {
+					return nil, err
+				}
+				visible[a.UserID] = v
+			}
+
+			if !v {
+				continue
+			}
+		}
+		result = append(result, a)
+	}
+
+	return result, nil
+}
+
+// gameTitle func. Returns the title of the game, or its id if it can't
+// be found
+func (s *server) gameTitle(r *http.Request, id int) string {
+	g, err := s.store.Game().Find(r.Context(), id)
+	if err != nil {
+		return fmt.Sprintf("game %d", id)
+	}
+
+	return g.Title
+}
+
+// baseURL func. Returns scheme and host the request was sent to
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host
+}
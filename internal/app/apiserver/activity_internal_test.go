@@ -0,0 +1,121 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/sessions"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleFeed(t *testing.T) {
+	store := teststore.New()
+	viewer := model.TestUser(t)
+	store.User().Create(context.Background(), viewer)
+	users := []*model.User{}
+	for i := 1; i <= 3; i++ {
+		u := model.TestUser(t)
+		u.Email = fmt.Sprintf("user%d@example.org", i)
+		store.User().Create(context.Background(), u)
+		users = append(users, u)
+	}
+	// The first two users are friends of the viewer, the second one has
+	// private library
+	for _, u := range users[:2] {
+		store.Friendship().Apply(context.Background(), viewer.ID, u.ID, model.FriendshipRequest)
+		store.Friendship().Apply(context.Background(), u.ID, viewer.ID, model.FriendshipAccept)
+	}
+	store.Library().SetVisibility(context.Background(), users[1].ID, model.LibraryVisibilityPrivate)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	serve := func(u *model.User, method, path string, payload interface{}) *httptest.ResponseRecorder {
+		b := &bytes.Buffer{}
+		if payload != nil {
+			json.NewEncoder(b).Encode(payload)
+		}
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, b)
+		if u != nil {
+			req.Header.Set("Cookie", testCookie(t, secretKey, u.ID))
+		}
+		s.ServeHTTP(rec, req)
+
+		return rec
+	}
+	// Every user starts playing the game and reviews it
+	for _, u := range users {
+		rec := serve(u, http.MethodPost, "/private/me/library", map[string]interface{}{
+			"game_id": g.ID,
+			"status":  model.LibraryStatusPlaying,
+		})
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		rec = serve(u, http.MethodPost, fmt.Sprintf("/private/games/%d/review", g.ID), map[string]interface{}{
+			"score": 8,
+		})
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	}
+
+	feed := func(query string) ([]*model.Activity, int) {
+		rec := serve(viewer, http.MethodGet, "/private/feed"+query, nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		res := &struct {
+			Activities []*model.Activity `json:"activities"`
+			Before     int               `json:"before"`
+		}{}
+		json.NewDecoder(rec.Body).Decode(res)
+
+		return res.Activities, res.Before
+	}
+	// Friends only, without the library of the second friend
+	activities, before := feed("?limit=2")
+	if assert.Len(t, activities, 2) {
+		assert.Equal(t, users[1].ID, activities[0].UserID)
+		assert.Equal(t, model.ActivityReviewed, activities[0].Type)
+		assert.Equal(t, "reviewed "+g.Title, activities[0].Text)
+		assert.Equal(t, users[0].ID, activities[1].UserID)
+		assert.Equal(t, model.ActivityReviewed, activities[1].Type)
+	}
+
+	activities, before = feed(fmt.Sprintf("?before=%d", before))
+	if assert.Len(t, activities, 1) {
+		assert.Equal(t, model.ActivityStartedPlaying, activities[0].Type)
+	}
+	assert.Zero(t, before)
+	assert.Equal(t, http.StatusBadRequest, serve(viewer, http.MethodGet, "/private/feed?before=0", nil).Code)
+
+	// Blocked friends disappear from the feed right away
+	rec := serve(users[1], http.MethodPost, fmt.Sprintf("/private/friends/%d/block", viewer.ID), nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	activities, _ = feed("")
+	for _, a := range activities {
+		assert.Equal(t, users[0].ID, a.UserID)
+	}
+
+	// Atom feeds show public activity only
+	rec = serve(nil, http.MethodGet, fmt.Sprintf("/users/%d/activity.atom", users[0].ID), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/atom+xml; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, 2, strings.Count(rec.Body.String(), "<entry>"))
+	assert.Contains(t, rec.Body.String(), fmt.Sprintf("<title>User %d started playing %s</title>", users[0].ID, g.Title))
+
+	rec = serve(nil, http.MethodGet, fmt.Sprintf("/users/%d/activity.atom", users[1].ID), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, strings.Count(rec.Body.String(), "<entry>"))
+
+	rec = serve(viewer, http.MethodGet, fmt.Sprintf("/users/%d/activity.atom", users[1].ID), nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -1,14 +1,32 @@
 package apiserver
 
 import (
+	"context"
 	"database/sql"
+	"io/fs"
 	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
 
+	"github.com/GShamian/tavern-of-games/internal/app/achievement"
+	"github.com/GShamian/tavern-of-games/internal/app/logging"
+	"github.com/GShamian/tavern-of-games/internal/app/store/migrate"
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlitestore"
 	"github.com/GShamian/tavern-of-games/internal/app/store/sqlstore"
+	"github.com/GShamian/tavern-of-games/internal/app/textfilter"
+	"github.com/GShamian/tavern-of-games/internal/app/trace"
+	"github.com/GShamian/tavern-of-games/migrations"
 	"github.com/gorilla/sessions"
 )
 
-// Start func. Starts server.
+// Start func. Starts server and serves until it fails or the process
+// gets SIGINT or SIGTERM. On a signal the server reports it isn't ready
+// and keeps serving for the shutdown drain, then it stops accepting
+// connections, drains in-flight requests and background jobs within the
+// shutdown timeout and closes the DB.
 func Start(config *Config) error {
 	// Getting a pointer to our db and getting an access to it.
 	db, err := newDB(config.DatabaseURL)
@@ -17,18 +35,155 @@ func Start(config *Config) error {
 	}
 	defer db.Close()
 	// Creating Store instance with our db. Check store.go documentation.
-	store := sqlstore.New(db)
+	store := newStore(config, db)
 	sessionStore := sessions.NewCookieStore([]byte(config.SessionKey))
 	// Creating server instance with our store. Check server.go documentation.
 	srv := newServer(store, sessionStore)
-	// Starting srv server with address from config
-	return http.ListenAndServe(config.BindAddr, srv)
+	if err := logging.Configure(srv.logger, config.LogLevel, config.LogFormat); err != nil {
+		return err
+	}
+	exporter, err := newTraceExporter(config.TraceExporter)
+	if err != nil {
+		return err
+	}
+	srv.tracer = trace.NewTracer(exporter)
+	srv.origins = config.AllowedOrigins
+	// Loading achievements and evaluating new ones from the history
+	definitions, err := achievement.LoadFile(config.AchievementsPath)
+	if err != nil {
+		return err
+	}
+	// Migrating the schema before anything reads it
+	migrator, err := newMigrator(config, db)
+	if err != nil {
+		return err
+	}
+	if config.AutoMigrate {
+		if err := migrator.Up(context.Background()); err != nil {
+			return err
+		}
+	}
+	srv.achievements = achievement.NewEngine(definitions, srv.store)
+	if err := srv.achievements.Sync(context.Background(), time.Now()); err != nil {
+		return err
+	}
+	// Loading word lists and limits of the text filter
+	filter, err := textfilter.LoadFile(config.TextFilterPath)
+	if err != nil {
+		return err
+	}
+	srv.configureTextFilter(filter)
+	// Running background jobs until the server stops
+	srv.jobs.Every(matchmakingInterval, srv.matchmaker.Tick)
+	srv.jobs.Every(lfgExpiryInterval, srv.expireLFGPosts)
+	srv.jobs.Every(timelineTTL, func(now time.Time) { srv.timelines.Sweep(now) })
+	srv.jobs.Every(time.Minute, srv.chatFlood.Sweep)
+	srv.jobs.Every(time.Minute, srv.lobbies.Sweep)
+	// Exposing stats of the DB pool with metrics of the server
+	srv.metrics.registerDBStats(db)
+	// Checking the DB and its schema for readiness
+	srv.health.Register("db", dbCheckTimeout, db.PingContext)
+	srv.health.Register("migrations", dbCheckTimeout, migrator.Check)
+	// Serving with addresses and timeouts from config until a signal
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	servers := []*http.Server{newHTTPServer(config, srv)}
+	if config.AdminBindAddr != "" {
+		admin := newHTTPServer(config, srv.adminHandler())
+		admin.Addr = config.AdminBindAddr
+		servers = append(servers, admin)
+	}
+
+	return srv.serve(servers, config.ShutdownDrain(), config.ShutdownTimeout(), signals)
+}
+
+// newHTTPServer func. Constructor for HTTP server of the handler with
+// address, timeouts and limit of request headers from config.
+func newHTTPServer(config *Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              config.BindAddr,
+		Handler:           handler,
+		ReadTimeout:       seconds(config.ReadTimeoutSeconds),
+		ReadHeaderTimeout: seconds(config.ReadHeaderTimeoutSeconds),
+		WriteTimeout:      seconds(config.WriteTimeoutSeconds),
+		IdleTimeout:       seconds(config.IdleTimeoutSeconds),
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+}
+
+// serve func. Serves the HTTP servers until one of them fails or a
+// signal comes. After a signal the server isn't ready but keeps serving
+// for the drain, so load balancers notice it before it stops accepting
+// connections, another signal cuts the drain short. Then the servers and
+// background jobs are shut down within the timeout. The first server is
+// the one streams are served on.
+func (s *server) serve(servers []*http.Server, drain, timeout time.Duration, signals <-chan os.Signal) error {
+	s.streamTimeout = servers[0].WriteTimeout
+
+	errs := make(chan error, len(servers))
+	for _, hs := range servers {
+		go func(hs *http.Server) {
+			errs <- hs.ListenAndServe()
+		}(hs)
+	}
+
+	select {
+	case err := <-errs:
+		// The other servers are closed right away
+		s.stop()
+		for _, hs := range servers {
+			hs.Close()
+		}
+		s.jobs.Stop()
+		return err
+	case sig := <-signals:
+		s.logger.Infof("shutting down on %s", sig)
+	}
+
+	s.drain()
+	if drain > 0 {
+		timer := time.NewTimer(drain)
+		select {
+		case <-timer.C:
+		case sig := <-signals:
+			s.logger.Infof("cutting the drain short on %s", sig)
+		}
+		timer.Stop()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Streams are ended first, the HTTP servers wait for the
+	// requests serving them otherwise. Jobs are stopped even if
+	// requests didn't drain in time.
+	s.stop()
+	var err error
+	for _, hs := range servers {
+		if shutdownErr := hs.Shutdown(ctx); err == nil {
+			err = shutdownErr
+		}
+	}
+	if jobsErr := s.jobs.Shutdown(ctx); err == nil {
+		err = jobsErr
+	}
+
+	return err
 }
 
 // newDB func. Constructor for DB. Importing a db url to get an access to db.
-// As a result we get a pointer on our target db.
+// As a result we get a pointer on our target db. The database URL is a
+// postgres or a SQLite one, see sqlitestore.Scheme.
 func newDB(databaseURL string) (*sql.DB, error) {
-	db, err := sql.Open("postgres", databaseURL)
+	var db *sql.DB
+	var err error
+	if path, ok := sqlitestore.Path(databaseURL); ok {
+		db, err = sqlitestore.Open(path)
+	} else {
+		db, err = sql.Open("postgres", databaseURL)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -39,3 +194,44 @@ func newDB(databaseURL string) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// newStore func. Constructor for store of the DB of the backend the
+// database URL of config points to.
+func newStore(config *Config, db *sql.DB) *sqlstore.Store {
+	if _, ok := sqlitestore.Path(config.DatabaseURL); ok {
+		return sqlitestore.New(db, config.QueryTimeout())
+	}
+
+	return sqlstore.New(db, config.QueryTimeout())
+}
+
+// newMigrator func. Constructor for migrator of the schema of the
+// backend the database URL of config points to. Migrations are the
+// embedded ones unless config has the migrations path.
+func newMigrator(config *Config, db *sql.DB) (*migrate.Migrator, error) {
+	_, isSQLite := sqlitestore.Path(config.DatabaseURL)
+
+	var fsys fs.FS
+	switch {
+	case config.MigrationsPath != "" && isSQLite:
+		fsys = os.DirFS(filepath.Join(config.MigrationsPath, "sqlite"))
+	case config.MigrationsPath != "":
+		fsys = os.DirFS(config.MigrationsPath)
+	case isSQLite:
+		fsys = migrations.SQLite()
+	default:
+		fsys = migrations.Postgres()
+	}
+
+	files, err := migrate.Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	dialect := migrate.Postgres
+	if isSQLite {
+		dialect = migrate.SQLite
+	}
+
+	return migrate.New(db, dialect, files), nil
+}
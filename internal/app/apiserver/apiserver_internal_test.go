@@ -0,0 +1,229 @@
+package apiserver
+
+import (
+	"bufio"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+	"github.com/gorilla/websocket"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewHTTPServer(t *testing.T) {
+	config := NewConfig()
+	config.BindAddr = ":9090"
+	config.WriteTimeoutSeconds = 0
+
+	hs := newHTTPServer(config, http.NotFoundHandler())
+	assert.Equal(t, ":9090", hs.Addr)
+	assert.Equal(t, 15*time.Second, hs.ReadTimeout)
+	assert.Equal(t, 5*time.Second, hs.ReadHeaderTimeout)
+	assert.Zero(t, hs.WriteTimeout)
+	assert.Equal(t, time.Minute, hs.IdleTimeout)
+	assert.Equal(t, 1<<20, hs.MaxHeaderBytes)
+	assert.Equal(t, 30*time.Second, config.ShutdownTimeout())
+}
+
+func TestNewDB(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apiserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := newDB("sqlite://" + filepath.Join(dir, "tavern.db"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer db.Close()
+
+	assert.FileExists(t, filepath.Join(dir, "tavern.db"))
+}
+
+func TestNewMigrator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apiserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := NewConfig()
+	config.DatabaseURL = "sqlite://" + filepath.Join(dir, "tavern.db")
+	db, err := newDB(config.DatabaseURL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer db.Close()
+
+	// SQLite migrations are embedded next to the postgres ones
+	m, err := newMigrator(config, db)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20201107120000), m.Latest())
+
+	config.DatabaseURL = "host=localhost dbname=tavern_of_games_db sslmode=disable"
+	m, err = newMigrator(config, db)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20201107120000), m.Latest())
+
+	// The migrations path overrides embedded migrations
+	config.MigrationsPath = dir
+	_, err = newMigrator(config, db)
+	assert.Error(t, err)
+}
+
+func TestMigrate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "apiserver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := NewConfig()
+	config.DatabaseURL = "sqlite://" + filepath.Join(dir, "tavern.db")
+
+	for _, args := range [][]string{nil, {"sideways"}, {"up", "now"}, {"goto"}, {"goto", "latest"}} {
+		assert.EqualError(t, Migrate(config, args, ioutil.Discard), errMigrateUsage.Error())
+	}
+
+	out := &strings.Builder{}
+	assert.NoError(t, Migrate(config, []string{"status"}, out))
+	assert.Equal(t, "version: 0\nlatest: 20201107120000\npending: 20201105120000_create_schema\npending: 20201107120000_create_calendar_tokens\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, Migrate(config, []string{"up"}, out))
+	assert.Equal(t, "version: 20201107120000\nlatest: 20201107120000\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, Migrate(config, []string{"goto", "0"}, out))
+	assert.Equal(t, "version: 0\nlatest: 20201107120000\npending: 20201105120000_create_schema\npending: 20201107120000_create_calendar_tokens\n", out.String())
+
+	assert.Error(t, Migrate(config, []string{"down"}, ioutil.Discard))
+}
+
+func TestServer_Serve(t *testing.T) {
+	s := newServer(teststore.New(), sessions.NewCookieStore([]byte("secret")))
+	ticked := make(chan struct{}, 1)
+	s.jobs.Every(time.Millisecond, func(time.Time) {
+		select {
+		case ticked <- struct{}{}:
+		default:
+		}
+	})
+	<-ticked
+
+	config := NewConfig()
+	config.BindAddr = "127.0.0.1:0"
+	signals := make(chan os.Signal, 1)
+	signals <- syscall.SIGTERM
+
+	admin := newHTTPServer(config, s.adminHandler())
+	assert.NoError(t, s.serve([]*http.Server{newHTTPServer(config, s), admin}, 0, time.Second, signals))
+
+	// Streams and jobs are over after shutdown
+	select {
+	case <-s.quit:
+	default:
+		t.Error("streams weren't stopped")
+	}
+	for len(ticked) > 0 {
+		<-ticked
+	}
+	time.Sleep(5 * time.Millisecond)
+	assert.Empty(t, ticked)
+}
+
+func TestServer_ServeDrains(t *testing.T) {
+	s := newServer(teststore.New(), sessions.NewCookieStore([]byte("secret")))
+	config := NewConfig()
+	config.BindAddr = "127.0.0.1:0"
+	signals := make(chan os.Signal, 1)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- s.serve([]*http.Server{newHTTPServer(config, s)}, 200*time.Millisecond, time.Second, signals)
+	}()
+	readyz := func() int {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		return rec.Code
+	}
+	assert.Equal(t, http.StatusOK, readyz())
+
+	// Not ready during the drain, while streams are still served
+	signals <- syscall.SIGTERM
+	for !s.draining() {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, http.StatusServiceUnavailable, readyz())
+	assert.False(t, s.stopping())
+	select {
+	case <-done:
+		t.Fatal("server shut down before the drain")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	assert.NoError(t, <-done)
+	assert.True(t, s.stopping())
+	assert.Equal(t, http.StatusServiceUnavailable, readyz())
+}
+
+func TestServer_StopEndsStreams(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	store.User().Create(context.Background(), u)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/private/notifications/stream", nil)
+	req.Header.Set("Cookie", testCookie(t, secretKey, u.ID))
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/private/chat/ws?room=" + model.ChatRoomHall
+	header := http.Header{}
+	header.Set("Cookie", testCookie(t, secretKey, u.ID))
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	s.stop()
+	s.stop()
+
+	ended := make(chan struct{})
+	go func() {
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+		}
+		close(ended)
+	}()
+	select {
+	case <-ended:
+	case <-time.After(time.Second):
+		t.Fatal("notification stream didn't end")
+	}
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway))
+}
@@ -0,0 +1,98 @@
+package apiserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/GShamian/tavern-of-games/internal/app/authz"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/gorilla/mux"
+)
+
+var (
+	errNotGuildMember = errors.New("not a member of the guild")
+)
+
+// guildAccess object that describes the guild of the request, the
+// membership of the actual user in it and the membership of the user
+// the action is applied to (nil if the user isn't a member).
+type guildAccess struct {
+	guild  *model.Guild
+	member *model.GuildMember
+	target *model.GuildMember
+}
+
+// authorizeGuild func. Wraps handler func of guild routes, so that only
+// members of the guild from the id path variable whose role is allowed
+// to perform the action by authz.Guild policy get through. Routes with
+// user_id path variable apply the action to that user, and the actual
+// user has to outrank them if they are a member. Handlers get the
+// guildAccess from the request context. It has to follow
+// authenticateUser in the router chain.
+func (s *server) authorizeGuild(action string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		guildID, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		g, err := s.store.Guild().Find(r.Context(), guildID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Finding membership of the actual user
+		access := &guildAccess{guild: g}
+		access.member, err = s.store.Guild().Member(r.Context(), guildID, u.ID)
+		if err == store.ErrRecordNotFound {
+			s.error(w, r, http.StatusForbidden, errNotGuildMember)
+			return
+		}
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Finding membership of the user the action is applied to
+		targetRole := ""
+		if _, ok := mux.Vars(r)["user_id"]; ok {
+			userID, err := pathInt(r, "user_id")
+			if err != nil {
+				s.error(w, r, http.StatusBadRequest, err)
+				return
+			}
+
+			access.target, err = s.store.Guild().Member(r.Context(), guildID, userID)
+			switch err {
+			case nil:
+				targetRole = access.target.Role
+			case store.ErrRecordNotFound:
+			default:
+				s.storeError(w, r, err)
+				return
+			}
+		}
+
+		if err := authz.Guild.Authorize(access.member.Role, action, targetRole); err != nil {
+			s.guildError(w, r, err)
+			return
+		}
+
+		next(w, r.WithContext(context.WithValue(r.Context(), ctxKeyGuildAccess, access)))
+	}
+}
+
+// guildError func. Creates an error response for guild requests
+func (s *server) guildError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case authz.ErrForbidden:
+		s.error(w, r, http.StatusForbidden, err)
+	case model.ErrGuildLeader, model.ErrGuildInviteExists:
+		s.error(w, r, http.StatusConflict, err)
+	default:
+		s.storeError(w, r, err)
+	}
+}
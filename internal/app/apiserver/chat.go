@@ -0,0 +1,427 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/authz"
+	"github.com/GShamian/tavern-of-games/internal/app/logging"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/textfilter"
+	"github.com/gorilla/websocket"
+)
+
+// Chat connection settings
+const (
+	chatWriteWait      = 10 * time.Second
+	chatPongWait       = 60 * time.Second
+	chatPingPeriod     = chatPongWait * 9 / 10
+	chatMaxMessageSize = 4096
+)
+
+var (
+	errChatRoomForbidden = errors.New("can't join chat room")
+)
+
+// newUpgrader func. Constructor for upgrader of requests to WebSocket
+// connections of the server. Browsers send cookies of the session with
+// the upgrade from any site and CORS doesn't apply to WebSockets, so
+// origins are checked by the server itself.
+func (s *server) newUpgrader() *websocket.Upgrader {
+	return &websocket.Upgrader{
+		ReadBufferSize:  1024,
+		WriteBufferSize: 1024,
+		CheckOrigin:     s.checkOrigin,
+	}
+}
+
+// checkOrigin func. Checks that the WebSocket request comes from the
+// host of the server or one of allowed origins. Requests without Origin
+// aren't sent by browsers, so they're allowed.
+func (s *server) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	for _, allowed := range s.origins {
+		if strings.EqualFold(strings.TrimSuffix(allowed, "/"), origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// closeGoingAway is the close message WebSocket connections get when the
+// server stops.
+var closeGoingAway = websocket.FormatCloseMessage(websocket.CloseGoingAway, "server is shutting down")
+
+// closeRestricted is the close message WebSocket connections of a user
+// get when the user is suspended or banned.
+var closeRestricted = websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "account is restricted")
+
+// chatClose object. Reply closing the connection with the close message
+// once replies before it are written.
+type chatClose []byte
+
+// handleChatMessages func. Handler func that returns a page of history
+// of the chat room, newest messages first. Older pages are requested
+// with before query parameter set to id of the oldest known message.
+func (s *server) handleChatMessages() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		room := r.URL.Query().Get("room")
+		if err := s.checkChatRoom(r, u, room); err != nil {
+			s.chatRoomError(w, r, err)
+			return
+		}
+		// Parsing history cursor and page size
+		q, err := parseListQuery(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		before, err := parseCursor(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		messages, err := s.store.Chat().FindByRoom(r.Context(), room, before, q.Limit)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, messages)
+	}
+}
+
+// handleChatSocket func. Handler func that upgrades the request to a
+// WebSocket connection to the chat room. Messages written by the actual
+// user are persisted and published to the broker, messages published
+// to the room are written back to the connection.
+func (s *server) handleChatSocket() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		room := r.URL.Query().Get("room")
+		if err := s.checkChatRoom(r, u, room); err != nil {
+			s.chatRoomError(w, r, err)
+			return
+		}
+		// Subscribing to the room before the upgrade, so the user gets
+		// every message written after the connection is open
+		messages, unsubscribe := s.broker.Subscribe(room)
+		// Upgrader writes an error response itself if it fails
+		conn, err := s.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			unsubscribe()
+			return
+		}
+
+		kicked, disconnect := s.online.connect(u.ID)
+		defer disconnect()
+
+		replies := make(chan interface{}, 8)
+		go s.chatWriter(conn, messages, replies, kicked)
+
+		s.chatReader(r, conn, u, room, replies)
+		unsubscribe()
+	}
+}
+
+// chatReader func. Reads messages of the user from the connection until
+// it fails. Messages which pass the text filter are persisted and
+// published to the room, flagged ones are queued for moderators. Errors
+// are sent back to the user through replies. Sanctions and access to the
+// room are checked on every message: muted users can't post, suspended
+// and banned users and ones who lost access to the room are
+// disconnected.
+func (s *server) chatReader(r *http.Request, conn *websocket.Conn, u *model.User, room string, replies chan<- interface{}) {
+	type request struct {
+		Text string `json:"text"`
+	}
+
+	conn.SetReadLimit(chatMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(chatPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(chatPongWait))
+	})
+
+	for {
+		req := &request{}
+		if err := conn.ReadJSON(req); err != nil {
+			return
+		}
+
+		if err := s.checkChatPost(r, u, room); err != nil {
+			reply, closing := chatPostReply(err)
+			select {
+			case replies <- reply:
+			default:
+			}
+			if closing == nil {
+				continue
+			}
+			select {
+			case replies <- closing:
+			default:
+			}
+			return
+		}
+
+		// Filtering the text and flooding, blocked messages aren't
+		// written at all
+		checker := append(textfilter.Pipeline{}, s.textFilter...)
+		checker = append(checker, s.chatFlood.For(u.ID, time.Now()))
+		flags, err := filterText(checker, map[string]string{"text": req.Text})
+		if err != nil {
+			select {
+			case replies <- map[string]string{"error": err.Error()}:
+			default:
+			}
+			continue
+		}
+
+		m := &model.ChatMessage{
+			Room:   room,
+			UserID: u.ID,
+			Text:   req.Text,
+		}
+		if err := s.store.Chat().Create(r.Context(), m); err != nil {
+			select {
+			case replies <- map[string]string{"error": err.Error()}:
+			default:
+			}
+			continue
+		}
+		s.reportFlagged(r, &model.Report{
+			TargetType:   model.ReportTargetMessage,
+			TargetID:     m.ID,
+			TargetUserID: u.ID,
+			Excerpt:      m.Text,
+		}, flags)
+
+		if err := s.broker.Publish(m); err != nil {
+			logging.FromContext(r.Context()).Errorf("publishing chat message %d: %v", m.ID, err)
+		}
+	}
+}
+
+// chatSanctionError object. The user can't post to chat because of the
+// sanction
+type chatSanctionError struct {
+	sanction *model.Sanction
+}
+
+// Error func. Implements error
+func (e *chatSanctionError) Error() string {
+	return e.sanction.Kind
+}
+
+// checkChatPost func. Checks that the user can still post to the room:
+// the user isn't suspended, banned or muted and has access to the room.
+func (s *server) checkChatPost(r *http.Request, u *model.User, room string) error {
+	sanctions, err := s.store.Moderation().Sanctions(r.Context(), u.ID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if sn := model.ActiveSanction(sanctions, now, model.SanctionSuspend, model.SanctionBan); sn != nil {
+		return &chatSanctionError{sn}
+	}
+	if sn := model.ActiveSanction(sanctions, now, model.SanctionMute); sn != nil {
+		return &chatSanctionError{sn}
+	}
+
+	return s.checkChatRoom(r, u, room)
+}
+
+// chatPostReply func. Describes the error of checkChatPost to the user.
+// Returns the reply closing the connection too, unless the user stays
+// connected: muted users do, as do users whose check failed for other
+// reasons.
+func chatPostReply(err error) (map[string]interface{}, chatClose) {
+	if e, ok := err.(*chatSanctionError); ok {
+		reply := sanctionResponse(e.sanction)
+		if e.sanction.Kind == model.SanctionMute {
+			return reply, nil
+		}
+		return reply, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reply["error"].(string))
+	}
+
+	switch err {
+	case errChatRoomForbidden, store.ErrRecordNotFound, model.ErrInvalidChatRoom:
+		return map[string]interface{}{"error": errChatRoomForbidden.Error()},
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, errChatRoomForbidden.Error())
+	}
+
+	return map[string]interface{}{"error": "can't post to chat"}, nil
+}
+
+// sendDirect func. Writes the message of the user to the direct
+// messages room with the other user and publishes it to the room, the
+// same way messages written over the WebSocket connection are.
+func (s *server) sendDirect(r *http.Request, userID, otherID int, text string) error {
+	m := &model.ChatMessage{
+		Room:   model.DirectChatRoom(userID, otherID),
+		UserID: userID,
+		Text:   text,
+	}
+	if err := s.store.Chat().Create(r.Context(), m); err != nil {
+		return err
+	}
+
+	return s.broker.Publish(m)
+}
+
+// chatWriter func. The only writer of the connection. Writes messages
+// published to the room and replies to the user, and pings the user to
+// keep the connection alive. Closes the connection when the subscription
+// is cancelled, a reply closes it, the user is disconnected or the
+// server stops.
+func (s *server) chatWriter(conn *websocket.Conn, messages <-chan *model.ChatMessage, replies <-chan interface{}, kicked <-chan struct{}) {
+	ticker := time.NewTicker(chatPingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		var err error
+		select {
+		case m, ok := <-messages:
+			if !ok {
+				writeReplies(conn, replies)
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(chatWriteWait))
+			err = conn.WriteJSON(m)
+		case reply := <-replies:
+			if msg, ok := reply.(chatClose); ok {
+				conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(chatWriteWait))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(chatWriteWait))
+			err = conn.WriteJSON(reply)
+		case <-kicked:
+			conn.WriteControl(websocket.CloseMessage, closeRestricted, time.Now().Add(chatWriteWait))
+			return
+		case <-s.quit:
+			conn.WriteControl(websocket.CloseMessage, closeGoingAway, time.Now().Add(chatWriteWait))
+			return
+		case <-ticker.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(chatWriteWait))
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writeReplies func. Writes replies left to the user and closes the
+// connection with the close message of the closing reply, if there's
+// one.
+func writeReplies(conn *websocket.Conn, replies <-chan interface{}) {
+	for {
+		select {
+		case reply := <-replies:
+			if msg, ok := reply.(chatClose); ok {
+				conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(chatWriteWait))
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(chatWriteWait))
+			if err := conn.WriteJSON(reply); err != nil {
+				return
+			}
+		default:
+			conn.WriteControl(websocket.CloseMessage, []byte{}, time.Now().Add(chatWriteWait))
+			return
+		}
+	}
+}
+
+// checkChatRoom func. Checks that the room exists and the user can join
+// it. Direct messages rooms are open only to their two users, and only
+// while none of them blocked the other one.
+func (s *server) checkChatRoom(r *http.Request, u *model.User, name string) error {
+	room, err := model.ParseChatRoom(name)
+	if err != nil {
+		return err
+	}
+
+	switch room.Kind {
+	case model.ChatRoomGame:
+		_, err := s.store.Game().Find(r.Context(), room.GameID)
+		return err
+	case model.ChatRoomGuild:
+		if _, err := s.store.Guild().Find(r.Context(), room.GuildID); err != nil {
+			return err
+		}
+
+		m, err := s.store.Guild().Member(r.Context(), room.GuildID, u.ID)
+		if err == store.ErrRecordNotFound {
+			return errChatRoomForbidden
+		}
+		if err != nil {
+			return err
+		}
+
+		if authz.Guild.Authorize(m.Role, authz.GuildChat, "") != nil {
+			return errChatRoomForbidden
+		}
+	case model.ChatRoomDirect:
+		if !room.Member(u.ID) {
+			return errChatRoomForbidden
+		}
+
+		other := room.UserIDs[0]
+		if other == u.ID {
+			other = room.UserIDs[1]
+		}
+
+		if _, err := s.store.User().Find(r.Context(), other); err != nil {
+			return err
+		}
+
+		hidden, err := s.isHidden(r, u.ID, other)
+		if err != nil {
+			return err
+		}
+
+		if hidden {
+			return store.ErrRecordNotFound
+		}
+	}
+
+	return nil
+}
+
+// chatRoomError func. Creates an error response for a chat room the
+// user can't join.
+func (s *server) chatRoomError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case model.ErrInvalidChatRoom:
+		s.error(w, r, http.StatusBadRequest, err)
+	case errChatRoomForbidden:
+		s.error(w, r, http.StatusForbidden, err)
+	default:
+		s.storeError(w, r, err)
+	}
+}
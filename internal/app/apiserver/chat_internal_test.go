@@ -0,0 +1,329 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/sessions"
+	"github.com/gorilla/websocket"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleChatSocket(t *testing.T) {
+	store := teststore.New()
+	u1 := model.TestUser(t)
+	store.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	store.User().Create(context.Background(), u2)
+	u3 := model.TestUser(t)
+	u3.Email = "user3@example.org"
+	store.User().Create(context.Background(), u3)
+	store.Friendship().Block(context.Background(), u3.ID, u1.ID)
+
+	secretKey := []byte("secret")
+	srv := httptest.NewServer(newServer(store, sessions.NewCookieStore(secretKey)))
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/private/chat/ws?room="
+
+	dial := func(u *model.User, room string) (*websocket.Conn, int) {
+		header := http.Header{}
+		if u != nil {
+			header.Set("Cookie", testCookie(t, secretKey, u.ID))
+		}
+
+		conn, resp, err := websocket.DefaultDialer.Dial(url+room, header)
+		if err != nil {
+			return nil, resp.StatusCode
+		}
+
+		return conn, resp.StatusCode
+	}
+
+	testCases := []struct {
+		name         string
+		user         *model.User
+		room         string
+		expectedCode int
+	}{
+		{
+			name:         "not authenticated",
+			user:         nil,
+			room:         model.ChatRoomHall,
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "invalid room",
+			user:         u1,
+			room:         "cellar",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unknown game",
+			user:         u1,
+			room:         model.GameChatRoom(1),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "direct room of other users",
+			user:         u1,
+			room:         model.DirectChatRoom(u2.ID, u3.ID),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "direct room with blocking user",
+			user:         u1,
+			room:         model.DirectChatRoom(u1.ID, u3.ID),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, code := dial(tc.user, tc.room)
+			assert.Equal(t, tc.expectedCode, code)
+		})
+	}
+
+	t.Run("direct messages", func(t *testing.T) {
+		room := model.DirectChatRoom(u1.ID, u2.ID)
+		conn1, code := dial(u1, room)
+		assert.Equal(t, http.StatusSwitchingProtocols, code)
+		defer conn1.Close()
+		conn2, code := dial(u2, room)
+		assert.Equal(t, http.StatusSwitchingProtocols, code)
+		defer conn2.Close()
+
+		assert.NoError(t, conn1.WriteJSON(map[string]string{"text": "hi"}))
+		for _, conn := range []*websocket.Conn{conn1, conn2} {
+			m := &model.ChatMessage{}
+			assert.NoError(t, conn.ReadJSON(m))
+			assert.Equal(t, "hi", m.Text)
+			assert.Equal(t, u1.ID, m.UserID)
+		}
+
+		assert.NoError(t, conn2.WriteJSON(map[string]string{"text": ""}))
+		reply := map[string]string{}
+		assert.NoError(t, conn2.ReadJSON(&reply))
+		assert.NotEmpty(t, reply["error"])
+	})
+}
+
+func TestServer_HandleChatSocket_Origin(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	store.User().Create(context.Background(), u)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	s.origins = []string{"https://tavern.example.org/"}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/private/chat/ws?room=" + model.ChatRoomHall
+
+	testCases := []struct {
+		name         string
+		origin       string
+		expectedCode int
+	}{
+		{
+			name:         "no origin",
+			origin:       "",
+			expectedCode: http.StatusSwitchingProtocols,
+		},
+		{
+			name:         "host of the server",
+			origin:       srv.URL,
+			expectedCode: http.StatusSwitchingProtocols,
+		},
+		{
+			name:         "allowed origin",
+			origin:       "https://tavern.example.org",
+			expectedCode: http.StatusSwitchingProtocols,
+		},
+		{
+			name:         "foreign origin",
+			origin:       "https://evil.example.com",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "foreign origin with allowed host as subdomain",
+			origin:       "https://tavern.example.org.evil.example.com",
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("Cookie", testCookie(t, secretKey, u.ID))
+			if tc.origin != "" {
+				header.Set("Origin", tc.origin)
+			}
+
+			conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+			if err == nil {
+				conn.Close()
+			}
+			assert.Equal(t, tc.expectedCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestServer_HandleChatSocket_Restrictions(t *testing.T) {
+	store := teststore.New()
+	u1 := model.TestUser(t)
+	store.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	store.User().Create(context.Background(), u2)
+	moderator := model.TestUser(t)
+	moderator.Email = "moderator@example.org"
+	moderator.Role = model.RoleModerator
+	store.User().Create(context.Background(), moderator)
+
+	secretKey := []byte("secret")
+	srv := httptest.NewServer(newServer(store, sessions.NewCookieStore(secretKey)))
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/private/chat/ws?room="
+
+	dial := func(u *model.User, room string) *websocket.Conn {
+		header := http.Header{}
+		header.Set("Cookie", testCookie(t, secretKey, u.ID))
+		conn, _, err := websocket.DefaultDialer.Dial(url+room, header)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return conn
+	}
+	// sanction imposes the sanction on the user without the server
+	// knowing, as another instance of the server would
+	sanction := func(u *model.User, kind string) {
+		rep := model.TestReport(t, moderator.ID, u.ID)
+		store.Moderation().CreateReport(context.Background(), rep)
+		sn := model.TestSanction(t, u.ID, moderator.ID)
+		sn.Kind = kind
+		if _, err := store.Moderation().Resolve(context.Background(), rep.ID, kind, "Insults", moderator.ID, sn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// closedWith reads replies until the connection is closed and returns
+	// the last reply and the close code
+	closedWith := func(conn *websocket.Conn) (map[string]interface{}, int) {
+		var last map[string]interface{}
+		for {
+			reply := map[string]interface{}{}
+			if err := conn.ReadJSON(&reply); err != nil {
+				if ce, ok := err.(*websocket.CloseError); ok {
+					return last, ce.Code
+				}
+				return last, 0
+			}
+			last = reply
+		}
+	}
+
+	t.Run("direct room after block", func(t *testing.T) {
+		conn := dial(u1, model.DirectChatRoom(u1.ID, u2.ID))
+		defer conn.Close()
+
+		store.Friendship().Block(context.Background(), u2.ID, u1.ID)
+		defer store.Friendship().Unblock(context.Background(), u2.ID, u1.ID)
+
+		assert.NoError(t, conn.WriteJSON(map[string]string{"text": "hi"}))
+		reply, code := closedWith(conn)
+		assert.Equal(t, errChatRoomForbidden.Error(), reply["error"])
+		assert.Equal(t, websocket.ClosePolicyViolation, code)
+	})
+
+	t.Run("suspended after connect", func(t *testing.T) {
+		conn := dial(u1, model.ChatRoomHall)
+		defer conn.Close()
+
+		sanction(u1, model.SanctionSuspend)
+		assert.NoError(t, conn.WriteJSON(map[string]string{"text": "hi"}))
+		reply, code := closedWith(conn)
+		assert.Equal(t, errAccountSuspended.Error(), reply["error"])
+		assert.Equal(t, websocket.ClosePolicyViolation, code)
+	})
+
+	t.Run("banned while connected", func(t *testing.T) {
+		conn := dial(u2, model.ChatRoomHall)
+		defer conn.Close()
+
+		rep := model.TestReport(t, moderator.ID, u2.ID)
+		store.Moderation().CreateReport(context.Background(), rep)
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(map[string]string{"action": model.SanctionBan, "reason": "Cheats"})
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/private/admin/reports/%d/resolve", srv.URL, rep.ID), b)
+		req.Header.Set("Cookie", testCookie(t, secretKey, moderator.ID))
+		resp, err := http.DefaultClient.Do(req)
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+
+		// The connection is closed without the user writing anything
+		_, code := closedWith(conn)
+		assert.Equal(t, websocket.ClosePolicyViolation, code)
+	})
+}
+
+func TestServer_HandleChatMessages(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	store.User().Create(context.Background(), u)
+	for i := 0; i < 3; i++ {
+		store.Chat().Create(context.Background(), model.TestChatMessage(t, model.ChatRoomHall, u.ID))
+	}
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	testCases := []struct {
+		name         string
+		query        string
+		expectedCode int
+	}{
+		{
+			name:         "newest",
+			query:        "room=hall",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "older page",
+			query:        "room=hall&before=3&limit=1",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "invalid cursor",
+			query:        "room=hall&before=x",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid room",
+			query:        "room=",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/private/chat/messages?%s", tc.query), nil)
+			req.Header.Set("Cookie", testCookie(t, secretKey, u.ID))
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
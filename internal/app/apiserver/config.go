@@ -1,17 +1,81 @@
 package apiserver
 
-// Config object that store information from toml config file
+import "time"
+
+// Config object that store information from toml config file.
+// The database URL is a postgres one or sqlite:// followed by path of a
+// SQLite file, e.g. sqlite:///var/lib/tavern.db.
+// Logs are written in text or json format. Metrics are served on
+// the admin address, if it's set. Spans are exported to stdout
+// or nowhere.
+// Migrations are embedded in the binary, the migrations path overrides
+// them with a directory holding postgres migrations and SQLite ones in
+// its sqlite subdirectory. With auto migrate the schema is migrated up
+// on start.
+// WebSocket connections are accepted from the host of the server and
+// allowed origins, e.g. https://tavern.example.org.
+// Timeouts of the HTTP server, the shutdown drain and deadline and the
+// default timeout of DB queries are in seconds, zero timeouts are off.
 type Config struct {
-	BindAddr    string `toml:"bind_addr"`
-	LogLevel    string `toml:"log_level"`
-	DatabaseURL string `toml:"database_url"`
-	SessionKey  string `toml:"session_key"`
+	BindAddr                 string   `toml:"bind_addr"`
+	AdminBindAddr            string   `toml:"admin_bind_addr"`
+	LogLevel                 string   `toml:"log_level"`
+	LogFormat                string   `toml:"log_format"`
+	TraceExporter            string   `toml:"trace_exporter"`
+	DatabaseURL              string   `toml:"database_url"`
+	SessionKey               string   `toml:"session_key"`
+	AchievementsPath         string   `toml:"achievements_path"`
+	TextFilterPath           string   `toml:"text_filter_path"`
+	MigrationsPath           string   `toml:"migrations_path"`
+	AllowedOrigins           []string `toml:"allowed_origins"`
+	AutoMigrate              bool     `toml:"auto_migrate"`
+	ReadTimeoutSeconds       int      `toml:"read_timeout_seconds"`
+	ReadHeaderTimeoutSeconds int      `toml:"read_header_timeout_seconds"`
+	WriteTimeoutSeconds      int      `toml:"write_timeout_seconds"`
+	IdleTimeoutSeconds       int      `toml:"idle_timeout_seconds"`
+	MaxHeaderBytes           int      `toml:"max_header_bytes"`
+	ShutdownDrainSeconds     int      `toml:"shutdown_drain_seconds"`
+	ShutdownTimeoutSeconds   int      `toml:"shutdown_timeout_seconds"`
+	QueryTimeoutSeconds      int      `toml:"query_timeout_seconds"`
 }
 
 // NewConfig function. Constructor for Config
 func NewConfig() *Config {
 	return &Config{
-		BindAddr: "*:8080",
-		LogLevel: "debug",
+		BindAddr:                 "*:8080",
+		LogLevel:                 "debug",
+		LogFormat:                "text",
+		TraceExporter:            traceExporterNone,
+		ReadTimeoutSeconds:       15,
+		ReadHeaderTimeoutSeconds: 5,
+		WriteTimeoutSeconds:      30,
+		IdleTimeoutSeconds:       60,
+		MaxHeaderBytes:           1 << 20,
+		ShutdownDrainSeconds:     5,
+		ShutdownTimeoutSeconds:   30,
+		QueryTimeoutSeconds:      5,
 	}
 }
+
+// ShutdownDrain func. Returns how long the server keeps serving while
+// it reports it isn't ready before it's stopped
+func (c *Config) ShutdownDrain() time.Duration {
+	return seconds(c.ShutdownDrainSeconds)
+}
+
+// ShutdownTimeout func. Returns how long the server drains in-flight
+// requests and background jobs when it's stopped
+func (c *Config) ShutdownTimeout() time.Duration {
+	return seconds(c.ShutdownTimeoutSeconds)
+}
+
+// QueryTimeout func. Returns how long a DB query can run unless the
+// request it serves is cancelled earlier
+func (c *Config) QueryTimeout() time.Duration {
+	return seconds(c.QueryTimeoutSeconds)
+}
+
+// seconds func. Converts seconds of the config to duration
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
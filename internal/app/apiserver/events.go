@@ -0,0 +1,347 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/ical"
+	"github.com/GShamian/tavern-of-games/internal/app/logging"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/gorilla/mux"
+)
+
+// calendarProdID identifies the server in exported calendars
+const calendarProdID = "-//Tavern of Games//Events//EN"
+
+// handleEventsList func. Handler func that returns the page of upcoming
+// events, soonest first. Events of hosts hidden from the viewer are
+// left out.
+func (s *server) handleEventsList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := parseListQuery(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		events, err := s.store.Event().FindUpcoming(r.Context(), time.Now(), q)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Leaving out events of hosts hidden from the viewer
+		viewerID := s.sessionUserID(r)
+		visible := events[:0]
+		for _, e := range events {
+			hidden, err := s.isHidden(r, viewerID, e.HostID)
+			if err != nil {
+				s.storeError(w, r, err)
+				return
+			}
+
+			if !hidden {
+				visible = append(visible, e)
+			}
+		}
+		events = visible
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, events)
+	}
+}
+
+// handleEventsGet func. Handler func that returns the event with the
+// number of users for every RSVP status
+func (s *server) handleEventsGet() http.HandlerFunc {
+	type response struct {
+		*model.Event
+		RSVPs map[string]int `json:"rsvps"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		e, ok := s.findEvent(w, r)
+		if !ok {
+			return
+		}
+
+		rsvps, err := s.store.Event().RSVPs(r.Context(), e.ID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		counts := map[string]int{
+			model.RSVPGoing:      0,
+			model.RSVPMaybe:      0,
+			model.RSVPDeclined:   0,
+			model.RSVPWaitlisted: 0,
+		}
+		for _, rsvp := range rsvps {
+			counts[rsvp.Status]++
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, &response{Event: e, RSVPs: counts})
+	}
+}
+
+// handleEventsCalendar func. Handler func that exports the event in
+// iCalendar format
+func (s *server) handleEventsCalendar() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		e, ok := s.findEvent(w, r)
+		if !ok {
+			return
+		}
+
+		s.respondCalendar(w, r, e.Title, []*model.Event{e})
+	}
+}
+
+// handleEventsCreate func. Handler func that writes event hosted by the
+// actual user
+func (s *server) handleEventsCreate() http.HandlerFunc {
+	type request struct {
+		GameID      int        `json:"game_id"`
+		Title       string     `json:"title"`
+		Description string     `json:"description"`
+		StartsAt    time.Time  `json:"starts_at"`
+		EndsAt      time.Time  `json:"ends_at"`
+		Timezone    string     `json:"timezone"`
+		Capacity    int        `json:"capacity"`
+		Recurrence  string     `json:"recurrence"`
+		RepeatUntil *time.Time `json:"repeat_until"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		e := &model.Event{
+			HostID:      u.ID,
+			GameID:      req.GameID,
+			Title:       req.Title,
+			Description: req.Description,
+			StartsAt:    req.StartsAt,
+			EndsAt:      req.EndsAt,
+			Timezone:    req.Timezone,
+			Capacity:    req.Capacity,
+			Recurrence:  req.Recurrence,
+			RepeatUntil: req.RepeatUntil,
+		}
+		flags, ok := s.filterContent(w, r, s.textFilter, map[string]string{
+			"title":       e.Title,
+			"description": e.Description,
+		})
+		if !ok {
+			return
+		}
+
+		if err := s.store.Event().Create(r.Context(), e); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		s.reportFlagged(r, &model.Report{
+			TargetType:   model.ReportTargetUser,
+			TargetID:     u.ID,
+			TargetUserID: u.ID,
+			Excerpt:      e.Title + "\n" + e.Description,
+		}, flags)
+		// Creating response with status 201 (Event created)
+		s.respond(w, r, http.StatusCreated, e)
+	}
+}
+
+// handleEventsRSVP func. Handler func that RSVPs to the event for the
+// actual user. Users who want to go to the full event are waitlisted.
+// Users promoted from the waitlist are notified by the host with a
+// direct message.
+func (s *server) handleEventsRSVP() http.HandlerFunc {
+	type request struct {
+		Status string `json:"status"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		e, ok := s.findEvent(w, r)
+		if !ok {
+			return
+		}
+
+		changed, err := s.store.Event().RSVP(r.Context(), e.ID, u.ID, req.Status)
+		if err != nil {
+			s.eventError(w, r, err)
+			return
+		}
+		// Notifying promoted users, the RSVPs are kept even if it fails
+		for _, rsvp := range changed[1:] {
+			text := fmt.Sprintf("A place freed up, you're going to %q", e.Title)
+			if err := s.sendDirect(r, e.HostID, rsvp.UserID, text); err != nil {
+				logging.FromContext(r.Context()).Errorf("notifying user %d promoted to event %d: %v", rsvp.UserID, e.ID, err)
+			}
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, changed[0])
+	}
+}
+
+// handleMyEventsCalendar func. Handler func that exports events the
+// actual user goes to, maybe goes to or waits for in iCalendar format
+func (s *server) handleMyEventsCalendar() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		events, err := s.store.Event().FindByAttendee(r.Context(), u.ID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		s.respondCalendar(w, r, "My events", events)
+	}
+}
+
+// handleCalendarTokenCreate func. Handler func that issues a new secret
+// token of the calendar feed of the actual user, revoking the old one.
+// The token and URL of the feed are only shown in this response.
+func (s *server) handleCalendarTokenCreate() http.HandlerFunc {
+	type response struct {
+		*model.CalendarToken
+		URL string `json:"url"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		t, err := model.NewCalendarToken(u.ID)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		if err := s.store.Event().SetCalendarToken(r.Context(), t); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 201 (Token created)
+		s.respond(w, r, http.StatusCreated, &response{
+			CalendarToken: t,
+			URL:           fmt.Sprintf("/calendars/%s.ics", t.Token),
+		})
+	}
+}
+
+// handleCalendarTokenRevoke func. Handler func that revokes the token
+// of the calendar feed of the actual user, the feed stops being served.
+func (s *server) handleCalendarTokenRevoke() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		if err := s.store.Event().RevokeCalendarToken(r.Context(), u.ID); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleTokenCalendar func. Handler func that exports events of the
+// owner of the token from the path like handleMyEventsCalendar does,
+// so calendar applications can subscribe to them without a session.
+// Unknown and revoked tokens look missing.
+func (s *server) handleTokenCalendar() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t, err := s.store.Event().FindCalendarToken(r.Context(), model.HashCalendarToken(mux.Vars(r)["token"]))
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		events, err := s.store.Event().FindByAttendee(r.Context(), t.UserID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		s.respondCalendar(w, r, "My events", events)
+	}
+}
+
+// findEvent func. Finds the event from the request path. Events of
+// hosts hidden from the viewer look missing. Writes the error response
+// and returns false if the event can't be shown.
+func (s *server) findEvent(w http.ResponseWriter, r *http.Request) (*model.Event, bool) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		s.error(w, r, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	e, err := s.store.Event().Find(r.Context(), id)
+	if err != nil {
+		s.storeError(w, r, err)
+		return nil, false
+	}
+
+	if hidden, err := s.isHidden(r, s.sessionUserID(r), e.HostID); err != nil || hidden {
+		s.hiddenError(w, r, err)
+		return nil, false
+	}
+
+	return e, true
+}
+
+// respondCalendar func. Writes the events as iCalendar with status 200.
+// Events are shown in time zones of their hosts and weekly ones repeat.
+func (s *server) respondCalendar(w http.ResponseWriter, r *http.Request, name string, events []*model.Event) {
+	c := &ical.Calendar{
+		ProdID: calendarProdID,
+		Name:   name,
+	}
+	now := time.Now()
+	for _, e := range events {
+		ce := &ical.Event{
+			UID:         fmt.Sprintf("event-%d@tavern-of-games", e.ID),
+			Summary:     e.Title,
+			Description: e.Description,
+			Start:       e.StartsAt,
+			End:         e.EndsAt,
+			Location:    e.Location(),
+			Stamp:       now,
+		}
+		if e.Recurrence == model.EventWeekly {
+			var until time.Time
+			if e.RepeatUntil != nil {
+				until = *e.RepeatUntil
+			}
+			ce.RRule = ical.WeeklyRule(until)
+		}
+		c.Events = append(c.Events, ce)
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if _, err := c.WriteTo(w); err != nil {
+		logging.FromContext(r.Context()).Errorf("writing calendar: %v", err)
+	}
+}
+
+// eventError func. Creates an error response for event requests
+func (s *server) eventError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case model.ErrEventOver:
+		s.error(w, r, http.StatusConflict, err)
+	default:
+		s.storeError(w, r, err)
+	}
+}
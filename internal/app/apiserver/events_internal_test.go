@@ -0,0 +1,227 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleEvents(t *testing.T) {
+	store := teststore.New()
+	host := model.TestUser(t)
+	store.User().Create(context.Background(), host)
+	u1 := model.TestUser(t)
+	u1.Email = "user1@example.org"
+	store.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	store.User().Create(context.Background(), u2)
+	u3 := model.TestUser(t)
+	u3.Email = "user3@example.org"
+	store.User().Create(context.Background(), u3)
+	store.Friendship().Block(context.Background(), u3.ID, host.ID)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	startsAt := time.Now().UTC().Add(24 * time.Hour)
+
+	testCases := []struct {
+		name         string
+		user         *model.User
+		method       string
+		path         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name:   "create",
+			user:   host,
+			method: http.MethodPost,
+			path:   "/private/events",
+			payload: map[string]interface{}{
+				"game_id":    g.ID,
+				"title":      "Weekly game night",
+				"starts_at":  startsAt,
+				"ends_at":    startsAt.Add(3 * time.Hour),
+				"timezone":   "Europe/Berlin",
+				"capacity":   1,
+				"recurrence": model.EventWeekly,
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "create invalid timezone",
+			user:   host,
+			method: http.MethodPost,
+			path:   "/private/events",
+			payload: map[string]interface{}{
+				"game_id":   g.ID,
+				"title":     "Game night",
+				"starts_at": startsAt,
+				"ends_at":   startsAt.Add(3 * time.Hour),
+				"timezone":  "Europe/Atlantis",
+				"capacity":  4,
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "list",
+			user:         u1,
+			method:       http.MethodGet,
+			path:         "/events",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "get hidden by block",
+			user:         u3,
+			method:       http.MethodGet,
+			path:         "/events/1",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "rsvp invalid status",
+			user:         u1,
+			method:       http.MethodPut,
+			path:         "/private/events/1/rsvp",
+			payload:      map[string]interface{}{"status": model.RSVPWaitlisted},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "rsvp going",
+			user:         u1,
+			method:       http.MethodPut,
+			path:         "/private/events/1/rsvp",
+			payload:      map[string]interface{}{"status": model.RSVPGoing},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "rsvp going to full event",
+			user:         u2,
+			method:       http.MethodPut,
+			path:         "/private/events/1/rsvp",
+			payload:      map[string]interface{}{"status": model.RSVPGoing},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "rsvp declined",
+			user:         u1,
+			method:       http.MethodPut,
+			path:         "/private/events/1/rsvp",
+			payload:      map[string]interface{}{"status": model.RSVPDeclined},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "rsvp missing event",
+			user:         u1,
+			method:       http.MethodPut,
+			path:         "/private/events/2/rsvp",
+			payload:      map[string]interface{}{"status": model.RSVPGoing},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if tc.payload != nil {
+				json.NewEncoder(b).Encode(tc.payload)
+			}
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, b)
+			req.Header.Set("Cookie", testCookie(t, secretKey, tc.user.ID))
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	// The waitlisted user went instead of the declined one and was notified
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events/1", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"rsvps":{"declined":1,"going":1,"maybe":0,"waitlisted":0}`)
+
+	messages, err := store.Chat().FindByRoom(context.Background(), model.DirectChatRoom(host.ID, u2.ID), 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, messages, 1)
+
+	// The event is exported in the host's time zone repeating weekly
+	rec = httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events/1.ics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", rec.Header().Get("Content-Type"))
+	local := startsAt.In(mustLoadLocation(t, "Europe/Berlin")).Format("20060102T150405")
+	assert.Contains(t, rec.Body.String(), "DTSTART;TZID=Europe/Berlin:"+local+"\r\n")
+	assert.Contains(t, rec.Body.String(), "RRULE:FREQ=WEEKLY\r\n")
+
+	// Feeds of users have only events they haven't declined
+	for user, n := range map[*model.User]int{u1: 0, u2: 1} {
+		rec = httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/private/me/events.ics", nil)
+		req.Header.Set("Cookie", testCookie(t, secretKey, user.ID))
+		s.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, n, strings.Count(rec.Body.String(), "BEGIN:VEVENT"))
+	}
+
+	// Calendar applications subscribe to the feed by its token, until
+	// the token is replaced or revoked
+	issue := func() string {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/private/me/calendar-token", nil)
+		req.Header.Set("Cookie", testCookie(t, secretKey, u2.ID))
+		s.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		body := map[string]string{}
+		json.NewDecoder(rec.Body).Decode(&body)
+		assert.Equal(t, "/calendars/"+body["token"]+".ics", body["url"])
+
+		return body["url"]
+	}
+	feed := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+		return rec
+	}
+
+	old := issue()
+	rec = feed(old)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, strings.Count(rec.Body.String(), "BEGIN:VEVENT"))
+
+	url := issue()
+	assert.Equal(t, http.StatusNotFound, feed(old).Code)
+	assert.Equal(t, http.StatusOK, feed(url).Code)
+
+	rec = httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodDelete, "/private/me/calendar-token", nil)
+	req.Header.Set("Cookie", testCookie(t, secretKey, u2.ID))
+	s.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, http.StatusNotFound, feed(url).Code)
+	assert.Equal(t, http.StatusNotFound, feed("/calendars/0123.ics").Code)
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return loc
+}
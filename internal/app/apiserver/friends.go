@@ -0,0 +1,187 @@
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/gorilla/mux"
+)
+
+// handleFriendsList func. Handler func that returns friendships of the
+// actual user with the imported status.
+func (s *server) handleFriendsList(status string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		friendships, err := s.store.Friendship().FindByUser(r.Context(), u.ID, status)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, friendships)
+	}
+}
+
+// handleFriendsApply func. Handler func that applies friendship action
+// from the path (or the imported one, if it isn't empty) of the actual
+// user to the friendship with other user.
+func (s *server) handleFriendsApply(action string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		a := action
+		if a == "" {
+			a = mux.Vars(r)["action"]
+		}
+		// Applying the action through friendship state machine
+		f, err := s.store.Friendship().Apply(r.Context(), u.ID, id, a)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Feeds of both users are merged again with the new friends
+		s.timelines.Invalidate(u.ID, id)
+		// Notifying the user about the new friend request
+		if a == model.FriendshipRequest && f.Status == model.FriendshipPending {
+			s.notify(r, &model.Notification{
+				UserID: id,
+				Type:   model.NotificationFriendRequest,
+				Text:   fmt.Sprintf("User %d sent you a friend request", u.ID),
+				Link:   "/private/friends/requests",
+			})
+		}
+
+		if f == nil {
+			// Creating response with status 204 (No content)
+			s.respond(w, r, http.StatusNoContent, nil)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, f)
+	}
+}
+
+// handleFriendsMutual func. Handler func that returns ids of mutual
+// friends of the actual user and other user.
+func (s *server) handleFriendsMutual() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Blocked users are hidden from each other
+		if hidden, err := s.isHidden(r, u.ID, id); err != nil || hidden {
+			s.hiddenError(w, r, err)
+			return
+		}
+
+		ids, err := s.store.Friendship().Mutual(r.Context(), u.ID, id)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, ids)
+	}
+}
+
+// handleBlocksList func. Handler func that returns ids of users blocked
+// by the actual user.
+func (s *server) handleBlocksList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		ids, err := s.store.Friendship().Blocked(r.Context(), u.ID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, ids)
+	}
+}
+
+// handleBlocksCreate func. Handler func that blocks other user for the
+// actual user. Any friendship between them is removed.
+func (s *server) handleBlocksCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.store.Friendship().Block(r.Context(), u.ID, id); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Feeds of both users are merged again without each other
+		s.timelines.Invalidate(u.ID, id)
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleBlocksDelete func. Handler func that unblocks other user for
+// the actual user.
+func (s *server) handleBlocksDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.store.Friendship().Unblock(r.Context(), u.ID, id); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// isHidden func. Checks if the user is hidden from the viewer because
+// one of them blocked the other one. Zero viewer id means anonymous
+// viewer, who is never blocked.
+func (s *server) isHidden(r *http.Request, viewerID, userID int) (bool, error) {
+	if viewerID == 0 || viewerID == userID {
+		return false, nil
+	}
+
+	return s.store.Friendship().IsBlocked(r.Context(), viewerID, userID)
+}
+
+// hiddenError func. Creates an error response for a user hidden from
+// the viewer. Hidden users look exactly like missing ones.
+func (s *server) hiddenError(w http.ResponseWriter, r *http.Request, err error) {
+	if err != nil {
+		s.storeError(w, r, err)
+		return
+	}
+
+	s.storeError(w, r, store.ErrRecordNotFound)
+}
+
+// isFriend func. Checks if two users are accepted friends.
+func (s *server) isFriend(r *http.Request, userID, otherID int) (bool, error) {
+	f, err := s.store.Friendship().Find(r.Context(), userID, otherID)
+	if err == store.ErrRecordNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return f.Status == model.FriendshipAccepted, nil
+}
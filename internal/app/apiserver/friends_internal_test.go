@@ -0,0 +1,135 @@
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/sessions"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleFriendsApply(t *testing.T) {
+	store := teststore.New()
+	u1 := model.TestUser(t)
+	store.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	store.User().Create(context.Background(), u2)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+
+	testCases := []struct {
+		name         string
+		actor        *model.User
+		method       string
+		path         string
+		expectedCode int
+	}{
+		{
+			name:         "accept without request",
+			actor:        u2,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/friends/%d/accept", u1.ID),
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "request",
+			actor:        u1,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/friends/%d/request", u2.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "unknown action",
+			actor:        u2,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/friends/%d/poke", u1.ID),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "accept",
+			actor:        u2,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/friends/%d/accept", u1.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "unfriend",
+			actor:        u1,
+			method:       http.MethodDelete,
+			path:         fmt.Sprintf("/private/friends/%d", u2.ID),
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "block",
+			actor:        u2,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/friends/%d/block", u1.ID),
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "request blocking user",
+			actor:        u1,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/friends/%d/request", u2.ID),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "library of blocking user",
+			actor:        u1,
+			method:       http.MethodGet,
+			path:         fmt.Sprintf("/users/%d/library", u2.ID),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "mutual friends of blocking user",
+			actor:        u1,
+			method:       http.MethodGet,
+			path:         fmt.Sprintf("/private/friends/%d/mutual", u2.ID),
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, nil)
+			req.Header.Set("Cookie", testCookie(t, secretKey, tc.actor.ID))
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestServer_HandleUserLibraryGet_Friends(t *testing.T) {
+	store := teststore.New()
+	u1 := model.TestUser(t)
+	store.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	store.User().Create(context.Background(), u2)
+	store.Library().SetVisibility(context.Background(), u1.ID, model.LibraryVisibilityFriends)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	get := func() int {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/users/%d/library", u1.ID), nil)
+		req.Header.Set("Cookie", testCookie(t, secretKey, u2.ID))
+		s.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, get())
+	store.Friendship().Apply(context.Background(), u1.ID, u2.ID, model.FriendshipRequest)
+	store.Friendship().Apply(context.Background(), u2.ID, u1.ID, model.FriendshipAccept)
+	assert.Equal(t, http.StatusOK, get())
+}
@@ -0,0 +1,491 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+var (
+	errInvalidGameID = errors.New("invalid game_id")
+)
+
+// guildView object that describes the guild with its members
+type guildView struct {
+	*model.Guild
+	Members []*model.GuildMember `json:"members"`
+}
+
+// handleGuildsList func. Handler func that returns the page of guilds
+// ordered by name
+func (s *server) handleGuildsList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := parseListQuery(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		guilds, err := s.store.Guild().List(r.Context(), q)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, guilds)
+	}
+}
+
+// handleGuildsGet func. Handler func that returns the guild with its
+// members
+func (s *server) handleGuildsGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		g, err := s.store.Guild().Find(r.Context(), id)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		members, err := s.store.Guild().Members(r.Context(), id)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, &guildView{Guild: g, Members: members})
+	}
+}
+
+// handleGuildsRank func. Handler func that returns the place of the
+// guild in the guild leaderboard of the game from game_id query
+// parameter during the season
+func (s *server) handleGuildsRank() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		gameID, err := strconv.Atoi(r.URL.Query().Get("game_id"))
+		if err != nil || gameID < 1 {
+			s.error(w, r, http.StatusBadRequest, errInvalidGameID)
+			return
+		}
+		// Checking that the guild exists
+		if _, err := s.store.Guild().Find(r.Context(), id); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		e, err := s.store.Guild().Rank(r.Context(), id, gameID, leaderboardSeason(r))
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, e)
+	}
+}
+
+// handleGuildLeaderboard func. Handler func that returns the page of
+// the guild leaderboard of the game during the season
+func (s *server) handleGuildLeaderboard() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gameID, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		q, err := parseListQuery(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Checking that the game exists
+		if _, err := s.store.Game().Find(r.Context(), gameID); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		entries, err := s.store.Guild().Leaderboard(r.Context(), gameID, leaderboardSeason(r), q)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, entries)
+	}
+}
+
+// handleGuildsCreate func. Handler func that founds a guild led by the
+// actual user
+func (s *server) handleGuildsCreate() http.HandlerFunc {
+	type request struct {
+		Name        string `json:"name"`
+		Tag         string `json:"tag"`
+		Description string `json:"description"`
+		EmblemURL   string `json:"emblem_url"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		flags, ok := s.filterContent(w, r, s.textFilter, map[string]string{
+			"name":        req.Name,
+			"description": req.Description,
+		})
+		if !ok {
+			return
+		}
+
+		g := &model.Guild{
+			Name:        req.Name,
+			Tag:         req.Tag,
+			Description: req.Description,
+			EmblemURL:   req.EmblemURL,
+		}
+		if err := s.store.Guild().Create(r.Context(), g, u.ID); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		s.reportFlagged(r, &model.Report{
+			TargetType:   model.ReportTargetGuild,
+			TargetID:     g.ID,
+			TargetUserID: u.ID,
+			Excerpt:      g.Name + "\n" + g.Description,
+		}, flags)
+		// Creating response with status 201 (Guild created)
+		s.respond(w, r, http.StatusCreated, g)
+	}
+}
+
+// handleGuildsUpdate func. Handler func that changes the guild profile
+func (s *server) handleGuildsUpdate() http.HandlerFunc {
+	type request struct {
+		Name        string `json:"name"`
+		Tag         string `json:"tag"`
+		Description string `json:"description"`
+		EmblemURL   string `json:"emblem_url"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		access := r.Context().Value(ctxKeyGuildAccess).(*guildAccess)
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		flags, ok := s.filterContent(w, r, s.textFilter, map[string]string{
+			"name":        req.Name,
+			"description": req.Description,
+		})
+		if !ok {
+			return
+		}
+
+		g := *access.guild
+		g.Name = req.Name
+		g.Tag = req.Tag
+		g.Description = req.Description
+		g.EmblemURL = req.EmblemURL
+		if err := s.store.Guild().Update(r.Context(), &g); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		s.reportFlagged(r, &model.Report{
+			TargetType:   model.ReportTargetGuild,
+			TargetID:     g.ID,
+			TargetUserID: access.member.UserID,
+			Excerpt:      g.Name + "\n" + g.Description,
+		}, flags)
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, &g)
+	}
+}
+
+// handleGuildsDisband func. Handler func that deletes the guild
+func (s *server) handleGuildsDisband() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		access := r.Context().Value(ctxKeyGuildAccess).(*guildAccess)
+		if err := s.store.Guild().Delete(r.Context(), access.guild.ID); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleGuildsLeave func. Handler func that removes the actual user
+// from members of the guild. The leader has to transfer leadership or
+// disband the guild instead.
+func (s *server) handleGuildsLeave() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		access := r.Context().Value(ctxKeyGuildAccess).(*guildAccess)
+		if err := s.store.Guild().RemoveMember(r.Context(), access.guild.ID, access.member.UserID); err != nil {
+			s.guildError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleGuildsJoin func. Handler func that sends join request of the
+// actual user to the guild. If the user was invited, the invitation is
+// accepted and the user joins the guild right away.
+func (s *server) handleGuildsJoin() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		s.guildInvite(w, r, &model.GuildInvite{
+			GuildID: id,
+			UserID:  u.ID,
+			Kind:    model.GuildJoinRequest,
+		})
+	}
+}
+
+// handleGuildsJoinCancel func. Handler func that cancels join request
+// of the actual user to the guild or declines invitation of the guild
+func (s *server) handleGuildsJoinCancel() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.store.Guild().RemoveInvite(r.Context(), id, u.ID); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleGuildsInvitesList func. Handler func that returns pending
+// invitations and join requests of the guild
+func (s *server) handleGuildsInvitesList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		access := r.Context().Value(ctxKeyGuildAccess).(*guildAccess)
+		invites, err := s.store.Guild().Invites(r.Context(), access.guild.ID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, invites)
+	}
+}
+
+// handleGuildsInvite func. Handler func that invites the user to the
+// guild. If the user asked to join, the join request is accepted and
+// the user joins the guild right away. Users who blocked each other
+// look missing to each other.
+func (s *server) handleGuildsInvite() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		access := r.Context().Value(ctxKeyGuildAccess).(*guildAccess)
+		userID, err := pathInt(r, "user_id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if hidden, err := s.isHidden(r, access.member.UserID, userID); err != nil || hidden {
+			s.hiddenError(w, r, err)
+			return
+		}
+
+		s.guildInvite(w, r, &model.GuildInvite{
+			GuildID:   access.guild.ID,
+			UserID:    userID,
+			Kind:      model.GuildInvitation,
+			InviterID: access.member.UserID,
+		})
+	}
+}
+
+// handleGuildsInviteCancel func. Handler func that cancels invitation
+// of the user to the guild or declines join request of the user
+func (s *server) handleGuildsInviteCancel() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		access := r.Context().Value(ctxKeyGuildAccess).(*guildAccess)
+		userID, err := pathInt(r, "user_id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.store.Guild().RemoveInvite(r.Context(), access.guild.ID, userID); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleGuildsKick func. Handler func that removes the user from
+// members of the guild
+func (s *server) handleGuildsKick() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		access := r.Context().Value(ctxKeyGuildAccess).(*guildAccess)
+		if access.target == nil {
+			s.storeError(w, r, store.ErrRecordNotFound)
+			return
+		}
+
+		if err := s.store.Guild().RemoveMember(r.Context(), access.guild.ID, access.target.UserID); err != nil {
+			s.guildError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleGuildsSetRole func. Handler func that promotes the member of
+// the guild to an officer or demotes the officer to a member
+func (s *server) handleGuildsSetRole() http.HandlerFunc {
+	type request struct {
+		Role string `json:"role"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		access := r.Context().Value(ctxKeyGuildAccess).(*guildAccess)
+		if access.target == nil {
+			s.storeError(w, r, store.ErrRecordNotFound)
+			return
+		}
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		m := &model.GuildMember{
+			GuildID: access.guild.ID,
+			UserID:  access.target.UserID,
+			Role:    req.Role,
+		}
+		if err := s.store.Guild().SetRole(r.Context(), m); err != nil {
+			s.guildError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, m)
+	}
+}
+
+// handleGuildsTransfer func. Handler func that makes the member of the
+// guild its leader. The actual leader becomes an officer.
+func (s *server) handleGuildsTransfer() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		access := r.Context().Value(ctxKeyGuildAccess).(*guildAccess)
+		if access.target == nil {
+			s.storeError(w, r, store.ErrRecordNotFound)
+			return
+		}
+
+		if err := s.store.Guild().Transfer(r.Context(), access.guild.ID, access.member.UserID, access.target.UserID); err != nil {
+			s.guildError(w, r, err)
+			return
+		}
+
+		members, err := s.store.Guild().Members(r.Context(), access.guild.ID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, members)
+	}
+}
+
+// handleMyGuild func. Handler func that returns membership of the
+// actual user in a guild
+func (s *server) handleMyGuild() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		m, err := s.store.Guild().MemberOf(r.Context(), u.ID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, m)
+	}
+}
+
+// handleMyGuildInvites func. Handler func that returns pending
+// invitations and join requests of the actual user
+func (s *server) handleMyGuildInvites() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		invites, err := s.store.Guild().InvitesOf(r.Context(), u.ID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, invites)
+	}
+}
+
+// guildInvite func. Sends the invite and responds with the new
+// membership if the opposite invite was pending, or with the invite.
+func (s *server) guildInvite(w http.ResponseWriter, r *http.Request, i *model.GuildInvite) {
+	m, err := s.store.Guild().Invite(r.Context(), i)
+	if err != nil {
+		s.guildError(w, r, err)
+		return
+	}
+
+	if m != nil {
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, m)
+		return
+	}
+	// Creating response with status 201 (Invite created)
+	s.respond(w, r, http.StatusCreated, i)
+}
+
+// leaderboardSeason func. Returns the season from the season query
+// parameter, the actual season by default.
+func leaderboardSeason(r *http.Request) string {
+	if season := r.URL.Query().Get("season"); season != "" {
+		return season
+	}
+
+	return model.SeasonOf(time.Now())
+}
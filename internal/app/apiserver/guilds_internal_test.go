@@ -0,0 +1,271 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/sessions"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleGuilds(t *testing.T) {
+	store := teststore.New()
+	leader := model.TestUser(t)
+	store.User().Create(context.Background(), leader)
+	u1 := model.TestUser(t)
+	u1.Email = "user1@example.org"
+	store.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	store.User().Create(context.Background(), u2)
+	u3 := model.TestUser(t)
+	u3.Email = "user3@example.org"
+	store.User().Create(context.Background(), u3)
+	store.Friendship().Block(context.Background(), u3.ID, leader.ID)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	guild := map[string]interface{}{
+		"name": "Knights of the Round Table",
+		"tag":  "KRT",
+	}
+
+	testCases := []struct {
+		name         string
+		user         *model.User
+		method       string
+		path         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name:         "create",
+			user:         leader,
+			method:       http.MethodPost,
+			path:         "/private/guilds",
+			payload:      guild,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "create taken name",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         "/private/guilds",
+			payload:      guild,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "create invalid",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         "/private/guilds",
+			payload:      map[string]interface{}{"name": "Round Table", "tag": "rt"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "request to join",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         "/private/guilds/1/join",
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "edit by non member",
+			user:         u1,
+			method:       http.MethodPut,
+			path:         "/private/guilds/1",
+			payload:      guild,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "list invites",
+			user:         leader,
+			method:       http.MethodGet,
+			path:         "/private/guilds/1/invites",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "accept join request",
+			user:         leader,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/guilds/1/invites/%d", u1.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "invite",
+			user:         leader,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/guilds/1/invites/%d", u2.ID),
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "invite twice",
+			user:         leader,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/guilds/1/invites/%d", u2.ID),
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "invite by member",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/guilds/1/invites/%d", u3.ID),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "invite blocking user",
+			user:         leader,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/guilds/1/invites/%d", u3.ID),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "accept invitation",
+			user:         u2,
+			method:       http.MethodPost,
+			path:         "/private/guilds/1/join",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "promote",
+			user:         leader,
+			method:       http.MethodPut,
+			path:         fmt.Sprintf("/private/guilds/1/members/%d/role", u1.ID),
+			payload:      map[string]interface{}{"role": model.GuildRoleOfficer},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "promote to leader",
+			user:         leader,
+			method:       http.MethodPut,
+			path:         fmt.Sprintf("/private/guilds/1/members/%d/role", u2.ID),
+			payload:      map[string]interface{}{"role": model.GuildRoleLeader},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "promote by officer",
+			user:         u1,
+			method:       http.MethodPut,
+			path:         fmt.Sprintf("/private/guilds/1/members/%d/role", u2.ID),
+			payload:      map[string]interface{}{"role": model.GuildRoleOfficer},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "kick leader",
+			user:         u1,
+			method:       http.MethodDelete,
+			path:         fmt.Sprintf("/private/guilds/1/members/%d", leader.ID),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "guild chat",
+			user:         u2,
+			method:       http.MethodGet,
+			path:         "/private/chat/messages?room=" + model.GuildChatRoom(1),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "kick",
+			user:         u1,
+			method:       http.MethodDelete,
+			path:         fmt.Sprintf("/private/guilds/1/members/%d", u2.ID),
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "guild chat after kick",
+			user:         u2,
+			method:       http.MethodGet,
+			path:         "/private/chat/messages?room=" + model.GuildChatRoom(1),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "leader leaves",
+			user:         leader,
+			method:       http.MethodPost,
+			path:         "/private/guilds/1/leave",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "edit",
+			user:         u1,
+			method:       http.MethodPut,
+			path:         "/private/guilds/1",
+			payload:      map[string]interface{}{"name": "Round Table", "tag": "RT"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "disband by officer",
+			user:         u1,
+			method:       http.MethodDelete,
+			path:         "/private/guilds/1",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "transfer",
+			user:         leader,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/guilds/1/transfer/%d", u1.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "old leader leaves",
+			user:         leader,
+			method:       http.MethodPost,
+			path:         "/private/guilds/1/leave",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "guild leaderboard",
+			user:         u1,
+			method:       http.MethodGet,
+			path:         fmt.Sprintf("/games/%d/leaderboard/guilds", g.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "rank without ratings",
+			user:         u1,
+			method:       http.MethodGet,
+			path:         fmt.Sprintf("/guilds/1/rank?game_id=%d", g.ID),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "disband",
+			user:         u1,
+			method:       http.MethodDelete,
+			path:         "/private/guilds/1",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "get disbanded",
+			user:         u1,
+			method:       http.MethodGet,
+			path:         "/guilds/1",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if tc.payload != nil {
+				json.NewEncoder(b).Encode(tc.payload)
+			}
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, b)
+			req.Header.Set("Cookie", testCookie(t, secretKey, tc.user.ID))
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package apiserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/health"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/google/uuid"
+)
+
+// Timeouts of health checks of subsystems
+const (
+	dbCheckTimeout     = 2 * time.Second
+	brokerCheckTimeout = time.Second
+)
+
+// healthCheckRoom is the chat room the broker check publishes to, no
+// user can join it
+const healthCheckRoom = "health"
+
+var (
+	errShuttingDown       = errors.New("shutting down")
+	errBrokerUnsubscribed = errors.New("broker cancelled the subscription")
+)
+
+// handleHealthz func. Handler func that tells the process is up. It
+// doesn't check subsystems, so a slow DB doesn't get the process killed.
+func (s *server) handleHealthz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, map[string]string{"status": health.StatusOK})
+	}
+}
+
+// handleReadyz func. Handler func that tells whether the server can
+// serve requests: runs health checks of subsystems and reports their
+// results. The server isn't ready once it's draining before shutdown.
+func (s *server) handleReadyz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.draining() {
+			s.respond(w, r, http.StatusServiceUnavailable, map[string]string{
+				"status": health.StatusFail,
+				"error":  errShuttingDown.Error(),
+			})
+			return
+		}
+
+		report := s.health.Run(r.Context())
+		if !report.OK() {
+			s.respond(w, r, http.StatusServiceUnavailable, report)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, report)
+	}
+}
+
+// checkBroker func. Health check of the chat broker: a message published
+// to the health room has to reach its subscriber. Messages are told
+// apart by their text, brokers with a shared backend don't pass the
+// same pointer.
+func (s *server) checkBroker(ctx context.Context) error {
+	messages, unsubscribe := s.broker.Subscribe(healthCheckRoom)
+	defer unsubscribe()
+
+	m := &model.ChatMessage{Room: healthCheckRoom, Text: uuid.New().String(), CreatedAt: time.Now()}
+	if err := s.broker.Publish(m); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case got, ok := <-messages:
+			if !ok {
+				return errBrokerUnsubscribed
+			}
+			if got.Text == m.Text {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
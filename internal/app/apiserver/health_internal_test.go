@@ -0,0 +1,70 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/sessions"
+
+	"github.com/GShamian/tavern-of-games/internal/app/health"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleHealth(t *testing.T) {
+	s := newServer(teststore.New(), sessions.NewCookieStore([]byte("secret")))
+
+	get := func(path string) (int, map[string]interface{}) {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		s.ServeHTTP(rec, req)
+		body := map[string]interface{}{}
+		json.NewDecoder(rec.Body).Decode(&body)
+
+		return rec.Code, body
+	}
+
+	code, body := get("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusOK, body["status"])
+
+	code, body = get("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, health.StatusOK, body["status"])
+	assert.Contains(t, body["checks"], "chat_broker")
+
+	s.health.Register("db", 0, func(context.Context) error {
+		return errors.New("connection refused")
+	})
+	code, body = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, health.StatusFail, body["status"])
+	db := body["checks"].(map[string]interface{})["db"].(map[string]interface{})
+	assert.Equal(t, "connection refused", db["error"])
+
+	// The server isn't ready while it's shutting down, but it's alive
+	s.health.Register("db", 0, func(context.Context) error {
+		return nil
+	})
+	s.stop()
+	code, body = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, errShuttingDown.Error(), body["error"])
+	code, _ = get("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestServer_CheckBroker(t *testing.T) {
+	s := newServer(teststore.New(), sessions.NewCookieStore([]byte("secret")))
+	assert.NoError(t, s.checkBroker(context.Background()))
+
+	// Users can't join the room of the check
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	assert.Error(t, s.checkChatRoom(req, model.TestUser(t), healthCheckRoom))
+}
@@ -0,0 +1,323 @@
+package apiserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/logging"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// lfgExpiryInterval is how often expired LFG posts are marked
+const lfgExpiryInterval = time.Minute
+
+var (
+	errInvalidLFGFilter = errors.New("invalid lfg filter")
+	errNotLFGAuthor     = errors.New("not the author of the lfg post")
+)
+
+// handleLFGList func. Handler func that returns the page of open LFG
+// posts, soonest games first. Posts can be filtered by game_id,
+// platform, language and the time window from-to (RFC 3339) of the
+// game start. Posts of users hidden from the viewer are left out.
+func (s *server) handleLFGList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := parseListQuery(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		f, err := parseLFGFilter(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		posts, err := s.store.LFG().FindOpen(r.Context(), f, time.Now(), q)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Leaving out posts of users hidden from the viewer
+		viewerID := s.sessionUserID(r)
+		visible := posts[:0]
+		for _, p := range posts {
+			hidden, err := s.isHidden(r, viewerID, p.UserID)
+			if err != nil {
+				s.storeError(w, r, err)
+				return
+			}
+
+			if !hidden {
+				visible = append(visible, p)
+			}
+		}
+		posts = visible
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, posts)
+	}
+}
+
+// handleLFGGet func. Handler func that returns the LFG post
+func (s *server) handleLFGGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, ok := s.findLFGPost(w, r, s.sessionUserID(r))
+		if !ok {
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, p)
+	}
+}
+
+// handleLFGCreate func. Handler func that writes LFG post of the
+// actual user
+func (s *server) handleLFGCreate() http.HandlerFunc {
+	type request struct {
+		GameID      int       `json:"game_id"`
+		Platform    string    `json:"platform"`
+		Players     int       `json:"players"`
+		Language    string    `json:"language"`
+		SkillMin    int       `json:"skill_min"`
+		SkillMax    int       `json:"skill_max"`
+		VoiceChat   bool      `json:"voice_chat"`
+		Description string    `json:"description"`
+		StartsAt    time.Time `json:"starts_at"`
+		ExpiresAt   time.Time `json:"expires_at"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		p := &model.LFGPost{
+			UserID:      u.ID,
+			GameID:      req.GameID,
+			Platform:    req.Platform,
+			Players:     req.Players,
+			Language:    req.Language,
+			SkillMin:    req.SkillMin,
+			SkillMax:    req.SkillMax,
+			VoiceChat:   req.VoiceChat,
+			Description: req.Description,
+			StartsAt:    req.StartsAt,
+			ExpiresAt:   req.ExpiresAt,
+		}
+		flags, ok := s.filterContent(w, r, s.textFilter, map[string]string{"description": p.Description})
+		if !ok {
+			return
+		}
+
+		if err := s.store.LFG().Create(r.Context(), p); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		s.reportFlagged(r, &model.Report{
+			TargetType:   model.ReportTargetUser,
+			TargetID:     u.ID,
+			TargetUserID: u.ID,
+			Excerpt:      p.Description,
+		}, flags)
+		// Creating response with status 201 (Post created)
+		s.respond(w, r, http.StatusCreated, p)
+	}
+}
+
+// handleLFGClose func. Handler func that closes LFG post of the actual
+// user once the group is full
+func (s *server) handleLFGClose() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		p, ok := s.findLFGPost(w, r, u.ID)
+		if !ok {
+			return
+		}
+
+		if p.UserID != u.ID {
+			s.lfgError(w, r, errNotLFGAuthor)
+			return
+		}
+
+		if err := s.store.LFG().Close(r.Context(), p.ID); err != nil {
+			s.lfgError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleLFGRequestsList func. Handler func that returns requests to
+// join the group of LFG post of the actual user
+func (s *server) handleLFGRequestsList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		p, ok := s.findLFGPost(w, r, u.ID)
+		if !ok {
+			return
+		}
+
+		if p.UserID != u.ID {
+			s.lfgError(w, r, errNotLFGAuthor)
+			return
+		}
+
+		requests, err := s.store.LFG().Requests(r.Context(), p.ID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, requests)
+	}
+}
+
+// handleLFGRequestsCreate func. Handler func that asks to join the
+// group of the LFG post for the actual user. Skill rating of the user
+// in the game has to be in the range of the post. The author of the
+// post is notified with a direct message.
+func (s *server) handleLFGRequestsCreate() http.HandlerFunc {
+	type request struct {
+		Message string `json:"message"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity. The message is optional.
+		req := &request{}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+				s.error(w, r, http.StatusBadRequest, err)
+				return
+			}
+		}
+
+		p, ok := s.findLFGPost(w, r, u.ID)
+		if !ok {
+			return
+		}
+
+		skill, err := skillRating(r, s.store, u.ID, p.GameID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		if !p.InSkillRange(skill) {
+			s.lfgError(w, r, model.ErrSkillOutOfRange)
+			return
+		}
+
+		lr := &model.LFGRequest{
+			PostID:  p.ID,
+			UserID:  u.ID,
+			Message: req.Message,
+		}
+		if err := s.store.LFG().Request(r.Context(), lr); err != nil {
+			s.lfgError(w, r, err)
+			return
+		}
+		// Notifying the author, the request is kept even if it fails
+		text := fmt.Sprintf("Asked to join your group for LFG post %d", p.ID)
+		if lr.Message != "" {
+			text += ": " + lr.Message
+		}
+		if err := s.sendDirect(r, u.ID, p.UserID, text); err != nil {
+			logging.FromContext(r.Context()).Errorf("notifying author of lfg post %d: %v", p.ID, err)
+		}
+		// Creating response with status 201 (Request created)
+		s.respond(w, r, http.StatusCreated, lr)
+	}
+}
+
+// expireLFGPosts func. Background job that marks expired LFG posts
+func (s *server) expireLFGPosts(now time.Time) {
+	n, err := s.store.LFG().Expire(context.Background(), now)
+	if err != nil {
+		s.logger.Errorf("expiring lfg posts: %v", err)
+		return
+	}
+
+	if n > 0 {
+		s.logger.Infof("expired %d lfg posts", n)
+	}
+}
+
+// findLFGPost func. Finds the LFG post from the request path. Posts of
+// users hidden from the viewer look missing. Writes the error response
+// and returns false if the post can't be shown.
+func (s *server) findLFGPost(w http.ResponseWriter, r *http.Request, viewerID int) (*model.LFGPost, bool) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		s.error(w, r, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	p, err := s.store.LFG().Find(r.Context(), id)
+	if err != nil {
+		s.storeError(w, r, err)
+		return nil, false
+	}
+
+	if hidden, err := s.isHidden(r, viewerID, p.UserID); err != nil || hidden {
+		s.hiddenError(w, r, err)
+		return nil, false
+	}
+
+	return p, true
+}
+
+// lfgError func. Creates an error response for LFG requests
+func (s *server) lfgError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case errNotLFGAuthor:
+		s.error(w, r, http.StatusForbidden, err)
+	case model.ErrLFGPostClosed:
+		s.error(w, r, http.StatusConflict, err)
+	case model.ErrOwnLFGPost, model.ErrSkillOutOfRange:
+		s.error(w, r, http.StatusUnprocessableEntity, err)
+	default:
+		s.storeError(w, r, err)
+	}
+}
+
+// parseLFGFilter func. Parses filter query parameters of LFG board lists
+func parseLFGFilter(r *http.Request) (*store.LFGFilter, error) {
+	values := r.URL.Query()
+	f := &store.LFGFilter{
+		Platform: values.Get("platform"),
+		Language: values.Get("language"),
+	}
+
+	if v := values.Get("game_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id < 1 {
+			return nil, errInvalidLFGFilter
+		}
+		f.GameID = id
+	}
+
+	for name, t := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+		if v := values.Get(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return nil, errInvalidLFGFilter
+			}
+			*t = parsed
+		}
+	}
+
+	return f, nil
+}
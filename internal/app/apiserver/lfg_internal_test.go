@@ -0,0 +1,203 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleLFG(t *testing.T) {
+	store := teststore.New()
+	author := model.TestUser(t)
+	store.User().Create(context.Background(), author)
+	u1 := model.TestUser(t)
+	u1.Email = "user1@example.org"
+	store.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	store.User().Create(context.Background(), u2)
+	store.Friendship().Block(context.Background(), u2.ID, author.ID)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	startsAt := time.Now().UTC().Add(time.Hour)
+
+	testCases := []struct {
+		name         string
+		user         *model.User
+		method       string
+		path         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name:   "create",
+			user:   author,
+			method: http.MethodPost,
+			path:   "/private/lfg",
+			payload: map[string]interface{}{
+				"game_id":    g.ID,
+				"platform":   model.PlatformPC,
+				"players":    2,
+				"language":   "en",
+				"voice_chat": true,
+				"starts_at":  startsAt,
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "create for skilled players",
+			user:   author,
+			method: http.MethodPost,
+			path:   "/private/lfg",
+			payload: map[string]interface{}{
+				"game_id":   g.ID,
+				"platform":  model.PlatformXbox,
+				"players":   1,
+				"language":  "de",
+				"skill_min": 1800,
+				"starts_at": startsAt.Add(time.Hour),
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "create invalid",
+			user:   author,
+			method: http.MethodPost,
+			path:   "/private/lfg",
+			payload: map[string]interface{}{
+				"game_id":   g.ID,
+				"platform":  "dreamcast",
+				"players":   2,
+				"language":  "en",
+				"starts_at": startsAt,
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "list",
+			user:         u1,
+			method:       http.MethodGet,
+			path:         fmt.Sprintf("/lfg?game_id=%d&language=en", g.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "list invalid window",
+			user:         u1,
+			method:       http.MethodGet,
+			path:         "/lfg?from=tomorrow",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "get hidden by block",
+			user:         u2,
+			method:       http.MethodGet,
+			path:         "/lfg/1",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "request own post",
+			user:         author,
+			method:       http.MethodPost,
+			path:         "/private/lfg/1/requests",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "request",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         "/private/lfg/1/requests",
+			payload:      map[string]interface{}{"message": "Count me in"},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "request twice",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         "/private/lfg/1/requests",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "request out of skill range",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         "/private/lfg/2/requests",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "list requests of other post",
+			user:         u1,
+			method:       http.MethodGet,
+			path:         "/private/lfg/1/requests",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "list requests",
+			user:         author,
+			method:       http.MethodGet,
+			path:         "/private/lfg/1/requests",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "close other post",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         "/private/lfg/1/close",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "close",
+			user:         author,
+			method:       http.MethodPost,
+			path:         "/private/lfg/1/close",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "close twice",
+			user:         author,
+			method:       http.MethodPost,
+			path:         "/private/lfg/1/close",
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if tc.payload != nil {
+				json.NewEncoder(b).Encode(tc.payload)
+			}
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, b)
+			req.Header.Set("Cookie", testCookie(t, secretKey, tc.user.ID))
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	// The author was notified about the request with a direct message
+	messages, err := store.Chat().FindByRoom(context.Background(), model.DirectChatRoom(u1.ID, author.ID), 0, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, "Asked to join your group for LFG post 1: Count me in", messages[0].Text)
+	}
+
+	// Expiry job marks the second post once its game is over
+	s.expireLFGPosts(startsAt.Add(2*time.Hour + model.LFGExpiryDelay))
+	p, _ := store.LFG().Find(context.Background(), 2)
+	assert.Equal(t, model.LFGExpired, p.Status)
+}
@@ -0,0 +1,271 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+var (
+	errLibraryPrivate = errors.New("library is private")
+	errNullEntry      = errors.New("null library entry")
+)
+
+// libraryEntryRequest object. Describes library entry fields
+// that user can change.
+type libraryEntryRequest struct {
+	GameID      int     `json:"game_id"`
+	Status      string  `json:"status"`
+	HoursPlayed float64 `json:"hours_played"`
+	Notes       string  `json:"notes"`
+}
+
+// entry func. Creates library entry of the user from the request
+func (req *libraryEntryRequest) entry(userID int) *model.LibraryEntry {
+	return &model.LibraryEntry{
+		UserID:      userID,
+		GameID:      req.GameID,
+		Status:      req.Status,
+		HoursPlayed: req.HoursPlayed,
+		Notes:       req.Notes,
+	}
+}
+
+// handleLibraryList func. Handler func that returns library entries of
+// the actual user. Entries can be filtered with status query parameter.
+func (s *server) handleLibraryList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Finding entries of the user with the status from the query
+		entries, err := s.store.Library().FindByUser(r.Context(), u.ID, r.URL.Query().Get("status"))
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, entries)
+	}
+}
+
+// handleLibraryAdd func. Handler func that adds a game to the library
+// of the actual user.
+func (s *server) handleLibraryAdd() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := &libraryEntryRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Adding entry to the library
+		e := req.entry(u.ID)
+		if err := s.store.Library().Add(r.Context(), e); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		if e.Status == model.LibraryStatusPlaying {
+			s.recordStartedPlaying(r, e)
+		}
+		// Creating response with status 201 (Entry created)
+		s.respond(w, r, http.StatusCreated, e)
+	}
+}
+
+// handleLibraryUpdate func. Handler func that changes status, hours
+// played and notes of a game in the library of the actual user.
+func (s *server) handleLibraryUpdate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		gameID, err := pathInt(r, "game_id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Decoding json from request to our entity
+		req := &libraryEntryRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Game id from the path wins over the one from the body
+		req.GameID = gameID
+		e := req.entry(u.ID)
+		// Remembering the status to know if the user started playing
+		prev, err := s.store.Library().Find(r.Context(), u.ID, gameID)
+		if err != nil && err != store.ErrRecordNotFound {
+			s.storeError(w, r, err)
+			return
+		}
+		if err := s.store.Library().Update(r.Context(), e); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		if e.Status == model.LibraryStatusPlaying && (prev == nil || prev.Status != model.LibraryStatusPlaying) {
+			s.recordStartedPlaying(r, e)
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, e)
+	}
+}
+
+// handleLibraryRemove func. Handler func that removes a game from the
+// library of the actual user.
+func (s *server) handleLibraryRemove() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		gameID, err := pathInt(r, "game_id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.store.Library().Remove(r.Context(), u.ID, gameID); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleLibraryBulk func. Handler func that adds and removes several
+// games of the library of the actual user at once. Either every change
+// is applied or none of them.
+func (s *server) handleLibraryBulk() http.HandlerFunc {
+	type request struct {
+		Add    []*libraryEntryRequest `json:"add"`
+		Remove []int                  `json:"remove"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		add := make([]*model.LibraryEntry, 0, len(req.Add))
+		for _, e := range req.Add {
+			if e == nil {
+				s.error(w, r, http.StatusBadRequest, errNullEntry)
+				return
+			}
+			add = append(add, e.entry(u.ID))
+		}
+
+		if err := s.store.Library().Bulk(r.Context(), u.ID, add, req.Remove); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		for _, e := range add {
+			if e.Status == model.LibraryStatusPlaying {
+				s.recordStartedPlaying(r, e)
+			}
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, add)
+	}
+}
+
+// handleLibraryVisibility func. Handler func that changes who can see
+// the library of the actual user on the profile.
+func (s *server) handleLibraryVisibility() http.HandlerFunc {
+	type request struct {
+		Visibility string `json:"visibility"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.store.Library().SetVisibility(r.Context(), u.ID, req.Visibility); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, req)
+	}
+}
+
+// handleUserLibraryGet func. Handler func that returns read-only view of
+// the library of any user, if the user's visibility setting allows the
+// viewer to see it. Users who blocked each other can't see libraries
+// of each other at all.
+func (s *server) handleUserLibraryGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Checking that the user exists and isn't hidden from the viewer
+		if _, err := s.store.User().Find(r.Context(), id); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		viewerID := s.sessionUserID(r)
+		if hidden, err := s.isHidden(r, viewerID, id); err != nil || hidden {
+			s.hiddenError(w, r, err)
+			return
+		}
+		// Checking library visibility setting of the user
+		visible, err := s.libraryVisible(r, viewerID, id)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		if !visible {
+			s.error(w, r, http.StatusForbidden, errLibraryPrivate)
+			return
+		}
+
+		entries, err := s.store.Library().FindByUser(r.Context(), id, r.URL.Query().Get("status"))
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, entries)
+	}
+}
+
+// libraryVisible func. Checks if the viewer can see the library of the
+// user according to the user's library visibility setting.
+func (s *server) libraryVisible(r *http.Request, viewerID, userID int) (bool, error) {
+	if viewerID == userID {
+		return true, nil
+	}
+
+	visibility, err := s.store.Library().Visibility(r.Context(), userID)
+	if err != nil {
+		return false, err
+	}
+
+	switch visibility {
+	case model.LibraryVisibilityPublic:
+		return true, nil
+	case model.LibraryVisibilityFriends:
+		if viewerID == 0 {
+			return false, nil
+		}
+		return s.isFriend(r, viewerID, userID)
+	}
+
+	return false, nil
+}
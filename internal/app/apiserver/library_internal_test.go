@@ -0,0 +1,167 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleLibraryAdd(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	store.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	sc := securecookie.New(secretKey, nil)
+	cookieStr, _ := sc.Encode(sessionName, map[interface{}]interface{}{"user_id": u.ID})
+
+	testCases := []struct {
+		name         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name: "valid",
+			payload: map[string]interface{}{
+				"game_id": g.ID,
+				"status":  model.LibraryStatusPlaying,
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name: "already in library",
+			payload: map[string]interface{}{
+				"game_id": g.ID,
+				"status":  model.LibraryStatusPlaying,
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name: "unknown game",
+			payload: map[string]interface{}{
+				"game_id": g.ID + 1,
+				"status":  model.LibraryStatusPlaying,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "invalid params",
+			payload: map[string]interface{}{
+				"game_id": g.ID,
+				"status":  "borrowed",
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "invalid payload",
+			payload:      "invalid",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			json.NewEncoder(b).Encode(tc.payload)
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/private/me/library", b)
+			req.Header.Set("Cookie", fmt.Sprintf("%s=%s", sessionName, cookieStr))
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestServer_HandleLibraryBulk(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	store.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	sc := securecookie.New(secretKey, nil)
+	cookieStr, _ := sc.Encode(sessionName, map[interface{}]interface{}{"user_id": u.ID})
+
+	testCases := []struct {
+		name         string
+		payload      string
+		expectedCode int
+	}{
+		{
+			name:         "null entry",
+			payload:      `{"add":[null]}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "invalid payload",
+			payload:      `"invalid"`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "valid",
+			payload:      fmt.Sprintf(`{"add":[{"game_id":%d,"status":%q}]}`, g.ID, model.LibraryStatusPlaying),
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, "/private/me/library/bulk", bytes.NewBufferString(tc.payload))
+			req.Header.Set("Cookie", fmt.Sprintf("%s=%s", sessionName, cookieStr))
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestServer_HandleUserLibraryGet(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	store.User().Create(context.Background(), u)
+	s := newServer(store, sessions.NewCookieStore([]byte("secret")))
+
+	testCases := []struct {
+		name         string
+		visibility   string
+		expectedCode int
+	}{
+		{
+			name:         "public",
+			visibility:   model.LibraryVisibilityPublic,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "private",
+			visibility:   model.LibraryVisibilityPrivate,
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store.Library().SetVisibility(context.Background(), u.ID, tc.visibility)
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/users/%d/library", u.ID), nil)
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
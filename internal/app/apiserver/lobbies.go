@@ -0,0 +1,470 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/lobby"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/gorilla/websocket"
+)
+
+// matchmakingInterval is how often queued players are matched
+const matchmakingInterval = time.Second
+
+// handleLobbiesList func. Handler func that returns open public lobbies.
+// Lobbies can be filtered with game_id query parameter.
+func (s *server) handleLobbiesList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gameID := 0
+		if v := r.URL.Query().Get("game_id"); v != "" {
+			id, err := strconv.Atoi(v)
+			if err != nil {
+				s.error(w, r, http.StatusBadRequest, errInvalidPathParam)
+				return
+			}
+			gameID = id
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, s.lobbies.List(gameID))
+	}
+}
+
+// handleLobbiesCreate func. Handler func that creates a lobby hosted by
+// the actual user.
+func (s *server) handleLobbiesCreate() http.HandlerFunc {
+	type request struct {
+		GameID     int    `json:"game_id"`
+		MaxPlayers int    `json:"max_players"`
+		Visibility string `json:"visibility"`
+		Password   string `json:"password"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Checking that the game exists
+		if _, err := s.store.Game().Find(r.Context(), req.GameID); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		l, err := s.lobbies.Create(&model.Lobby{
+			GameID:     req.GameID,
+			MaxPlayers: req.MaxPlayers,
+			Visibility: req.Visibility,
+			Password:   req.Password,
+		}, u.ID)
+		if err != nil {
+			s.lobbyError(w, r, err)
+			return
+		}
+		// Creating response with status 201 (Lobby created)
+		s.respond(w, r, http.StatusCreated, l)
+	}
+}
+
+// handleLobbiesGet func. Handler func that returns the lobby
+func (s *server) handleLobbiesGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		l, err := s.lobbies.Get(id)
+		if err != nil {
+			s.lobbyError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, l)
+	}
+}
+
+// handleLobbiesJoin func. Handler func that adds the actual user to the
+// lobby. Lobbies with members who blocked the user look missing to the user.
+func (s *server) handleLobbiesJoin() http.HandlerFunc {
+	type request struct {
+		Password string `json:"password"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Password is optional, so is the body
+		req := &request{}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+				s.error(w, r, http.StatusBadRequest, err)
+				return
+			}
+		}
+		// Checking that no member of the lobby blocked the user
+		l, err := s.lobbies.Get(id)
+		if err != nil {
+			s.lobbyError(w, r, err)
+			return
+		}
+
+		for _, m := range l.Members {
+			if hidden, err := s.isHidden(r, u.ID, m.UserID); err != nil || hidden {
+				s.hiddenError(w, r, err)
+				return
+			}
+		}
+
+		l, err = s.lobbies.Join(id, u.ID, req.Password)
+		if err != nil {
+			s.lobbyError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, l)
+	}
+}
+
+// handleLobbiesLeave func. Handler func that removes the actual user
+// from the lobby.
+func (s *server) handleLobbiesLeave() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.lobbies.Leave(id, u.ID); err != nil {
+			s.lobbyError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleLobbiesKick func. Handler func that removes a member from the
+// lobby hosted by the actual user.
+func (s *server) handleLobbiesKick() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID, err := pathInt(r, "user_id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.lobbies.Kick(id, u.ID, userID); err != nil {
+			s.lobbyError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleLobbiesInvite func. Handler func that invites the user to the
+// open lobby the actual user is a member of. The invited user gets a
+// notification and still needs the password of the lobby to join.
+func (s *server) handleLobbiesInvite() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		userID, err := pathInt(r, "user_id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		l, err := s.lobbies.Get(id)
+		if err != nil {
+			s.lobbyError(w, r, err)
+			return
+		}
+
+		if l.Member(u.ID) == nil {
+			s.lobbyError(w, r, lobby.ErrNotMember)
+			return
+		}
+
+		if l.State != model.LobbyOpen {
+			s.lobbyError(w, r, lobby.ErrLobbyStarted)
+			return
+		}
+		if _, err := s.store.User().Find(r.Context(), userID); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Blocked users can't invite each other
+		if hidden, err := s.isHidden(r, u.ID, userID); err != nil || hidden {
+			s.hiddenError(w, r, err)
+			return
+		}
+
+		s.notify(r, &model.Notification{
+			UserID: userID,
+			Type:   model.NotificationLobbyInvite,
+			Text:   fmt.Sprintf("User %d invited you to lobby %d", u.ID, l.ID),
+			Link:   fmt.Sprintf("/private/lobbies/%d", l.ID),
+		})
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleLobbiesReady func. Handler func that changes ready flag of the
+// actual user in the lobby.
+func (s *server) handleLobbiesReady() http.HandlerFunc {
+	type request struct {
+		Ready bool `json:"ready"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		l, err := s.lobbies.SetReady(id, u.ID, req.Ready)
+		if err != nil {
+			s.lobbyError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, l)
+	}
+}
+
+// handleLobbiesStart func. Handler func that starts the lobby hosted by
+// the actual user.
+func (s *server) handleLobbiesStart() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		l, err := s.lobbies.Start(id, u.ID)
+		if err != nil {
+			s.lobbyError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, l)
+	}
+}
+
+// handleLobbiesSocket func. Handler func that upgrades the request to a
+// WebSocket connection that pushes lobby snapshots to the actual user
+// on every change, while the user is a member of the lobby.
+func (s *server) handleLobbiesSocket() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		updates, unsubscribe, err := s.lobbies.Subscribe(id)
+		if err != nil {
+			s.lobbyError(w, r, err)
+			return
+		}
+		defer unsubscribe()
+		// Only members can follow the lobby
+		l, err := s.lobbies.Get(id)
+		if err != nil || l.Member(u.ID) == nil {
+			s.lobbyError(w, r, lobby.ErrNotMember)
+			return
+		}
+		// Upgrader writes an error response itself if it fails
+		conn, err := s.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		kicked, disconnect := s.online.connect(u.ID)
+		defer disconnect()
+		// Reading the connection only to notice when the user leaves
+		go func() {
+			conn.SetReadLimit(chatMaxMessageSize)
+			conn.SetReadDeadline(time.Now().Add(chatPongWait))
+			conn.SetPongHandler(func(string) error {
+				return conn.SetReadDeadline(time.Now().Add(chatPongWait))
+			})
+			for {
+				if _, _, err := conn.NextReader(); err != nil {
+					unsubscribe()
+					return
+				}
+			}
+		}()
+
+		s.lobbyWriter(conn, u, updates, kicked)
+	}
+}
+
+// lobbyWriter func. The only writer of the lobby connection. Writes
+// lobby snapshots until the lobby starts or closes, the user stops being
+// a member, the connection fails, the user is disconnected or the server
+// stops.
+func (s *server) lobbyWriter(conn *websocket.Conn, u *model.User, updates <-chan *model.Lobby, kicked <-chan struct{}) {
+	ticker := time.NewTicker(chatPingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case l, ok := <-updates:
+			if !ok {
+				conn.WriteControl(websocket.CloseMessage, []byte{}, time.Now().Add(chatWriteWait))
+				return
+			}
+
+			conn.SetWriteDeadline(time.Now().Add(chatWriteWait))
+			if err := conn.WriteJSON(l); err != nil || l.Member(u.ID) == nil {
+				return
+			}
+		case <-kicked:
+			conn.WriteControl(websocket.CloseMessage, closeRestricted, time.Now().Add(chatWriteWait))
+			return
+		case <-s.quit:
+			conn.WriteControl(websocket.CloseMessage, closeGoingAway, time.Now().Add(chatWriteWait))
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(chatWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleMatchmakingEnqueue func. Handler func that puts the actual user
+// into the matchmaking queue of the game.
+func (s *server) handleMatchmakingEnqueue() http.HandlerFunc {
+	type request struct {
+		GameID  int `json:"game_id"`
+		Players int `json:"players"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Checking that the game exists
+		if _, err := s.store.Game().Find(r.Context(), req.GameID); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		// Matching players by their skill rating in the game
+		skill, err := skillRating(r, s.store, u.ID, req.GameID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		t := &lobby.Ticket{
+			UserID:  u.ID,
+			GameID:  req.GameID,
+			Players: req.Players,
+			Rating:  skill,
+		}
+		if err := s.matchmaker.Enqueue(t); err != nil {
+			s.lobbyError(w, r, err)
+			return
+		}
+		// Creating response with status 201 (Ticket created)
+		s.respond(w, r, http.StatusCreated, t)
+	}
+}
+
+// handleMatchmakingStatus func. Handler func that returns the ticket of
+// the actual user while queued, or the lobby the user was matched into.
+func (s *server) handleMatchmakingStatus() http.HandlerFunc {
+	type response struct {
+		Ticket  *lobby.Ticket `json:"ticket,omitempty"`
+		LobbyID int           `json:"lobby_id,omitempty"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		t, lobbyID, err := s.matchmaker.Status(u.ID)
+		if err != nil {
+			s.lobbyError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, &response{Ticket: t, LobbyID: lobbyID})
+	}
+}
+
+// handleMatchmakingCancel func. Handler func that takes the actual user
+// out of the matchmaking queue.
+func (s *server) handleMatchmakingCancel() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		if err := s.matchmaker.Cancel(u.ID); err != nil {
+			s.lobbyError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// lobbyError func. Creates an error response with status code that
+// matches the error returned by lobby manager or matchmaker.
+func (s *server) lobbyError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case lobby.ErrLobbyNotFound, lobby.ErrNotQueued:
+		s.error(w, r, http.StatusNotFound, err)
+	case lobby.ErrWrongPassword, lobby.ErrNotHost, lobby.ErrNotMember:
+		s.error(w, r, http.StatusForbidden, err)
+	case lobby.ErrLobbyFull, lobby.ErrLobbyStarted, lobby.ErrAlreadyInLobby, lobby.ErrAlreadyQueued, lobby.ErrNotReady:
+		s.error(w, r, http.StatusConflict, err)
+	default:
+		s.storeError(w, r, err)
+	}
+}
@@ -0,0 +1,192 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+	"github.com/gorilla/websocket"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleLobbies(t *testing.T) {
+	store := teststore.New()
+	host := model.TestUser(t)
+	store.User().Create(context.Background(), host)
+	guest := model.TestUser(t)
+	guest.Email = "user2@example.org"
+	store.User().Create(context.Background(), guest)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	l, _ := s.lobbies.Create(&model.Lobby{
+		GameID:     g.ID,
+		MaxPlayers: 2,
+		Visibility: model.LobbyPublic,
+		Password:   "secret",
+	}, host.ID)
+
+	testCases := []struct {
+		name         string
+		user         *model.User
+		path         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name:         "create for unknown game",
+			user:         guest,
+			path:         "/private/lobbies",
+			payload:      map[string]interface{}{"game_id": g.ID + 1, "max_players": 2, "visibility": "public"},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "create while in lobby",
+			user:         host,
+			path:         "/private/lobbies",
+			payload:      map[string]interface{}{"game_id": g.ID, "max_players": 2, "visibility": "public"},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "join with wrong password",
+			user:         guest,
+			path:         fmt.Sprintf("/private/lobbies/%d/join", l.ID),
+			payload:      map[string]interface{}{"password": "wrong"},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "join",
+			user:         guest,
+			path:         fmt.Sprintf("/private/lobbies/%d/join", l.ID),
+			payload:      map[string]interface{}{"password": "secret"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "start before ready",
+			user:         host,
+			path:         fmt.Sprintf("/private/lobbies/%d/start", l.ID),
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "ready",
+			user:         guest,
+			path:         fmt.Sprintf("/private/lobbies/%d/ready", l.ID),
+			payload:      map[string]interface{}{"ready": true},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "start by guest",
+			user:         guest,
+			path:         fmt.Sprintf("/private/lobbies/%d/start", l.ID),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "start",
+			user:         host,
+			path:         fmt.Sprintf("/private/lobbies/%d/start", l.ID),
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if tc.payload != nil {
+				json.NewEncoder(b).Encode(tc.payload)
+			}
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, tc.path, b)
+			req.Header.Set("Cookie", testCookie(t, secretKey, tc.user.ID))
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestServer_HandleLobbiesSocket(t *testing.T) {
+	store := teststore.New()
+	host := model.TestUser(t)
+	store.User().Create(context.Background(), host)
+	guest := model.TestUser(t)
+	guest.Email = "user2@example.org"
+	store.User().Create(context.Background(), guest)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	l, _ := s.lobbies.Create(model.TestLobby(t, 1), host.ID)
+	url := fmt.Sprintf("ws%s/private/lobbies/%d/ws", strings.TrimPrefix(srv.URL, "http"), l.ID)
+
+	header := http.Header{}
+	header.Set("Cookie", testCookie(t, secretKey, guest.ID))
+	_, resp, err := websocket.DefaultDialer.Dial(url, header)
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	header.Set("Cookie", testCookie(t, secretKey, host.ID))
+	conn, _, err := websocket.DefaultDialer.Dial(url, header)
+	assert.NoError(t, err)
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	snapshot := &model.Lobby{}
+	assert.NoError(t, conn.ReadJSON(snapshot))
+	assert.Len(t, snapshot.Members, 1)
+
+	s.lobbies.Join(l.ID, guest.ID, "")
+	assert.NoError(t, conn.ReadJSON(snapshot))
+	assert.Len(t, snapshot.Members, 2)
+}
+
+func TestServer_HandleMatchmaking(t *testing.T) {
+	store := teststore.New()
+	u1 := model.TestUser(t)
+	store.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	store.User().Create(context.Background(), u2)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	do := func(method string, u *model.User, payload interface{}) *httptest.ResponseRecorder {
+		b := &bytes.Buffer{}
+		if payload != nil {
+			json.NewEncoder(b).Encode(payload)
+		}
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, "/private/matchmaking", b)
+		req.Header.Set("Cookie", testCookie(t, secretKey, u.ID))
+		s.ServeHTTP(rec, req)
+		return rec
+	}
+
+	ticket := map[string]interface{}{"game_id": g.ID, "players": 2}
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, u1, nil).Code)
+	assert.Equal(t, http.StatusCreated, do(http.MethodPost, u1, ticket).Code)
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, u1, ticket).Code)
+	assert.Equal(t, http.StatusCreated, do(http.MethodPost, u2, ticket).Code)
+	s.matchmaker.Tick(time.Now())
+
+	status := map[string]interface{}{}
+	rec := do(http.MethodGet, u2, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	json.NewDecoder(rec.Body).Decode(&status)
+	assert.NotZero(t, status["lobby_id"])
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, u2, nil).Code)
+}
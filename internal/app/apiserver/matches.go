@@ -0,0 +1,327 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/rating"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+var (
+	errLobbyNotStarted = errors.New("lobby hasn't started")
+	errLobbyMismatch   = errors.New("match doesn't match the lobby")
+)
+
+// handleMatchesCreate func. Handler func that reports result of a match
+// played by the actual user. If the match was played in a lobby, the
+// lobby has to be started and its members have to be the players. The
+// lobby is closed once its result is reported.
+func (s *server) handleMatchesCreate() http.HandlerFunc {
+	type request struct {
+		GameID  int    `json:"game_id"`
+		LobbyID int    `json:"lobby_id"`
+		SideA   []int  `json:"side_a"`
+		SideB   []int  `json:"side_b"`
+		Winner  string `json:"winner"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		m := &model.Match{
+			GameID:     req.GameID,
+			LobbyID:    req.LobbyID,
+			SideA:      req.SideA,
+			SideB:      req.SideB,
+			Winner:     req.Winner,
+			ReportedBy: u.ID,
+		}
+		// Checking the match against the lobby it was played in
+		if m.LobbyID != 0 {
+			l, err := s.lobbies.Get(m.LobbyID)
+			if err != nil {
+				s.lobbyError(w, r, err)
+				return
+			}
+			if l.State != model.LobbyStarted {
+				s.error(w, r, http.StatusConflict, errLobbyNotStarted)
+				return
+			}
+			if !matchPlayedIn(m, l) {
+				s.error(w, r, http.StatusUnprocessableEntity, errLobbyMismatch)
+				return
+			}
+		}
+
+		if err := s.store.Match().Create(r.Context(), m); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		if m.LobbyID != 0 {
+			s.lobbies.Close(m.LobbyID)
+		}
+		// Creating response with status 201 (Match created)
+		s.respond(w, r, http.StatusCreated, m)
+	}
+}
+
+// handleMatchesGet func. Handler func that returns the match. Matches
+// are visible to their players and moderators only.
+func (s *server) handleMatchesGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m, ok := s.findMatch(w, r)
+		if !ok {
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, m)
+	}
+}
+
+// handleMatchesConfirm func. Handler func that confirms the reported
+// result on behalf of the other side. Confirmed results update ratings.
+func (s *server) handleMatchesConfirm() http.HandlerFunc {
+	return s.handleMatchesSettle((*model.Match).Confirm)
+}
+
+// handleMatchesDispute func. Handler func that disputes the reported
+// result on behalf of the other side. Disputes are resolved by moderators.
+func (s *server) handleMatchesDispute() http.HandlerFunc {
+	return s.handleMatchesSettle((*model.Match).Dispute)
+}
+
+// handleMatchesSettle func. Handler func that applies the imported
+// action of the actual user to the match
+func (s *server) handleMatchesSettle(action func(*model.Match, int) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		m, ok := s.findMatch(w, r)
+		if !ok {
+			return
+		}
+
+		if err := action(m, u.ID); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		if err := s.store.Match().Update(r.Context(), m); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		s.recordMatch(r, m)
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, m)
+	}
+}
+
+// handleAdminMatchesList func. Handler func that returns matches with
+// the status from status query parameter, disputed ones by default.
+func (s *server) handleAdminMatchesList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := parseListQuery(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		status := r.URL.Query().Get("status")
+		if status == "" {
+			status = model.MatchDisputed
+		}
+
+		matches, err := s.store.Match().FindByStatus(r.Context(), status, q)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, matches)
+	}
+}
+
+// handleAdminMatchesResolve func. Handler func that lets a moderator
+// decide the winner of an unsettled match
+func (s *server) handleAdminMatchesResolve() http.HandlerFunc {
+	type request struct {
+		Winner string `json:"winner"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		m, ok := s.findMatch(w, r)
+		if !ok {
+			return
+		}
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := m.Resolve(req.Winner); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		if err := s.store.Match().Update(r.Context(), m); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		s.recordMatch(r, m)
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, m)
+	}
+}
+
+// handleLeaderboard func. Handler func that returns the page of the
+// game leaderboard. The season query parameter defaults to the current
+// season. With around=me the page is centered on the session user.
+func (s *server) handleLeaderboard() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		gameID, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		q, err := parseListQuery(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Checking that the game exists
+		if _, err := s.store.Game().Find(r.Context(), gameID); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		season := leaderboardSeason(r)
+		// Moving the page to the players around the session user
+		if r.URL.Query().Get("around") == "me" {
+			userID := s.sessionUserID(r)
+			if userID == 0 {
+				s.error(w, r, http.StatusUnauthorized, errNotAuthenticated)
+				return
+			}
+
+			rank, err := s.store.Rating().Rank(r.Context(), userID, gameID, season)
+			if err != nil {
+				s.storeError(w, r, err)
+				return
+			}
+			q.Offset = rank - 1 - q.Limit/2
+			if q.Offset < 0 {
+				q.Offset = 0
+			}
+		}
+
+		entries, err := s.store.Rating().Leaderboard(r.Context(), gameID, season, q)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, entries)
+	}
+}
+
+// findMatch func. Finds the match from the request path. Matches of
+// other players look missing to users who aren't moderators. Writes
+// the error response and returns false if the match can't be shown.
+func (s *server) findMatch(w http.ResponseWriter, r *http.Request) (*model.Match, bool) {
+	u := r.Context().Value(ctxKeyUser).(*model.User)
+	id, err := pathInt(r, "id")
+	if err != nil {
+		s.error(w, r, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	m, err := s.store.Match().Find(r.Context(), id)
+	if err == nil && m.Side(u.ID) == "" && !u.HasRole(model.RoleModerator) {
+		err = store.ErrRecordNotFound
+	}
+	if err != nil {
+		s.storeError(w, r, err)
+		return nil, false
+	}
+
+	return m, true
+}
+
+// recordMatch func. Records events of players of the match, once its
+// result is final and rated
+func (s *server) recordMatch(r *http.Request, m *model.Match) {
+	if m.RatedAt == nil {
+		return
+	}
+
+	winners := []int{}
+	switch m.Winner {
+	case model.MatchWinnerA:
+		winners = m.SideA
+	case model.MatchWinnerB:
+		winners = m.SideB
+	}
+
+	for _, id := range m.Players() {
+		s.recordEvent(r, &model.UserEvent{UserID: id, Type: model.EventMatchPlayed, GameID: m.GameID})
+	}
+	for _, id := range winners {
+		s.recordEvent(r, &model.UserEvent{UserID: id, Type: model.EventMatchWon, GameID: m.GameID})
+	}
+}
+
+// skillRating func. Returns the user's rating in the game during the
+// current season read from the imported store, or the default rating of
+// new players.
+func skillRating(r *http.Request, st store.Store, userID, gameID int) (int, error) {
+	rt, err := st.Rating().Find(r.Context(), userID, gameID, model.SeasonOf(time.Now()))
+	switch err {
+	case nil:
+		return int(math.Round(rt.Rating)), nil
+	case store.ErrRecordNotFound:
+		return rating.DefaultRating, nil
+	}
+
+	return 0, err
+}
+
+// matchPlayedIn func. Checks that the match is of the lobby game and
+// its players are exactly the lobby members
+func matchPlayedIn(m *model.Match, l *model.Lobby) bool {
+	if m.GameID != l.GameID {
+		return false
+	}
+
+	players := m.Players()
+	if len(players) != len(l.Members) {
+		return false
+	}
+
+	members := make([]int, len(l.Members))
+	for i, member := range l.Members {
+		members[i] = member.UserID
+	}
+	sort.Ints(players)
+	sort.Ints(members)
+	for i := range players {
+		if players[i] != members[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,271 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/sessions"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleMatches(t *testing.T) {
+	store := teststore.New()
+	u1 := model.TestUser(t)
+	store.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	store.User().Create(context.Background(), u2)
+	outsider := model.TestUser(t)
+	outsider.Email = "user3@example.org"
+	store.User().Create(context.Background(), outsider)
+	moderator := model.TestUser(t)
+	moderator.Email = "moderator@example.org"
+	moderator.Role = model.RoleModerator
+	store.User().Create(context.Background(), moderator)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	l, _ := s.lobbies.Create(model.TestLobby(t, g.ID), u1.ID)
+	started, _ := s.lobbies.Create(model.TestLobby(t, g.ID), u2.ID)
+	s.lobbies.Join(started.ID, moderator.ID, "")
+	s.lobbies.SetReady(started.ID, moderator.ID, true)
+	s.lobbies.Start(started.ID, u2.ID)
+	confirmed := model.TestMatch(t, g.ID, u1.ID, u2.ID)
+	store.Match().Create(context.Background(), confirmed)
+	disputed := model.TestMatch(t, g.ID, u1.ID, u2.ID)
+	store.Match().Create(context.Background(), disputed)
+
+	testCases := []struct {
+		name         string
+		user         *model.User
+		method       string
+		path         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name:         "report",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         "/private/matches",
+			payload:      map[string]interface{}{"game_id": g.ID, "side_a": []int{u1.ID}, "side_b": []int{u2.ID}, "winner": "a"},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "report for others",
+			user:         outsider,
+			method:       http.MethodPost,
+			path:         "/private/matches",
+			payload:      map[string]interface{}{"game_id": g.ID, "side_a": []int{u1.ID}, "side_b": []int{u2.ID}, "winner": "a"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "report for open lobby",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         "/private/matches",
+			payload:      map[string]interface{}{"game_id": g.ID, "lobby_id": l.ID, "side_a": []int{u1.ID}, "side_b": []int{u2.ID}, "winner": "a"},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "report for started lobby",
+			user:         u2,
+			method:       http.MethodPost,
+			path:         "/private/matches",
+			payload:      map[string]interface{}{"game_id": g.ID, "lobby_id": started.ID, "side_a": []int{u2.ID}, "side_b": []int{moderator.ID}, "winner": "a"},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "report for started lobby twice",
+			user:         u2,
+			method:       http.MethodPost,
+			path:         "/private/matches",
+			payload:      map[string]interface{}{"game_id": g.ID, "lobby_id": started.ID, "side_a": []int{u2.ID}, "side_b": []int{moderator.ID}, "winner": "a"},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "get by outsider",
+			user:         outsider,
+			method:       http.MethodGet,
+			path:         fmt.Sprintf("/private/matches/%d", confirmed.ID),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "get by moderator",
+			user:         moderator,
+			method:       http.MethodGet,
+			path:         fmt.Sprintf("/private/matches/%d", confirmed.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "confirm by reporter",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/matches/%d/confirm", confirmed.ID),
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "confirm",
+			user:         u2,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/matches/%d/confirm", confirmed.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "dispute confirmed",
+			user:         u2,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/matches/%d/dispute", confirmed.ID),
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "dispute",
+			user:         u2,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/matches/%d/dispute", disputed.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "list disputes by player",
+			user:         u1,
+			method:       http.MethodGet,
+			path:         "/private/admin/matches",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "list disputes",
+			user:         moderator,
+			method:       http.MethodGet,
+			path:         "/private/admin/matches?status=disputed",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "resolve with unknown winner",
+			user:         moderator,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/admin/matches/%d/resolve", disputed.ID),
+			payload:      map[string]interface{}{"winner": "c"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "resolve",
+			user:         moderator,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/admin/matches/%d/resolve", disputed.ID),
+			payload:      map[string]interface{}{"winner": "draw"},
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if tc.payload != nil {
+				json.NewEncoder(b).Encode(tc.payload)
+			}
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, b)
+			req.Header.Set("Cookie", testCookie(t, secretKey, tc.user.ID))
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	rt, err := store.Rating().Find(context.Background(), u1.ID, g.ID, confirmed.Season)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, rt.Matches)
+}
+
+func TestServer_HandleLeaderboard(t *testing.T) {
+	store := teststore.New()
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+	users := make([]*model.User, 5)
+	for i := range users {
+		users[i] = model.TestUser(t)
+		users[i].Email = fmt.Sprintf("user%d@example.org", i)
+		store.User().Create(context.Background(), users[i])
+	}
+	// Every user beats the next one
+	for i := 0; i < len(users)-1; i++ {
+		m := model.TestMatch(t, g.ID, users[i].ID, users[i+1].ID)
+		store.Match().Create(context.Background(), m)
+		m.Confirm(users[i+1].ID)
+		store.Match().Update(context.Background(), m)
+	}
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+
+	testCases := []struct {
+		name          string
+		user          *model.User
+		query         string
+		expectedCode  int
+		expectedRanks []int
+	}{
+		{
+			name:          "first page",
+			query:         "?limit=2",
+			expectedCode:  http.StatusOK,
+			expectedRanks: []int{1, 2},
+		},
+		{
+			name:          "around me",
+			user:          users[3],
+			query:         "?limit=3&around=me",
+			expectedCode:  http.StatusOK,
+			expectedRanks: []int{3, 4, 5},
+		},
+		{
+			name:         "around me anonymously",
+			query:        "?around=me",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "past season",
+			query:         "?season=2000-Q1",
+			expectedCode:  http.StatusOK,
+			expectedRanks: []int{},
+		},
+		{
+			name:         "invalid limit",
+			query:        "?limit=0",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/games/%d/leaderboard%s", g.ID, tc.query), nil)
+			if tc.user != nil {
+				req.Header.Set("Cookie", testCookie(t, secretKey, tc.user.ID))
+			}
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+			if tc.expectedRanks == nil {
+				return
+			}
+
+			entries := []*model.LeaderboardEntry{}
+			json.NewDecoder(rec.Body).Decode(&entries)
+			ranks := []int{}
+			for _, e := range entries {
+				ranks = append(ranks, e.Rank)
+			}
+			assert.Equal(t, tc.expectedRanks, ranks)
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package apiserver
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/metrics"
+	"github.com/gorilla/mux"
+)
+
+// Results of logins counted by metrics
+const (
+	loginSuccess = "success"
+	loginFailure = "failure"
+)
+
+// serverMetrics object. Describes metrics of the server exposed on the
+// admin listener.
+type serverMetrics struct {
+	registry *metrics.Registry
+	requests *metrics.Counter
+	latency  *metrics.Histogram
+	logins   *metrics.Counter
+}
+
+// newServerMetrics func. Constructor for metrics of the server: requests
+// by route and status, logins by result, and gauges of online users and
+// open lobbies.
+func newServerMetrics(s *server) *serverMetrics {
+	r := metrics.NewRegistry()
+	m := &serverMetrics{
+		registry: r,
+		requests: r.NewCounter("http_requests_total", "Requests served by route template and status.", "route", "code"),
+		latency:  r.NewHistogram("http_request_duration_seconds", "Latency of requests by route template.", nil, "route"),
+		logins:   r.NewCounter("logins_total", "Logins by result.", "result"),
+	}
+	r.NewGaugeFunc("online_users", "Users with open chat, lobby or notification streams.", func() float64 {
+		return float64(s.online.count())
+	})
+	r.NewGaugeFunc("open_lobbies", "Lobbies waiting for players.", func() float64 {
+		return float64(s.lobbies.Count())
+	})
+
+	return m
+}
+
+// registerDBStats func. Registers stats of the DB connection pool
+func (m *serverMetrics) registerDBStats(db *sql.DB) {
+	r := m.registry
+	r.NewGaugeFunc("db_max_open_connections", "Maximum number of open connections to the DB.", func() float64 {
+		return float64(db.Stats().MaxOpenConnections)
+	})
+	r.NewGaugeFunc("db_open_connections", "Established connections to the DB.", func() float64 {
+		return float64(db.Stats().OpenConnections)
+	})
+	r.NewGaugeFunc("db_in_use_connections", "Connections to the DB in use.", func() float64 {
+		return float64(db.Stats().InUse)
+	})
+	r.NewGaugeFunc("db_idle_connections", "Idle connections to the DB.", func() float64 {
+		return float64(db.Stats().Idle)
+	})
+	r.NewCounterFunc("db_wait_count_total", "Connections to the DB waited for.", func() float64 {
+		return float64(db.Stats().WaitCount)
+	})
+	r.NewCounterFunc("db_wait_duration_seconds_total", "Time spent waiting for connections to the DB.", func() float64 {
+		return db.Stats().WaitDuration.Seconds()
+	})
+}
+
+// measureRequest func. Middleware func for http handler, that
+// counts requests and their latencies by route template and
+// status. Requests which match no route aren't measured.
+func (s *server) measureRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routeTemplate(r)
+		start := time.Now()
+		rw := &responseWriter{w, http.StatusOK}
+		next.ServeHTTP(rw, r)
+
+		s.metrics.requests.Inc(route, strconv.Itoa(rw.code))
+		s.metrics.latency.Observe(time.Since(start).Seconds(), route)
+	})
+}
+
+// adminHandler func. Returns handler of the admin listener, it serves
+// metrics of the server
+func (s *server) adminHandler() http.Handler {
+	router := mux.NewRouter()
+	router.Handle("/metrics", s.metrics.registry.Handler()).Methods("GET")
+
+	return router
+}
+
+// presence object. Tracks open streams of users, users with at least
+// one open stream are online. Streams of a user can be disconnected,
+// e.g. when the user is suspended. It is safe for concurrent use.
+type presence struct {
+	mu      sync.Mutex
+	streams map[int]map[chan struct{}]struct{}
+}
+
+// newPresence func. Constructor for presence
+func newPresence() *presence {
+	return &presence{
+		streams: make(map[int]map[chan struct{}]struct{}),
+	}
+}
+
+// connect func. Counts the stream of the user opened. Returns channel
+// closed when the stream has to be disconnected and func that counts the
+// stream closed.
+func (p *presence) connect(userID int) (<-chan struct{}, func()) {
+	ch := make(chan struct{})
+
+	p.mu.Lock()
+	if p.streams[userID] == nil {
+		p.streams[userID] = make(map[chan struct{}]struct{})
+	}
+	p.streams[userID][ch] = struct{}{}
+	p.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+
+			delete(p.streams[userID], ch)
+			if len(p.streams[userID]) == 0 {
+				delete(p.streams, userID)
+			}
+		})
+	}
+}
+
+// disconnect func. Signals every open stream of the user to close
+func (p *presence) disconnect(userID int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for ch := range p.streams[userID] {
+		close(ch)
+	}
+	delete(p.streams, userID)
+}
+
+// count func. Returns number of online users
+func (p *presence) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.streams)
+}
@@ -0,0 +1,111 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+
+	"github.com/GShamian/tavern-of-games/internal/app/metrics"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Metrics(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	store.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	db, err := sql.Open("postgres", "host=localhost dbname=metrics_test sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s.metrics.registerDBStats(db)
+	s.lobbies.Create(model.TestLobby(t, g.ID), u.ID)
+
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	admin := httptest.NewServer(s.adminHandler())
+	defer admin.Close()
+
+	login := func(password string) {
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(map[string]string{"email": u.Email, "password": password})
+		res, err := http.Post(srv.URL+"/sessions", "application/json", b)
+		if assert.NoError(t, err) {
+			res.Body.Close()
+		}
+	}
+	login(u.Password)
+	login("wrong password")
+	login("wrong password")
+	for _, path := range []string{"/games/1", "/games/2", "/no/such/route"} {
+		res, err := http.Get(srv.URL + path)
+		if assert.NoError(t, err) {
+			res.Body.Close()
+		}
+	}
+
+	// Users with open streams are online
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/private/notifications/stream", nil)
+	req.Header.Set("Cookie", testCookie(t, secretKey, u.ID))
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+
+	scrape := func() string {
+		res, err := http.Get(admin.URL + "/metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, metrics.ContentType, res.Header.Get("Content-Type"))
+		body, _ := ioutil.ReadAll(res.Body)
+
+		return string(body)
+	}
+
+	var body string
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if body = scrape(); bytes.Contains([]byte(body), []byte("online_users 1\n")) {
+			break
+		}
+	}
+	assert.Contains(t, body, `http_requests_total{route="/games/{id:[0-9]+}",code="200"} 1`)
+	assert.Contains(t, body, `http_requests_total{route="/games/{id:[0-9]+}",code="404"} 1`)
+	assert.Contains(t, body, `http_requests_total{route="/sessions",code="401"} 2`)
+	assert.NotContains(t, body, "/no/such/route")
+	assert.Contains(t, body, `http_request_duration_seconds_count{route="/sessions"} 3`)
+	assert.Contains(t, body, `logins_total{result="success"} 1`)
+	assert.Contains(t, body, `logins_total{result="failure"} 2`)
+	assert.Contains(t, body, "online_users 1\n")
+	assert.Contains(t, body, "open_lobbies 1\n")
+	assert.Contains(t, body, "db_open_connections 0\n")
+	assert.Contains(t, body, "# TYPE db_wait_count_total counter\n")
+
+	// Closing the stream takes the user offline
+	stream.Body.Close()
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if body = scrape(); bytes.Contains([]byte(body), []byte("online_users 0\n")) {
+			break
+		}
+	}
+	assert.Contains(t, body, "online_users 0\n")
+}
@@ -0,0 +1,85 @@
+package apiserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/migrate"
+)
+
+var errMigrateUsage = errors.New("usage: migrate up|down|status|goto VERSION")
+
+// Migrate func. Runs migrate command of args on the DB of config and
+// writes the status of the schema to out: up applies all pending
+// migrations, down reverts the last applied one, goto moves the schema
+// to the version, zero one is the schema without migrations, and status
+// only writes the status.
+func Migrate(config *Config, args []string, out io.Writer) error {
+	var version int64
+	switch {
+	case len(args) == 1 && (args[0] == "up" || args[0] == "down" || args[0] == "status"):
+	case len(args) == 2 && args[0] == "goto":
+		v, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return errMigrateUsage
+		}
+		version = v
+	default:
+		return errMigrateUsage
+	}
+
+	db, err := newDB(config.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := newMigrator(config, db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "goto":
+		err = migrator.Goto(ctx, version)
+	}
+	if err != nil {
+		return err
+	}
+
+	status, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	return writeMigrateStatus(out, status)
+}
+
+// writeMigrateStatus func. Writing the version of the schema and
+// pending migrations
+func writeMigrateStatus(out io.Writer, status *migrate.Status) error {
+	dirty := ""
+	if status.Dirty {
+		dirty = " (dirty)"
+	}
+
+	if _, err := fmt.Fprintf(out, "version: %d%s\nlatest: %d\n", status.Version, dirty, status.Latest); err != nil {
+		return err
+	}
+
+	for _, m := range status.Pending {
+		if _, err := fmt.Fprintf(out, "pending: %d_%s\n", m.Version, m.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
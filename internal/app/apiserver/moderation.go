@@ -0,0 +1,464 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/GShamian/tavern-of-games/internal/app/logging"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/textfilter"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// reportExcerptLength is how many characters of the reported content
+// are kept with the report
+const reportExcerptLength = 500
+
+var (
+	errOwnReport        = errors.New("can't report own content")
+	errInvalidDuration  = errors.New("invalid duration")
+	errAccountSuspended = errors.New("account is suspended")
+	errAccountBanned    = errors.New("account is banned")
+	errMuted            = errors.New("muted")
+)
+
+// handleReportsCreate func. Handler func that reports the user, the
+// chat message, the review or the guild to moderators. Messages can be
+// reported only by those who can read them.
+func (s *server) handleReportsCreate() http.HandlerFunc {
+	type request struct {
+		TargetType string `json:"target_type"`
+		TargetID   int    `json:"target_id"`
+		Reason     string `json:"reason"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		rep := &model.Report{
+			ReporterID: u.ID,
+			TargetType: req.TargetType,
+			TargetID:   req.TargetID,
+			Reason:     req.Reason,
+		}
+		if err := s.reportTarget(r, u, rep); err != nil {
+			s.moderationError(w, r, err)
+			return
+		}
+
+		if err := s.store.Moderation().CreateReport(r.Context(), rep); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 201 (Report created)
+		s.respond(w, r, http.StatusCreated, rep)
+	}
+}
+
+// handleMySanctions func. Handler func that returns sanctions imposed on
+// the actual user, newest first, including warnings and expired ones
+func (s *server) handleMySanctions() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		sanctions, err := s.store.Moderation().Sanctions(r.Context(), u.ID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, sanctions)
+	}
+}
+
+// handleAdminReportsList func. Handler func that returns the page of the
+// moderator queue, oldest reports first. Open reports are returned
+// unless status query parameter asks for others.
+func (s *server) handleAdminReportsList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := parseListQuery(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		status := r.URL.Query().Get("status")
+		if status == "" {
+			status = model.ReportOpen
+		}
+
+		reports, err := s.store.Moderation().FindReports(r.Context(), status, q)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, reports)
+	}
+}
+
+// handleAdminReportsGet func. Handler func that returns the report with
+// sanctions imposed on the reported user before
+func (s *server) handleAdminReportsGet() http.HandlerFunc {
+	type response struct {
+		*model.Report
+		Sanctions []*model.Sanction `json:"sanctions"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		rep, ok := s.findReport(w, r)
+		if !ok {
+			return
+		}
+
+		sanctions, err := s.store.Moderation().Sanctions(r.Context(), rep.TargetUserID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, &response{Report: rep, Sanctions: sanctions})
+	}
+}
+
+// handleAdminReportsResolve func. Handler func that lets a moderator
+// resolve the report for a reason: dismiss it, remove the reported
+// content, or warn, mute, suspend or ban the reported user. Mutes and
+// suspensions last for the duration, e.g. "72h", bans without one are
+// permanent. Open streams of suspended and banned users are closed.
+func (s *server) handleAdminReportsResolve() http.HandlerFunc {
+	type request struct {
+		Action   string `json:"action"`
+		Reason   string `json:"reason"`
+		Duration string `json:"duration"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		rep, ok := s.findReport(w, r)
+		if !ok {
+			return
+		}
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		var duration time.Duration
+		if req.Duration != "" {
+			d, err := time.ParseDuration(req.Duration)
+			if err != nil || d <= 0 {
+				s.error(w, r, http.StatusBadRequest, errInvalidDuration)
+				return
+			}
+			duration = d
+		}
+
+		now := time.Now().UTC()
+		var sanction *model.Sanction
+		switch req.Action {
+		case model.SanctionWarn, model.SanctionMute, model.SanctionSuspend, model.SanctionBan:
+			sanction = &model.Sanction{
+				UserID:      rep.TargetUserID,
+				Kind:        req.Action,
+				Reason:      req.Reason,
+				ModeratorID: u.ID,
+			}
+			if duration > 0 {
+				expires := now.Add(duration)
+				sanction.ExpiresAt = &expires
+			}
+		}
+		// Checking the action before the content is removed, as the
+		// removal can't be rolled back with the report
+		check := *rep
+		if err := check.Resolve(req.Action, req.Reason, u.ID, now); err != nil {
+			s.moderationError(w, r, err)
+			return
+		}
+
+		if sanction != nil {
+			if err := sanction.Validate(); err != nil {
+				s.storeError(w, r, err)
+				return
+			}
+		}
+
+		if req.Action == model.ModerationRemoveContent {
+			if err := s.removeReported(r, rep); err != nil {
+				s.storeError(w, r, err)
+				return
+			}
+		}
+
+		resolved, err := s.store.Moderation().Resolve(r.Context(), rep.ID, req.Action, req.Reason, u.ID, sanction)
+		if err != nil {
+			s.moderationError(w, r, err)
+			return
+		}
+		// Suspended and banned users lose their open streams right away
+		if req.Action == model.SanctionSuspend || req.Action == model.SanctionBan {
+			s.online.disconnect(rep.TargetUserID)
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, resolved)
+	}
+}
+
+// handleAdminSanctionsLift func. Handler func that lets a moderator end
+// the active sanction early
+func (s *server) handleAdminSanctionsLift() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.store.Moderation().Lift(r.Context(), id, time.Now().UTC()); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// findReport func. Finds the report from the request path. Writes the
+// error response and returns false if it can't be found.
+func (s *server) findReport(w http.ResponseWriter, r *http.Request) (*model.Report, bool) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		s.error(w, r, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	rep, err := s.store.Moderation().FindReport(r.Context(), id)
+	if err != nil {
+		s.storeError(w, r, err)
+		return nil, false
+	}
+
+	return rep, true
+}
+
+// reportTarget func. Finds the reported target and sets the user it
+// comes from and the excerpt of its content in the report. Guilds come
+// from their leaders. Unknown target types are left for validation.
+func (s *server) reportTarget(r *http.Request, u *model.User, rep *model.Report) error {
+	switch rep.TargetType {
+	case model.ReportTargetUser:
+		target, err := s.store.User().Find(r.Context(), rep.TargetID)
+		if err != nil {
+			return err
+		}
+		rep.TargetUserID = target.ID
+	case model.ReportTargetMessage:
+		m, err := s.store.Chat().Find(r.Context(), rep.TargetID)
+		if err != nil {
+			return err
+		}
+		// Users who blocked the author can report direct messages
+		// they got before, so only membership of the room is checked
+		room, err := model.ParseChatRoom(m.Room)
+		if err != nil {
+			return err
+		}
+		if room.Kind == model.ChatRoomDirect {
+			err = nil
+			if !room.Member(u.ID) {
+				err = errChatRoomForbidden
+			}
+		} else {
+			err = s.checkChatRoom(r, u, m.Room)
+		}
+		if err == errChatRoomForbidden {
+			return store.ErrRecordNotFound
+		}
+		if err != nil {
+			return err
+		}
+		rep.TargetUserID = m.UserID
+		rep.Excerpt = m.Text
+	case model.ReportTargetReview:
+		rv, err := s.store.Review().Find(r.Context(), rep.TargetID)
+		if err != nil {
+			return err
+		}
+		rep.TargetUserID = rv.UserID
+		rep.Excerpt = rv.Text
+	case model.ReportTargetGuild:
+		g, err := s.store.Guild().Find(r.Context(), rep.TargetID)
+		if err != nil {
+			return err
+		}
+
+		members, err := s.store.Guild().Members(r.Context(), g.ID)
+		if err != nil {
+			return err
+		}
+		for _, m := range members {
+			if m.Role == model.GuildRoleLeader {
+				rep.TargetUserID = m.UserID
+			}
+		}
+		rep.Excerpt = g.Name + "\n" + g.Description
+	default:
+		return nil
+	}
+
+	if rep.TargetUserID == u.ID {
+		return errOwnReport
+	}
+	rep.Excerpt = truncate(rep.Excerpt, reportExcerptLength)
+
+	return nil
+}
+
+// removeReported func. Removes the reported content. Messages and
+// reviews are deleted, descriptions and emblems of guilds are cleared.
+// Content which is already gone is fine.
+func (s *server) removeReported(r *http.Request, rep *model.Report) error {
+	var err error
+	switch rep.TargetType {
+	case model.ReportTargetMessage:
+		err = s.store.Chat().Delete(r.Context(), rep.TargetID)
+	case model.ReportTargetReview:
+		var rv *model.Review
+		if rv, err = s.store.Review().Find(r.Context(), rep.TargetID); err == nil {
+			err = s.store.Review().Delete(r.Context(), rv.UserID, rv.GameID)
+		}
+	case model.ReportTargetGuild:
+		var g *model.Guild
+		if g, err = s.store.Guild().Find(r.Context(), rep.TargetID); err == nil {
+			g.Description = ""
+			g.EmblemURL = ""
+			err = s.store.Guild().Update(r.Context(), g)
+		}
+	}
+
+	if err == store.ErrRecordNotFound {
+		return nil
+	}
+
+	return err
+}
+
+// filterContent func. Runs texts of the fields of the content through
+// the checker. Blocked texts fail with validation errors of the fields,
+// written as the response. Returns reasons the content was flagged for
+// and false if it was blocked.
+func (s *server) filterContent(w http.ResponseWriter, r *http.Request, checker textfilter.Checker, fields map[string]string) ([]string, bool) {
+	reasons, err := filterText(checker, fields)
+	if err != nil {
+		s.storeError(w, r, err)
+		return nil, false
+	}
+
+	return reasons, true
+}
+
+// reportFlagged func. Queues the content flagged by the text filter for
+// moderators, unless it wasn't flagged. Failing to report doesn't fail
+// the request, the error is only logged.
+func (s *server) reportFlagged(r *http.Request, rep *model.Report, reasons []string) {
+	if len(reasons) == 0 {
+		return
+	}
+
+	rep.ReporterID = 0
+	rep.Reason = "Flagged by the text filter: " + strings.Join(reasons, ", ")
+	rep.Excerpt = truncate(rep.Excerpt, reportExcerptLength)
+	if err := s.store.Moderation().CreateReport(r.Context(), rep); err != nil {
+		logging.FromContext(r.Context()).WithFields(logrus.Fields{
+			"user_id": rep.TargetUserID,
+			"target":  rep.TargetType,
+		}).Errorf("reporting flagged content: %v", err)
+	}
+}
+
+// activeSanction func. Returns the sanction of one of the kinds which
+// restricts the user the longest now, or nil if there is none
+func (s *server) activeSanction(r *http.Request, userID int, kinds ...string) (*model.Sanction, error) {
+	sanctions, err := s.store.Moderation().Sanctions(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ActiveSanction(sanctions, time.Now(), kinds...), nil
+}
+
+// sanctionResponse func. Describes the sanction to the restricted user:
+// what can't be done, why and until when. Permanent sanctions have null
+// expiry.
+func sanctionResponse(sn *model.Sanction) map[string]interface{} {
+	err := errMuted
+	switch sn.Kind {
+	case model.SanctionSuspend:
+		err = errAccountSuspended
+	case model.SanctionBan:
+		err = errAccountBanned
+	}
+
+	return map[string]interface{}{
+		"error":      err.Error(),
+		"reason":     sn.Reason,
+		"expires_at": sn.ExpiresAt,
+	}
+}
+
+// moderationError func. Creates an error response for moderation
+// requests
+func (s *server) moderationError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case model.ErrReportResolved:
+		s.error(w, r, http.StatusConflict, err)
+	case model.ErrReportNoContent, errOwnReport:
+		s.error(w, r, http.StatusUnprocessableEntity, err)
+	default:
+		s.storeError(w, r, err)
+	}
+}
+
+// filterText func. Runs texts of the fields through the checker as a
+// validation rule. Returns validation errors of blocked fields and
+// distinct reasons the other ones were flagged for.
+func filterText(checker textfilter.Checker, fields map[string]string) ([]string, error) {
+	reasons := []string{}
+	seen := map[string]bool{}
+	rule := textfilter.NewRule(checker, func(res textfilter.Result) {
+		if !seen[res.Reason] {
+			seen[res.Reason] = true
+			reasons = append(reasons, res.Reason)
+		}
+	})
+
+	errs := validation.Errors{}
+	for name, text := range fields {
+		errs[name] = validation.Validate(text, rule)
+	}
+
+	return reasons, errs.Filter()
+}
+
+// truncate func. Cuts the text to at most n characters
+func truncate(text string, n int) string {
+	if utf8.RuneCountInString(text) <= n {
+		return text
+	}
+
+	return string([]rune(text)[:n])
+}
@@ -0,0 +1,354 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/sessions"
+	"github.com/gorilla/websocket"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	storepkg "github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/textfilter"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleModeration(t *testing.T) {
+	store := teststore.New()
+	reporter := model.TestUser(t)
+	store.User().Create(context.Background(), reporter)
+	author := model.TestUser(t)
+	author.Email = "author@example.org"
+	store.User().Create(context.Background(), author)
+	other := model.TestUser(t)
+	other.Email = "other@example.org"
+	store.User().Create(context.Background(), other)
+	moderator := model.TestUser(t)
+	moderator.Email = "moderator@example.org"
+	moderator.Role = model.RoleModerator
+	store.User().Create(context.Background(), moderator)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	m := model.TestChatMessage(t, model.DirectChatRoom(reporter.ID, author.ID), author.ID)
+	store.Chat().Create(context.Background(), m)
+	rv := model.TestReview(t, author.ID, g.ID)
+	store.Review().Create(context.Background(), rv)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+
+	testCases := []struct {
+		name         string
+		user         *model.User
+		method       string
+		path         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name:         "report message of other users",
+			user:         other,
+			method:       http.MethodPost,
+			path:         "/private/reports",
+			payload:      map[string]interface{}{"target_type": model.ReportTargetMessage, "target_id": m.ID, "reason": "Insults"},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "report message",
+			user:         reporter,
+			method:       http.MethodPost,
+			path:         "/private/reports",
+			payload:      map[string]interface{}{"target_type": model.ReportTargetMessage, "target_id": m.ID, "reason": "Insults"},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "report message again",
+			user:         reporter,
+			method:       http.MethodPost,
+			path:         "/private/reports",
+			payload:      map[string]interface{}{"target_type": model.ReportTargetMessage, "target_id": m.ID, "reason": "Insults"},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "report own message",
+			user:         author,
+			method:       http.MethodPost,
+			path:         "/private/reports",
+			payload:      map[string]interface{}{"target_type": model.ReportTargetMessage, "target_id": m.ID, "reason": "Oops"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "report unknown target type",
+			user:         reporter,
+			method:       http.MethodPost,
+			path:         "/private/reports",
+			payload:      map[string]interface{}{"target_type": "lobby", "target_id": 1, "reason": "Spam"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "report review",
+			user:         other,
+			method:       http.MethodPost,
+			path:         "/private/reports",
+			payload:      map[string]interface{}{"target_type": model.ReportTargetReview, "target_id": rv.ID, "reason": "Spoilers"},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "report user",
+			user:         other,
+			method:       http.MethodPost,
+			path:         "/private/reports",
+			payload:      map[string]interface{}{"target_type": model.ReportTargetUser, "target_id": author.ID, "reason": "Cheats"},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "queue of not a moderator",
+			user:         reporter,
+			method:       http.MethodGet,
+			path:         "/private/admin/reports",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "queue",
+			user:         moderator,
+			method:       http.MethodGet,
+			path:         "/private/admin/reports",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "remove content of user",
+			user:         moderator,
+			method:       http.MethodPost,
+			path:         "/private/admin/reports/3/resolve",
+			payload:      map[string]interface{}{"action": model.ModerationRemoveContent, "reason": "Cheats"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "remove message",
+			user:         moderator,
+			method:       http.MethodPost,
+			path:         "/private/admin/reports/1/resolve",
+			payload:      map[string]interface{}{"action": model.ModerationRemoveContent, "reason": "Insults"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "resolve again",
+			user:         moderator,
+			method:       http.MethodPost,
+			path:         "/private/admin/reports/1/resolve",
+			payload:      map[string]interface{}{"action": model.ModerationDismiss, "reason": "Fine"},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "mute without duration",
+			user:         moderator,
+			method:       http.MethodPost,
+			path:         "/private/admin/reports/2/resolve",
+			payload:      map[string]interface{}{"action": model.SanctionMute, "reason": "Spoilers"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "mute with invalid duration",
+			user:         moderator,
+			method:       http.MethodPost,
+			path:         "/private/admin/reports/2/resolve",
+			payload:      map[string]interface{}{"action": model.SanctionMute, "reason": "Spoilers", "duration": "a week"},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "mute",
+			user:         moderator,
+			method:       http.MethodPost,
+			path:         "/private/admin/reports/2/resolve",
+			payload:      map[string]interface{}{"action": model.SanctionMute, "reason": "Spoilers", "duration": "24h"},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "report with history",
+			user:         moderator,
+			method:       http.MethodGet,
+			path:         "/private/admin/reports/3",
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if tc.payload != nil {
+				json.NewEncoder(b).Encode(tc.payload)
+			}
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, b)
+			req.Header.Set("Cookie", testCookie(t, secretKey, tc.user.ID))
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	_, err := store.Chat().Find(context.Background(), m.ID)
+	assert.Error(t, err)
+
+	serve := func(u *model.User, method, path string, payload interface{}) *httptest.ResponseRecorder {
+		b := &bytes.Buffer{}
+		if payload != nil {
+			json.NewEncoder(b).Encode(payload)
+		}
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, b)
+		req.Header.Set("Cookie", testCookie(t, secretKey, u.ID))
+		s.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("muted user can't post to chat", func(t *testing.T) {
+		srv := httptest.NewServer(s)
+		defer srv.Close()
+		url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/private/chat/ws?room=" + model.ChatRoomHall
+		header := http.Header{}
+		header.Set("Cookie", testCookie(t, secretKey, author.ID))
+
+		conn, _, err := websocket.DefaultDialer.Dial(url, header)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		assert.NoError(t, conn.WriteJSON(map[string]string{"text": "hi"}))
+		reply := map[string]interface{}{}
+		assert.NoError(t, conn.ReadJSON(&reply))
+		assert.Equal(t, errMuted.Error(), reply["error"])
+		assert.Equal(t, "Spoilers", reply["reason"])
+		assert.NotNil(t, reply["expires_at"])
+	})
+
+	t.Run("banned user is forbidden", func(t *testing.T) {
+		rec := serve(moderator, http.MethodPost, "/private/admin/reports/3/resolve", map[string]interface{}{
+			"action": model.SanctionBan,
+			"reason": "Cheats",
+		})
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		rec = serve(author, http.MethodGet, "/private/whoami", nil)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		res := map[string]interface{}{}
+		json.NewDecoder(rec.Body).Decode(&res)
+		assert.Equal(t, errAccountBanned.Error(), res["error"])
+		assert.Equal(t, "Cheats", res["reason"])
+		assert.Nil(t, res["expires_at"])
+
+		sanctions, _ := store.Moderation().Sanctions(context.Background(), author.ID)
+		if assert.Len(t, sanctions, 2) {
+			rec = serve(moderator, http.MethodDelete, fmt.Sprintf("/private/admin/sanctions/%d", sanctions[0].ID), nil)
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+		}
+
+		rec = serve(author, http.MethodGet, "/private/me/sanctions", nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestServer_HandleTextFilter(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	store.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+	g2 := model.TestGame(t)
+	g2.Title = "Chess"
+	store.Game().Create(context.Background(), g2)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	c := textfilter.DefaultConfig()
+	c.WordLists = []*textfilter.WordList{{Locale: "en", Block: []string{"darn"}, Flag: []string{"noob"}}}
+	s.configureTextFilter(c)
+
+	testCases := []struct {
+		name         string
+		path         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name:         "blocked review",
+			path:         fmt.Sprintf("/private/games/%d/review", g.ID),
+			payload:      map[string]interface{}{"score": 1, "text": "D4RN this game"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "flagged review",
+			path:         fmt.Sprintf("/private/games/%d/review", g.ID),
+			payload:      map[string]interface{}{"score": 1, "text": "Only a n00b loses here"},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "review with many links",
+			path:         fmt.Sprintf("/private/games/%d/review", g2.ID),
+			payload:      map[string]interface{}{"score": 9, "text": "See http://a.org http://b.org http://c.org"},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "blocked guild",
+			path:         "/private/guilds",
+			payload:      map[string]interface{}{"name": "Darn Knights", "tag": "DK"},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			json.NewEncoder(b).Encode(tc.payload)
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, tc.path, b)
+			req.Header.Set("Cookie", testCookie(t, secretKey, u.ID))
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	// Flagged reviews wait for moderators
+	reports, err := store.Moderation().FindReports(context.Background(), model.ReportOpen, &storepkg.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, reports, 2) {
+		assert.Equal(t, model.ReportTargetReview, reports[0].TargetType)
+		assert.Equal(t, u.ID, reports[0].TargetUserID)
+		assert.Zero(t, reports[0].ReporterID)
+		assert.Contains(t, reports[1].Reason, "links")
+	}
+
+	t.Run("chat flood", func(t *testing.T) {
+		srv := httptest.NewServer(s)
+		defer srv.Close()
+		url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/private/chat/ws?room=" + model.ChatRoomHall
+		header := http.Header{}
+		header.Set("Cookie", testCookie(t, secretKey, u.ID))
+
+		conn, _, err := websocket.DefaultDialer.Dial(url, header)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer conn.Close()
+
+		assert.NoError(t, conn.WriteJSON(map[string]string{"text": "gg"}))
+		m := &model.ChatMessage{}
+		assert.NoError(t, conn.ReadJSON(m))
+		assert.Equal(t, "gg", m.Text)
+
+		assert.NoError(t, conn.WriteJSON(map[string]string{"text": "G G"}))
+		reply := map[string]interface{}{}
+		assert.NoError(t, conn.ReadJSON(&reply))
+		assert.NotEmpty(t, reply["error"])
+	})
+}
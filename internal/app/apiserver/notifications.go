@@ -0,0 +1,298 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/logging"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/tournament"
+	"github.com/sirupsen/logrus"
+)
+
+// notificationsPingPeriod is how often an idle notification stream is
+// pinged, so proxies don't close it
+const notificationsPingPeriod = 30 * time.Second
+
+var (
+	errStreamingNotSupported = errors.New("response writer doesn't support streaming")
+)
+
+// handleNotificationsList func. Handler func that returns a page of
+// notifications of the actual user, newest first, with the number of
+// unread ones. Older pages are requested with before query parameter
+// set to the cursor returned with the page, there are no more pages if
+// it's missing.
+func (s *server) handleNotificationsList() http.HandlerFunc {
+	type response struct {
+		Notifications []*model.Notification `json:"notifications"`
+		Unread        int                   `json:"unread"`
+		Before        int                   `json:"before,omitempty"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Parsing cursor and page size
+		q, err := parseListQuery(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		before, err := parseCursor(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		notifications, err := s.store.Notification().FindByUser(r.Context(), u.ID, before, q.Limit)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		unread, err := s.store.Notification().CountUnread(r.Context(), u.ID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		res := &response{Notifications: notifications, Unread: unread}
+		if len(notifications) == q.Limit {
+			res.Before = notifications[len(notifications)-1].ID
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, res)
+	}
+}
+
+// handleNotificationsRead func. Handler func that marks the notification
+// of the actual user read
+func (s *server) handleNotificationsRead() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.store.Notification().MarkRead(r.Context(), u.ID, id); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleNotificationsReadAll func. Handler func that marks every
+// notification of the actual user read
+func (s *server) handleNotificationsReadAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		if _, err := s.store.Notification().MarkAllRead(r.Context(), u.ID); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleNotificationPreferences func. Handler func that returns whether
+// the actual user receives notifications of every type
+func (s *server) handleNotificationPreferences() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		prefs, err := s.notificationPreferences(r, u.ID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, prefs)
+	}
+}
+
+// handleNotificationPreferencesUpdate func. Handler func that turns
+// notification types on or off for the actual user. Types missing from
+// the request are left as they are.
+func (s *server) handleNotificationPreferencesUpdate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := map[string]bool{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.store.Notification().SetPreferences(r.Context(), u.ID, req); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		prefs, err := s.notificationPreferences(r, u.ID)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, prefs)
+	}
+}
+
+// handleNotificationsStream func. Handler func that streams new
+// notifications of the actual user as server-sent events until the
+// client goes away, the user is disconnected or the server stops. The
+// stream ends before the write timeout of the server, clients reconnect
+// then. Notifications missed while disconnected are in the notification
+// list.
+func (s *server) handleNotificationsStream() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			s.error(w, r, http.StatusInternalServerError, errStreamingNotSupported)
+			return
+		}
+		// Subscribing before the response starts, so the user gets
+		// every notification created after the stream is open
+		notifications, unsubscribe := s.notifications.Subscribe(u.ID)
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+		kicked, disconnect := s.online.connect(u.ID)
+		defer disconnect()
+
+		ticker := time.NewTicker(notificationsPingPeriod)
+		defer ticker.Stop()
+		// Ending the stream while it can still be written
+		var deadline <-chan time.Time
+		if s.streamTimeout > 0 {
+			timer := time.NewTimer(s.streamTimeout * 9 / 10)
+			defer timer.Stop()
+			deadline = timer.C
+		}
+
+		for {
+			var err error
+			select {
+			case <-r.Context().Done():
+				return
+			case <-s.quit:
+				return
+			case <-kicked:
+				return
+			case <-deadline:
+				return
+			case n, ok := <-notifications:
+				if !ok {
+					return
+				}
+				data, _ := json.Marshal(n)
+				_, err = fmt.Fprintf(w, "id: %d\nevent: notification\ndata: %s\n\n", n.ID, data)
+			case <-ticker.C:
+				_, err = fmt.Fprint(w, ": ping\n\n")
+			}
+
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// notify func. Notifies the user, unless the user turned notifications
+// of the type off. The notification is stored and published to open
+// streams of the user. Failing to notify doesn't fail the request, the
+// error is only logged.
+func (s *server) notify(r *http.Request, n *model.Notification) {
+	logger := logging.FromContext(r.Context()).WithFields(logrus.Fields{
+		"user_id": n.UserID,
+		"type":    n.Type,
+	})
+
+	prefs, err := s.store.Notification().Preferences(r.Context(), n.UserID)
+	if err != nil {
+		logger.Errorf("notifying: %v", err)
+		return
+	}
+
+	if enabled, ok := prefs[n.Type]; ok && !enabled {
+		return
+	}
+
+	if err := s.store.Notification().Create(r.Context(), n); err != nil {
+		logger.Errorf("notifying: %v", err)
+		return
+	}
+
+	if err := s.notifications.Publish(n); err != nil {
+		logger.Errorf("publishing notification: %v", err)
+	}
+}
+
+// notifyTournamentMatches func. Notifies players of bracket matches of
+// the tournament which became ready to be played, i.e. aren't in the
+// imported set of matches which were ready before.
+func (s *server) notifyTournamentMatches(r *http.Request, t *model.Tournament, before map[int]bool) {
+	for id := range readyMatches(t.Bracket) {
+		if before[id] {
+			continue
+		}
+
+		m, _ := t.Bracket.Match(id)
+		for _, userID := range []int{m.A.UserID, m.B.UserID} {
+			s.notify(r, &model.Notification{
+				UserID: userID,
+				Type:   model.NotificationTournamentMatch,
+				Text:   fmt.Sprintf("Your match of round %d in tournament %q is ready", m.Round, t.Name),
+				Link:   fmt.Sprintf("/tournaments/%d/bracket", t.ID),
+			})
+		}
+	}
+}
+
+// notificationPreferences func. Returns notification preferences of
+// the user for every type, types the user didn't set are on
+func (s *server) notificationPreferences(r *http.Request, userID int) (map[string]bool, error) {
+	prefs, err := s.store.Notification().Preferences(r.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range model.NotificationTypes {
+		if _, ok := prefs[t]; !ok {
+			prefs[t] = true
+		}
+	}
+
+	return prefs, nil
+}
+
+// readyMatches func. Returns ids of matches of the bracket ready to be
+// played. Brackets of tournaments which haven't started are nil.
+func readyMatches(b *tournament.Bracket) map[int]bool {
+	ready := map[int]bool{}
+	if b == nil {
+		return ready
+	}
+
+	for _, m := range b.Matches {
+		if m.Ready() {
+			ready[m.ID] = true
+		}
+	}
+
+	return ready
+}
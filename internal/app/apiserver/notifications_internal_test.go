@@ -0,0 +1,223 @@
+package apiserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleNotifications(t *testing.T) {
+	store := teststore.New()
+	u1 := model.TestUser(t)
+	store.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	store.User().Create(context.Background(), u2)
+	u3 := model.TestUser(t)
+	u3.Email = "user3@example.org"
+	store.User().Create(context.Background(), u3)
+	store.Friendship().Block(context.Background(), u3.ID, u1.ID)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	l, _ := s.lobbies.Create(model.TestLobby(t, g.ID), u1.ID)
+
+	testCases := []struct {
+		name         string
+		user         *model.User
+		method       string
+		path         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name:         "friend request",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/friends/%d/request", u2.ID),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "lobby invite",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/lobbies/%d/invite/%d", l.ID, u2.ID),
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "lobby invite by not a member",
+			user:         u2,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/lobbies/%d/invite/%d", l.ID, u1.ID),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "lobby invite of blocker",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/lobbies/%d/invite/%d", l.ID, u3.ID),
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "turn lobby invites off",
+			user:         u2,
+			method:       http.MethodPut,
+			path:         "/private/notifications/preferences",
+			payload:      map[string]bool{model.NotificationLobbyInvite: false},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "turn unknown type off",
+			user:         u2,
+			method:       http.MethodPut,
+			path:         "/private/notifications/preferences",
+			payload:      map[string]bool{"newsletter": false},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "lobby invite turned off",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         fmt.Sprintf("/private/lobbies/%d/invite/%d", l.ID, u2.ID),
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "list invalid cursor",
+			user:         u2,
+			method:       http.MethodGet,
+			path:         "/private/notifications?before=first",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "read other user's notification",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         "/private/notifications/1/read",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "read",
+			user:         u2,
+			method:       http.MethodPost,
+			path:         "/private/notifications/1/read",
+			expectedCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if tc.payload != nil {
+				json.NewEncoder(b).Encode(tc.payload)
+			}
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, b)
+			req.Header.Set("Cookie", testCookie(t, secretKey, tc.user.ID))
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	// The friend request and the first lobby invite were delivered
+	list := func(query string) map[string]interface{} {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/private/notifications"+query, nil)
+		req.Header.Set("Cookie", testCookie(t, secretKey, u2.ID))
+		s.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		res := map[string]interface{}{}
+		json.NewDecoder(rec.Body).Decode(&res)
+
+		return res
+	}
+
+	res := list("?limit=1")
+	assert.Equal(t, float64(1), res["unread"])
+	assert.Len(t, res["notifications"], 1)
+	assert.Equal(t, float64(2), res["before"])
+
+	res = list("?before=2")
+	if assert.Len(t, res["notifications"], 1) {
+		n := res["notifications"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, model.NotificationFriendRequest, n["type"])
+		assert.Equal(t, true, n["read"])
+	}
+	assert.NotContains(t, res, "before")
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/private/notifications/read", nil)
+	req.Header.Set("Cookie", testCookie(t, secretKey, u2.ID))
+	s.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, float64(0), list("")["unread"])
+}
+
+func TestServer_HandleNotificationsStream(t *testing.T) {
+	store := teststore.New()
+	u1 := model.TestUser(t)
+	store.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	store.User().Create(context.Background(), u2)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/private/notifications/stream", nil)
+	req.Header.Set("Cookie", testCookie(t, secretKey, u2.ID))
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	// The friend request is pushed to the open stream
+	rec := httptest.NewRecorder()
+	friendReq, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/private/friends/%d/request", u2.ID), nil)
+	friendReq.Header.Set("Cookie", testCookie(t, secretKey, u1.ID))
+	s.ServeHTTP(rec, friendReq)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	events := make(chan string)
+	go func() {
+		var event []string
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			if scanner.Text() != "" {
+				event = append(event, scanner.Text())
+				continue
+			}
+			events <- strings.Join(event, "\n")
+			event = nil
+		}
+		close(events)
+	}()
+
+	select {
+	case event := <-events:
+		assert.True(t, strings.HasPrefix(event, "id: 1\nevent: notification\ndata: {"))
+		assert.Contains(t, event, `"type":"friend_request"`)
+	case <-time.After(time.Second):
+		t.Fatal("notification wasn't streamed")
+	}
+}
@@ -0,0 +1,80 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// Pagination limits of list requests
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+var (
+	errInvalidSort   = errors.New("invalid sort")
+	errInvalidLimit  = errors.New("invalid limit")
+	errInvalidOffset = errors.New("invalid offset")
+	errInvalidCursor = errors.New("invalid cursor")
+)
+
+// parseListQuery func. Parses sort, limit and offset query parameters
+// of list requests. The first of the imported sorts is the default one.
+// Lists without imported sorts can't be sorted.
+func parseListQuery(r *http.Request, sorts ...string) (*store.ListQuery, error) {
+	values := r.URL.Query()
+	q := &store.ListQuery{
+		Sort:  values.Get("sort"),
+		Limit: defaultListLimit,
+	}
+	// Checking that sort is one of the allowed ones
+	if q.Sort == "" && len(sorts) > 0 {
+		q.Sort = sorts[0]
+	}
+	allowed := q.Sort == "" && len(sorts) == 0
+	for _, sort := range sorts {
+		if q.Sort == sort {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return nil, errInvalidSort
+	}
+	// Parsing page size
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxListLimit {
+			return nil, errInvalidLimit
+		}
+		q.Limit = limit
+	}
+	// Parsing page offset
+	if v := values.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return nil, errInvalidOffset
+		}
+		q.Offset = offset
+	}
+
+	return q, nil
+}
+
+// parseCursor func. Parses before query parameter of lists paginated
+// with a cursor. Zero cursor means the first page.
+func parseCursor(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("before")
+	if v == "" {
+		return 0, nil
+	}
+
+	before, err := strconv.Atoi(v)
+	if err != nil || before < 1 {
+		return 0, errInvalidCursor
+	}
+
+	return before, nil
+}
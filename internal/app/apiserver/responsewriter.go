@@ -1,6 +1,15 @@
 package apiserver
 
-import "net/http"
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+var (
+	errHijackNotSupported = errors.New("response writer doesn't support hijacking")
+)
 
 // responseWriter object. Stores http ResponseWriter
 // and code variable.
@@ -14,3 +23,24 @@ func (w *responseWriter) WriteHeader(statusCode int) {
 	w.code = statusCode
 	w.ResponseWriter.WriteHeader(statusCode)
 }
+
+// Hijack func. Wraps http Hijacker, so the connection can be
+// taken over, e.g. by WebSocket upgrade.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errHijackNotSupported
+	}
+
+	w.code = http.StatusSwitchingProtocols
+
+	return h.Hijack()
+}
+
+// Flush func. Wraps http Flusher, so streamed responses, e.g.
+// server-sent events, reach the client right away.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
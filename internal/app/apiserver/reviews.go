@@ -0,0 +1,239 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+var (
+	errOwnReviewVote = errors.New("can't vote for own review")
+)
+
+// reviewRequest object. Describes review fields that user can change.
+type reviewRequest struct {
+	Score int    `json:"score"`
+	Text  string `json:"text"`
+}
+
+// handleGamesGet func. Handler func that returns the game together
+// with its aggregated rating.
+func (s *server) handleGamesGet() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		g, err := s.store.Game().Find(r.Context(), id)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, g)
+	}
+}
+
+// handleReviewsList func. Handler func that returns reviews of the game.
+// Reviews can be sorted by recency or helpfulness and paginated. Reviews
+// of users hidden from the viewer by a block are left out.
+func (s *server) handleReviewsList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Parsing sort and pagination of the list
+		q, err := parseListQuery(r, store.SortRecent, store.SortHelpful)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		reviews, err := s.store.Review().FindByGame(r.Context(), id, q)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Leaving out reviews of users hidden from the viewer
+		viewerID := s.sessionUserID(r)
+		visible := reviews[:0]
+		for _, rv := range reviews {
+			hidden, err := s.isHidden(r, viewerID, rv.UserID)
+			if err != nil {
+				s.storeError(w, r, err)
+				return
+			}
+
+			if !hidden {
+				visible = append(visible, rv)
+			}
+		}
+		reviews = visible
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, reviews)
+	}
+}
+
+// handleReviewsCreate func. Handler func that writes review of the
+// actual user for the game.
+func (s *server) handleReviewsCreate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rv, ok := s.decodeReview(w, r)
+		if !ok {
+			return
+		}
+
+		flags, ok := s.filterContent(w, r, s.textFilter, map[string]string{"text": rv.Text})
+		if !ok {
+			return
+		}
+
+		if err := s.store.Review().Create(r.Context(), rv); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		s.reportFlagged(r, &model.Report{
+			TargetType:   model.ReportTargetReview,
+			TargetID:     rv.ID,
+			TargetUserID: rv.UserID,
+			Excerpt:      rv.Text,
+		}, flags)
+		s.recordEvent(r, &model.UserEvent{UserID: rv.UserID, Type: model.EventReviewWritten, GameID: rv.GameID})
+		s.recordActivity(r, &model.Activity{
+			UserID: rv.UserID,
+			Type:   model.ActivityReviewed,
+			GameID: rv.GameID,
+			Text:   "reviewed " + s.gameTitle(r, rv.GameID),
+			Link:   fmt.Sprintf("/games/%d/reviews", rv.GameID),
+		})
+		// Creating response with status 201 (Review created)
+		s.respond(w, r, http.StatusCreated, rv)
+	}
+}
+
+// handleReviewsUpdate func. Handler func that changes review of the
+// actual user for the game.
+func (s *server) handleReviewsUpdate() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rv, ok := s.decodeReview(w, r)
+		if !ok {
+			return
+		}
+
+		flags, ok := s.filterContent(w, r, s.textFilter, map[string]string{"text": rv.Text})
+		if !ok {
+			return
+		}
+
+		if err := s.store.Review().Update(r.Context(), rv); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		s.reportFlagged(r, &model.Report{
+			TargetType:   model.ReportTargetReview,
+			TargetID:     rv.ID,
+			TargetUserID: rv.UserID,
+			Excerpt:      rv.Text,
+		}, flags)
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, rv)
+	}
+}
+
+// handleReviewsDelete func. Handler func that deletes review of the
+// actual user for the game.
+func (s *server) handleReviewsDelete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.store.Review().Delete(r.Context(), u.ID, id); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleReviewsVote func. Handler func that writes helpful or unhelpful
+// vote of the actual user for somebody else's review.
+func (s *server) handleReviewsVote() http.HandlerFunc {
+	type request struct {
+		Helpful bool `json:"helpful"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+		// Users can't vote for their own reviews
+		rv, err := s.store.Review().Find(r.Context(), id)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		if rv.UserID == u.ID {
+			s.error(w, r, http.StatusForbidden, errOwnReviewVote)
+			return
+		}
+
+		if err := s.store.Review().Vote(r.Context(), &model.ReviewVote{
+			ReviewID: id,
+			UserID:   u.ID,
+			Helpful:  req.Helpful,
+		}); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// decodeReview func. Creates review of the actual user for the game
+// from the request path and body. Writes an error response and returns
+// false if the request is malformed.
+func (s *server) decodeReview(w http.ResponseWriter, r *http.Request) (*model.Review, bool) {
+	u := r.Context().Value(ctxKeyUser).(*model.User)
+	id, err := pathInt(r, "id")
+	if err != nil {
+		s.error(w, r, http.StatusBadRequest, err)
+		return nil, false
+	}
+	// Decoding json from request to our entity
+	req := &reviewRequest{}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		s.error(w, r, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	return &model.Review{
+		UserID: u.ID,
+		GameID: id,
+		Score:  req.Score,
+		Text:   req.Text,
+	}, true
+}
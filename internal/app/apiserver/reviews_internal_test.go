@@ -0,0 +1,137 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleReviewsCreate(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	store.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	sc := securecookie.New(secretKey, nil)
+	cookieStr, _ := sc.Encode(sessionName, map[interface{}]interface{}{"user_id": u.ID})
+
+	testCases := []struct {
+		name         string
+		gameID       int
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name:   "valid",
+			gameID: g.ID,
+			payload: map[string]interface{}{
+				"score": 9,
+				"text":  "Brilliant",
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "second review",
+			gameID: g.ID,
+			payload: map[string]interface{}{
+				"score": 9,
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:   "unknown game",
+			gameID: g.ID + 1,
+			payload: map[string]interface{}{
+				"score": 9,
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "invalid score",
+			gameID: g.ID,
+			payload: map[string]interface{}{
+				"score": 11,
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			json.NewEncoder(b).Encode(tc.payload)
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/private/games/%d/review", tc.gameID), b)
+			req.Header.Set("Cookie", fmt.Sprintf("%s=%s", sessionName, cookieStr))
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/games/%d", g.ID), nil)
+	s.ServeHTTP(rec, req)
+	game := &model.Game{}
+	json.NewDecoder(rec.Body).Decode(game)
+	assert.Equal(t, 1, game.RatingCount)
+	assert.Equal(t, 9.0, game.RatingMean)
+}
+
+func TestServer_HandleReviewsList(t *testing.T) {
+	s := newServer(teststore.New(), sessions.NewCookieStore([]byte("secret")))
+	testCases := []struct {
+		name         string
+		query        string
+		expectedCode int
+	}{
+		{
+			name:         "default",
+			query:        "",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "sorted and paginated",
+			query:        "?sort=helpful&limit=5&offset=10",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "unknown sort",
+			query:        "?sort=score",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "limit too big",
+			query:        "?limit=1000",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "negative offset",
+			query:        "?offset=-1",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, "/games/1/reviews"+tc.query, nil)
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
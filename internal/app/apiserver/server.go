@@ -5,14 +5,31 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/GShamian/tavern-of-games/internal/app/achievement"
+	"github.com/GShamian/tavern-of-games/internal/app/authz"
+	"github.com/GShamian/tavern-of-games/internal/app/chat"
+	"github.com/GShamian/tavern-of-games/internal/app/feed"
+	"github.com/GShamian/tavern-of-games/internal/app/health"
+	"github.com/GShamian/tavern-of-games/internal/app/job"
+	"github.com/GShamian/tavern-of-games/internal/app/lobby"
+	"github.com/GShamian/tavern-of-games/internal/app/logging"
 	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/notify"
 	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/tracestore"
+	"github.com/GShamian/tavern-of-games/internal/app/textfilter"
+	"github.com/GShamian/tavern-of-games/internal/app/trace"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/google/uuid"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
+	"github.com/gorilla/websocket"
 	"github.com/sirupsen/logrus"
 )
 
@@ -20,33 +37,78 @@ const (
 	sessionName        = "tavern_of_games"
 	ctxKeyUser  ctxKey = iota
 	ctxKeyRequestID
+	ctxKeyGuildAccess
 )
 
 var (
 	errIncorrectEmailOrPassword = errors.New("incorrect email or password")
 	errNotAuthenticated         = errors.New("not authenticated")
+	errInvalidPathParam         = errors.New("invalid path parameter")
+	errForbidden                = errors.New("forbidden")
 )
 
 type ctxKey int8
 
 // Server object
 type server struct {
-	router       *mux.Router
-	logger       *logrus.Logger
-	store        store.Store
-	sessionStore sessions.Store
+	router        *mux.Router
+	logger        *logrus.Logger
+	store         store.Store
+	sessionStore  sessions.Store
+	broker        chat.Broker
+	notifications notify.Broker
+	lobbies       *lobby.Manager
+	matchmaker    *lobby.Matchmaker
+	achievements  *achievement.Engine
+	jobs          *job.Runner
+	timelines     *feed.Cache
+	textFilter    textfilter.Pipeline
+	chatFlood     *textfilter.Flood
+	metrics       *serverMetrics
+	health        *health.Registry
+	tracer        *trace.Tracer
+	online        *presence
+	upgrader      *websocket.Upgrader
+	origins       []string
+	streamTimeout time.Duration
+	unready       chan struct{}
+	drainOnce     sync.Once
+	quit          chan struct{}
+	stopOnce      sync.Once
 }
 
 // newServer func. Constructor for a server. It creates new
-// server instance with mux router, logger and our imported
-// session store and store.
+// server instance with mux router, logger, in-process chat
+// and notification brokers, lobby manager with matchmaker,
+// achievements engine without achievements, runner of
+// background jobs, cache of feed timelines, metrics, health
+// checks, tracer discarding spans, presence of users, upgrader of
+// WebSocket connections from the host of the server only and our
+// imported session store and store. Streams of the server last until
+// the server is stopped.
 func newServer(store store.Store, sessionStore sessions.Store) *server {
 	s := &server{
-		router:       mux.NewRouter(),
-		logger:       logrus.New(),
-		store:        store,
-		sessionStore: sessionStore,
+		router:        mux.NewRouter(),
+		logger:        logging.New(),
+		store:         tracestore.New(store),
+		sessionStore:  sessionStore,
+		broker:        chat.NewMemoryBroker(),
+		notifications: notify.NewMemoryBroker(),
+		lobbies:       lobby.NewManager(),
+		achievements:  achievement.NewEngine(nil, store),
+		jobs:          job.NewRunner(),
+		timelines:     feed.NewCache(timelineTTL),
+		tracer:        trace.NewTracer(nil),
+		online:        newPresence(),
+		unready:       make(chan struct{}),
+		quit:          make(chan struct{}),
 	}
+	s.upgrader = s.newUpgrader()
+	s.metrics = newServerMetrics(s)
+	s.health = health.NewRegistry()
+	s.health.Register("chat_broker", brokerCheckTimeout, s.checkBroker)
+	s.configureTextFilter(textfilter.DefaultConfig())
+	s.matchmaker = lobby.NewMatchmaker(s.lobbies, lobby.DefaultTolerance)
 
 	s.configureRouter()
 
@@ -58,25 +120,217 @@ func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.router.ServeHTTP(w, r)
 }
 
+// drain func. Marks the server not ready, so load balancers stop
+// sending requests to it, while it keeps serving them. It can be called
+// more than once.
+func (s *server) drain() {
+	s.drainOnce.Do(func() {
+		close(s.unready)
+	})
+}
+
+// draining func. Checks whether the server is drained or stopped
+func (s *server) draining() bool {
+	select {
+	case <-s.unready:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop func. Ends streams of the server: server-sent events streams
+// return and WebSocket connections are closed, so they don't hold up
+// shutdown. The server isn't ready anymore. It can be called more than
+// once.
+func (s *server) stop() {
+	s.drain()
+	s.stopOnce.Do(func() {
+		close(s.quit)
+	})
+}
+
+// stopping func. Checks whether the server is stopped
+func (s *server) stopping() bool {
+	select {
+	case <-s.quit:
+		return true
+	default:
+		return false
+	}
+}
+
 // configureRouter func. Configuring router
 func (s *server) configureRouter() {
+	// Appending middleware func traceRequest to the router chain,
+	// the rest of middleware funcs and handlers are traced in its span
+	s.router.Use(s.traceRequest)
 	// Appending middleware func setRequestID to the router chain
-	s.router.Use(s.setRequestID)
+	s.router.Use(s.traceMiddleware("setRequestID", s.setRequestID))
 	// Appending middleware func logRequest to the router chain
-	s.router.Use(s.logRequest)
+	s.router.Use(s.traceMiddleware("logRequest", s.logRequest))
+	// Appending middleware func measureRequest to the router chain
+	s.router.Use(s.traceMiddleware("measureRequest", s.measureRequest))
 	// Applying the CORS middleware to our router
-	s.router.Use(handlers.CORS(handlers.AllowedOrigins([]string{"*"})))
+	s.router.Use(s.traceMiddleware("CORS", handlers.CORS(handlers.AllowedOrigins([]string{"*"}))))
+	// Registering routes of liveness and readiness probes
+	s.router.HandleFunc("/healthz", s.handleHealthz()).Methods("GET")
+	s.router.HandleFunc("/readyz", s.handleReadyz()).Methods("GET")
 	// Registering a new route for url /users for our router
 	s.router.HandleFunc("/users", s.handleUsersCreate()).Methods("POST")
 	// Registering a new route for url /sessions for our router
 	s.router.HandleFunc("/sessions", s.handleSessionsCreate()).Methods("POST")
+	// Registering a new route for url /users/{id}/library for our router
+	s.router.HandleFunc("/users/{id:[0-9]+}/library", s.handleUserLibraryGet()).Methods("GET")
+	// Registering a new route for url /users/{id} for our router
+	s.router.HandleFunc("/users/{id:[0-9]+}", s.handleUsersProfile()).Methods("GET")
+	// Registering a new route for url /users/{id}/activity.atom for our router
+	s.router.HandleFunc("/users/{id:[0-9]+}/activity.atom", s.handleUserActivityFeed()).Methods("GET")
+	// Registering routes of game catalog and game reviews
+	s.router.HandleFunc("/games/{id:[0-9]+}", s.handleGamesGet()).Methods("GET")
+	s.router.HandleFunc("/games/{id:[0-9]+}/reviews", s.handleReviewsList()).Methods("GET")
+	s.router.HandleFunc("/games/{id:[0-9]+}/leaderboard", s.handleLeaderboard()).Methods("GET")
+	s.router.HandleFunc("/games/{id:[0-9]+}/leaderboard/guilds", s.handleGuildLeaderboard()).Methods("GET")
+	// Registering a new route for url /lobbies for our router
+	s.router.HandleFunc("/lobbies", s.handleLobbiesList()).Methods("GET")
+	// Registering routes of tournaments and their brackets
+	s.router.HandleFunc("/tournaments", s.handleTournamentsList()).Methods("GET")
+	s.router.HandleFunc("/tournaments/{id:[0-9]+}", s.handleTournamentsGet()).Methods("GET")
+	s.router.HandleFunc("/tournaments/{id:[0-9]+}/bracket", s.handleTournamentsBracket()).Methods("GET")
+	// Registering routes of guilds and their leaderboard places
+	s.router.HandleFunc("/guilds", s.handleGuildsList()).Methods("GET")
+	s.router.HandleFunc("/guilds/{id:[0-9]+}", s.handleGuildsGet()).Methods("GET")
+	s.router.HandleFunc("/guilds/{id:[0-9]+}/rank", s.handleGuildsRank()).Methods("GET")
+	// Registering routes of the looking-for-group board
+	s.router.HandleFunc("/lfg", s.handleLFGList()).Methods("GET")
+	s.router.HandleFunc("/lfg/{id:[0-9]+}", s.handleLFGGet()).Methods("GET")
+	// Registering routes of events and their calendars
+	s.router.HandleFunc("/events", s.handleEventsList()).Methods("GET")
+	s.router.HandleFunc("/events/{id:[0-9]+}", s.handleEventsGet()).Methods("GET")
+	s.router.HandleFunc("/events/{id:[0-9]+}.ics", s.handleEventsCalendar()).Methods("GET")
+	s.router.HandleFunc("/calendars/{token:[0-9a-f]+}.ics", s.handleTokenCalendar()).Methods("GET")
 	// Registering a new route for /private url path prefix and
 	// creating a subrouter for the route.
 	private := s.router.PathPrefix("/private").Subrouter()
 	// Appending middleware func authenticateUser to the router chain
-	private.Use(s.authenticateUser)
+	private.Use(s.traceMiddleware("authenticateUser", s.authenticateUser))
 	// Registering a new route for url /whoami for our router
 	private.HandleFunc("/whoami", s.handleWhoami())
+	// Registering routes of user's notifications, their preferences
+	// and the stream of new ones
+	private.HandleFunc("/notifications", s.handleNotificationsList()).Methods("GET")
+	private.HandleFunc("/notifications/read", s.handleNotificationsReadAll()).Methods("POST")
+	private.HandleFunc("/notifications/{id:[0-9]+}/read", s.handleNotificationsRead()).Methods("POST")
+	private.HandleFunc("/notifications/preferences", s.handleNotificationPreferences()).Methods("GET")
+	private.HandleFunc("/notifications/preferences", s.handleNotificationPreferencesUpdate()).Methods("PUT")
+	private.HandleFunc("/notifications/stream", s.handleNotificationsStream()).Methods("GET")
+	// Registering routes of reports to moderators and user's own
+	// sanctions
+	private.HandleFunc("/reports", s.handleReportsCreate()).Methods("POST")
+	private.HandleFunc("/me/sanctions", s.handleMySanctions()).Methods("GET")
+	// Registering a new route for url /feed for our router
+	private.HandleFunc("/feed", s.handleFeed()).Methods("GET")
+	// Registering routes of user's own game library
+	private.HandleFunc("/me/library", s.handleLibraryList()).Methods("GET")
+	private.HandleFunc("/me/library", s.handleLibraryAdd()).Methods("POST")
+	private.HandleFunc("/me/library/bulk", s.handleLibraryBulk()).Methods("POST")
+	private.HandleFunc("/me/library/visibility", s.handleLibraryVisibility()).Methods("PUT")
+	private.HandleFunc("/me/library/{game_id:[0-9]+}", s.handleLibraryUpdate()).Methods("PUT")
+	private.HandleFunc("/me/library/{game_id:[0-9]+}", s.handleLibraryRemove()).Methods("DELETE")
+	// Registering a new route for url /me/achievements for our router
+	private.HandleFunc("/me/achievements", s.handleAchievementsProgress()).Methods("GET")
+	// Registering routes of user's own reviews and review votes
+	private.HandleFunc("/games/{id:[0-9]+}/review", s.handleReviewsCreate()).Methods("POST")
+	private.HandleFunc("/games/{id:[0-9]+}/review", s.handleReviewsUpdate()).Methods("PUT")
+	private.HandleFunc("/games/{id:[0-9]+}/review", s.handleReviewsDelete()).Methods("DELETE")
+	private.HandleFunc("/reviews/{id:[0-9]+}/vote", s.handleReviewsVote()).Methods("POST")
+	// Registering routes of friends, friend requests and blocks
+	private.HandleFunc("/friends", s.handleFriendsList(model.FriendshipAccepted)).Methods("GET")
+	private.HandleFunc("/friends/requests", s.handleFriendsList(model.FriendshipPending)).Methods("GET")
+	private.HandleFunc("/friends/blocked", s.handleBlocksList()).Methods("GET")
+	private.HandleFunc("/friends/{id:[0-9]+}", s.handleFriendsApply(model.FriendshipUnfriend)).Methods("DELETE")
+	private.HandleFunc("/friends/{id:[0-9]+}/{action:request|accept|decline|cancel}", s.handleFriendsApply("")).Methods("POST")
+	private.HandleFunc("/friends/{id:[0-9]+}/mutual", s.handleFriendsMutual()).Methods("GET")
+	private.HandleFunc("/friends/{id:[0-9]+}/block", s.handleBlocksCreate()).Methods("POST")
+	private.HandleFunc("/friends/{id:[0-9]+}/block", s.handleBlocksDelete()).Methods("DELETE")
+	// Registering routes of chat rooms history and WebSocket connection
+	private.HandleFunc("/chat/messages", s.handleChatMessages()).Methods("GET")
+	private.HandleFunc("/chat/ws", s.handleChatSocket()).Methods("GET")
+	// Registering routes of lobbies and matchmaking queue
+	private.HandleFunc("/lobbies", s.handleLobbiesCreate()).Methods("POST")
+	private.HandleFunc("/lobbies/{id:[0-9]+}", s.handleLobbiesGet()).Methods("GET")
+	private.HandleFunc("/lobbies/{id:[0-9]+}/join", s.handleLobbiesJoin()).Methods("POST")
+	private.HandleFunc("/lobbies/{id:[0-9]+}/leave", s.handleLobbiesLeave()).Methods("POST")
+	private.HandleFunc("/lobbies/{id:[0-9]+}/kick/{user_id:[0-9]+}", s.handleLobbiesKick()).Methods("POST")
+	private.HandleFunc("/lobbies/{id:[0-9]+}/invite/{user_id:[0-9]+}", s.handleLobbiesInvite()).Methods("POST")
+	private.HandleFunc("/lobbies/{id:[0-9]+}/ready", s.handleLobbiesReady()).Methods("POST")
+	private.HandleFunc("/lobbies/{id:[0-9]+}/start", s.handleLobbiesStart()).Methods("POST")
+	private.HandleFunc("/lobbies/{id:[0-9]+}/ws", s.handleLobbiesSocket()).Methods("GET")
+	private.HandleFunc("/matchmaking", s.handleMatchmakingEnqueue()).Methods("POST")
+	private.HandleFunc("/matchmaking", s.handleMatchmakingStatus()).Methods("GET")
+	private.HandleFunc("/matchmaking", s.handleMatchmakingCancel()).Methods("DELETE")
+	// Registering routes of match results
+	private.HandleFunc("/matches", s.handleMatchesCreate()).Methods("POST")
+	private.HandleFunc("/matches/{id:[0-9]+}", s.handleMatchesGet()).Methods("GET")
+	private.HandleFunc("/matches/{id:[0-9]+}/confirm", s.handleMatchesConfirm()).Methods("POST")
+	private.HandleFunc("/matches/{id:[0-9]+}/dispute", s.handleMatchesDispute()).Methods("POST")
+	// Registering routes of tournament organization and participation
+	private.HandleFunc("/tournaments", s.handleTournamentsCreate()).Methods("POST")
+	private.HandleFunc("/tournaments/{id:[0-9]+}/register", s.handleTournamentsRegister()).Methods("POST")
+	private.HandleFunc("/tournaments/{id:[0-9]+}/register", s.handleTournamentsUnregister()).Methods("DELETE")
+	private.HandleFunc("/tournaments/{id:[0-9]+}/start", s.handleTournamentsStart()).Methods("POST")
+	private.HandleFunc("/tournaments/{id:[0-9]+}/withdraw", s.handleTournamentsWithdraw()).Methods("POST")
+	private.HandleFunc("/tournaments/{id:[0-9]+}/matches/{match_id:[0-9]+}/result", s.handleTournamentsResult()).Methods("POST")
+	private.HandleFunc("/tournaments/{id:[0-9]+}/matches/{match_id:[0-9]+}/no-show", s.handleTournamentsNoShow()).Methods("POST")
+	// Registering routes of guild membership. Permissions of guild
+	// members are checked by authorizeGuild.
+	private.HandleFunc("/me/guild", s.handleMyGuild()).Methods("GET")
+	private.HandleFunc("/me/guild/invites", s.handleMyGuildInvites()).Methods("GET")
+	private.HandleFunc("/guilds", s.handleGuildsCreate()).Methods("POST")
+	private.HandleFunc("/guilds/{id:[0-9]+}/join", s.handleGuildsJoin()).Methods("POST")
+	private.HandleFunc("/guilds/{id:[0-9]+}/join", s.handleGuildsJoinCancel()).Methods("DELETE")
+	private.HandleFunc("/guilds/{id:[0-9]+}", s.authorizeGuild(authz.GuildEdit, s.handleGuildsUpdate())).Methods("PUT")
+	private.HandleFunc("/guilds/{id:[0-9]+}", s.authorizeGuild(authz.GuildDisband, s.handleGuildsDisband())).Methods("DELETE")
+	private.HandleFunc("/guilds/{id:[0-9]+}/leave", s.authorizeGuild(authz.GuildLeave, s.handleGuildsLeave())).Methods("POST")
+	private.HandleFunc("/guilds/{id:[0-9]+}/invites", s.authorizeGuild(authz.GuildInvite, s.handleGuildsInvitesList())).Methods("GET")
+	private.HandleFunc("/guilds/{id:[0-9]+}/invites/{user_id:[0-9]+}", s.authorizeGuild(authz.GuildInvite, s.handleGuildsInvite())).Methods("POST")
+	private.HandleFunc("/guilds/{id:[0-9]+}/invites/{user_id:[0-9]+}", s.authorizeGuild(authz.GuildInvite, s.handleGuildsInviteCancel())).Methods("DELETE")
+	private.HandleFunc("/guilds/{id:[0-9]+}/members/{user_id:[0-9]+}", s.authorizeGuild(authz.GuildKick, s.handleGuildsKick())).Methods("DELETE")
+	private.HandleFunc("/guilds/{id:[0-9]+}/members/{user_id:[0-9]+}/role", s.authorizeGuild(authz.GuildSetRole, s.handleGuildsSetRole())).Methods("PUT")
+	private.HandleFunc("/guilds/{id:[0-9]+}/transfer/{user_id:[0-9]+}", s.authorizeGuild(authz.GuildTransfer, s.handleGuildsTransfer())).Methods("POST")
+	// Registering routes of user's own LFG posts and join requests
+	private.HandleFunc("/lfg", s.handleLFGCreate()).Methods("POST")
+	private.HandleFunc("/lfg/{id:[0-9]+}/close", s.handleLFGClose()).Methods("POST")
+	private.HandleFunc("/lfg/{id:[0-9]+}/requests", s.handleLFGRequestsList()).Methods("GET")
+	private.HandleFunc("/lfg/{id:[0-9]+}/requests", s.handleLFGRequestsCreate()).Methods("POST")
+	// Registering routes of hosting, RSVPing and the calendar of events
+	private.HandleFunc("/me/events.ics", s.handleMyEventsCalendar()).Methods("GET")
+	private.HandleFunc("/me/calendar-token", s.handleCalendarTokenCreate()).Methods("POST")
+	private.HandleFunc("/me/calendar-token", s.handleCalendarTokenRevoke()).Methods("DELETE")
+	private.HandleFunc("/events", s.handleEventsCreate()).Methods("POST")
+	private.HandleFunc("/events/{id:[0-9]+}/rsvp", s.handleEventsRSVP()).Methods("PUT")
+	// Registering a new route for /private/admin url path prefix and
+	// creating a subrouter for moderators only.
+	admin := private.PathPrefix("/admin").Subrouter()
+	// Appending middleware func requireRole to the router chain
+	admin.Use(s.traceMiddleware("requireRole", s.requireRole(model.RoleModerator)))
+	// Registering routes of dispute resolution
+	admin.HandleFunc("/matches", s.handleAdminMatchesList()).Methods("GET")
+	admin.HandleFunc("/matches/{id:[0-9]+}/resolve", s.handleAdminMatchesResolve()).Methods("POST")
+	// Registering routes of the moderator queue and sanctions
+	admin.HandleFunc("/reports", s.handleAdminReportsList()).Methods("GET")
+	admin.HandleFunc("/reports/{id:[0-9]+}", s.handleAdminReportsGet()).Methods("GET")
+	admin.HandleFunc("/reports/{id:[0-9]+}/resolve", s.handleAdminReportsResolve()).Methods("POST")
+	admin.HandleFunc("/sanctions/{id:[0-9]+}", s.handleAdminSanctionsLift()).Methods("DELETE")
+	// Tracing every handler registered above
+	s.traceHandlers()
+}
+
+// configureTextFilter func. Sets up the text filter of user-generated
+// content and flood detection of chat with the config
+func (s *server) configureTextFilter(c *textfilter.Config) {
+	s.textFilter = c.Pipeline()
+	s.chatFlood = c.NewFlood()
 }
 
 // setRequestID func. Middleware func for http handler, that sets id in
@@ -94,24 +348,35 @@ func (s *server) setRequestID(next http.Handler) http.Handler {
 }
 
 // logRequest func. Middleware func for http handler, that
-// logs requests.
+// logs requests. The logger of the request is passed in its
+// context, so handlers log with the same fields.
 func (s *server) logRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Creating new logger that has two fields
-		logger := s.logger.WithFields(logrus.Fields{
+		// Creating new logger that has fields of the request
+		fields := logrus.Fields{
 			// Writing the address that sent network request
 			"remote_addr": r.RemoteAddr,
 			// Writing the request id from context with value
 			"request_id": r.Context().Value(ctxKeyRequestID),
-		})
-		// Logging request method and unmodified request-target
-		logger.Infof("started %s %s", r.Method, r.RequestURI)
+			"method":     r.Method,
+		}
+		// Writing the template of the matched route, e.g. /games/{id}
+		if route := routeTemplate(r); route != "" {
+			fields["route"] = route
+		}
+		// Writing the trace id, so logs and spans can be matched
+		if span := trace.SpanFromContext(r.Context()); span != nil {
+			fields["trace_id"] = span.Context().TraceID.String()
+		}
+		ctx := logging.NewContext(r.Context(), s.logger.WithFields(fields))
+		// Logging request method and request-target without secrets
+		logging.FromContext(ctx).Infof("started %s %s", r.Method, redactRequestURI(r))
 		// Getting local time
 		start := time.Now()
 		// Making response with statut ok
 		rw := &responseWriter{w, http.StatusOK}
 		// Serving response
-		next.ServeHTTP(rw, r)
+		next.ServeHTTP(rw, r.WithContext(ctx))
 		// Logger variable
 		var level logrus.Level
 		switch {
@@ -125,8 +390,9 @@ func (s *server) logRequest(next http.Handler) http.Handler {
 			// Assigning logger level with information
 			level = logrus.InfoLevel
 		}
-		// Main logging process
-		logger.Logf(
+		// Main logging process, with fields added while serving,
+		// e.g. the authenticated user
+		logging.FromContext(ctx).Logf(
 			// Logger level
 			level,
 			"completed with %d %s in %v",
@@ -140,6 +406,21 @@ func (s *server) logRequest(next http.Handler) http.Handler {
 	})
 }
 
+// redactRequestURI func. Returns the request URI of the request with
+// values of sensitive query parameters and path variables redacted,
+// e.g. the token of /calendars/{token}.ics
+func redactRequestURI(r *http.Request) string {
+	u := *r.URL
+	for name, value := range mux.Vars(r) {
+		if logging.Sensitive(name) && value != "" {
+			u.Path = strings.Replace(u.Path, value, logging.Redacted, -1)
+			u.RawPath = ""
+		}
+	}
+
+	return logging.RedactURL(&u)
+}
+
 // authenticateUser func. Middleware func for http handler, that
 // autentificates user.
 func (s *server) authenticateUser(next http.Handler) http.Handler {
@@ -157,16 +438,59 @@ func (s *server) authenticateUser(next http.Handler) http.Handler {
 			return
 		}
 		// Checking for user autentification
-		u, err := s.store.User().Find(id.(int))
+		u, err := s.store.User().Find(r.Context(), id.(int))
 		if err != nil {
 			s.error(w, r, http.StatusUnauthorized, errNotAuthenticated)
 			return
 		}
+		// Logging the rest of the request with the user
+		logging.AddFields(r.Context(), logrus.Fields{"user_id": u.ID})
+		// Checking that the user isn't suspended or banned
+		sanction, err := s.activeSanction(r, u.ID, model.SanctionSuspend, model.SanctionBan)
+		if err != nil {
+			s.error(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		if sanction != nil {
+			s.respond(w, r, http.StatusForbidden, sanctionResponse(sanction))
+			return
+		}
 		// Serving response with context
 		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKeyUser, u)))
 	})
 }
 
+// requireRole func. Returns middleware func for http handler, that lets
+// only users with the imported role or a more powerful one through. It
+// has to follow authenticateUser in the router chain.
+func (s *server) requireRole(role string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Checking role of the actual user
+			if !r.Context().Value(ctxKeyUser).(*model.User).HasRole(role) {
+				s.error(w, r, http.StatusForbidden, errForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// sessionUserID func. Returns id of the user of the request session, if
+// the request has one. Public routes use it to tailor the response to
+// the viewer without requiring authentication.
+func (s *server) sessionUserID(r *http.Request) int {
+	session, err := s.sessionStore.Get(r, sessionName)
+	if err != nil {
+		return 0
+	}
+
+	id, _ := session.Values["user_id"].(int)
+
+	return id
+}
+
 // handleUsersCreate func. Middleware func for http handler, that
 // handles user creation.
 func (s *server) handleUsersCreate() http.HandlerFunc {
@@ -191,8 +515,8 @@ func (s *server) handleUsersCreate() http.HandlerFunc {
 			Password: req.Password,
 		}
 		// Adding user model to DB
-		if err := s.store.User().Create(u); err != nil {
-			s.error(w, r, http.StatusUnprocessableEntity, err)
+		if err := s.store.User().Create(r.Context(), u); err != nil {
+			s.storeError(w, r, err)
 			return
 		}
 		// Clearing users password field
@@ -220,8 +544,9 @@ func (s *server) handleSessionsCreate() http.HandlerFunc {
 			return
 		}
 		// Finding user with Email from the request
-		u, err := s.store.User().FindByEmail(req.Email)
+		u, err := s.store.User().FindByEmail(r.Context(), req.Email)
 		if err != nil || !u.ComparePassword(req.Password) {
+			s.metrics.logins.Inc(loginFailure)
 			s.error(w, r, http.StatusUnauthorized, errIncorrectEmailOrPassword)
 			return
 		}
@@ -238,6 +563,9 @@ func (s *server) handleSessionsCreate() http.HandlerFunc {
 			s.error(w, r, http.StatusInternalServerError, err)
 			return
 		}
+		s.metrics.logins.Inc(loginSuccess)
+		// Logging in counts for membership achievements
+		s.recordEvent(r, &model.UserEvent{UserID: u.ID, Type: model.EventLogin})
 		// Creating response with status 200 (OK status)
 		s.respond(w, r, http.StatusOK, nil)
 	}
@@ -259,6 +587,39 @@ func (s *server) error(w http.ResponseWriter, r *http.Request, code int, err err
 	s.respond(w, r, code, map[string]string{"error": err.Error()})
 }
 
+// storeError func. Function that creates an error response with status
+// code that matches the error returned by the store. Queries that timed
+// out or were cancelled mean the store is unavailable.
+func (s *server) storeError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err.(type) {
+	case validation.Errors, validation.Error:
+		s.error(w, r, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	switch err {
+	case store.ErrRecordNotFound:
+		s.error(w, r, http.StatusNotFound, err)
+	case store.ErrRecordExists, model.ErrInvalidFriendshipTransition, model.ErrInvalidMatchTransition:
+		s.error(w, r, http.StatusConflict, err)
+	case context.DeadlineExceeded, context.Canceled:
+		s.error(w, r, http.StatusServiceUnavailable, err)
+	default:
+		s.error(w, r, http.StatusInternalServerError, err)
+	}
+}
+
+// pathInt func. Function that returns integer path variable of the
+// request with the imported name.
+func pathInt(r *http.Request, name string) (int, error) {
+	v, err := strconv.Atoi(mux.Vars(r)[name])
+	if err != nil {
+		return 0, errInvalidPathParam
+	}
+
+	return v, nil
+}
+
 // respond func. Function that writes status code in response header
 // and encodes data in json for response.
 func (s *server) respond(w http.ResponseWriter, r *http.Request, code int, data interface{}) {
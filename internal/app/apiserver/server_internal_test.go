@@ -2,6 +2,7 @@ package apiserver
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"github.com/gorilla/securecookie"
 	"github.com/gorilla/sessions"
 
+	"github.com/GShamian/tavern-of-games/internal/app/logging"
 	"github.com/GShamian/tavern-of-games/internal/app/model"
 
 	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
@@ -20,7 +22,7 @@ import (
 func TestServer_AuthenticateUser(t *testing.T) {
 	store := teststore.New()
 	u := model.TestUser(t)
-	store.User().Create(u)
+	store.User().Create(context.Background(), u)
 
 	testCases := []struct {
 		name         string
@@ -75,6 +77,14 @@ func TestServer_HandleUsersCreate(t *testing.T) {
 			},
 			expectedCode: http.StatusCreated,
 		},
+		{
+			name: "taken email",
+			payload: map[string]interface{}{
+				"email":    "user@example.org",
+				"password": "another",
+			},
+			expectedCode: http.StatusConflict,
+		},
 		{
 			name:         "invalid payload",
 			payload:      "invalid",
@@ -105,7 +115,7 @@ func TestServer_HandleUsersCreate(t *testing.T) {
 func TestServer_HandleSessionsCreate(t *testing.T) {
 	store := teststore.New()
 	u := model.TestUser(t)
-	store.User().Create(u)
+	store.User().Create(context.Background(), u)
 	s := newServer(store, sessions.NewCookieStore([]byte("secret")))
 	testCases := []struct {
 		name         string
@@ -154,3 +164,109 @@ func TestServer_HandleSessionsCreate(t *testing.T) {
 		})
 	}
 }
+
+// testCookie func. Returns Cookie header value with session of the user
+func testCookie(t *testing.T, secretKey []byte, userID int) string {
+	t.Helper()
+
+	cookieStr, err := securecookie.New(secretKey, nil).Encode(sessionName, map[interface{}]interface{}{
+		"user_id": userID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fmt.Sprintf("%s=%s", sessionName, cookieStr)
+}
+
+func TestServer_LogRequest(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	store.User().Create(context.Background(), u)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	buf := &bytes.Buffer{}
+	s.logger.SetOutput(buf)
+	assert.NoError(t, logging.Configure(s.logger, "info", logging.FormatJSON))
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/private/whoami?token=abc", nil)
+	req.Header.Set("Cookie", testCookie(t, secretKey, u.ID))
+	s.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	entries := []map[string]interface{}{}
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		entry := map[string]interface{}{}
+		if !assert.NoError(t, dec.Decode(&entry)) {
+			return
+		}
+		entries = append(entries, entry)
+	}
+	if !assert.Len(t, entries, 2) {
+		return
+	}
+
+	started, completed := entries[0], entries[1]
+	assert.Equal(t, "started GET /private/whoami?token=%5BREDACTED%5D", started["msg"])
+	assert.Equal(t, "/private/whoami", started["route"])
+	assert.Equal(t, rec.Header().Get("X-Request-ID"), started["request_id"])
+	assert.Nil(t, started["user_id"])
+	// The user is known once the request is authenticated
+	assert.Equal(t, started["request_id"], completed["request_id"])
+	assert.Equal(t, float64(u.ID), completed["user_id"])
+}
+
+func TestServer_LogRequest_PathToken(t *testing.T) {
+	s := newServer(teststore.New(), sessions.NewCookieStore([]byte("secret")))
+	buf := &bytes.Buffer{}
+	s.logger.SetOutput(buf)
+	assert.NoError(t, logging.Configure(s.logger, "info", logging.FormatJSON))
+
+	token := "0123456789abcdef0123456789abcdef"
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/calendars/"+token+".ics", nil)
+	s.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	assert.NotContains(t, buf.String(), token)
+	assert.Contains(t, buf.String(), "started GET /calendars/%5BREDACTED%5D.ics")
+}
+
+func TestServer_StoreContext(t *testing.T) {
+	store := teststore.New()
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+	s := newServer(store, sessions.NewCookieStore([]byte("secret")))
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name         string
+		ctx          context.Context
+		expectedCode int
+	}{
+		{
+			name:         "live request",
+			ctx:          context.Background(),
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "cancelled request",
+			ctx:          cancelled,
+			expectedCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/games/%d", g.ID), nil)
+			s.ServeHTTP(rec, req.WithContext(tc.ctx))
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,423 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/tournament"
+)
+
+var (
+	errNotOrganizer         = errors.New("only the organizer can do this")
+	errTournamentNotStarted = errors.New("tournament hasn't started")
+)
+
+// handleTournamentsList func. Handler func that returns tournaments with
+// the status from status query parameter, open for registration by default.
+func (s *server) handleTournamentsList() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q, err := parseListQuery(r)
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		status := r.URL.Query().Get("status")
+		if status == "" {
+			status = model.TournamentRegistration
+		}
+
+		tournaments, err := s.store.Tournament().FindByStatus(r.Context(), status, q)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, tournaments)
+	}
+}
+
+// handleTournamentsGet func. Handler func that returns the tournament
+// with its participants
+func (s *server) handleTournamentsGet() http.HandlerFunc {
+	type response struct {
+		*model.Tournament
+		Participants []*model.TournamentParticipant `json:"participants"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		t, err := s.store.Tournament().Find(r.Context(), id)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		participants, err := s.store.Tournament().Participants(r.Context(), id)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, &response{t, participants})
+	}
+}
+
+// handleTournamentsBracket func. Handler func that exports the bracket
+// of the started tournament with standings and the champion
+func (s *server) handleTournamentsBracket() http.HandlerFunc {
+	type response struct {
+		*tournament.Bracket
+		Standings []*tournament.Standing `json:"standings"`
+		Champion  int                    `json:"champion,omitempty"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		t, err := s.store.Tournament().Find(r.Context(), id)
+		if err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+
+		if t.Bracket == nil {
+			s.error(w, r, http.StatusConflict, errTournamentNotStarted)
+			return
+		}
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, &response{
+			Bracket:   t.Bracket,
+			Standings: t.Bracket.Standings(),
+			Champion:  t.Bracket.Champion(),
+		})
+	}
+}
+
+// handleTournamentsCreate func. Handler func that creates a tournament
+// organized by the actual user
+func (s *server) handleTournamentsCreate() http.HandlerFunc {
+	type request struct {
+		GameID               int       `json:"game_id"`
+		Name                 string    `json:"name"`
+		Format               string    `json:"format"`
+		Seeding              string    `json:"seeding"`
+		MaxPlayers           int       `json:"max_players"`
+		Rounds               int       `json:"rounds"`
+		RegistrationOpensAt  time.Time `json:"registration_opens_at"`
+		RegistrationClosesAt time.Time `json:"registration_closes_at"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		t := &model.Tournament{
+			GameID:               req.GameID,
+			OrganizerID:          u.ID,
+			Name:                 req.Name,
+			Format:               req.Format,
+			Seeding:              req.Seeding,
+			MaxPlayers:           req.MaxPlayers,
+			Rounds:               req.Rounds,
+			RegistrationOpensAt:  req.RegistrationOpensAt,
+			RegistrationClosesAt: req.RegistrationClosesAt,
+		}
+		if err := s.store.Tournament().Create(r.Context(), t); err != nil {
+			s.storeError(w, r, err)
+			return
+		}
+		// Creating response with status 201 (Tournament created)
+		s.respond(w, r, http.StatusCreated, t)
+	}
+}
+
+// handleTournamentsRegister func. Handler func that registers the actual
+// user for the tournament
+func (s *server) handleTournamentsRegister() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.store.Tournament().Register(r.Context(), id, u.ID); err != nil {
+			s.tournamentError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleTournamentsUnregister func. Handler func that cancels registration
+// of the actual user for the tournament
+func (s *server) handleTournamentsUnregister() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.store.Tournament().Unregister(r.Context(), id, u.ID); err != nil {
+			s.tournamentError(w, r, err)
+			return
+		}
+		// Creating response with status 204 (No content)
+		s.respond(w, r, http.StatusNoContent, nil)
+	}
+}
+
+// handleTournamentsStart func. Handler func that lets the organizer
+// close the registration, seed the participants and create the bracket
+func (s *server) handleTournamentsStart() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		// Seeding participants in the transaction the tournament is
+		// locked in, so they don't change until it starts
+		var t *model.Tournament
+		err = s.store.WithTx(r.Context(), func(tx store.Store) error {
+			var err error
+			t, err = tx.Tournament().Update(r.Context(), id, func(t *model.Tournament) error {
+				if t.OrganizerID != u.ID {
+					return errNotOrganizer
+				}
+
+				players, err := s.seedTournament(r, tx, t)
+				if err != nil {
+					return err
+				}
+
+				return t.Start(players)
+			})
+
+			return err
+		})
+		if err != nil {
+			s.tournamentError(w, r, err)
+			return
+		}
+		s.notifyTournamentMatches(r, t, nil)
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, t)
+	}
+}
+
+// handleTournamentsResult func. Handler func that reports result of the
+// bracket match. Results are reported by players of the match or by the
+// organizer, draws are allowed in round robin and Swiss tournaments only.
+func (s *server) handleTournamentsResult() http.HandlerFunc {
+	type request struct {
+		WinnerID int  `json:"winner_id"`
+		Draw     bool `json:"draw"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		s.advanceTournament(w, r, func(t *model.Tournament, m *tournament.Match) error {
+			if t.OrganizerID != u.ID && m.A.UserID != u.ID && m.B.UserID != u.ID {
+				return errForbidden
+			}
+
+			if req.Draw {
+				return t.Bracket.ReportDraw(m.ID)
+			}
+
+			return t.Bracket.Report(m.ID, req.WinnerID)
+		})
+	}
+}
+
+// handleTournamentsNoShow func. Handler func that lets the organizer
+// record that the player didn't show up for the bracket match
+func (s *server) handleTournamentsNoShow() http.HandlerFunc {
+	type request struct {
+		UserID int `json:"user_id"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		// Decoding json from request to our entity
+		req := &request{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		s.advanceTournament(w, r, func(t *model.Tournament, m *tournament.Match) error {
+			if t.OrganizerID != u.ID {
+				return errNotOrganizer
+			}
+
+			return t.Bracket.Forfeit(m.ID, req.UserID)
+		})
+	}
+}
+
+// handleTournamentsWithdraw func. Handler func that withdraws the actual
+// user from the running tournament. The user forfeits the rest of matches.
+func (s *server) handleTournamentsWithdraw() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		u := r.Context().Value(ctxKeyUser).(*model.User)
+		id, err := pathInt(r, "id")
+		if err != nil {
+			s.error(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		var ready map[int]bool
+		t, err := s.store.Tournament().Update(r.Context(), id, func(t *model.Tournament) error {
+			ready = readyMatches(t.Bracket)
+
+			return t.Advance(func(b *tournament.Bracket) error {
+				return b.Withdraw(u.ID)
+			})
+		})
+		if err != nil {
+			s.tournamentError(w, r, err)
+			return
+		}
+		s.recordTournament(r, t)
+		s.notifyTournamentMatches(r, t, ready)
+		// Creating response with status 200 (OK status)
+		s.respond(w, r, http.StatusOK, t)
+	}
+}
+
+// advanceTournament func. Applies the change to the bracket match from
+// the request path and responds with the match
+func (s *server) advanceTournament(w http.ResponseWriter, r *http.Request, change func(*model.Tournament, *tournament.Match) error) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		s.error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	matchID, err := pathInt(r, "match_id")
+	if err != nil {
+		s.error(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	var m *tournament.Match
+	var ready map[int]bool
+	t, err := s.store.Tournament().Update(r.Context(), id, func(t *model.Tournament) error {
+		ready = readyMatches(t.Bracket)
+
+		return t.Advance(func(b *tournament.Bracket) error {
+			if m, err = b.Match(matchID); err != nil {
+				return err
+			}
+
+			return change(t, m)
+		})
+	})
+	if err != nil {
+		s.tournamentError(w, r, err)
+		return
+	}
+	s.recordTournament(r, t)
+	s.notifyTournamentMatches(r, t, ready)
+	// Creating response with status 200 (OK status)
+	s.respond(w, r, http.StatusOK, m)
+}
+
+// recordTournament func. Records the win of the champion, once the
+// tournament is finished. Finished tournaments can't change, so the
+// win is recorded once.
+func (s *server) recordTournament(r *http.Request, t *model.Tournament) {
+	if t.Status != model.TournamentFinished {
+		return
+	}
+
+	if champion := t.Bracket.Champion(); champion != 0 {
+		s.recordEvent(r, &model.UserEvent{UserID: champion, Type: model.EventTournamentWon, GameID: t.GameID})
+		s.recordActivity(r, &model.Activity{
+			UserID: champion,
+			Type:   model.ActivityTournamentWon,
+			GameID: t.GameID,
+			Text:   fmt.Sprintf("won tournament %q", t.Name),
+			Link:   fmt.Sprintf("/tournaments/%d", t.ID),
+		})
+	}
+}
+
+// seedTournament func. Returns participants of the tournament in seed
+// order, read from the imported store. Ratings are skill ratings of the
+// tournament game, random seeding is seeded with the tournament id, so
+// it can be replayed.
+func (s *server) seedTournament(r *http.Request, st store.Store, t *model.Tournament) ([]int, error) {
+	participants, err := st.Tournament().Participants(r.Context(), t.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	entrants := make([]tournament.Entrant, len(participants))
+	for i, p := range participants {
+		entrants[i].UserID = p.UserID
+		if t.Seeding != tournament.SeedByRating {
+			continue
+		}
+		skill, err := skillRating(r, st, p.UserID, t.GameID)
+		if err != nil {
+			return nil, err
+		}
+		entrants[i].Rating = float64(skill)
+	}
+
+	return tournament.Seed(entrants, t.Seeding, int64(t.ID))
+}
+
+// tournamentError func. Function that creates an error response with
+// status code that matches the tournament error.
+func (s *server) tournamentError(w http.ResponseWriter, r *http.Request, err error) {
+	switch err {
+	case tournament.ErrMatchNotFound:
+		s.error(w, r, http.StatusNotFound, err)
+	case errForbidden, errNotOrganizer, tournament.ErrNotInBracket:
+		s.error(w, r, http.StatusForbidden, err)
+	case tournament.ErrMatchNotReady, tournament.ErrTooFewPlayers, model.ErrRegistrationClosed,
+		model.ErrTournamentFull, model.ErrTournamentNotRunning:
+		s.error(w, r, http.StatusConflict, err)
+	case tournament.ErrDrawNotAllowed, tournament.ErrNotInMatch:
+		s.error(w, r, http.StatusUnprocessableEntity, err)
+	default:
+		s.storeError(w, r, err)
+	}
+}
@@ -0,0 +1,243 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/tournament"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_HandleTournaments(t *testing.T) {
+	store := teststore.New()
+	organizer := model.TestUser(t)
+	store.User().Create(context.Background(), organizer)
+	u1 := model.TestUser(t)
+	u1.Email = "user1@example.org"
+	store.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	store.User().Create(context.Background(), u2)
+	u3 := model.TestUser(t)
+	u3.Email = "user3@example.org"
+	store.User().Create(context.Background(), u3)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	now := time.Now().UTC()
+
+	testCases := []struct {
+		name         string
+		user         *model.User
+		method       string
+		path         string
+		payload      interface{}
+		expectedCode int
+	}{
+		{
+			name:   "create",
+			user:   organizer,
+			method: http.MethodPost,
+			path:   "/private/tournaments",
+			payload: map[string]interface{}{
+				"game_id":                g.ID,
+				"name":                   "Weekly cup",
+				"format":                 tournament.SingleElimination,
+				"seeding":                tournament.SeedRandom,
+				"max_players":            3,
+				"registration_opens_at":  now.Add(-time.Hour),
+				"registration_closes_at": now.Add(time.Hour),
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "create invalid",
+			user:   organizer,
+			method: http.MethodPost,
+			path:   "/private/tournaments",
+			payload: map[string]interface{}{
+				"game_id":     g.ID,
+				"name":        "Weekly cup",
+				"format":      "ladder",
+				"seeding":     tournament.SeedRandom,
+				"max_players": 8,
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "register",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         "/private/tournaments/1/register",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "register twice",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         "/private/tournaments/1/register",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "bracket before start",
+			user:         u1,
+			method:       http.MethodGet,
+			path:         "/tournaments/1/bracket",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "start alone",
+			user:         organizer,
+			method:       http.MethodPost,
+			path:         "/private/tournaments/1/start",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "register second",
+			user:         u2,
+			method:       http.MethodPost,
+			path:         "/private/tournaments/1/register",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "register third",
+			user:         u3,
+			method:       http.MethodPost,
+			path:         "/private/tournaments/1/register",
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "register when full",
+			user:         organizer,
+			method:       http.MethodPost,
+			path:         "/private/tournaments/1/register",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "start by player",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         "/private/tournaments/1/start",
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "start",
+			user:         organizer,
+			method:       http.MethodPost,
+			path:         "/private/tournaments/1/start",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "unregister after start",
+			user:         u1,
+			method:       http.MethodDelete,
+			path:         "/private/tournaments/1/register",
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "report unknown match",
+			user:         organizer,
+			method:       http.MethodPost,
+			path:         "/private/tournaments/1/matches/10/result",
+			payload:      map[string]interface{}{"winner_id": u1.ID},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "report draw",
+			user:         organizer,
+			method:       http.MethodPost,
+			path:         "/private/tournaments/1/matches/2/result",
+			payload:      map[string]interface{}{"draw": true},
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "no-show by player",
+			user:         u1,
+			method:       http.MethodPost,
+			path:         "/private/tournaments/1/matches/2/no-show",
+			payload:      map[string]interface{}{"user_id": u2.ID},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "withdraw by outsider",
+			user:         organizer,
+			method:       http.MethodPost,
+			path:         "/private/tournaments/1/withdraw",
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			if tc.payload != nil {
+				json.NewEncoder(b).Encode(tc.payload)
+			}
+			rec := httptest.NewRecorder()
+			req, _ := http.NewRequest(tc.method, tc.path, b)
+			req.Header.Set("Cookie", testCookie(t, secretKey, tc.user.ID))
+			s.ServeHTTP(rec, req)
+			assert.Equal(t, tc.expectedCode, rec.Code)
+		})
+	}
+
+	// Playing the rest of the bracket: three players, so the top seed
+	// has a bye and the other two play the first match
+	tr, _ := store.Tournament().Find(context.Background(), 1)
+	first := tr.Bracket.Matches[1]
+	if first.Bye {
+		first = tr.Bracket.Matches[0]
+	}
+	report := func(u *model.User, matchID int, payload interface{}) int {
+		b := &bytes.Buffer{}
+		json.NewEncoder(b).Encode(payload)
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/private/tournaments/1/matches/%d/result", matchID), b)
+		req.Header.Set("Cookie", testCookie(t, secretKey, u.ID))
+		s.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	player := u1
+	if first.A.UserID != u1.ID && first.B.UserID != u1.ID {
+		player = u2
+	}
+	assert.Equal(t, http.StatusUnprocessableEntity, report(player, first.ID, map[string]interface{}{"winner_id": organizer.ID}))
+	assert.Equal(t, http.StatusOK, report(player, first.ID, map[string]interface{}{"winner_id": player.ID}))
+	assert.Equal(t, http.StatusOK, report(organizer, 3, map[string]interface{}{"winner_id": player.ID}))
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/tournaments/1/bracket", nil)
+	s.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	bracket := &struct {
+		Format   string `json:"format"`
+		Champion int    `json:"champion"`
+	}{}
+	json.NewDecoder(rec.Body).Decode(bracket)
+	assert.Equal(t, tournament.SingleElimination, bracket.Format)
+	assert.Equal(t, player.ID, bracket.Champion)
+
+	tr, _ = store.Tournament().Find(context.Background(), 1)
+	assert.Equal(t, model.TournamentFinished, tr.Status)
+
+	// The champion was notified about the first match and the final
+	notifications, err := store.Notification().FindByUser(context.Background(), player.ID, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 2)
+	for _, n := range notifications {
+		assert.Equal(t, model.NotificationTournamentMatch, n.Type)
+	}
+}
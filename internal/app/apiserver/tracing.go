@@ -0,0 +1,112 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/GShamian/tavern-of-games/internal/app/trace"
+	"github.com/gorilla/mux"
+)
+
+// traceparentHeader is the W3C Trace Context header callers pass their
+// span in
+const traceparentHeader = "traceparent"
+
+// Exporters of spans set in config
+const (
+	traceExporterNone   = "none"
+	traceExporterStdout = "stdout"
+)
+
+var (
+	errUnknownTraceExporter = errors.New("unknown trace exporter")
+)
+
+// newTraceExporter func. Returns the exporter of spans with the name
+func newTraceExporter(name string) (trace.Exporter, error) {
+	switch name {
+	case "", traceExporterNone:
+		return trace.Discard, nil
+	case traceExporterStdout:
+		return trace.NewWriterExporter(os.Stdout), nil
+	default:
+		return nil, errUnknownTraceExporter
+	}
+}
+
+// traceRequest func. Middleware func for http handler, that
+// starts the root span of the request. The span continues the
+// trace of the caller if it sent a valid traceparent header.
+func (s *server) traceRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if sc, err := trace.ParseTraceparent(r.Header.Get(traceparentHeader)); err == nil {
+			ctx = trace.ContextWithRemote(ctx, sc)
+		}
+
+		route := routeTemplate(r)
+		ctx, span := s.tracer.Start(ctx, r.Method+" "+route)
+		defer span.End()
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.route", route)
+
+		rw := &responseWriter{w, http.StatusOK}
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		span.SetAttribute("http.status_code", rw.code)
+		if rw.code >= 500 {
+			span.SetError(errors.New(http.StatusText(rw.code)))
+		}
+	})
+}
+
+// traceMiddleware func. Wraps the middleware with its span. The span
+// lasts until the middleware returns, so it covers the rest of the
+// chain too.
+func (s *server) traceMiddleware(name string, mw mux.MiddlewareFunc) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		h := mw(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, span := trace.Start(r.Context(), "middleware "+name)
+			defer span.End()
+
+			h.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// traceHandlers func. Wraps handlers of all routes registered so far
+// with their spans, named by route templates.
+func (s *server) traceHandlers() {
+	s.router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		h := route.GetHandler()
+		if h == nil {
+			return nil
+		}
+
+		tpl, _ := route.GetPathTemplate()
+		name := "handler " + tpl
+		route.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, span := trace.Start(r.Context(), name)
+			defer span.End()
+
+			h.ServeHTTP(w, r.WithContext(ctx))
+		}))
+
+		return nil
+	})
+}
+
+// routeTemplate func. Returns template of the route the request
+// matched, e.g. /games/{id:[0-9]+}, or empty string if it matched none
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
+	}
+
+	tpl, _ := route.GetPathTemplate()
+
+	return tpl
+}
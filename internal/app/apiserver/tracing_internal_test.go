@@ -0,0 +1,120 @@
+package apiserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/sessions"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/trace"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_Tracing(t *testing.T) {
+	store := teststore.New()
+	u := model.TestUser(t)
+	store.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	store.Game().Create(context.Background(), g)
+
+	secretKey := []byte("secret")
+	s := newServer(store, sessions.NewCookieStore(secretKey))
+	exporter := trace.NewMemoryExporter()
+	s.tracer = trace.NewTracer(exporter)
+
+	spans := func() map[string]*trace.SpanData {
+		byName := make(map[string]*trace.SpanData)
+		for _, d := range exporter.Spans() {
+			byName[d.Name] = d
+		}
+		exporter.Reset()
+
+		return byName
+	}
+
+	t.Run("continues trace of the caller", func(t *testing.T) {
+		remote, _ := trace.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/private/whoami", nil)
+		req.Header.Set("Cookie", testCookie(t, secretKey, u.ID))
+		req.Header.Set(traceparentHeader, remote.Traceparent())
+		s.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		byName := spans()
+		root := byName["GET /private/whoami"]
+		if !assert.NotNil(t, root) {
+			return
+		}
+		assert.Equal(t, remote.SpanID, root.ParentID)
+		assert.Equal(t, http.StatusOK, root.Attributes["http.status_code"])
+		assert.Equal(t, "/private/whoami", root.Attributes["http.route"])
+
+		// Middleware funcs nest in the order they're applied
+		chain := []string{
+			"GET /private/whoami",
+			"middleware setRequestID",
+			"middleware logRequest",
+			"middleware measureRequest",
+			"middleware CORS",
+			"middleware authenticateUser",
+			"handler /private/whoami",
+		}
+		for i, name := range chain {
+			d := byName[name]
+			if !assert.NotNil(t, d, name) {
+				continue
+			}
+			assert.Equal(t, remote.TraceID, d.TraceID, name)
+			if i > 0 {
+				assert.Equal(t, byName[chain[i-1]].SpanID, d.ParentID, name)
+			}
+		}
+
+		find := byName["UserRepository.Find"]
+		if assert.NotNil(t, find) {
+			assert.Equal(t, byName["middleware authenticateUser"].SpanID, find.ParentID)
+			assert.Equal(t, "store", find.Attributes["component"])
+		}
+	})
+
+	t.Run("starts new trace", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/games/4242", nil)
+		req.Header.Set(traceparentHeader, "garbage")
+		s.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		byName := spans()
+		root := byName["GET /games/{id:[0-9]+}"]
+		if !assert.NotNil(t, root) {
+			return
+		}
+		assert.False(t, root.ParentID.IsValid())
+
+		find := byName["GameRepository.Find"]
+		if assert.NotNil(t, find) {
+			assert.Equal(t, byName["handler /games/{id:[0-9]+}"].SpanID, find.ParentID)
+			assert.Equal(t, root.TraceID, find.TraceID)
+			assert.NotEmpty(t, find.Error)
+		}
+	})
+}
+
+func TestNewTraceExporter(t *testing.T) {
+	e, err := newTraceExporter(traceExporterNone)
+	assert.NoError(t, err)
+	assert.Equal(t, trace.Discard, e)
+
+	e, err = newTraceExporter(traceExporterStdout)
+	assert.NoError(t, err)
+	assert.IsType(t, &trace.WriterExporter{}, e)
+
+	_, err = newTraceExporter("jaeger")
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+// Package atom writes feeds in Atom format (RFC 4287), so they can be
+// followed from feed readers.
+package atom
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// namespace is the XML namespace of Atom documents
+const namespace = "http://www.w3.org/2005/Atom"
+
+// Feed object that describes a feed with its entries, newest first. ID
+// and IDs of entries are permanent IRIs, e.g. tag URIs (RFC 4151).
+type Feed struct {
+	ID      string
+	Title   string
+	Link    string
+	Author  string
+	Updated time.Time
+	Entries []*Entry
+}
+
+// Entry object that describes an entry of the feed
+type Entry struct {
+	ID      string
+	Title   string
+	Link    string
+	Summary string
+	Updated time.Time
+}
+
+// feedXML and entryXML objects describe XML elements of the feed
+type feedXML struct {
+	XMLName xml.Name    `xml:"feed"`
+	NS      string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Link    *linkXML    `xml:"link,omitempty"`
+	Author  *authorXML  `xml:"author,omitempty"`
+	Updated string      `xml:"updated"`
+	Entries []*entryXML `xml:"entry"`
+}
+
+type entryXML struct {
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Link    *linkXML `xml:"link,omitempty"`
+	Summary string   `xml:"summary,omitempty"`
+	Updated string   `xml:"updated"`
+}
+
+type linkXML struct {
+	Href string `xml:"href,attr"`
+}
+
+type authorXML struct {
+	Name string `xml:"name"`
+}
+
+// WriteTo func. Writes the feed to w. Implements io.WriterTo.
+func (f *Feed) WriteTo(w io.Writer) (int64, error) {
+	doc := &feedXML{
+		NS:      namespace,
+		ID:      f.ID,
+		Title:   f.Title,
+		Link:    link(f.Link),
+		Updated: timestamp(f.Updated),
+	}
+	if f.Author != "" {
+		doc.Author = &authorXML{Name: f.Author}
+	}
+
+	for _, e := range f.Entries {
+		doc.Entries = append(doc.Entries, &entryXML{
+			ID:      e.ID,
+			Title:   e.Title,
+			Link:    link(e.Link),
+			Summary: e.Summary,
+			Updated: timestamp(e.Updated),
+		})
+	}
+
+	cw := &countingWriter{w: w}
+	if _, err := io.WriteString(cw, xml.Header); err != nil {
+		return cw.n, err
+	}
+
+	enc := xml.NewEncoder(cw)
+	enc.Indent("", "  ")
+	err := enc.Encode(doc)
+
+	return cw.n, err
+}
+
+// link func. Returns link element with the href, nil for empty href
+func link(href string) *linkXML {
+	if href == "" {
+		return nil
+	}
+
+	return &linkXML{Href: href}
+}
+
+// timestamp func. Returns the time as RFC 3339 date-time in UTC
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// countingWriter object. Counts bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write func. Implements io.Writer
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+
+	return n, err
+}
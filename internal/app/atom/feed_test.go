@@ -0,0 +1,52 @@
+package atom_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/atom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeed_WriteTo(t *testing.T) {
+	updated := time.Date(2020, 11, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+	f := &atom.Feed{
+		ID:      "tag:tavern-of-games,2020:users/1/activity",
+		Title:   "Activity of user 1",
+		Link:    "http://localhost:8080/users/1",
+		Author:  "User 1",
+		Updated: updated,
+		Entries: []*atom.Entry{
+			{
+				ID:      "tag:tavern-of-games,2020:activities/2",
+				Title:   "User 1 reviewed <Chess & Go>",
+				Link:    "http://localhost:8080/games/1/reviews",
+				Updated: updated,
+			},
+		},
+	}
+
+	b := &bytes.Buffer{}
+	n, err := f.WriteTo(b)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(b.Len()), n)
+	assert.True(t, strings.HasPrefix(b.String(), xml.Header+`<feed xmlns="http://www.w3.org/2005/Atom">`))
+	assert.Contains(t, b.String(), "<updated>2020-11-01T11:00:00Z</updated>")
+	assert.Contains(t, b.String(), "<title>User 1 reviewed &lt;Chess &amp; Go&gt;</title>")
+	assert.Contains(t, b.String(), `<link href="http://localhost:8080/games/1/reviews"></link>`)
+	assert.NotContains(t, b.String(), "<summary>")
+
+	// The feed is well-formed XML
+	parsed := &struct {
+		Entries []struct {
+			ID string `xml:"id"`
+		} `xml:"entry"`
+	}{}
+	assert.NoError(t, xml.Unmarshal(b.Bytes(), parsed))
+	if assert.Len(t, parsed.Entries, 1) {
+		assert.Equal(t, "tag:tavern-of-games,2020:activities/2", parsed.Entries[0].ID)
+	}
+}
@@ -0,0 +1,33 @@
+package authz
+
+import (
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+// Guild actions
+const (
+	GuildChat     = "guild.chat"
+	GuildLeave    = "guild.leave"
+	GuildInvite   = "guild.invite"
+	GuildEdit     = "guild.edit"
+	GuildKick     = "guild.kick"
+	GuildSetRole  = "guild.set_role"
+	GuildTransfer = "guild.transfer"
+	GuildDisband  = "guild.disband"
+)
+
+// Guild policy. Members chat, officers manage membership and the guild
+// profile, the leader manages officers and the guild itself.
+var Guild = NewPolicy(
+	[]string{model.GuildRoleMember, model.GuildRoleOfficer, model.GuildRoleLeader},
+	map[string]string{
+		GuildChat:     model.GuildRoleMember,
+		GuildLeave:    model.GuildRoleMember,
+		GuildInvite:   model.GuildRoleOfficer,
+		GuildEdit:     model.GuildRoleOfficer,
+		GuildKick:     model.GuildRoleOfficer,
+		GuildSetRole:  model.GuildRoleLeader,
+		GuildTransfer: model.GuildRoleLeader,
+		GuildDisband:  model.GuildRoleLeader,
+	},
+)
@@ -0,0 +1,68 @@
+// Package authz implements permission policies of roles. A policy grants
+// every action to the least powerful role allowed to perform it, and all
+// more powerful roles are allowed to perform it as well.
+package authz
+
+import (
+	"errors"
+)
+
+var (
+	// ErrForbidden error tells us, that the role isn't allowed to
+	// perform the action
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Policy object that describes ranked roles and actions they are
+// allowed to perform
+type Policy struct {
+	ranks  map[string]int
+	grants map[string]string
+}
+
+// NewPolicy func. Constructor for a policy. Roles are listed from the
+// least powerful one, grants map actions to the least powerful role
+// allowed to perform them.
+func NewPolicy(roles []string, grants map[string]string) *Policy {
+	p := &Policy{
+		ranks:  make(map[string]int, len(roles)),
+		grants: grants,
+	}
+	for i, role := range roles {
+		p.ranks[role] = i + 1
+	}
+
+	return p
+}
+
+// Can func. Checks that the role is allowed to perform the action.
+// Unknown roles and actions are never allowed.
+func (p *Policy) Can(role, action string) bool {
+	granted, ok := p.grants[action]
+	if !ok || p.ranks[role] == 0 {
+		return false
+	}
+
+	return p.ranks[role] >= p.ranks[granted]
+}
+
+// Outranks func. Checks that the role is more powerful than the other one
+func (p *Policy) Outranks(role, other string) bool {
+	return p.ranks[role] > p.ranks[other]
+}
+
+// Authorize func. Returns ErrForbidden if the role isn't allowed to
+// perform the action. Actions applied to somebody with target role
+// (empty for actions without one) also require the role to outrank
+// the target, so nobody can act on their equals or superiors.
+func (p *Policy) Authorize(role, action, targetRole string) error {
+	if !p.Can(role, action) {
+		return ErrForbidden
+	}
+
+	if targetRole != "" && !p.Outranks(role, targetRole) {
+		return ErrForbidden
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package authz_test
+
+import (
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/authz"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Authorize(t *testing.T) {
+	testCases := []struct {
+		name       string
+		role       string
+		action     string
+		targetRole string
+		allowed    bool
+	}{
+		{
+			name:    "member chats",
+			role:    model.GuildRoleMember,
+			action:  authz.GuildChat,
+			allowed: true,
+		},
+		{
+			name:    "member invites",
+			role:    model.GuildRoleMember,
+			action:  authz.GuildInvite,
+			allowed: false,
+		},
+		{
+			name:    "leader invites",
+			role:    model.GuildRoleLeader,
+			action:  authz.GuildInvite,
+			allowed: true,
+		},
+		{
+			name:       "officer kicks member",
+			role:       model.GuildRoleOfficer,
+			action:     authz.GuildKick,
+			targetRole: model.GuildRoleMember,
+			allowed:    true,
+		},
+		{
+			name:       "officer kicks officer",
+			role:       model.GuildRoleOfficer,
+			action:     authz.GuildKick,
+			targetRole: model.GuildRoleOfficer,
+			allowed:    false,
+		},
+		{
+			name:       "officer kicks leader",
+			role:       model.GuildRoleOfficer,
+			action:     authz.GuildKick,
+			targetRole: model.GuildRoleLeader,
+			allowed:    false,
+		},
+		{
+			name:       "officer promotes member",
+			role:       model.GuildRoleOfficer,
+			action:     authz.GuildSetRole,
+			targetRole: model.GuildRoleMember,
+			allowed:    false,
+		},
+		{
+			name:       "leader promotes officer",
+			role:       model.GuildRoleLeader,
+			action:     authz.GuildSetRole,
+			targetRole: model.GuildRoleOfficer,
+			allowed:    true,
+		},
+		{
+			name:    "unknown role",
+			role:    "guest",
+			action:  authz.GuildChat,
+			allowed: false,
+		},
+		{
+			name:    "unknown action",
+			role:    model.GuildRoleLeader,
+			action:  "guild.rename",
+			allowed: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := authz.Guild.Authorize(tc.role, tc.action, tc.targetRole)
+			if tc.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, authz.ErrForbidden.Error())
+			}
+		})
+	}
+}
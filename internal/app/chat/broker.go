@@ -0,0 +1,77 @@
+package chat
+
+import (
+	"sync"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+// subscriptionBuffer is the number of messages a subscriber can fall
+// behind before the broker starts dropping messages for it
+const subscriptionBuffer = 64
+
+// Broker interface. Fans chat messages out to every subscriber of the
+// message's room. Implementations backed by a shared backend let several
+// server instances serve the same rooms.
+type Broker interface {
+	Publish(*model.ChatMessage) error
+	Subscribe(room string) (<-chan *model.ChatMessage, func())
+}
+
+// MemoryBroker object. In-process Broker implementation. It is safe
+// for concurrent use.
+type MemoryBroker struct {
+	mu    sync.RWMutex
+	rooms map[string]map[chan *model.ChatMessage]struct{}
+}
+
+// NewMemoryBroker func. Constructor for MemoryBroker
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		rooms: make(map[string]map[chan *model.ChatMessage]struct{}),
+	}
+}
+
+// Publish func. Sends the message to every subscriber of its room.
+// Subscribers that can't keep up miss the message instead of blocking
+// the publisher, they can catch up through the chat history.
+func (b *MemoryBroker) Publish(m *model.ChatMessage) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.rooms[m.Room] {
+		select {
+		case ch <- m:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Subscribe func. Subscribes to messages of the room. Returns the channel
+// messages are delivered to and the func that cancels the subscription
+// and closes the channel.
+func (b *MemoryBroker) Subscribe(room string) (<-chan *model.ChatMessage, func()) {
+	ch := make(chan *model.ChatMessage, subscriptionBuffer)
+
+	b.mu.Lock()
+	if b.rooms[room] == nil {
+		b.rooms[room] = make(map[chan *model.ChatMessage]struct{})
+	}
+	b.rooms[room][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.rooms[room], ch)
+			if len(b.rooms[room]) == 0 {
+				delete(b.rooms, room)
+			}
+			close(ch)
+			b.mu.Unlock()
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package chat_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/chat"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBroker_Publish(t *testing.T) {
+	b := chat.NewMemoryBroker()
+	hall, unsubscribeHall := b.Subscribe(model.ChatRoomHall)
+	defer unsubscribeHall()
+	game, unsubscribeGame := b.Subscribe(model.GameChatRoom(1))
+	defer unsubscribeGame()
+
+	m := model.TestChatMessage(t, model.ChatRoomHall, 1)
+	assert.NoError(t, b.Publish(m))
+	assert.Equal(t, m, <-hall)
+	assert.Len(t, game, 0)
+}
+
+func TestMemoryBroker_Subscribe(t *testing.T) {
+	b := chat.NewMemoryBroker()
+	ch, unsubscribe := b.Subscribe(model.ChatRoomHall)
+	unsubscribe()
+	unsubscribe()
+
+	_, ok := <-ch
+	assert.False(t, ok)
+	assert.NoError(t, b.Publish(model.TestChatMessage(t, model.ChatRoomHall, 1)))
+}
+
+func TestMemoryBroker_Concurrent(t *testing.T) {
+	b := chat.NewMemoryBroker()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			ch, unsubscribe := b.Subscribe(model.ChatRoomHall)
+			defer unsubscribe()
+			for j := 0; j < 10; j++ {
+				select {
+				case <-ch:
+				default:
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				b.Publish(model.TestChatMessage(t, model.ChatRoomHall, 1))
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,85 @@
+// Package feed keeps timelines of activity feeds merged on read, so
+// reading the next pages of a feed doesn't merge it again.
+package feed
+
+import (
+	"sync"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+// Cache object. Keeps the timeline of every user for the TTL after it
+// was merged. It is safe for concurrent use.
+type Cache struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	timelines map[int]*timeline
+}
+
+// timeline object. Describes a cached timeline, newest activities first
+type timeline struct {
+	activities []*model.Activity
+	expiresAt  time.Time
+}
+
+// NewCache func. Constructor for Cache
+func NewCache(ttl time.Duration) *Cache {
+	return &Cache{
+		ttl:       ttl,
+		timelines: make(map[int]*timeline),
+	}
+}
+
+// Get func. Returns the timeline of the user, unless it isn't cached or
+// has expired at the time
+func (c *Cache) Get(userID int, now time.Time) ([]*model.Activity, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, ok := c.timelines[userID]
+	if !ok || !now.Before(t.expiresAt) {
+		return nil, false
+	}
+
+	return t.activities, true
+}
+
+// Set func. Caches the timeline of the user merged at the time
+func (c *Cache) Set(userID int, activities []*model.Activity, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.timelines[userID] = &timeline{
+		activities: activities,
+		expiresAt:  now.Add(c.ttl),
+	}
+}
+
+// Invalidate func. Drops timelines of the users, e.g. once whom they
+// follow changes
+func (c *Cache) Invalidate(userIDs ...int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, id := range userIDs {
+		delete(c.timelines, id)
+	}
+}
+
+// Sweep func. Drops timelines which expired at the time. Returns the
+// number of dropped timelines.
+func (c *Cache) Sweep(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for id, t := range c.timelines {
+		if !now.Before(t.expiresAt) {
+			delete(c.timelines, id)
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,43 @@
+package feed_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/feed"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Get(t *testing.T) {
+	c := feed.NewCache(time.Minute)
+	now := time.Now()
+	activities := []*model.Activity{model.TestActivity(t, 2, 1)}
+
+	_, ok := c.Get(1, now)
+	assert.False(t, ok)
+
+	c.Set(1, activities, now)
+	cached, ok := c.Get(1, now.Add(30*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, activities, cached)
+
+	_, ok = c.Get(1, now.Add(time.Minute))
+	assert.False(t, ok)
+
+	c.Set(1, activities, now)
+	c.Invalidate(1, 2)
+	_, ok = c.Get(1, now)
+	assert.False(t, ok)
+}
+
+func TestCache_Sweep(t *testing.T) {
+	c := feed.NewCache(time.Minute)
+	now := time.Now()
+	c.Set(1, nil, now)
+	c.Set(2, nil, now.Add(time.Minute))
+
+	assert.Equal(t, 1, c.Sweep(now.Add(time.Minute)))
+	_, ok := c.Get(2, now.Add(time.Minute))
+	assert.True(t, ok)
+}
@@ -0,0 +1,156 @@
+// Package health runs health checks of subsystems of the server, e.g.
+// the DB or the chat broker, for readiness probes.
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Statuses of checks and reports
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// DefaultTimeout is the timeout of checks registered without one
+const DefaultTimeout = 2 * time.Second
+
+// Check func. Checks the subsystem, it's healthy unless an error is
+// returned. Checks have to give up when the context is done.
+type Check func(ctx context.Context) error
+
+// Result object. Describes the result of a check and how long it took.
+type Result struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report object. Describes results of all checks, the report is ok only
+// if all checks are.
+type Report struct {
+	Status string             `json:"status"`
+	Checks map[string]*Result `json:"checks"`
+}
+
+// OK func. Checks whether all checks are ok
+func (r *Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// check object. Describes a registered check.
+type check struct {
+	name    string
+	timeout time.Duration
+	fn      Check
+}
+
+// Registry object. Keeps checks subsystems register. It is safe for
+// concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	checks map[string]*check
+}
+
+// NewRegistry func. Constructor for Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		checks: make(map[string]*check),
+	}
+}
+
+// Register func. Registers the check with the name and the timeout,
+// replacing the check registered with the name before. Zero timeout
+// means the default one.
+func (r *Registry) Register(name string, timeout time.Duration, fn Check) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks[name] = &check{name: name, timeout: timeout, fn: fn}
+}
+
+// Names func. Returns names of registered checks in order
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.checks))
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Run func. Runs all checks at once, each within its timeout, and
+// reports their results. A check which doesn't return in time fails.
+func (r *Registry) Run(ctx context.Context) *Report {
+	r.mu.RLock()
+	checks := make([]*check, 0, len(r.checks))
+	for _, c := range r.checks {
+		checks = append(checks, c)
+	}
+	r.mu.RUnlock()
+
+	results := make([]*Result, len(checks))
+	wg := sync.WaitGroup{}
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := &Report{
+		Status: StatusOK,
+		Checks: make(map[string]*Result, len(checks)),
+	}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+
+	return report
+}
+
+// run func. Runs the check within its timeout
+func (c *check) run(ctx context.Context) *Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	res := &Result{
+		Status:    StatusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+
+	return res
+}
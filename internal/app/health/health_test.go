@@ -0,0 +1,61 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/health"
+)
+
+func TestRegistry_Run(t *testing.T) {
+	r := health.NewRegistry()
+	report := r.Run(context.Background())
+	assert.True(t, report.OK())
+	assert.Empty(t, report.Checks)
+
+	r.Register("db", time.Second, func(context.Context) error {
+		return nil
+	})
+	r.Register("broker", 0, func(context.Context) error {
+		return errors.New("broker is down")
+	})
+	r.Register("mailer", 10*time.Millisecond, func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	r.Register("blobs", 10*time.Millisecond, func(context.Context) error {
+		// Checks ignoring the context don't hold up the report
+		time.Sleep(time.Second)
+		return nil
+	})
+	assert.Equal(t, []string{"blobs", "broker", "db", "mailer"}, r.Names())
+
+	start := time.Now()
+	report = r.Run(context.Background())
+	assert.True(t, time.Since(start) < 500*time.Millisecond)
+	assert.False(t, report.OK())
+	assert.Equal(t, health.StatusOK, report.Checks["db"].Status)
+	assert.Empty(t, report.Checks["db"].Error)
+	assert.Equal(t, health.StatusFail, report.Checks["broker"].Status)
+	assert.Equal(t, "broker is down", report.Checks["broker"].Error)
+	assert.Equal(t, health.StatusFail, report.Checks["mailer"].Status)
+	assert.Equal(t, health.StatusFail, report.Checks["blobs"].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["blobs"].Error)
+	assert.True(t, report.Checks["blobs"].LatencyMS >= 10)
+
+	// Registering with the same name replaces the check
+	r.Register("broker", 0, func(context.Context) error {
+		return nil
+	})
+	r.Register("mailer", 0, func(context.Context) error {
+		return nil
+	})
+	r.Register("blobs", 0, func(context.Context) error {
+		return nil
+	})
+	assert.True(t, r.Run(context.Background()).OK())
+}
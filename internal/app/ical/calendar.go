@@ -0,0 +1,302 @@
+// Package ical writes calendars of events in iCalendar format (RFC 5545),
+// so they can be imported to or subscribed from calendar applications.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Formats of date-time values
+const (
+	utcFormat   = "20060102T150405Z"
+	localFormat = "20060102T150405"
+)
+
+// timezoneYears is how many years after the last event time zones of
+// the calendar describe offsets for, if the event repeats forever
+const timezoneYears = 10
+
+// maxLineLength is the length of content lines in octets, longer lines
+// are folded
+const maxLineLength = 75
+
+// textEscaper escapes special characters of text values
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+// Calendar object that describes a calendar with its events
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []*Event
+}
+
+// Event object that describes an event of the calendar. Times of events
+// with a location other than UTC are written as local times of that
+// location, so recurring events follow its daylight saving time, and
+// the calendar describes offsets of the location while its events last.
+// RRule is the recurrence rule of the event, empty for single events.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Start       time.Time
+	End         time.Time
+	Location    *time.Location
+	RRule       string
+	Stamp       time.Time
+}
+
+// WeeklyRule func. Returns the recurrence rule of an event repeated
+// every week until the time, zero time means forever.
+func WeeklyRule(until time.Time) string {
+	if until.IsZero() {
+		return "FREQ=WEEKLY"
+	}
+
+	return "FREQ=WEEKLY;UNTIL=" + until.UTC().Format(utcFormat)
+}
+
+// WriteTo func. Writes the calendar to w. Implements io.WriterTo.
+func (c *Calendar) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	writeLine(bw, "BEGIN:VCALENDAR")
+	writeLine(bw, "VERSION:2.0")
+	writeLine(bw, "PRODID:"+c.ProdID)
+	writeLine(bw, "CALSCALE:GREGORIAN")
+	if c.Name != "" {
+		writeLine(bw, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+
+	for _, tz := range c.timezones() {
+		tz.write(bw)
+	}
+
+	for _, e := range c.Events {
+		writeLine(bw, "BEGIN:VEVENT")
+		writeLine(bw, "UID:"+e.UID)
+		writeLine(bw, "DTSTAMP:"+e.Stamp.UTC().Format(utcFormat))
+		writeLine(bw, "DTSTART"+dateTime(e.Start, e.Location))
+		writeLine(bw, "DTEND"+dateTime(e.End, e.Location))
+		if e.RRule != "" {
+			writeLine(bw, "RRULE:"+e.RRule)
+		}
+		writeLine(bw, "SUMMARY:"+escapeText(e.Summary))
+		if e.Description != "" {
+			writeLine(bw, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		writeLine(bw, "END:VEVENT")
+	}
+
+	writeLine(bw, "END:VCALENDAR")
+	err := bw.Flush()
+
+	return cw.n, err
+}
+
+// timezones func. Returns time zones of locations of events other than
+// UTC, in order of their first events, spanning every event in them
+func (c *Calendar) timezones() []*timezone {
+	timezones := []*timezone{}
+	byName := map[string]*timezone{}
+	for _, e := range c.Events {
+		if e.Location == nil || e.Location == time.UTC {
+			continue
+		}
+
+		tz, ok := byName[e.Location.String()]
+		if !ok {
+			tz = &timezone{loc: e.Location, from: e.Start, to: e.End}
+			byName[e.Location.String()] = tz
+			timezones = append(timezones, tz)
+		}
+
+		if e.Start.Before(tz.from) {
+			tz.from = e.Start
+		}
+		if last := e.last(); last.After(tz.to) {
+			tz.to = last
+		}
+	}
+
+	return timezones
+}
+
+// last func. Returns the end of the last occurrence of the event. Events
+// repeating forever end timezoneYears after the first occurrence.
+func (e *Event) last() time.Time {
+	if e.RRule == "" {
+		return e.End
+	}
+
+	i := strings.Index(e.RRule, "UNTIL=")
+	if i < 0 {
+		return e.End.AddDate(timezoneYears, 0, 0)
+	}
+
+	value := e.RRule[i+len("UNTIL="):]
+	if j := strings.IndexByte(value, ';'); j >= 0 {
+		value = value[:j]
+	}
+	until, err := time.Parse(utcFormat, value)
+	if err != nil {
+		return e.End.AddDate(timezoneYears, 0, 0)
+	}
+
+	return until.Add(e.End.Sub(e.Start))
+}
+
+// timezone object that describes offsets of the location from UTC
+// between the times
+type timezone struct {
+	loc  *time.Location
+	from time.Time
+	to   time.Time
+}
+
+// write func. Writes the time zone as VTIMEZONE component. The offset
+// in effect at the start is the first observance, every change of the
+// offset until the end is one more.
+func (tz *timezone) write(w *bufio.Writer) {
+	writeLine(w, "BEGIN:VTIMEZONE")
+	writeLine(w, "TZID:"+tz.loc.String())
+
+	name, offset := tz.from.In(tz.loc).Zone()
+	writeObservance(w, observance(tz.loc, tz.from, offset), tz.from, name, offset, offset)
+	for _, t := range transitions(tz.loc, tz.from, tz.to) {
+		_, before := t.Add(-time.Second).In(tz.loc).Zone()
+		name, after := t.In(tz.loc).Zone()
+		writeObservance(w, observance(tz.loc, t, after), t, name, before, after)
+	}
+
+	writeLine(w, "END:VTIMEZONE")
+}
+
+// observance func. Returns kind of observance of the offset of the
+// location at the time: offsets greater than the least one of the year
+// are daylight saving time.
+func observance(loc *time.Location, t time.Time, offset int) string {
+	year := t.In(loc).Year()
+	_, january := time.Date(year, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	_, july := time.Date(year, time.July, 1, 0, 0, 0, 0, loc).Zone()
+	if offset > january || offset > july {
+		return "DAYLIGHT"
+	}
+
+	return "STANDARD"
+}
+
+// writeObservance func. Writes observance of the time zone starting at
+// the time, its start is local time of the offset it follows.
+func writeObservance(w *bufio.Writer, kind string, start time.Time, name string, from, to int) {
+	writeLine(w, "BEGIN:"+kind)
+	writeLine(w, "DTSTART:"+start.In(time.FixedZone("", from)).Format(localFormat))
+	writeLine(w, "TZOFFSETFROM:"+utcOffset(from))
+	writeLine(w, "TZOFFSETTO:"+utcOffset(to))
+	if name != "" {
+		writeLine(w, "TZNAME:"+escapeText(name))
+	}
+	writeLine(w, "END:"+kind)
+}
+
+// transitions func. Returns times between from and to when offset of the
+// location changes. Offsets are compared day by day, and the change
+// within a day is found to the second.
+func transitions(loc *time.Location, from, to time.Time) []time.Time {
+	changes := []time.Time{}
+	from = from.Truncate(time.Second)
+	for t := from; t.Before(to); t = t.Add(24 * time.Hour) {
+		next := t.Add(24 * time.Hour)
+		_, before := t.In(loc).Zone()
+		if _, after := next.In(loc).Zone(); after == before {
+			continue
+		}
+
+		lo, hi := t, next
+		for hi.Sub(lo) > time.Second {
+			mid := lo.Add(hi.Sub(lo) / 2).Truncate(time.Second)
+			if _, offset := mid.In(loc).Zone(); offset == before {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		if hi.Before(to) {
+			changes = append(changes, hi)
+		}
+	}
+
+	return changes
+}
+
+// utcOffset func. Returns the offset in seconds east of UTC as +HHMM
+func utcOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	offset /= 60
+
+	return fmt.Sprintf("%s%02d%02d", sign, offset/60, offset%60)
+}
+
+// dateTime func. Returns parameters and value of date-time property,
+// in UTC or as local time of the location.
+func dateTime(t time.Time, loc *time.Location) string {
+	if loc == nil || loc == time.UTC {
+		return ":" + t.UTC().Format(utcFormat)
+	}
+
+	return ";TZID=" + loc.String() + ":" + t.In(loc).Format(localFormat)
+}
+
+// escapeText func. Escapes special characters of text value
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// writeLine func. Writes content line ended with CRLF. Lines longer
+// than maxLineLength octets are folded without splitting characters.
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineLength
+	for len(line) > limit {
+		i := limit
+		for i > 0 && !utf8.RuneStart(line[i]) {
+			i--
+		}
+		w.WriteString(line[:i])
+		w.WriteString("\r\n ")
+		line = line[i:]
+		// Continuation lines start with a space
+		limit = maxLineLength - 1
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+// countingWriter object. Counts octets written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write func. Implements io.Writer
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,105 @@
+package ical_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/ical"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendar_WriteTo(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2020, 10, 23, 17, 0, 0, 0, time.UTC)
+	c := &ical.Calendar{
+		ProdID: "-//Tavern of Games//Events//EN",
+		Name:   "Game nights",
+		Events: []*ical.Event{
+			{
+				UID:         "event-1@tavern-of-games",
+				Summary:     "Catan, Carcassonne; and snacks",
+				Description: "Bring\nfriends",
+				Start:       start,
+				End:         start.Add(3 * time.Hour),
+				Location:    berlin,
+				RRule:       ical.WeeklyRule(time.Date(2020, 12, 18, 17, 0, 0, 0, time.UTC)),
+				Stamp:       start,
+			},
+			{
+				UID:     "event-2@tavern-of-games",
+				Summary: strings.Repeat("Long ", 20),
+				Start:   start,
+				End:     start.Add(time.Hour),
+				Stamp:   start,
+			},
+		},
+	}
+
+	b := &strings.Builder{}
+	n, err := c.WriteTo(b)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(b.Len()), n)
+
+	out := b.String()
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Contains(t, out, "DTSTART;TZID=Europe/Berlin:20201023T190000\r\n")
+	assert.Contains(t, out, "RRULE:FREQ=WEEKLY;UNTIL=20201218T170000Z\r\n")
+	assert.Contains(t, out, `SUMMARY:Catan\, Carcassonne\; and snacks`+"\r\n")
+	assert.Contains(t, out, `DESCRIPTION:Bring\nfriends`+"\r\n")
+	assert.Contains(t, out, "DTSTART:20201023T170000Z\r\n")
+	// Berlin is described from the first event until the last one ends,
+	// summer time ends in between
+	assert.Contains(t, out, "BEGIN:VTIMEZONE\r\nTZID:Europe/Berlin\r\n"+
+		"BEGIN:DAYLIGHT\r\nDTSTART:20201023T190000\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0200\r\nTZNAME:CEST\r\nEND:DAYLIGHT\r\n"+
+		"BEGIN:STANDARD\r\nDTSTART:20201025T030000\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nTZNAME:CET\r\nEND:STANDARD\r\n"+
+		"END:VTIMEZONE\r\n")
+	assert.Equal(t, 1, strings.Count(out, "BEGIN:VTIMEZONE"))
+	assert.Less(t, strings.Index(out, "END:VTIMEZONE"), strings.Index(out, "BEGIN:VEVENT"))
+
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+	assert.Contains(t, out, "Lo\r\n ng Long")
+}
+
+func TestCalendar_WriteTo_Timezones(t *testing.T) {
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Repeating forever, the time zone spans years of offset changes
+	start := time.Date(2020, 10, 23, 9, 0, 0, 0, time.UTC)
+	c := &ical.Calendar{
+		Events: []*ical.Event{
+			{
+				UID:      "event-1@tavern-of-games",
+				Start:    start,
+				End:      start.Add(time.Hour),
+				Location: sydney,
+				RRule:    ical.WeeklyRule(time.Time{}),
+				Stamp:    start,
+			},
+		},
+	}
+
+	b := &strings.Builder{}
+	_, err = c.WriteTo(b)
+	assert.NoError(t, err)
+
+	out := b.String()
+	assert.Contains(t, out, "BEGIN:DAYLIGHT\r\nDTSTART:20201023T200000\r\nTZOFFSETFROM:+1100\r\nTZOFFSETTO:+1100\r\n")
+	assert.Contains(t, out, "BEGIN:STANDARD\r\nDTSTART:20210404T030000\r\nTZOFFSETFROM:+1100\r\nTZOFFSETTO:+1000\r\n")
+	assert.Contains(t, out, "BEGIN:DAYLIGHT\r\nDTSTART:20211003T020000\r\nTZOFFSETFROM:+1000\r\nTZOFFSETTO:+1100\r\n")
+	assert.Equal(t, 10, strings.Count(out, "TZOFFSETFROM:+1100\r\nTZOFFSETTO:+1000"))
+}
+
+func TestWeeklyRule(t *testing.T) {
+	assert.Equal(t, "FREQ=WEEKLY", ical.WeeklyRule(time.Time{}))
+}
@@ -0,0 +1,86 @@
+// Package job runs periodic background jobs of the server, so they can
+// be stopped together when the server shuts down.
+package job
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Runner object that runs periodic jobs until it is stopped. It is safe
+// for concurrent use.
+type Runner struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	stop    chan struct{}
+	stopped bool
+}
+
+// NewRunner func. Constructor for Runner
+func NewRunner() *Runner {
+	return &Runner{
+		stop: make(chan struct{}),
+	}
+}
+
+// Every func. Runs fn every interval with the time of the tick in a
+// separate goroutine until the runner is stopped. A run isn't
+// interrupted by Stop, Stop waits for it instead. Jobs added after the
+// runner is stopped never run.
+func (r *Runner) Every(interval time.Duration, fn func(now time.Time)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.stopped {
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case now := <-ticker.C:
+				fn(now)
+			}
+		}
+	}()
+}
+
+// Stop func. Stops all jobs and waits until their running ticks are
+// over. It is the shutdown hook of the runner and can be called more
+// than once.
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	if !r.stopped {
+		r.stopped = true
+		close(r.stop)
+	}
+	r.mu.Unlock()
+
+	r.wg.Wait()
+}
+
+// Shutdown func. Stops all jobs like Stop, but waits for their running
+// ticks only until the context is done and returns its error then.
+func (r *Runner) Shutdown(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,83 @@
+package job_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/job"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunner_Stop(t *testing.T) {
+	r := job.NewRunner()
+
+	var ticks int32
+	done := make(chan struct{})
+	r.Every(time.Millisecond, func(time.Time) {
+		if atomic.AddInt32(&ticks, 1) == 3 {
+			close(done)
+		}
+	})
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job didn't run")
+	}
+
+	r.Stop()
+	n := atomic.LoadInt32(&ticks)
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, n, atomic.LoadInt32(&ticks))
+
+	// Stopping twice and adding jobs after the stop are no-ops
+	r.Stop()
+	r.Every(time.Millisecond, func(time.Time) {
+		t.Error("job ran after stop")
+	})
+	time.Sleep(5 * time.Millisecond)
+}
+
+func TestRunner_StopWaitsForRunningTick(t *testing.T) {
+	r := job.NewRunner()
+
+	started := make(chan struct{})
+	var finished int32
+	r.Every(time.Millisecond, func(time.Time) {
+		if atomic.LoadInt32(&finished) == 1 {
+			return
+		}
+		close(started)
+		time.Sleep(10 * time.Millisecond)
+		atomic.StoreInt32(&finished, 1)
+	})
+
+	<-started
+	r.Stop()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&finished))
+}
+
+func TestRunner_Shutdown(t *testing.T) {
+	r := job.NewRunner()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r.Every(time.Millisecond, func(time.Time) {
+		select {
+		case <-started:
+		default:
+			close(started)
+			<-release
+		}
+	})
+
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, r.Shutdown(ctx))
+
+	close(release)
+	assert.NoError(t, r.Shutdown(context.Background()))
+}
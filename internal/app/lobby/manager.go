@@ -0,0 +1,417 @@
+package lobby
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+var (
+	// ErrLobbyNotFound error tells us, that there is no lobby with the id
+	ErrLobbyNotFound = errors.New("lobby not found")
+	// ErrLobbyFull error tells us, that the lobby has no free places
+	ErrLobbyFull = errors.New("lobby is full")
+	// ErrLobbyStarted error tells us, that the lobby has already started
+	ErrLobbyStarted = errors.New("lobby has already started")
+	// ErrWrongPassword error tells us, that the lobby password doesn't match
+	ErrWrongPassword = errors.New("wrong lobby password")
+	// ErrNotHost error tells us, that only the host can do the action
+	ErrNotHost = errors.New("only the host can do this")
+	// ErrNotMember error tells us, that the user isn't a member of the lobby
+	ErrNotMember = errors.New("not a member of the lobby")
+	// ErrAlreadyInLobby error tells us, that the user is already in a lobby
+	ErrAlreadyInLobby = errors.New("already in a lobby")
+	// ErrNotReady error tells us, that not every member of the lobby is ready
+	ErrNotReady = errors.New("not every member is ready")
+)
+
+// StartedTTL is how long a started lobby is kept for the result of its
+// match to be reported.
+const StartedTTL = 6 * time.Hour
+
+// Manager object. Keeps state of every lobby in memory and pushes
+// lobby snapshots to subscribers on every change. Started lobbies
+// release their members and are kept until the result of their match is
+// reported or they expire. It is safe for concurrent use.
+type Manager struct {
+	mu          sync.Mutex
+	lastID      int
+	lobbies     map[int]*model.Lobby
+	userLobby   map[int]int
+	subscribers map[int]map[chan *model.Lobby]struct{}
+}
+
+// NewManager func. Constructor for Manager
+func NewManager() *Manager {
+	return &Manager{
+		lobbies:     make(map[int]*model.Lobby),
+		userLobby:   make(map[int]int),
+		subscribers: make(map[int]map[chan *model.Lobby]struct{}),
+	}
+}
+
+// Create func. Creates a new lobby hosted by the user. A user can be
+// a member of one lobby at a time.
+func (m *Manager) Create(l *model.Lobby, hostID int) (*model.Lobby, error) {
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := l.BeforeCreate(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.userLobby[hostID]; ok {
+		return nil, ErrAlreadyInLobby
+	}
+
+	m.lastID++
+	l.ID = m.lastID
+	l.HostID = hostID
+	l.Members = []*model.LobbyMember{{UserID: hostID, JoinedAt: l.CreatedAt}}
+	m.lobbies[l.ID] = l
+	m.userLobby[hostID] = l.ID
+
+	return l.Copy(), nil
+}
+
+// Get func. Returns snapshot of the lobby
+func (m *Manager) Get(id int) (*model.Lobby, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.lobbies[id]
+	if !ok {
+		return nil, ErrLobbyNotFound
+	}
+
+	return l.Copy(), nil
+}
+
+// Current func. Returns id of the lobby the user is a member of
+func (m *Manager) Current(userID int) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	id, ok := m.userLobby[userID]
+
+	return id, ok
+}
+
+// List func. Returns snapshots of open public lobbies of the game
+// ordered from the oldest. Zero game id means lobbies of every game.
+func (m *Manager) List(gameID int) []*model.Lobby {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	lobbies := []*model.Lobby{}
+	for _, l := range m.lobbies {
+		if l.Visibility == model.LobbyPublic && l.State == model.LobbyOpen &&
+			(gameID == 0 || l.GameID == gameID) {
+			lobbies = append(lobbies, l.Copy())
+		}
+	}
+
+	sort.Slice(lobbies, func(i, j int) bool {
+		return lobbies[i].ID < lobbies[j].ID
+	})
+
+	return lobbies
+}
+
+// Count func. Returns number of open lobbies
+func (m *Manager) Count() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	n := 0
+	for _, l := range m.lobbies {
+		if l.State == model.LobbyOpen {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Join func. Adds the user to the open lobby if the password matches
+// and the lobby has free places.
+func (m *Manager) Join(id, userID int, password string) (*model.Lobby, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.lobbies[id]
+	if !ok {
+		return nil, ErrLobbyNotFound
+	}
+
+	if current, ok := m.userLobby[userID]; ok {
+		if current == id {
+			return l.Copy(), nil
+		}
+		return nil, ErrAlreadyInLobby
+	}
+
+	switch {
+	case l.State != model.LobbyOpen:
+		return nil, ErrLobbyStarted
+	case len(l.Members) >= l.MaxPlayers:
+		return nil, ErrLobbyFull
+	case !l.ComparePassword(password):
+		return nil, ErrWrongPassword
+	}
+
+	m.addMember(l, userID)
+
+	return l.Copy(), nil
+}
+
+// Leave func. Removes the user from the open lobby. If the host leaves,
+// the longest waiting member becomes the host. Empty lobbies are closed.
+func (m *Manager) Leave(id, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.lobbies[id]
+	switch {
+	case !ok:
+		return ErrLobbyNotFound
+	case l.Member(userID) == nil:
+		return ErrNotMember
+	case l.State != model.LobbyOpen:
+		return ErrLobbyStarted
+	}
+
+	m.removeMember(l, userID)
+
+	return nil
+}
+
+// Kick func. Removes the user from the open lobby by the host's decision.
+func (m *Manager) Kick(id, hostID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.lobbies[id]
+	switch {
+	case !ok:
+		return ErrLobbyNotFound
+	case l.HostID != hostID:
+		return ErrNotHost
+	case hostID == userID || l.Member(userID) == nil:
+		return ErrNotMember
+	case l.State != model.LobbyOpen:
+		return ErrLobbyStarted
+	}
+
+	m.removeMember(l, userID)
+
+	return nil
+}
+
+// SetReady func. Changes ready flag of the member of the open lobby.
+func (m *Manager) SetReady(id, userID int, ready bool) (*model.Lobby, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.lobbies[id]
+	if !ok {
+		return nil, ErrLobbyNotFound
+	}
+
+	member := l.Member(userID)
+	switch {
+	case member == nil:
+		return nil, ErrNotMember
+	case l.State != model.LobbyOpen:
+		return nil, ErrLobbyStarted
+	}
+
+	member.Ready = ready
+	m.publish(l)
+
+	return l.Copy(), nil
+}
+
+// Start func. Starts the lobby by the host's decision once there are
+// enough players and every one of them is ready. Members are free to
+// join other lobbies right away and subscribers get the last snapshot
+// of the lobby.
+func (m *Manager) Start(id, hostID int) (*model.Lobby, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.lobbies[id]
+	switch {
+	case !ok:
+		return nil, ErrLobbyNotFound
+	case l.HostID != hostID:
+		return nil, ErrNotHost
+	case l.State != model.LobbyOpen:
+		return nil, ErrLobbyStarted
+	case len(l.Members) < model.LobbyMinPlayers:
+		return nil, ErrNotReady
+	}
+
+	for _, member := range l.Members {
+		if !member.Ready && member.UserID != l.HostID {
+			return nil, ErrNotReady
+		}
+	}
+
+	now := time.Now().UTC()
+	l.State = model.LobbyStarted
+	l.StartedAt = &now
+	m.publish(l)
+	m.release(l)
+	m.unsubscribeAll(l.ID)
+
+	return l.Copy(), nil
+}
+
+// Close func. Removes the started lobby once the result of its match is
+// reported, so the result can't be reported twice.
+func (m *Manager) Close(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.lobbies[id]
+	if !ok || l.State != model.LobbyStarted {
+		return ErrLobbyNotFound
+	}
+
+	m.remove(l)
+
+	return nil
+}
+
+// Sweep func. Removes lobbies started longer than StartedTTL before
+// the time
+func (m *Manager) Sweep(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, l := range m.lobbies {
+		if l.StartedAt != nil && now.Sub(*l.StartedAt) > StartedTTL {
+			m.remove(l)
+		}
+	}
+}
+
+// Subscribe func. Subscribes to snapshots of the lobby. The current
+// snapshot is delivered right away. Subscribers always get the latest
+// snapshot, intermediate ones may be skipped. The channel is closed when
+// the lobby starts or closes, or the returned func is called.
+func (m *Manager) Subscribe(id int) (<-chan *model.Lobby, func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.lobbies[id]
+	if !ok {
+		return nil, nil, ErrLobbyNotFound
+	}
+
+	ch := make(chan *model.Lobby, 1)
+	ch <- l.Copy()
+	if l.State != model.LobbyOpen {
+		close(ch)
+		return ch, func() {}, nil
+	}
+	if m.subscribers[id] == nil {
+		m.subscribers[id] = make(map[chan *model.Lobby]struct{})
+	}
+	m.subscribers[id][ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+
+			if _, ok := m.subscribers[id][ch]; ok {
+				delete(m.subscribers[id], ch)
+				close(ch)
+			}
+		})
+	}, nil
+}
+
+// addMember func. Adds the user to the lobby. Has to be called with
+// the lock held.
+func (m *Manager) addMember(l *model.Lobby, userID int) {
+	l.Members = append(l.Members, &model.LobbyMember{
+		UserID:   userID,
+		JoinedAt: time.Now().UTC(),
+	})
+	m.userLobby[userID] = l.ID
+	m.publish(l)
+}
+
+// removeMember func. Removes the user from the lobby, passes hosting to
+// the next member and closes the lobby if it's empty. Has to be called
+// with the lock held.
+func (m *Manager) removeMember(l *model.Lobby, userID int) {
+	delete(m.userLobby, userID)
+	for i, member := range l.Members {
+		if member.UserID == userID {
+			l.Members = append(l.Members[:i], l.Members[i+1:]...)
+			break
+		}
+	}
+
+	if len(l.Members) == 0 {
+		m.remove(l)
+		return
+	}
+
+	if l.HostID == userID {
+		l.HostID = l.Members[0].UserID
+	}
+	m.publish(l)
+}
+
+// remove func. Removes the lobby, releasing its members and closing
+// channels of its subscribers. Has to be called with the lock held.
+func (m *Manager) remove(l *model.Lobby) {
+	delete(m.lobbies, l.ID)
+	m.release(l)
+	m.unsubscribeAll(l.ID)
+}
+
+// release func. Frees members of the lobby to join other lobbies. Has
+// to be called with the lock held.
+func (m *Manager) release(l *model.Lobby) {
+	for _, member := range l.Members {
+		if m.userLobby[member.UserID] == l.ID {
+			delete(m.userLobby, member.UserID)
+		}
+	}
+}
+
+// unsubscribeAll func. Closes channels of subscribers of the lobby. Has
+// to be called with the lock held.
+func (m *Manager) unsubscribeAll(id int) {
+	for ch := range m.subscribers[id] {
+		close(ch)
+	}
+	delete(m.subscribers, id)
+}
+
+// publish func. Pushes snapshot of the lobby to its subscribers,
+// replacing snapshots they haven't received yet. Has to be called with
+// the lock held.
+func (m *Manager) publish(l *model.Lobby) {
+	for ch := range m.subscribers[l.ID] {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- l.Copy()
+	}
+}
@@ -0,0 +1,198 @@
+package lobby_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/lobby"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager_Join(t *testing.T) {
+	m := lobby.NewManager()
+	l := model.TestLobby(t, 1)
+	l.MaxPlayers = 2
+	l.Password = "secret"
+	l, err := m.Create(l, 1)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		userID      int
+		password    string
+		expectedErr error
+	}{
+		{
+			name:        "wrong password",
+			userID:      2,
+			password:    "wrong",
+			expectedErr: lobby.ErrWrongPassword,
+		},
+		{
+			name:     "valid",
+			userID:   2,
+			password: "secret",
+		},
+		{
+			name:        "full",
+			userID:      3,
+			password:    "secret",
+			expectedErr: lobby.ErrLobbyFull,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := m.Join(l.ID, tc.userID, tc.password)
+			assert.Equal(t, tc.expectedErr, err)
+		})
+	}
+
+	_, err = m.Join(l.ID+1, 3, "")
+	assert.Equal(t, lobby.ErrLobbyNotFound, err)
+	_, err = m.Create(model.TestLobby(t, 1), 2)
+	assert.Equal(t, lobby.ErrAlreadyInLobby, err)
+}
+
+func TestManager_Start(t *testing.T) {
+	m := lobby.NewManager()
+	l, _ := m.Create(model.TestLobby(t, 1), 1)
+
+	_, err := m.Start(l.ID, 1)
+	assert.Equal(t, lobby.ErrNotReady, err)
+
+	m.Join(l.ID, 2, "")
+	_, err = m.Start(l.ID, 2)
+	assert.Equal(t, lobby.ErrNotHost, err)
+	_, err = m.Start(l.ID, 1)
+	assert.Equal(t, lobby.ErrNotReady, err)
+
+	_, err = m.SetReady(l.ID, 2, true)
+	assert.NoError(t, err)
+	l, err = m.Start(l.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LobbyStarted, l.State)
+
+	_, err = m.Join(l.ID, 3, "")
+	assert.Equal(t, lobby.ErrLobbyStarted, err)
+	assert.Equal(t, lobby.ErrLobbyStarted, m.Leave(l.ID, 2))
+}
+
+func TestManager_Close(t *testing.T) {
+	m := lobby.NewManager()
+	l, _ := m.Create(model.TestLobby(t, 1), 1)
+	m.Join(l.ID, 2, "")
+	m.SetReady(l.ID, 2, true)
+	updates, unsubscribe, _ := m.Subscribe(l.ID)
+	defer unsubscribe()
+	<-updates
+
+	assert.Equal(t, lobby.ErrLobbyNotFound, m.Close(l.ID))
+
+	// Members of the started lobby are free to join other lobbies and
+	// subscribers get the last snapshot
+	_, err := m.Start(l.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LobbyStarted, (<-updates).State)
+	_, ok := <-updates
+	assert.False(t, ok)
+	for _, userID := range []int{1, 2} {
+		_, ok := m.Current(userID)
+		assert.False(t, ok)
+	}
+	other, err := m.Create(model.TestLobby(t, 1), 1)
+	assert.NoError(t, err)
+
+	// The started lobby is kept until the result is reported
+	_, err = m.Get(l.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, m.Close(l.ID))
+	_, err = m.Get(l.ID)
+	assert.Equal(t, lobby.ErrLobbyNotFound, err)
+	assert.Equal(t, lobby.ErrLobbyNotFound, m.Close(l.ID))
+
+	id, ok := m.Current(1)
+	assert.True(t, ok)
+	assert.Equal(t, other.ID, id)
+}
+
+func TestManager_Sweep(t *testing.T) {
+	m := lobby.NewManager()
+	open, _ := m.Create(model.TestLobby(t, 1), 1)
+	l, _ := m.Create(model.TestLobby(t, 1), 2)
+	m.Join(l.ID, 3, "")
+	m.SetReady(l.ID, 3, true)
+	l, _ = m.Start(l.ID, 2)
+
+	m.Sweep(l.StartedAt.Add(lobby.StartedTTL))
+	_, err := m.Get(l.ID)
+	assert.NoError(t, err)
+
+	m.Sweep(l.StartedAt.Add(lobby.StartedTTL + time.Second))
+	_, err = m.Get(l.ID)
+	assert.Equal(t, lobby.ErrLobbyNotFound, err)
+	_, err = m.Get(open.ID)
+	assert.NoError(t, err)
+}
+
+func TestManager_Leave(t *testing.T) {
+	m := lobby.NewManager()
+	l, _ := m.Create(model.TestLobby(t, 1), 1)
+	m.Join(l.ID, 2, "")
+	m.Join(l.ID, 3, "")
+
+	updates, unsubscribe, err := m.Subscribe(l.ID)
+	assert.NoError(t, err)
+	defer unsubscribe()
+	assert.Len(t, (<-updates).Members, 3)
+
+	assert.Equal(t, lobby.ErrNotHost, m.Kick(l.ID, 2, 3))
+	assert.NoError(t, m.Kick(l.ID, 1, 3))
+	assert.Len(t, (<-updates).Members, 2)
+
+	assert.NoError(t, m.Leave(l.ID, 1))
+	snapshot := <-updates
+	assert.Equal(t, 2, snapshot.HostID)
+
+	assert.NoError(t, m.Leave(l.ID, 2))
+	_, ok := <-updates
+	assert.False(t, ok)
+	_, err = m.Get(l.ID)
+	assert.Equal(t, lobby.ErrLobbyNotFound, err)
+}
+
+func TestManager_Concurrent(t *testing.T) {
+	m := lobby.NewManager()
+	l := model.TestLobby(t, 1)
+	l.MaxPlayers = 10
+	l, _ = m.Create(l, 1)
+	updates, unsubscribe, _ := m.Subscribe(l.ID)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range updates {
+		}
+	}()
+
+	wg := sync.WaitGroup{}
+	for i := 2; i <= 21; i++ {
+		wg.Add(1)
+		go func(userID int) {
+			defer wg.Done()
+			if _, err := m.Join(l.ID, userID, ""); err == nil {
+				m.SetReady(l.ID, userID, true)
+			}
+			m.List(0)
+		}(i)
+	}
+	wg.Wait()
+	unsubscribe()
+	<-done
+
+	l, _ = m.Get(l.ID)
+	assert.Len(t, l.Members, 10)
+	assert.Equal(t, 1, m.Count())
+}
@@ -0,0 +1,267 @@
+package lobby
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var (
+	// ErrAlreadyQueued error tells us, that the user is already queued
+	ErrAlreadyQueued = errors.New("already queued")
+	// ErrNotQueued error tells us, that the user isn't queued
+	ErrNotQueued = errors.New("not queued")
+)
+
+// Ticket object. Describes a player waiting in the matchmaking queue
+// for a game with the number of players the match needs.
+type Ticket struct {
+	UserID   int       `json:"user_id"`
+	GameID   int       `json:"game_id"`
+	Players  int       `json:"players"`
+	Rating   int       `json:"rating"`
+	QueuedAt time.Time `json:"queued_at"`
+}
+
+// Tolerance object. Describes how far from each other skill ratings of
+// matched players can be. The window starts at Base and widens by Rate
+// for every second the player waits, up to Max.
+type Tolerance struct {
+	Base float64
+	Rate float64
+	Max  float64
+}
+
+// DefaultTolerance is the tolerance used by the server
+var DefaultTolerance = Tolerance{
+	Base: 100,
+	Rate: 10,
+	Max:  1000,
+}
+
+// Validate func. Validating ticket instance for game id and number of players
+func (t *Ticket) Validate() error {
+	return validation.ValidateStruct(
+		t,
+		validation.Field(&t.GameID, validation.Required, validation.Min(1)),
+		validation.Field(&t.Players, validation.Required, validation.Min(model.LobbyMinPlayers), validation.Max(model.LobbyMaxPlayers)),
+	)
+}
+
+// window func. Returns rating window of the ticket at the moment
+func (tol Tolerance) window(t *Ticket, now time.Time) float64 {
+	return math.Min(tol.Base+tol.Rate*now.Sub(t.QueuedAt).Seconds(), tol.Max)
+}
+
+// fits func. Checks that ratings of the tickets fit into the windows of
+// each other at the moment
+func (tol Tolerance) fits(a, b *Ticket, now time.Time) bool {
+	diff := math.Abs(float64(a.Rating - b.Rating))
+	return diff <= tol.window(a, now) && diff <= tol.window(b, now)
+}
+
+// Match func. Groups tickets into matches. Tickets match if they are for
+// the same game and number of players, and ratings of every two of them
+// fit into the windows of each other. The longest waiting players are
+// matched first, with the closest ratings available. Match is pure and
+// deterministic.
+func Match(tickets []*Ticket, now time.Time, tol Tolerance) [][]*Ticket {
+	sorted := make([]*Ticket, len(tickets))
+	copy(sorted, tickets)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].QueuedAt.Equal(sorted[j].QueuedAt) {
+			return sorted[i].QueuedAt.Before(sorted[j].QueuedAt)
+		}
+		return sorted[i].UserID < sorted[j].UserID
+	})
+
+	matched := map[int]bool{}
+	matches := [][]*Ticket{}
+	for i, anchor := range sorted {
+		if matched[anchor.UserID] {
+			continue
+		}
+		// Collecting players who fit the anchor and whom the anchor fits
+		candidates := []*Ticket{}
+		for _, t := range sorted[i+1:] {
+			if matched[t.UserID] || t.GameID != anchor.GameID || t.Players != anchor.Players {
+				continue
+			}
+
+			if tol.fits(anchor, t, now) {
+				candidates = append(candidates, t)
+			}
+		}
+
+		if len(candidates) < anchor.Players-1 {
+			continue
+		}
+		// Closest ratings first, longest waiting among equal ones
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return math.Abs(float64(candidates[i].Rating-anchor.Rating)) <
+				math.Abs(float64(candidates[j].Rating-anchor.Rating))
+		})
+		// Taking candidates who fit every player taken before them
+		match := []*Ticket{anchor}
+		for _, t := range candidates {
+			if len(match) == anchor.Players {
+				break
+			}
+
+			fit := true
+			for _, m := range match[1:] {
+				if !tol.fits(m, t, now) {
+					fit = false
+					break
+				}
+			}
+			if fit {
+				match = append(match, t)
+			}
+		}
+
+		if len(match) < anchor.Players {
+			continue
+		}
+		for _, t := range match {
+			matched[t.UserID] = true
+		}
+		matches = append(matches, match)
+	}
+
+	return matches
+}
+
+// Matchmaker object. Matchmaking queue that puts matched players into
+// new private lobbies of the manager. It is safe for concurrent use.
+type Matchmaker struct {
+	mu        sync.Mutex
+	manager   *Manager
+	tolerance Tolerance
+	tickets   map[int]*Ticket
+	matched   map[int]int
+}
+
+// NewMatchmaker func. Constructor for Matchmaker
+func NewMatchmaker(manager *Manager, tolerance Tolerance) *Matchmaker {
+	return &Matchmaker{
+		manager:   manager,
+		tolerance: tolerance,
+		tickets:   make(map[int]*Ticket),
+		matched:   make(map[int]int),
+	}
+}
+
+// Enqueue func. Puts the ticket into the queue
+func (mm *Matchmaker) Enqueue(t *Ticket) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	if _, ok := mm.manager.Current(t.UserID); ok {
+		return ErrAlreadyInLobby
+	}
+
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	if _, ok := mm.tickets[t.UserID]; ok {
+		return ErrAlreadyQueued
+	}
+
+	t.QueuedAt = time.Now().UTC()
+	mm.tickets[t.UserID] = t
+	delete(mm.matched, t.UserID)
+
+	return nil
+}
+
+// Cancel func. Takes the user out of the queue
+func (mm *Matchmaker) Cancel(userID int) error {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	if _, ok := mm.tickets[userID]; !ok {
+		return ErrNotQueued
+	}
+
+	delete(mm.tickets, userID)
+
+	return nil
+}
+
+// Status func. Returns copy of the user's ticket if the user is queued,
+// or id of the lobby the user was matched into. The lobby is only
+// returned once, it's claimed then.
+func (mm *Matchmaker) Status(userID int) (*Ticket, int, error) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	if t, ok := mm.tickets[userID]; ok {
+		c := *t
+		return &c, 0, nil
+	}
+
+	if id, ok := mm.matched[userID]; ok {
+		delete(mm.matched, userID)
+		return nil, id, nil
+	}
+
+	return nil, 0, ErrNotQueued
+}
+
+// Count func. Returns number of queued players
+func (mm *Matchmaker) Count() int {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	return len(mm.tickets)
+}
+
+// Tick func. Matches queued players and creates lobbies for the matches.
+// Players who joined a lobby on their own are taken out of the queue.
+// Lobbies matched players left or that are gone aren't kept for them
+// any longer.
+func (mm *Matchmaker) Tick(now time.Time) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	for userID, lobbyID := range mm.matched {
+		if id, ok := mm.manager.Current(userID); !ok || id != lobbyID {
+			delete(mm.matched, userID)
+		}
+	}
+
+	tickets := make([]*Ticket, 0, len(mm.tickets))
+	for id, t := range mm.tickets {
+		if _, ok := mm.manager.Current(id); ok {
+			delete(mm.tickets, id)
+			continue
+		}
+		tickets = append(tickets, t)
+	}
+
+	for _, match := range Match(tickets, now, mm.tolerance) {
+		l, err := mm.manager.Create(&model.Lobby{
+			GameID:     match[0].GameID,
+			MaxPlayers: match[0].Players,
+			Visibility: model.LobbyPrivate,
+		}, match[0].UserID)
+		if err != nil {
+			continue
+		}
+
+		for _, t := range match {
+			if t != match[0] {
+				mm.manager.Join(l.ID, t.UserID, "")
+			}
+			delete(mm.tickets, t.UserID)
+			mm.matched[t.UserID] = l.ID
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package lobby_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/lobby"
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatch(t *testing.T) {
+	now := time.Date(2020, 10, 15, 20, 0, 0, 0, time.UTC)
+	ticket := func(userID, gameID, players, rating int, waited time.Duration) *lobby.Ticket {
+		return &lobby.Ticket{
+			UserID:   userID,
+			GameID:   gameID,
+			Players:  players,
+			Rating:   rating,
+			QueuedAt: now.Add(-waited),
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		tickets  []*lobby.Ticket
+		expected [][]int
+	}{
+		{
+			name: "close ratings",
+			tickets: []*lobby.Ticket{
+				ticket(1, 1, 2, 1500, 0),
+				ticket(2, 1, 2, 1550, 0),
+			},
+			expected: [][]int{{1, 2}},
+		},
+		{
+			name: "far ratings",
+			tickets: []*lobby.Ticket{
+				ticket(1, 1, 2, 1500, 0),
+				ticket(2, 1, 2, 1800, 0),
+			},
+			expected: [][]int{},
+		},
+		{
+			name: "far ratings after long wait",
+			tickets: []*lobby.Ticket{
+				ticket(1, 1, 2, 1500, time.Minute),
+				ticket(2, 1, 2, 1800, time.Minute),
+			},
+			expected: [][]int{{1, 2}},
+		},
+		{
+			name: "only one of players waited long",
+			tickets: []*lobby.Ticket{
+				ticket(1, 1, 2, 1500, time.Minute),
+				ticket(2, 1, 2, 1800, 0),
+			},
+			expected: [][]int{},
+		},
+		{
+			name: "different games",
+			tickets: []*lobby.Ticket{
+				ticket(1, 1, 2, 1500, 0),
+				ticket(2, 2, 2, 1500, 0),
+			},
+			expected: [][]int{},
+		},
+		{
+			name: "closest rating first",
+			tickets: []*lobby.Ticket{
+				ticket(1, 1, 2, 1500, 2*time.Second),
+				ticket(2, 1, 2, 1580, time.Second),
+				ticket(3, 1, 2, 1510, 0),
+			},
+			expected: [][]int{{1, 3}},
+		},
+		{
+			name: "longest waiting first",
+			tickets: []*lobby.Ticket{
+				ticket(3, 1, 2, 1500, 0),
+				ticket(1, 1, 2, 1500, 2*time.Second),
+				ticket(2, 1, 2, 1500, time.Second),
+			},
+			expected: [][]int{{1, 2}},
+		},
+		{
+			name: "players far from each other",
+			tickets: []*lobby.Ticket{
+				ticket(1, 1, 3, 1000, 0),
+				ticket(2, 1, 3, 910, 0),
+				ticket(3, 1, 3, 1090, 0),
+			},
+			expected: [][]int{},
+		},
+		{
+			name: "players close to each other",
+			tickets: []*lobby.Ticket{
+				ticket(1, 1, 3, 1000, 0),
+				ticket(2, 1, 3, 910, 0),
+				ticket(3, 1, 3, 1090, 0),
+				ticket(4, 1, 3, 950, 0),
+			},
+			expected: [][]int{{1, 4, 2}},
+		},
+		{
+			name: "four players",
+			tickets: []*lobby.Ticket{
+				ticket(1, 1, 4, 1500, 0),
+				ticket(2, 1, 4, 1500, 0),
+				ticket(3, 1, 4, 1500, 0),
+				ticket(4, 1, 4, 1500, 0),
+				ticket(5, 1, 4, 1500, 0),
+			},
+			expected: [][]int{{1, 2, 3, 4}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			matches := [][]int{}
+			for _, match := range lobby.Match(tc.tickets, now, lobby.DefaultTolerance) {
+				ids := []int{}
+				for _, t := range match {
+					ids = append(ids, t.UserID)
+				}
+				matches = append(matches, ids)
+			}
+			assert.Equal(t, tc.expected, matches)
+		})
+	}
+}
+
+func TestMatchmaker_Tick(t *testing.T) {
+	m := lobby.NewManager()
+	mm := lobby.NewMatchmaker(m, lobby.DefaultTolerance)
+	assert.NoError(t, mm.Enqueue(&lobby.Ticket{UserID: 1, GameID: 1, Players: 2, Rating: 1500}))
+	assert.Equal(t, lobby.ErrAlreadyQueued, mm.Enqueue(&lobby.Ticket{UserID: 1, GameID: 1, Players: 2, Rating: 1500}))
+	assert.NoError(t, mm.Enqueue(&lobby.Ticket{UserID: 2, GameID: 1, Players: 2, Rating: 1520}))
+	assert.NoError(t, mm.Enqueue(&lobby.Ticket{UserID: 3, GameID: 1, Players: 2, Rating: 1500}))
+	assert.NoError(t, mm.Cancel(3))
+	assert.Equal(t, lobby.ErrNotQueued, mm.Cancel(3))
+
+	mm.Tick(time.Now())
+	assert.Equal(t, 0, mm.Count())
+
+	_, id, err := mm.Status(2)
+	assert.NoError(t, err)
+	l, err := m.Get(id)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LobbyPrivate, l.Visibility)
+	assert.Len(t, l.Members, 2)
+
+	assert.Equal(t, lobby.ErrAlreadyInLobby, mm.Enqueue(&lobby.Ticket{UserID: 1, GameID: 1, Players: 2}))
+
+	// The lobby is claimed once, and forgotten once the player leaves
+	_, _, err = mm.Status(2)
+	assert.Equal(t, lobby.ErrNotQueued, err)
+	assert.NoError(t, m.Leave(id, 1))
+	mm.Tick(time.Now())
+	_, _, err = mm.Status(1)
+	assert.Equal(t, lobby.ErrNotQueued, err)
+}
@@ -0,0 +1,94 @@
+// Package logging configures loggers of the server and keeps the logger
+// of a request in its context, so everything serving the request logs
+// with the same fields.
+package logging
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Formats of log entries
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var (
+	errUnknownFormat = errors.New("unknown log format")
+)
+
+type ctxKey struct{}
+
+// holder object. Keeps the request logger, so fields added deeper in
+// the request are seen by everyone logging it.
+type holder struct {
+	mu    sync.Mutex
+	entry *logrus.Entry
+}
+
+// New func. Constructor for a logger with text format and redaction of
+// sensitive fields
+func New() *logrus.Logger {
+	l := logrus.New()
+	l.AddHook(redactHook{})
+
+	return l
+}
+
+// Configure func. Sets the level and the format of the logger. Empty
+// format means text.
+func Configure(l *logrus.Logger, level, format string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "", FormatText:
+		l.SetFormatter(&logrus.TextFormatter{})
+	case FormatJSON:
+		l.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return errUnknownFormat
+	}
+	l.SetLevel(lvl)
+
+	return nil
+}
+
+// NewContext func. Returns copy of the context with the request logger
+func NewContext(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &holder{entry: entry})
+}
+
+// FromContext func. Returns the request logger of the context, or the
+// standard logger if the context has none
+func FromContext(ctx context.Context) *logrus.Entry {
+	h, ok := ctx.Value(ctxKey{}).(*holder)
+	if !ok {
+		return logrus.NewEntry(logrus.StandardLogger())
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.entry
+}
+
+// AddFields func. Adds fields to the request logger of the context. It's
+// a no-op if the context has no request logger.
+func AddFields(ctx context.Context, fields logrus.Fields) {
+	h, ok := ctx.Value(ctxKey{}).(*holder)
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entry = h.entry.WithFields(fields)
+}
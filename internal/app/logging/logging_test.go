@@ -0,0 +1,75 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/logging"
+)
+
+func TestConfigure(t *testing.T) {
+	l := logging.New()
+	assert.NoError(t, logging.Configure(l, "warn", logging.FormatJSON))
+	assert.Equal(t, logrus.WarnLevel, l.Level)
+	assert.IsType(t, &logrus.JSONFormatter{}, l.Formatter)
+
+	assert.NoError(t, logging.Configure(l, "debug", ""))
+	assert.IsType(t, &logrus.TextFormatter{}, l.Formatter)
+
+	assert.Error(t, logging.Configure(l, "loud", logging.FormatText))
+	assert.Error(t, logging.Configure(l, "info", "xml"))
+}
+
+func TestContext(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := logging.New()
+	l.SetOutput(buf)
+	assert.NoError(t, logging.Configure(l, "info", logging.FormatJSON))
+
+	// Loggers of contexts without one are the standard logger
+	assert.Equal(t, logrus.StandardLogger(), logging.FromContext(context.Background()).Logger)
+	logging.AddFields(context.Background(), logrus.Fields{"user_id": 1})
+
+	ctx := logging.NewContext(context.Background(), l.WithField("request_id", "abc"))
+	logging.AddFields(ctx, logrus.Fields{"user_id": 1})
+	logging.FromContext(ctx).Info("hello")
+
+	entry := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "abc", entry["request_id"])
+	assert.Equal(t, float64(1), entry["user_id"])
+	assert.Equal(t, "hello", entry["msg"])
+}
+
+func TestRedaction(t *testing.T) {
+	buf := &bytes.Buffer{}
+	l := logging.New()
+	l.SetOutput(buf)
+	assert.NoError(t, logging.Configure(l, "info", logging.FormatJSON))
+
+	base := l.WithField("Password", "123456")
+	base.WithFields(logrus.Fields{"session_key": "abc", "user_id": 1}).Info("signed in")
+
+	entry := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, logging.Redacted, entry["Password"])
+	assert.Equal(t, logging.Redacted, entry["session_key"])
+	assert.Equal(t, float64(1), entry["user_id"])
+	// Fields of the entry logged from aren't changed
+	assert.Equal(t, "123456", base.Data["Password"])
+}
+
+func TestRedactURL(t *testing.T) {
+	u, _ := url.Parse("/games/1?page=2")
+	assert.Equal(t, "/games/1?page=2", logging.RedactURL(u))
+
+	u, _ = url.Parse("/invites?token=abc&page=2")
+	assert.Equal(t, "/invites?page=2&token=%5BREDACTED%5D", logging.RedactURL(u))
+	assert.Equal(t, "token=abc&page=2", u.RawQuery)
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Redacted replaces values of sensitive fields in logs
+const Redacted = "[REDACTED]"
+
+// sensitive are parts of names of fields and query parameters whose
+// values never get to logs
+var sensitive = []string{
+	"password",
+	"token",
+	"secret",
+	"session",
+	"cookie",
+	"authorization",
+}
+
+// Sensitive func. Checks whether the value of the field with the name
+// is redacted in logs
+func Sensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range sensitive {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RedactURL func. Returns the request URI of the URL with values of
+// sensitive query parameters redacted
+func RedactURL(u *url.URL) string {
+	query := u.Query()
+	redacted := false
+	for name := range query {
+		if Sensitive(name) {
+			query.Set(name, Redacted)
+			redacted = true
+		}
+	}
+	if !redacted {
+		return u.RequestURI()
+	}
+
+	copied := *u
+	copied.RawQuery = query.Encode()
+
+	return copied.RequestURI()
+}
+
+// redactHook object. Logrus hook that redacts values of sensitive fields
+// of every entry.
+type redactHook struct{}
+
+// Levels func. The hook redacts entries of all levels
+func (redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire func. Replaces values of sensitive fields of the entry. Fields
+// are copied first, since entries derived from the same logger share
+// them.
+func (redactHook) Fire(e *logrus.Entry) error {
+	var data logrus.Fields
+	for name := range e.Data {
+		if !Sensitive(name) {
+			continue
+		}
+		if data == nil {
+			data = make(logrus.Fields, len(e.Data))
+			for k, v := range e.Data {
+				data[k] = v
+			}
+		}
+		data[name] = Redacted
+	}
+	if data != nil {
+		e.Data = data
+	}
+
+	return nil
+}
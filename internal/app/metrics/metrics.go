@@ -0,0 +1,374 @@
+// Package metrics keeps metrics of the server and exposes them in the
+// Prometheus text format.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContentType is the content type of the Prometheus text format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are upper bounds of histogram buckets fit for request
+// latencies in seconds
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// metric interface. Writes samples of a metric family.
+type metric interface {
+	write(w *bufio.Writer)
+}
+
+// Registry object. Keeps metrics in the order they were registered in.
+// It is safe for concurrent use.
+type Registry struct {
+	mu      sync.Mutex
+	names   map[string]bool
+	metrics []metric
+}
+
+// NewRegistry func. Constructor for Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		names: make(map[string]bool),
+	}
+}
+
+// register func. Adds the metric with the name, names are unique
+func (r *Registry) register(name string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.names[name] {
+		panic("metrics: duplicate metric " + name)
+	}
+	r.names[name] = true
+	r.metrics = append(r.metrics, m)
+}
+
+// WriteTo func. Writes all metrics in the Prometheus text format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, m := range metrics {
+		m.write(bw)
+	}
+	err := bw.Flush()
+
+	return cw.n, err
+}
+
+// Handler func. Returns handler that serves the metrics
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		r.WriteTo(w)
+	})
+}
+
+// family object. Describes a metric family: its name, help, type and
+// names of labels.
+type family struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+// header func. Writes HELP and TYPE lines of the family
+func (f *family) header(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.kind)
+}
+
+// key func. Returns key of the label values, panics if their number
+// doesn't match names of labels
+func (f *family) key(values []string) string {
+	if len(values) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s has %d labels, got %d values", f.name, len(f.labels), len(values)))
+	}
+
+	return strings.Join(values, "\xff")
+}
+
+// labelPairs func. Formats labels with the values and extra pairs, e.g.
+// {route="/games",le="0.5"}
+func (f *family) labelPairs(values []string, extra ...string) string {
+	if len(values) == 0 && len(extra) == 0 {
+		return ""
+	}
+
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	pairs := make([]string, 0, len(values)+len(extra)/2)
+	for i, v := range values {
+		pairs = append(pairs, f.labels[i]+`="`+escape.Replace(v)+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+escape.Replace(extra[i+1])+`"`)
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// series object. Describes a value of a counter or a gauge with its
+// label values.
+type series struct {
+	values []string
+	value  float64
+}
+
+// vector object. Keeps values of a counter or a gauge by label values.
+type vector struct {
+	family
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// add func. Adds delta to the value with the label values, or sets it
+func (v *vector) add(delta float64, set bool, values []string) {
+	key := v.key(values)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{values: append([]string(nil), values...)}
+		v.series[key] = s
+	}
+	if set {
+		s.value = delta
+	} else {
+		s.value += delta
+	}
+}
+
+// write func. Writes values ordered by label values
+func (v *vector) write(w *bufio.Writer) {
+	v.header(w)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for _, key := range sortedKeys(v.series) {
+		s := v.series[key]
+		fmt.Fprintf(w, "%s%s %s\n", v.name, v.labelPairs(s.values), formatFloat(s.value))
+	}
+}
+
+// Counter object. Describes a metric which only goes up, e.g. number of
+// requests served.
+type Counter struct {
+	vector
+}
+
+// NewCounter func. Registers counter with the labels
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{vector{family: family{name, help, "counter", labels}, series: make(map[string]*series)}}
+	r.register(name, c)
+
+	return c
+}
+
+// Inc func. Adds one to the counter with the label values
+func (c *Counter) Inc(values ...string) {
+	c.add(1, false, values)
+}
+
+// Add func. Adds delta to the counter with the label values, negative
+// deltas are ignored
+func (c *Counter) Add(delta float64, values ...string) {
+	if delta < 0 {
+		return
+	}
+	c.add(delta, false, values)
+}
+
+// Gauge object. Describes a metric which goes up and down, e.g. number
+// of open lobbies.
+type Gauge struct {
+	vector
+}
+
+// NewGauge func. Registers gauge with the labels
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{vector{family: family{name, help, "gauge", labels}, series: make(map[string]*series)}}
+	r.register(name, g)
+
+	return g
+}
+
+// Set func. Sets the gauge with the label values
+func (g *Gauge) Set(value float64, values ...string) {
+	g.add(value, true, values)
+}
+
+// Add func. Adds delta to the gauge with the label values
+func (g *Gauge) Add(delta float64, values ...string) {
+	g.add(delta, false, values)
+}
+
+// valueFunc object. Describes a counter or a gauge read when metrics
+// are written.
+type valueFunc struct {
+	family
+	fn func() float64
+}
+
+// NewCounterFunc func. Registers counter without labels whose value is
+// read from fn every time metrics are written, e.g. a counter kept by
+// another package
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(name, &valueFunc{family: family{name: name, help: help, kind: "counter"}, fn: fn})
+}
+
+// NewGaugeFunc func. Registers gauge without labels whose value is read
+// from fn every time metrics are written
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(name, &valueFunc{family: family{name: name, help: help, kind: "gauge"}, fn: fn})
+}
+
+// write func. Writes the value read right now
+func (v *valueFunc) write(w *bufio.Writer) {
+	v.header(w)
+	fmt.Fprintf(w, "%s %s\n", v.name, formatFloat(v.fn()))
+}
+
+// histogramSeries object. Describes observations of a histogram with
+// its label values.
+type histogramSeries struct {
+	values []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// Histogram object. Describes a metric which counts observations in
+// buckets, e.g. latencies of requests.
+type Histogram struct {
+	family
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+// NewHistogram func. Registers histogram with upper bounds of buckets
+// and the labels. Nil buckets mean the default ones.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	h := &Histogram{
+		family:  family{name, help, "histogram", labels},
+		buckets: buckets,
+		series:  make(map[string]*histogramSeries),
+	}
+	r.register(name, h)
+
+	return h
+}
+
+// Observe func. Adds the observed value to the histogram with the label
+// values
+func (h *Histogram) Observe(value float64, values ...string) {
+	key := h.key(values)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{
+			values: append([]string(nil), values...),
+			counts: make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+
+	for i, upper := range h.buckets {
+		if value <= upper {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.sum += value
+}
+
+// write func. Writes cumulative buckets, sums and counts ordered by
+// label values
+func (h *Histogram) write(w *bufio.Writer) {
+	h.header(w)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(s.values, "le", formatFloat(upper)), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labelPairs(s.values, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labelPairs(s.values), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labelPairs(s.values), s.count)
+	}
+}
+
+// sortedKeys func. Returns keys of the map in order
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]*series:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*histogramSeries:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// formatFloat func. Formats the value the way Prometheus parses it
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// countingWriter object. Counts bytes written to the underlying writer.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write func. Writes to the underlying writer and counts bytes
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+
+	return n, err
+}
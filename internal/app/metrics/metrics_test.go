@@ -0,0 +1,72 @@
+package metrics_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/metrics"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	r := metrics.NewRegistry()
+	requests := r.NewCounter("requests_total", "Requests served.", "route", "code")
+	online := r.NewGauge("online", "Users online.")
+	r.NewGaugeFunc("lobbies", "Open lobbies.", func() float64 { return 3 })
+	r.NewCounterFunc("waits_total", "Waits.", func() float64 { return 7 })
+	latency := r.NewHistogram("latency_seconds", "Latency\nof requests.", []float64{1, 0.1}, "route")
+
+	requests.Inc("/games/{id}", "200")
+	requests.Add(2, "/games/{id}", "200")
+	requests.Add(-1, "/games/{id}", "200")
+	requests.Inc(`/a"b`, "500")
+	online.Set(5)
+	online.Add(-1)
+	latency.Observe(0.05, "/games")
+	latency.Observe(0.5, "/games")
+	latency.Observe(3, "/games")
+
+	buf := &bytes.Buffer{}
+	n, err := r.WriteTo(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	assert.Equal(t, `# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/a\"b",code="500"} 1
+requests_total{route="/games/{id}",code="200"} 3
+# HELP online Users online.
+# TYPE online gauge
+online 4
+# HELP lobbies Open lobbies.
+# TYPE lobbies gauge
+lobbies 3
+# HELP waits_total Waits.
+# TYPE waits_total counter
+waits_total 7
+# HELP latency_seconds Latency\nof requests.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="/games",le="0.1"} 1
+latency_seconds_bucket{route="/games",le="1"} 2
+latency_seconds_bucket{route="/games",le="+Inf"} 3
+latency_seconds_sum{route="/games"} 3.55
+latency_seconds_count{route="/games"} 3
+`, buf.String())
+
+	assert.Panics(t, func() { r.NewGauge("online", "Again.") })
+	assert.Panics(t, func() { requests.Inc("/games") })
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	r := metrics.NewRegistry()
+	r.NewCounter("logins_total", "Logins.", "result").Inc("success")
+
+	rec := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	r.Handler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, metrics.ContentType, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `logins_total{result="success"} 1`)
+}
@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// User event types
+const (
+	EventLogin         = "login"
+	EventMatchPlayed   = "match_played"
+	EventMatchWon      = "match_won"
+	EventReviewWritten = "review_written"
+	EventTournamentWon = "tournament_won"
+)
+
+// UserEvent object that describes something the user did. Events are
+// the history achievements are evaluated from. Zero GameID means the
+// event isn't related to a game.
+type UserEvent struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Type      string    `json:"type"`
+	GameID    int       `json:"game_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AchievementProgress object that describes how close the user is to
+// the achievement. Progress never exceeds Target.
+type AchievementProgress struct {
+	UserID     int        `json:"user_id"`
+	Code       string     `json:"code"`
+	Progress   int        `json:"progress"`
+	Target     int        `json:"target"`
+	UnlockedAt *time.Time `json:"unlocked_at,omitempty"`
+}
+
+// BeforeCreate func. Sets creation date of the event unless it is known
+func (e *UserEvent) BeforeCreate() {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+}
+
+// BeforeSave func. Caps progress with the target and unlocks the
+// achievement once the target is reached
+func (p *AchievementProgress) BeforeSave() {
+	if p.Progress > p.Target {
+		p.Progress = p.Target
+	}
+
+	if p.UnlockedAt == nil && p.Progress >= p.Target {
+		now := time.Now().UTC()
+		p.UnlockedAt = &now
+	}
+}
+
+// Unlocked func. Checks that the achievement is unlocked
+func (p *AchievementProgress) Unlocked() bool {
+	return p.UnlockedAt != nil
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Activity types
+const (
+	ActivityStartedPlaying      = "started_playing"
+	ActivityReviewed            = "reviewed"
+	ActivityAchievementUnlocked = "achievement_unlocked"
+	ActivityTournamentWon       = "tournament_won"
+)
+
+// Activity object that describes something the user did, shown to
+// friends of the user in their feeds. Text describes what the user did
+// in the past tense, e.g. "started playing Chess". Zero GameID means
+// the activity isn't related to a game.
+type Activity struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Type      string    `json:"type"`
+	GameID    int       `json:"game_id,omitempty"`
+	Text      string    `json:"text"`
+	Link      string    `json:"link,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate func. Validating activity instance for type and text
+func (a *Activity) Validate() error {
+	return validation.ValidateStruct(
+		a,
+		validation.Field(&a.UserID, validation.Required),
+		validation.Field(&a.Type, validation.Required, validation.In(
+			ActivityStartedPlaying,
+			ActivityReviewed,
+			ActivityAchievementUnlocked,
+			ActivityTournamentWon,
+		)),
+		validation.Field(&a.Text, validation.Required, validation.Length(1, 300)),
+		validation.Field(&a.Link, validation.Length(0, 200)),
+	)
+}
+
+// BeforeCreate func. Sets the date the activity happened at
+func (a *Activity) BeforeCreate() {
+	a.CreatedAt = time.Now().UTC()
+}
+
+// Library func. Checks if the activity comes from the library of the
+// user, so it is shown to those who can see the library only
+func (a *Activity) Library() bool {
+	return a.Type == ActivityStartedPlaying
+}
@@ -0,0 +1,73 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+func TestActivity_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		a       func() *model.Activity
+		isValid bool
+	}{
+		{
+			name: "valid",
+			a: func() *model.Activity {
+				return model.TestActivity(t, 1, 1)
+			},
+			isValid: true,
+		},
+		{
+			name: "without game",
+			a: func() *model.Activity {
+				a := model.TestActivity(t, 1, 0)
+				a.Type = model.ActivityAchievementUnlocked
+
+				return a
+			},
+			isValid: true,
+		},
+		{
+			name: "unknown type",
+			a: func() *model.Activity {
+				a := model.TestActivity(t, 1, 1)
+				a.Type = "logged_in"
+
+				return a
+			},
+			isValid: false,
+		},
+		{
+			name: "empty text",
+			a: func() *model.Activity {
+				a := model.TestActivity(t, 1, 1)
+				a.Text = ""
+
+				return a
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.a().Validate())
+			} else {
+				assert.Error(t, tc.a().Validate())
+			}
+		})
+	}
+}
+
+func TestActivity_Library(t *testing.T) {
+	a := model.TestActivity(t, 1, 1)
+	assert.False(t, a.Library())
+
+	a.Type = model.ActivityStartedPlaying
+	assert.True(t, a.Library())
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Chat room kinds
+const (
+	ChatRoomHall   = "hall"
+	ChatRoomGame   = "game"
+	ChatRoomDirect = "dm"
+	ChatRoomGuild  = "guild"
+)
+
+var (
+	// ErrInvalidChatRoom error tells us, that chat room name can't be parsed
+	ErrInvalidChatRoom = errors.New("invalid chat room")
+)
+
+// ChatMessage object that describes a message written to a chat room
+type ChatMessage struct {
+	ID        int       `json:"id"`
+	Room      string    `json:"room"`
+	UserID    int       `json:"user_id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ChatRoom object that describes parsed chat room name. Hall is the
+// public tavern hall, game rooms belong to a game, guild rooms belong
+// to members of a guild and direct rooms belong to two users.
+type ChatRoom struct {
+	Kind    string
+	GameID  int
+	GuildID int
+	UserIDs [2]int
+}
+
+// Validate func. Validating chat message instance for room and text
+func (m *ChatMessage) Validate() error {
+	return validation.ValidateStruct(
+		m,
+		validation.Field(&m.Room, validation.Required),
+		validation.Field(&m.Text, validation.Required, validation.Length(1, 2000)),
+	)
+}
+
+// BeforeCreate func. Sets the date the message was written at
+func (m *ChatMessage) BeforeCreate() {
+	m.CreatedAt = time.Now().UTC()
+}
+
+// GameChatRoom func. Returns name of the chat room of the game
+func GameChatRoom(gameID int) string {
+	return fmt.Sprintf("%s:%d", ChatRoomGame, gameID)
+}
+
+// GuildChatRoom func. Returns name of the chat room of the guild
+func GuildChatRoom(guildID int) string {
+	return fmt.Sprintf("%s:%d", ChatRoomGuild, guildID)
+}
+
+// DirectChatRoom func. Returns name of the direct messages room of two
+// users. The name doesn't depend on the order of the users.
+func DirectChatRoom(userID, otherID int) string {
+	if userID > otherID {
+		userID, otherID = otherID, userID
+	}
+
+	return fmt.Sprintf("%s:%d:%d", ChatRoomDirect, userID, otherID)
+}
+
+// ParseChatRoom func. Parses chat room name created by GameChatRoom,
+// GuildChatRoom, DirectChatRoom or the hall room name.
+func ParseChatRoom(name string) (*ChatRoom, error) {
+	parts := strings.Split(name, ":")
+	ids := make([]int, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		id, err := strconv.Atoi(p)
+		if err != nil || id < 1 {
+			return nil, ErrInvalidChatRoom
+		}
+		ids = append(ids, id)
+	}
+
+	switch {
+	case parts[0] == ChatRoomHall && len(ids) == 0:
+		return &ChatRoom{Kind: ChatRoomHall}, nil
+	case parts[0] == ChatRoomGame && len(ids) == 1:
+		return &ChatRoom{Kind: ChatRoomGame, GameID: ids[0]}, nil
+	case parts[0] == ChatRoomGuild && len(ids) == 1:
+		return &ChatRoom{Kind: ChatRoomGuild, GuildID: ids[0]}, nil
+	case parts[0] == ChatRoomDirect && len(ids) == 2 && ids[0] < ids[1]:
+		return &ChatRoom{Kind: ChatRoomDirect, UserIDs: [2]int{ids[0], ids[1]}}, nil
+	}
+
+	return nil, ErrInvalidChatRoom
+}
+
+// Member func. Checks if the user belongs to the direct messages room
+func (r *ChatRoom) Member(userID int) bool {
+	return r.UserIDs[0] == userID || r.UserIDs[1] == userID
+}
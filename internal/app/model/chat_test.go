@@ -0,0 +1,75 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+func TestParseChatRoom(t *testing.T) {
+	testCases := []struct {
+		name     string
+		room     string
+		expected *model.ChatRoom
+	}{
+		{
+			name:     "hall",
+			room:     model.ChatRoomHall,
+			expected: &model.ChatRoom{Kind: model.ChatRoomHall},
+		},
+		{
+			name:     "game",
+			room:     model.GameChatRoom(7),
+			expected: &model.ChatRoom{Kind: model.ChatRoomGame, GameID: 7},
+		},
+		{
+			name:     "guild",
+			room:     model.GuildChatRoom(3),
+			expected: &model.ChatRoom{Kind: model.ChatRoomGuild, GuildID: 3},
+		},
+		{
+			name:     "direct",
+			room:     model.DirectChatRoom(5, 2),
+			expected: &model.ChatRoom{Kind: model.ChatRoomDirect, UserIDs: [2]int{2, 5}},
+		},
+		{
+			name: "unordered direct",
+			room: "dm:5:2",
+		},
+		{
+			name: "game without id",
+			room: "game",
+		},
+		{
+			name: "unknown kind",
+			room: "lobby:1",
+		},
+		{
+			name: "invalid id",
+			room: "game:abc",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			room, err := model.ParseChatRoom(tc.room)
+			if tc.expected == nil {
+				assert.EqualError(t, err, model.ErrInvalidChatRoom.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, room)
+		})
+	}
+}
+
+func TestChatMessage_Validate(t *testing.T) {
+	m := model.TestChatMessage(t, model.ChatRoomHall, 1)
+	assert.NoError(t, m.Validate())
+
+	m.Text = ""
+	assert.Error(t, m.Validate())
+}
@@ -0,0 +1,230 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// RSVP statuses. Users who want to go to the full event are waitlisted.
+const (
+	RSVPGoing      = "going"
+	RSVPMaybe      = "maybe"
+	RSVPDeclined   = "declined"
+	RSVPWaitlisted = "waitlisted"
+)
+
+// Event recurrences
+const (
+	EventWeekly = "weekly"
+)
+
+var (
+	// ErrEventOver error tells us, that the event (every occurrence of
+	// a recurring one) is over
+	ErrEventOver = errors.New("event is over")
+)
+
+// Event object that describes a game night hosted by the user. Times
+// are stored in UTC, Timezone is the IANA time zone of the host the
+// event is shown in. Weekly events repeat until RepeatUntil, or
+// forever if it isn't set.
+type Event struct {
+	ID          int        `json:"id"`
+	HostID      int        `json:"host_id"`
+	GameID      int        `json:"game_id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      time.Time  `json:"ends_at"`
+	Timezone    string     `json:"timezone"`
+	Capacity    int        `json:"capacity"`
+	Recurrence  string     `json:"recurrence"`
+	RepeatUntil *time.Time `json:"repeat_until,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// EventRSVP object that describes RSVP of the user to the event. For
+// waitlisted users UpdatedAt is their place in the waitlist.
+type EventRSVP struct {
+	EventID   int       `json:"event_id"`
+	UserID    int       `json:"user_id"`
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CalendarToken object that describes the secret token of the calendar
+// feed of the user's events, calendar applications subscribe to the
+// feed without a session. Only the hash of the token is stored, the
+// token itself is shown to the user once. A new token replaces the old
+// one.
+type CalendarToken struct {
+	UserID    int       `json:"-"`
+	Token     string    `json:"token,omitempty"`
+	Hash      string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewCalendarToken func. Constructor for a random CalendarToken of the
+// user
+func NewCalendarToken(userID int) (*CalendarToken, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	token := hex.EncodeToString(b)
+
+	return &CalendarToken{
+		UserID:    userID,
+		Token:     token,
+		Hash:      HashCalendarToken(token),
+		CreatedAt: time.Now().UTC(),
+	}, nil
+}
+
+// HashCalendarToken func. Returns the hash the token is stored and
+// found by
+func HashCalendarToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Validate func. Validating event instance for title, game, times,
+// time zone, capacity and recurrence
+func (e *Event) Validate() error {
+	return validation.ValidateStruct(
+		e,
+		validation.Field(&e.Title, validation.Required, validation.Length(3, 100)),
+		validation.Field(&e.Description, validation.Length(0, 2000)),
+		validation.Field(&e.GameID, validation.Required),
+		validation.Field(&e.StartsAt, validation.Required),
+		validation.Field(&e.EndsAt, validation.Required, validation.Min(e.StartsAt).Exclusive()),
+		validation.Field(&e.Timezone, validation.Required, validation.By(timezone)),
+		validation.Field(&e.Capacity, validation.Required, validation.Min(1), validation.Max(1000)),
+		validation.Field(&e.Recurrence, validation.In(EventWeekly)),
+		validation.Field(
+			&e.RepeatUntil,
+			validation.When(e.RepeatUntil != nil, validation.Min(e.StartsAt)),
+			validation.When(e.Recurrence == "", validation.Nil),
+		),
+	)
+}
+
+// BeforeCreate func. Stores times of the event in UTC and sets the date
+// it was created at
+func (e *Event) BeforeCreate() {
+	e.StartsAt = e.StartsAt.UTC()
+	e.EndsAt = e.EndsAt.UTC()
+	if e.RepeatUntil != nil {
+		until := e.RepeatUntil.UTC()
+		e.RepeatUntil = &until
+	}
+	e.CreatedAt = time.Now().UTC()
+}
+
+// Location func. Returns time zone of the host, UTC if it's unknown
+func (e *Event) Location() *time.Location {
+	loc, err := time.LoadLocation(e.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// Over func. Checks if the last occurrence of the event is over at the time
+func (e *Event) Over(now time.Time) bool {
+	switch {
+	case e.Recurrence == "":
+		return !now.Before(e.EndsAt)
+	case e.RepeatUntil == nil:
+		return false
+	}
+
+	return !now.Before(e.RepeatUntil.Add(e.EndsAt.Sub(e.StartsAt)))
+}
+
+// ApplyRSVP func. Applies RSVP of the user with the status to RSVPs of
+// the event at the time. Users who want to go to the full event are
+// waitlisted. When somebody going changes their mind, the first user of
+// the waitlist goes instead. Returns changed RSVPs, the one of the user
+// goes first even if it didn't change.
+func (e *Event) ApplyRSVP(rsvps []*EventRSVP, userID int, status string, now time.Time) ([]*EventRSVP, error) {
+	if err := validation.Validate(status, validation.Required, validation.In(RSVPGoing, RSVPMaybe, RSVPDeclined)); err != nil {
+		return nil, err
+	}
+
+	if e.Over(now) {
+		return nil, ErrEventOver
+	}
+
+	var own *EventRSVP
+	going := 0
+	for _, r := range rsvps {
+		if r.UserID == userID {
+			own = r
+		}
+		if r.Status == RSVPGoing {
+			going++
+		}
+	}
+
+	if own == nil {
+		own = &EventRSVP{EventID: e.ID, UserID: userID}
+	}
+	prev := own.Status
+	// Users who already go or wait keep their place
+	next := status
+	if status == RSVPGoing {
+		switch {
+		case prev == RSVPGoing || prev == RSVPWaitlisted:
+			next = prev
+		case going >= e.Capacity:
+			next = RSVPWaitlisted
+		}
+	}
+
+	if next != prev {
+		own.Status = next
+		own.UpdatedAt = now
+	}
+	changed := []*EventRSVP{own}
+	if prev != RSVPGoing || next == RSVPGoing {
+		return changed, nil
+	}
+	// Promoting the first user of the waitlist to the free place
+	var first *EventRSVP
+	for _, r := range rsvps {
+		if r.Status != RSVPWaitlisted {
+			continue
+		}
+		if first == nil || r.UpdatedAt.Before(first.UpdatedAt) ||
+			(r.UpdatedAt.Equal(first.UpdatedAt) && r.UserID < first.UserID) {
+			first = r
+		}
+	}
+
+	if first != nil {
+		first.Status = RSVPGoing
+		first.UpdatedAt = now
+		changed = append(changed, first)
+	}
+
+	return changed, nil
+}
+
+// timezone func. Validation rule that checks that the value is a known
+// IANA time zone name
+func timezone(value interface{}) error {
+	s, _ := value.(string)
+	if _, err := time.LoadLocation(s); err != nil || s == "" || s == "Local" {
+		return errors.New("must be a valid time zone")
+	}
+
+	return nil
+}
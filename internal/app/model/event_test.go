@@ -0,0 +1,174 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+func TestEvent_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		e       func() *model.Event
+		isValid bool
+	}{
+		{
+			name: "valid",
+			e: func() *model.Event {
+				return model.TestEvent(t, 1, 1)
+			},
+			isValid: true,
+		},
+		{
+			name: "repeat until",
+			e: func() *model.Event {
+				e := model.TestEvent(t, 1, 1)
+				until := e.StartsAt.Add(4 * 7 * 24 * time.Hour)
+				e.RepeatUntil = &until
+
+				return e
+			},
+			isValid: true,
+		},
+		{
+			name: "repeat until without recurrence",
+			e: func() *model.Event {
+				e := model.TestEvent(t, 1, 1)
+				e.Recurrence = ""
+				until := e.StartsAt.Add(7 * 24 * time.Hour)
+				e.RepeatUntil = &until
+
+				return e
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown recurrence",
+			e: func() *model.Event {
+				e := model.TestEvent(t, 1, 1)
+				e.Recurrence = "daily"
+
+				return e
+			},
+			isValid: false,
+		},
+		{
+			name: "ends before start",
+			e: func() *model.Event {
+				e := model.TestEvent(t, 1, 1)
+				e.EndsAt = e.StartsAt
+
+				return e
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown timezone",
+			e: func() *model.Event {
+				e := model.TestEvent(t, 1, 1)
+				e.Timezone = "Mars/Olympus"
+
+				return e
+			},
+			isValid: false,
+		},
+		{
+			name: "no capacity",
+			e: func() *model.Event {
+				e := model.TestEvent(t, 1, 1)
+				e.Capacity = 0
+
+				return e
+			},
+			isValid: false,
+		},
+		{
+			name: "empty title",
+			e: func() *model.Event {
+				e := model.TestEvent(t, 1, 1)
+				e.Title = ""
+
+				return e
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.e().Validate())
+			} else {
+				assert.Error(t, tc.e().Validate())
+			}
+		})
+	}
+}
+
+func TestEvent_Over(t *testing.T) {
+	e := model.TestEvent(t, 1, 1)
+	assert.False(t, e.Over(e.EndsAt.Add(365*24*time.Hour)))
+
+	until := e.StartsAt.Add(7 * 24 * time.Hour)
+	e.RepeatUntil = &until
+	assert.False(t, e.Over(until))
+	assert.True(t, e.Over(until.Add(e.EndsAt.Sub(e.StartsAt))))
+
+	e.Recurrence = ""
+	e.RepeatUntil = nil
+	assert.False(t, e.Over(e.StartsAt))
+	assert.True(t, e.Over(e.EndsAt))
+}
+
+func TestEvent_ApplyRSVP(t *testing.T) {
+	e := model.TestEvent(t, 1, 1)
+	e.ID = 1
+	e.Capacity = 2
+	now := time.Now().UTC()
+	// Users RSVP in order of their ids
+	rsvps := []*model.EventRSVP{}
+	rsvp := func(userID int, status string) []*model.EventRSVP {
+		t.Helper()
+		changed, err := e.ApplyRSVP(rsvps, userID, status, now)
+		assert.NoError(t, err)
+		if len(rsvps) < userID {
+			rsvps = append(rsvps, changed[0])
+		}
+		now = now.Add(time.Minute)
+
+		return changed
+	}
+
+	rsvp(1, model.RSVPGoing)
+	rsvp(2, model.RSVPGoing)
+	assert.Equal(t, model.RSVPWaitlisted, rsvp(3, model.RSVPGoing)[0].Status)
+	assert.Equal(t, model.RSVPWaitlisted, rsvp(4, model.RSVPGoing)[0].Status)
+	// Asking to go again keeps the place in the waitlist
+	waitlisted := rsvps[2].UpdatedAt
+	assert.Equal(t, model.RSVPWaitlisted, rsvp(3, model.RSVPGoing)[0].Status)
+	assert.Equal(t, waitlisted, rsvps[2].UpdatedAt)
+	// The first user of the waitlist goes instead of the declined one
+	changed := rsvp(1, model.RSVPDeclined)
+	if assert.Len(t, changed, 2) {
+		assert.Equal(t, model.RSVPDeclined, changed[0].Status)
+		assert.Equal(t, 3, changed[1].UserID)
+		assert.Equal(t, model.RSVPGoing, changed[1].Status)
+	}
+	// Maybe frees the place too
+	changed = rsvp(2, model.RSVPMaybe)
+	if assert.Len(t, changed, 2) {
+		assert.Equal(t, 4, changed[1].UserID)
+	}
+	// Nobody is waitlisted anymore
+	assert.Len(t, rsvp(3, model.RSVPDeclined), 1)
+
+	_, err := e.ApplyRSVP(rsvps, 5, model.RSVPWaitlisted, now)
+	assert.Error(t, err)
+
+	e.Recurrence = ""
+	_, err = e.ApplyRSVP(rsvps, 5, model.RSVPGoing, e.EndsAt)
+	assert.EqualError(t, err, model.ErrEventOver.Error())
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// Friendship statuses
+const (
+	FriendshipPending  = "pending"
+	FriendshipAccepted = "accepted"
+)
+
+// Friendship actions
+const (
+	FriendshipRequest  = "request"
+	FriendshipAccept   = "accept"
+	FriendshipDecline  = "decline"
+	FriendshipCancel   = "cancel"
+	FriendshipUnfriend = "unfriend"
+)
+
+var (
+	// ErrInvalidFriendshipTransition error tells us, that the action
+	// can't be applied to the current state of the friendship
+	ErrInvalidFriendshipTransition = errors.New("invalid friendship transition")
+)
+
+// Friendship object that describes relation between two users. UserID
+// is the user who sent the request and FriendID is the one who got it.
+type Friendship struct {
+	UserID    int       `json:"user_id"`
+	FriendID  int       `json:"friend_id"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Other func. Returns id of the other user of the friendship
+func (f *Friendship) Other(userID int) int {
+	if f.UserID == userID {
+		return f.FriendID
+	}
+
+	return f.UserID
+}
+
+// NextFriendship func. Friendship state machine. Takes the current
+// friendship between actor and other user (nil if there is none) and
+// returns the friendship after actor applies the action to it. Nil
+// result means that the friendship has to be removed.
+//
+//	none     --request-->  pending
+//	pending  --accept--->  accepted (only by the requested user)
+//	pending  --request-->  accepted (only by the requested user)
+//	pending  --decline-->  none     (only by the requested user)
+//	pending  --cancel--->  none     (only by the requester)
+//	accepted --unfriend->  none     (by any of the users)
+func NextFriendship(f *Friendship, actorID, otherID int, action string) (*Friendship, error) {
+	if actorID == otherID {
+		return nil, ErrInvalidFriendshipTransition
+	}
+
+	now := time.Now().UTC()
+	if f == nil {
+		if action != FriendshipRequest {
+			return nil, ErrInvalidFriendshipTransition
+		}
+
+		return &Friendship{
+			UserID:    actorID,
+			FriendID:  otherID,
+			Status:    FriendshipPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}, nil
+	}
+
+	next := *f
+	next.UpdatedAt = now
+	switch {
+	case f.Status == FriendshipPending && f.FriendID == actorID &&
+		(action == FriendshipAccept || action == FriendshipRequest):
+		next.Status = FriendshipAccepted
+		return &next, nil
+	case f.Status == FriendshipPending && f.FriendID == actorID && action == FriendshipDecline:
+		return nil, nil
+	case f.Status == FriendshipPending && f.UserID == actorID && action == FriendshipCancel:
+		return nil, nil
+	case f.Status == FriendshipAccepted && action == FriendshipUnfriend:
+		return nil, nil
+	}
+
+	return nil, ErrInvalidFriendshipTransition
+}
@@ -0,0 +1,149 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+func TestNextFriendship(t *testing.T) {
+	pending := &model.Friendship{UserID: 1, FriendID: 2, Status: model.FriendshipPending}
+	accepted := &model.Friendship{UserID: 1, FriendID: 2, Status: model.FriendshipAccepted}
+
+	testCases := []struct {
+		name           string
+		f              *model.Friendship
+		actorID        int
+		otherID        int
+		action         string
+		expectedStatus string
+		isValid        bool
+	}{
+		{
+			name:           "request",
+			f:              nil,
+			actorID:        1,
+			otherID:        2,
+			action:         model.FriendshipRequest,
+			expectedStatus: model.FriendshipPending,
+			isValid:        true,
+		},
+		{
+			name:    "request yourself",
+			f:       nil,
+			actorID: 1,
+			otherID: 1,
+			action:  model.FriendshipRequest,
+			isValid: false,
+		},
+		{
+			name:    "accept without request",
+			f:       nil,
+			actorID: 1,
+			otherID: 2,
+			action:  model.FriendshipAccept,
+			isValid: false,
+		},
+		{
+			name:           "accept",
+			f:              pending,
+			actorID:        2,
+			otherID:        1,
+			action:         model.FriendshipAccept,
+			expectedStatus: model.FriendshipAccepted,
+			isValid:        true,
+		},
+		{
+			name:    "accept own request",
+			f:       pending,
+			actorID: 1,
+			otherID: 2,
+			action:  model.FriendshipAccept,
+			isValid: false,
+		},
+		{
+			name:           "mutual request",
+			f:              pending,
+			actorID:        2,
+			otherID:        1,
+			action:         model.FriendshipRequest,
+			expectedStatus: model.FriendshipAccepted,
+			isValid:        true,
+		},
+		{
+			name:    "repeated request",
+			f:       pending,
+			actorID: 1,
+			otherID: 2,
+			action:  model.FriendshipRequest,
+			isValid: false,
+		},
+		{
+			name:    "decline",
+			f:       pending,
+			actorID: 2,
+			otherID: 1,
+			action:  model.FriendshipDecline,
+			isValid: true,
+		},
+		{
+			name:    "decline own request",
+			f:       pending,
+			actorID: 1,
+			otherID: 2,
+			action:  model.FriendshipDecline,
+			isValid: false,
+		},
+		{
+			name:    "cancel",
+			f:       pending,
+			actorID: 1,
+			otherID: 2,
+			action:  model.FriendshipCancel,
+			isValid: true,
+		},
+		{
+			name:    "cancel other's request",
+			f:       pending,
+			actorID: 2,
+			otherID: 1,
+			action:  model.FriendshipCancel,
+			isValid: false,
+		},
+		{
+			name:    "unfriend",
+			f:       accepted,
+			actorID: 2,
+			otherID: 1,
+			action:  model.FriendshipUnfriend,
+			isValid: true,
+		},
+		{
+			name:    "unfriend pending",
+			f:       pending,
+			actorID: 1,
+			otherID: 2,
+			action:  model.FriendshipUnfriend,
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next, err := model.NextFriendship(tc.f, tc.actorID, tc.otherID, tc.action)
+			if !tc.isValid {
+				assert.EqualError(t, err, model.ErrInvalidFriendshipTransition.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			if tc.expectedStatus == "" {
+				assert.Nil(t, next)
+			} else {
+				assert.Equal(t, tc.expectedStatus, next.Status)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Game object that has id, title and aggregated rating fields.
+// Rating fields are kept up to date incrementally by the store
+// every time a review is written, changed or deleted.
+type Game struct {
+	ID              int     `json:"id"`
+	Title           string  `json:"title"`
+	RatingCount     int     `json:"rating_count"`
+	RatingSum       int     `json:"-"`
+	RatingMean      float64 `json:"rating_mean"`
+	RatingHistogram []int   `json:"rating_histogram"`
+}
+
+// Validate func. Validating game instance for title
+func (g *Game) Validate() error {
+	return validation.ValidateStruct(
+		g,
+		validation.Field(&g.Title, validation.Required, validation.Length(1, 200)),
+	)
+}
+
+// BeforeCreate func. Resets aggregated rating of the new game
+func (g *Game) BeforeCreate() {
+	g.RatingCount = 0
+	g.RatingSum = 0
+	g.RatingHistogram = make([]int, ReviewScoreMax)
+	g.ComputeRatingMean()
+}
+
+// AddRating func. Adds review score to aggregated rating of the game
+func (g *Game) AddRating(score int) {
+	g.RatingCount++
+	g.RatingSum += score
+	g.RatingHistogram[score-1]++
+	g.ComputeRatingMean()
+}
+
+// RemoveRating func. Removes review score from aggregated rating of the game
+func (g *Game) RemoveRating(score int) {
+	g.RatingCount--
+	g.RatingSum -= score
+	g.RatingHistogram[score-1]--
+	g.ComputeRatingMean()
+}
+
+// ComputeRatingMean func. Calculates mean score from the count and
+// the sum of scores.
+func (g *Game) ComputeRatingMean() {
+	if g.RatingCount == 0 {
+		g.RatingMean = 0
+		return
+	}
+
+	g.RatingMean = float64(g.RatingSum) / float64(g.RatingCount)
+}
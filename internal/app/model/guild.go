@@ -0,0 +1,138 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/go-ozzo/ozzo-validation/v4/is"
+)
+
+// Guild member roles, from the least powerful one
+const (
+	GuildRoleMember  = "member"
+	GuildRoleOfficer = "officer"
+	GuildRoleLeader  = "leader"
+)
+
+// Guild invite kinds. Invitations are sent by the guild to the user,
+// join requests are sent by the user to the guild.
+const (
+	GuildInvitation  = "invitation"
+	GuildJoinRequest = "request"
+)
+
+var (
+	// ErrGuildLeader error tells us, that the action can't be applied
+	// to the leader of the guild. Leadership has to be transferred or
+	// the guild has to be disbanded instead.
+	ErrGuildLeader = errors.New("can't be applied to the guild leader")
+
+	// ErrGuildInviteExists error tells us, that the same invite is
+	// already pending
+	ErrGuildInviteExists = errors.New("guild invite already exists")
+
+	// guildTag describes tags shown next to names of guild members
+	guildTag = regexp.MustCompile(`^[A-Z0-9]+$`)
+)
+
+// Guild object that describes a group of players
+type Guild struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Tag         string    `json:"tag"`
+	Description string    `json:"description"`
+	EmblemURL   string    `json:"emblem_url"`
+	MemberCount int       `json:"member_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// GuildMember object that describes membership of the user in the guild.
+// Every user is a member of one guild at most.
+type GuildMember struct {
+	GuildID  int       `json:"guild_id"`
+	UserID   int       `json:"user_id"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// GuildInvite object that describes pending invitation of the user to
+// the guild or pending join request of the user. InviterID is zero for
+// join requests.
+type GuildInvite struct {
+	GuildID   int       `json:"guild_id"`
+	UserID    int       `json:"user_id"`
+	Kind      string    `json:"kind"`
+	InviterID int       `json:"inviter_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GuildLeaderboardEntry object that describes a place of the guild in
+// the guild leaderboard of a game. Rating is the mean rating of members
+// who have one in the game during the season, Players is their number.
+type GuildLeaderboardEntry struct {
+	Rank    int     `json:"rank"`
+	GuildID int     `json:"guild_id"`
+	Name    string  `json:"name"`
+	Tag     string  `json:"tag"`
+	Rating  float64 `json:"rating"`
+	Players int     `json:"players"`
+}
+
+// Validate func. Validating guild instance for name, tag, description
+// and emblem url
+func (g *Guild) Validate() error {
+	return validation.ValidateStruct(
+		g,
+		validation.Field(&g.Name, validation.Required, validation.Length(3, 40)),
+		validation.Field(&g.Tag, validation.Required, validation.Length(2, 5), validation.Match(guildTag)),
+		validation.Field(&g.Description, validation.Length(0, 1000)),
+		validation.Field(&g.EmblemURL, is.URL),
+	)
+}
+
+// BeforeCreate func. Sets the date the guild was founded at
+func (g *Guild) BeforeCreate() {
+	g.CreatedAt = time.Now().UTC()
+}
+
+// Validate func. Validating guild member instance for role. Leaders
+// are only appointed by founding the guild or transferring leadership.
+func (m *GuildMember) Validate() error {
+	return validation.ValidateStruct(
+		m,
+		validation.Field(&m.Role, validation.Required, validation.In(GuildRoleMember, GuildRoleOfficer)),
+	)
+}
+
+// Validate func. Validating guild invite instance for kind and inviter
+func (i *GuildInvite) Validate() error {
+	return validation.ValidateStruct(
+		i,
+		validation.Field(&i.Kind, validation.Required, validation.In(GuildInvitation, GuildJoinRequest)),
+		validation.Field(&i.InviterID, validation.By(requiredIf(i.Kind == GuildInvitation))),
+	)
+}
+
+// BeforeCreate func. Sets the date the invite was sent at
+func (i *GuildInvite) BeforeCreate() {
+	i.CreatedAt = time.Now().UTC()
+}
+
+// AcceptsGuildInvite func. Decides what happens when the invite is sent
+// while the pending one exists (nil if there is none). An invitation
+// sent to the user who asked to join, or a join request sent by the
+// invited user, accepts the pending invite and the user joins the
+// guild. Sending the same kind of invite twice is an error.
+func AcceptsGuildInvite(pending, i *GuildInvite) (bool, error) {
+	if pending == nil {
+		return false, nil
+	}
+
+	if pending.Kind == i.Kind {
+		return false, ErrGuildInviteExists
+	}
+
+	return true, nil
+}
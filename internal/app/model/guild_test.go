@@ -0,0 +1,116 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+func TestGuild_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		g       func() *model.Guild
+		isValid bool
+	}{
+		{
+			name: "valid",
+			g: func() *model.Guild {
+				return model.TestGuild(t)
+			},
+			isValid: true,
+		},
+		{
+			name: "without emblem",
+			g: func() *model.Guild {
+				g := model.TestGuild(t)
+				g.EmblemURL = ""
+
+				return g
+			},
+			isValid: true,
+		},
+		{
+			name: "short name",
+			g: func() *model.Guild {
+				g := model.TestGuild(t)
+				g.Name = "KR"
+
+				return g
+			},
+			isValid: false,
+		},
+		{
+			name: "lowercase tag",
+			g: func() *model.Guild {
+				g := model.TestGuild(t)
+				g.Tag = "krt"
+
+				return g
+			},
+			isValid: false,
+		},
+		{
+			name: "long tag",
+			g: func() *model.Guild {
+				g := model.TestGuild(t)
+				g.Tag = "KNIGHTS"
+
+				return g
+			},
+			isValid: false,
+		},
+		{
+			name: "invalid emblem",
+			g: func() *model.Guild {
+				g := model.TestGuild(t)
+				g.EmblemURL = "emblem"
+
+				return g
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.g().Validate())
+			} else {
+				assert.Error(t, tc.g().Validate())
+			}
+		})
+	}
+}
+
+func TestGuildInvite_Validate(t *testing.T) {
+	i := &model.GuildInvite{GuildID: 1, UserID: 2, Kind: model.GuildJoinRequest}
+	assert.NoError(t, i.Validate())
+
+	i.Kind = model.GuildInvitation
+	assert.Error(t, i.Validate())
+
+	i.InviterID = 3
+	assert.NoError(t, i.Validate())
+}
+
+func TestAcceptsGuildInvite(t *testing.T) {
+	request := &model.GuildInvite{GuildID: 1, UserID: 2, Kind: model.GuildJoinRequest}
+	invitation := &model.GuildInvite{GuildID: 1, UserID: 2, Kind: model.GuildInvitation, InviterID: 3}
+
+	accepts, err := model.AcceptsGuildInvite(nil, request)
+	assert.NoError(t, err)
+	assert.False(t, accepts)
+
+	accepts, err = model.AcceptsGuildInvite(request, invitation)
+	assert.NoError(t, err)
+	assert.True(t, accepts)
+
+	accepts, err = model.AcceptsGuildInvite(invitation, request)
+	assert.NoError(t, err)
+	assert.True(t, accepts)
+
+	_, err = model.AcceptsGuildInvite(request, request)
+	assert.EqualError(t, err, model.ErrGuildInviteExists.Error())
+}
@@ -0,0 +1,144 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Gaming platforms
+const (
+	PlatformPC          = "pc"
+	PlatformPlayStation = "playstation"
+	PlatformXbox        = "xbox"
+	PlatformSwitch      = "switch"
+	PlatformMobile      = "mobile"
+	PlatformTabletop    = "tabletop"
+)
+
+// LFG post statuses
+const (
+	LFGOpen    = "open"
+	LFGClosed  = "closed"
+	LFGExpired = "expired"
+)
+
+// LFGExpiryDelay is how long after the start of the game its LFG post
+// expires, unless the post sets its own expiration time
+const LFGExpiryDelay = time.Hour
+
+var (
+	// ErrLFGPostClosed error tells us, that the post was closed by its
+	// author or has expired
+	ErrLFGPostClosed = errors.New("lfg post is closed")
+	// ErrOwnLFGPost error tells us, that the user can't ask to join
+	// their own post
+	ErrOwnLFGPost = errors.New("can't request to join own lfg post")
+	// ErrSkillOutOfRange error tells us, that the skill rating of the
+	// user is out of the range the post looks for
+	ErrSkillOutOfRange = errors.New("skill rating is out of range")
+
+	// languageCode describes two letter ISO 639-1 language codes
+	languageCode = regexp.MustCompile(`^[a-z]{2}$`)
+)
+
+// LFGPost object that describes a post of the looking-for-group board:
+// the user looks for Players more players for the game on the platform
+// at StartsAt. Zero skill bounds mean there is no bound.
+type LFGPost struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	GameID      int       `json:"game_id"`
+	Platform    string    `json:"platform"`
+	Players     int       `json:"players"`
+	Language    string    `json:"language"`
+	SkillMin    int       `json:"skill_min"`
+	SkillMax    int       `json:"skill_max"`
+	VoiceChat   bool      `json:"voice_chat"`
+	Description string    `json:"description"`
+	StartsAt    time.Time `json:"starts_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// LFGRequest object that describes request of the user to join the
+// group of the LFG post
+type LFGRequest struct {
+	PostID    int       `json:"post_id"`
+	UserID    int       `json:"user_id"`
+	Message   string    `json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate func. Validating LFG post instance for game, platform, number
+// of players, language, skill range and times
+func (p *LFGPost) Validate() error {
+	return validation.ValidateStruct(
+		p,
+		validation.Field(&p.GameID, validation.Required),
+		validation.Field(&p.Platform, validation.Required, validation.In(
+			PlatformPC, PlatformPlayStation, PlatformXbox, PlatformSwitch, PlatformMobile, PlatformTabletop,
+		)),
+		validation.Field(&p.Players, validation.Required, validation.Min(1), validation.Max(99)),
+		validation.Field(&p.Language, validation.Required, validation.Match(languageCode)),
+		validation.Field(&p.SkillMin, validation.Min(0)),
+		validation.Field(&p.SkillMax, validation.When(p.SkillMax != 0, validation.Min(p.SkillMin))),
+		validation.Field(&p.Description, validation.Length(0, 500)),
+		validation.Field(&p.StartsAt, validation.Required),
+		validation.Field(&p.ExpiresAt, validation.When(!p.ExpiresAt.IsZero(), validation.Min(p.StartsAt))),
+	)
+}
+
+// BeforeCreate func. Opens the post and sets the date it was written
+// at. Posts without expiration time expire LFGExpiryDelay after the start.
+func (p *LFGPost) BeforeCreate() {
+	p.Status = LFGOpen
+	p.CreatedAt = time.Now().UTC()
+	p.StartsAt = p.StartsAt.UTC()
+	if p.ExpiresAt.IsZero() {
+		p.ExpiresAt = p.StartsAt.Add(LFGExpiryDelay)
+	}
+	p.ExpiresAt = p.ExpiresAt.UTC()
+}
+
+// Expired func. Checks if the post has expired at the time, even if
+// the expiry job hasn't marked it yet
+func (p *LFGPost) Expired(now time.Time) bool {
+	return p.Status == LFGExpired || !now.Before(p.ExpiresAt)
+}
+
+// CanRequest func. Checks that the user can ask to join the group of
+// the post at the time
+func (p *LFGPost) CanRequest(userID int, now time.Time) error {
+	if p.Status != LFGOpen || p.Expired(now) {
+		return ErrLFGPostClosed
+	}
+
+	if p.UserID == userID {
+		return ErrOwnLFGPost
+	}
+
+	return nil
+}
+
+// InSkillRange func. Checks that the skill rating is in the range the
+// post looks for
+func (p *LFGPost) InSkillRange(skill int) bool {
+	return skill >= p.SkillMin && (p.SkillMax == 0 || skill <= p.SkillMax)
+}
+
+// Validate func. Validating LFG request instance for message
+func (r *LFGRequest) Validate() error {
+	return validation.ValidateStruct(
+		r,
+		validation.Field(&r.Message, validation.Length(0, 300)),
+	)
+}
+
+// BeforeCreate func. Sets the date the request was sent at
+func (r *LFGRequest) BeforeCreate() {
+	r.CreatedAt = time.Now().UTC()
+}
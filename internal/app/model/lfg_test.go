@@ -0,0 +1,120 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+func TestLFGPost_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		p       func() *model.LFGPost
+		isValid bool
+	}{
+		{
+			name: "valid",
+			p: func() *model.LFGPost {
+				return model.TestLFGPost(t, 1, 1)
+			},
+			isValid: true,
+		},
+		{
+			name: "skill range",
+			p: func() *model.LFGPost {
+				p := model.TestLFGPost(t, 1, 1)
+				p.SkillMin = 1400
+				p.SkillMax = 1600
+
+				return p
+			},
+			isValid: true,
+		},
+		{
+			name: "inverted skill range",
+			p: func() *model.LFGPost {
+				p := model.TestLFGPost(t, 1, 1)
+				p.SkillMin = 1600
+				p.SkillMax = 1400
+
+				return p
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown platform",
+			p: func() *model.LFGPost {
+				p := model.TestLFGPost(t, 1, 1)
+				p.Platform = "dreamcast"
+
+				return p
+			},
+			isValid: false,
+		},
+		{
+			name: "invalid language",
+			p: func() *model.LFGPost {
+				p := model.TestLFGPost(t, 1, 1)
+				p.Language = "english"
+
+				return p
+			},
+			isValid: false,
+		},
+		{
+			name: "no players",
+			p: func() *model.LFGPost {
+				p := model.TestLFGPost(t, 1, 1)
+				p.Players = 0
+
+				return p
+			},
+			isValid: false,
+		},
+		{
+			name: "expires before start",
+			p: func() *model.LFGPost {
+				p := model.TestLFGPost(t, 1, 1)
+				p.ExpiresAt = p.StartsAt.Add(-time.Minute)
+
+				return p
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.p().Validate())
+			} else {
+				assert.Error(t, tc.p().Validate())
+			}
+		})
+	}
+}
+
+func TestLFGPost_CanRequest(t *testing.T) {
+	p := model.TestLFGPost(t, 1, 1)
+	p.SkillMin = 1400
+	p.SkillMax = 1600
+	p.BeforeCreate()
+	now := time.Now()
+
+	assert.Equal(t, p.StartsAt.Add(model.LFGExpiryDelay), p.ExpiresAt)
+	assert.NoError(t, p.CanRequest(2, now))
+	assert.EqualError(t, p.CanRequest(1, now), model.ErrOwnLFGPost.Error())
+	assert.EqualError(t, p.CanRequest(2, p.ExpiresAt), model.ErrLFGPostClosed.Error())
+	assert.True(t, p.InSkillRange(1500))
+	assert.False(t, p.InSkillRange(1700))
+
+	p.SkillMax = 0
+	assert.True(t, p.InSkillRange(1700))
+	assert.False(t, p.InSkillRange(1300))
+
+	p.Status = model.LFGClosed
+	assert.EqualError(t, p.CanRequest(2, now), model.ErrLFGPostClosed.Error())
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Library entry statuses
+const (
+	LibraryStatusOwned    = "owned"
+	LibraryStatusPlaying  = "playing"
+	LibraryStatusFinished = "finished"
+	LibraryStatusWishlist = "wishlist"
+)
+
+// Library visibility settings
+const (
+	LibraryVisibilityPublic  = "public"
+	LibraryVisibilityFriends = "friends"
+	LibraryVisibilityPrivate = "private"
+)
+
+// LibraryEntry object that describes a game in user's library
+type LibraryEntry struct {
+	UserID      int       `json:"user_id"`
+	GameID      int       `json:"game_id"`
+	Status      string    `json:"status"`
+	HoursPlayed float64   `json:"hours_played"`
+	AddedAt     time.Time `json:"added_at"`
+	Notes       string    `json:"notes"`
+}
+
+// Validate func. Validating library entry instance for game id, status,
+// hours played and notes
+func (e *LibraryEntry) Validate() error {
+	return validation.ValidateStruct(
+		e,
+		validation.Field(&e.GameID, validation.Required, validation.Min(1)),
+		validation.Field(&e.Status, validation.Required, validation.In(
+			LibraryStatusOwned,
+			LibraryStatusPlaying,
+			LibraryStatusFinished,
+			LibraryStatusWishlist,
+		)),
+		validation.Field(&e.HoursPlayed, validation.Min(0.0)),
+		validation.Field(&e.Notes, validation.Length(0, 2000)),
+	)
+}
+
+// BeforeCreate func. Sets the date the entry was added at
+// if it wasn't set before.
+func (e *LibraryEntry) BeforeCreate() {
+	if e.AddedAt.IsZero() {
+		e.AddedAt = time.Now().UTC()
+	}
+}
+
+// ValidateLibraryVisibility func. Checks that visibility is
+// one of the known library visibility settings
+func ValidateLibraryVisibility(visibility string) error {
+	return validation.Validate(
+		visibility,
+		validation.Required,
+		validation.In(LibraryVisibilityPublic, LibraryVisibilityFriends, LibraryVisibilityPrivate),
+	)
+}
@@ -0,0 +1,69 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+func TestLibraryEntry_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		e       func() *model.LibraryEntry
+		isValid bool
+	}{
+		{
+			name: "valid",
+			e: func() *model.LibraryEntry {
+				return model.TestLibraryEntry(t, 1, 1)
+			},
+			isValid: true,
+		},
+		{
+			name: "empty game",
+			e: func() *model.LibraryEntry {
+				return model.TestLibraryEntry(t, 1, 0)
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown status",
+			e: func() *model.LibraryEntry {
+				e := model.TestLibraryEntry(t, 1, 1)
+				e.Status = "borrowed"
+
+				return e
+			},
+			isValid: false,
+		},
+		{
+			name: "negative hours played",
+			e: func() *model.LibraryEntry {
+				e := model.TestLibraryEntry(t, 1, 1)
+				e.HoursPlayed = -1
+
+				return e
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.e().Validate())
+			} else {
+				assert.Error(t, tc.e().Validate())
+			}
+		})
+	}
+}
+
+func TestValidateLibraryVisibility(t *testing.T) {
+	assert.NoError(t, model.ValidateLibraryVisibility(model.LibraryVisibilityPublic))
+	assert.NoError(t, model.ValidateLibraryVisibility(model.LibraryVisibilityFriends))
+	assert.NoError(t, model.ValidateLibraryVisibility(model.LibraryVisibilityPrivate))
+	assert.Error(t, model.ValidateLibraryVisibility("secret"))
+}
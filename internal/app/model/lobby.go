@@ -0,0 +1,115 @@
+package model
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Lobby visibilities
+const (
+	LobbyPublic  = "public"
+	LobbyPrivate = "private"
+)
+
+// Lobby states
+const (
+	LobbyOpen    = "open"
+	LobbyStarted = "started"
+)
+
+// Lobby size bounds
+const (
+	LobbyMinPlayers = 2
+	LobbyMaxPlayers = 64
+)
+
+// Lobby object that describes a group of players gathering to play a
+// game. The first member is the host.
+type Lobby struct {
+	ID                int            `json:"id"`
+	GameID            int            `json:"game_id"`
+	HostID            int            `json:"host_id"`
+	MaxPlayers        int            `json:"max_players"`
+	Visibility        string         `json:"visibility"`
+	Password          string         `json:"password,omitempty"`
+	EncryptedPassword string         `json:"-"`
+	HasPassword       bool           `json:"has_password"`
+	State             string         `json:"state"`
+	Members           []*LobbyMember `json:"members"`
+	CreatedAt         time.Time      `json:"created_at"`
+	StartedAt         *time.Time     `json:"started_at,omitempty"`
+}
+
+// LobbyMember object that describes a player in the lobby
+type LobbyMember struct {
+	UserID   int       `json:"user_id"`
+	Ready    bool      `json:"ready"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// Validate func. Validating lobby instance for game id, size,
+// visibility and password
+func (l *Lobby) Validate() error {
+	return validation.ValidateStruct(
+		l,
+		validation.Field(&l.GameID, validation.Required, validation.Min(1)),
+		validation.Field(&l.MaxPlayers, validation.Required, validation.Min(LobbyMinPlayers), validation.Max(LobbyMaxPlayers)),
+		validation.Field(&l.Visibility, validation.Required, validation.In(LobbyPublic, LobbyPrivate)),
+		validation.Field(&l.Password, validation.Length(0, 100)),
+	)
+}
+
+// BeforeCreate func. Encrypts lobby password and sets initial state
+// of the lobby.
+func (l *Lobby) BeforeCreate() error {
+	if len(l.Password) > 0 {
+		enc, err := encryptString(l.Password)
+		if err != nil {
+			return err
+		}
+
+		l.EncryptedPassword = enc
+		l.HasPassword = true
+	}
+
+	l.Password = ""
+	l.State = LobbyOpen
+	l.CreatedAt = time.Now().UTC()
+
+	return nil
+}
+
+// ComparePassword func. Compares password with the lobby password.
+// Lobbies without password accept any password.
+func (l *Lobby) ComparePassword(password string) bool {
+	if !l.HasPassword {
+		return true
+	}
+
+	return compareEncryptedString(l.EncryptedPassword, password)
+}
+
+// Member func. Returns the lobby member with user id
+func (l *Lobby) Member(userID int) *LobbyMember {
+	for _, m := range l.Members {
+		if m.UserID == userID {
+			return m
+		}
+	}
+
+	return nil
+}
+
+// Copy func. Returns deep copy of the lobby, which is safe to share
+// while the original keeps changing.
+func (l *Lobby) Copy() *Lobby {
+	c := *l
+	c.Members = make([]*LobbyMember, len(l.Members))
+	for i, m := range l.Members {
+		mc := *m
+		c.Members[i] = &mc
+	}
+
+	return &c
+}
@@ -0,0 +1,74 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+func TestLobby_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		l       func() *model.Lobby
+		isValid bool
+	}{
+		{
+			name: "valid",
+			l: func() *model.Lobby {
+				return model.TestLobby(t, 1)
+			},
+			isValid: true,
+		},
+		{
+			name: "empty game",
+			l: func() *model.Lobby {
+				return model.TestLobby(t, 0)
+			},
+			isValid: false,
+		},
+		{
+			name: "too small",
+			l: func() *model.Lobby {
+				l := model.TestLobby(t, 1)
+				l.MaxPlayers = 1
+
+				return l
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown visibility",
+			l: func() *model.Lobby {
+				l := model.TestLobby(t, 1)
+				l.Visibility = "hidden"
+
+				return l
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.l().Validate())
+			} else {
+				assert.Error(t, tc.l().Validate())
+			}
+		})
+	}
+}
+
+func TestLobby_ComparePassword(t *testing.T) {
+	l := model.TestLobby(t, 1)
+	assert.NoError(t, l.BeforeCreate())
+	assert.True(t, l.ComparePassword("anything"))
+
+	l.Password = "secret"
+	assert.NoError(t, l.BeforeCreate())
+	assert.Empty(t, l.Password)
+	assert.True(t, l.ComparePassword("secret"))
+	assert.False(t, l.ComparePassword("wrong"))
+}
@@ -0,0 +1,203 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Match statuses
+const (
+	MatchPending   = "pending"
+	MatchConfirmed = "confirmed"
+	MatchDisputed  = "disputed"
+	MatchResolved  = "resolved"
+)
+
+// Match outcomes
+const (
+	MatchWinnerA = "a"
+	MatchWinnerB = "b"
+	MatchDraw    = "draw"
+)
+
+var (
+	// ErrInvalidMatchTransition error tells us, that the action can't be
+	// applied to the match by the user in its current status
+	ErrInvalidMatchTransition = errors.New("invalid match transition")
+	errDuplicatePlayer        = errors.New("player can't appear twice")
+	errReporterNotPlayer      = errors.New("reporter has to be a player")
+)
+
+// Match object that describes reported result of a match between two
+// sides. The result counts for ratings once the other side confirms it
+// or a moderator resolves a dispute.
+type Match struct {
+	ID         int        `json:"id"`
+	GameID     int        `json:"game_id"`
+	LobbyID    int        `json:"lobby_id,omitempty"`
+	SideA      []int      `json:"side_a"`
+	SideB      []int      `json:"side_b"`
+	Winner     string     `json:"winner"`
+	Status     string     `json:"status"`
+	ReportedBy int        `json:"reported_by"`
+	Season     string     `json:"season"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RatedAt    *time.Time `json:"rated_at,omitempty"`
+}
+
+// Validate func. Validating match instance for game id, sides, winner
+// and reporter
+func (m *Match) Validate() error {
+	return validation.ValidateStruct(
+		m,
+		validation.Field(&m.GameID, validation.Required, validation.Min(1)),
+		validation.Field(&m.SideA, validation.Required, validation.By(m.uniquePlayers)),
+		validation.Field(&m.SideB, validation.Required),
+		validation.Field(&m.Winner, validation.Required, validation.In(MatchWinnerA, MatchWinnerB, MatchDraw)),
+		validation.Field(&m.ReportedBy, validation.By(m.reporterPlays)),
+	)
+}
+
+// BeforeCreate func. Sets initial status, creation date and season
+// of the match
+func (m *Match) BeforeCreate() {
+	m.Status = MatchPending
+	m.CreatedAt = time.Now().UTC()
+	m.Season = SeasonOf(m.CreatedAt)
+	m.RatedAt = nil
+}
+
+// Side func. Returns the side the user plays for, or empty string if
+// the user doesn't play in the match
+func (m *Match) Side(userID int) string {
+	for _, id := range m.SideA {
+		if id == userID {
+			return MatchWinnerA
+		}
+	}
+
+	for _, id := range m.SideB {
+		if id == userID {
+			return MatchWinnerB
+		}
+	}
+
+	return ""
+}
+
+// Players func. Returns ids of players of both sides
+func (m *Match) Players() []int {
+	return append(append([]int{}, m.SideA...), m.SideB...)
+}
+
+// Confirm func. The other side than the reporter's one confirms the result
+func (m *Match) Confirm(userID int) error {
+	if !m.opponentOfReporter(userID) {
+		return ErrInvalidMatchTransition
+	}
+
+	m.Status = MatchConfirmed
+
+	return nil
+}
+
+// Dispute func. The other side than the reporter's one disputes the result
+func (m *Match) Dispute(userID int) error {
+	if !m.opponentOfReporter(userID) {
+		return ErrInvalidMatchTransition
+	}
+
+	m.Status = MatchDisputed
+
+	return nil
+}
+
+// Resolve func. A moderator decides the winner of the unsettled match
+func (m *Match) Resolve(winner string) error {
+	if m.Final() {
+		return ErrInvalidMatchTransition
+	}
+
+	if err := validation.Validate(winner, validation.Required, validation.In(MatchWinnerA, MatchWinnerB, MatchDraw)); err != nil {
+		return err
+	}
+
+	m.Winner = winner
+	m.Status = MatchResolved
+
+	return nil
+}
+
+// Final func. Checks if the result of the match counts for ratings
+func (m *Match) Final() bool {
+	return m.Status == MatchConfirmed || m.Status == MatchResolved
+}
+
+// ScoreA func. Returns score of side a in Glicko terms
+func (m *Match) ScoreA() float64 {
+	switch m.Winner {
+	case MatchWinnerA:
+		return 1
+	case MatchWinnerB:
+		return 0
+	}
+
+	return 0.5
+}
+
+// opponentOfReporter func. Checks that the pending match can be
+// confirmed or disputed by the user
+func (m *Match) opponentOfReporter(userID int) bool {
+	side := m.Side(userID)
+
+	return m.Status == MatchPending && side != "" && side != m.Side(m.ReportedBy)
+}
+
+// uniquePlayers func. Validation rule that checks that no player
+// appears twice in the match
+func (m *Match) uniquePlayers(interface{}) error {
+	seen := map[int]bool{}
+	for _, id := range m.Players() {
+		if seen[id] {
+			return errDuplicatePlayer
+		}
+		seen[id] = true
+	}
+
+	return nil
+}
+
+// reporterPlays func. Validation rule that checks that the reporter
+// plays in the match
+func (m *Match) reporterPlays(interface{}) error {
+	if m.Side(m.ReportedBy) == "" {
+		return errReporterNotPlayer
+	}
+
+	return nil
+}
+
+// ValidMatchTransition func. Checks that the stored match can move
+// from one status to another. Stores use it to reject updates made
+// with a stale copy of the match.
+func ValidMatchTransition(from, to string) bool {
+	switch from {
+	case MatchPending:
+		return to == MatchConfirmed || to == MatchDisputed || to == MatchResolved
+	case MatchDisputed:
+		return to == MatchResolved
+	}
+
+	return false
+}
+
+// SeasonOf func. Returns the rating season of the date. Seasons are
+// calendar quarters, e.g. 2020-Q4.
+func SeasonOf(t time.Time) string {
+	t = t.UTC()
+
+	return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1)
+}
@@ -0,0 +1,122 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+func TestMatch_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		m       func() *model.Match
+		isValid bool
+	}{
+		{
+			name: "valid",
+			m: func() *model.Match {
+				return model.TestMatch(t, 1, 1, 2)
+			},
+			isValid: true,
+		},
+		{
+			name: "empty game",
+			m: func() *model.Match {
+				return model.TestMatch(t, 0, 1, 2)
+			},
+			isValid: false,
+		},
+		{
+			name: "empty side",
+			m: func() *model.Match {
+				m := model.TestMatch(t, 1, 1, 2)
+				m.SideB = nil
+
+				return m
+			},
+			isValid: false,
+		},
+		{
+			name: "player on both sides",
+			m: func() *model.Match {
+				return model.TestMatch(t, 1, 1, 1)
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown winner",
+			m: func() *model.Match {
+				m := model.TestMatch(t, 1, 1, 2)
+				m.Winner = "c"
+
+				return m
+			},
+			isValid: false,
+		},
+		{
+			name: "reporter doesn't play",
+			m: func() *model.Match {
+				m := model.TestMatch(t, 1, 1, 2)
+				m.ReportedBy = 3
+
+				return m
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.m().Validate())
+			} else {
+				assert.Error(t, tc.m().Validate())
+			}
+		})
+	}
+}
+
+func TestMatch_Confirm(t *testing.T) {
+	m := model.TestMatch(t, 1, 1, 2)
+	m.BeforeCreate()
+
+	assert.EqualError(t, m.Confirm(1), model.ErrInvalidMatchTransition.Error())
+	assert.EqualError(t, m.Confirm(3), model.ErrInvalidMatchTransition.Error())
+	assert.NoError(t, m.Confirm(2))
+	assert.True(t, m.Final())
+	assert.EqualError(t, m.Dispute(2), model.ErrInvalidMatchTransition.Error())
+}
+
+func TestMatch_Resolve(t *testing.T) {
+	m := model.TestMatch(t, 1, 1, 2)
+	m.BeforeCreate()
+
+	assert.NoError(t, m.Dispute(2))
+	assert.False(t, m.Final())
+	assert.Error(t, m.Resolve("c"))
+	assert.NoError(t, m.Resolve(model.MatchWinnerB))
+	assert.Equal(t, model.MatchResolved, m.Status)
+	assert.Equal(t, 0.0, m.ScoreA())
+	assert.EqualError(t, m.Resolve(model.MatchDraw), model.ErrInvalidMatchTransition.Error())
+}
+
+func TestSeasonOf(t *testing.T) {
+	assert.Equal(t, "2020-Q1", model.SeasonOf(time.Date(2020, time.March, 31, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2020-Q4", model.SeasonOf(time.Date(2020, time.October, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestRateMatch(t *testing.T) {
+	m := model.TestMatch(t, 1, 1, 2)
+	ratings := []*model.Rating{
+		model.NewRating(1, 1, "2020-Q4"),
+		model.NewRating(2, 1, "2020-Q4"),
+	}
+
+	model.RateMatch(m, ratings)
+	assert.Greater(t, ratings[0].Rating, ratings[1].Rating)
+	assert.Equal(t, 1, ratings[0].Matches)
+	assert.Equal(t, 1, ratings[1].Matches)
+}
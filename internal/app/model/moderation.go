@@ -0,0 +1,214 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Report target types, i.e. what can be reported to moderators
+const (
+	ReportTargetUser    = "user"
+	ReportTargetMessage = "message"
+	ReportTargetReview  = "review"
+	ReportTargetGuild   = "guild"
+)
+
+// Report statuses. Open reports wait in the moderator queue.
+const (
+	ReportOpen      = "open"
+	ReportResolved  = "resolved"
+	ReportDismissed = "dismissed"
+)
+
+// Sanction kinds. Warnings are only recorded, muted users can't post to
+// chat, suspended and banned users can't use their accounts. Bans may be
+// permanent.
+const (
+	SanctionWarn    = "warn"
+	SanctionMute    = "mute"
+	SanctionSuspend = "suspend"
+	SanctionBan     = "ban"
+)
+
+// Moderation actions taken on reports besides sanctions
+const (
+	ModerationDismiss       = "dismiss"
+	ModerationRemoveContent = "remove_content"
+)
+
+var (
+	// ErrReportResolved error tells us, that the report isn't open
+	// anymore, so no other action can be taken on it
+	ErrReportResolved = errors.New("report is already resolved")
+
+	// ErrReportNoContent error tells us, that the reported target has
+	// no content which can be removed, e.g. it's a user
+	ErrReportNoContent = errors.New("reported target has no content to remove")
+)
+
+// Report object that describes a report of a user, a chat message, a
+// review or a guild sent to moderators. TargetUserID is the reported user
+// or the author of the reported content, Excerpt is the reported content
+// at the time of the report, so moderators can see it after it changes.
+// Zero ReporterID means the content was flagged by the text filter.
+type Report struct {
+	ID           int        `json:"id"`
+	ReporterID   int        `json:"reporter_id,omitempty"`
+	TargetType   string     `json:"target_type"`
+	TargetID     int        `json:"target_id"`
+	TargetUserID int        `json:"target_user_id"`
+	Reason       string     `json:"reason"`
+	Excerpt      string     `json:"excerpt,omitempty"`
+	Status       string     `json:"status"`
+	Action       string     `json:"action,omitempty"`
+	Resolution   string     `json:"resolution,omitempty"`
+	ModeratorID  int        `json:"moderator_id,omitempty"`
+	ResolvedAt   *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// Sanction object that describes a sanction a moderator imposed on the
+// user. Nil ExpiresAt means the sanction never expires, zero ReportID
+// means it wasn't imposed on a report.
+type Sanction struct {
+	ID          int        `json:"id"`
+	UserID      int        `json:"user_id"`
+	Kind        string     `json:"kind"`
+	Reason      string     `json:"reason"`
+	ModeratorID int        `json:"moderator_id"`
+	ReportID    int        `json:"report_id,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Validate func. Validating report instance for target and reason
+func (r *Report) Validate() error {
+	return validation.ValidateStruct(
+		r,
+		validation.Field(&r.TargetType, validation.Required, validation.In(
+			ReportTargetUser,
+			ReportTargetMessage,
+			ReportTargetReview,
+			ReportTargetGuild,
+		)),
+		validation.Field(&r.TargetID, validation.Required),
+		validation.Field(&r.TargetUserID, validation.Required),
+		validation.Field(&r.Reason, validation.Required, validation.Length(1, 500)),
+	)
+}
+
+// BeforeCreate func. Opens the report and sets the date it was sent at
+func (r *Report) BeforeCreate() {
+	r.Status = ReportOpen
+	r.Action = ""
+	r.Resolution = ""
+	r.ModeratorID = 0
+	r.ResolvedAt = nil
+	r.CreatedAt = time.Now().UTC()
+}
+
+// Resolve func. Resolves the open report with the action taken by the
+// moderator for the reason at the time. Reports are dismissed without
+// any action, the action is either removal of the reported content or
+// a sanction on the reported user.
+func (r *Report) Resolve(action, reason string, moderatorID int, now time.Time) error {
+	if r.Status != ReportOpen {
+		return ErrReportResolved
+	}
+
+	if err := (validation.Errors{
+		"action": validation.Validate(action, validation.Required, validation.In(
+			ModerationDismiss,
+			ModerationRemoveContent,
+			SanctionWarn,
+			SanctionMute,
+			SanctionSuspend,
+			SanctionBan,
+		)),
+		"reason": validation.Validate(reason, validation.Required, validation.Length(1, 500)),
+	}).Filter(); err != nil {
+		return err
+	}
+
+	if action == ModerationRemoveContent && r.TargetType == ReportTargetUser {
+		return ErrReportNoContent
+	}
+
+	r.Status = ReportResolved
+	if action == ModerationDismiss {
+		r.Status = ReportDismissed
+	}
+	r.Action = action
+	r.Resolution = reason
+	r.ModeratorID = moderatorID
+	r.ResolvedAt = &now
+
+	return nil
+}
+
+// Validate func. Validating sanction instance for kind, reason and
+// expiry. Mutes and suspensions have to expire, warnings never expire
+// as they have no effect.
+func (s *Sanction) Validate() error {
+	return validation.ValidateStruct(
+		s,
+		validation.Field(&s.UserID, validation.Required),
+		validation.Field(&s.Kind, validation.Required, validation.In(
+			SanctionWarn,
+			SanctionMute,
+			SanctionSuspend,
+			SanctionBan,
+		)),
+		validation.Field(&s.Reason, validation.Required, validation.Length(1, 500)),
+		validation.Field(&s.ModeratorID, validation.Required),
+		validation.Field(
+			&s.ExpiresAt,
+			validation.When(s.Kind == SanctionWarn, validation.Nil),
+			validation.When(s.Kind == SanctionMute || s.Kind == SanctionSuspend, validation.NotNil),
+		),
+	)
+}
+
+// BeforeCreate func. Sets the date the sanction was imposed at
+func (s *Sanction) BeforeCreate() {
+	s.CreatedAt = time.Now().UTC()
+}
+
+// Active func. Checks if the sanction restricts the user at the time
+func (s *Sanction) Active(now time.Time) bool {
+	return s.Kind != SanctionWarn && (s.ExpiresAt == nil || s.ExpiresAt.After(now))
+}
+
+// ActiveSanction func. Returns the sanction of one of the kinds which
+// restricts the user the longest at the time, or nil if the user isn't
+// restricted by any of them.
+func ActiveSanction(sanctions []*Sanction, now time.Time, kinds ...string) *Sanction {
+	var longest *Sanction
+	for _, s := range sanctions {
+		if !s.Active(now) || !containsString(kinds, s.Kind) {
+			continue
+		}
+
+		switch {
+		case longest == nil, s.ExpiresAt == nil:
+			longest = s
+		case longest.ExpiresAt != nil && s.ExpiresAt.After(*longest.ExpiresAt):
+			longest = s
+		}
+	}
+
+	return longest
+}
+
+// containsString func. Checks if the value is one of the values
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
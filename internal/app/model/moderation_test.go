@@ -0,0 +1,198 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+func TestReport_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		r       func() *model.Report
+		isValid bool
+	}{
+		{
+			name: "valid",
+			r: func() *model.Report {
+				return model.TestReport(t, 1, 2)
+			},
+			isValid: true,
+		},
+		{
+			name: "flagged by text filter",
+			r: func() *model.Report {
+				r := model.TestReport(t, 0, 2)
+				r.TargetType = model.ReportTargetReview
+
+				return r
+			},
+			isValid: true,
+		},
+		{
+			name: "unknown target type",
+			r: func() *model.Report {
+				r := model.TestReport(t, 1, 2)
+				r.TargetType = "lobby"
+
+				return r
+			},
+			isValid: false,
+		},
+		{
+			name: "without target",
+			r: func() *model.Report {
+				r := model.TestReport(t, 1, 2)
+				r.TargetID = 0
+
+				return r
+			},
+			isValid: false,
+		},
+		{
+			name: "empty reason",
+			r: func() *model.Report {
+				r := model.TestReport(t, 1, 2)
+				r.Reason = ""
+
+				return r
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.r().Validate())
+			} else {
+				assert.Error(t, tc.r().Validate())
+			}
+		})
+	}
+}
+
+func TestReport_Resolve(t *testing.T) {
+	now := time.Now().UTC()
+	r := model.TestReport(t, 1, 2)
+	r.BeforeCreate()
+
+	assert.Error(t, r.Resolve("delete", "Rude", 3, now))
+	assert.Error(t, r.Resolve(model.SanctionWarn, "", 3, now))
+	assert.EqualError(t, r.Resolve(model.ModerationRemoveContent, "Rude", 3, now), model.ErrReportNoContent.Error())
+	assert.Equal(t, model.ReportOpen, r.Status)
+
+	assert.NoError(t, r.Resolve(model.SanctionWarn, "Rude", 3, now))
+	assert.Equal(t, model.ReportResolved, r.Status)
+	assert.Equal(t, model.SanctionWarn, r.Action)
+	assert.Equal(t, 3, r.ModeratorID)
+	assert.EqualError(t, r.Resolve(model.ModerationDismiss, "Fine", 3, now), model.ErrReportResolved.Error())
+
+	r = model.TestReport(t, 1, 2)
+	r.BeforeCreate()
+	assert.NoError(t, r.Resolve(model.ModerationDismiss, "Nothing wrong", 3, now))
+	assert.Equal(t, model.ReportDismissed, r.Status)
+}
+
+func TestSanction_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		s       func() *model.Sanction
+		isValid bool
+	}{
+		{
+			name: "valid",
+			s: func() *model.Sanction {
+				return model.TestSanction(t, 1, 2)
+			},
+			isValid: true,
+		},
+		{
+			name: "permanent ban",
+			s: func() *model.Sanction {
+				s := model.TestSanction(t, 1, 2)
+				s.Kind = model.SanctionBan
+				s.ExpiresAt = nil
+
+				return s
+			},
+			isValid: true,
+		},
+		{
+			name: "permanent suspension",
+			s: func() *model.Sanction {
+				s := model.TestSanction(t, 1, 2)
+				s.Kind = model.SanctionSuspend
+				s.ExpiresAt = nil
+
+				return s
+			},
+			isValid: false,
+		},
+		{
+			name: "expiring warning",
+			s: func() *model.Sanction {
+				s := model.TestSanction(t, 1, 2)
+				s.Kind = model.SanctionWarn
+
+				return s
+			},
+			isValid: false,
+		},
+		{
+			name: "empty reason",
+			s: func() *model.Sanction {
+				s := model.TestSanction(t, 1, 2)
+				s.Reason = ""
+
+				return s
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.s().Validate())
+			} else {
+				assert.Error(t, tc.s().Validate())
+			}
+		})
+	}
+}
+
+func TestActiveSanction(t *testing.T) {
+	now := time.Now().UTC()
+	sanction := func(kind string, expires time.Duration) *model.Sanction {
+		s := model.TestSanction(t, 1, 2)
+		s.Kind = kind
+		s.ExpiresAt = nil
+		if expires != 0 {
+			at := now.Add(expires)
+			s.ExpiresAt = &at
+		}
+
+		return s
+	}
+
+	warning := sanction(model.SanctionWarn, 0)
+	expired := sanction(model.SanctionSuspend, -time.Hour)
+	mute := sanction(model.SanctionMute, time.Hour)
+	suspension := sanction(model.SanctionSuspend, time.Hour)
+	longer := sanction(model.SanctionSuspend, 2*time.Hour)
+	ban := sanction(model.SanctionBan, 0)
+
+	sanctions := []*model.Sanction{warning, expired, mute, suspension}
+	assert.Nil(t, model.ActiveSanction(sanctions, now, model.SanctionWarn))
+	assert.Equal(t, mute, model.ActiveSanction(sanctions, now, model.SanctionMute))
+	assert.Equal(t, suspension, model.ActiveSanction(sanctions, now, model.SanctionSuspend, model.SanctionBan))
+	assert.Nil(t, model.ActiveSanction(sanctions, now.Add(time.Hour), model.SanctionSuspend))
+
+	sanctions = append(sanctions, ban, longer)
+	assert.Equal(t, ban, model.ActiveSanction(sanctions, now, model.SanctionSuspend, model.SanctionBan))
+	assert.Equal(t, longer, model.ActiveSanction(sanctions, now, model.SanctionSuspend))
+}
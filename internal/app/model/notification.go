@@ -0,0 +1,75 @@
+package model
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Notification types
+const (
+	NotificationFriendRequest   = "friend_request"
+	NotificationLobbyInvite     = "lobby_invite"
+	NotificationTournamentMatch = "tournament_match"
+	NotificationAchievement     = "achievement"
+)
+
+// NotificationTypes lists every notification type users can turn off
+var NotificationTypes = []string{
+	NotificationFriendRequest,
+	NotificationLobbyInvite,
+	NotificationTournamentMatch,
+	NotificationAchievement,
+}
+
+// Notification object that describes a notification of the user. Link
+// is the path of the API resource the notification is about.
+type Notification struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Type      string    `json:"type"`
+	Text      string    `json:"text"`
+	Link      string    `json:"link,omitempty"`
+	Read      bool      `json:"read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate func. Validating notification instance for type and text
+func (n *Notification) Validate() error {
+	return validation.ValidateStruct(
+		n,
+		validation.Field(&n.UserID, validation.Required),
+		validation.Field(&n.Type, validation.Required, validation.In(stringsToInterfaces(NotificationTypes)...)),
+		validation.Field(&n.Text, validation.Required, validation.Length(1, 500)),
+		validation.Field(&n.Link, validation.Length(0, 200)),
+	)
+}
+
+// BeforeCreate func. Marks the notification unread and sets the date
+// it was created at
+func (n *Notification) BeforeCreate() {
+	n.Read = false
+	n.CreatedAt = time.Now().UTC()
+}
+
+// ValidateNotificationPreferences func. Validating notification
+// preferences of the user, every key has to be a notification type
+func ValidateNotificationPreferences(prefs map[string]bool) error {
+	for t := range prefs {
+		if err := validation.Validate(t, validation.In(stringsToInterfaces(NotificationTypes)...)); err != nil {
+			return validation.Errors{t: err}
+		}
+	}
+
+	return nil
+}
+
+// stringsToInterfaces func. Converts strings for validation.In rule
+func stringsToInterfaces(values []string) []interface{} {
+	res := make([]interface{}, len(values))
+	for i, v := range values {
+		res[i] = v
+	}
+
+	return res
+}
@@ -0,0 +1,64 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+func TestNotification_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		n       func() *model.Notification
+		isValid bool
+	}{
+		{
+			name: "valid",
+			n: func() *model.Notification {
+				return model.TestNotification(t, 1)
+			},
+			isValid: true,
+		},
+		{
+			name: "unknown type",
+			n: func() *model.Notification {
+				n := model.TestNotification(t, 1)
+				n.Type = "newsletter"
+
+				return n
+			},
+			isValid: false,
+		},
+		{
+			name: "empty text",
+			n: func() *model.Notification {
+				n := model.TestNotification(t, 1)
+				n.Text = ""
+
+				return n
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.n().Validate())
+			} else {
+				assert.Error(t, tc.n().Validate())
+			}
+		})
+	}
+}
+
+func TestValidateNotificationPreferences(t *testing.T) {
+	assert.NoError(t, model.ValidateNotificationPreferences(map[string]bool{
+		model.NotificationAchievement: false,
+	}))
+	assert.Error(t, model.ValidateNotificationPreferences(map[string]bool{
+		"newsletter": false,
+	}))
+}
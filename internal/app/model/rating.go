@@ -0,0 +1,74 @@
+package model
+
+import (
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/rating"
+)
+
+// Rating object that describes Glicko-2 skill rating of the user in
+// the game during the season
+type Rating struct {
+	UserID     int       `json:"user_id"`
+	GameID     int       `json:"game_id"`
+	Season     string    `json:"season"`
+	Rating     float64   `json:"rating"`
+	RD         float64   `json:"rd"`
+	Volatility float64   `json:"volatility"`
+	Matches    int       `json:"matches"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// LeaderboardEntry object that describes a place in the leaderboard
+type LeaderboardEntry struct {
+	Rank int `json:"rank"`
+	*Rating
+}
+
+// NewRating func. Returns default rating of a user who hasn't played
+// the game in the season yet
+func NewRating(userID, gameID int, season string) *Rating {
+	p := rating.NewPlayer()
+
+	return &Rating{
+		UserID:     userID,
+		GameID:     gameID,
+		Season:     season,
+		Rating:     p.Rating,
+		RD:         p.RD,
+		Volatility: p.Volatility,
+	}
+}
+
+// Player func. Returns the rating in terms of the rating package
+func (r *Rating) Player() rating.Player {
+	return rating.Player{
+		Rating:     r.Rating,
+		RD:         r.RD,
+		Volatility: r.Volatility,
+	}
+}
+
+// RateMatch func. Updates ratings of both sides of the match. Ratings
+// have to be listed in order of match players, side a first.
+func RateMatch(m *Match, ratings []*Rating) {
+	a := make([]rating.Player, len(m.SideA))
+	b := make([]rating.Player, len(m.SideB))
+	for i, r := range ratings {
+		if i < len(a) {
+			a[i] = r.Player()
+		} else {
+			b[i-len(a)] = r.Player()
+		}
+	}
+
+	newA, newB := rating.UpdateTeams(a, b, m.ScoreA(), rating.DefaultTau)
+	now := time.Now().UTC()
+	for i, p := range append(newA, newB...) {
+		ratings[i].Rating = p.Rating
+		ratings[i].RD = p.RD
+		ratings[i].Volatility = p.Volatility
+		ratings[i].Matches++
+		ratings[i].UpdatedAt = now
+	}
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Review score bounds
+const (
+	ReviewScoreMin = 1
+	ReviewScoreMax = 10
+)
+
+// Review object that describes player's review of a game. Every
+// user can write only one review per game.
+type Review struct {
+	ID             int       `json:"id"`
+	UserID         int       `json:"user_id"`
+	GameID         int       `json:"game_id"`
+	Score          int       `json:"score"`
+	Text           string    `json:"text"`
+	HelpfulCount   int       `json:"helpful_count"`
+	UnhelpfulCount int       `json:"unhelpful_count"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ReviewVote object that describes user's helpful or unhelpful vote
+// for a review.
+type ReviewVote struct {
+	ReviewID int  `json:"review_id"`
+	UserID   int  `json:"user_id"`
+	Helpful  bool `json:"helpful"`
+}
+
+// Validate func. Validating review instance for game id, score and text
+func (r *Review) Validate() error {
+	return validation.ValidateStruct(
+		r,
+		validation.Field(&r.GameID, validation.Required, validation.Min(1)),
+		validation.Field(&r.Score, validation.Required, validation.Min(ReviewScoreMin), validation.Max(ReviewScoreMax)),
+		validation.Field(&r.Text, validation.Length(0, 5000)),
+	)
+}
+
+// BeforeCreate func. Sets creation and update dates of the review
+func (r *Review) BeforeCreate() {
+	r.CreatedAt = time.Now().UTC()
+	r.UpdatedAt = r.CreatedAt
+}
+
+// BeforeUpdate func. Sets update date of the review
+func (r *Review) BeforeUpdate() {
+	r.UpdatedAt = time.Now().UTC()
+}
@@ -0,0 +1,78 @@
+package model_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+func TestReview_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		r       func() *model.Review
+		isValid bool
+	}{
+		{
+			name: "valid",
+			r: func() *model.Review {
+				return model.TestReview(t, 1, 1)
+			},
+			isValid: true,
+		},
+		{
+			name: "empty game",
+			r: func() *model.Review {
+				return model.TestReview(t, 1, 0)
+			},
+			isValid: false,
+		},
+		{
+			name: "score too low",
+			r: func() *model.Review {
+				r := model.TestReview(t, 1, 1)
+				r.Score = 0
+
+				return r
+			},
+			isValid: false,
+		},
+		{
+			name: "score too high",
+			r: func() *model.Review {
+				r := model.TestReview(t, 1, 1)
+				r.Score = 11
+
+				return r
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.r().Validate())
+			} else {
+				assert.Error(t, tc.r().Validate())
+			}
+		})
+	}
+}
+
+func TestGame_AddRating(t *testing.T) {
+	g := model.TestGame(t)
+	g.BeforeCreate()
+	g.AddRating(10)
+	g.AddRating(5)
+	assert.Equal(t, 2, g.RatingCount)
+	assert.Equal(t, 7.5, g.RatingMean)
+	assert.Equal(t, 1, g.RatingHistogram[9])
+	assert.Equal(t, 1, g.RatingHistogram[4])
+
+	g.RemoveRating(10)
+	assert.Equal(t, 1, g.RatingCount)
+	assert.Equal(t, 5.0, g.RatingMean)
+	assert.Equal(t, 0, g.RatingHistogram[9])
+}
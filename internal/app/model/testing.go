@@ -1,6 +1,11 @@
 package model
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/tournament"
+)
 
 // TestUser object for testing
 func TestUser(t *testing.T) *User {
@@ -9,3 +14,157 @@ func TestUser(t *testing.T) *User {
 		Password: "password",
 	}
 }
+
+// TestGame object for testing
+func TestGame(t *testing.T) *Game {
+	return &Game{
+		Title: "Gloomhaven",
+	}
+}
+
+// TestLibraryEntry object for testing
+func TestLibraryEntry(t *testing.T, userID, gameID int) *LibraryEntry {
+	return &LibraryEntry{
+		UserID: userID,
+		GameID: gameID,
+		Status: LibraryStatusOwned,
+	}
+}
+
+// TestReview object for testing
+func TestReview(t *testing.T, userID, gameID int) *Review {
+	return &Review{
+		UserID: userID,
+		GameID: gameID,
+		Score:  8,
+		Text:   "Great game for a long evening",
+	}
+}
+
+// TestChatMessage object for testing
+func TestChatMessage(t *testing.T, room string, userID int) *ChatMessage {
+	return &ChatMessage{
+		Room:   room,
+		UserID: userID,
+		Text:   "Anyone up for a round?",
+	}
+}
+
+// TestLobby object for testing
+func TestLobby(t *testing.T, gameID int) *Lobby {
+	return &Lobby{
+		GameID:     gameID,
+		MaxPlayers: 4,
+		Visibility: LobbyPublic,
+	}
+}
+
+// TestMatch object for testing
+func TestMatch(t *testing.T, gameID, userID, opponentID int) *Match {
+	return &Match{
+		GameID:     gameID,
+		SideA:      []int{userID},
+		SideB:      []int{opponentID},
+		Winner:     MatchWinnerA,
+		ReportedBy: userID,
+	}
+}
+
+// TestTournament object for testing
+func TestTournament(t *testing.T, gameID, organizerID int) *Tournament {
+	now := time.Now().UTC()
+
+	return &Tournament{
+		GameID:               gameID,
+		OrganizerID:          organizerID,
+		Name:                 "Weekly cup",
+		Format:               tournament.SingleElimination,
+		Seeding:              tournament.SeedByRating,
+		MaxPlayers:           8,
+		RegistrationOpensAt:  now.Add(-time.Hour),
+		RegistrationClosesAt: now.Add(time.Hour),
+	}
+}
+
+// TestGuild object for testing
+func TestGuild(t *testing.T) *Guild {
+	return &Guild{
+		Name:        "Knights of the Round Table",
+		Tag:         "KRT",
+		Description: "We play on Fridays",
+		EmblemURL:   "https://example.org/emblem.png",
+	}
+}
+
+// TestLFGPost object for testing
+func TestLFGPost(t *testing.T, userID, gameID int) *LFGPost {
+	return &LFGPost{
+		UserID:   userID,
+		GameID:   gameID,
+		Platform: PlatformPC,
+		Players:  2,
+		Language: "en",
+		StartsAt: time.Now().UTC().Add(time.Hour),
+	}
+}
+
+// TestEvent object for testing
+func TestEvent(t *testing.T, hostID, gameID int) *Event {
+	starts := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Second)
+
+	return &Event{
+		HostID:     hostID,
+		GameID:     gameID,
+		Title:      "Board game night",
+		StartsAt:   starts,
+		EndsAt:     starts.Add(3 * time.Hour),
+		Timezone:   "Europe/Berlin",
+		Capacity:   4,
+		Recurrence: EventWeekly,
+	}
+}
+
+// TestNotification object for testing
+func TestNotification(t *testing.T, userID int) *Notification {
+	return &Notification{
+		UserID: userID,
+		Type:   NotificationFriendRequest,
+		Text:   "You have a new friend request",
+		Link:   "/private/friends/requests",
+	}
+}
+
+// TestActivity object for testing
+func TestActivity(t *testing.T, userID, gameID int) *Activity {
+	return &Activity{
+		UserID: userID,
+		Type:   ActivityReviewed,
+		GameID: gameID,
+		Text:   "reviewed Chess",
+		Link:   "/games/1/reviews",
+	}
+}
+
+// TestReport object for testing
+func TestReport(t *testing.T, reporterID, userID int) *Report {
+	return &Report{
+		ReporterID:   reporterID,
+		TargetType:   ReportTargetUser,
+		TargetID:     userID,
+		TargetUserID: userID,
+		Reason:       "Insults other players",
+	}
+}
+
+// TestSanction object for testing
+func TestSanction(t *testing.T, userID, moderatorID int) *Sanction {
+	expires := time.Now().UTC().Add(24 * time.Hour)
+
+	return &Sanction{
+		UserID:      userID,
+		Kind:        SanctionMute,
+		Reason:      "Spamming the hall",
+		ModeratorID: moderatorID,
+		ExpiresAt:   &expires,
+	}
+}
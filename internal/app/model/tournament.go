@@ -0,0 +1,148 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/tournament"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Tournament statuses
+const (
+	TournamentRegistration = "registration"
+	TournamentRunning      = "running"
+	TournamentFinished     = "finished"
+)
+
+// Tournament size bounds
+const (
+	TournamentMinPlayers = 2
+	TournamentMaxPlayers = 256
+)
+
+var (
+	// ErrRegistrationClosed error tells us, that the tournament doesn't
+	// accept registrations now
+	ErrRegistrationClosed = errors.New("tournament registration is closed")
+	// ErrTournamentFull error tells us, that the tournament has no free places
+	ErrTournamentFull = errors.New("tournament is full")
+	// ErrTournamentNotRunning error tells us, that the tournament bracket
+	// can't be changed in its current status
+	ErrTournamentNotRunning = errors.New("tournament isn't running")
+	errRegistrationWindow   = errors.New("registration has to close after it opens")
+)
+
+// Tournament object that describes a tournament of the game. Players
+// register between RegistrationOpensAt and RegistrationClosesAt, then
+// the organizer starts the tournament and the bracket is created.
+type Tournament struct {
+	ID                   int                 `json:"id"`
+	GameID               int                 `json:"game_id"`
+	OrganizerID          int                 `json:"organizer_id"`
+	Name                 string              `json:"name"`
+	Format               string              `json:"format"`
+	Seeding              string              `json:"seeding"`
+	MaxPlayers           int                 `json:"max_players"`
+	Rounds               int                 `json:"rounds,omitempty"`
+	RegistrationOpensAt  time.Time           `json:"registration_opens_at"`
+	RegistrationClosesAt time.Time           `json:"registration_closes_at"`
+	Status               string              `json:"status"`
+	Bracket              *tournament.Bracket `json:"-"`
+	CreatedAt            time.Time           `json:"created_at"`
+}
+
+// TournamentParticipant object that describes a player registered for
+// the tournament
+type TournamentParticipant struct {
+	TournamentID int       `json:"tournament_id"`
+	UserID       int       `json:"user_id"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// Validate func. Validating tournament instance for game id, name,
+// format, seeding, size and registration window
+func (t *Tournament) Validate() error {
+	return validation.ValidateStruct(
+		t,
+		validation.Field(&t.GameID, validation.Required, validation.Min(1)),
+		validation.Field(&t.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&t.Format, validation.Required, validation.In(
+			tournament.SingleElimination,
+			tournament.DoubleElimination,
+			tournament.RoundRobin,
+			tournament.Swiss,
+		)),
+		validation.Field(&t.Seeding, validation.Required, validation.In(tournament.SeedByRating, tournament.SeedRandom)),
+		validation.Field(&t.MaxPlayers, validation.Required, validation.Min(TournamentMinPlayers), validation.Max(TournamentMaxPlayers)),
+		validation.Field(&t.Rounds, validation.Min(0), validation.Max(t.MaxPlayers-1)),
+		validation.Field(&t.RegistrationOpensAt, validation.Required),
+		validation.Field(&t.RegistrationClosesAt, validation.Required, validation.By(t.closesAfterOpening)),
+	)
+}
+
+// BeforeCreate func. Sets initial status and creation date of the tournament
+func (t *Tournament) BeforeCreate() {
+	t.Status = TournamentRegistration
+	t.Bracket = nil
+	t.CreatedAt = time.Now().UTC()
+}
+
+// CanRegister func. Checks that one more player can register for the
+// tournament with the imported number of participants at the moment
+func (t *Tournament) CanRegister(now time.Time, participants int) error {
+	if t.Status != TournamentRegistration || now.Before(t.RegistrationOpensAt) || !now.Before(t.RegistrationClosesAt) {
+		return ErrRegistrationClosed
+	}
+
+	if participants >= t.MaxPlayers {
+		return ErrTournamentFull
+	}
+
+	return nil
+}
+
+// Start func. Creates the bracket for players in seed order and closes
+// the registration
+func (t *Tournament) Start(players []int) error {
+	if t.Status != TournamentRegistration {
+		return ErrTournamentNotRunning
+	}
+
+	b, err := tournament.New(t.Format, players, t.Rounds)
+	if err != nil {
+		return err
+	}
+
+	t.Bracket = b
+	t.Status = TournamentRunning
+
+	return nil
+}
+
+// Advance func. Applies the change to the bracket of the running
+// tournament and finishes the tournament once the bracket is finished
+func (t *Tournament) Advance(change func(*tournament.Bracket) error) error {
+	if t.Status != TournamentRunning {
+		return ErrTournamentNotRunning
+	}
+
+	if err := change(t.Bracket); err != nil {
+		return err
+	}
+
+	if t.Bracket.Finished() {
+		t.Status = TournamentFinished
+	}
+
+	return nil
+}
+
+// closesAfterOpening func. Validation rule that checks the registration window
+func (t *Tournament) closesAfterOpening(interface{}) error {
+	if !t.RegistrationClosesAt.After(t.RegistrationOpensAt) {
+		return errRegistrationWindow
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/tournament"
+)
+
+func TestTournament_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		tr      func() *model.Tournament
+		isValid bool
+	}{
+		{
+			name: "valid",
+			tr: func() *model.Tournament {
+				return model.TestTournament(t, 1, 1)
+			},
+			isValid: true,
+		},
+		{
+			name: "empty name",
+			tr: func() *model.Tournament {
+				tr := model.TestTournament(t, 1, 1)
+				tr.Name = ""
+
+				return tr
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown format",
+			tr: func() *model.Tournament {
+				tr := model.TestTournament(t, 1, 1)
+				tr.Format = "ladder"
+
+				return tr
+			},
+			isValid: false,
+		},
+		{
+			name: "unknown seeding",
+			tr: func() *model.Tournament {
+				tr := model.TestTournament(t, 1, 1)
+				tr.Seeding = "alphabetical"
+
+				return tr
+			},
+			isValid: false,
+		},
+		{
+			name: "too small",
+			tr: func() *model.Tournament {
+				tr := model.TestTournament(t, 1, 1)
+				tr.MaxPlayers = 1
+
+				return tr
+			},
+			isValid: false,
+		},
+		{
+			name: "too many swiss rounds",
+			tr: func() *model.Tournament {
+				tr := model.TestTournament(t, 1, 1)
+				tr.Format = tournament.Swiss
+				tr.Rounds = tr.MaxPlayers
+
+				return tr
+			},
+			isValid: false,
+		},
+		{
+			name: "registration closes before opening",
+			tr: func() *model.Tournament {
+				tr := model.TestTournament(t, 1, 1)
+				tr.RegistrationClosesAt = tr.RegistrationOpensAt.Add(-time.Minute)
+
+				return tr
+			},
+			isValid: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.isValid {
+				assert.NoError(t, tc.tr().Validate())
+			} else {
+				assert.Error(t, tc.tr().Validate())
+			}
+		})
+	}
+}
+
+func TestTournament_CanRegister(t *testing.T) {
+	tr := model.TestTournament(t, 1, 1)
+	tr.BeforeCreate()
+	now := time.Now()
+
+	assert.NoError(t, tr.CanRegister(now, 0))
+	assert.EqualError(t, tr.CanRegister(now, tr.MaxPlayers), model.ErrTournamentFull.Error())
+	assert.EqualError(t, tr.CanRegister(tr.RegistrationClosesAt, 0), model.ErrRegistrationClosed.Error())
+	assert.EqualError(t, tr.CanRegister(tr.RegistrationOpensAt.Add(-time.Second), 0), model.ErrRegistrationClosed.Error())
+}
+
+func TestTournament_Advance(t *testing.T) {
+	tr := model.TestTournament(t, 1, 1)
+	tr.BeforeCreate()
+
+	assert.EqualError(t, tr.Advance(func(*tournament.Bracket) error { return nil }), model.ErrTournamentNotRunning.Error())
+	assert.EqualError(t, tr.Start([]int{1}), tournament.ErrTooFewPlayers.Error())
+	assert.NoError(t, tr.Start([]int{1, 2}))
+	assert.Equal(t, model.TournamentRunning, tr.Status)
+	assert.EqualError(t, tr.CanRegister(time.Now(), 2), model.ErrRegistrationClosed.Error())
+
+	assert.NoError(t, tr.Advance(func(b *tournament.Bracket) error {
+		return b.Report(1, 2)
+	}))
+	assert.Equal(t, model.TournamentFinished, tr.Status)
+	assert.Equal(t, 2, tr.Bracket.Champion())
+}
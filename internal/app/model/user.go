@@ -1,17 +1,29 @@
 package model
 
 import (
+	"time"
+
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// User object that has id, email, password and encrypted password fields
+// User roles
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// User object that has id, email, password, encrypted password, role
+// and registration date fields
 type User struct {
-	ID                int    `json:"id"`
-	Email             string `json:"email"`
-	Password          string `json:"password,omitempty"`
-	EncryptedPassword string `json:"-"`
+	ID                int       `json:"id"`
+	Email             string    `json:"email"`
+	Password          string    `json:"password,omitempty"`
+	EncryptedPassword string    `json:"-"`
+	Role              string    `json:"role"`
+	CreatedAt         time.Time `json:"created_at"`
 }
 
 // Validate func. Validating user instance for id, email and password
@@ -21,12 +33,22 @@ func (u *User) Validate() error {
 		u,
 		validation.Field(&u.Email, validation.Required, is.Email),
 		validation.Field(&u.Password, validation.By(requiredIf(u.EncryptedPassword == "")), validation.Length(6, 100)),
+		validation.Field(&u.Role, validation.In(RoleUser, RoleModerator, RoleAdmin)),
 	)
 }
 
 // BeforeCreate func. Encrypting password func that encrypts password and writes encrypted
-// version in User's EncryptedPassword field.
+// version in User's EncryptedPassword field. Users get the default role unless
+// another one is set, registration date is set to now unless it is known.
 func (u *User) BeforeCreate() error {
+	if u.Role == "" {
+		u.Role = RoleUser
+	}
+
+	if u.CreatedAt.IsZero() {
+		u.CreatedAt = time.Now().UTC()
+	}
+
 	if len(u.Password) > 0 {
 		enc, err := encryptString(u.Password)
 		if err != nil {
@@ -44,9 +66,22 @@ func (u *User) Sanitize() {
 	u.Password = ""
 }
 
+// HasRole func. Checks that the user's role is the imported one or
+// a more powerful one. Admins can do everything moderators can.
+func (u *User) HasRole(role string) bool {
+	switch role {
+	case RoleAdmin:
+		return u.Role == RoleAdmin
+	case RoleModerator:
+		return u.Role == RoleModerator || u.Role == RoleAdmin
+	}
+
+	return true
+}
+
 // ComparePassword func. Compares password with its encrypted variant
 func (u *User) ComparePassword(password string) bool {
-	return bcrypt.CompareHashAndPassword([]byte(u.EncryptedPassword), []byte(password)) == nil
+	return compareEncryptedString(u.EncryptedPassword, password)
 }
 
 // encryptString func. Using function GenerateFromPassword from bcrypt
@@ -60,3 +95,9 @@ func encryptString(s string) (string, error) {
 
 	return string(b), nil
 }
+
+// compareEncryptedString func. Compares imported string with its
+// variant encrypted by encryptString.
+func compareEncryptedString(enc, s string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(enc), []byte(s)) == nil
+}
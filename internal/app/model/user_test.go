@@ -62,6 +62,16 @@ func TestUser_Validate(t *testing.T) {
 			},
 			isValid: false,
 		},
+		{
+			name: "unknown role",
+			u: func() *model.User {
+				u := model.TestUser(t)
+				u.Role = "wizard"
+
+				return u
+			},
+			isValid: false,
+		},
 		{
 			name: "short password",
 			u: func() *model.User {
@@ -88,4 +98,14 @@ func TestUser_BeforeCreate(t *testing.T) {
 	u := model.TestUser(t)
 	assert.NoError(t, u.BeforeCreate())
 	assert.NotEmpty(t, u.EncryptedPassword)
+	assert.Equal(t, model.RoleUser, u.Role)
+	assert.False(t, u.CreatedAt.IsZero())
+}
+
+func TestUser_HasRole(t *testing.T) {
+	u := model.TestUser(t)
+	u.Role = model.RoleModerator
+	assert.True(t, u.HasRole(model.RoleUser))
+	assert.True(t, u.HasRole(model.RoleModerator))
+	assert.False(t, u.HasRole(model.RoleAdmin))
 }
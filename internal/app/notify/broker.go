@@ -0,0 +1,79 @@
+// Package notify delivers notifications of users to their open streams
+// in real time.
+package notify
+
+import (
+	"sync"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+// subscriptionBuffer is the number of notifications a subscriber can
+// fall behind before the broker starts dropping notifications for it
+const subscriptionBuffer = 16
+
+// Broker interface. Fans notifications out to every subscriber of the
+// notified user, e.g. every open tab. Implementations backed by a shared
+// backend let several server instances serve the same users.
+type Broker interface {
+	Publish(*model.Notification) error
+	Subscribe(userID int) (<-chan *model.Notification, func())
+}
+
+// MemoryBroker object. In-process Broker implementation. It is safe
+// for concurrent use.
+type MemoryBroker struct {
+	mu    sync.RWMutex
+	users map[int]map[chan *model.Notification]struct{}
+}
+
+// NewMemoryBroker func. Constructor for MemoryBroker
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		users: make(map[int]map[chan *model.Notification]struct{}),
+	}
+}
+
+// Publish func. Sends the notification to every subscriber of its user.
+// Subscribers that can't keep up miss the notification instead of
+// blocking the publisher, it stays in the notification list anyway.
+func (b *MemoryBroker) Publish(n *model.Notification) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.users[n.UserID] {
+		select {
+		case ch <- n:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Subscribe func. Subscribes to notifications of the user. Returns the
+// channel notifications are delivered to and the func that cancels the
+// subscription and closes the channel.
+func (b *MemoryBroker) Subscribe(userID int) (<-chan *model.Notification, func()) {
+	ch := make(chan *model.Notification, subscriptionBuffer)
+
+	b.mu.Lock()
+	if b.users[userID] == nil {
+		b.users[userID] = make(map[chan *model.Notification]struct{})
+	}
+	b.users[userID][ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.users[userID], ch)
+			if len(b.users[userID]) == 0 {
+				delete(b.users, userID)
+			}
+			close(ch)
+			b.mu.Unlock()
+		})
+	}
+}
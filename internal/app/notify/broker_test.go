@@ -0,0 +1,36 @@
+package notify_test
+
+import (
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/notify"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryBroker_Publish(t *testing.T) {
+	b := notify.NewMemoryBroker()
+	tab1, unsubscribe1 := b.Subscribe(1)
+	defer unsubscribe1()
+	tab2, unsubscribe2 := b.Subscribe(1)
+	defer unsubscribe2()
+	other, unsubscribeOther := b.Subscribe(2)
+	defer unsubscribeOther()
+
+	n := model.TestNotification(t, 1)
+	assert.NoError(t, b.Publish(n))
+	assert.Equal(t, n, <-tab1)
+	assert.Equal(t, n, <-tab2)
+	assert.Len(t, other, 0)
+}
+
+func TestMemoryBroker_Subscribe(t *testing.T) {
+	b := notify.NewMemoryBroker()
+	ch, unsubscribe := b.Subscribe(1)
+	unsubscribe()
+	unsubscribe()
+
+	_, ok := <-ch
+	assert.False(t, ok)
+	assert.NoError(t, b.Publish(model.TestNotification(t, 1)))
+}
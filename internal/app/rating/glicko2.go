@@ -0,0 +1,161 @@
+// Package rating implements Glicko-2 skill rating system as described
+// by Mark E. Glickman in "Example of the Glicko-2 system".
+package rating
+
+import (
+	"math"
+)
+
+// Glicko-2 defaults for new players
+const (
+	DefaultRating     = 1500.0
+	DefaultRD         = 350.0
+	DefaultVolatility = 0.06
+	// DefaultTau constrains change of volatility over time
+	DefaultTau = 0.5
+)
+
+// Scores of a single game
+const (
+	Win  = 1.0
+	Draw = 0.5
+	Loss = 0.0
+)
+
+const (
+	// scale converts ratings between Glicko and Glicko-2 scales
+	scale = 173.7178
+	// epsilon is convergence tolerance of volatility iteration
+	epsilon = 0.000001
+)
+
+// Player object. Describes player's skill on Glicko scale.
+type Player struct {
+	Rating     float64
+	RD         float64
+	Volatility float64
+}
+
+// Result object. Describes a single game of a rating period: the
+// opponent's rating before the period and the score of the player.
+type Result struct {
+	Opponent Player
+	Score    float64
+}
+
+// NewPlayer func. Returns a player with default rating
+func NewPlayer() Player {
+	return Player{
+		Rating:     DefaultRating,
+		RD:         DefaultRD,
+		Volatility: DefaultVolatility,
+	}
+}
+
+// Update func. Returns the player's skill after the rating period
+// with the results. A period without results only increases rating
+// deviation.
+func Update(p Player, results []Result, tau float64) Player {
+	mu := (p.Rating - DefaultRating) / scale
+	phi := p.RD / scale
+
+	if len(results) == 0 {
+		phi = math.Sqrt(phi*phi + p.Volatility*p.Volatility)
+		return Player{
+			Rating:     p.Rating,
+			RD:         phi * scale,
+			Volatility: p.Volatility,
+		}
+	}
+	// Computing estimated variance and improvement of the rating
+	v, delta := 0.0, 0.0
+	for _, r := range results {
+		muJ := (r.Opponent.Rating - DefaultRating) / scale
+		gJ := g(r.Opponent.RD / scale)
+		eJ := e(mu, muJ, gJ)
+		v += gJ * gJ * eJ * (1 - eJ)
+		delta += gJ * (r.Score - eJ)
+	}
+	v = 1 / v
+	delta *= v
+
+	sigma := volatility(p.Volatility, phi, v, delta, tau)
+	// Updating rating deviation and rating
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phiNew := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	muNew := mu + phiNew*phiNew*delta/v
+
+	return Player{
+		Rating:     muNew*scale + DefaultRating,
+		RD:         phiNew * scale,
+		Volatility: sigma,
+	}
+}
+
+// UpdateTeams func. Returns skills of two teams after a match where team
+// a scored scoreA. Every player is rated as if they played every player of
+// the other team, using skills from before the match.
+func UpdateTeams(a, b []Player, scoreA float64, tau float64) ([]Player, []Player) {
+	return updateTeam(a, b, scoreA, tau), updateTeam(b, a, 1-scoreA, tau)
+}
+
+// updateTeam func. Returns skills of the team after playing the opponents
+func updateTeam(team, opponents []Player, score float64, tau float64) []Player {
+	results := make([]Result, len(opponents))
+	for i, o := range opponents {
+		results[i] = Result{Opponent: o, Score: score}
+	}
+
+	updated := make([]Player, len(team))
+	for i, p := range team {
+		updated[i] = Update(p, results, tau)
+	}
+
+	return updated
+}
+
+// g func. Reduces impact of a game according to opponent's deviation
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// e func. Expected score against the opponent
+func e(mu, muJ, gJ float64) float64 {
+	return 1 / (1 + math.Exp(-gJ*(mu-muJ)))
+}
+
+// volatility func. Finds new volatility with the Illinois algorithm
+func volatility(sigma, phi, v, delta, tau float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
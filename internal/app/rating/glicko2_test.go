@@ -0,0 +1,45 @@
+package rating_test
+
+import (
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/rating"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdate(t *testing.T) {
+	// Example from Glickman's "Example of the Glicko-2 system"
+	p := rating.Player{Rating: 1500, RD: 200, Volatility: 0.06}
+	results := []rating.Result{
+		{Opponent: rating.Player{Rating: 1400, RD: 30}, Score: rating.Win},
+		{Opponent: rating.Player{Rating: 1550, RD: 100}, Score: rating.Loss},
+		{Opponent: rating.Player{Rating: 1700, RD: 300}, Score: rating.Loss},
+	}
+
+	updated := rating.Update(p, results, 0.5)
+	assert.InDelta(t, 1464.06, updated.Rating, 0.01)
+	assert.InDelta(t, 151.52, updated.RD, 0.01)
+	assert.InDelta(t, 0.05999, updated.Volatility, 0.00001)
+}
+
+func TestUpdate_NoGames(t *testing.T) {
+	p := rating.Player{Rating: 1500, RD: 200, Volatility: 0.06}
+	updated := rating.Update(p, nil, rating.DefaultTau)
+	assert.Equal(t, p.Rating, updated.Rating)
+	assert.Greater(t, updated.RD, p.RD)
+}
+
+func TestUpdateTeams(t *testing.T) {
+	a := []rating.Player{rating.NewPlayer(), rating.NewPlayer()}
+	b := []rating.Player{rating.NewPlayer()}
+
+	newA, newB := rating.UpdateTeams(a, b, rating.Win, rating.DefaultTau)
+	assert.Len(t, newA, 2)
+	assert.Len(t, newB, 1)
+	assert.Greater(t, newA[0].Rating, rating.DefaultRating)
+	assert.Less(t, newB[0].Rating, rating.DefaultRating)
+
+	newA, newB = rating.UpdateTeams(a, b, rating.Draw, rating.DefaultTau)
+	assert.InDelta(t, rating.DefaultRating, newA[0].Rating, 0.001)
+	assert.InDelta(t, rating.DefaultRating, newB[0].Rating, 0.001)
+}
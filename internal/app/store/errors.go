@@ -5,4 +5,7 @@ import "errors"
 var (
 	// ErrRecordNotFound error tells us, that we can't fing target record in DB
 	ErrRecordNotFound = errors.New("record not found")
+	// ErrRecordExists error tells us, that the record we want to write
+	// breaks a unique constraint in DB
+	ErrRecordExists = errors.New("record already exists")
 )
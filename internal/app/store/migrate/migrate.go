@@ -0,0 +1,391 @@
+// Package migrate runs golang-migrate style migrations of the DB schema.
+// The version of the schema is kept in schema_migrations the way
+// golang-migrate keeps it, so schemas migrated by either of them can be
+// migrated further by the other one.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	errNoMigrations = errors.New("no migrations found")
+	errSchemaDirty  = errors.New("schema is dirty, the last migration failed")
+)
+
+// lockID is the key of the postgres advisory lock migrations hold, any
+// key works as long as all instances use the same one
+const lockID = 7214602831
+
+// Dialect object. Describes SQL of the DB schema migrations are run on
+type Dialect struct {
+	// exists query tells if the version table was created
+	exists string
+	// lock statement holds the lock of migrations until the transaction
+	// ends, transactions of DBs without one hold the lock themselves
+	lock string
+}
+
+// Dialects of DBs migrations are run on. SQLite transactions run one at
+// a time as long as they're begun as immediate ones, see sqlitestore.Open.
+var (
+	Postgres = &Dialect{
+		exists: "SELECT to_regclass('schema_migrations') IS NOT NULL",
+		lock:   fmt.Sprintf("SELECT pg_advisory_xact_lock(%d)", lockID),
+	}
+	SQLite = &Dialect{
+		exists: "SELECT count(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'",
+	}
+)
+
+// Migration object. Versioned change of the schema made of
+// <version>_<name>.up.sql and <version>_<name>.down.sql files.
+// Migrations without down file can't be reverted.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status object. Describes the version of the schema and migrations
+// which aren't applied yet.
+type Status struct {
+	Version int64
+	Dirty   bool
+	Latest  int64
+	Pending []*Migration
+}
+
+// Load func. Reading migrations from the root directory of fsys, ordered
+// by version. Other files and directories are skipped.
+func Load(fsys fs.FS) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+		direction := ""
+		switch {
+		case entry.IsDir():
+			continue
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		i := strings.IndexByte(name, '_')
+		if i < 0 {
+			continue
+		}
+		version, err := strconv.ParseInt(name[:i], 10, 64)
+		if err != nil || version <= 0 {
+			continue
+		}
+
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{
+				Version: version,
+				Name:    strings.TrimSuffix(name[i+1:], "."+direction+".sql"),
+			}
+			byVersion[version] = m
+		}
+
+		if direction == "up" {
+			m.Up = string(b)
+		} else {
+			m.Down = string(b)
+		}
+	}
+
+	migrations := []*Migration{}
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d has no up file", m.Version)
+		}
+		migrations = append(migrations, m)
+	}
+
+	if len(migrations) == 0 {
+		return nil, errNoMigrations
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Migrator object. Moves the schema of the DB between versions of the
+// migrations. Every move runs in one transaction holding the lock of
+// migrations, so concurrently started instances migrate the schema once
+// and a failed move leaves the schema as it was.
+type Migrator struct {
+	db         *sql.DB
+	dialect    *Dialect
+	migrations []*Migration
+}
+
+// New func. Constructor for Migrator of the DB of the dialect. The
+// migrations are ordered by version, see Load.
+func New(db *sql.DB, dialect *Dialect, migrations []*Migration) *Migrator {
+	return &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: migrations,
+	}
+}
+
+// Latest func. Returns version of the latest migration
+func (m *Migrator) Latest() int64 {
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up func. Applying all migrations which aren't applied yet
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.Goto(ctx, m.Latest())
+}
+
+// Down func. Reverting the last applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.migrate(ctx, func(current int64) (int64, error) {
+		if current == 0 {
+			return 0, errors.New("no migrations are applied")
+		}
+
+		var previous int64
+		for _, migration := range m.migrations {
+			if migration.Version >= current {
+				break
+			}
+			previous = migration.Version
+		}
+
+		return previous, nil
+	})
+}
+
+// Goto func. Applying or reverting migrations until the schema is at
+// the version. Zero version is the schema without migrations.
+func (m *Migrator) Goto(ctx context.Context, version int64) error {
+	if version != 0 && m.find(version) == nil {
+		return fmt.Errorf("no migration with version %d", version)
+	}
+
+	return m.migrate(ctx, func(int64) (int64, error) {
+		return version, nil
+	})
+}
+
+// Status func. Returns the version of the schema and pending migrations
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	version, dirty, err := m.version(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Status{
+		Version: version,
+		Dirty:   dirty,
+		Latest:  m.Latest(),
+		Pending: []*Migration{},
+	}
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			s.Pending = append(s.Pending, migration)
+		}
+	}
+
+	return s, nil
+}
+
+// Check func. Checks that the schema is migrated to the latest version
+// and the last migration didn't fail.
+func (m *Migrator) Check(ctx context.Context) error {
+	version, dirty, err := m.version(ctx, m.db)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return errSchemaDirty
+	}
+
+	switch latest := m.Latest(); {
+	case version == 0:
+		return fmt.Errorf("schema isn't migrated, migrations are at version %d", latest)
+	case version != latest:
+		return fmt.Errorf("schema is at version %d, migrations are at version %d", version, latest)
+	}
+
+	return nil
+}
+
+// migrate func. Moving the schema to the version target returns for the
+// current one in a transaction holding the lock of migrations
+func (m *Migrator) migrate(ctx context.Context, target func(current int64) (int64, error)) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := m.move(ctx, tx, target); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// move func. Applying or reverting migrations with tx and writing the
+// new version of the schema
+func (m *Migrator) move(ctx context.Context, tx *sql.Tx, target func(current int64) (int64, error)) error {
+	if m.dialect.lock != "" {
+		if _, err := tx.ExecContext(ctx, m.dialect.lock); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		"CREATE TABLE IF NOT EXISTS schema_migrations (version bigint not null primary key, dirty boolean not null)",
+	); err != nil {
+		return err
+	}
+
+	current, dirty, err := m.version(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	if dirty {
+		return errSchemaDirty
+	}
+
+	if current != 0 && m.find(current) == nil {
+		return fmt.Errorf("schema is at version %d, which has no migration", current)
+	}
+
+	version, err := target(current)
+	if err != nil {
+		return err
+	}
+
+	if version == current {
+		return nil
+	}
+
+	for _, step := range m.steps(current, version) {
+		sql := step.Up
+		if version < current {
+			if step.Down == "" {
+				return fmt.Errorf("migration %d can't be reverted", step.Version)
+			}
+			sql = step.Down
+		}
+
+		if _, err := tx.ExecContext(ctx, sql); err != nil {
+			return fmt.Errorf("migration %d_%s: %v", step.Version, step.Name, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations"); err != nil {
+		return err
+	}
+
+	if version == 0 {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO schema_migrations (version, dirty) VALUES (%d, false)", version))
+
+	return err
+}
+
+// steps func. Returns migrations moving the schema from one version to
+// another in order they run: applied ones up to the version, reverted
+// ones down from the version.
+func (m *Migrator) steps(from, to int64) []*Migration {
+	steps := []*Migration{}
+	if from < to {
+		for _, migration := range m.migrations {
+			if migration.Version > from && migration.Version <= to {
+				steps = append(steps, migration)
+			}
+		}
+
+		return steps
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		if migration := m.migrations[i]; migration.Version <= from && migration.Version > to {
+			steps = append(steps, migration)
+		}
+	}
+
+	return steps
+}
+
+// find func. Finding migration with the version
+func (m *Migrator) find(version int64) *Migration {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration
+		}
+	}
+
+	return nil
+}
+
+// version func. Returns the version of the schema and whether the last
+// migration failed. Schemas without the version table are at zero
+// version.
+func (m *Migrator) version(ctx context.Context, q queryer) (int64, bool, error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, m.dialect.exists).Scan(&exists); err != nil {
+		return 0, false, err
+	}
+
+	if !exists {
+		return 0, false, nil
+	}
+
+	var version int64
+	var dirty bool
+	if err := q.QueryRowContext(
+		ctx,
+		"SELECT version, dirty FROM schema_migrations LIMIT 1",
+	).Scan(&version, &dirty); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	return version, dirty, nil
+}
+
+// queryer interface. Implemented by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
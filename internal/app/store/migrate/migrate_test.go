@@ -0,0 +1,222 @@
+package migrate_test
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/migrate"
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlitestore"
+	"github.com/GShamian/tavern-of-games/migrations"
+)
+
+var testMigrations = fstest.MapFS{
+	"1_create_a.up.sql":   {Data: []byte("CREATE TABLE a (id integer primary key)")},
+	"1_create_a.down.sql": {Data: []byte("DROP TABLE a")},
+	"2_create_b.up.sql":   {Data: []byte("CREATE TABLE b (id integer primary key); INSERT INTO b (id) VALUES (1)")},
+	"2_create_b.down.sql": {Data: []byte("DROP TABLE b")},
+	"README.md":           {Data: []byte("not a migration")},
+}
+
+// testDB func. Opens SQLite DB in a temporary directory, returns path
+// of its file too
+func testDB(t *testing.T) (*sql.DB, string, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "test.db")
+	db, err := sqlitestore.Open(path)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	return db, path, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+
+	var exists bool
+	if err := db.QueryRow(
+		"SELECT count(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?",
+		name,
+	).Scan(&exists); err != nil {
+		t.Fatal(err)
+	}
+
+	return exists
+}
+
+func TestLoad(t *testing.T) {
+	files, err := migrate.Load(testMigrations)
+	assert.NoError(t, err)
+	if assert.Len(t, files, 2) {
+		assert.Equal(t, int64(1), files[0].Version)
+		assert.Equal(t, "create_a", files[0].Name)
+		assert.Equal(t, "DROP TABLE a", files[0].Down)
+		assert.Equal(t, int64(2), files[1].Version)
+	}
+
+	files, err = migrate.Load(migrations.Postgres())
+	assert.NoError(t, err)
+	assert.True(t, files[len(files)-1].Version >= 20201105120000)
+
+	_, err = migrate.Load(fstest.MapFS{})
+	assert.Error(t, err)
+
+	_, err = migrate.Load(fstest.MapFS{"1_create_a.down.sql": {Data: []byte("DROP TABLE a")}})
+	assert.Error(t, err)
+}
+
+func TestMigrator_UpDown(t *testing.T) {
+	db, _, teardown := testDB(t)
+	defer teardown()
+
+	files, err := migrate.Load(testMigrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := migrate.New(db, migrate.SQLite, files)
+	ctx := context.Background()
+
+	assert.Error(t, m.Check(ctx))
+	assert.Error(t, m.Down(ctx))
+
+	s, err := m.Status(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), s.Version)
+	assert.Equal(t, int64(2), s.Latest)
+	assert.Len(t, s.Pending, 2)
+
+	assert.NoError(t, m.Up(ctx))
+	assert.NoError(t, m.Check(ctx))
+	assert.True(t, tableExists(t, db, "b"))
+	// Up is a no-op once the schema is at the latest version
+	assert.NoError(t, m.Up(ctx))
+
+	assert.NoError(t, m.Down(ctx))
+	assert.EqualError(t, m.Check(ctx), "schema is at version 1, migrations are at version 2")
+	assert.True(t, tableExists(t, db, "a"))
+	assert.False(t, tableExists(t, db, "b"))
+
+	s, err = m.Status(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), s.Version)
+	assert.Len(t, s.Pending, 1)
+
+	assert.Error(t, m.Goto(ctx, 3))
+	assert.NoError(t, m.Goto(ctx, 0))
+	assert.False(t, tableExists(t, db, "a"))
+	assert.EqualError(t, m.Check(ctx), "schema isn't migrated, migrations are at version 2")
+}
+
+func TestMigrator_Failed(t *testing.T) {
+	db, _, teardown := testDB(t)
+	defer teardown()
+
+	fsys := fstest.MapFS{
+		"1_create_a.up.sql": testMigrations["1_create_a.up.sql"],
+		"2_broken.up.sql":   {Data: []byte("CREATE TABLE")},
+	}
+	files, err := migrate.Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := migrate.New(db, migrate.SQLite, files)
+	ctx := context.Background()
+
+	// The failed migration rolls back the ones applied before it
+	assert.Error(t, m.Up(ctx))
+	assert.False(t, tableExists(t, db, "a"))
+	s, err := m.Status(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), s.Version)
+
+	// Migrations without down files can't be reverted
+	assert.NoError(t, m.Goto(ctx, 1))
+	assert.Error(t, m.Down(ctx))
+	assert.True(t, tableExists(t, db, "a"))
+}
+
+func TestMigrator_Dirty(t *testing.T) {
+	db, _, teardown := testDB(t)
+	defer teardown()
+
+	files, err := migrate.Load(testMigrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := migrate.New(db, migrate.SQLite, files)
+	ctx := context.Background()
+
+	assert.NoError(t, m.Goto(ctx, 1))
+	if _, err := db.Exec("UPDATE schema_migrations SET dirty = true"); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.EqualError(t, m.Up(ctx), "schema is dirty, the last migration failed")
+	assert.EqualError(t, m.Check(ctx), "schema is dirty, the last migration failed")
+
+	s, err := m.Status(ctx)
+	assert.NoError(t, err)
+	assert.True(t, s.Dirty)
+}
+
+func TestMigrator_Concurrent(t *testing.T) {
+	_, path, teardown := testDB(t)
+	defer teardown()
+
+	files, err := migrate.Load(migrations.SQLite())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Instances sharing the DB start at once, each with its own pool
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		db, err := sqlitestore.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer db.Close()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- migrate.New(db, migrate.SQLite, files).Up(context.Background())
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+
+	db, err := sqlitestore.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	m := migrate.New(db, migrate.SQLite, files)
+	assert.NoError(t, m.Check(context.Background()))
+	assert.NoError(t, m.Goto(context.Background(), 0))
+	assert.False(t, tableExists(t, db, "users"))
+}
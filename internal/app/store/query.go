@@ -0,0 +1,28 @@
+package store
+
+import (
+	"time"
+)
+
+// Sort orders of review lists
+const (
+	SortRecent  = "recent"
+	SortHelpful = "helpful"
+)
+
+// ListQuery object. Describes sorting and pagination of list requests.
+type ListQuery struct {
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+// LFGFilter object. Describes filters of LFG board lists. Zero fields
+// don't filter, the time window is applied to start times of games.
+type LFGFilter struct {
+	GameID   int
+	Platform string
+	Language string
+	From     time.Time
+	To       time.Time
+}
@@ -1,10 +1,218 @@
 package store
 
-import "github.com/GShamian/tavern-of-games/internal/app/model"
+import (
+	"context"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
 
 // UserRepository interface
 type UserRepository interface {
-	Create(*model.User) error
-	Find(int) (*model.User, error)
-	FindByEmail(string) (*model.User, error)
+	Create(context.Context, *model.User) error
+	Find(context.Context, int) (*model.User, error)
+	FindByEmail(context.Context, string) (*model.User, error)
+	FindRegisteredBefore(context.Context, time.Time) ([]int, error)
+}
+
+// GameRepository interface
+type GameRepository interface {
+	Create(context.Context, *model.Game) error
+	Find(context.Context, int) (*model.Game, error)
+}
+
+// LibraryRepository interface. Entries are keyed by user id and game id.
+type LibraryRepository interface {
+	Add(context.Context, *model.LibraryEntry) error
+	Update(context.Context, *model.LibraryEntry) error
+	Remove(ctx context.Context, userID, gameID int) error
+	Find(ctx context.Context, userID, gameID int) (*model.LibraryEntry, error)
+	FindByUser(ctx context.Context, userID int, status string) ([]*model.LibraryEntry, error)
+	Bulk(ctx context.Context, userID int, add []*model.LibraryEntry, remove []int) error
+	Visibility(ctx context.Context, userID int) (string, error)
+	SetVisibility(ctx context.Context, userID int, visibility string) error
+}
+
+// ReviewRepository interface. Writing, changing and deleting a review
+// also updates aggregated rating of the reviewed game.
+type ReviewRepository interface {
+	Create(context.Context, *model.Review) error
+	Update(context.Context, *model.Review) error
+	Delete(ctx context.Context, userID, gameID int) error
+	Find(context.Context, int) (*model.Review, error)
+	FindByUserAndGame(ctx context.Context, userID, gameID int) (*model.Review, error)
+	FindByGame(ctx context.Context, gameID int, q *ListQuery) ([]*model.Review, error)
+	Vote(context.Context, *model.ReviewVote) error
+}
+
+// FriendshipRepository interface. Friendship changes go through
+// model.NextFriendship, so every implementation enforces the same
+// state machine. Blocked users can't send friend requests to each other.
+type FriendshipRepository interface {
+	Find(ctx context.Context, userID, otherID int) (*model.Friendship, error)
+	Apply(ctx context.Context, actorID, otherID int, action string) (*model.Friendship, error)
+	FindByUser(ctx context.Context, userID int, status string) ([]*model.Friendship, error)
+	Mutual(ctx context.Context, userID, otherID int) ([]int, error)
+	Block(ctx context.Context, blockerID, blockedID int) error
+	Unblock(ctx context.Context, blockerID, blockedID int) error
+	Blocked(ctx context.Context, blockerID int) ([]int, error)
+	IsBlocked(ctx context.Context, userID, otherID int) (bool, error)
+}
+
+// ChatRepository interface. Chat history is paginated with a cursor:
+// messages older than the message with beforeID are returned, newest
+// first. Zero beforeID means the newest messages of the room.
+type ChatRepository interface {
+	Create(context.Context, *model.ChatMessage) error
+	Find(context.Context, int) (*model.ChatMessage, error)
+	Delete(context.Context, int) error
+	FindByRoom(ctx context.Context, room string, beforeID, limit int) ([]*model.ChatMessage, error)
+}
+
+// MatchRepository interface. Update saves the status and the winner of
+// the match. Once the result is final, ratings of the players are
+// updated in the same transaction, at most once per match.
+type MatchRepository interface {
+	Create(context.Context, *model.Match) error
+	Find(context.Context, int) (*model.Match, error)
+	Update(context.Context, *model.Match) error
+	FindByStatus(ctx context.Context, status string, q *ListQuery) ([]*model.Match, error)
+}
+
+// RatingRepository interface. Ratings are written by MatchRepository,
+// users without rated matches have no rating. Leaderboard places are
+// ordered by rating, ties are broken by user id.
+type RatingRepository interface {
+	Find(ctx context.Context, userID, gameID int, season string) (*model.Rating, error)
+	Leaderboard(ctx context.Context, gameID int, season string, q *ListQuery) ([]*model.LeaderboardEntry, error)
+	Rank(ctx context.Context, userID, gameID int, season string) (int, error)
+}
+
+// TournamentRepository interface. Update locks the tournament while
+// the imported func changes it, so concurrently reported results can't
+// overwrite each other. Registration is checked with
+// model.Tournament.CanRegister under the same lock.
+type TournamentRepository interface {
+	Create(context.Context, *model.Tournament) error
+	Find(context.Context, int) (*model.Tournament, error)
+	FindByStatus(ctx context.Context, status string, q *ListQuery) ([]*model.Tournament, error)
+	Update(ctx context.Context, id int, fn func(*model.Tournament) error) (*model.Tournament, error)
+	Register(ctx context.Context, tournamentID, userID int) error
+	Unregister(ctx context.Context, tournamentID, userID int) error
+	Participants(ctx context.Context, tournamentID int) ([]*model.TournamentParticipant, error)
+}
+
+// AchievementRepository interface. User events are the history
+// achievements are evaluated from. Zero gameID of event counters means
+// events of any game. Saved progress never decreases and unlocked
+// achievements stay unlocked. Evaluations remember criteria
+// fingerprints of achievements the whole history was evaluated for.
+type AchievementRepository interface {
+	CreateEvent(context.Context, *model.UserEvent) error
+	CountEvents(ctx context.Context, userID int, eventType string, gameID int) (int, error)
+	CountEventsByUser(ctx context.Context, eventType string, gameID int) (map[int]int, error)
+	SaveProgress(context.Context, *model.AchievementProgress) error
+	FindProgress(ctx context.Context, userID int) ([]*model.AchievementProgress, error)
+	Evaluated(ctx context.Context, code string) (string, error)
+	SetEvaluated(ctx context.Context, code, fingerprint string) error
+}
+
+// GuildRepository interface. Every user is a member of one guild at
+// most and every guild has exactly one leader, who can't be removed or
+// demoted until the leadership is transferred. Sending an invite while
+// the opposite one is pending (see model.AcceptsGuildInvite) accepts it:
+// the user joins the guild and the returned membership isn't nil.
+// Guild leaderboard places are ordered by mean rating of members, ties
+// are broken by guild id.
+type GuildRepository interface {
+	Create(ctx context.Context, g *model.Guild, leaderID int) error
+	Find(context.Context, int) (*model.Guild, error)
+	List(ctx context.Context, q *ListQuery) ([]*model.Guild, error)
+	Update(context.Context, *model.Guild) error
+	Delete(context.Context, int) error
+	Member(ctx context.Context, guildID, userID int) (*model.GuildMember, error)
+	MemberOf(ctx context.Context, userID int) (*model.GuildMember, error)
+	Members(ctx context.Context, guildID int) ([]*model.GuildMember, error)
+	RemoveMember(ctx context.Context, guildID, userID int) error
+	SetRole(context.Context, *model.GuildMember) error
+	Transfer(ctx context.Context, guildID, leaderID, userID int) error
+	Invite(context.Context, *model.GuildInvite) (*model.GuildMember, error)
+	RemoveInvite(ctx context.Context, guildID, userID int) error
+	Invites(ctx context.Context, guildID int) ([]*model.GuildInvite, error)
+	InvitesOf(ctx context.Context, userID int) ([]*model.GuildInvite, error)
+	Leaderboard(ctx context.Context, gameID int, season string, q *ListQuery) ([]*model.GuildLeaderboardEntry, error)
+	Rank(ctx context.Context, guildID, gameID int, season string) (*model.GuildLeaderboardEntry, error)
+}
+
+// LFGRepository interface. Only open posts that haven't expired yet are
+// listed, soonest games first. Expire marks posts which expired at the
+// time and returns their number. Requests to join are checked with
+// model.LFGPost.CanRequest while the post is locked.
+type LFGRepository interface {
+	Create(context.Context, *model.LFGPost) error
+	Find(context.Context, int) (*model.LFGPost, error)
+	FindOpen(ctx context.Context, f *LFGFilter, now time.Time, q *ListQuery) ([]*model.LFGPost, error)
+	Close(context.Context, int) error
+	Expire(ctx context.Context, now time.Time) (int, error)
+	Request(context.Context, *model.LFGRequest) error
+	Requests(ctx context.Context, postID int) ([]*model.LFGRequest, error)
+}
+
+// EventRepository interface. Upcoming events are the ones which aren't
+// over at the time (see model.Event.Over), soonest first. RSVPs are
+// applied with model.Event.ApplyRSVP while the event is locked and the
+// changed ones are returned, the RSVP of the user first. Attended events
+// are the ones the user goes to, maybe goes to or waits for. A user has
+// at most one calendar token, setting one replaces the old one, it's
+// found by its hash.
+type EventRepository interface {
+	Create(context.Context, *model.Event) error
+	Find(context.Context, int) (*model.Event, error)
+	FindUpcoming(ctx context.Context, now time.Time, q *ListQuery) ([]*model.Event, error)
+	FindByAttendee(ctx context.Context, userID int) ([]*model.Event, error)
+	RSVP(ctx context.Context, eventID, userID int, status string) ([]*model.EventRSVP, error)
+	RSVPs(ctx context.Context, eventID int) ([]*model.EventRSVP, error)
+	SetCalendarToken(context.Context, *model.CalendarToken) error
+	RevokeCalendarToken(ctx context.Context, userID int) error
+	FindCalendarToken(ctx context.Context, hash string) (*model.CalendarToken, error)
+}
+
+// NotificationRepository interface. Notifications are paginated with a
+// cursor like chat history: notifications older than the one with
+// beforeID are returned, newest first. MarkRead of a notification of
+// another user fails with ErrRecordNotFound. Preferences map types to
+// whether the user receives notifications of the type, types missing
+// from the map are on.
+type NotificationRepository interface {
+	Create(context.Context, *model.Notification) error
+	FindByUser(ctx context.Context, userID, beforeID, limit int) ([]*model.Notification, error)
+	CountUnread(ctx context.Context, userID int) (int, error)
+	MarkRead(ctx context.Context, userID, id int) error
+	MarkAllRead(ctx context.Context, userID int) (int, error)
+	Preferences(ctx context.Context, userID int) (map[string]bool, error)
+	SetPreferences(ctx context.Context, userID int, prefs map[string]bool) error
+}
+
+// ActivityRepository interface. Activities are paginated with a cursor
+// like chat history: activities older than the one with beforeID are
+// returned, newest first. Feeds are merged on read from activities of
+// the users they follow.
+type ActivityRepository interface {
+	Create(context.Context, *model.Activity) error
+	FindByUsers(ctx context.Context, userIDs []int, beforeID, limit int) ([]*model.Activity, error)
+}
+
+// ModerationRepository interface. A user has one open report of the
+// same target at most, reporting it again fails with ErrRecordExists.
+// Reports of the text filter have no reporter and aren't limited. Reports are resolved once: Resolve locks the report, fails with
+// model.ErrReportResolved unless it's open and imposes the sanction
+// taken on it, if any, in the same transaction. Lift ends the active
+// sanction at the time, other sanctions can't be lifted.
+type ModerationRepository interface {
+	CreateReport(context.Context, *model.Report) error
+	FindReport(context.Context, int) (*model.Report, error)
+	FindReports(ctx context.Context, status string, q *ListQuery) ([]*model.Report, error)
+	Resolve(ctx context.Context, id int, action, reason string, moderatorID int, s *model.Sanction) (*model.Report, error)
+	Sanctions(ctx context.Context, userID int) ([]*model.Sanction, error)
+	Lift(ctx context.Context, id int, now time.Time) error
 }
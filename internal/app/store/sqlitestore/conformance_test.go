@@ -0,0 +1,18 @@
+package sqlitestore_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlitestore"
+	"github.com/GShamian/tavern-of-games/internal/app/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (store.Store, func()) {
+		db, teardown := sqlitestore.TestDB(t)
+
+		return sqlitestore.New(db, 5*time.Second), teardown
+	})
+}
@@ -0,0 +1,116 @@
+package sqlitestore
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteParam matches $N params of queries
+var sqliteParam = regexp.MustCompile(`\$(\d+)`)
+
+// dialect object. Dialect of SQLite: ?N params, no row locks as
+// transactions run one at a time, arrays are kept as JSON text and
+// times as text in UTC.
+type dialect struct{}
+
+func (dialect) Rebind(query string) string {
+	return sqliteParam.ReplaceAllString(query, "?$1")
+}
+
+func (dialect) Lock() string {
+	return ""
+}
+
+func (dialect) Ints(ids *[]int) interface{} {
+	return (*intArray)(ids)
+}
+
+func (dialect) AnyOf(column, param string) string {
+	return column + " IN (SELECT value FROM json_each(" + param + "))"
+}
+
+// Epoch func. Returns nanoseconds since the Unix epoch of the time
+// expression. Times are kept as text in UTC, e.g. 2006-01-02
+// 15:04:05.999999999+00:00, so nanoseconds are read from the digits
+// after the seconds, date functions only keep milliseconds.
+func (dialect) Epoch(expr string) string {
+	return "(CAST(strftime('%s', " + expr + ") AS INTEGER) * 1000000000 + CASE WHEN substr(" + expr + ", 20, 1) = '.' " +
+		"THEN CAST(substr(substr(" + expr + ", 21, length(" + expr + ") - 26) || '000000000', 1, 9) AS INTEGER) ELSE 0 END)"
+}
+
+func (dialect) MoveBucket(column, from, to string) string {
+	changes := ""
+	if from != "" {
+		changes += ", " + jsonBucket(from) + ", json_extract(" + column + ", " + jsonBucket(from) + ") - 1"
+	}
+	if to != "" {
+		changes += ", " + jsonBucket(to) + ", json_extract(" + column + ", " + jsonBucket(to) + ") + 1"
+	}
+
+	return column + " = json_set(" + column + changes + ")"
+}
+
+func (dialect) TxOptions() *sql.TxOptions {
+	return nil
+}
+
+func (dialect) IsRetryable(err error) bool {
+	return false
+}
+
+func (dialect) IsUniqueViolation(err error) bool {
+	return isSQLiteError(err, sqlite3.SQLITE_CONSTRAINT_UNIQUE) ||
+		isSQLiteError(err, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY)
+}
+
+func (dialect) IsForeignKeyViolation(err error) bool {
+	return isSQLiteError(err, sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY)
+}
+
+// isSQLiteError func. Checks that err is a SQLite error with the
+// extended code
+func isSQLiteError(err error, code int) bool {
+	sqliteErr, ok := err.(*sqlite.Error)
+	return ok && sqliteErr.Code() == code
+}
+
+// jsonBucket func. Returns JSON path of the bucket in the param, buckets
+// start from 1, JSON arrays from 0
+func jsonBucket(param string) string {
+	return "'$[' || (" + param + " - 1) || ']'"
+}
+
+// intArray object. Ints kept as a JSON array in a text column
+type intArray []int
+
+// Value func. Returns the ints as JSON
+func (a intArray) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+
+	b, err := json.Marshal([]int(a))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(b), nil
+}
+
+// Scan func. Reading the ints from JSON
+func (a *intArray) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), a)
+	case []byte:
+		return json.Unmarshal(v, a)
+	}
+
+	return fmt.Errorf("can't scan %T into ints", src)
+}
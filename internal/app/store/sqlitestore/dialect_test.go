@@ -0,0 +1,44 @@
+package sqlitestore
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialect_Epoch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sqlitestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	times := []time.Time{
+		time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		time.Date(2020, 1, 2, 3, 4, 5, 500000000, time.UTC),
+		time.Date(2020, 1, 2, 3, 4, 5, 1, time.UTC),
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		times = append(times, time.Unix(0, r.Int63n(4e18)).UTC())
+	}
+
+	query := "SELECT " + dialect{}.Epoch("?1")
+	for _, tm := range times {
+		var nanos int64
+		if assert.NoError(t, db.QueryRow(query, tm).Scan(&nanos)) {
+			assert.Equal(t, tm.UnixNano(), nanos, tm.String())
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Package sqlitestore is the store kept in a SQLite file, for taverns
+// running on a single node without postgres. It's the sqlstore store
+// running queries in the SQLite dialect.
+package sqlitestore
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlstore"
+)
+
+// Scheme starts database URLs of SQLite DBs followed by path of the
+// file, e.g. sqlite:///var/lib/tavern.db
+const Scheme = "sqlite://"
+
+// Path func. Returns path of the SQLite DB file if the database URL is
+// a SQLite one
+func Path(databaseURL string) (string, bool) {
+	if !strings.HasPrefix(databaseURL, Scheme) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(databaseURL, Scheme), true
+}
+
+// Open func. Opens the SQLite DB in the file at the path, the file is
+// created if it doesn't exist. Foreign keys are enforced and the DB has
+// a single connection: SQLite writes in one transaction at a time
+// anyway, so writers wait for the connection instead of failing on the
+// locked DB. Transactions begin immediate, so ones of other processes
+// sharing the file wait for each other instead of failing to upgrade
+// their locks.
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open(
+		"sqlite",
+		"file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate&_time_format=sqlite",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+
+	return db, nil
+}
+
+// New func. Constructor for Store object of the SQLite DB opened with
+// Open. Every repository call is cancelled once it takes longer than
+// queryTimeout, zero means calls are only limited by their context.
+func New(db *sql.DB, queryTimeout time.Duration) *sqlstore.Store {
+	return sqlstore.NewWithDialect(db, dialect{}, queryTimeout)
+}
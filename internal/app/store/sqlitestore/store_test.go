@@ -0,0 +1,17 @@
+package sqlitestore_test
+
+import (
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlitestore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPath(t *testing.T) {
+	path, ok := sqlitestore.Path("sqlite:///var/lib/tavern.db")
+	assert.True(t, ok)
+	assert.Equal(t, "/var/lib/tavern.db", path)
+
+	_, ok = sqlitestore.Path("host=localhost port=5432 dbname=tavern")
+	assert.False(t, ok)
+}
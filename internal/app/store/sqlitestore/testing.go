@@ -0,0 +1,49 @@
+package sqlitestore
+
+import (
+	"context"
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/migrate"
+	"github.com/GShamian/tavern-of-games/migrations"
+)
+
+// TestDB func. Opens a new SQLite DB with the migrated schema in a
+// temporary directory, it's removed on teardown.
+func TestDB(t *testing.T) (*sql.DB, func()) {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "sqlitestore")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := Open(filepath.Join(dir, "test.db"))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	teardown := func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+
+	// Migrating the schema up with the embedded migrations
+	files, err := migrate.Load(migrations.SQLite())
+	if err != nil {
+		teardown()
+		t.Fatal(err)
+	}
+
+	if err := migrate.New(db, migrate.SQLite, files).Up(context.Background()); err != nil {
+		teardown()
+		t.Fatal(err)
+	}
+
+	return db, teardown
+}
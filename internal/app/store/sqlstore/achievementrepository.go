@@ -0,0 +1,185 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// AchievementRepository object for storing user events and achievement progress
+type AchievementRepository struct {
+	store *Store
+}
+
+// CreateEvent func. Writing imported user event in DB
+func (r *AchievementRepository) CreateEvent(ctx context.Context, e *model.UserEvent) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	e.BeforeCreate()
+
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		"INSERT INTO user_events (user_id, type, game_id, created_at) VALUES ($1, $2, $3, $4) RETURNING id",
+		e.UserID,
+		e.Type,
+		e.GameID,
+		e.CreatedAt,
+	).Scan(&e.ID); err != nil {
+		if r.store.dialect.IsForeignKeyViolation(err) {
+			return store.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// CountEvents func. Counting user's events of the type
+func (r *AchievementRepository) CountEvents(ctx context.Context, userID int, eventType string, gameID int) (int, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	var count int
+	err := r.store.conn().QueryRowContext(
+		ctx,
+		`SELECT count(*) FROM user_events WHERE user_id = $1 AND type = $2
+		AND ($3 = 0 OR game_id = $3)`,
+		userID,
+		eventType,
+		gameID,
+	).Scan(&count)
+
+	return count, err
+}
+
+// CountEventsByUser func. Counting events of the type of every user
+// who has them
+func (r *AchievementRepository) CountEventsByUser(ctx context.Context, eventType string, gameID int) (map[int]int, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		`SELECT user_id, count(*) FROM user_events WHERE type = $1
+		AND ($2 = 0 OR game_id = $2) GROUP BY user_id`,
+		eventType,
+		gameID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[int]int{}
+	for rows.Next() {
+		var userID, count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, err
+		}
+		counts[userID] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// SaveProgress func. Writing user's progress of the achievement. Lower
+// progress than the stored one and unlock dates of unlocked achievements
+// are kept, the stored values are scanned back into imported progress.
+func (r *AchievementRepository) SaveProgress(ctx context.Context, p *model.AchievementProgress) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	p.BeforeSave()
+
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		`INSERT INTO achievement_progress (user_id, code, progress, target, unlocked_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, code) DO UPDATE SET
+		progress = CASE WHEN EXCLUDED.progress > achievement_progress.progress
+			THEN EXCLUDED.progress ELSE achievement_progress.progress END,
+		target = EXCLUDED.target,
+		unlocked_at = COALESCE(achievement_progress.unlocked_at, EXCLUDED.unlocked_at)
+		RETURNING progress, unlocked_at`,
+		p.UserID,
+		p.Code,
+		p.Progress,
+		p.Target,
+		p.UnlockedAt,
+	).Scan(&p.Progress, &p.UnlockedAt); err != nil {
+		if r.store.dialect.IsForeignKeyViolation(err) {
+			return store.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// FindProgress func. Finding user's progress of all achievements
+func (r *AchievementRepository) FindProgress(ctx context.Context, userID int) ([]*model.AchievementProgress, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		`SELECT user_id, code, progress, target, unlocked_at FROM achievement_progress
+		WHERE user_id = $1 ORDER BY code`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	progress := []*model.AchievementProgress{}
+	for rows.Next() {
+		p := &model.AchievementProgress{}
+		if err := rows.Scan(&p.UserID, &p.Code, &p.Progress, &p.Target, &p.UnlockedAt); err != nil {
+			return nil, err
+		}
+		progress = append(progress, p)
+	}
+
+	return progress, rows.Err()
+}
+
+// Evaluated func. Finding criteria fingerprint the achievement was
+// last evaluated for. Empty fingerprint means it never was.
+func (r *AchievementRepository) Evaluated(ctx context.Context, code string) (string, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	var fingerprint string
+	err := r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT fingerprint FROM achievement_evaluations WHERE code = $1",
+		code,
+	).Scan(&fingerprint)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+
+	return fingerprint, err
+}
+
+// SetEvaluated func. Writing criteria fingerprint the achievement was evaluated for
+func (r *AchievementRepository) SetEvaluated(ctx context.Context, code, fingerprint string) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.store.conn().ExecContext(
+		ctx,
+		`INSERT INTO achievement_evaluations (code, fingerprint, evaluated_at) VALUES ($1, $2, $3)
+		ON CONFLICT (code) DO UPDATE SET fingerprint = EXCLUDED.fingerprint, evaluated_at = EXCLUDED.evaluated_at`,
+		code,
+		fingerprint,
+		time.Now(),
+	)
+
+	return err
+}
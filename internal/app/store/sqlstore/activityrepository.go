@@ -0,0 +1,86 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// ActivityRepository object for storing activities users show to
+// their friends
+type ActivityRepository struct {
+	store *Store
+}
+
+// Create func. Writing imported activity in DB
+func (r *ActivityRepository) Create(ctx context.Context, a *model.Activity) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	a.BeforeCreate()
+
+	gameID := sql.NullInt64{Int64: int64(a.GameID), Valid: a.GameID != 0}
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		`INSERT INTO activities (user_id, type, game_id, text, link, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		a.UserID,
+		a.Type,
+		gameID,
+		a.Text,
+		a.Link,
+		a.CreatedAt,
+	).Scan(&a.ID); err != nil {
+		if r.store.dialect.IsForeignKeyViolation(err) {
+			return store.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// FindByUsers func. Finding the page of activities of the users older
+// than the activity with beforeID, newest first
+func (r *ActivityRepository) FindByUsers(ctx context.Context, userIDs []int, beforeID, limit int) ([]*model.Activity, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		`SELECT id, user_id, type, coalesce(game_id, 0), text, link, created_at FROM activities
+		WHERE `+r.store.dialect.AnyOf("user_id", "$1")+` AND ($2 = 0 OR id < $2) ORDER BY id DESC LIMIT $3`,
+		r.store.dialect.Ints(&userIDs),
+		beforeID,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activities := []*model.Activity{}
+	for rows.Next() {
+		a := &model.Activity{}
+		if err := rows.Scan(
+			&a.ID,
+			&a.UserID,
+			&a.Type,
+			&a.GameID,
+			&a.Text,
+			&a.Link,
+			&a.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		activities = append(activities, a)
+	}
+
+	return activities, rows.Err()
+}
@@ -0,0 +1,57 @@
+package sqlstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActivityRepository_FindByUsers(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+	u3 := model.TestUser(t)
+	u3.Email = "user3@example.org"
+	s.User().Create(context.Background(), u3)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	assert.EqualError(t, s.Activity().Create(context.Background(), model.TestActivity(t, u1.ID, g.ID+1)), store.ErrRecordNotFound.Error())
+
+	a := model.TestActivity(t, u1.ID, 0)
+	a.Type = model.ActivityAchievementUnlocked
+	assert.NoError(t, s.Activity().Create(context.Background(), a))
+	assert.NotZero(t, a.ID)
+	s.Activity().Create(context.Background(), model.TestActivity(t, u2.ID, g.ID))
+	s.Activity().Create(context.Background(), model.TestActivity(t, u3.ID, g.ID))
+	s.Activity().Create(context.Background(), model.TestActivity(t, u1.ID, g.ID))
+
+	activities, err := s.Activity().FindByUsers(context.Background(), []int{u1.ID, u2.ID}, 0, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, activities, 2) {
+		assert.Equal(t, u1.ID, activities[0].UserID)
+		assert.Equal(t, g.ID, activities[0].GameID)
+		assert.Equal(t, u2.ID, activities[1].UserID)
+	}
+
+	activities, err = s.Activity().FindByUsers(context.Background(), []int{u1.ID, u2.ID}, activities[1].ID, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, activities, 1) {
+		assert.Equal(t, a.ID, activities[0].ID)
+		assert.Zero(t, activities[0].GameID)
+	}
+
+	activities, err = s.Activity().FindByUsers(context.Background(), nil, 0, 2)
+	assert.NoError(t, err)
+	assert.Len(t, activities, 0)
+}
@@ -0,0 +1,118 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// ChatRepository object for storing chat messages
+type ChatRepository struct {
+	store *Store
+}
+
+// Create func. Writing imported chat message in DB
+func (r *ChatRepository) Create(ctx context.Context, m *model.ChatMessage) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	m.BeforeCreate()
+
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		"INSERT INTO chat_messages (room, user_id, text, created_at) VALUES ($1, $2, $3, $4) RETURNING id",
+		m.Room,
+		m.UserID,
+		m.Text,
+		m.CreatedAt,
+	).Scan(&m.ID); err != nil {
+		if r.store.dialect.IsForeignKeyViolation(err) {
+			return store.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Find func. Finding chat message with the right (id we need) id
+func (r *ChatRepository) Find(ctx context.Context, id int) (*model.ChatMessage, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	m := &model.ChatMessage{}
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT id, room, user_id, text, created_at FROM chat_messages WHERE id = $1",
+		id,
+	).Scan(
+		&m.ID,
+		&m.Room,
+		&m.UserID,
+		&m.Text,
+		&m.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Delete func. Deleting chat message with the id from DB
+func (r *ChatRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.store.conn().ExecContext(ctx, "DELETE FROM chat_messages WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res)
+}
+
+// FindByRoom func. Finding a page of messages of the chat room written
+// before the message with beforeID, newest first.
+func (r *ChatRepository) FindByRoom(ctx context.Context, room string, beforeID, limit int) ([]*model.ChatMessage, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		`SELECT id, room, user_id, text, created_at FROM chat_messages
+		WHERE room = $1 AND ($2 = 0 OR id < $2) ORDER BY id DESC LIMIT $3`,
+		room,
+		beforeID,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []*model.ChatMessage{}
+	for rows.Next() {
+		m := &model.ChatMessage{}
+		if err := rows.Scan(
+			&m.ID,
+			&m.Room,
+			&m.UserID,
+			&m.Text,
+			&m.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, rows.Err()
+}
@@ -0,0 +1,19 @@
+package sqlstore_test
+
+import (
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlstore"
+	"github.com/GShamian/tavern-of-games/internal/app/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (store.Store, func()) {
+		db, teardown := sqlstore.TestDB(t, databaseURL)
+
+		return sqlstore.New(db, queryTimeout), func() {
+			teardown("users", "games", "guilds", "achievement_evaluations")
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Dialect interface. Everything the store does differently on postgres
+// and SQLite. Queries are written for postgres, with $N params, and
+// rebound to the params of the DB before they run.
+type Dialect interface {
+	// Rebind func. Returns the query with params of the DB
+	Rebind(query string) string
+	// Lock func. Returns the suffix of SELECT locking the rows it finds
+	// until the transaction ends
+	Lock() string
+	// Ints func. Returns query arg and scan destination of ints kept in
+	// an array column
+	Ints(ids *[]int) interface{}
+	// AnyOf func. Returns condition that the column equals one of ints
+	// of the array param
+	AnyOf(column, param string) string
+	// Epoch func. Returns time since the Unix epoch of the time
+	// expression as an integer count of the smallest units of time of
+	// the DB, so times can be added up and compared exactly
+	Epoch(expr string) string
+	// MoveBucket func. Returns assignment moving one count of the array
+	// column from the bucket of the from param to the bucket of the to
+	// param, empty params are skipped. Buckets start from 1.
+	MoveBucket(column, from, to string) string
+	// TxOptions func. Returns options of transactions of WithTx
+	TxOptions() *sql.TxOptions
+	// IsRetryable func. Checks that the transaction failed with err can
+	// be run once more, e.g. it failed to serialize
+	IsRetryable(err error) bool
+	// IsUniqueViolation func. Checks that err is a broken unique
+	// constraint or primary key
+	IsUniqueViolation(err error) bool
+	// IsForeignKeyViolation func. Checks that err is a broken foreign key
+	IsForeignKeyViolation(err error) bool
+}
+
+// conn object. Runs queries of the DB or the transaction rebound by the
+// dialect, with times of args converted to UTC. SQLite keeps times as
+// text, which is only ordered the way times are if they're all in the
+// same time zone.
+type conn struct {
+	q       queryer
+	dialect Dialect
+}
+
+// ExecContext func. Executes the query
+func (c conn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.q.ExecContext(ctx, c.dialect.Rebind(query), utc(args)...)
+}
+
+// QueryContext func. Runs the query
+func (c conn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.q.QueryContext(ctx, c.dialect.Rebind(query), utc(args)...)
+}
+
+// QueryRowContext func. Runs the query expecting one row
+func (c conn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.q.QueryRowContext(ctx, c.dialect.Rebind(query), utc(args)...)
+}
+
+// utc func. Converting times of query args to UTC, nil times stay NULL
+func utc(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			converted[i] = v.UTC()
+		case *time.Time:
+			if v != nil {
+				converted[i] = v.UTC()
+			}
+		case sql.NullTime:
+			if v.Valid {
+				converted[i] = v.Time.UTC()
+			}
+		default:
+			converted[i] = arg
+		}
+	}
+
+	return converted
+}
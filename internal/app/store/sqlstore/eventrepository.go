@@ -0,0 +1,305 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+const eventColumns = `id, host_id, game_id, title, description, starts_at, ends_at, timezone,
+	capacity, recurrence, repeat_until, created_at`
+
+// EventRepository object for storing events and RSVPs to them
+type EventRepository struct {
+	store *Store
+}
+
+// Create func. Writing imported event in DB
+func (r *EventRepository) Create(ctx context.Context, e *model.Event) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	e.BeforeCreate()
+
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		`INSERT INTO events (host_id, game_id, title, description, starts_at, ends_at, timezone,
+		capacity, recurrence, repeat_until, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`,
+		e.HostID,
+		e.GameID,
+		e.Title,
+		e.Description,
+		e.StartsAt,
+		e.EndsAt,
+		e.Timezone,
+		e.Capacity,
+		e.Recurrence,
+		e.RepeatUntil,
+		e.CreatedAt,
+	).Scan(&e.ID); err != nil {
+		if r.store.dialect.IsForeignKeyViolation(err) {
+			return store.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Find func. Finding event with the right (id we need) id
+func (r *EventRepository) Find(ctx context.Context, id int) (*model.Event, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.find(ctx, r.store.conn(), id, false)
+}
+
+// FindUpcoming func. Finding the page of events which aren't over at
+// the time, soonest first
+func (r *EventRepository) FindUpcoming(ctx context.Context, now time.Time, q *store.ListQuery) ([]*model.Event, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	// The last occurrence of a recurring event ends as long after its
+	// repeat_until as the event lasts
+	d := r.store.dialect
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		"SELECT "+eventColumns+` FROM events
+		WHERE CASE
+			WHEN recurrence = '' THEN ends_at > $1
+			ELSE repeat_until IS NULL
+				OR `+d.Epoch("repeat_until")+` + `+d.Epoch("ends_at")+` - `+d.Epoch("starts_at")+` > `+d.Epoch("$1")+`
+		END
+		ORDER BY starts_at, id LIMIT $2 OFFSET $3`,
+		now,
+		q.Limit,
+		q.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scanAll(rows)
+}
+
+// FindByAttendee func. Finding events the user goes to, maybe goes to
+// or waits for, soonest first
+func (r *EventRepository) FindByAttendee(ctx context.Context, userID int) ([]*model.Event, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		"SELECT "+eventColumns+` FROM events WHERE id IN (
+			SELECT event_id FROM event_rsvps WHERE user_id = $1 AND status <> $2
+		) ORDER BY starts_at, id`,
+		userID,
+		model.RSVPDeclined,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.scanAll(rows)
+}
+
+// RSVP func. Applying RSVP of the user with the status to the event.
+// The event is locked while its RSVPs are changed.
+func (r *EventRepository) RSVP(ctx context.Context, eventID, userID int, status string) ([]*model.EventRSVP, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	var changed []*model.EventRSVP
+	err := r.store.transact(ctx, func(tx queryer) error {
+		e, err := r.find(ctx, tx, eventID, true)
+		if err != nil {
+			return err
+		}
+
+		rsvps, err := r.rsvps(ctx, tx, eventID)
+		if err != nil {
+			return err
+		}
+
+		changed, err = e.ApplyRSVP(rsvps, userID, status, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+
+		for _, rsvp := range changed {
+			if _, err := tx.ExecContext(
+				ctx,
+				`INSERT INTO event_rsvps (event_id, user_id, status, updated_at) VALUES ($1, $2, $3, $4)
+				ON CONFLICT (event_id, user_id) DO UPDATE SET status = $3, updated_at = $4`,
+				rsvp.EventID,
+				rsvp.UserID,
+				rsvp.Status,
+				rsvp.UpdatedAt,
+			); err != nil {
+				if r.store.dialect.IsForeignKeyViolation(err) {
+					return store.ErrRecordNotFound
+				}
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+// RSVPs func. Finding RSVPs to the event in order they were changed
+func (r *EventRepository) RSVPs(ctx context.Context, eventID int) ([]*model.EventRSVP, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.rsvps(ctx, r.store.conn(), eventID)
+}
+
+// SetCalendarToken func. Writing calendar token of the user in DB
+// instead of the old one
+func (r *EventRepository) SetCalendarToken(ctx context.Context, t *model.CalendarToken) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.store.conn().ExecContext(
+		ctx,
+		`INSERT INTO calendar_tokens (user_id, hash, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET hash = EXCLUDED.hash, created_at = EXCLUDED.created_at`,
+		t.UserID,
+		t.Hash,
+		t.CreatedAt,
+	); err != nil {
+		if r.store.dialect.IsForeignKeyViolation(err) {
+			return store.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// RevokeCalendarToken func. Deleting calendar token of the user from DB
+func (r *EventRepository) RevokeCalendarToken(ctx context.Context, userID int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.store.conn().ExecContext(ctx, "DELETE FROM calendar_tokens WHERE user_id = $1", userID)
+
+	return err
+}
+
+// FindCalendarToken func. Finding calendar token with the hash
+func (r *EventRepository) FindCalendarToken(ctx context.Context, hash string) (*model.CalendarToken, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	t := &model.CalendarToken{}
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT user_id, hash, created_at FROM calendar_tokens WHERE hash = $1",
+		hash,
+	).Scan(&t.UserID, &t.Hash, &t.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// rsvps func. Finding RSVPs to the event with the queryer
+func (r *EventRepository) rsvps(ctx context.Context, q queryer, eventID int) ([]*model.EventRSVP, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT event_id, user_id, status, updated_at FROM event_rsvps
+		WHERE event_id = $1 ORDER BY updated_at, user_id`,
+		eventID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rsvps := []*model.EventRSVP{}
+	for rows.Next() {
+		rsvp := &model.EventRSVP{}
+		if err := rows.Scan(&rsvp.EventID, &rsvp.UserID, &rsvp.Status, &rsvp.UpdatedAt); err != nil {
+			return nil, err
+		}
+		rsvps = append(rsvps, rsvp)
+	}
+
+	return rsvps, rows.Err()
+}
+
+// find func. Finding event with the id, locking its row if needed
+func (r *EventRepository) find(ctx context.Context, q queryer, id int, lock bool) (*model.Event, error) {
+	query := "SELECT " + eventColumns + " FROM events WHERE id = $1"
+	if lock {
+		query += r.store.dialect.Lock()
+	}
+
+	return r.scan(q.QueryRowContext(ctx, query, id))
+}
+
+// scanAll func. Scanning events from rows and closing them
+func (r *EventRepository) scanAll(rows *sql.Rows) ([]*model.Event, error) {
+	defer rows.Close()
+
+	events := []*model.Event{}
+	for rows.Next() {
+		e, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// scan func. Scanning event columns from row
+func (r *EventRepository) scan(row interface{ Scan(...interface{}) error }) (*model.Event, error) {
+	e := &model.Event{}
+	var until sql.NullTime
+	if err := row.Scan(
+		&e.ID,
+		&e.HostID,
+		&e.GameID,
+		&e.Title,
+		&e.Description,
+		&e.StartsAt,
+		&e.EndsAt,
+		&e.Timezone,
+		&e.Capacity,
+		&e.Recurrence,
+		&until,
+		&e.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	if until.Valid {
+		e.RepeatUntil = &until.Time
+	}
+
+	return e, nil
+}
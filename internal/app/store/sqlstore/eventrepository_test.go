@@ -0,0 +1,151 @@
+package sqlstore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventRepository_FindUpcoming(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	assert.EqualError(t, s.Event().Create(context.Background(), model.TestEvent(t, u.ID, g.ID+1)), store.ErrRecordNotFound.Error())
+
+	e1 := model.TestEvent(t, u.ID, g.ID)
+	e1.Recurrence = ""
+	assert.NoError(t, s.Event().Create(context.Background(), e1))
+	assert.NotZero(t, e1.ID)
+
+	e2 := model.TestEvent(t, u.ID, g.ID)
+	e2.StartsAt = e1.StartsAt.Add(-time.Hour)
+	until := e2.StartsAt.Add(7 * 24 * time.Hour)
+	e2.RepeatUntil = &until
+	s.Event().Create(context.Background(), e2)
+
+	e, err := s.Event().Find(context.Background(), e2.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", e.Timezone)
+	if assert.NotNil(t, e.RepeatUntil) {
+		assert.True(t, until.Equal(*e.RepeatUntil))
+	}
+
+	q := &store.ListQuery{Limit: 10}
+	events, err := s.Event().FindUpcoming(context.Background(), time.Now(), q)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, e2.ID, events[0].ID)
+	}
+
+	events, err = s.Event().FindUpcoming(context.Background(), e1.EndsAt, q)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, e2.ID, events[0].ID)
+	}
+}
+
+func TestEventRepository_RSVP(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+	u3 := model.TestUser(t)
+	u3.Email = "user3@example.org"
+	s.User().Create(context.Background(), u3)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+	e := model.TestEvent(t, u1.ID, g.ID)
+	e.Capacity = 1
+	s.Event().Create(context.Background(), e)
+
+	_, err := s.Event().RSVP(context.Background(), e.ID+1, u1.ID, model.RSVPGoing)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	changed, err := s.Event().RSVP(context.Background(), e.ID, u1.ID, model.RSVPGoing)
+	assert.NoError(t, err)
+	if assert.Len(t, changed, 1) {
+		assert.Equal(t, model.RSVPGoing, changed[0].Status)
+	}
+
+	changed, err = s.Event().RSVP(context.Background(), e.ID, u2.ID, model.RSVPGoing)
+	assert.NoError(t, err)
+	if assert.Len(t, changed, 1) {
+		assert.Equal(t, model.RSVPWaitlisted, changed[0].Status)
+	}
+
+	s.Event().RSVP(context.Background(), e.ID, u3.ID, model.RSVPMaybe)
+
+	events, err := s.Event().FindByAttendee(context.Background(), u2.ID)
+	assert.NoError(t, err)
+	assert.Len(t, events, 1)
+
+	changed, err = s.Event().RSVP(context.Background(), e.ID, u1.ID, model.RSVPDeclined)
+	assert.NoError(t, err)
+	if assert.Len(t, changed, 2) {
+		assert.Equal(t, u2.ID, changed[1].UserID)
+		assert.Equal(t, model.RSVPGoing, changed[1].Status)
+	}
+
+	rsvps, err := s.Event().RSVPs(context.Background(), e.ID)
+	assert.NoError(t, err)
+	statuses := map[int]string{}
+	for _, rsvp := range rsvps {
+		statuses[rsvp.UserID] = rsvp.Status
+	}
+	assert.Equal(t, map[int]string{
+		u1.ID: model.RSVPDeclined,
+		u2.ID: model.RSVPGoing,
+		u3.ID: model.RSVPMaybe,
+	}, statuses)
+
+	events, err = s.Event().FindByAttendee(context.Background(), u1.ID)
+	assert.NoError(t, err)
+	assert.Len(t, events, 0)
+}
+
+func TestEventRepository_CalendarToken(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users")
+
+	s := sqlstore.New(db, queryTimeout)
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+
+	missing, _ := model.NewCalendarToken(u.ID + 1)
+	assert.EqualError(t, s.Event().SetCalendarToken(context.Background(), missing), store.ErrRecordNotFound.Error())
+
+	old, err := model.NewCalendarToken(u.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Event().SetCalendarToken(context.Background(), old))
+	found, err := s.Event().FindCalendarToken(context.Background(), model.HashCalendarToken(old.Token))
+	assert.NoError(t, err)
+	assert.Equal(t, u.ID, found.UserID)
+
+	// A new token replaces the old one
+	token, _ := model.NewCalendarToken(u.ID)
+	assert.NoError(t, s.Event().SetCalendarToken(context.Background(), token))
+	_, err = s.Event().FindCalendarToken(context.Background(), old.Hash)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	_, err = s.Event().FindCalendarToken(context.Background(), token.Hash)
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Event().RevokeCalendarToken(context.Background(), u.ID))
+	_, err = s.Event().FindCalendarToken(context.Background(), token.Hash)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
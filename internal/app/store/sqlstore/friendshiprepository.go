@@ -0,0 +1,286 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// FriendshipRepository object for storing friendships and blocks
+type FriendshipRepository struct {
+	store *Store
+}
+
+// Find func. Finding friendship between two users in any direction
+func (r *FriendshipRepository) Find(ctx context.Context, userID, otherID int) (*model.Friendship, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.find(ctx, r.store.conn(), userID, otherID, "")
+}
+
+// Apply func. Applying friendship action of the actor to the friendship
+// with other user in one transaction. Returns the new friendship, which
+// is nil if the friendship was removed.
+func (r *FriendshipRepository) Apply(ctx context.Context, actorID, otherID int, action string) (*model.Friendship, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	var next *model.Friendship
+	err := r.store.transact(ctx, func(tx queryer) error {
+		if action == model.FriendshipRequest {
+			blocked, err := r.isBlocked(ctx, tx, actorID, otherID)
+			if err != nil {
+				return err
+			}
+
+			if blocked {
+				return store.ErrRecordNotFound
+			}
+		}
+
+		f, err := r.find(ctx, tx, actorID, otherID, r.store.dialect.Lock())
+		if err != nil && err != store.ErrRecordNotFound {
+			return err
+		}
+
+		next, err = model.NextFriendship(f, actorID, otherID, action)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case f == nil:
+			_, err = tx.ExecContext(
+				ctx,
+				`INSERT INTO friendships (user_id, friend_id, status, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5)`,
+				next.UserID,
+				next.FriendID,
+				next.Status,
+				next.CreatedAt,
+				next.UpdatedAt,
+			)
+			switch {
+			case r.store.dialect.IsUniqueViolation(err):
+				return store.ErrRecordExists
+			case r.store.dialect.IsForeignKeyViolation(err):
+				return store.ErrRecordNotFound
+			}
+		case next == nil:
+			_, err = tx.ExecContext(
+				ctx,
+				"DELETE FROM friendships WHERE user_id = $1 AND friend_id = $2",
+				f.UserID,
+				f.FriendID,
+			)
+		default:
+			_, err = tx.ExecContext(
+				ctx,
+				"UPDATE friendships SET status = $3, updated_at = $4 WHERE user_id = $1 AND friend_id = $2",
+				next.UserID,
+				next.FriendID,
+				next.Status,
+				next.UpdatedAt,
+			)
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return next, nil
+}
+
+// FindByUser func. Finding all friendships of the user with the status
+func (r *FriendshipRepository) FindByUser(ctx context.Context, userID int, status string) ([]*model.Friendship, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		`SELECT user_id, friend_id, status, created_at, updated_at FROM friendships
+		WHERE (user_id = $1 OR friend_id = $1) AND status = $2 ORDER BY updated_at DESC`,
+		userID,
+		status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	friendships := []*model.Friendship{}
+	for rows.Next() {
+		f := &model.Friendship{}
+		if err := rows.Scan(
+			&f.UserID,
+			&f.FriendID,
+			&f.Status,
+			&f.CreatedAt,
+			&f.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		friendships = append(friendships, f)
+	}
+
+	return friendships, rows.Err()
+}
+
+// Mutual func. Finding ids of users who are friends of both users
+func (r *FriendshipRepository) Mutual(ctx context.Context, userID, otherID int) ([]int, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		`WITH friends AS (
+			SELECT user_id AS owner_id, friend_id AS id FROM friendships WHERE status = $3
+			UNION ALL
+			SELECT friend_id, user_id FROM friendships WHERE status = $3
+		)
+		SELECT a.id FROM friends a JOIN friends b ON a.id = b.id
+		WHERE a.owner_id = $1 AND b.owner_id = $2 ORDER BY a.id`,
+		userID,
+		otherID,
+		model.FriendshipAccepted,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanIDs(rows)
+}
+
+// Block func. Writing a block of the blocked user by the blocker and
+// removing any friendship between them in one transaction.
+func (r *FriendshipRepository) Block(ctx context.Context, blockerID, blockedID int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if blockerID == blockedID {
+		return model.ErrInvalidFriendshipTransition
+	}
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		if _, err := tx.ExecContext(
+			ctx,
+			`DELETE FROM friendships WHERE (user_id = $1 AND friend_id = $2)
+			OR (user_id = $2 AND friend_id = $1)`,
+			blockerID,
+			blockedID,
+		); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO blocks (blocker_id, blocked_id) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+			blockerID,
+			blockedID,
+		); err != nil {
+			if r.store.dialect.IsForeignKeyViolation(err) {
+				return store.ErrRecordNotFound
+			}
+			return err
+		}
+
+		return nil
+	})
+}
+
+// Unblock func. Deleting a block of the blocked user by the blocker
+func (r *FriendshipRepository) Unblock(ctx context.Context, blockerID, blockedID int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.store.conn().ExecContext(
+		ctx,
+		"DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2",
+		blockerID,
+		blockedID,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Blocked func. Finding ids of users blocked by the blocker
+func (r *FriendshipRepository) Blocked(ctx context.Context, blockerID int) ([]int, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		"SELECT blocked_id FROM blocks WHERE blocker_id = $1 ORDER BY created_at DESC",
+		blockerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanIDs(rows)
+}
+
+// IsBlocked func. Checks if any of two users blocked the other one
+func (r *FriendshipRepository) IsBlocked(ctx context.Context, userID, otherID int) (bool, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.isBlocked(ctx, r.store.conn(), userID, otherID)
+}
+
+// find func. Finding friendship between two users in any direction with q.
+// Suffix is appended to the query, e.g. to lock the row.
+func (r *FriendshipRepository) find(ctx context.Context, q queryer, userID, otherID int, suffix string) (*model.Friendship, error) {
+	f := &model.Friendship{}
+	if err := q.QueryRowContext(
+		ctx,
+		`SELECT user_id, friend_id, status, created_at, updated_at FROM friendships
+		WHERE (user_id = $1 AND friend_id = $2) OR (user_id = $2 AND friend_id = $1)`+suffix,
+		userID,
+		otherID,
+	).Scan(
+		&f.UserID,
+		&f.FriendID,
+		&f.Status,
+		&f.CreatedAt,
+		&f.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// isBlocked func. Checks if any of two users blocked the other one with q
+func (r *FriendshipRepository) isBlocked(ctx context.Context, q queryer, userID, otherID int) (bool, error) {
+	var blocked bool
+	err := q.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM blocks WHERE (blocker_id = $1 AND blocked_id = $2)
+		OR (blocker_id = $2 AND blocked_id = $1))`,
+		userID,
+		otherID,
+	).Scan(&blocked)
+
+	return blocked, err
+}
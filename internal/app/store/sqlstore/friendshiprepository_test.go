@@ -0,0 +1,111 @@
+package sqlstore_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFriendshipRepository_Apply(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users")
+
+	s := sqlstore.New(db, queryTimeout)
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+
+	_, err := s.Friendship().Apply(context.Background(), u1.ID, u2.ID, model.FriendshipAccept)
+	assert.EqualError(t, err, model.ErrInvalidFriendshipTransition.Error())
+
+	f, err := s.Friendship().Apply(context.Background(), u1.ID, u2.ID, model.FriendshipRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, model.FriendshipPending, f.Status)
+
+	f, err = s.Friendship().Apply(context.Background(), u2.ID, u1.ID, model.FriendshipAccept)
+	assert.NoError(t, err)
+	assert.Equal(t, model.FriendshipAccepted, f.Status)
+
+	f, err = s.Friendship().Find(context.Background(), u2.ID, u1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.FriendshipAccepted, f.Status)
+
+	f, err = s.Friendship().Apply(context.Background(), u1.ID, u2.ID, model.FriendshipUnfriend)
+	assert.NoError(t, err)
+	assert.Nil(t, f)
+	_, err = s.Friendship().Find(context.Background(), u1.ID, u2.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	_, err = s.Friendship().Apply(context.Background(), u1.ID, u2.ID+1, model.FriendshipRequest)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
+
+func TestFriendshipRepository_Mutual(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users")
+
+	s := sqlstore.New(db, queryTimeout)
+	users := make([]*model.User, 4)
+	for i := range users {
+		users[i] = model.TestUser(t)
+		users[i].Email = fmt.Sprintf("user%d@example.org", i)
+		s.User().Create(context.Background(), users[i])
+	}
+
+	befriend := func(a, b *model.User) {
+		s.Friendship().Apply(context.Background(), a.ID, b.ID, model.FriendshipRequest)
+		s.Friendship().Apply(context.Background(), b.ID, a.ID, model.FriendshipAccept)
+	}
+	befriend(users[0], users[2])
+	befriend(users[1], users[2])
+	befriend(users[0], users[3])
+	s.Friendship().Apply(context.Background(), users[1].ID, users[3].ID, model.FriendshipRequest)
+
+	ids, err := s.Friendship().Mutual(context.Background(), users[0].ID, users[1].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{users[2].ID}, ids)
+
+	friendships, err := s.Friendship().FindByUser(context.Background(), users[0].ID, model.FriendshipAccepted)
+	assert.NoError(t, err)
+	assert.Len(t, friendships, 2)
+}
+
+func TestFriendshipRepository_Block(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users")
+
+	s := sqlstore.New(db, queryTimeout)
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+
+	s.Friendship().Apply(context.Background(), u1.ID, u2.ID, model.FriendshipRequest)
+	assert.NoError(t, s.Friendship().Block(context.Background(), u2.ID, u1.ID))
+	_, err := s.Friendship().Find(context.Background(), u1.ID, u2.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	blocked, err := s.Friendship().IsBlocked(context.Background(), u1.ID, u2.ID)
+	assert.NoError(t, err)
+	assert.True(t, blocked)
+
+	ids, err := s.Friendship().Blocked(context.Background(), u2.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{u1.ID}, ids)
+
+	_, err = s.Friendship().Apply(context.Background(), u1.ID, u2.ID, model.FriendshipRequest)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	assert.NoError(t, s.Friendship().Unblock(context.Background(), u2.ID, u1.ID))
+	assert.EqualError(t, s.Friendship().Unblock(context.Background(), u2.ID, u1.ID), store.ErrRecordNotFound.Error())
+	blocked, _ = s.Friendship().IsBlocked(context.Background(), u1.ID, u2.ID)
+	assert.False(t, blocked)
+}
@@ -0,0 +1,61 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// GameRepository object for storing game entities
+type GameRepository struct {
+	store *Store
+}
+
+// Create func. Writing a title of imported Game in DB. Aggregated
+// rating of the new game starts empty.
+func (r *GameRepository) Create(ctx context.Context, g *model.Game) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	g.BeforeCreate()
+
+	return r.store.conn().QueryRowContext(
+		ctx,
+		"INSERT INTO games (title) VALUES ($1) RETURNING id",
+		g.Title,
+	).Scan(&g.ID)
+}
+
+// Find func. Finding game with the right (id we need) id
+func (r *GameRepository) Find(ctx context.Context, id int) (*model.Game, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	g := &model.Game{}
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT id, title, rating_count, rating_sum, rating_histogram FROM games WHERE id = $1",
+		id,
+	).Scan(
+		&g.ID,
+		&g.Title,
+		&g.RatingCount,
+		&g.RatingSum,
+		r.store.dialect.Ints(&g.RatingHistogram),
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	g.ComputeRatingMean()
+
+	return g, nil
+}
@@ -0,0 +1,36 @@
+package sqlstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameRepository_Create(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("games")
+
+	s := sqlstore.New(db, queryTimeout)
+	g := model.TestGame(t)
+	assert.NoError(t, s.Game().Create(context.Background(), g))
+	assert.NotZero(t, g.ID)
+}
+
+func TestGameRepository_Find(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("games")
+
+	s := sqlstore.New(db, queryTimeout)
+	_, err := s.Game().Find(context.Background(), 1)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	g1 := model.TestGame(t)
+	s.Game().Create(context.Background(), g1)
+	g2, err := s.Game().Find(context.Background(), g1.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, g2)
+}
@@ -0,0 +1,603 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+const guildColumns = `id, name, tag, description, emblem_url, created_at,
+	(SELECT count(*) FROM guild_members WHERE guild_id = guilds.id)`
+
+// rankedGuilds query. Numbering guilds by mean rating of their members
+// in the game during the season
+const rankedGuilds = `SELECT g.id, g.name, g.tag, avg(r.rating), count(*),
+	ROW_NUMBER() OVER (ORDER BY avg(r.rating) DESC, g.id) AS rank
+	FROM guilds g
+	JOIN guild_members m ON m.guild_id = g.id
+	JOIN ratings r ON r.user_id = m.user_id
+	WHERE r.game_id = $1 AND r.season = $2
+	GROUP BY g.id`
+
+// GuildRepository object for storing guilds, their members and invites
+type GuildRepository struct {
+	store *Store
+}
+
+// Create func. Writing imported guild in DB together with membership
+// of its leader. Names and tags of guilds are unique.
+func (r *GuildRepository) Create(ctx context.Context, g *model.Guild, leaderID int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	g.BeforeCreate()
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		if err := tx.QueryRowContext(
+			ctx,
+			`INSERT INTO guilds (name, tag, description, emblem_url, created_at)
+			VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+			g.Name,
+			g.Tag,
+			g.Description,
+			g.EmblemURL,
+			g.CreatedAt,
+		).Scan(&g.ID); err != nil {
+			if r.store.dialect.IsUniqueViolation(err) {
+				return store.ErrRecordExists
+			}
+			return err
+		}
+
+		if err := r.join(ctx, tx, &model.GuildMember{
+			GuildID:  g.ID,
+			UserID:   leaderID,
+			Role:     model.GuildRoleLeader,
+			JoinedAt: g.CreatedAt,
+		}); err != nil {
+			return err
+		}
+		g.MemberCount = 1
+
+		return nil
+	})
+}
+
+// Find func. Finding guild with the right (id we need) id
+func (r *GuildRepository) Find(ctx context.Context, id int) (*model.Guild, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.scan(r.store.conn().QueryRowContext(ctx, "SELECT "+guildColumns+" FROM guilds WHERE id = $1", id))
+}
+
+// List func. Finding the page of guilds ordered by name
+func (r *GuildRepository) List(ctx context.Context, q *store.ListQuery) ([]*model.Guild, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		"SELECT "+guildColumns+" FROM guilds ORDER BY lower(name), id LIMIT $1 OFFSET $2",
+		q.Limit,
+		q.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	guilds := []*model.Guild{}
+	for rows.Next() {
+		g, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		guilds = append(guilds, g)
+	}
+
+	return guilds, rows.Err()
+}
+
+// Update func. Changing name, tag, description and emblem of the guild
+func (r *GuildRepository) Update(ctx context.Context, g *model.Guild) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	res, err := r.store.conn().ExecContext(
+		ctx,
+		"UPDATE guilds SET name = $2, tag = $3, description = $4, emblem_url = $5 WHERE id = $1",
+		g.ID,
+		g.Name,
+		g.Tag,
+		g.Description,
+		g.EmblemURL,
+	)
+	if err != nil {
+		if r.store.dialect.IsUniqueViolation(err) {
+			return store.ErrRecordExists
+		}
+		return err
+	}
+
+	return expectAffected(res)
+}
+
+// Delete func. Deleting the guild with its members and invites
+func (r *GuildRepository) Delete(ctx context.Context, id int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.store.conn().ExecContext(ctx, "DELETE FROM guilds WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res)
+}
+
+// Member func. Finding membership of the user in the guild
+func (r *GuildRepository) Member(ctx context.Context, guildID, userID int) (*model.GuildMember, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return scanGuildMember(r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT guild_id, user_id, role, joined_at FROM guild_members WHERE guild_id = $1 AND user_id = $2",
+		guildID,
+		userID,
+	))
+}
+
+// MemberOf func. Finding membership of the user in any guild
+func (r *GuildRepository) MemberOf(ctx context.Context, userID int) (*model.GuildMember, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return scanGuildMember(r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT guild_id, user_id, role, joined_at FROM guild_members WHERE user_id = $1",
+		userID,
+	))
+}
+
+// Members func. Finding members of the guild, the leader first,
+// then officers and members in order of joining
+func (r *GuildRepository) Members(ctx context.Context, guildID int) ([]*model.GuildMember, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		`SELECT guild_id, user_id, role, joined_at FROM guild_members WHERE guild_id = $1
+		ORDER BY CASE role WHEN 'leader' THEN 0 WHEN 'officer' THEN 1 ELSE 2 END, joined_at, user_id`,
+		guildID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*model.GuildMember{}
+	for rows.Next() {
+		m, err := scanGuildMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// RemoveMember func. Deleting the user from members of the guild. The
+// leader can't be removed.
+func (r *GuildRepository) RemoveMember(ctx context.Context, guildID, userID int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		m, err := r.lockMember(ctx, tx, guildID, userID)
+		if err != nil {
+			return err
+		}
+
+		if m.Role == model.GuildRoleLeader {
+			return model.ErrGuildLeader
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM guild_members WHERE guild_id = $1 AND user_id = $2", guildID, userID)
+
+		return err
+	})
+}
+
+// SetRole func. Changing role of the member of the guild. The leader
+// can't be demoted.
+func (r *GuildRepository) SetRole(ctx context.Context, m *model.GuildMember) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		current, err := r.lockMember(ctx, tx, m.GuildID, m.UserID)
+		if err != nil {
+			return err
+		}
+
+		if current.Role == model.GuildRoleLeader {
+			return model.ErrGuildLeader
+		}
+
+		if _, err := tx.ExecContext(
+			ctx,
+			"UPDATE guild_members SET role = $3 WHERE guild_id = $1 AND user_id = $2",
+			m.GuildID,
+			m.UserID,
+			m.Role,
+		); err != nil {
+			return err
+		}
+		m.JoinedAt = current.JoinedAt
+
+		return nil
+	})
+}
+
+// Transfer func. Making the member of the guild its leader. The old
+// leader becomes an officer.
+func (r *GuildRepository) Transfer(ctx context.Context, guildID, leaderID, userID int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		leader, err := r.lockMember(ctx, tx, guildID, leaderID)
+		if err != nil {
+			return err
+		}
+
+		if leader.Role != model.GuildRoleLeader {
+			return store.ErrRecordNotFound
+		}
+
+		if _, err := r.lockMember(ctx, tx, guildID, userID); err != nil {
+			return err
+		}
+
+		if leaderID == userID {
+			return nil
+		}
+		// Demoting the old leader first, so the guild never has two
+		if _, err := tx.ExecContext(
+			ctx,
+			"UPDATE guild_members SET role = $3 WHERE guild_id = $1 AND user_id = $2",
+			guildID,
+			leaderID,
+			model.GuildRoleOfficer,
+		); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			"UPDATE guild_members SET role = $3 WHERE guild_id = $1 AND user_id = $2",
+			guildID,
+			userID,
+			model.GuildRoleLeader,
+		)
+
+		return err
+	})
+}
+
+// Invite func. Writing imported invite in DB, or accepting the pending
+// opposite one. The user joins the guild in that case, and the rest of
+// the invites of the user are deleted.
+func (r *GuildRepository) Invite(ctx context.Context, i *model.GuildInvite) (*model.GuildMember, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := i.Validate(); err != nil {
+		return nil, err
+	}
+
+	i.BeforeCreate()
+
+	var m *model.GuildMember
+	err := r.store.transact(ctx, func(tx queryer) error {
+		// Locking the guild, so it can't be disbanded meanwhile
+		if err := tx.QueryRowContext(ctx, "SELECT id FROM guilds WHERE id = $1"+r.store.dialect.Lock(), i.GuildID).Scan(&i.GuildID); err != nil {
+			if err == sql.ErrNoRows {
+				return store.ErrRecordNotFound
+			}
+			return err
+		}
+
+		if _, err := scanGuildMember(tx.QueryRowContext(
+			ctx,
+			"SELECT guild_id, user_id, role, joined_at FROM guild_members WHERE user_id = $1",
+			i.UserID,
+		)); err != store.ErrRecordNotFound {
+			if err == nil {
+				return store.ErrRecordExists
+			}
+			return err
+		}
+
+		pending, err := scanGuildInvite(tx.QueryRowContext(
+			ctx,
+			`SELECT guild_id, user_id, kind, coalesce(inviter_id, 0), created_at FROM guild_invites
+			WHERE guild_id = $1 AND user_id = $2`+r.store.dialect.Lock(),
+			i.GuildID,
+			i.UserID,
+		))
+		if err == store.ErrRecordNotFound {
+			pending, err = nil, nil
+		}
+		if err != nil {
+			return err
+		}
+
+		accepts, err := model.AcceptsGuildInvite(pending, i)
+		if err != nil {
+			return err
+		}
+
+		if !accepts {
+			var inviterID interface{}
+			if i.InviterID != 0 {
+				inviterID = i.InviterID
+			}
+
+			if _, err := tx.ExecContext(
+				ctx,
+				`INSERT INTO guild_invites (guild_id, user_id, kind, inviter_id, created_at)
+				VALUES ($1, $2, $3, $4, $5)`,
+				i.GuildID,
+				i.UserID,
+				i.Kind,
+				inviterID,
+				i.CreatedAt,
+			); err != nil {
+				if r.store.dialect.IsForeignKeyViolation(err) {
+					return store.ErrRecordNotFound
+				}
+				return err
+			}
+
+			return nil
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM guild_invites WHERE user_id = $1", i.UserID); err != nil {
+			return err
+		}
+
+		m = &model.GuildMember{
+			GuildID:  i.GuildID,
+			UserID:   i.UserID,
+			Role:     model.GuildRoleMember,
+			JoinedAt: i.CreatedAt,
+		}
+
+		return r.join(ctx, tx, m)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// RemoveInvite func. Deleting pending invite or join request of the
+// user to the guild
+func (r *GuildRepository) RemoveInvite(ctx context.Context, guildID, userID int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.store.conn().ExecContext(
+		ctx,
+		"DELETE FROM guild_invites WHERE guild_id = $1 AND user_id = $2",
+		guildID,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res)
+}
+
+// Invites func. Finding pending invites of the guild, oldest first
+func (r *GuildRepository) Invites(ctx context.Context, guildID int) ([]*model.GuildInvite, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.findInvites(ctx, "guild_id", guildID)
+}
+
+// InvitesOf func. Finding pending invites of the user, oldest first
+func (r *GuildRepository) InvitesOf(ctx context.Context, userID int) ([]*model.GuildInvite, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.findInvites(ctx, "user_id", userID)
+}
+
+// Leaderboard func. Finding the page of the guild leaderboard of the
+// game during the season
+func (r *GuildRepository) Leaderboard(ctx context.Context, gameID int, season string, q *store.ListQuery) ([]*model.GuildLeaderboardEntry, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		"SELECT * FROM ("+rankedGuilds+") AS ranked ORDER BY rank LIMIT $3 OFFSET $4",
+		gameID,
+		season,
+		q.Limit,
+		q.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*model.GuildLeaderboardEntry{}
+	for rows.Next() {
+		e, err := scanGuildLeaderboardEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// Rank func. Finding the place of the guild in the guild leaderboard
+// of the game during the season
+func (r *GuildRepository) Rank(ctx context.Context, guildID, gameID int, season string) (*model.GuildLeaderboardEntry, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return scanGuildLeaderboardEntry(r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT * FROM ("+rankedGuilds+") AS ranked WHERE id = $3",
+		gameID,
+		season,
+		guildID,
+	))
+}
+
+// join func. Writing membership of the user in DB. Users who are
+// already members of a guild can't join another one.
+func (r *GuildRepository) join(ctx context.Context, tx queryer, m *model.GuildMember) error {
+	if _, err := tx.ExecContext(
+		ctx,
+		"INSERT INTO guild_members (guild_id, user_id, role, joined_at) VALUES ($1, $2, $3, $4)",
+		m.GuildID,
+		m.UserID,
+		m.Role,
+		m.JoinedAt,
+	); err != nil {
+		switch {
+		case r.store.dialect.IsUniqueViolation(err):
+			return store.ErrRecordExists
+		case r.store.dialect.IsForeignKeyViolation(err):
+			return store.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// lockMember func. Finding membership of the user in the guild and
+// locking its row
+func (r *GuildRepository) lockMember(ctx context.Context, tx queryer, guildID, userID int) (*model.GuildMember, error) {
+	return scanGuildMember(tx.QueryRowContext(
+		ctx,
+		`SELECT guild_id, user_id, role, joined_at FROM guild_members
+		WHERE guild_id = $1 AND user_id = $2`+r.store.dialect.Lock(),
+		guildID,
+		userID,
+	))
+}
+
+// findInvites func. Finding pending invites with the column equal to id
+func (r *GuildRepository) findInvites(ctx context.Context, column string, id int) ([]*model.GuildInvite, error) {
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		`SELECT guild_id, user_id, kind, coalesce(inviter_id, 0), created_at FROM guild_invites
+		WHERE `+column+` = $1 ORDER BY created_at, guild_id, user_id`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []*model.GuildInvite{}
+	for rows.Next() {
+		i, err := scanGuildInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, i)
+	}
+
+	return invites, rows.Err()
+}
+
+// scan func. Scanning guild columns from row
+func (r *GuildRepository) scan(row interface{ Scan(...interface{}) error }) (*model.Guild, error) {
+	g := &model.Guild{}
+	if err := row.Scan(
+		&g.ID,
+		&g.Name,
+		&g.Tag,
+		&g.Description,
+		&g.EmblemURL,
+		&g.CreatedAt,
+		&g.MemberCount,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// scanGuildMember func. Scanning guild member columns from row
+func scanGuildMember(row interface{ Scan(...interface{}) error }) (*model.GuildMember, error) {
+	m := &model.GuildMember{}
+	if err := row.Scan(&m.GuildID, &m.UserID, &m.Role, &m.JoinedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// scanGuildInvite func. Scanning guild invite columns from row
+func scanGuildInvite(row interface{ Scan(...interface{}) error }) (*model.GuildInvite, error) {
+	i := &model.GuildInvite{}
+	if err := row.Scan(&i.GuildID, &i.UserID, &i.Kind, &i.InviterID, &i.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// scanGuildLeaderboardEntry func. Scanning ranked guild columns from row
+func scanGuildLeaderboardEntry(row interface{ Scan(...interface{}) error }) (*model.GuildLeaderboardEntry, error) {
+	e := &model.GuildLeaderboardEntry{}
+	if err := row.Scan(&e.GuildID, &e.Name, &e.Tag, &e.Rating, &e.Players, &e.Rank); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return e, nil
+}
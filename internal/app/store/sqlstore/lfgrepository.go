@@ -0,0 +1,270 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+const lfgPostColumns = `id, user_id, game_id, platform, players, language, skill_min, skill_max,
+	voice_chat, description, starts_at, expires_at, status, created_at`
+
+// LFGRepository object for storing posts of the looking-for-group board
+type LFGRepository struct {
+	store *Store
+}
+
+// Create func. Writing imported LFG post in DB
+func (r *LFGRepository) Create(ctx context.Context, p *model.LFGPost) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	p.BeforeCreate()
+
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		`INSERT INTO lfg_posts (user_id, game_id, platform, players, language, skill_min, skill_max,
+		voice_chat, description, starts_at, expires_at, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id`,
+		p.UserID,
+		p.GameID,
+		p.Platform,
+		p.Players,
+		p.Language,
+		p.SkillMin,
+		p.SkillMax,
+		p.VoiceChat,
+		p.Description,
+		p.StartsAt,
+		p.ExpiresAt,
+		p.Status,
+		p.CreatedAt,
+	).Scan(&p.ID); err != nil {
+		if r.store.dialect.IsForeignKeyViolation(err) {
+			return store.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Find func. Finding LFG post with the right (id we need) id
+func (r *LFGRepository) Find(ctx context.Context, id int) (*model.LFGPost, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.find(ctx, r.store.conn(), id, false)
+}
+
+// FindOpen func. Finding the page of open posts matching the filter,
+// which haven't expired at the time, soonest games first
+func (r *LFGRepository) FindOpen(ctx context.Context, f *store.LFGFilter, now time.Time, q *store.ListQuery) ([]*model.LFGPost, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	// Casts give postgres the type of the time params, which are only
+	// checked for NULL there
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		"SELECT "+lfgPostColumns+` FROM lfg_posts
+		WHERE status = 'open' AND expires_at > $1
+		AND ($2 = 0 OR game_id = $2)
+		AND ($3 = '' OR platform = $3)
+		AND ($4 = '' OR language = $4)
+		AND (CAST($5 AS timestamptz) IS NULL OR starts_at >= $5)
+		AND (CAST($6 AS timestamptz) IS NULL OR starts_at <= $6)
+		ORDER BY starts_at, id LIMIT $7 OFFSET $8`,
+		now,
+		f.GameID,
+		f.Platform,
+		f.Language,
+		nullTime(f.From),
+		nullTime(f.To),
+		q.Limit,
+		q.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []*model.LFGPost{}
+	for rows.Next() {
+		p, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, p)
+	}
+
+	return posts, rows.Err()
+}
+
+// Close func. Closing the open post, so nobody can ask to join it anymore
+func (r *LFGRepository) Close(ctx context.Context, id int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		p, err := r.find(ctx, tx, id, true)
+		if err != nil {
+			return err
+		}
+
+		if p.Status != model.LFGOpen {
+			return model.ErrLFGPostClosed
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE lfg_posts SET status = $2 WHERE id = $1", id, model.LFGClosed)
+
+		return err
+	})
+}
+
+// Expire func. Marking open posts which expired at the time as expired
+func (r *LFGRepository) Expire(ctx context.Context, now time.Time) (int, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.store.conn().ExecContext(
+		ctx,
+		"UPDATE lfg_posts SET status = $2 WHERE status = $1 AND expires_at <= $3",
+		model.LFGOpen,
+		model.LFGExpired,
+		now,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return int(n), err
+}
+
+// Request func. Writing request of the user to join the group of the
+// post. The post is locked while the request is checked.
+func (r *LFGRepository) Request(ctx context.Context, req *model.LFGRequest) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	req.BeforeCreate()
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		p, err := r.find(ctx, tx, req.PostID, true)
+		if err != nil {
+			return err
+		}
+
+		if err := p.CanRequest(req.UserID, req.CreatedAt); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO lfg_requests (post_id, user_id, message, created_at) VALUES ($1, $2, $3, $4)",
+			req.PostID,
+			req.UserID,
+			req.Message,
+			req.CreatedAt,
+		); err != nil {
+			switch {
+			case r.store.dialect.IsUniqueViolation(err):
+				return store.ErrRecordExists
+			case r.store.dialect.IsForeignKeyViolation(err):
+				return store.ErrRecordNotFound
+			}
+			return err
+		}
+
+		return nil
+	})
+}
+
+// Requests func. Finding requests to join the group of the post in
+// order they were sent
+func (r *LFGRepository) Requests(ctx context.Context, postID int) ([]*model.LFGRequest, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		`SELECT post_id, user_id, message, created_at FROM lfg_requests
+		WHERE post_id = $1 ORDER BY created_at, user_id`,
+		postID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []*model.LFGRequest{}
+	for rows.Next() {
+		req := &model.LFGRequest{}
+		if err := rows.Scan(&req.PostID, &req.UserID, &req.Message, &req.CreatedAt); err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+
+	return requests, rows.Err()
+}
+
+// find func. Finding LFG post with the id, locking its row if needed
+func (r *LFGRepository) find(ctx context.Context, q queryer, id int, lock bool) (*model.LFGPost, error) {
+	query := "SELECT " + lfgPostColumns + " FROM lfg_posts WHERE id = $1"
+	if lock {
+		query += r.store.dialect.Lock()
+	}
+
+	return r.scan(q.QueryRowContext(ctx, query, id))
+}
+
+// scan func. Scanning LFG post columns from row
+func (r *LFGRepository) scan(row interface{ Scan(...interface{}) error }) (*model.LFGPost, error) {
+	p := &model.LFGPost{}
+	if err := row.Scan(
+		&p.ID,
+		&p.UserID,
+		&p.GameID,
+		&p.Platform,
+		&p.Players,
+		&p.Language,
+		&p.SkillMin,
+		&p.SkillMax,
+		&p.VoiceChat,
+		&p.Description,
+		&p.StartsAt,
+		&p.ExpiresAt,
+		&p.Status,
+		&p.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// nullTime func. Returns the time as a query argument, zero time is NULL
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t
+}
@@ -0,0 +1,246 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// LibraryRepository object for storing library entries
+type LibraryRepository struct {
+	store *Store
+}
+
+// Add func. Writing imported library entry in DB
+func (r *LibraryRepository) Add(ctx context.Context, e *model.LibraryEntry) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.add(ctx, r.store.conn(), e)
+}
+
+// Update func. Rewriting status, hours played and notes of the
+// library entry with the same user id and game id
+func (r *LibraryRepository) Update(ctx context.Context, e *model.LibraryEntry) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		`UPDATE library_entries SET status = $3, hours_played = $4, notes = $5
+		WHERE user_id = $1 AND game_id = $2 RETURNING added_at`,
+		e.UserID,
+		e.GameID,
+		e.Status,
+		e.HoursPlayed,
+		e.Notes,
+	).Scan(&e.AddedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return store.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Remove func. Deleting library entry with the right user id and game id
+func (r *LibraryRepository) Remove(ctx context.Context, userID, gameID int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.remove(ctx, r.store.conn(), userID, gameID)
+}
+
+// Find func. Finding library entry with the right user id and game id
+func (r *LibraryRepository) Find(ctx context.Context, userID, gameID int) (*model.LibraryEntry, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	e := &model.LibraryEntry{}
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		`SELECT user_id, game_id, status, hours_played, added_at, notes
+		FROM library_entries WHERE user_id = $1 AND game_id = $2`,
+		userID,
+		gameID,
+	).Scan(
+		&e.UserID,
+		&e.GameID,
+		&e.Status,
+		&e.HoursPlayed,
+		&e.AddedAt,
+		&e.Notes,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// FindByUser func. Finding all library entries of the user. If status
+// isn't empty only entries with this status are returned.
+func (r *LibraryRepository) FindByUser(ctx context.Context, userID int, status string) ([]*model.LibraryEntry, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		`SELECT user_id, game_id, status, hours_played, added_at, notes
+		FROM library_entries WHERE user_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY added_at DESC, game_id`,
+		userID,
+		status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*model.LibraryEntry{}
+	for rows.Next() {
+		e := &model.LibraryEntry{}
+		if err := rows.Scan(
+			&e.UserID,
+			&e.GameID,
+			&e.Status,
+			&e.HoursPlayed,
+			&e.AddedAt,
+			&e.Notes,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// Bulk func. Adding and removing several library entries of the user
+// in one transaction. Either all changes are written or none of them.
+func (r *LibraryRepository) Bulk(ctx context.Context, userID int, add []*model.LibraryEntry, remove []int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		for _, e := range add {
+			e.UserID = userID
+			if err := r.add(ctx, tx, e); err != nil {
+				return err
+			}
+		}
+
+		for _, gameID := range remove {
+			if err := r.remove(ctx, tx, userID, gameID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Visibility func. Returns library visibility setting of the user.
+// Libraries are public until the user changes the setting.
+func (r *LibraryRepository) Visibility(ctx context.Context, userID int) (string, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	var visibility string
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT visibility FROM library_settings WHERE user_id = $1",
+		userID,
+	).Scan(&visibility); err != nil {
+		if err == sql.ErrNoRows {
+			return model.LibraryVisibilityPublic, nil
+		}
+		return "", err
+	}
+
+	return visibility, nil
+}
+
+// SetVisibility func. Writing library visibility setting of the user
+func (r *LibraryRepository) SetVisibility(ctx context.Context, userID int, visibility string) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := model.ValidateLibraryVisibility(visibility); err != nil {
+		return err
+	}
+
+	_, err := r.store.conn().ExecContext(
+		ctx,
+		`INSERT INTO library_settings (user_id, visibility) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET visibility = EXCLUDED.visibility`,
+		userID,
+		visibility,
+	)
+
+	return err
+}
+
+// add func. Validating and writing library entry with q
+func (r *LibraryRepository) add(ctx context.Context, q queryer, e *model.LibraryEntry) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	e.BeforeCreate()
+
+	if _, err := q.ExecContext(
+		ctx,
+		`INSERT INTO library_entries (user_id, game_id, status, hours_played, added_at, notes)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		e.UserID,
+		e.GameID,
+		e.Status,
+		e.HoursPlayed,
+		e.AddedAt,
+		e.Notes,
+	); err != nil {
+		switch {
+		case r.store.dialect.IsUniqueViolation(err):
+			return store.ErrRecordExists
+		case r.store.dialect.IsForeignKeyViolation(err):
+			return store.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// remove func. Deleting library entry with q
+func (r *LibraryRepository) remove(ctx context.Context, q queryer, userID, gameID int) error {
+	res, err := q.ExecContext(
+		ctx,
+		"DELETE FROM library_entries WHERE user_id = $1 AND game_id = $2",
+		userID,
+		gameID,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
+}
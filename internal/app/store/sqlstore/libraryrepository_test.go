@@ -0,0 +1,93 @@
+package sqlstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLibraryRepository_Add(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	assert.NoError(t, s.Library().Add(context.Background(), model.TestLibraryEntry(t, u.ID, g.ID)))
+	assert.EqualError(t, s.Library().Add(context.Background(), model.TestLibraryEntry(t, u.ID, g.ID)), store.ErrRecordExists.Error())
+	assert.EqualError(t, s.Library().Add(context.Background(), model.TestLibraryEntry(t, u.ID, g.ID+1)), store.ErrRecordNotFound.Error())
+}
+
+func TestLibraryRepository_Update(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	e := model.TestLibraryEntry(t, u.ID, g.ID)
+	assert.EqualError(t, s.Library().Update(context.Background(), e), store.ErrRecordNotFound.Error())
+
+	s.Library().Add(context.Background(), e)
+	e.Status = model.LibraryStatusFinished
+	assert.NoError(t, s.Library().Update(context.Background(), e))
+
+	e2, err := s.Library().Find(context.Background(), u.ID, g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LibraryStatusFinished, e2.Status)
+}
+
+func TestLibraryRepository_Bulk(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g1 := model.TestGame(t)
+	s.Game().Create(context.Background(), g1)
+	g2 := model.TestGame(t)
+	s.Game().Create(context.Background(), g2)
+	s.Library().Add(context.Background(), model.TestLibraryEntry(t, u.ID, g1.ID))
+
+	err := s.Library().Bulk(context.Background(), u.ID, []*model.LibraryEntry{
+		model.TestLibraryEntry(t, u.ID, g2.ID),
+	}, []int{g2.ID + 1})
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	_, err = s.Library().Find(context.Background(), u.ID, g2.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	err = s.Library().Bulk(context.Background(), u.ID, []*model.LibraryEntry{
+		model.TestLibraryEntry(t, u.ID, g2.ID),
+	}, []int{g1.ID})
+	assert.NoError(t, err)
+	entries, _ := s.Library().FindByUser(context.Background(), u.ID, "")
+	assert.Len(t, entries, 1)
+}
+
+func TestLibraryRepository_Visibility(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users")
+
+	s := sqlstore.New(db, queryTimeout)
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+
+	v, err := s.Library().Visibility(context.Background(), u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LibraryVisibilityPublic, v)
+
+	assert.NoError(t, s.Library().SetVisibility(context.Background(), u.ID, model.LibraryVisibilityPrivate))
+	v, _ = s.Library().Visibility(context.Background(), u.ID)
+	assert.Equal(t, model.LibraryVisibilityPrivate, v)
+}
@@ -0,0 +1,254 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+const matchColumns = `id, game_id, COALESCE(lobby_id, 0), side_a, side_b, winner,
+	status, reported_by, season, created_at, rated_at`
+
+// MatchRepository object for storing reported match results
+type MatchRepository struct {
+	store *Store
+}
+
+// Create func. Writing imported match in DB
+func (r *MatchRepository) Create(ctx context.Context, m *model.Match) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	m.BeforeCreate()
+
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		`INSERT INTO matches (game_id, lobby_id, side_a, side_b, winner, status, reported_by, season, created_at)
+		VALUES ($1, NULLIF($2, 0), $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+		m.GameID,
+		m.LobbyID,
+		r.store.dialect.Ints(&m.SideA),
+		r.store.dialect.Ints(&m.SideB),
+		m.Winner,
+		m.Status,
+		m.ReportedBy,
+		m.Season,
+		m.CreatedAt,
+	).Scan(&m.ID); err != nil {
+		if r.store.dialect.IsForeignKeyViolation(err) {
+			return store.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Find func. Finding match with the right (id we need) id
+func (r *MatchRepository) Find(ctx context.Context, id int) (*model.Match, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.scan(r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT "+matchColumns+" FROM matches WHERE id = $1",
+		id,
+	))
+}
+
+// Update func. Writing status and winner of the match. The stored match
+// is locked, so concurrent updates can't both move it out of the same
+// status. When the result becomes final, ratings of the players are
+// updated in the same transaction and the match is marked as rated.
+func (r *MatchRepository) Update(ctx context.Context, m *model.Match) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		var status string
+		if err := tx.QueryRowContext(
+			ctx,
+			"SELECT status FROM matches WHERE id = $1"+r.store.dialect.Lock(),
+			m.ID,
+		).Scan(&status); err != nil {
+			if err == sql.ErrNoRows {
+				return store.ErrRecordNotFound
+			}
+			return err
+		}
+
+		if !model.ValidMatchTransition(status, m.Status) {
+			return model.ErrInvalidMatchTransition
+		}
+
+		if _, err := tx.ExecContext(
+			ctx,
+			"UPDATE matches SET winner = $2, status = $3 WHERE id = $1",
+			m.ID,
+			m.Winner,
+			m.Status,
+		); err != nil {
+			return err
+		}
+
+		if !m.Final() {
+			return nil
+		}
+
+		return r.rate(ctx, tx, m)
+	})
+}
+
+// FindByStatus func. Finding matches with the status, oldest first
+func (r *MatchRepository) FindByStatus(ctx context.Context, status string, q *store.ListQuery) ([]*model.Match, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		"SELECT "+matchColumns+" FROM matches WHERE status = $1 ORDER BY created_at, id LIMIT $2 OFFSET $3",
+		status,
+		q.Limit,
+		q.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matches := []*model.Match{}
+	for rows.Next() {
+		m, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+
+	return matches, rows.Err()
+}
+
+// rate func. Updating ratings of the match players. Missing ratings
+// are created with default values first, then all of them are locked
+// in user id order, so concurrently rated matches can't deadlock.
+func (r *MatchRepository) rate(ctx context.Context, tx queryer, m *model.Match) error {
+	players := m.Players()
+	ids := append([]int{}, players...)
+	sort.Ints(ids)
+
+	for _, id := range ids {
+		d := model.NewRating(id, m.GameID, m.Season)
+		if _, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO ratings (user_id, game_id, season, rating, rd, volatility)
+			VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING`,
+			d.UserID,
+			d.GameID,
+			d.Season,
+			d.Rating,
+			d.RD,
+			d.Volatility,
+		); err != nil {
+			if r.store.dialect.IsForeignKeyViolation(err) {
+				return store.ErrRecordNotFound
+			}
+			return err
+		}
+	}
+
+	rows, err := tx.QueryContext(
+		ctx,
+		"SELECT "+ratingColumns+` FROM ratings WHERE game_id = $1 AND season = $2
+		AND `+r.store.dialect.AnyOf("user_id", "$3")+` ORDER BY user_id`+r.store.dialect.Lock(),
+		m.GameID,
+		m.Season,
+		r.store.dialect.Ints(&ids),
+	)
+	if err != nil {
+		return err
+	}
+
+	byUser := map[int]*model.Rating{}
+	for rows.Next() {
+		rt, err := scanRating(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		byUser[rt.UserID] = rt
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	ratings := make([]*model.Rating, len(players))
+	for i, id := range players {
+		ratings[i] = byUser[id]
+	}
+	model.RateMatch(m, ratings)
+
+	for _, rt := range ratings {
+		if _, err := tx.ExecContext(
+			ctx,
+			`UPDATE ratings SET rating = $4, rd = $5, volatility = $6, matches = $7, updated_at = $8
+			WHERE user_id = $1 AND game_id = $2 AND season = $3`,
+			rt.UserID,
+			rt.GameID,
+			rt.Season,
+			rt.Rating,
+			rt.RD,
+			rt.Volatility,
+			rt.Matches,
+			rt.UpdatedAt,
+		); err != nil {
+			return err
+		}
+	}
+
+	ratedAt := time.Now().UTC()
+	if _, err := tx.ExecContext(
+		ctx,
+		"UPDATE matches SET rated_at = $2 WHERE id = $1",
+		m.ID,
+		ratedAt,
+	); err != nil {
+		return err
+	}
+	m.RatedAt = &ratedAt
+
+	return nil
+}
+
+// scan func. Scanning match columns from row
+func (r *MatchRepository) scan(row interface{ Scan(...interface{}) error }) (*model.Match, error) {
+	m := &model.Match{}
+	if err := row.Scan(
+		&m.ID,
+		&m.GameID,
+		&m.LobbyID,
+		r.store.dialect.Ints(&m.SideA),
+		r.store.dialect.Ints(&m.SideB),
+		&m.Winner,
+		&m.Status,
+		&m.ReportedBy,
+		&m.Season,
+		&m.CreatedAt,
+		&m.RatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return m, nil
+}
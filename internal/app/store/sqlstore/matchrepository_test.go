@@ -0,0 +1,99 @@
+package sqlstore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchRepository_Create(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "opponent@example.org"
+	s.User().Create(context.Background(), u2)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	assert.EqualError(t, s.Match().Create(context.Background(), model.TestMatch(t, g.ID+1, u1.ID, u2.ID)), store.ErrRecordNotFound.Error())
+
+	m := model.TestMatch(t, g.ID, u1.ID, u2.ID)
+	assert.NoError(t, s.Match().Create(context.Background(), m))
+	assert.NotZero(t, m.ID)
+
+	m, err := s.Match().Find(context.Background(), m.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.MatchPending, m.Status)
+	assert.Equal(t, []int{u1.ID}, m.SideA)
+	assert.Equal(t, []int{u2.ID}, m.SideB)
+}
+
+func TestMatchRepository_Update(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "opponent@example.org"
+	s.User().Create(context.Background(), u2)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	m := model.TestMatch(t, g.ID, u1.ID, u2.ID)
+	s.Match().Create(context.Background(), m)
+
+	assert.NoError(t, m.Confirm(u2.ID))
+	assert.NoError(t, s.Match().Update(context.Background(), m))
+	assert.NotNil(t, m.RatedAt)
+	assert.EqualError(t, s.Match().Update(context.Background(), m), model.ErrInvalidMatchTransition.Error())
+
+	winner, err := s.Rating().Find(context.Background(), u1.ID, g.ID, m.Season)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, winner.Matches)
+	loser, err := s.Rating().Find(context.Background(), u2.ID, g.ID, m.Season)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, loser.Matches)
+	assert.Greater(t, winner.Rating, loser.Rating)
+}
+
+func TestMatchRepository_FindByStatus(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "opponent@example.org"
+	s.User().Create(context.Background(), u2)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	m1 := model.TestMatch(t, g.ID, u1.ID, u2.ID)
+	s.Match().Create(context.Background(), m1)
+	m2 := model.TestMatch(t, g.ID, u1.ID, u2.ID)
+	s.Match().Create(context.Background(), m2)
+	m2.Dispute(u2.ID)
+	s.Match().Update(context.Background(), m2)
+
+	q := &store.ListQuery{Limit: 10}
+	matches, err := s.Match().FindByStatus(context.Background(), model.MatchDisputed, q)
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, m2.ID, matches[0].ID)
+
+	matches, err = s.Match().FindByStatus(context.Background(), model.MatchPending, q)
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, m1.ID, matches[0].ID)
+}
@@ -0,0 +1,277 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+const reportColumns = `id, reporter_id, target_type, target_id, target_user_id, reason, excerpt,
+	status, action, resolution, moderator_id, resolved_at, created_at`
+
+const sanctionColumns = "id, user_id, kind, reason, moderator_id, report_id, expires_at, created_at"
+
+// ModerationRepository object for storing reports sent to moderators
+// and sanctions imposed by them
+type ModerationRepository struct {
+	store *Store
+}
+
+// CreateReport func. Writing imported report in DB
+func (r *ModerationRepository) CreateReport(ctx context.Context, rep *model.Report) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := rep.Validate(); err != nil {
+		return err
+	}
+
+	rep.BeforeCreate()
+
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		`INSERT INTO reports (reporter_id, target_type, target_id, target_user_id, reason, excerpt,
+		status, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`,
+		sql.NullInt64{Int64: int64(rep.ReporterID), Valid: rep.ReporterID != 0},
+		rep.TargetType,
+		rep.TargetID,
+		rep.TargetUserID,
+		rep.Reason,
+		rep.Excerpt,
+		rep.Status,
+		rep.CreatedAt,
+	).Scan(&rep.ID); err != nil {
+		switch {
+		case r.store.dialect.IsUniqueViolation(err):
+			return store.ErrRecordExists
+		case r.store.dialect.IsForeignKeyViolation(err):
+			return store.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// FindReport func. Finding report with the right (id we need) id
+func (r *ModerationRepository) FindReport(ctx context.Context, id int) (*model.Report, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.findReport(ctx, r.store.conn(), id, false)
+}
+
+// FindReports func. Finding the page of reports with the status,
+// oldest first, so the moderator queue is worked through in order
+func (r *ModerationRepository) FindReports(ctx context.Context, status string, q *store.ListQuery) ([]*model.Report, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		"SELECT "+reportColumns+" FROM reports WHERE status = $1 ORDER BY id LIMIT $2 OFFSET $3",
+		status,
+		q.Limit,
+		q.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []*model.Report{}
+	for rows.Next() {
+		rep, err := r.scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, rep)
+	}
+
+	return reports, rows.Err()
+}
+
+// Resolve func. Resolving the report with the action taken by the
+// moderator and imposing the sanction, if any. The report is locked
+// while it's resolved.
+func (r *ModerationRepository) Resolve(ctx context.Context, id int, action, reason string, moderatorID int, s *model.Sanction) (*model.Report, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	var rep *model.Report
+	err := r.store.transact(ctx, func(tx queryer) error {
+		var err error
+		if rep, err = r.findReport(ctx, tx, id, true); err != nil {
+			return err
+		}
+
+		if err := rep.Resolve(action, reason, moderatorID, time.Now().UTC()); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(
+			ctx,
+			`UPDATE reports SET status = $2, action = $3, resolution = $4, moderator_id = $5,
+			resolved_at = $6 WHERE id = $1`,
+			rep.ID,
+			rep.Status,
+			rep.Action,
+			rep.Resolution,
+			rep.ModeratorID,
+			rep.ResolvedAt,
+		); err != nil {
+			if r.store.dialect.IsForeignKeyViolation(err) {
+				return store.ErrRecordNotFound
+			}
+			return err
+		}
+
+		if s == nil {
+			return nil
+		}
+
+		if err := s.Validate(); err != nil {
+			return err
+		}
+
+		s.BeforeCreate()
+		s.ReportID = rep.ID
+
+		if err := tx.QueryRowContext(
+			ctx,
+			`INSERT INTO sanctions (user_id, kind, reason, moderator_id, report_id, expires_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+			s.UserID,
+			s.Kind,
+			s.Reason,
+			s.ModeratorID,
+			s.ReportID,
+			s.ExpiresAt,
+			s.CreatedAt,
+		).Scan(&s.ID); err != nil {
+			if r.store.dialect.IsForeignKeyViolation(err) {
+				return store.ErrRecordNotFound
+			}
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rep, nil
+}
+
+// Sanctions func. Finding sanctions imposed on the user, newest first
+func (r *ModerationRepository) Sanctions(ctx context.Context, userID int) ([]*model.Sanction, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		"SELECT "+sanctionColumns+" FROM sanctions WHERE user_id = $1 ORDER BY id DESC",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sanctions := []*model.Sanction{}
+	for rows.Next() {
+		s := &model.Sanction{}
+		var moderatorID, reportID sql.NullInt64
+		var expires sql.NullTime
+		if err := rows.Scan(
+			&s.ID,
+			&s.UserID,
+			&s.Kind,
+			&s.Reason,
+			&moderatorID,
+			&reportID,
+			&expires,
+			&s.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		s.ModeratorID = int(moderatorID.Int64)
+		s.ReportID = int(reportID.Int64)
+		if expires.Valid {
+			s.ExpiresAt = &expires.Time
+		}
+		sanctions = append(sanctions, s)
+	}
+
+	return sanctions, rows.Err()
+}
+
+// Lift func. Ending the active sanction with the id at the time
+func (r *ModerationRepository) Lift(ctx context.Context, id int, now time.Time) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.store.conn().ExecContext(
+		ctx,
+		`UPDATE sanctions SET expires_at = $2
+		WHERE id = $1 AND kind <> $3 AND (expires_at IS NULL OR expires_at > $2)`,
+		id,
+		now,
+		model.SanctionWarn,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res)
+}
+
+// findReport func. Finding report with the id, locking its row if needed
+func (r *ModerationRepository) findReport(ctx context.Context, q queryer, id int, lock bool) (*model.Report, error) {
+	query := "SELECT " + reportColumns + " FROM reports WHERE id = $1"
+	if lock {
+		query += r.store.dialect.Lock()
+	}
+
+	return r.scanReport(q.QueryRowContext(ctx, query, id))
+}
+
+// scanReport func. Scanning report columns from row
+func (r *ModerationRepository) scanReport(row interface{ Scan(...interface{}) error }) (*model.Report, error) {
+	rep := &model.Report{}
+	var reporterID, moderatorID sql.NullInt64
+	var resolved sql.NullTime
+	if err := row.Scan(
+		&rep.ID,
+		&reporterID,
+		&rep.TargetType,
+		&rep.TargetID,
+		&rep.TargetUserID,
+		&rep.Reason,
+		&rep.Excerpt,
+		&rep.Status,
+		&rep.Action,
+		&rep.Resolution,
+		&moderatorID,
+		&resolved,
+		&rep.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	rep.ReporterID = int(reporterID.Int64)
+	rep.ModeratorID = int(moderatorID.Int64)
+	if resolved.Valid {
+		rep.ResolvedAt = &resolved.Time
+	}
+
+	return rep, nil
+}
@@ -0,0 +1,140 @@
+package sqlstore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModerationRepository_CreateReport(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users")
+
+	s := sqlstore.New(db, queryTimeout)
+	reporter := model.TestUser(t)
+	s.User().Create(context.Background(), reporter)
+	u := model.TestUser(t)
+	u.Email = "reported@example.org"
+	s.User().Create(context.Background(), u)
+
+	rep := model.TestReport(t, reporter.ID, u.ID)
+	assert.NoError(t, s.Moderation().CreateReport(context.Background(), rep))
+	assert.NotZero(t, rep.ID)
+	assert.Equal(t, model.ReportOpen, rep.Status)
+	assert.EqualError(t, s.Moderation().CreateReport(context.Background(), model.TestReport(t, reporter.ID, u.ID+1)), store.ErrRecordNotFound.Error())
+	// The same target can be reported again once the report is resolved
+	assert.EqualError(t, s.Moderation().CreateReport(context.Background(), model.TestReport(t, reporter.ID, u.ID)), store.ErrRecordExists.Error())
+	_, err := s.Moderation().Resolve(context.Background(), rep.ID, model.ModerationDismiss, "Nothing wrong", reporter.ID, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Moderation().CreateReport(context.Background(), model.TestReport(t, reporter.ID, u.ID)))
+	// The text filter reports the same target as often as it's flagged
+	assert.NoError(t, s.Moderation().CreateReport(context.Background(), model.TestReport(t, 0, u.ID)))
+	assert.NoError(t, s.Moderation().CreateReport(context.Background(), model.TestReport(t, 0, u.ID)))
+}
+
+func TestModerationRepository_FindReports(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users")
+
+	s := sqlstore.New(db, queryTimeout)
+	reporter := model.TestUser(t)
+	s.User().Create(context.Background(), reporter)
+	u := model.TestUser(t)
+	u.Email = "reported@example.org"
+	s.User().Create(context.Background(), u)
+
+	first := model.TestReport(t, reporter.ID, u.ID)
+	s.Moderation().CreateReport(context.Background(), first)
+	second := model.TestReport(t, u.ID, reporter.ID)
+	s.Moderation().CreateReport(context.Background(), second)
+
+	found, err := s.Moderation().FindReport(context.Background(), second.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, second.TargetUserID, found.TargetUserID)
+
+	reports, err := s.Moderation().FindReports(context.Background(), model.ReportOpen, &store.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, reports, 2) {
+		assert.Equal(t, first.ID, reports[0].ID)
+	}
+
+	s.Moderation().Resolve(context.Background(), first.ID, model.SanctionWarn, "Rude", reporter.ID, nil)
+	reports, err = s.Moderation().FindReports(context.Background(), model.ReportOpen, &store.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, reports, 1)
+
+	_, err = s.Moderation().FindReport(context.Background(), second.ID+1)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
+
+func TestModerationRepository_Resolve(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users")
+
+	s := sqlstore.New(db, queryTimeout)
+	moderator := model.TestUser(t)
+	s.User().Create(context.Background(), moderator)
+	u := model.TestUser(t)
+	u.Email = "reported@example.org"
+	s.User().Create(context.Background(), u)
+
+	rep := model.TestReport(t, moderator.ID, u.ID)
+	s.Moderation().CreateReport(context.Background(), rep)
+	// Invalid sanctions leave the report open
+	invalid := model.TestSanction(t, u.ID, moderator.ID)
+	invalid.ExpiresAt = nil
+	_, err := s.Moderation().Resolve(context.Background(), rep.ID, model.SanctionMute, "Spam", moderator.ID, invalid)
+	assert.Error(t, err)
+
+	found, _ := s.Moderation().FindReport(context.Background(), rep.ID)
+	assert.Equal(t, model.ReportOpen, found.Status)
+
+	sanction := model.TestSanction(t, u.ID, moderator.ID)
+	resolved, err := s.Moderation().Resolve(context.Background(), rep.ID, model.SanctionMute, "Spam", moderator.ID, sanction)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReportResolved, resolved.Status)
+	assert.Equal(t, moderator.ID, resolved.ModeratorID)
+	assert.NotZero(t, sanction.ID)
+	assert.Equal(t, rep.ID, sanction.ReportID)
+
+	_, err = s.Moderation().Resolve(context.Background(), rep.ID, model.ModerationDismiss, "Fine", moderator.ID, nil)
+	assert.EqualError(t, err, model.ErrReportResolved.Error())
+	_, err = s.Moderation().Resolve(context.Background(), rep.ID+1, model.ModerationDismiss, "Fine", moderator.ID, nil)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
+
+func TestModerationRepository_Lift(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users")
+
+	s := sqlstore.New(db, queryTimeout)
+	moderator := model.TestUser(t)
+	s.User().Create(context.Background(), moderator)
+	u := model.TestUser(t)
+	u.Email = "reported@example.org"
+	s.User().Create(context.Background(), u)
+
+	rep := model.TestReport(t, moderator.ID, u.ID)
+	s.Moderation().CreateReport(context.Background(), rep)
+	ban := model.TestSanction(t, u.ID, moderator.ID)
+	ban.Kind = model.SanctionBan
+	ban.ExpiresAt = nil
+	s.Moderation().Resolve(context.Background(), rep.ID, model.SanctionBan, "Cheating", moderator.ID, ban)
+
+	now := time.Now().UTC()
+	sanctions, err := s.Moderation().Sanctions(context.Background(), u.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, sanctions, 1) {
+		assert.True(t, sanctions[0].Active(now))
+	}
+
+	assert.NoError(t, s.Moderation().Lift(context.Background(), ban.ID, now))
+	sanctions, _ = s.Moderation().Sanctions(context.Background(), u.ID)
+	assert.False(t, sanctions[0].Active(now.Add(time.Second)))
+	assert.EqualError(t, s.Moderation().Lift(context.Background(), ban.ID, now.Add(time.Second)), store.ErrRecordNotFound.Error())
+}
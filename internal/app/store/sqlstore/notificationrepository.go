@@ -0,0 +1,196 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// NotificationRepository object for storing notifications of users and
+// their notification preferences
+type NotificationRepository struct {
+	store *Store
+}
+
+// Create func. Writing imported notification in DB
+func (r *NotificationRepository) Create(ctx context.Context, n *model.Notification) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := n.Validate(); err != nil {
+		return err
+	}
+
+	n.BeforeCreate()
+
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		`INSERT INTO notifications (user_id, type, text, link, read, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		n.UserID,
+		n.Type,
+		n.Text,
+		n.Link,
+		n.Read,
+		n.CreatedAt,
+	).Scan(&n.ID); err != nil {
+		if r.store.dialect.IsForeignKeyViolation(err) {
+			return store.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// FindByUser func. Finding the page of notifications of the user older
+// than the notification with beforeID, newest first
+func (r *NotificationRepository) FindByUser(ctx context.Context, userID, beforeID, limit int) ([]*model.Notification, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		`SELECT id, user_id, type, text, link, read, created_at FROM notifications
+		WHERE user_id = $1 AND ($2 = 0 OR id < $2) ORDER BY id DESC LIMIT $3`,
+		userID,
+		beforeID,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*model.Notification{}
+	for rows.Next() {
+		n := &model.Notification{}
+		if err := rows.Scan(
+			&n.ID,
+			&n.UserID,
+			&n.Type,
+			&n.Text,
+			&n.Link,
+			&n.Read,
+			&n.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// CountUnread func. Counting unread notifications of the user
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID int) (int, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	n := 0
+	err := r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT count(*) FROM notifications WHERE user_id = $1 AND NOT read",
+		userID,
+	).Scan(&n)
+
+	return n, err
+}
+
+// MarkRead func. Marking the notification of the user read
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.store.conn().ExecContext(
+		ctx,
+		"UPDATE notifications SET read = true WHERE id = $1 AND user_id = $2",
+		id,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(res)
+}
+
+// MarkAllRead func. Marking every notification of the user read,
+// returns the number of notifications that were unread
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID int) (int, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	res, err := r.store.conn().ExecContext(
+		ctx,
+		"UPDATE notifications SET read = true WHERE user_id = $1 AND NOT read",
+		userID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return int(n), err
+}
+
+// Preferences func. Finding notification preferences of the user
+func (r *NotificationRepository) Preferences(ctx context.Context, userID int) (map[string]bool, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		"SELECT type, enabled FROM notification_preferences WHERE user_id = $1",
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prefs := map[string]bool{}
+	for rows.Next() {
+		var t string
+		var enabled bool
+		if err := rows.Scan(&t, &enabled); err != nil {
+			return nil, err
+		}
+		prefs[t] = enabled
+	}
+
+	return prefs, rows.Err()
+}
+
+// SetPreferences func. Writing the notification preferences over the
+// ones of the user, types missing from prefs are left as they are
+func (r *NotificationRepository) SetPreferences(ctx context.Context, userID int, prefs map[string]bool) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := model.ValidateNotificationPreferences(prefs); err != nil {
+		return err
+	}
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		for t, enabled := range prefs {
+			if _, err := tx.ExecContext(
+				ctx,
+				`INSERT INTO notification_preferences (user_id, type, enabled) VALUES ($1, $2, $3)
+				ON CONFLICT (user_id, type) DO UPDATE SET enabled = $3`,
+				userID,
+				t,
+				enabled,
+			); err != nil {
+				if r.store.dialect.IsForeignKeyViolation(err) {
+					return store.ErrRecordNotFound
+				}
+				return err
+			}
+		}
+
+		return nil
+	})
+}
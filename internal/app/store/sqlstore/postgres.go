@@ -0,0 +1,105 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// Postgres error codes we react on
+const (
+	uniqueViolation      = "23505"
+	foreignKeyViolation  = "23503"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// postgres object. Dialect of postgres, the one queries are written in
+type postgres struct{}
+
+func (postgres) Rebind(query string) string {
+	return query
+}
+
+func (postgres) Lock() string {
+	return " FOR UPDATE"
+}
+
+func (postgres) Ints(ids *[]int) interface{} {
+	return &pqInts{ids: ids}
+}
+
+func (postgres) AnyOf(column, param string) string {
+	return column + " = ANY(" + param + ")"
+}
+
+func (postgres) Epoch(expr string) string {
+	return "CAST(extract(epoch from CAST(" + expr + " AS timestamptz)) * 1000000 AS bigint)"
+}
+
+func (postgres) MoveBucket(column, from, to string) string {
+	assignments := []string{}
+	if from != "" {
+		assignments = append(assignments, column+"["+from+"] = "+column+"["+from+"] - 1")
+	}
+	if to != "" {
+		assignments = append(assignments, column+"["+to+"] = "+column+"["+to+"] + 1")
+	}
+
+	return strings.Join(assignments, ", ")
+}
+
+func (postgres) TxOptions() *sql.TxOptions {
+	return &sql.TxOptions{Isolation: sql.LevelSerializable}
+}
+
+func (postgres) IsRetryable(err error) bool {
+	return isErrorCode(err, serializationFailure) || isErrorCode(err, deadlockDetected)
+}
+
+func (postgres) IsUniqueViolation(err error) bool {
+	return isErrorCode(err, uniqueViolation)
+}
+
+func (postgres) IsForeignKeyViolation(err error) bool {
+	return isErrorCode(err, foreignKeyViolation)
+}
+
+// isErrorCode func. Checks that err is a postgres error with
+// the imported code.
+func isErrorCode(err error, code string) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && string(pqErr.Code) == code
+}
+
+// pqInts object. Ints kept in a postgres bigint array
+type pqInts struct {
+	ids *[]int
+}
+
+// Value func. Returns the ints as postgres array
+func (a *pqInts) Value() (driver.Value, error) {
+	ids := make([]int64, len(*a.ids))
+	for i, id := range *a.ids {
+		ids[i] = int64(id)
+	}
+
+	return pq.Array(ids).Value()
+}
+
+// Scan func. Reading the ints from postgres array
+func (a *pqInts) Scan(src interface{}) error {
+	ids := []int64{}
+	if err := pq.Array(&ids).Scan(src); err != nil {
+		return err
+	}
+
+	*a.ids = make([]int, len(ids))
+	for i, id := range ids {
+		(*a.ids)[i] = int(id)
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+const ratingColumns = "user_id, game_id, season, rating, rd, volatility, matches, updated_at"
+
+// rankedRatings query. Numbering ratings of the game during the season
+// by leaderboard places
+const rankedRatings = `SELECT ` + ratingColumns + `,
+	ROW_NUMBER() OVER (ORDER BY rating DESC, user_id) AS rank
+	FROM ratings WHERE game_id = $1 AND season = $2`
+
+// RatingRepository object for reading skill ratings
+type RatingRepository struct {
+	store *Store
+}
+
+// Find func. Finding user's rating in the game during the season
+func (r *RatingRepository) Find(ctx context.Context, userID, gameID int, season string) (*model.Rating, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return scanRating(r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT "+ratingColumns+" FROM ratings WHERE user_id = $1 AND game_id = $2 AND season = $3",
+		userID,
+		gameID,
+		season,
+	))
+}
+
+// Leaderboard func. Finding the page of the game leaderboard during the season
+func (r *RatingRepository) Leaderboard(ctx context.Context, gameID int, season string, q *store.ListQuery) ([]*model.LeaderboardEntry, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		"SELECT * FROM ("+rankedRatings+") AS ranked ORDER BY rank LIMIT $3 OFFSET $4",
+		gameID,
+		season,
+		q.Limit,
+		q.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*model.LeaderboardEntry{}
+	for rows.Next() {
+		e := &model.LeaderboardEntry{Rating: &model.Rating{}}
+		if err := rows.Scan(
+			&e.UserID,
+			&e.GameID,
+			&e.Season,
+			&e.Rating.Rating,
+			&e.RD,
+			&e.Volatility,
+			&e.Matches,
+			&e.UpdatedAt,
+			&e.Rank,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
+
+// Rank func. Finding user's place in the game leaderboard during the season
+func (r *RatingRepository) Rank(ctx context.Context, userID, gameID int, season string) (int, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	var rank int
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT rank FROM ("+rankedRatings+") AS ranked WHERE user_id = $3",
+		gameID,
+		season,
+		userID,
+	).Scan(&rank); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, store.ErrRecordNotFound
+		}
+		return 0, err
+	}
+
+	return rank, nil
+}
+
+// scanRating func. Scanning rating columns from row
+func scanRating(row interface{ Scan(...interface{}) error }) (*model.Rating, error) {
+	rt := &model.Rating{}
+	if err := row.Scan(
+		&rt.UserID,
+		&rt.GameID,
+		&rt.Season,
+		&rt.Rating,
+		&rt.RD,
+		&rt.Volatility,
+		&rt.Matches,
+		&rt.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return rt, nil
+}
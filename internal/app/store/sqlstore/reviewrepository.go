@@ -0,0 +1,361 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+const reviewColumns = `id, user_id, game_id, score, text, helpful_count,
+	unhelpful_count, created_at, updated_at`
+
+// ReviewRepository object for storing game reviews
+type ReviewRepository struct {
+	store *Store
+}
+
+// Create func. Writing imported review in DB and adding its score
+// to aggregated rating of the game in one transaction.
+func (r *ReviewRepository) Create(ctx context.Context, rv *model.Review) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := rv.Validate(); err != nil {
+		return err
+	}
+
+	rv.BeforeCreate()
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		if err := tx.QueryRowContext(
+			ctx,
+			`INSERT INTO reviews (user_id, game_id, score, text, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+			rv.UserID,
+			rv.GameID,
+			rv.Score,
+			rv.Text,
+			rv.CreatedAt,
+			rv.UpdatedAt,
+		).Scan(&rv.ID); err != nil {
+			switch {
+			case r.store.dialect.IsUniqueViolation(err):
+				return store.ErrRecordExists
+			case r.store.dialect.IsForeignKeyViolation(err):
+				return store.ErrRecordNotFound
+			}
+			return err
+		}
+
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE games SET rating_count = rating_count + 1, rating_sum = rating_sum + $2,
+			`+r.store.dialect.MoveBucket("rating_histogram", "", "$2")+` WHERE id = $1`,
+			rv.GameID,
+			rv.Score,
+		)
+
+		return err
+	})
+}
+
+// Update func. Rewriting score and text of the user's review of the
+// game and moving its score in aggregated rating of the game.
+func (r *ReviewRepository) Update(ctx context.Context, rv *model.Review) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := rv.Validate(); err != nil {
+		return err
+	}
+
+	rv.BeforeUpdate()
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		var oldScore int
+		if err := tx.QueryRowContext(
+			ctx,
+			"SELECT score FROM reviews WHERE user_id = $1 AND game_id = $2"+r.store.dialect.Lock(),
+			rv.UserID,
+			rv.GameID,
+		).Scan(&oldScore); err != nil {
+			if err == sql.ErrNoRows {
+				return store.ErrRecordNotFound
+			}
+			return err
+		}
+
+		if err := tx.QueryRowContext(
+			ctx,
+			`UPDATE reviews SET score = $3, text = $4, updated_at = $5
+			WHERE user_id = $1 AND game_id = $2
+			RETURNING id, helpful_count, unhelpful_count, created_at`,
+			rv.UserID,
+			rv.GameID,
+			rv.Score,
+			rv.Text,
+			rv.UpdatedAt,
+		).Scan(
+			&rv.ID,
+			&rv.HelpfulCount,
+			&rv.UnhelpfulCount,
+			&rv.CreatedAt,
+		); err != nil {
+			return err
+		}
+
+		if oldScore == rv.Score {
+			return nil
+		}
+
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE games SET rating_sum = rating_sum - $2 + $3,
+			`+r.store.dialect.MoveBucket("rating_histogram", "$2", "$3")+` WHERE id = $1`,
+			rv.GameID,
+			oldScore,
+			rv.Score,
+		)
+
+		return err
+	})
+}
+
+// Delete func. Deleting user's review of the game and removing its
+// score from aggregated rating of the game.
+func (r *ReviewRepository) Delete(ctx context.Context, userID, gameID int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		var score int
+		if err := tx.QueryRowContext(
+			ctx,
+			"DELETE FROM reviews WHERE user_id = $1 AND game_id = $2 RETURNING score",
+			userID,
+			gameID,
+		).Scan(&score); err != nil {
+			if err == sql.ErrNoRows {
+				return store.ErrRecordNotFound
+			}
+			return err
+		}
+
+		_, err := tx.ExecContext(
+			ctx,
+			`UPDATE games SET rating_count = rating_count - 1, rating_sum = rating_sum - $2,
+			`+r.store.dialect.MoveBucket("rating_histogram", "$2", "")+` WHERE id = $1`,
+			gameID,
+			score,
+		)
+
+		return err
+	})
+}
+
+// Find func. Finding review with the right (id we need) id
+func (r *ReviewRepository) Find(ctx context.Context, id int) (*model.Review, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.scan(r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT "+reviewColumns+" FROM reviews WHERE id = $1",
+		id,
+	))
+}
+
+// FindByUserAndGame func. Finding user's review of the game
+func (r *ReviewRepository) FindByUserAndGame(ctx context.Context, userID, gameID int) (*model.Review, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.scan(r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT "+reviewColumns+" FROM reviews WHERE user_id = $1 AND game_id = $2",
+		userID,
+		gameID,
+	))
+}
+
+// FindByGame func. Finding reviews of the game sorted and paginated
+// according to the list query.
+func (r *ReviewRepository) FindByGame(ctx context.Context, gameID int, q *store.ListQuery) ([]*model.Review, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	order := "created_at DESC, id DESC"
+	if q.Sort == store.SortHelpful {
+		order = "helpful_count - unhelpful_count DESC, " + order
+	}
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		"SELECT "+reviewColumns+" FROM reviews WHERE game_id = $1 ORDER BY "+order+" LIMIT $2 OFFSET $3",
+		gameID,
+		q.Limit,
+		q.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []*model.Review{}
+	for rows.Next() {
+		rv, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, rv)
+	}
+
+	return reviews, rows.Err()
+}
+
+// Vote func. Writing user's helpful or unhelpful vote for the review.
+// Changing the vote moves it between helpful and unhelpful counters
+// of the review, repeating the same vote changes nothing. Concurrent
+// first votes of the user are counted once.
+func (r *ReviewRepository) Vote(ctx context.Context, v *model.ReviewVote) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		helpful, found, err := r.findVote(ctx, tx, v)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			inserted, err := r.insertVote(ctx, tx, v)
+			if err != nil {
+				return err
+			}
+			// A concurrent first vote of the user got inserted meanwhile,
+			// the vote changes that one
+			if !inserted {
+				if helpful, found, err = r.findVote(ctx, tx, v); err != nil {
+					return err
+				}
+				if !found {
+					return store.ErrRecordNotFound
+				}
+			}
+		}
+
+		switch {
+		case found && helpful == v.Helpful:
+			return nil
+		case found:
+			if _, err := tx.ExecContext(
+				ctx,
+				"UPDATE review_votes SET helpful = $3 WHERE review_id = $1 AND user_id = $2",
+				v.ReviewID,
+				v.UserID,
+				v.Helpful,
+			); err != nil {
+				return err
+			}
+		}
+
+		helpfulDelta, unhelpfulDelta := reviewVoteDeltas(v.Helpful, found)
+
+		_, err = tx.ExecContext(
+			ctx,
+			`UPDATE reviews SET helpful_count = helpful_count + $2,
+			unhelpful_count = unhelpful_count + $3 WHERE id = $1`,
+			v.ReviewID,
+			helpfulDelta,
+			unhelpfulDelta,
+		)
+
+		return err
+	})
+}
+
+// findVote func. Finding the vote of the user for the review with tx and
+// locking it. Returns whether the vote is helpful and whether it's found.
+func (r *ReviewRepository) findVote(ctx context.Context, tx queryer, v *model.ReviewVote) (bool, bool, error) {
+	var helpful bool
+	if err := tx.QueryRowContext(
+		ctx,
+		"SELECT helpful FROM review_votes WHERE review_id = $1 AND user_id = $2"+r.store.dialect.Lock(),
+		v.ReviewID,
+		v.UserID,
+	).Scan(&helpful); err != nil {
+		if err == sql.ErrNoRows {
+			return false, false, nil
+		}
+		return false, false, err
+	}
+
+	return helpful, true, nil
+}
+
+// insertVote func. Inserting the first vote of the user for the review
+// with tx. Returns false if the user has voted already.
+func (r *ReviewRepository) insertVote(ctx context.Context, tx queryer, v *model.ReviewVote) (bool, error) {
+	res, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO review_votes (review_id, user_id, helpful) VALUES ($1, $2, $3)
+		ON CONFLICT (review_id, user_id) DO NOTHING`,
+		v.ReviewID,
+		v.UserID,
+		v.Helpful,
+	)
+	if err != nil {
+		if r.store.dialect.IsForeignKeyViolation(err) {
+			return false, store.ErrRecordNotFound
+		}
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+// reviewVoteDeltas func. Returns how helpful and unhelpful counters of
+// the review change after the vote. If the vote is switched, the previous
+// opposite vote is taken back.
+func reviewVoteDeltas(helpful, switched bool) (int, int) {
+	helpfulDelta, unhelpfulDelta := 0, 1
+	if helpful {
+		helpfulDelta, unhelpfulDelta = 1, 0
+	}
+
+	if switched {
+		return helpfulDelta - unhelpfulDelta, unhelpfulDelta - helpfulDelta
+	}
+
+	return helpfulDelta, unhelpfulDelta
+}
+
+// scan func. Scanning review columns from row
+func (r *ReviewRepository) scan(row interface{ Scan(...interface{}) error }) (*model.Review, error) {
+	rv := &model.Review{}
+	if err := row.Scan(
+		&rv.ID,
+		&rv.UserID,
+		&rv.GameID,
+		&rv.Score,
+		&rv.Text,
+		&rv.HelpfulCount,
+		&rv.UnhelpfulCount,
+		&rv.CreatedAt,
+		&rv.UpdatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	return rv, nil
+}
@@ -0,0 +1,177 @@
+package sqlstore_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReviewRepository_Create(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	rv := model.TestReview(t, u.ID, g.ID)
+	assert.NoError(t, s.Review().Create(context.Background(), rv))
+	assert.NotZero(t, rv.ID)
+	assert.EqualError(t, s.Review().Create(context.Background(), model.TestReview(t, u.ID, g.ID)), store.ErrRecordExists.Error())
+
+	g, err := s.Game().Find(context.Background(), g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, g.RatingCount)
+	assert.Equal(t, float64(rv.Score), g.RatingMean)
+	assert.Equal(t, 1, g.RatingHistogram[rv.Score-1])
+}
+
+func TestReviewRepository_Update(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	rv := model.TestReview(t, u.ID, g.ID)
+	assert.EqualError(t, s.Review().Update(context.Background(), rv), store.ErrRecordNotFound.Error())
+
+	s.Review().Create(context.Background(), rv)
+	rv2 := model.TestReview(t, u.ID, g.ID)
+	rv2.Score = 3
+	assert.NoError(t, s.Review().Update(context.Background(), rv2))
+	assert.Equal(t, rv.ID, rv2.ID)
+
+	g, err := s.Game().Find(context.Background(), g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, g.RatingCount)
+	assert.Equal(t, 3.0, g.RatingMean)
+	assert.Equal(t, 0, g.RatingHistogram[rv.Score-1])
+	assert.Equal(t, 1, g.RatingHistogram[2])
+}
+
+func TestReviewRepository_Delete(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	assert.EqualError(t, s.Review().Delete(context.Background(), u.ID, g.ID), store.ErrRecordNotFound.Error())
+
+	s.Review().Create(context.Background(), model.TestReview(t, u.ID, g.ID))
+	assert.NoError(t, s.Review().Delete(context.Background(), u.ID, g.ID))
+
+	g, err := s.Game().Find(context.Background(), g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, g.RatingCount)
+	assert.Equal(t, 0.0, g.RatingMean)
+}
+
+func TestReviewRepository_FindByGame(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	rv1 := model.TestReview(t, u1.ID, g.ID)
+	s.Review().Create(context.Background(), rv1)
+	rv2 := model.TestReview(t, u2.ID, g.ID)
+	s.Review().Create(context.Background(), rv2)
+	s.Review().Vote(context.Background(), &model.ReviewVote{ReviewID: rv1.ID, UserID: u2.ID, Helpful: true})
+
+	reviews, err := s.Review().FindByGame(context.Background(), g.ID, &store.ListQuery{Sort: store.SortRecent, Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, reviews, 2)
+	assert.Equal(t, rv2.ID, reviews[0].ID)
+
+	reviews, err = s.Review().FindByGame(context.Background(), g.ID, &store.ListQuery{Sort: store.SortHelpful, Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, reviews, 1)
+	assert.Equal(t, rv1.ID, reviews[0].ID)
+}
+
+func TestReviewRepository_Vote(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+	rv := model.TestReview(t, u1.ID, g.ID)
+	s.Review().Create(context.Background(), rv)
+
+	assert.EqualError(t, s.Review().Vote(context.Background(), &model.ReviewVote{ReviewID: rv.ID + 1, UserID: u2.ID}), store.ErrRecordNotFound.Error())
+
+	assert.NoError(t, s.Review().Vote(context.Background(), &model.ReviewVote{ReviewID: rv.ID, UserID: u2.ID, Helpful: true}))
+	assert.NoError(t, s.Review().Vote(context.Background(), &model.ReviewVote{ReviewID: rv.ID, UserID: u2.ID, Helpful: true}))
+	rv, _ = s.Review().Find(context.Background(), rv.ID)
+	assert.Equal(t, 1, rv.HelpfulCount)
+	assert.Equal(t, 0, rv.UnhelpfulCount)
+
+	assert.NoError(t, s.Review().Vote(context.Background(), &model.ReviewVote{ReviewID: rv.ID, UserID: u2.ID, Helpful: false}))
+	rv, _ = s.Review().Find(context.Background(), rv.ID)
+	assert.Equal(t, 0, rv.HelpfulCount)
+	assert.Equal(t, 1, rv.UnhelpfulCount)
+}
+
+func TestReviewRepository_VoteConcurrently(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+	rv := model.TestReview(t, u1.ID, g.ID)
+	s.Review().Create(context.Background(), rv)
+
+	// First votes of the user arriving at once count once
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.Review().Vote(context.Background(), &model.ReviewVote{ReviewID: rv.ID, UserID: u2.ID, Helpful: true})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	rv, _ = s.Review().Find(context.Background(), rv.ID)
+	assert.Equal(t, 1, rv.HelpfulCount)
+	assert.Equal(t, 0, rv.UnhelpfulCount)
+}
@@ -1,37 +1,278 @@
 package sqlstore
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
 	"github.com/GShamian/tavern-of-games/internal/app/store"
 	// import postgres
 	_ "github.com/lib/pq"
 )
 
+// maxTxAttempts is how many times a transaction failing to serialize is
+// run before its error is returned
+const maxTxAttempts = 3
+
 // Store object, that is made to store information about DB
 type Store struct {
-	db             *sql.DB
-	userRepository *UserRepository
+	db                     *sql.DB
+	tx                     *sql.Tx
+	dialect                Dialect
+	queryTimeout           time.Duration
+	userRepository         *UserRepository
+	gameRepository         *GameRepository
+	libraryRepository      *LibraryRepository
+	reviewRepository       *ReviewRepository
+	friendshipRepository   *FriendshipRepository
+	chatRepository         *ChatRepository
+	matchRepository        *MatchRepository
+	ratingRepository       *RatingRepository
+	tournamentRepository   *TournamentRepository
+	achievementRepository  *AchievementRepository
+	guildRepository        *GuildRepository
+	lfgRepository          *LFGRepository
+	eventRepository        *EventRepository
+	notificationRepository *NotificationRepository
+	activityRepository     *ActivityRepository
+	moderationRepository   *ModerationRepository
+}
+
+// New func. Constructor for Store object of the postgres DB. Every
+// repository call is cancelled once it takes longer than queryTimeout,
+// zero means calls are only limited by their context.
+func New(db *sql.DB, queryTimeout time.Duration) *Store {
+	return NewWithDialect(db, postgres{}, queryTimeout)
+}
+
+// NewWithDialect func. Constructor for Store object running queries in
+// the dialect of another DB than postgres, see sqlitestore.
+func NewWithDialect(db *sql.DB, d Dialect, queryTimeout time.Duration) *Store {
+	return newStore(db, nil, d, queryTimeout)
 }
 
-// New func. Constructor for Store object
-func New(db *sql.DB) *Store {
-	return &Store{
-		db: db,
+// newStore func. Returns the store with all of its repositories, they're
+// created up front, so concurrent calls don't race creating them.
+func newStore(db *sql.DB, tx *sql.Tx, d Dialect, queryTimeout time.Duration) *Store {
+	s := &Store{
+		db:           db,
+		tx:           tx,
+		dialect:      d,
+		queryTimeout: queryTimeout,
 	}
+	s.userRepository = &UserRepository{store: s}
+	s.gameRepository = &GameRepository{store: s}
+	s.libraryRepository = &LibraryRepository{store: s}
+	s.reviewRepository = &ReviewRepository{store: s}
+	s.friendshipRepository = &FriendshipRepository{store: s}
+	s.chatRepository = &ChatRepository{store: s}
+	s.matchRepository = &MatchRepository{store: s}
+	s.ratingRepository = &RatingRepository{store: s}
+	s.tournamentRepository = &TournamentRepository{store: s}
+	s.achievementRepository = &AchievementRepository{store: s}
+	s.guildRepository = &GuildRepository{store: s}
+	s.lfgRepository = &LFGRepository{store: s}
+	s.eventRepository = &EventRepository{store: s}
+	s.notificationRepository = &NotificationRepository{store: s}
+	s.activityRepository = &ActivityRepository{store: s}
+	s.moderationRepository = &ModerationRepository{store: s}
+
+	return s
 }
 
-// User func. If userrepository is nil assigns it with
-// pointer on UserRepository which is initialised
-// with calling store.
+// User func. Returns UserRepository of the store
 func (s *Store) User() store.UserRepository {
-	if s.userRepository != nil {
-		return s.userRepository
+	return s.userRepository
+}
+
+// Game func. Returns GameRepository of the store
+func (s *Store) Game() store.GameRepository {
+	return s.gameRepository
+}
+
+// Library func. Returns LibraryRepository of the store
+func (s *Store) Library() store.LibraryRepository {
+	return s.libraryRepository
+}
+
+// Review func. Returns ReviewRepository of the store
+func (s *Store) Review() store.ReviewRepository {
+	return s.reviewRepository
+}
+
+// Friendship func. Returns FriendshipRepository of the store
+func (s *Store) Friendship() store.FriendshipRepository {
+	return s.friendshipRepository
+}
+
+// Chat func. Returns ChatRepository of the store
+func (s *Store) Chat() store.ChatRepository {
+	return s.chatRepository
+}
+
+// Match func. Returns MatchRepository of the store
+func (s *Store) Match() store.MatchRepository {
+	return s.matchRepository
+}
+
+// Rating func. Returns RatingRepository of the store
+func (s *Store) Rating() store.RatingRepository {
+	return s.ratingRepository
+}
+
+// Tournament func. Returns TournamentRepository of the store
+func (s *Store) Tournament() store.TournamentRepository {
+	return s.tournamentRepository
+}
+
+// Achievement func. Returns AchievementRepository of the store
+func (s *Store) Achievement() store.AchievementRepository {
+	return s.achievementRepository
+}
+
+// Guild func. Returns GuildRepository of the store
+func (s *Store) Guild() store.GuildRepository {
+	return s.guildRepository
+}
+
+// LFG func. Returns LFGRepository of the store
+func (s *Store) LFG() store.LFGRepository {
+	return s.lfgRepository
+}
+
+// Event func. Returns EventRepository of the store
+func (s *Store) Event() store.EventRepository {
+	return s.eventRepository
+}
+
+// Notification func. Returns NotificationRepository of the store
+func (s *Store) Notification() store.NotificationRepository {
+	return s.notificationRepository
+}
+
+// Activity func. Returns ActivityRepository of the store
+func (s *Store) Activity() store.ActivityRepository {
+	return s.activityRepository
+}
+
+// Moderation func. Returns ModerationRepository of the store
+func (s *Store) Moderation() store.ModerationRepository {
+	return s.moderationRepository
+}
+
+// withTimeout func. Returns context of the repository call limited by
+// the query timeout of the store
+func (s *Store) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
 	}
 
-	s.userRepository = &UserRepository{
-		store: s,
+	return context.WithTimeout(ctx, s.queryTimeout)
+}
+
+// WithTx func. Runs fn with the store bound to a DB transaction: its
+// repositories run their queries in the transaction. The transaction is
+// committed if fn succeeds and rolled back otherwise. Postgres
+// transactions are serializable, ones failing to serialize or deadlocked
+// are retried from the start, so fn can run a few times. SQLite ones run
+// one at a time and can't conflict. WithTx of the bound store joins the
+// transaction it's bound to.
+func (s *Store) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	if s.tx != nil {
+		return fn(s)
 	}
 
-	return s.userRepository
+	for attempt := 1; ; attempt++ {
+		err := s.begin(ctx, s.dialect.TxOptions(), func(tx *sql.Tx) error {
+			return fn(s.bound(tx))
+		})
+		if attempt == maxTxAttempts || !s.dialect.IsRetryable(err) {
+			return err
+		}
+	}
+}
+
+// bound func. Returns the store bound to the transaction. Repositories
+// of the bound store are its own.
+func (s *Store) bound(tx *sql.Tx) *Store {
+	return newStore(s.db, tx, s.dialect, s.queryTimeout)
+}
+
+// conn func. Returns the transaction the store is bound to or the DB,
+// running queries in the dialect of the store
+func (s *Store) conn() queryer {
+	if s.tx != nil {
+		return conn{q: s.tx, dialect: s.dialect}
+	}
+
+	return conn{q: s.db, dialect: s.dialect}
+}
+
+// transact func. Runs fn inside of a DB transaction. The transaction
+// is committed if fn succeeds and rolled back otherwise. The transaction
+// is rolled back as well once the context is done. The store bound to a
+// transaction runs fn in it.
+func (s *Store) transact(ctx context.Context, fn func(queryer) error) error {
+	if s.tx != nil {
+		return fn(s.conn())
+	}
+
+	return s.begin(ctx, nil, func(tx *sql.Tx) error {
+		return fn(conn{q: tx, dialect: s.dialect})
+	})
+}
+
+// begin func. Runs fn inside of a new DB transaction with the options
+func (s *Store) begin(ctx context.Context, opts *sql.TxOptions, fn func(*sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// queryer interface. Implemented by both *sql.DB and *sql.Tx, so
+// repository helpers can run either inside or outside a transaction.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// scanIDs func. Scanning a single id column from every row and
+// closing the rows.
+func scanIDs(rows *sql.Rows) ([]int, error) {
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// expectAffected func. Returns store.ErrRecordNotFound if the statement
+// with the imported result changed no rows.
+func expectAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return store.ErrRecordNotFound
+	}
+
+	return nil
 }
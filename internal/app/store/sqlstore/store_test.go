@@ -1,14 +1,25 @@
 package sqlstore_test
 
 import (
+	"context"
+	"errors"
 	"os"
 	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlstore"
+	"github.com/stretchr/testify/assert"
 )
 
+const queryTimeout = 5 * time.Second
+
 var (
 	databaseURL string
 )
 
+// TestMain func. Tests run on the postgres DB of DATABASE_URL
 func TestMain(m *testing.M) {
 	databaseURL = os.Getenv("DATABASE_URL")
 	if databaseURL == "" {
@@ -17,3 +28,59 @@ func TestMain(m *testing.M) {
 
 	os.Exit(m.Run())
 }
+
+func TestStore_Context(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users")
+
+	s := sqlstore.New(db, queryTimeout)
+	u := model.TestUser(t)
+	assert.NoError(t, s.User().Create(context.Background(), u))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := s.User().Find(ctx, u.ID)
+	assert.EqualError(t, err, context.Canceled.Error())
+
+	s = sqlstore.New(db, time.Nanosecond)
+	_, err = s.User().Find(context.Background(), u.ID)
+	assert.EqualError(t, err, context.DeadlineExceeded.Error())
+}
+
+func TestStore_WithTx(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	errFailed := errors.New("failed")
+	u := model.TestUser(t)
+	err := s.WithTx(context.Background(), func(tx store.Store) error {
+		if err := tx.User().Create(context.Background(), u); err != nil {
+			return err
+		}
+		if _, err := tx.User().Find(context.Background(), u.ID); err != nil {
+			return err
+		}
+
+		return errFailed
+	})
+	assert.EqualError(t, err, errFailed.Error())
+	_, err = s.User().Find(context.Background(), u.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	g := model.TestGame(t)
+	err = s.WithTx(context.Background(), func(tx store.Store) error {
+		if err := tx.User().Create(context.Background(), u); err != nil {
+			return err
+		}
+
+		return tx.WithTx(context.Background(), func(tx store.Store) error {
+			return tx.Game().Create(context.Background(), g)
+		})
+	})
+	assert.NoError(t, err)
+	_, err = s.User().Find(context.Background(), u.ID)
+	assert.NoError(t, err)
+	_, err = s.Game().Find(context.Background(), g.ID)
+	assert.NoError(t, err)
+}
@@ -1,10 +1,14 @@
 package sqlstore
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
 	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store/migrate"
+	"github.com/GShamian/tavern-of-games/migrations"
 )
 
 // TestDB ...
@@ -16,15 +20,30 @@ func TestDB(t *testing.T, databaseURL string) (*sql.DB, func(...string)) {
 		t.Fatal(err)
 	}
 
-	if err := db.Ping(); err != nil {
-		t.Fatal(err)
-	}
-
-	return db, func(tables ...string) {
+	teardown := func(tables ...string) {
 		if len(tables) > 0 {
 			db.Exec(fmt.Sprintf("TRUNCATE %s CASCADE", strings.Join(tables, ", ")))
 		}
 
 		db.Close()
 	}
+
+	if err := db.Ping(); err != nil {
+		teardown()
+		t.Fatal(err)
+	}
+
+	// Migrating the schema up with the embedded migrations
+	files, err := migrate.Load(migrations.Postgres())
+	if err != nil {
+		teardown()
+		t.Fatal(err)
+	}
+
+	if err := migrate.New(db, migrate.Postgres, files).Up(context.Background()); err != nil {
+		teardown()
+		t.Fatal(err)
+	}
+
+	return db, teardown
 }
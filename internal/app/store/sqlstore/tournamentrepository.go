@@ -0,0 +1,289 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+const tournamentColumns = `id, game_id, organizer_id, name, format, seeding, max_players,
+	rounds, registration_opens_at, registration_closes_at, status, bracket, created_at`
+
+// TournamentRepository object for storing tournaments and their participants
+type TournamentRepository struct {
+	store *Store
+}
+
+// Create func. Writing imported tournament in DB
+func (r *TournamentRepository) Create(ctx context.Context, t *model.Tournament) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	t.BeforeCreate()
+
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		`INSERT INTO tournaments (game_id, organizer_id, name, format, seeding, max_players,
+		rounds, registration_opens_at, registration_closes_at, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`,
+		t.GameID,
+		t.OrganizerID,
+		t.Name,
+		t.Format,
+		t.Seeding,
+		t.MaxPlayers,
+		t.Rounds,
+		t.RegistrationOpensAt,
+		t.RegistrationClosesAt,
+		t.Status,
+		t.CreatedAt,
+	).Scan(&t.ID); err != nil {
+		if r.store.dialect.IsForeignKeyViolation(err) {
+			return store.ErrRecordNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Find func. Finding tournament with the right (id we need) id
+func (r *TournamentRepository) Find(ctx context.Context, id int) (*model.Tournament, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.find(ctx, r.store.conn(), id, false)
+}
+
+// FindByStatus func. Finding tournaments with the status, the ones
+// closing registration first go first
+func (r *TournamentRepository) FindByStatus(ctx context.Context, status string, q *store.ListQuery) ([]*model.Tournament, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		"SELECT "+tournamentColumns+` FROM tournaments WHERE status = $1
+		ORDER BY registration_closes_at, id LIMIT $2 OFFSET $3`,
+		status,
+		q.Limit,
+		q.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tournaments := []*model.Tournament{}
+	for rows.Next() {
+		t, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		tournaments = append(tournaments, t)
+	}
+
+	return tournaments, rows.Err()
+}
+
+// Update func. Locking the tournament, changing it with fn and writing
+// its status and bracket back in one transaction
+func (r *TournamentRepository) Update(ctx context.Context, id int, fn func(*model.Tournament) error) (*model.Tournament, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	var t *model.Tournament
+	err := r.store.transact(ctx, func(tx queryer) error {
+		var err error
+		if t, err = r.find(ctx, tx, id, true); err != nil {
+			return err
+		}
+
+		if err := fn(t); err != nil {
+			return err
+		}
+
+		bracket, err := json.Marshal(t.Bracket)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(
+			ctx,
+			"UPDATE tournaments SET status = $2, bracket = $3 WHERE id = $1",
+			t.ID,
+			t.Status,
+			string(bracket),
+		)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Register func. Writing the user as a participant of the tournament.
+// The tournament is locked while free places are counted.
+func (r *TournamentRepository) Register(ctx context.Context, tournamentID, userID int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		t, err := r.find(ctx, tx, tournamentID, true)
+		if err != nil {
+			return err
+		}
+
+		var count int
+		if err := tx.QueryRowContext(
+			ctx,
+			"SELECT count(*) FROM tournament_participants WHERE tournament_id = $1",
+			tournamentID,
+		).Scan(&count); err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		if err := t.CanRegister(now, count); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(
+			ctx,
+			"INSERT INTO tournament_participants (tournament_id, user_id, registered_at) VALUES ($1, $2, $3)",
+			tournamentID,
+			userID,
+			now,
+		); err != nil {
+			switch {
+			case r.store.dialect.IsUniqueViolation(err):
+				return store.ErrRecordExists
+			case r.store.dialect.IsForeignKeyViolation(err):
+				return store.ErrRecordNotFound
+			}
+			return err
+		}
+
+		return nil
+	})
+}
+
+// Unregister func. Deleting the user from participants of the tournament
+// while the registration isn't over
+func (r *TournamentRepository) Unregister(ctx context.Context, tournamentID, userID int) error {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	return r.store.transact(ctx, func(tx queryer) error {
+		t, err := r.find(ctx, tx, tournamentID, true)
+		if err != nil {
+			return err
+		}
+
+		if t.Status != model.TournamentRegistration {
+			return model.ErrRegistrationClosed
+		}
+
+		res, err := tx.ExecContext(
+			ctx,
+			"DELETE FROM tournament_participants WHERE tournament_id = $1 AND user_id = $2",
+			tournamentID,
+			userID,
+		)
+		if err != nil {
+			return err
+		}
+
+		if n, err := res.RowsAffected(); err != nil {
+			return err
+		} else if n == 0 {
+			return store.ErrRecordNotFound
+		}
+
+		return nil
+	})
+}
+
+// Participants func. Finding participants of the tournament in order
+// of registration
+func (r *TournamentRepository) Participants(ctx context.Context, tournamentID int) ([]*model.TournamentParticipant, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		`SELECT tournament_id, user_id, registered_at FROM tournament_participants
+		WHERE tournament_id = $1 ORDER BY registered_at, user_id`,
+		tournamentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	participants := []*model.TournamentParticipant{}
+	for rows.Next() {
+		p := &model.TournamentParticipant{}
+		if err := rows.Scan(&p.TournamentID, &p.UserID, &p.RegisteredAt); err != nil {
+			return nil, err
+		}
+		participants = append(participants, p)
+	}
+
+	return participants, rows.Err()
+}
+
+// find func. Finding tournament with the id, locking its row if needed
+func (r *TournamentRepository) find(ctx context.Context, q queryer, id int, lock bool) (*model.Tournament, error) {
+	query := "SELECT " + tournamentColumns + " FROM tournaments WHERE id = $1"
+	if lock {
+		query += r.store.dialect.Lock()
+	}
+
+	return r.scan(q.QueryRowContext(ctx, query, id))
+}
+
+// scan func. Scanning tournament columns from row
+func (r *TournamentRepository) scan(row interface{ Scan(...interface{}) error }) (*model.Tournament, error) {
+	t := &model.Tournament{}
+	var bracket []byte
+	if err := row.Scan(
+		&t.ID,
+		&t.GameID,
+		&t.OrganizerID,
+		&t.Name,
+		&t.Format,
+		&t.Seeding,
+		&t.MaxPlayers,
+		&t.Rounds,
+		&t.RegistrationOpensAt,
+		&t.RegistrationClosesAt,
+		&t.Status,
+		&bracket,
+		&t.CreatedAt,
+	); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, store.ErrRecordNotFound
+		}
+		return nil, err
+	}
+
+	if bracket != nil {
+		if err := json.Unmarshal(bracket, &t.Bracket); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
@@ -0,0 +1,107 @@
+package sqlstore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/sqlstore"
+	"github.com/GShamian/tavern-of-games/internal/app/tournament"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTournamentRepository_Create(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	assert.EqualError(t, s.Tournament().Create(context.Background(), model.TestTournament(t, g.ID+1, u.ID)), store.ErrRecordNotFound.Error())
+
+	tr := model.TestTournament(t, g.ID, u.ID)
+	assert.NoError(t, s.Tournament().Create(context.Background(), tr))
+	assert.NotZero(t, tr.ID)
+
+	tr, err := s.Tournament().Find(context.Background(), tr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TournamentRegistration, tr.Status)
+	assert.Nil(t, tr.Bracket)
+
+	tournaments, err := s.Tournament().FindByStatus(context.Background(), model.TournamentRegistration, &store.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, tournaments, 1)
+}
+
+func TestTournamentRepository_Register(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	tr := model.TestTournament(t, g.ID, u1.ID)
+	tr.MaxPlayers = 2
+	s.Tournament().Create(context.Background(), tr)
+
+	assert.NoError(t, s.Tournament().Register(context.Background(), tr.ID, u1.ID))
+	assert.EqualError(t, s.Tournament().Register(context.Background(), tr.ID, u1.ID), store.ErrRecordExists.Error())
+	assert.NoError(t, s.Tournament().Register(context.Background(), tr.ID, u2.ID))
+	assert.EqualError(t, s.Tournament().Register(context.Background(), tr.ID, u2.ID+1), model.ErrTournamentFull.Error())
+
+	participants, err := s.Tournament().Participants(context.Background(), tr.ID)
+	assert.NoError(t, err)
+	assert.Len(t, participants, 2)
+
+	assert.NoError(t, s.Tournament().Unregister(context.Background(), tr.ID, u2.ID))
+	assert.EqualError(t, s.Tournament().Unregister(context.Background(), tr.ID, u2.ID), store.ErrRecordNotFound.Error())
+	participants, _ = s.Tournament().Participants(context.Background(), tr.ID)
+	assert.Len(t, participants, 1)
+}
+
+func TestTournamentRepository_Update(t *testing.T) {
+	db, teardown := sqlstore.TestDB(t, databaseURL)
+	defer teardown("users", "games")
+
+	s := sqlstore.New(db, queryTimeout)
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	tr := model.TestTournament(t, g.ID, u.ID)
+	s.Tournament().Create(context.Background(), tr)
+
+	_, err := s.Tournament().Update(context.Background(), tr.ID+1, func(*model.Tournament) error { return nil })
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	tr, err = s.Tournament().Update(context.Background(), tr.ID, func(tr *model.Tournament) error {
+		return tr.Start([]int{1, 2, 3})
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, model.TournamentRunning, tr.Status)
+
+	errFailed := errors.New("failed")
+	_, err = s.Tournament().Update(context.Background(), tr.ID, func(tr *model.Tournament) error {
+		tr.Bracket.Report(2, 2)
+		return errFailed
+	})
+	assert.EqualError(t, err, errFailed.Error())
+
+	tr, err = s.Tournament().Find(context.Background(), tr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, tournament.SingleElimination, tr.Bracket.Format)
+	assert.False(t, tr.Bracket.Matches[1].Done)
+	assert.EqualError(t, s.Tournament().Unregister(context.Background(), tr.ID, u.ID), model.ErrRegistrationClosed.Error())
+}
@@ -1,7 +1,9 @@
 package sqlstore
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
 	"github.com/GShamian/tavern-of-games/internal/app/model"
 	"github.com/GShamian/tavern-of-games/internal/app/store"
@@ -13,8 +15,9 @@ type UserRepository struct {
 }
 
 // Create func. Writing an email and encrypted password in the fields
-// in DB that match to imported User.
-func (r *UserRepository) Create(u *model.User) error {
+// in DB that match to imported User. Emails are unique, creating a user
+// with a taken email fails with store.ErrRecordExists.
+func (r *UserRepository) Create(ctx context.Context, u *model.User) error {
 	// Checking user's fields for incorrect entries
 	if err := u.Validate(); err != nil {
 		return err
@@ -23,23 +26,42 @@ func (r *UserRepository) Create(u *model.User) error {
 	if err := u.BeforeCreate(); err != nil {
 		return err
 	}
-	// Writing an email and ecrypted password in DB
-	return r.store.db.QueryRow("INSERT INTO users (email, encrypted_password) VALUES ($1, $2) RETURNING id",
+	// The query timeout only limits the query, not the validation and
+	// hashing of the password above
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+	// Writing an email, ecrypted password, role and registration date in DB
+	if err := r.store.conn().QueryRowContext(ctx, "INSERT INTO users (email, encrypted_password, role, created_at) VALUES ($1, $2, $3, $4) RETURNING id",
 		u.Email,
 		u.EncryptedPassword,
-	).Scan(&u.ID)
+		u.Role,
+		u.CreatedAt,
+	).Scan(&u.ID); err != nil {
+		if r.store.dialect.IsUniqueViolation(err) {
+			return store.ErrRecordExists
+		}
+		return err
+	}
+
+	return nil
 }
 
 // FindByEmail func. Finding user with the right (email we need) email
-func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
 	u := &model.User{}
-	if err := r.store.db.QueryRow(
-		"SELECT id, email, encrypted_password FROM users WHERE email = $1",
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT id, email, encrypted_password, role, created_at FROM users WHERE email = $1",
 		email,
 	).Scan(
 		&u.ID,
 		&u.Email,
 		&u.EncryptedPassword,
+		&u.Role,
+		&u.CreatedAt,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, store.ErrRecordNotFound
@@ -51,15 +73,21 @@ func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
 }
 
 // Find func. Finding user with the right (id we need) id
-func (r *UserRepository) Find(id int) (*model.User, error) {
+func (r *UserRepository) Find(ctx context.Context, id int) (*model.User, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
 	u := &model.User{}
-	if err := r.store.db.QueryRow(
-		"SELECT id, email, encrypted_password FROM users WHERE id = $1",
+	if err := r.store.conn().QueryRowContext(
+		ctx,
+		"SELECT id, email, encrypted_password, role, created_at FROM users WHERE id = $1",
 		id,
 	).Scan(
 		&u.ID,
 		&u.Email,
 		&u.EncryptedPassword,
+		&u.Role,
+		&u.CreatedAt,
 	); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, store.ErrRecordNotFound
@@ -69,3 +97,20 @@ func (r *UserRepository) Find(id int) (*model.User, error) {
 
 	return u, nil
 }
+
+// FindRegisteredBefore func. Finding ids of users registered before the time
+func (r *UserRepository) FindRegisteredBefore(ctx context.Context, t time.Time) ([]int, error) {
+	ctx, cancel := r.store.withTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.store.conn().QueryContext(
+		ctx,
+		"SELECT id FROM users WHERE created_at < $1 ORDER BY id",
+		t,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanIDs(rows)
+}
@@ -1,6 +1,30 @@
 package store
 
-// Store interface
+import "context"
+
+// Store interface. Repository calls take the context of the request
+// they serve and fail with its error once it's cancelled or its
+// deadline passes. WithTx runs fn with the store whose repositories
+// are bound to a transaction: their changes are kept if fn succeeds and
+// rolled back if it fails. fn can run more than once, if the
+// transaction conflicts with another one. WithTx of the store fn gets
+// joins the transaction.
 type Store interface {
+	WithTx(ctx context.Context, fn func(Store) error) error
 	User() UserRepository
+	Game() GameRepository
+	Library() LibraryRepository
+	Review() ReviewRepository
+	Friendship() FriendshipRepository
+	Chat() ChatRepository
+	Match() MatchRepository
+	Rating() RatingRepository
+	Tournament() TournamentRepository
+	Achievement() AchievementRepository
+	Guild() GuildRepository
+	LFG() LFGRepository
+	Event() EventRepository
+	Notification() NotificationRepository
+	Activity() ActivityRepository
+	Moderation() ModerationRepository
 }
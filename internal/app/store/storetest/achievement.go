@@ -0,0 +1,105 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+func testAchievementEvents(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 2)
+	u1, u2 := users[0], users[1]
+
+	assert.EqualError(t, s.Achievement().CreateEvent(ctx, &model.UserEvent{UserID: u2.ID + 1, Type: model.EventLogin}), store.ErrRecordNotFound.Error())
+
+	e := &model.UserEvent{UserID: u1.ID, Type: model.EventMatchWon, GameID: 1}
+	assert.NoError(t, s.Achievement().CreateEvent(ctx, e))
+	assert.NotZero(t, e.ID)
+	assert.False(t, e.CreatedAt.IsZero())
+	e2 := &model.UserEvent{UserID: u1.ID, Type: model.EventMatchWon, GameID: 2}
+	assert.NoError(t, s.Achievement().CreateEvent(ctx, e2))
+	assert.NotEqual(t, e.ID, e2.ID)
+	assert.NoError(t, s.Achievement().CreateEvent(ctx, &model.UserEvent{UserID: u2.ID, Type: model.EventMatchWon, GameID: 1}))
+	assert.NoError(t, s.Achievement().CreateEvent(ctx, &model.UserEvent{UserID: u2.ID, Type: model.EventLogin}))
+
+	// Changing created events doesn't change stored ones
+	e.Type = model.EventLogin
+
+	count, err := s.Achievement().CountEvents(ctx, u1.ID, model.EventMatchWon, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	count, err = s.Achievement().CountEvents(ctx, u1.ID, model.EventMatchWon, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	count, err = s.Achievement().CountEvents(ctx, u1.ID, model.EventLogin, 0)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	counts, err := s.Achievement().CountEventsByUser(ctx, model.EventMatchWon, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int{u1.ID: 1, u2.ID: 1}, counts)
+	counts, err = s.Achievement().CountEventsByUser(ctx, model.EventMatchWon, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int{u1.ID: 2, u2.ID: 1}, counts)
+
+	fingerprint, err := s.Achievement().Evaluated(ctx, "critic")
+	assert.NoError(t, err)
+	assert.Empty(t, fingerprint)
+	assert.NoError(t, s.Achievement().SetEvaluated(ctx, "critic", "count=5"))
+	assert.NoError(t, s.Achievement().SetEvaluated(ctx, "critic", "count=3"))
+	fingerprint, err = s.Achievement().Evaluated(ctx, "critic")
+	assert.NoError(t, err)
+	assert.Equal(t, "count=3", fingerprint)
+}
+
+func testAchievementProgress(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	u := createUsers(t, s, 1)[0]
+
+	assert.EqualError(t, s.Achievement().SaveProgress(ctx, &model.AchievementProgress{UserID: u.ID + 1, Code: "critic", Progress: 1, Target: 3}), store.ErrRecordNotFound.Error())
+
+	p := &model.AchievementProgress{UserID: u.ID, Code: "critic", Progress: 2, Target: 3}
+	assert.NoError(t, s.Achievement().SaveProgress(ctx, p))
+	assert.False(t, p.Unlocked())
+
+	// Progress is capped by the target and never decreases
+	p = &model.AchievementProgress{UserID: u.ID, Code: "critic", Progress: 5, Target: 3}
+	assert.NoError(t, s.Achievement().SaveProgress(ctx, p))
+	assert.True(t, p.Unlocked())
+	assert.Equal(t, 3, p.Progress)
+	unlockedAt := *p.UnlockedAt
+
+	p = &model.AchievementProgress{UserID: u.ID, Code: "critic", Progress: 1, Target: 3}
+	assert.NoError(t, s.Achievement().SaveProgress(ctx, p))
+	assert.True(t, p.Unlocked())
+	assert.Equal(t, 3, p.Progress)
+	assert.WithinDuration(t, unlockedAt, *p.UnlockedAt, time.Millisecond)
+
+	s.Achievement().SaveProgress(ctx, &model.AchievementProgress{UserID: u.ID, Code: "champion", Progress: 1, Target: 10})
+
+	progress, err := s.Achievement().FindProgress(ctx, u.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, progress, 2) {
+		assert.Equal(t, "champion", progress[0].Code)
+		assert.False(t, progress[0].Unlocked())
+		assert.Equal(t, "critic", progress[1].Code)
+		assert.True(t, progress[1].Unlocked())
+
+		// Changing found and saved progress doesn't change stored one
+		progress[0].Progress = 9
+		p.Progress = 0
+		progress, _ = s.Achievement().FindProgress(ctx, u.ID)
+		assert.Equal(t, 1, progress[0].Progress)
+		assert.Equal(t, 3, progress[1].Progress)
+	}
+
+	progress, err = s.Achievement().FindProgress(ctx, u.ID+1)
+	assert.NoError(t, err)
+	assert.Empty(t, progress)
+}
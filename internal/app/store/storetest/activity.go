@@ -0,0 +1,67 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+func testActivity(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 3)
+	u1, u2, u3 := users[0], users[1], users[2]
+	g := createGame(t, s)
+
+	assert.EqualError(t, s.Activity().Create(ctx, model.TestActivity(t, u1.ID, g.ID+1)), store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.Activity().Create(ctx, model.TestActivity(t, u3.ID+1, g.ID)), store.ErrRecordNotFound.Error())
+
+	a := model.TestActivity(t, u1.ID, 0)
+	a.Type = model.ActivityAchievementUnlocked
+	assert.NoError(t, s.Activity().Create(ctx, a))
+	assert.NotZero(t, a.ID)
+	assert.False(t, a.CreatedAt.IsZero())
+	s.Activity().Create(ctx, model.TestActivity(t, u2.ID, g.ID))
+	s.Activity().Create(ctx, model.TestActivity(t, u3.ID, g.ID))
+	last := model.TestActivity(t, u1.ID, g.ID)
+	s.Activity().Create(ctx, last)
+	assert.NotEqual(t, a.ID, last.ID)
+
+	// Feeds are merged from activities of every user, newest first
+	activities, err := s.Activity().FindByUsers(ctx, []int{u1.ID, u2.ID}, 0, 2)
+	assert.NoError(t, err)
+	if !assert.Len(t, activities, 2) {
+		return
+	}
+	assert.Equal(t, last.ID, activities[0].ID)
+	assert.Equal(t, g.ID, activities[0].GameID)
+	assert.Equal(t, u2.ID, activities[1].UserID)
+
+	older, err := s.Activity().FindByUsers(ctx, []int{u1.ID, u2.ID}, activities[1].ID, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, older, 1) {
+		assert.Equal(t, a.ID, older[0].ID)
+		assert.Equal(t, model.ActivityAchievementUnlocked, older[0].Type)
+		assert.Zero(t, older[0].GameID)
+	}
+
+	// Changing created and found activities doesn't change stored ones
+	a.Text = "Changed"
+	activities[0].Text = "Changed"
+	activities, _ = s.Activity().FindByUsers(ctx, []int{u1.ID}, 0, 10)
+	if assert.Len(t, activities, 2) {
+		assert.Equal(t, model.TestActivity(t, 0, 0).Text, activities[0].Text)
+		assert.Equal(t, model.TestActivity(t, 0, 0).Text, activities[1].Text)
+	}
+
+	activities, err = s.Activity().FindByUsers(ctx, []int{u3.ID}, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, activities, 1)
+
+	activities, err = s.Activity().FindByUsers(ctx, nil, 0, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, activities)
+}
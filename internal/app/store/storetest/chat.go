@@ -0,0 +1,59 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+func testChat(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	u := createUsers(t, s, 1)[0]
+
+	assert.EqualError(t, s.Chat().Create(ctx, model.TestChatMessage(t, model.ChatRoomHall, u.ID+1)), store.ErrRecordNotFound.Error())
+	assert.Error(t, s.Chat().Create(ctx, model.TestChatMessage(t, "", u.ID)))
+
+	messages := make([]*model.ChatMessage, 5)
+	for i := range messages {
+		messages[i] = model.TestChatMessage(t, model.ChatRoomHall, u.ID)
+		assert.NoError(t, s.Chat().Create(ctx, messages[i]))
+	}
+	assert.NotZero(t, messages[0].ID)
+	assert.NotEqual(t, messages[0].ID, messages[1].ID)
+	s.Chat().Create(ctx, model.TestChatMessage(t, model.GameChatRoom(1), u.ID))
+
+	page, err := s.Chat().FindByRoom(ctx, model.ChatRoomHall, 0, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, page, 2) {
+		assert.Equal(t, messages[4].ID, page[0].ID)
+		assert.Equal(t, messages[3].ID, page[1].ID)
+
+		page, err = s.Chat().FindByRoom(ctx, model.ChatRoomHall, page[1].ID, 10)
+		assert.NoError(t, err)
+		if assert.Len(t, page, 3) {
+			assert.Equal(t, messages[2].ID, page[0].ID)
+		}
+	}
+
+	// Changing created and found messages doesn't change stored ones
+	messages[0].Text = "Changed"
+	page[2].Text = "Changed"
+	found, err := s.Chat().Find(ctx, messages[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestChatMessage(t, "", 0).Text, found.Text)
+	found.Text = "Changed"
+	found, _ = s.Chat().Find(ctx, messages[0].ID)
+	assert.Equal(t, model.TestChatMessage(t, "", 0).Text, found.Text)
+
+	assert.NoError(t, s.Chat().Delete(ctx, messages[0].ID))
+	_, err = s.Chat().Find(ctx, messages[0].ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.Chat().Delete(ctx, messages[0].ID), store.ErrRecordNotFound.Error())
+	page, err = s.Chat().FindByRoom(ctx, model.ChatRoomHall, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, page, 4)
+}
@@ -0,0 +1,211 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+func testEvent(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	u := createUsers(t, s, 1)[0]
+	g := createGame(t, s)
+
+	assert.EqualError(t, s.Event().Create(ctx, model.TestEvent(t, u.ID, g.ID+1)), store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.Event().Create(ctx, model.TestEvent(t, u.ID+1, g.ID)), store.ErrRecordNotFound.Error())
+
+	e1 := model.TestEvent(t, u.ID, g.ID)
+	e1.Recurrence = ""
+	assert.NoError(t, s.Event().Create(ctx, e1))
+	assert.NotZero(t, e1.ID)
+
+	e2 := model.TestEvent(t, u.ID, g.ID)
+	e2.StartsAt = e1.StartsAt.Add(-time.Hour)
+	until := e2.StartsAt.Add(7 * 24 * time.Hour)
+	e2.RepeatUntil = &until
+	assert.NoError(t, s.Event().Create(ctx, e2))
+	assert.NotEqual(t, e1.ID, e2.ID)
+
+	found, err := s.Event().Find(ctx, e2.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", found.Timezone)
+	assert.Equal(t, model.EventWeekly, found.Recurrence)
+	assert.True(t, e2.StartsAt.Equal(found.StartsAt))
+	if assert.NotNil(t, found.RepeatUntil) {
+		assert.True(t, until.Equal(*found.RepeatUntil))
+	}
+	_, err = s.Event().Find(ctx, e2.ID+1)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	// Changing created and found events doesn't change stored ones
+	e2.Title = "Changed"
+	*e2.RepeatUntil = e2.StartsAt
+	*found.RepeatUntil = found.StartsAt
+	found, _ = s.Event().Find(ctx, e2.ID)
+	assert.Equal(t, model.TestEvent(t, 0, 0).Title, found.Title)
+	if assert.NotNil(t, found.RepeatUntil) {
+		assert.True(t, until.Equal(*found.RepeatUntil))
+	}
+
+	q := &store.ListQuery{Limit: 10}
+	events, err := s.Event().FindUpcoming(ctx, time.Now(), q)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, e2.ID, events[0].ID)
+		assert.Equal(t, e1.ID, events[1].ID)
+	}
+	events, err = s.Event().FindUpcoming(ctx, time.Now(), &store.ListQuery{Limit: 10, Offset: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, e1.ID, events[0].ID)
+	}
+
+	// Weekly events are over after the last occurrence
+	events, err = s.Event().FindUpcoming(ctx, e1.EndsAt, q)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, e2.ID, events[0].ID)
+	}
+	events, err = s.Event().FindUpcoming(ctx, until.Add(e2.EndsAt.Sub(e2.StartsAt)), q)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func testEventRSVP(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 3)
+	u1, u2, u3 := users[0], users[1], users[2]
+	g := createGame(t, s)
+
+	e := model.TestEvent(t, u1.ID, g.ID)
+	e.Capacity = 1
+	s.Event().Create(ctx, e)
+
+	_, err := s.Event().RSVP(ctx, e.ID+1, u1.ID, model.RSVPGoing)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	_, err = s.Event().RSVP(ctx, e.ID, u3.ID+1, model.RSVPGoing)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	_, err = s.Event().RSVP(ctx, e.ID, u1.ID, model.RSVPWaitlisted)
+	assert.Error(t, err)
+
+	changed, err := s.Event().RSVP(ctx, e.ID, u1.ID, model.RSVPGoing)
+	assert.NoError(t, err)
+	if assert.Len(t, changed, 1) {
+		assert.Equal(t, u1.ID, changed[0].UserID)
+		assert.Equal(t, model.RSVPGoing, changed[0].Status)
+	}
+
+	// The event is full, so the next user waits
+	changed, err = s.Event().RSVP(ctx, e.ID, u2.ID, model.RSVPGoing)
+	assert.NoError(t, err)
+	if assert.Len(t, changed, 1) {
+		assert.Equal(t, model.RSVPWaitlisted, changed[0].Status)
+
+		// Changing returned RSVPs doesn't change stored ones
+		changed[0].Status = model.RSVPGoing
+	}
+
+	s.Event().RSVP(ctx, e.ID, u3.ID, model.RSVPMaybe)
+
+	events, err := s.Event().FindByAttendee(ctx, u2.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, e.ID, events[0].ID)
+		events[0].Capacity = 10
+	}
+
+	// The first user of the waitlist goes instead of the one who changed
+	// their mind
+	changed, err = s.Event().RSVP(ctx, e.ID, u1.ID, model.RSVPDeclined)
+	assert.NoError(t, err)
+	if assert.Len(t, changed, 2) {
+		assert.Equal(t, u1.ID, changed[0].UserID)
+		assert.Equal(t, model.RSVPDeclined, changed[0].Status)
+		assert.Equal(t, u2.ID, changed[1].UserID)
+		assert.Equal(t, model.RSVPGoing, changed[1].Status)
+	}
+
+	rsvps, err := s.Event().RSVPs(ctx, e.ID)
+	assert.NoError(t, err)
+	statuses := map[int]string{}
+	for _, rsvp := range rsvps {
+		statuses[rsvp.UserID] = rsvp.Status
+		rsvp.Status = model.RSVPWaitlisted
+	}
+	assert.Equal(t, map[int]string{
+		u1.ID: model.RSVPDeclined,
+		u2.ID: model.RSVPGoing,
+		u3.ID: model.RSVPMaybe,
+	}, statuses)
+
+	events, err = s.Event().FindByAttendee(ctx, u1.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+
+	// The event is full again, nobody else waits
+	changed, err = s.Event().RSVP(ctx, e.ID, u1.ID, model.RSVPGoing)
+	assert.NoError(t, err)
+	if assert.Len(t, changed, 1) {
+		assert.Equal(t, model.RSVPWaitlisted, changed[0].Status)
+	}
+
+	rsvps, err = s.Event().RSVPs(ctx, e.ID+1)
+	assert.NoError(t, err)
+	assert.Empty(t, rsvps)
+
+	over := model.TestEvent(t, u1.ID, g.ID)
+	over.Recurrence = ""
+	over.StartsAt = time.Now().UTC().Add(-2 * time.Hour).Truncate(time.Second)
+	over.EndsAt = over.StartsAt.Add(time.Hour)
+	s.Event().Create(ctx, over)
+	_, err = s.Event().RSVP(ctx, over.ID, u1.ID, model.RSVPGoing)
+	assert.EqualError(t, err, model.ErrEventOver.Error())
+}
+
+func testEventCalendarToken(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 2)
+	u := users[0]
+
+	missing, _ := model.NewCalendarToken(users[1].ID + 1)
+	assert.EqualError(t, s.Event().SetCalendarToken(ctx, missing), store.ErrRecordNotFound.Error())
+
+	old, err := model.NewCalendarToken(u.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Event().SetCalendarToken(ctx, old))
+	found, err := s.Event().FindCalendarToken(ctx, model.HashCalendarToken(old.Token))
+	assert.NoError(t, err)
+	assert.Equal(t, u.ID, found.UserID)
+	assert.Equal(t, old.Hash, found.Hash)
+	assert.Empty(t, found.Token)
+
+	// Changing set and found tokens doesn't change stored ones
+	old.UserID = users[1].ID
+	found.UserID = users[1].ID
+	found, _ = s.Event().FindCalendarToken(ctx, old.Hash)
+	assert.Equal(t, u.ID, found.UserID)
+
+	// A new token replaces the old one, tokens of other users stay
+	other, _ := model.NewCalendarToken(users[1].ID)
+	assert.NoError(t, s.Event().SetCalendarToken(ctx, other))
+	token, _ := model.NewCalendarToken(u.ID)
+	assert.NoError(t, s.Event().SetCalendarToken(ctx, token))
+	_, err = s.Event().FindCalendarToken(ctx, old.Hash)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	found, err = s.Event().FindCalendarToken(ctx, token.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, u.ID, found.UserID)
+
+	assert.NoError(t, s.Event().RevokeCalendarToken(ctx, u.ID))
+	assert.NoError(t, s.Event().RevokeCalendarToken(ctx, u.ID))
+	_, err = s.Event().FindCalendarToken(ctx, token.Hash)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	found, err = s.Event().FindCalendarToken(ctx, other.Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, users[1].ID, found.UserID)
+}
@@ -0,0 +1,184 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+func testFriendshipTransitions(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	const (
+		none     = ""
+		pending  = model.FriendshipPending
+		accepted = model.FriendshipAccepted
+	)
+
+	// States are the ones of the friendship requested by the first user
+	tests := []struct {
+		state   string
+		byOther bool
+		action  string
+		next    string
+		err     error
+	}{
+		{none, false, model.FriendshipRequest, pending, nil},
+		{none, false, model.FriendshipAccept, none, model.ErrInvalidFriendshipTransition},
+		{none, false, model.FriendshipDecline, none, model.ErrInvalidFriendshipTransition},
+		{none, false, model.FriendshipCancel, none, model.ErrInvalidFriendshipTransition},
+		{none, false, model.FriendshipUnfriend, none, model.ErrInvalidFriendshipTransition},
+		{pending, true, model.FriendshipAccept, accepted, nil},
+		{pending, true, model.FriendshipRequest, accepted, nil},
+		{pending, true, model.FriendshipDecline, none, nil},
+		{pending, true, model.FriendshipCancel, pending, model.ErrInvalidFriendshipTransition},
+		{pending, true, model.FriendshipUnfriend, pending, model.ErrInvalidFriendshipTransition},
+		{pending, false, model.FriendshipCancel, none, nil},
+		{pending, false, model.FriendshipRequest, pending, model.ErrInvalidFriendshipTransition},
+		{pending, false, model.FriendshipAccept, pending, model.ErrInvalidFriendshipTransition},
+		{pending, false, model.FriendshipDecline, pending, model.ErrInvalidFriendshipTransition},
+		{accepted, false, model.FriendshipUnfriend, none, nil},
+		{accepted, true, model.FriendshipUnfriend, none, nil},
+		{accepted, true, model.FriendshipRequest, accepted, model.ErrInvalidFriendshipTransition},
+		{accepted, false, model.FriendshipAccept, accepted, model.ErrInvalidFriendshipTransition},
+		{accepted, true, model.FriendshipCancel, accepted, model.ErrInvalidFriendshipTransition},
+		{accepted, false, model.FriendshipDecline, accepted, model.ErrInvalidFriendshipTransition},
+	}
+
+	for _, tc := range tests {
+		users := createUsers(t, s, 2)
+		u, other := users[0], users[1]
+		if tc.state != none {
+			s.Friendship().Apply(ctx, u.ID, other.ID, model.FriendshipRequest)
+		}
+		if tc.state == accepted {
+			s.Friendship().Apply(ctx, other.ID, u.ID, model.FriendshipAccept)
+		}
+
+		actor, target := u, other
+		if tc.byOther {
+			actor, target = other, u
+		}
+		f, err := s.Friendship().Apply(ctx, actor.ID, target.ID, tc.action)
+		if tc.err != nil {
+			assert.EqualError(t, err, tc.err.Error(), "%s %s by other %t", tc.state, tc.action, tc.byOther)
+		} else {
+			assert.NoError(t, err, "%s %s by other %t", tc.state, tc.action, tc.byOther)
+		}
+
+		found, err := s.Friendship().Find(ctx, other.ID, u.ID)
+		if tc.next == none {
+			assert.EqualError(t, err, store.ErrRecordNotFound.Error(), "%s %s by other %t", tc.state, tc.action, tc.byOther)
+			if tc.err == nil {
+				assert.Nil(t, f)
+			}
+			continue
+		}
+
+		if assert.NoError(t, err, "%s %s by other %t", tc.state, tc.action, tc.byOther) {
+			assert.Equal(t, tc.next, found.Status, "%s %s by other %t", tc.state, tc.action, tc.byOther)
+			assert.Equal(t, u.ID, found.UserID)
+			assert.Equal(t, other.ID, found.FriendID)
+		}
+		if tc.err == nil && assert.NotNil(t, f) {
+			assert.Equal(t, tc.next, f.Status)
+		}
+	}
+
+	// Nobody is friends with themselves or with missing users
+	u := createUsers(t, s, 1)[0]
+	_, err := s.Friendship().Apply(ctx, u.ID, u.ID, model.FriendshipRequest)
+	assert.EqualError(t, err, model.ErrInvalidFriendshipTransition.Error())
+	_, err = s.Friendship().Apply(ctx, u.ID, u.ID+1, model.FriendshipRequest)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
+
+func testFriendshipLists(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 4)
+	befriend := func(a, b *model.User) {
+		s.Friendship().Apply(ctx, a.ID, b.ID, model.FriendshipRequest)
+		s.Friendship().Apply(ctx, b.ID, a.ID, model.FriendshipAccept)
+	}
+	befriend(users[0], users[2])
+	befriend(users[1], users[2])
+	befriend(users[0], users[3])
+	s.Friendship().Apply(ctx, users[1].ID, users[3].ID, model.FriendshipRequest)
+
+	ids, err := s.Friendship().Mutual(ctx, users[0].ID, users[1].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{users[2].ID}, ids)
+
+	ids, err = s.Friendship().Mutual(ctx, users[2].ID, users[3].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{users[0].ID}, ids)
+
+	friendships, err := s.Friendship().FindByUser(ctx, users[0].ID, model.FriendshipAccepted)
+	assert.NoError(t, err)
+	assert.Len(t, friendships, 2)
+
+	friendships, err = s.Friendship().FindByUser(ctx, users[3].ID, model.FriendshipPending)
+	assert.NoError(t, err)
+	if assert.Len(t, friendships, 1) {
+		assert.Equal(t, users[1].ID, friendships[0].UserID)
+
+		// Changing found friendships doesn't change stored ones
+		friendships[0].Status = model.FriendshipAccepted
+		f, err := s.Friendship().Find(ctx, users[1].ID, users[3].ID)
+		assert.NoError(t, err)
+		assert.Equal(t, model.FriendshipPending, f.Status)
+		f.Status = model.FriendshipAccepted
+		f, _ = s.Friendship().Find(ctx, users[1].ID, users[3].ID)
+		assert.Equal(t, model.FriendshipPending, f.Status)
+	}
+}
+
+func testFriendshipBlock(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 3)
+	u1, u2, u3 := users[0], users[1], users[2]
+
+	assert.EqualError(t, s.Friendship().Block(ctx, u1.ID, u1.ID), model.ErrInvalidFriendshipTransition.Error())
+	assert.EqualError(t, s.Friendship().Block(ctx, u1.ID, u3.ID+1), store.ErrRecordNotFound.Error())
+
+	// Blocking removes the friendship in any direction
+	s.Friendship().Apply(ctx, u1.ID, u2.ID, model.FriendshipRequest)
+	assert.NoError(t, s.Friendship().Block(ctx, u2.ID, u1.ID))
+	assert.NoError(t, s.Friendship().Block(ctx, u2.ID, u1.ID))
+	_, err := s.Friendship().Find(ctx, u1.ID, u2.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	for _, pair := range [][2]int{{u1.ID, u2.ID}, {u2.ID, u1.ID}} {
+		blocked, err := s.Friendship().IsBlocked(ctx, pair[0], pair[1])
+		assert.NoError(t, err)
+		assert.True(t, blocked)
+
+		_, err = s.Friendship().Apply(ctx, pair[0], pair[1], model.FriendshipRequest)
+		assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	}
+
+	blocked, err := s.Friendship().IsBlocked(ctx, u1.ID, u3.ID)
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+
+	assert.NoError(t, s.Friendship().Block(ctx, u2.ID, u3.ID))
+	ids, err := s.Friendship().Blocked(ctx, u2.ID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int{u1.ID, u3.ID}, ids)
+	ids, err = s.Friendship().Blocked(ctx, u1.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, ids)
+
+	assert.NoError(t, s.Friendship().Unblock(ctx, u2.ID, u1.ID))
+	assert.EqualError(t, s.Friendship().Unblock(ctx, u2.ID, u1.ID), store.ErrRecordNotFound.Error())
+	blocked, err = s.Friendship().IsBlocked(ctx, u1.ID, u2.ID)
+	assert.NoError(t, err)
+	assert.False(t, blocked)
+
+	f, err := s.Friendship().Apply(ctx, u1.ID, u2.ID, model.FriendshipRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, model.FriendshipPending, f.Status)
+}
@@ -0,0 +1,263 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+func testGuild(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 3)
+
+	assert.EqualError(t, s.Guild().Create(ctx, model.TestGuild(t), users[2].ID+1), store.ErrRecordNotFound.Error())
+
+	g := model.TestGuild(t)
+	assert.NoError(t, s.Guild().Create(ctx, g, users[0].ID))
+	assert.NotZero(t, g.ID)
+	assert.Equal(t, 1, g.MemberCount)
+
+	// Names are unique regardless of case, tags are unique, and users
+	// lead one guild at most
+	other := model.TestGuild(t)
+	other.Name = "knights of the round table"
+	other.Tag = "RT"
+	assert.EqualError(t, s.Guild().Create(ctx, other, users[1].ID), store.ErrRecordExists.Error())
+	other = model.TestGuild(t)
+	other.Name = "Round Table"
+	assert.EqualError(t, s.Guild().Create(ctx, other, users[1].ID), store.ErrRecordExists.Error())
+	other.Tag = "RT"
+	assert.EqualError(t, s.Guild().Create(ctx, other, users[0].ID), store.ErrRecordExists.Error())
+	assert.NoError(t, s.Guild().Create(ctx, other, users[1].ID))
+	assert.NotEqual(t, g.ID, other.ID)
+
+	// Changing created and found guilds doesn't change stored ones
+	g.Name = "Changed"
+	found, err := s.Guild().Find(ctx, g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestGuild(t).Name, found.Name)
+	assert.Equal(t, 1, found.MemberCount)
+	found.Tag = "CHG"
+	found, _ = s.Guild().Find(ctx, g.ID)
+	assert.Equal(t, model.TestGuild(t).Tag, found.Tag)
+	_, err = s.Guild().Find(ctx, other.ID+1)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	guilds, err := s.Guild().List(ctx, &store.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, guilds, 2) {
+		assert.Equal(t, g.ID, guilds[0].ID)
+		assert.Equal(t, other.ID, guilds[1].ID)
+		assert.Equal(t, 1, guilds[1].MemberCount)
+		guilds[0].Name = "Changed"
+	}
+	guilds, err = s.Guild().List(ctx, &store.ListQuery{Limit: 10, Offset: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, guilds, 1) {
+		assert.Equal(t, other.ID, guilds[0].ID)
+	}
+
+	update := model.TestGuild(t)
+	update.ID = other.ID
+	assert.EqualError(t, s.Guild().Update(ctx, update), store.ErrRecordExists.Error())
+	update.Name = "The Round Table"
+	update.Tag = "TRT"
+	update.Description = "We play on Saturdays"
+	assert.NoError(t, s.Guild().Update(ctx, update))
+	found, err = s.Guild().Find(ctx, other.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "The Round Table", found.Name)
+	assert.Equal(t, "TRT", found.Tag)
+	assert.Equal(t, "We play on Saturdays", found.Description)
+	update.ID = other.ID + 1
+	assert.EqualError(t, s.Guild().Update(ctx, update), store.ErrRecordNotFound.Error())
+
+	assert.NoError(t, s.Guild().Delete(ctx, other.ID))
+	assert.EqualError(t, s.Guild().Delete(ctx, other.ID), store.ErrRecordNotFound.Error())
+	_, err = s.Guild().MemberOf(ctx, users[1].ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
+
+func testGuildInvites(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 3)
+	leader, u2, u3 := users[0], users[1], users[2]
+
+	g := model.TestGuild(t)
+	s.Guild().Create(ctx, g, leader.ID)
+	other := model.TestGuild(t)
+	other.Name = "Round Table"
+	other.Tag = "RT"
+	s.Guild().Create(ctx, other, u3.ID)
+
+	_, err := s.Guild().Invite(ctx, &model.GuildInvite{GuildID: other.ID + 1, UserID: u2.ID, Kind: model.GuildJoinRequest})
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	_, err = s.Guild().Invite(ctx, &model.GuildInvite{GuildID: g.ID, UserID: leader.ID, Kind: model.GuildJoinRequest})
+	assert.EqualError(t, err, store.ErrRecordExists.Error())
+
+	invitation := &model.GuildInvite{GuildID: g.ID, UserID: u2.ID, Kind: model.GuildInvitation, InviterID: leader.ID}
+	m, err := s.Guild().Invite(ctx, invitation)
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+	_, err = s.Guild().Invite(ctx, invitation)
+	assert.EqualError(t, err, model.ErrGuildInviteExists.Error())
+
+	request := &model.GuildInvite{GuildID: other.ID, UserID: u2.ID, Kind: model.GuildJoinRequest}
+	m, err = s.Guild().Invite(ctx, request)
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+
+	invites, err := s.Guild().InvitesOf(ctx, u2.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, invites, 2) {
+		assert.Equal(t, g.ID, invites[0].GuildID)
+		assert.Equal(t, model.GuildInvitation, invites[0].Kind)
+
+		// Changing found invites doesn't change stored ones
+		invites[0].Kind = model.GuildJoinRequest
+		invites, _ = s.Guild().Invites(ctx, g.ID)
+		assert.Equal(t, model.GuildInvitation, invites[0].Kind)
+	}
+
+	// The opposite invite accepts the pending one, other invites of the
+	// user are gone then
+	m, err = s.Guild().Invite(ctx, &model.GuildInvite{GuildID: g.ID, UserID: u2.ID, Kind: model.GuildJoinRequest})
+	assert.NoError(t, err)
+	if assert.NotNil(t, m) {
+		assert.Equal(t, model.GuildRoleMember, m.Role)
+		assert.Equal(t, g.ID, m.GuildID)
+	}
+	invites, err = s.Guild().InvitesOf(ctx, u2.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, invites)
+	invites, err = s.Guild().Invites(ctx, other.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, invites)
+
+	found, err := s.Guild().Find(ctx, g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, found.MemberCount)
+
+	assert.EqualError(t, s.Guild().RemoveInvite(ctx, g.ID, u2.ID), store.ErrRecordNotFound.Error())
+	_, err = s.Guild().Invite(ctx, &model.GuildInvite{GuildID: g.ID, UserID: u2.ID, Kind: model.GuildJoinRequest})
+	assert.EqualError(t, err, store.ErrRecordExists.Error())
+
+	members, err := s.Guild().Members(ctx, g.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, members, 2) {
+		assert.Equal(t, leader.ID, members[0].UserID)
+		assert.Equal(t, u2.ID, members[1].UserID)
+	}
+}
+
+func testGuildRoles(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 3)
+	leader, u2, u3 := users[0], users[1], users[2]
+
+	g := model.TestGuild(t)
+	s.Guild().Create(ctx, g, leader.ID)
+	for _, u := range []*model.User{u2, u3} {
+		s.Guild().Invite(ctx, &model.GuildInvite{GuildID: g.ID, UserID: u.ID, Kind: model.GuildJoinRequest})
+		s.Guild().Invite(ctx, &model.GuildInvite{GuildID: g.ID, UserID: u.ID, Kind: model.GuildInvitation, InviterID: leader.ID})
+	}
+
+	officer := &model.GuildMember{GuildID: g.ID, UserID: u3.ID, Role: model.GuildRoleOfficer}
+	assert.NoError(t, s.Guild().SetRole(ctx, officer))
+	assert.False(t, officer.JoinedAt.IsZero())
+	assert.Error(t, s.Guild().SetRole(ctx, &model.GuildMember{GuildID: g.ID, UserID: u2.ID, Role: model.GuildRoleLeader}))
+	assert.EqualError(t, s.Guild().SetRole(ctx, &model.GuildMember{GuildID: g.ID, UserID: leader.ID, Role: model.GuildRoleMember}), model.ErrGuildLeader.Error())
+	assert.EqualError(t, s.Guild().SetRole(ctx, &model.GuildMember{GuildID: g.ID + 1, UserID: u2.ID, Role: model.GuildRoleOfficer}), store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.Guild().RemoveMember(ctx, g.ID, leader.ID), model.ErrGuildLeader.Error())
+
+	// The leader goes first, then officers and members
+	members, err := s.Guild().Members(ctx, g.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, members, 3) {
+		assert.Equal(t, leader.ID, members[0].UserID)
+		assert.Equal(t, u3.ID, members[1].UserID)
+		assert.Equal(t, u2.ID, members[2].UserID)
+
+		// Changing found members doesn't change stored ones
+		members[2].Role = model.GuildRoleOfficer
+		m, _ := s.Guild().Member(ctx, g.ID, u2.ID)
+		assert.Equal(t, model.GuildRoleMember, m.Role)
+		m.Role = model.GuildRoleOfficer
+		m, _ = s.Guild().MemberOf(ctx, u2.ID)
+		assert.Equal(t, model.GuildRoleMember, m.Role)
+	}
+
+	assert.EqualError(t, s.Guild().Transfer(ctx, g.ID, u2.ID, leader.ID), store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.Guild().Transfer(ctx, g.ID, leader.ID, u3.ID+1), store.ErrRecordNotFound.Error())
+	assert.NoError(t, s.Guild().Transfer(ctx, g.ID, leader.ID, u2.ID))
+
+	m, err := s.Guild().Member(ctx, g.ID, leader.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.GuildRoleOfficer, m.Role)
+	m, err = s.Guild().Member(ctx, g.ID, u2.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.GuildRoleLeader, m.Role)
+	_, err = s.Guild().Member(ctx, g.ID+1, u2.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	assert.NoError(t, s.Guild().RemoveMember(ctx, g.ID, leader.ID))
+	assert.EqualError(t, s.Guild().RemoveMember(ctx, g.ID, leader.ID), store.ErrRecordNotFound.Error())
+	_, err = s.Guild().MemberOf(ctx, leader.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	assert.NoError(t, s.Guild().Delete(ctx, g.ID))
+	_, err = s.Guild().MemberOf(ctx, u2.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
+
+func testGuildLeaderboard(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 3)
+	game := createGame(t, s)
+
+	g1 := model.TestGuild(t)
+	s.Guild().Create(ctx, g1, users[0].ID)
+	g2 := model.TestGuild(t)
+	g2.Name = "Round Table"
+	g2.Tag = "RT"
+	s.Guild().Create(ctx, g2, users[1].ID)
+
+	m := model.TestMatch(t, game.ID, users[0].ID, users[1].ID)
+	s.Match().Create(ctx, m)
+	m.Confirm(users[1].ID)
+	s.Match().Update(ctx, m)
+	// Players without guilds aren't counted
+	m2 := model.TestMatch(t, game.ID, users[0].ID, users[2].ID)
+	s.Match().Create(ctx, m2)
+	m2.Confirm(users[2].ID)
+	s.Match().Update(ctx, m2)
+
+	entries, err := s.Guild().Leaderboard(ctx, game.ID, m.Season, &store.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, 1, entries[0].Rank)
+		assert.Equal(t, g1.ID, entries[0].GuildID)
+		assert.Equal(t, g1.Name, entries[0].Name)
+		assert.Equal(t, g1.Tag, entries[0].Tag)
+		assert.Equal(t, 1, entries[0].Players)
+		assert.Greater(t, entries[0].Rating, entries[1].Rating)
+	}
+
+	entries, err = s.Guild().Leaderboard(ctx, game.ID, m.Season, &store.ListQuery{Limit: 10, Offset: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, 2, entries[0].Rank)
+	}
+
+	e, err := s.Guild().Rank(ctx, g2.ID, game.ID, m.Season)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, e.Rank)
+	assert.Equal(t, g2.ID, e.GuildID)
+
+	_, err = s.Guild().Rank(ctx, g2.ID, game.ID, "2019-Q1")
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
@@ -0,0 +1,170 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+func testLFG(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	u := createUsers(t, s, 1)[0]
+	g := createGame(t, s)
+
+	assert.EqualError(t, s.LFG().Create(ctx, model.TestLFGPost(t, u.ID, g.ID+1)), store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.LFG().Create(ctx, model.TestLFGPost(t, u.ID+1, g.ID)), store.ErrRecordNotFound.Error())
+
+	p1 := model.TestLFGPost(t, u.ID, g.ID)
+	assert.NoError(t, s.LFG().Create(ctx, p1))
+	assert.NotZero(t, p1.ID)
+	assert.Equal(t, model.LFGOpen, p1.Status)
+	assert.False(t, p1.ExpiresAt.IsZero())
+
+	p2 := model.TestLFGPost(t, u.ID, g.ID)
+	p2.Platform = model.PlatformTabletop
+	p2.Language = "de"
+	p2.StartsAt = p1.StartsAt.Add(-30 * time.Minute)
+	assert.NoError(t, s.LFG().Create(ctx, p2))
+	assert.NotEqual(t, p1.ID, p2.ID)
+
+	// Changing created and found posts doesn't change stored ones
+	p1.Description = "Changed"
+	found, err := s.LFG().Find(ctx, p1.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, found.Description)
+	found.Status = model.LFGClosed
+	found, _ = s.LFG().Find(ctx, p1.ID)
+	assert.Equal(t, model.LFGOpen, found.Status)
+	_, err = s.LFG().Find(ctx, p2.ID+1)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	now := time.Now()
+	q := &store.ListQuery{Limit: 10}
+	posts, err := s.LFG().FindOpen(ctx, &store.LFGFilter{}, now, q)
+	assert.NoError(t, err)
+	if assert.Len(t, posts, 2) {
+		assert.Equal(t, p2.ID, posts[0].ID)
+		assert.Equal(t, p1.ID, posts[1].ID)
+		posts[0].Language = "fr"
+	}
+	posts, err = s.LFG().FindOpen(ctx, &store.LFGFilter{}, now, &store.ListQuery{Limit: 10, Offset: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, posts, 1) {
+		assert.Equal(t, p1.ID, posts[0].ID)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		filter *store.LFGFilter
+		ids    []int
+	}{
+		{
+			name:   "game",
+			filter: &store.LFGFilter{GameID: g.ID},
+			ids:    []int{p2.ID, p1.ID},
+		},
+		{
+			name:   "other game",
+			filter: &store.LFGFilter{GameID: g.ID + 1},
+			ids:    []int{},
+		},
+		{
+			name:   "platform and language",
+			filter: &store.LFGFilter{Platform: model.PlatformPC, Language: "en"},
+			ids:    []int{p1.ID},
+		},
+		{
+			name:   "language",
+			filter: &store.LFGFilter{Language: "de"},
+			ids:    []int{p2.ID},
+		},
+		{
+			name:   "from",
+			filter: &store.LFGFilter{From: p1.StartsAt.Add(-time.Minute)},
+			ids:    []int{p1.ID},
+		},
+		{
+			name:   "to",
+			filter: &store.LFGFilter{To: p1.StartsAt.Add(-time.Minute)},
+			ids:    []int{p2.ID},
+		},
+	} {
+		posts, err := s.LFG().FindOpen(ctx, tc.filter, now, q)
+		assert.NoError(t, err, tc.name)
+		ids := []int{}
+		for _, p := range posts {
+			ids = append(ids, p.ID)
+		}
+		assert.Equal(t, tc.ids, ids, tc.name)
+	}
+
+	// Expired posts aren't listed even before they're marked expired
+	posts, err = s.LFG().FindOpen(ctx, &store.LFGFilter{}, p1.ExpiresAt, q)
+	assert.NoError(t, err)
+	assert.Empty(t, posts)
+
+	n, err := s.LFG().Expire(ctx, p2.ExpiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	found, err = s.LFG().Find(ctx, p2.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LFGExpired, found.Status)
+	assert.EqualError(t, s.LFG().Close(ctx, p2.ID), model.ErrLFGPostClosed.Error())
+
+	assert.NoError(t, s.LFG().Close(ctx, p1.ID))
+	assert.EqualError(t, s.LFG().Close(ctx, p1.ID), model.ErrLFGPostClosed.Error())
+	assert.EqualError(t, s.LFG().Close(ctx, p2.ID+1), store.ErrRecordNotFound.Error())
+	found, err = s.LFG().Find(ctx, p1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LFGClosed, found.Status)
+
+	n, err = s.LFG().Expire(ctx, p1.ExpiresAt)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func testLFGRequests(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 3)
+	g := createGame(t, s)
+
+	p := model.TestLFGPost(t, users[0].ID, g.ID)
+	s.LFG().Create(ctx, p)
+
+	assert.EqualError(t, s.LFG().Request(ctx, &model.LFGRequest{PostID: p.ID + 1, UserID: users[1].ID}), store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.LFG().Request(ctx, &model.LFGRequest{PostID: p.ID, UserID: users[2].ID + 1}), store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.LFG().Request(ctx, &model.LFGRequest{PostID: p.ID, UserID: users[0].ID}), model.ErrOwnLFGPost.Error())
+
+	req := &model.LFGRequest{PostID: p.ID, UserID: users[1].ID, Message: "Count me in"}
+	assert.NoError(t, s.LFG().Request(ctx, req))
+	assert.False(t, req.CreatedAt.IsZero())
+	assert.EqualError(t, s.LFG().Request(ctx, &model.LFGRequest{PostID: p.ID, UserID: users[1].ID}), store.ErrRecordExists.Error())
+	assert.NoError(t, s.LFG().Request(ctx, &model.LFGRequest{PostID: p.ID, UserID: users[2].ID}))
+
+	requests, err := s.LFG().Requests(ctx, p.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, requests, 2) {
+		assert.Equal(t, users[1].ID, requests[0].UserID)
+		assert.Equal(t, "Count me in", requests[0].Message)
+		assert.Equal(t, users[2].ID, requests[1].UserID)
+
+		// Changing sent and found requests doesn't change stored ones
+		req.Message = "Changed"
+		requests[1].Message = "Changed"
+		requests, _ = s.LFG().Requests(ctx, p.ID)
+		assert.Equal(t, "Count me in", requests[0].Message)
+		assert.Empty(t, requests[1].Message)
+	}
+
+	requests, err = s.LFG().Requests(ctx, p.ID+1)
+	assert.NoError(t, err)
+	assert.Empty(t, requests)
+
+	s.LFG().Close(ctx, p.ID)
+	assert.EqualError(t, s.LFG().Request(ctx, &model.LFGRequest{PostID: p.ID, UserID: users[1].ID}), model.ErrLFGPostClosed.Error())
+}
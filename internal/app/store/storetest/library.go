@@ -0,0 +1,101 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+func testLibrary(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	u := createUsers(t, s, 1)[0]
+	g1, g2 := createGame(t, s), createGame(t, s)
+
+	e := model.TestLibraryEntry(t, u.ID, g1.ID)
+	assert.EqualError(t, s.Library().Update(ctx, e), store.ErrRecordNotFound.Error())
+	assert.NoError(t, s.Library().Add(ctx, e))
+	assert.False(t, e.AddedAt.IsZero())
+	assert.EqualError(t, s.Library().Add(ctx, model.TestLibraryEntry(t, u.ID, g1.ID)), store.ErrRecordExists.Error())
+	assert.EqualError(t, s.Library().Add(ctx, model.TestLibraryEntry(t, u.ID, g2.ID+1)), store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.Library().Add(ctx, model.TestLibraryEntry(t, u.ID+1, g1.ID)), store.ErrRecordNotFound.Error())
+
+	invalid := model.TestLibraryEntry(t, u.ID, g2.ID)
+	invalid.Status = "borrowed"
+	assert.Error(t, s.Library().Add(ctx, invalid))
+
+	// Changing the added entry doesn't change the stored one
+	e.Status = model.LibraryStatusFinished
+	found, err := s.Library().Find(ctx, u.ID, g1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LibraryStatusOwned, found.Status)
+
+	assert.NoError(t, s.Library().Update(ctx, e))
+	found, err = s.Library().Find(ctx, u.ID, g1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LibraryStatusFinished, found.Status)
+	assert.True(t, e.AddedAt.Equal(found.AddedAt))
+
+	s.Library().Add(ctx, model.TestLibraryEntry(t, u.ID, g2.ID))
+	entries, err := s.Library().FindByUser(ctx, u.ID, "")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	entries, err = s.Library().FindByUser(ctx, u.ID, model.LibraryStatusFinished)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, g1.ID, entries[0].GameID)
+
+		// Changing found entries doesn't change stored ones
+		entries[0].Status = model.LibraryStatusOwned
+		found, _ = s.Library().Find(ctx, u.ID, g1.ID)
+		assert.Equal(t, model.LibraryStatusFinished, found.Status)
+	}
+
+	assert.NoError(t, s.Library().Remove(ctx, u.ID, g1.ID))
+	assert.EqualError(t, s.Library().Remove(ctx, u.ID, g1.ID), store.ErrRecordNotFound.Error())
+	_, err = s.Library().Find(ctx, u.ID, g1.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	v, err := s.Library().Visibility(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LibraryVisibilityPublic, v)
+	assert.Error(t, s.Library().SetVisibility(ctx, u.ID, "secret"))
+	assert.NoError(t, s.Library().SetVisibility(ctx, u.ID, model.LibraryVisibilityPrivate))
+	v, err = s.Library().Visibility(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LibraryVisibilityPrivate, v)
+}
+
+func testLibraryBulk(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	u := createUsers(t, s, 1)[0]
+	g1, g2 := createGame(t, s), createGame(t, s)
+	s.Library().Add(ctx, model.TestLibraryEntry(t, u.ID, g1.ID))
+
+	// Nothing changes if any of the changes fails
+	err := s.Library().Bulk(ctx, u.ID, []*model.LibraryEntry{
+		model.TestLibraryEntry(t, u.ID, g2.ID),
+	}, []int{g2.ID + 1})
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	_, err = s.Library().Find(ctx, u.ID, g2.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	err = s.Library().Bulk(ctx, u.ID, []*model.LibraryEntry{
+		model.TestLibraryEntry(t, u.ID, g1.ID),
+	}, nil)
+	assert.EqualError(t, err, store.ErrRecordExists.Error())
+
+	err = s.Library().Bulk(ctx, u.ID, []*model.LibraryEntry{
+		model.TestLibraryEntry(t, 0, g2.ID),
+	}, []int{g1.ID})
+	assert.NoError(t, err)
+	entries, err := s.Library().FindByUser(ctx, u.ID, "")
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, g2.ID, entries[0].GameID)
+		assert.Equal(t, u.ID, entries[0].UserID)
+	}
+}
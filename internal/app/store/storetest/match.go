@@ -0,0 +1,141 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+func testMatch(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 2)
+	g := createGame(t, s)
+
+	assert.EqualError(t, s.Match().Create(ctx, model.TestMatch(t, g.ID+1, users[0].ID, users[1].ID)), store.ErrRecordNotFound.Error())
+	assert.Error(t, s.Match().Create(ctx, model.TestMatch(t, g.ID, users[0].ID, users[0].ID)))
+
+	m := model.TestMatch(t, g.ID, users[0].ID, users[1].ID)
+	assert.NoError(t, s.Match().Create(ctx, m))
+	assert.NotZero(t, m.ID)
+	assert.Equal(t, model.MatchPending, m.Status)
+
+	// Changing created and found matches doesn't change stored ones
+	m.SideA[0] = users[1].ID
+	found, err := s.Match().Find(ctx, m.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{users[0].ID}, found.SideA)
+	assert.Equal(t, []int{users[1].ID}, found.SideB)
+	found.Status = model.MatchConfirmed
+	found, _ = s.Match().Find(ctx, m.ID)
+	assert.Equal(t, model.MatchPending, found.Status)
+	_, err = s.Match().Find(ctx, m.ID+1)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	// Pending matches go to final or disputed ones, disputed ones get
+	// resolved and final ones stay as they are
+	assert.NoError(t, found.Dispute(users[1].ID))
+	assert.NoError(t, s.Match().Update(ctx, found))
+	assert.Nil(t, found.RatedAt)
+	found.Status = model.MatchConfirmed
+	assert.EqualError(t, s.Match().Update(ctx, found), model.ErrInvalidMatchTransition.Error())
+
+	found, _ = s.Match().Find(ctx, m.ID)
+	assert.Equal(t, model.MatchDisputed, found.Status)
+	assert.NoError(t, found.Resolve(model.MatchWinnerB))
+	assert.NoError(t, s.Match().Update(ctx, found))
+	assert.NotNil(t, found.RatedAt)
+	assert.EqualError(t, s.Match().Update(ctx, found), model.ErrInvalidMatchTransition.Error())
+
+	found, err = s.Match().Find(ctx, m.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.MatchResolved, found.Status)
+	assert.Equal(t, model.MatchWinnerB, found.Winner)
+	assert.NotNil(t, found.RatedAt)
+
+	pending := model.TestMatch(t, g.ID, users[0].ID, users[1].ID)
+	s.Match().Create(ctx, pending)
+	q := &store.ListQuery{Limit: 10}
+	matches, err := s.Match().FindByStatus(ctx, model.MatchPending, q)
+	assert.NoError(t, err)
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, pending.ID, matches[0].ID)
+	}
+	matches, err = s.Match().FindByStatus(ctx, model.MatchResolved, q)
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+	matches, err = s.Match().FindByStatus(ctx, model.MatchDisputed, q)
+	assert.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func testRating(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 3)
+	g := createGame(t, s)
+	season := model.SeasonOf(time.Now())
+
+	_, err := s.Rating().Find(ctx, users[0].ID, g.ID, season)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	_, err = s.Rating().Rank(ctx, users[0].ID, g.ID, season)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	// Ratings change once the result is final
+	m := model.TestMatch(t, g.ID, users[0].ID, users[1].ID)
+	s.Match().Create(ctx, m)
+	_, err = s.Rating().Find(ctx, users[0].ID, g.ID, m.Season)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	m.Confirm(users[1].ID)
+	assert.NoError(t, s.Match().Update(ctx, m))
+	winner, err := s.Rating().Find(ctx, users[0].ID, g.ID, m.Season)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, winner.Matches)
+	loser, err := s.Rating().Find(ctx, users[1].ID, g.ID, m.Season)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, loser.Matches)
+	assert.Greater(t, winner.Rating, loser.Rating)
+
+	m2 := model.TestMatch(t, g.ID, users[2].ID, users[1].ID)
+	m2.Winner = model.MatchDraw
+	s.Match().Create(ctx, m2)
+	m2.Confirm(users[1].ID)
+	s.Match().Update(ctx, m2)
+
+	entries, err := s.Rating().Leaderboard(ctx, g.ID, m.Season, &store.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 3) {
+		assert.Equal(t, 1, entries[0].Rank)
+		assert.Equal(t, users[0].ID, entries[0].UserID)
+		assert.Equal(t, 3, entries[2].Rank)
+		assert.Equal(t, users[1].ID, entries[2].UserID)
+		assert.Equal(t, 2, entries[2].Matches)
+
+		// Changing found ratings doesn't change stored ones
+		entries[0].Rating.Rating = 0
+		winner.Rating = 0
+		found, _ := s.Rating().Find(ctx, users[0].ID, g.ID, m.Season)
+		assert.Greater(t, found.Rating, loser.Rating)
+	}
+
+	entries, err = s.Rating().Leaderboard(ctx, g.ID, m.Season, &store.ListQuery{Limit: 1, Offset: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, 2, entries[0].Rank)
+		assert.Equal(t, users[2].ID, entries[0].UserID)
+	}
+
+	entries, err = s.Rating().Leaderboard(ctx, g.ID, "2000-Q1", &store.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	for i, u := range []*model.User{users[0], users[2], users[1]} {
+		rank, err := s.Rating().Rank(ctx, u.ID, g.ID, m.Season)
+		assert.NoError(t, err)
+		assert.Equal(t, i+1, rank)
+	}
+}
@@ -0,0 +1,167 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+func testModerationReports(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 2)
+	reporter, u := users[0], users[1]
+
+	assert.EqualError(t, s.Moderation().CreateReport(ctx, model.TestReport(t, reporter.ID, u.ID+1)), store.ErrRecordNotFound.Error())
+
+	first := model.TestReport(t, reporter.ID, u.ID)
+	assert.NoError(t, s.Moderation().CreateReport(ctx, first))
+	assert.NotZero(t, first.ID)
+	assert.Equal(t, model.ReportOpen, first.Status)
+
+	// The same target can be reported again once the report is resolved,
+	// the text filter reports it as often as it's flagged
+	assert.EqualError(t, s.Moderation().CreateReport(ctx, model.TestReport(t, reporter.ID, u.ID)), store.ErrRecordExists.Error())
+	filtered := model.TestReport(t, 0, u.ID)
+	assert.NoError(t, s.Moderation().CreateReport(ctx, filtered))
+	assert.NoError(t, s.Moderation().CreateReport(ctx, model.TestReport(t, 0, u.ID)))
+	second := model.TestReport(t, u.ID, reporter.ID)
+	assert.NoError(t, s.Moderation().CreateReport(ctx, second))
+
+	// Changing created and found reports doesn't change stored ones
+	second.Reason = "Changed"
+	found, err := s.Moderation().FindReport(ctx, second.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, reporter.ID, found.TargetUserID)
+	assert.Equal(t, model.TestReport(t, 0, 0).Reason, found.Reason)
+	found.Status = model.ReportResolved
+	found, _ = s.Moderation().FindReport(ctx, second.ID)
+	assert.Equal(t, model.ReportOpen, found.Status)
+	found, err = s.Moderation().FindReport(ctx, filtered.ID)
+	assert.NoError(t, err)
+	assert.Zero(t, found.ReporterID)
+	_, err = s.Moderation().FindReport(ctx, second.ID+1)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	q := &store.ListQuery{Limit: 10}
+	reports, err := s.Moderation().FindReports(ctx, model.ReportOpen, q)
+	assert.NoError(t, err)
+	if assert.Len(t, reports, 4) {
+		assert.Equal(t, first.ID, reports[0].ID)
+		assert.Equal(t, second.ID, reports[3].ID)
+		reports[0].Status = model.ReportResolved
+	}
+	reports, err = s.Moderation().FindReports(ctx, model.ReportOpen, &store.ListQuery{Limit: 2, Offset: 3})
+	assert.NoError(t, err)
+	if assert.Len(t, reports, 1) {
+		assert.Equal(t, second.ID, reports[0].ID)
+	}
+
+	resolved, err := s.Moderation().Resolve(ctx, first.ID, model.ModerationDismiss, "Nothing wrong", reporter.ID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReportDismissed, resolved.Status)
+	assert.Equal(t, reporter.ID, resolved.ModeratorID)
+	assert.NotNil(t, resolved.ResolvedAt)
+	assert.NoError(t, s.Moderation().CreateReport(ctx, model.TestReport(t, reporter.ID, u.ID)))
+
+	reports, err = s.Moderation().FindReports(ctx, model.ReportOpen, q)
+	assert.NoError(t, err)
+	assert.Len(t, reports, 4)
+	reports, err = s.Moderation().FindReports(ctx, model.ReportDismissed, q)
+	assert.NoError(t, err)
+	if assert.Len(t, reports, 1) {
+		assert.Equal(t, first.ID, reports[0].ID)
+		assert.Equal(t, "Nothing wrong", reports[0].Resolution)
+	}
+}
+
+func testModerationSanctions(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 2)
+	moderator, u := users[0], users[1]
+
+	rep := model.TestReport(t, moderator.ID, u.ID)
+	s.Moderation().CreateReport(ctx, rep)
+
+	// Invalid sanctions leave the report open
+	invalid := model.TestSanction(t, u.ID, moderator.ID)
+	invalid.ExpiresAt = nil
+	_, err := s.Moderation().Resolve(ctx, rep.ID, model.SanctionMute, "Spam", moderator.ID, invalid)
+	assert.Error(t, err)
+	found, _ := s.Moderation().FindReport(ctx, rep.ID)
+	assert.Equal(t, model.ReportOpen, found.Status)
+	sanctions, err := s.Moderation().Sanctions(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, sanctions)
+
+	mute := model.TestSanction(t, u.ID, moderator.ID)
+	resolved, err := s.Moderation().Resolve(ctx, rep.ID, model.SanctionMute, "Spam", moderator.ID, mute)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ReportResolved, resolved.Status)
+	assert.Equal(t, model.SanctionMute, resolved.Action)
+	assert.Equal(t, moderator.ID, resolved.ModeratorID)
+	assert.NotZero(t, mute.ID)
+	assert.Equal(t, rep.ID, mute.ReportID)
+
+	// Changing returned reports doesn't change stored ones
+	resolved.Status = model.ReportOpen
+	found, _ = s.Moderation().FindReport(ctx, rep.ID)
+	assert.Equal(t, model.ReportResolved, found.Status)
+
+	_, err = s.Moderation().Resolve(ctx, rep.ID, model.ModerationDismiss, "Fine", moderator.ID, nil)
+	assert.EqualError(t, err, model.ErrReportResolved.Error())
+	_, err = s.Moderation().Resolve(ctx, rep.ID+1, model.ModerationDismiss, "Fine", moderator.ID, nil)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	warned := model.TestReport(t, moderator.ID, u.ID)
+	s.Moderation().CreateReport(ctx, warned)
+	warn := model.TestSanction(t, u.ID, moderator.ID)
+	warn.Kind = model.SanctionWarn
+	warn.ExpiresAt = nil
+	s.Moderation().Resolve(ctx, warned.ID, model.SanctionWarn, "Rude", moderator.ID, warn)
+
+	banned := model.TestReport(t, moderator.ID, u.ID)
+	s.Moderation().CreateReport(ctx, banned)
+	ban := model.TestSanction(t, u.ID, moderator.ID)
+	ban.Kind = model.SanctionBan
+	ban.ExpiresAt = nil
+	s.Moderation().Resolve(ctx, banned.ID, model.SanctionBan, "Cheating", moderator.ID, ban)
+
+	now := time.Now().UTC()
+	sanctions, err = s.Moderation().Sanctions(ctx, u.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, sanctions, 3) {
+		assert.Equal(t, ban.ID, sanctions[0].ID)
+		assert.True(t, sanctions[0].Active(now))
+		assert.Equal(t, warn.ID, sanctions[1].ID)
+		assert.False(t, sanctions[1].Active(now))
+		assert.Equal(t, mute.ID, sanctions[2].ID)
+		if assert.NotNil(t, sanctions[2].ExpiresAt) {
+			assert.WithinDuration(t, *mute.ExpiresAt, *sanctions[2].ExpiresAt, time.Millisecond)
+		}
+
+		// Changing imposed and found sanctions doesn't change stored ones
+		sanctions[0].Kind = model.SanctionWarn
+		*mute.ExpiresAt = now
+		sanctions, _ = s.Moderation().Sanctions(ctx, u.ID)
+		assert.Equal(t, model.SanctionBan, sanctions[0].Kind)
+		assert.True(t, sanctions[2].Active(now))
+	}
+
+	sanctions, err = s.Moderation().Sanctions(ctx, moderator.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, sanctions)
+
+	// Warnings and sanctions which ended can't be lifted
+	assert.EqualError(t, s.Moderation().Lift(ctx, warn.ID, now), store.ErrRecordNotFound.Error())
+	assert.NoError(t, s.Moderation().Lift(ctx, ban.ID, now))
+	assert.EqualError(t, s.Moderation().Lift(ctx, ban.ID, now.Add(time.Second)), store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.Moderation().Lift(ctx, ban.ID+1, now), store.ErrRecordNotFound.Error())
+	sanctions, _ = s.Moderation().Sanctions(ctx, u.ID)
+	assert.False(t, sanctions[0].Active(now.Add(time.Second)))
+	assert.True(t, sanctions[2].Active(now.Add(time.Second)))
+}
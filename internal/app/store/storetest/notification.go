@@ -0,0 +1,119 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+func testNotification(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 2)
+	u1, u2 := users[0], users[1]
+
+	assert.EqualError(t, s.Notification().Create(ctx, model.TestNotification(t, u2.ID+1)), store.ErrRecordNotFound.Error())
+	invalid := model.TestNotification(t, u1.ID)
+	invalid.Type = "newsletter"
+	assert.Error(t, s.Notification().Create(ctx, invalid))
+
+	created := make([]*model.Notification, 3)
+	for i := range created {
+		created[i] = model.TestNotification(t, u1.ID)
+		assert.NoError(t, s.Notification().Create(ctx, created[i]))
+	}
+	assert.NotZero(t, created[0].ID)
+	assert.NotEqual(t, created[0].ID, created[1].ID)
+	other := model.TestNotification(t, u2.ID)
+	s.Notification().Create(ctx, other)
+
+	notifications, err := s.Notification().FindByUser(ctx, u1.ID, 0, 2)
+	assert.NoError(t, err)
+	if !assert.Len(t, notifications, 2) {
+		return
+	}
+	assert.Equal(t, created[2].ID, notifications[0].ID)
+	assert.Equal(t, created[1].ID, notifications[1].ID)
+	assert.False(t, notifications[0].Read)
+
+	older, err := s.Notification().FindByUser(ctx, u1.ID, notifications[1].ID, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, older, 1) {
+		assert.Equal(t, created[0].ID, older[0].ID)
+	}
+
+	// Changing created and found notifications doesn't change stored ones
+	created[0].Text = "Changed"
+	notifications[0].Read = true
+	notifications, _ = s.Notification().FindByUser(ctx, u1.ID, 0, 10)
+	assert.Equal(t, model.TestNotification(t, 0).Text, notifications[2].Text)
+	assert.False(t, notifications[0].Read)
+
+	assert.EqualError(t, s.Notification().MarkRead(ctx, u1.ID, other.ID), store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.Notification().MarkRead(ctx, u1.ID, other.ID+1), store.ErrRecordNotFound.Error())
+	assert.NoError(t, s.Notification().MarkRead(ctx, u1.ID, created[2].ID))
+	assert.NoError(t, s.Notification().MarkRead(ctx, u1.ID, created[2].ID))
+	notifications, _ = s.Notification().FindByUser(ctx, u1.ID, 0, 1)
+	assert.True(t, notifications[0].Read)
+
+	unread, err := s.Notification().CountUnread(ctx, u1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, unread)
+
+	n, err := s.Notification().MarkAllRead(ctx, u1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	n, err = s.Notification().MarkAllRead(ctx, u1.ID)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+
+	unread, err = s.Notification().CountUnread(ctx, u1.ID)
+	assert.NoError(t, err)
+	assert.Zero(t, unread)
+	unread, err = s.Notification().CountUnread(ctx, u2.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, unread)
+}
+
+func testNotificationPreferences(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 2)
+	u := users[0]
+
+	prefs, err := s.Notification().Preferences(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, prefs)
+
+	assert.Error(t, s.Notification().SetPreferences(ctx, u.ID, map[string]bool{"newsletter": false}))
+	assert.EqualError(t, s.Notification().SetPreferences(ctx, users[1].ID+1, map[string]bool{
+		model.NotificationAchievement: false,
+	}), store.ErrRecordNotFound.Error())
+
+	set := map[string]bool{
+		model.NotificationAchievement: false,
+		model.NotificationLobbyInvite: false,
+	}
+	assert.NoError(t, s.Notification().SetPreferences(ctx, u.ID, set))
+	assert.NoError(t, s.Notification().SetPreferences(ctx, u.ID, map[string]bool{
+		model.NotificationLobbyInvite: true,
+	}))
+
+	// Changing set and found preferences doesn't change stored ones
+	set[model.NotificationFriendRequest] = false
+	prefs, err = s.Notification().Preferences(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{
+		model.NotificationAchievement: false,
+		model.NotificationLobbyInvite: true,
+	}, prefs)
+	prefs[model.NotificationAchievement] = true
+	prefs, _ = s.Notification().Preferences(ctx, u.ID)
+	assert.False(t, prefs[model.NotificationAchievement])
+
+	prefs, err = s.Notification().Preferences(ctx, users[1].ID)
+	assert.NoError(t, err)
+	assert.Empty(t, prefs)
+}
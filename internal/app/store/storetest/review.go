@@ -0,0 +1,130 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+func testReview(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 2)
+	g := createGame(t, s)
+
+	rv := model.TestReview(t, users[0].ID, g.ID)
+	assert.EqualError(t, s.Review().Update(ctx, rv), store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.Review().Delete(ctx, users[0].ID, g.ID), store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.Review().Create(ctx, model.TestReview(t, users[0].ID, g.ID+1)), store.ErrRecordNotFound.Error())
+
+	invalid := model.TestReview(t, users[0].ID, g.ID)
+	invalid.Score = model.ReviewScoreMax + 1
+	assert.Error(t, s.Review().Create(ctx, invalid))
+
+	assert.NoError(t, s.Review().Create(ctx, rv))
+	assert.NotZero(t, rv.ID)
+	assert.EqualError(t, s.Review().Create(ctx, model.TestReview(t, users[0].ID, g.ID)), store.ErrRecordExists.Error())
+
+	rv2 := model.TestReview(t, users[1].ID, g.ID)
+	rv2.Score = 4
+	assert.NoError(t, s.Review().Create(ctx, rv2))
+	assert.NotEqual(t, rv.ID, rv2.ID)
+
+	found, err := s.Game().Find(ctx, g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, found.RatingCount)
+	assert.Equal(t, float64(rv.Score+rv2.Score)/2, found.RatingMean)
+	assert.Equal(t, 1, found.RatingHistogram[rv.Score-1])
+	assert.Equal(t, 1, found.RatingHistogram[rv2.Score-1])
+
+	// Changing created and found reviews doesn't change stored ones
+	rv.Text = "Changed"
+	review, err := s.Review().Find(ctx, rv.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestReview(t, 0, 0).Text, review.Text)
+	review.Score = 1
+	review, err = s.Review().FindByUserAndGame(ctx, users[0].ID, g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, rv.ID, review.ID)
+	assert.Equal(t, model.TestReview(t, 0, 0).Score, review.Score)
+
+	update := model.TestReview(t, users[0].ID, g.ID)
+	update.Score = 2
+	assert.NoError(t, s.Review().Update(ctx, update))
+	assert.Equal(t, rv.ID, update.ID)
+	found, err = s.Game().Find(ctx, g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, found.RatingCount)
+	assert.Equal(t, 3.0, found.RatingMean)
+	assert.Equal(t, 0, found.RatingHistogram[rv.Score-1])
+	assert.Equal(t, 1, found.RatingHistogram[1])
+
+	assert.NoError(t, s.Review().Delete(ctx, users[0].ID, g.ID))
+	_, err = s.Review().Find(ctx, rv.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	found, err = s.Game().Find(ctx, g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, found.RatingCount)
+	assert.Equal(t, float64(rv2.Score), found.RatingMean)
+	assert.Equal(t, 0, found.RatingHistogram[1])
+}
+
+func testReviewVote(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 3)
+	g := createGame(t, s)
+
+	rv1 := model.TestReview(t, users[0].ID, g.ID)
+	s.Review().Create(ctx, rv1)
+	rv2 := model.TestReview(t, users[1].ID, g.ID)
+	s.Review().Create(ctx, rv2)
+
+	assert.EqualError(t, s.Review().Vote(ctx, &model.ReviewVote{ReviewID: rv2.ID + 1, UserID: users[2].ID}), store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.Review().Vote(ctx, &model.ReviewVote{ReviewID: rv1.ID, UserID: users[2].ID + 1}), store.ErrRecordNotFound.Error())
+
+	// Votes are counted once per user, changing the vote moves it
+	assert.NoError(t, s.Review().Vote(ctx, &model.ReviewVote{ReviewID: rv1.ID, UserID: users[2].ID, Helpful: true}))
+	assert.NoError(t, s.Review().Vote(ctx, &model.ReviewVote{ReviewID: rv1.ID, UserID: users[2].ID, Helpful: true}))
+	assert.NoError(t, s.Review().Vote(ctx, &model.ReviewVote{ReviewID: rv1.ID, UserID: users[1].ID, Helpful: true}))
+	assert.NoError(t, s.Review().Vote(ctx, &model.ReviewVote{ReviewID: rv2.ID, UserID: users[2].ID, Helpful: true}))
+	assert.NoError(t, s.Review().Vote(ctx, &model.ReviewVote{ReviewID: rv2.ID, UserID: users[2].ID, Helpful: false}))
+
+	found, err := s.Review().Find(ctx, rv1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, found.HelpfulCount)
+	assert.Equal(t, 0, found.UnhelpfulCount)
+	found, err = s.Review().Find(ctx, rv2.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, found.HelpfulCount)
+	assert.Equal(t, 1, found.UnhelpfulCount)
+
+	// Updating the review keeps its votes
+	update := model.TestReview(t, users[0].ID, g.ID)
+	update.Text = "Even better the second time"
+	assert.NoError(t, s.Review().Update(ctx, update))
+	assert.Equal(t, 2, update.HelpfulCount)
+
+	reviews, err := s.Review().FindByGame(ctx, g.ID, &store.ListQuery{Sort: store.SortRecent, Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, reviews, 2) {
+		assert.Equal(t, rv2.ID, reviews[0].ID)
+	}
+
+	reviews, err = s.Review().FindByGame(ctx, g.ID, &store.ListQuery{Sort: store.SortHelpful, Limit: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, reviews, 1) {
+		assert.Equal(t, rv1.ID, reviews[0].ID)
+
+		// Changing found reviews doesn't change stored ones
+		reviews[0].HelpfulCount = 0
+		found, _ = s.Review().Find(ctx, rv1.ID)
+		assert.Equal(t, 2, found.HelpfulCount)
+	}
+
+	reviews, err = s.Review().FindByGame(ctx, g.ID, &store.ListQuery{Sort: store.SortHelpful, Limit: 10, Offset: 2})
+	assert.NoError(t, err)
+	assert.Empty(t, reviews)
+}
@@ -0,0 +1,329 @@
+// Package storetest is the conformance suite of store implementations:
+// every implementation runs it, so they behave the same way.
+package storetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// Open func. Returns an empty store for the test and the func that
+// cleans it up once the test is over
+type Open func(t *testing.T) (store.Store, func())
+
+// Run func. Runs the conformance suite against stores that open returns
+func Run(t *testing.T, open Open) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s store.Store)
+	}{
+		{"UserRepository_Create", testUserCreate},
+		{"UserRepository_Find", testUserFind},
+		{"UserRepository_FindByEmail", testUserFindByEmail},
+		{"UserRepository_FindRegisteredBefore", testUserFindRegisteredBefore},
+		{"UserRepository_Concurrent", testUserConcurrent},
+		{"GameRepository", testGame},
+		{"LibraryRepository", testLibrary},
+		{"LibraryRepository_Bulk", testLibraryBulk},
+		{"ReviewRepository", testReview},
+		{"ReviewRepository_Vote", testReviewVote},
+		{"FriendshipRepository_Transitions", testFriendshipTransitions},
+		{"FriendshipRepository_Lists", testFriendshipLists},
+		{"FriendshipRepository_Block", testFriendshipBlock},
+		{"ChatRepository", testChat},
+		{"MatchRepository", testMatch},
+		{"RatingRepository", testRating},
+		{"TournamentRepository", testTournament},
+		{"TournamentRepository_Update", testTournamentUpdate},
+		{"AchievementRepository_Events", testAchievementEvents},
+		{"AchievementRepository_Progress", testAchievementProgress},
+		{"GuildRepository", testGuild},
+		{"GuildRepository_Invites", testGuildInvites},
+		{"GuildRepository_Roles", testGuildRoles},
+		{"GuildRepository_Leaderboard", testGuildLeaderboard},
+		{"LFGRepository", testLFG},
+		{"LFGRepository_Requests", testLFGRequests},
+		{"EventRepository", testEvent},
+		{"EventRepository_RSVP", testEventRSVP},
+		{"EventRepository_CalendarToken", testEventCalendarToken},
+		{"NotificationRepository", testNotification},
+		{"NotificationRepository_Preferences", testNotificationPreferences},
+		{"ActivityRepository", testActivity},
+		{"ModerationRepository_Reports", testModerationReports},
+		{"ModerationRepository_Sanctions", testModerationSanctions},
+		{"WithTx", testWithTx},
+		{"Context", testContext},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, teardown := open(t)
+			defer teardown()
+
+			tc.test(t, s)
+		})
+	}
+}
+
+// lastUser is the number of the last user createUsers created
+var lastUser int64
+
+// testUser func. Returns valid user with the number in email
+func testUser(t *testing.T, n int) *model.User {
+	u := model.TestUser(t)
+	u.Email = fmt.Sprintf("user%d@example.org", n)
+
+	return u
+}
+
+// createUsers func. Creates n users with emails no other user has
+func createUsers(t *testing.T, s store.Store, n int) []*model.User {
+	t.Helper()
+
+	users := make([]*model.User, n)
+	for i := range users {
+		users[i] = model.TestUser(t)
+		users[i].Email = fmt.Sprintf("member%d@example.org", atomic.AddInt64(&lastUser, 1))
+		if err := s.User().Create(context.Background(), users[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return users
+}
+
+// createGame func. Creates a game
+func createGame(t *testing.T, s store.Store) *model.Game {
+	t.Helper()
+
+	g := model.TestGame(t)
+	if err := s.Game().Create(context.Background(), g); err != nil {
+		t.Fatal(err)
+	}
+
+	return g
+}
+
+func testUserCreate(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	u := testUser(t, 1)
+	assert.NoError(t, s.User().Create(ctx, u))
+	assert.NotZero(t, u.ID)
+	assert.NotEmpty(t, u.EncryptedPassword)
+	assert.Equal(t, model.RoleUser, u.Role)
+
+	u2 := testUser(t, 2)
+	assert.NoError(t, s.User().Create(ctx, u2))
+	assert.NotEqual(t, u.ID, u2.ID)
+
+	taken := testUser(t, 1)
+	assert.EqualError(t, s.User().Create(ctx, taken), store.ErrRecordExists.Error())
+
+	invalid := testUser(t, 3)
+	invalid.Email = "invalid"
+	assert.Error(t, s.User().Create(ctx, invalid))
+
+	// Changing the created user doesn't change the stored one
+	u.Role = model.RoleAdmin
+	found, err := s.User().Find(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.RoleUser, found.Role)
+}
+
+func testUserFind(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	_, err := s.User().Find(ctx, 1)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	u := testUser(t, 1)
+	s.User().Create(ctx, u)
+	found, err := s.User().Find(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, u.ID, found.ID)
+	assert.Equal(t, u.Email, found.Email)
+	assert.Equal(t, u.EncryptedPassword, found.EncryptedPassword)
+	assert.Empty(t, found.Password)
+	assert.True(t, found.ComparePassword(model.TestUser(t).Password))
+
+	// Changing the found user doesn't change the stored one
+	found.Email = "changed@example.org"
+	found, err = s.User().Find(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, u.Email, found.Email)
+}
+
+func testUserFindByEmail(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	u := testUser(t, 1)
+	_, err := s.User().FindByEmail(ctx, u.Email)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	s.User().Create(ctx, u)
+	found, err := s.User().FindByEmail(ctx, u.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, u.ID, found.ID)
+
+	found.Role = model.RoleAdmin
+	found, err = s.User().FindByEmail(ctx, u.Email)
+	assert.NoError(t, err)
+	assert.Equal(t, model.RoleUser, found.Role)
+}
+
+func testUserFindRegisteredBefore(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	now := time.Now().UTC()
+	ids := []int{}
+	for i, age := range []time.Duration{72 * time.Hour, 0, 48 * time.Hour} {
+		u := testUser(t, i)
+		u.CreatedAt = now.Add(-age)
+		s.User().Create(ctx, u)
+		if age > 0 {
+			ids = append(ids, u.ID)
+		}
+	}
+
+	found, err := s.User().FindRegisteredBefore(ctx, now.Add(-24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, ids, found)
+
+	found, err = s.User().FindRegisteredBefore(ctx, now.Add(-96*time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func testUserConcurrent(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	const n = 10
+	ids := make(chan int, n)
+	taken := make(chan error, n)
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			u := testUser(t, i)
+			if err := s.User().Create(ctx, u); err != nil {
+				t.Error(err)
+				return
+			}
+			ids <- u.ID
+		}(i)
+		go func() {
+			defer wg.Done()
+			taken <- s.User().Create(ctx, testUser(t, n))
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	close(taken)
+
+	unique := map[int]bool{}
+	for id := range ids {
+		unique[id] = true
+	}
+	assert.Len(t, unique, n)
+
+	created := 0
+	for err := range taken {
+		if err == nil {
+			created++
+			continue
+		}
+		assert.EqualError(t, err, store.ErrRecordExists.Error())
+	}
+	assert.Equal(t, 1, created)
+}
+
+func testGame(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	_, err := s.Game().Find(ctx, 1)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	invalid := model.TestGame(t)
+	invalid.Title = ""
+	assert.Error(t, s.Game().Create(ctx, invalid))
+
+	g := model.TestGame(t)
+	assert.NoError(t, s.Game().Create(ctx, g))
+	assert.NotZero(t, g.ID)
+
+	found, err := s.Game().Find(ctx, g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, g.Title, found.Title)
+	assert.Zero(t, found.RatingCount)
+
+	g2 := model.TestGame(t)
+	assert.NoError(t, s.Game().Create(ctx, g2))
+	assert.NotEqual(t, g.ID, g2.ID)
+
+	// Changing created and found games doesn't change stored ones
+	g.Title = "Changed"
+	found.RatingHistogram[0] = 5
+	found, err = s.Game().Find(ctx, g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestGame(t).Title, found.Title)
+	assert.Zero(t, found.RatingHistogram[0])
+}
+
+func testWithTx(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+	u := testUser(t, 1)
+	err := s.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.User().Create(ctx, u); err != nil {
+			return err
+		}
+		if _, err := tx.User().Find(ctx, u.ID); err != nil {
+			return err
+		}
+
+		return errFailed
+	})
+	assert.EqualError(t, err, errFailed.Error())
+	_, err = s.User().FindByEmail(ctx, u.Email)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	g := model.TestGame(t)
+	err = s.WithTx(ctx, func(tx store.Store) error {
+		if err := tx.User().Create(ctx, u); err != nil {
+			return err
+		}
+
+		return tx.WithTx(ctx, func(tx store.Store) error {
+			return tx.Game().Create(ctx, g)
+		})
+	})
+	assert.NoError(t, err)
+	_, err = s.User().Find(ctx, u.ID)
+	assert.NoError(t, err)
+	_, err = s.Game().Find(ctx, g.ID)
+	assert.NoError(t, err)
+
+	err = s.WithTx(ctx, func(tx store.Store) error {
+		return tx.User().Create(ctx, testUser(t, 1))
+	})
+	assert.EqualError(t, err, store.ErrRecordExists.Error())
+}
+
+func testContext(t *testing.T, s store.Store) {
+	u := testUser(t, 1)
+	assert.NoError(t, s.User().Create(context.Background(), u))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.User().Find(ctx, u.ID)
+	assert.EqualError(t, err, context.Canceled.Error())
+	assert.EqualError(t, s.Game().Create(ctx, model.TestGame(t)), context.Canceled.Error())
+	assert.EqualError(t, s.WithTx(ctx, func(store.Store) error { return nil }), context.Canceled.Error())
+}
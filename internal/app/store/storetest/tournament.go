@@ -0,0 +1,131 @@
+package storetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/tournament"
+)
+
+func testTournament(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 3)
+	g := createGame(t, s)
+
+	assert.EqualError(t, s.Tournament().Create(ctx, model.TestTournament(t, g.ID+1, users[0].ID)), store.ErrRecordNotFound.Error())
+
+	tr := model.TestTournament(t, g.ID, users[0].ID)
+	tr.MaxPlayers = 2
+	assert.NoError(t, s.Tournament().Create(ctx, tr))
+	assert.NotZero(t, tr.ID)
+	assert.Equal(t, model.TournamentRegistration, tr.Status)
+
+	later := model.TestTournament(t, g.ID, users[0].ID)
+	later.RegistrationClosesAt = later.RegistrationClosesAt.Add(time.Hour)
+	s.Tournament().Create(ctx, later)
+	tournaments, err := s.Tournament().FindByStatus(ctx, model.TournamentRegistration, &store.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, tournaments, 2) {
+		assert.Equal(t, tr.ID, tournaments[0].ID)
+	}
+	tournaments, err = s.Tournament().FindByStatus(ctx, model.TournamentRunning, &store.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, tournaments)
+
+	// Changing created and found tournaments doesn't change stored ones
+	tr.Name = "Changed"
+	found, err := s.Tournament().Find(ctx, tr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TestTournament(t, 0, 0).Name, found.Name)
+	assert.Nil(t, found.Bracket)
+	found.MaxPlayers = 8
+
+	assert.EqualError(t, s.Tournament().Register(ctx, later.ID+1, users[0].ID), store.ErrRecordNotFound.Error())
+	assert.NoError(t, s.Tournament().Register(ctx, tr.ID, users[0].ID))
+	assert.EqualError(t, s.Tournament().Register(ctx, tr.ID, users[0].ID), store.ErrRecordExists.Error())
+	assert.NoError(t, s.Tournament().Register(ctx, tr.ID, users[1].ID))
+	assert.EqualError(t, s.Tournament().Register(ctx, tr.ID, users[2].ID), model.ErrTournamentFull.Error())
+
+	participants, err := s.Tournament().Participants(ctx, tr.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, participants, 2) {
+		assert.Equal(t, users[0].ID, participants[0].UserID)
+		assert.Equal(t, users[1].ID, participants[1].UserID)
+		participants[0].UserID = users[2].ID
+	}
+
+	assert.NoError(t, s.Tournament().Unregister(ctx, tr.ID, users[1].ID))
+	assert.EqualError(t, s.Tournament().Unregister(ctx, tr.ID, users[1].ID), store.ErrRecordNotFound.Error())
+	participants, err = s.Tournament().Participants(ctx, tr.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, participants, 1) {
+		assert.Equal(t, users[0].ID, participants[0].UserID)
+	}
+
+	// Registration isn't open before it opens and after it closes
+	closed := model.TestTournament(t, g.ID, users[0].ID)
+	closed.RegistrationOpensAt = time.Now().UTC().Add(time.Hour)
+	closed.RegistrationClosesAt = closed.RegistrationOpensAt.Add(time.Hour)
+	s.Tournament().Create(ctx, closed)
+	assert.EqualError(t, s.Tournament().Register(ctx, closed.ID, users[0].ID), model.ErrRegistrationClosed.Error())
+}
+
+func testTournamentUpdate(t *testing.T, s store.Store) {
+	ctx := context.Background()
+	users := createUsers(t, s, 2)
+	g := createGame(t, s)
+
+	tr := model.TestTournament(t, g.ID, users[0].ID)
+	s.Tournament().Create(ctx, tr)
+	s.Tournament().Register(ctx, tr.ID, users[0].ID)
+
+	_, err := s.Tournament().Update(ctx, tr.ID+1, func(*model.Tournament) error { return nil })
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	started, err := s.Tournament().Update(ctx, tr.ID, func(tr *model.Tournament) error {
+		return tr.Start([]int{users[0].ID, users[1].ID})
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, model.TournamentRunning, started.Status)
+	if !assert.NotNil(t, started.Bracket) {
+		return
+	}
+	assert.EqualError(t, s.Tournament().Unregister(ctx, tr.ID, users[0].ID), model.ErrRegistrationClosed.Error())
+	assert.EqualError(t, s.Tournament().Register(ctx, tr.ID, users[1].ID), model.ErrRegistrationClosed.Error())
+
+	// Changes of failed updates and of returned tournaments are lost
+	matchID := started.Bracket.Matches[0].ID
+	started.Bracket.Matches[0].Done = true
+	errFailed := errors.New("failed")
+	_, err = s.Tournament().Update(ctx, tr.ID, func(tr *model.Tournament) error {
+		if err := tr.Advance(func(b *tournament.Bracket) error { return b.Report(matchID, users[0].ID) }); err != nil {
+			return err
+		}
+		return errFailed
+	})
+	assert.EqualError(t, err, errFailed.Error())
+
+	found, err := s.Tournament().Find(ctx, tr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TournamentRunning, found.Status)
+	assert.Equal(t, tournament.SingleElimination, found.Bracket.Format)
+	assert.False(t, found.Bracket.Matches[0].Done)
+	found.Bracket.Matches[0].Done = true
+
+	finished, err := s.Tournament().Update(ctx, tr.ID, func(tr *model.Tournament) error {
+		return tr.Advance(func(b *tournament.Bracket) error { return b.Report(matchID, users[1].ID) })
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, model.TournamentFinished, finished.Status)
+
+	found, err = s.Tournament().Find(ctx, tr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.TournamentFinished, found.Status)
+	assert.Equal(t, users[1].ID, found.Bracket.Matches[0].Winner)
+}
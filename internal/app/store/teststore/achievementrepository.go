@@ -0,0 +1,174 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+// achievementKey object. Every user has one progress per achievement.
+type achievementKey struct {
+	userID int
+	code   string
+}
+
+// AchievementRepository object for testing only
+type AchievementRepository struct {
+	store     *Store
+	events    map[int]*model.UserEvent
+	progress  map[achievementKey]*model.AchievementProgress
+	evaluated map[string]string
+	lastID    int
+}
+
+// CreateEvent func. Writing imported user event in the map of test events.
+func (r *AchievementRepository) CreateEvent(ctx context.Context, e *model.UserEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if _, err := r.store.User().Find(ctx, e.UserID); err != nil {
+		return err
+	}
+
+	e.BeforeCreate()
+	r.lastID++
+	e.ID = r.lastID
+	r.events[e.ID] = clone(e).(*model.UserEvent)
+
+	return nil
+}
+
+// CountEvents func. Counting user's events of the type.
+func (r *AchievementRepository) CountEvents(ctx context.Context, userID int, eventType string, gameID int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	return r.countEvents(eventType, gameID)[userID], nil
+}
+
+// CountEventsByUser func. Counting events of the type of every user
+// who has them.
+func (r *AchievementRepository) CountEventsByUser(ctx context.Context, eventType string, gameID int) (map[int]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	return r.countEvents(eventType, gameID), nil
+}
+
+// SaveProgress func. Writing user's progress of the achievement. For
+// additional information check achievementrepository.go documentation
+// in sqlstore dir.
+func (r *AchievementRepository) SaveProgress(ctx context.Context, p *model.AchievementProgress) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if _, err := r.store.User().Find(ctx, p.UserID); err != nil {
+		return err
+	}
+
+	p.BeforeSave()
+	k := achievementKey{p.UserID, p.Code}
+	if old, ok := r.progress[k]; ok {
+		if old.Progress > p.Progress {
+			p.Progress = old.Progress
+		}
+		if old.UnlockedAt != nil {
+			p.UnlockedAt = old.UnlockedAt
+		}
+	}
+
+	r.progress[k] = clone(p).(*model.AchievementProgress)
+
+	return nil
+}
+
+// FindProgress func. Finding user's progress of all achievements.
+func (r *AchievementRepository) FindProgress(ctx context.Context, userID int) ([]*model.AchievementProgress, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	progress := []*model.AchievementProgress{}
+	for k, p := range r.progress {
+		if k.userID == userID {
+			progress = append(progress, clone(p).(*model.AchievementProgress))
+		}
+	}
+
+	sort.Slice(progress, func(i, j int) bool {
+		return progress[i].Code < progress[j].Code
+	})
+
+	return progress, nil
+}
+
+// Evaluated func. Finding criteria fingerprint the achievement was
+// last evaluated for.
+func (r *AchievementRepository) Evaluated(ctx context.Context, code string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	return r.evaluated[code], nil
+}
+
+// SetEvaluated func. Writing criteria fingerprint the achievement was evaluated for.
+func (r *AchievementRepository) SetEvaluated(ctx context.Context, code, fingerprint string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	r.evaluated[code] = fingerprint
+
+	return nil
+}
+
+// countEvents func. Counting events of the type by user
+func (r *AchievementRepository) countEvents(eventType string, gameID int) map[int]int {
+	counts := map[int]int{}
+	for _, e := range r.events {
+		if e.Type == eventType && (gameID == 0 || e.GameID == gameID) {
+			counts[e.UserID]++
+		}
+	}
+
+	return counts
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *AchievementRepository) copyFor(s *Store) *AchievementRepository {
+	return &AchievementRepository{
+		store:     s,
+		events:    clone(r.events).(map[int]*model.UserEvent),
+		progress:  clone(r.progress).(map[achievementKey]*model.AchievementProgress),
+		evaluated: clone(r.evaluated).(map[string]string),
+		lastID:    r.lastID,
+	}
+}
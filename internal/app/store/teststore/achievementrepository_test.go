@@ -0,0 +1,75 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAchievementRepository_CountEvents(t *testing.T) {
+	s := teststore.New()
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+
+	assert.EqualError(t, s.Achievement().CreateEvent(context.Background(), &model.UserEvent{UserID: u2.ID + 1, Type: model.EventLogin}), store.ErrRecordNotFound.Error())
+	assert.NoError(t, s.Achievement().CreateEvent(context.Background(), &model.UserEvent{UserID: u1.ID, Type: model.EventMatchWon, GameID: 1}))
+	assert.NoError(t, s.Achievement().CreateEvent(context.Background(), &model.UserEvent{UserID: u1.ID, Type: model.EventMatchWon, GameID: 2}))
+	assert.NoError(t, s.Achievement().CreateEvent(context.Background(), &model.UserEvent{UserID: u2.ID, Type: model.EventMatchWon, GameID: 1}))
+
+	count, err := s.Achievement().CountEvents(context.Background(), u1.ID, model.EventMatchWon, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	count, err = s.Achievement().CountEvents(context.Background(), u1.ID, model.EventMatchWon, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	counts, err := s.Achievement().CountEventsByUser(context.Background(), model.EventMatchWon, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int{u1.ID: 1, u2.ID: 1}, counts)
+}
+
+func TestAchievementRepository_SaveProgress(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+
+	p := &model.AchievementProgress{UserID: u.ID, Code: "critic", Progress: 2, Target: 3}
+	assert.NoError(t, s.Achievement().SaveProgress(context.Background(), p))
+	assert.False(t, p.Unlocked())
+
+	p = &model.AchievementProgress{UserID: u.ID, Code: "critic", Progress: 5, Target: 3}
+	assert.NoError(t, s.Achievement().SaveProgress(context.Background(), p))
+	assert.True(t, p.Unlocked())
+	assert.Equal(t, 3, p.Progress)
+
+	p = &model.AchievementProgress{UserID: u.ID, Code: "critic", Progress: 1, Target: 3}
+	assert.NoError(t, s.Achievement().SaveProgress(context.Background(), p))
+	assert.True(t, p.Unlocked())
+	assert.Equal(t, 3, p.Progress)
+
+	progress, err := s.Achievement().FindProgress(context.Background(), u.ID)
+	assert.NoError(t, err)
+	assert.Len(t, progress, 1)
+	assert.True(t, progress[0].Unlocked())
+}
+
+func TestAchievementRepository_Evaluated(t *testing.T) {
+	s := teststore.New()
+
+	fingerprint, err := s.Achievement().Evaluated(context.Background(), "critic")
+	assert.NoError(t, err)
+	assert.Empty(t, fingerprint)
+
+	assert.NoError(t, s.Achievement().SetEvaluated(context.Background(), "critic", "count=5"))
+	assert.NoError(t, s.Achievement().SetEvaluated(context.Background(), "critic", "count=3"))
+	fingerprint, err = s.Achievement().Evaluated(context.Background(), "critic")
+	assert.NoError(t, err)
+	assert.Equal(t, "count=3", fingerprint)
+}
@@ -0,0 +1,89 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+)
+
+// ActivityRepository object for testing only
+type ActivityRepository struct {
+	store      *Store
+	activities map[int]*model.Activity
+	lastID     int
+}
+
+// Create func. Writing imported activity in the map of test activities.
+func (r *ActivityRepository) Create(ctx context.Context, a *model.Activity) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := r.store.User().Find(ctx, a.UserID); err != nil {
+		return err
+	}
+
+	if a.GameID != 0 {
+		if _, err := r.store.Game().Find(ctx, a.GameID); err != nil {
+			return err
+		}
+	}
+
+	a.BeforeCreate()
+	r.lastID++
+	a.ID = r.lastID
+	r.activities[a.ID] = clone(a).(*model.Activity)
+
+	return nil
+}
+
+// FindByUsers func. Finding the page of activities of the users older
+// than the activity with beforeID, newest first.
+func (r *ActivityRepository) FindByUsers(ctx context.Context, userIDs []int, beforeID, limit int) ([]*model.Activity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	users := make(map[int]bool, len(userIDs))
+	for _, id := range userIDs {
+		users[id] = true
+	}
+
+	activities := []*model.Activity{}
+	for _, a := range r.activities {
+		if users[a.UserID] && (beforeID == 0 || a.ID < beforeID) {
+			activities = append(activities, a)
+		}
+	}
+
+	sort.Slice(activities, func(i, j int) bool {
+		return activities[i].ID > activities[j].ID
+	})
+
+	if len(activities) > limit {
+		activities = activities[:limit]
+	}
+
+	return clone(activities).([]*model.Activity), nil
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *ActivityRepository) copyFor(s *Store) *ActivityRepository {
+	return &ActivityRepository{
+		store:      s,
+		activities: clone(r.activities).(map[int]*model.Activity),
+		lastID:     r.lastID,
+	}
+}
@@ -0,0 +1,113 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// ChatRepository object for testing only
+type ChatRepository struct {
+	store    *Store
+	messages map[int]*model.ChatMessage
+	lastID   int
+}
+
+// Create func. Writing imported chat message in the map of test messages.
+func (r *ChatRepository) Create(ctx context.Context, m *model.ChatMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := r.store.User().Find(ctx, m.UserID); err != nil {
+		return err
+	}
+
+	m.BeforeCreate()
+	r.lastID++
+	m.ID = r.lastID
+	r.messages[m.ID] = clone(m).(*model.ChatMessage)
+
+	return nil
+}
+
+// Find func. Finding chat message with the right (id we need) id
+func (r *ChatRepository) Find(ctx context.Context, id int) (*model.ChatMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	m, ok := r.messages[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return clone(m).(*model.ChatMessage), nil
+}
+
+// Delete func. Deleting chat message with the id from the map
+func (r *ChatRepository) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if _, ok := r.messages[id]; !ok {
+		return store.ErrRecordNotFound
+	}
+	delete(r.messages, id)
+
+	return nil
+}
+
+// FindByRoom func. Finding a page of messages of the chat room written
+// before the message with beforeID, newest first.
+func (r *ChatRepository) FindByRoom(ctx context.Context, room string, beforeID, limit int) ([]*model.ChatMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	messages := []*model.ChatMessage{}
+	for _, m := range r.messages {
+		if m.Room == room && (beforeID == 0 || m.ID < beforeID) {
+			messages = append(messages, m)
+		}
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].ID > messages[j].ID
+	})
+
+	if len(messages) > limit {
+		messages = messages[:limit]
+	}
+
+	return clone(messages).([]*model.ChatMessage), nil
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *ChatRepository) copyFor(s *Store) *ChatRepository {
+	return &ChatRepository{
+		store:    s,
+		messages: clone(r.messages).(map[int]*model.ChatMessage),
+		lastID:   r.lastID,
+	}
+}
@@ -0,0 +1,63 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChatRepository_Create(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+
+	m := model.TestChatMessage(t, model.ChatRoomHall, u.ID)
+	assert.NoError(t, s.Chat().Create(context.Background(), m))
+	assert.NotZero(t, m.ID)
+	assert.EqualError(t, s.Chat().Create(context.Background(), model.TestChatMessage(t, model.ChatRoomHall, u.ID+1)), store.ErrRecordNotFound.Error())
+}
+
+func TestChatRepository_FindByRoom(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+
+	messages := make([]*model.ChatMessage, 5)
+	for i := range messages {
+		messages[i] = model.TestChatMessage(t, model.ChatRoomHall, u.ID)
+		s.Chat().Create(context.Background(), messages[i])
+	}
+	s.Chat().Create(context.Background(), model.TestChatMessage(t, model.GameChatRoom(1), u.ID))
+
+	page, err := s.Chat().FindByRoom(context.Background(), model.ChatRoomHall, 0, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page, 2)
+	assert.Equal(t, messages[4].ID, page[0].ID)
+
+	page, err = s.Chat().FindByRoom(context.Background(), model.ChatRoomHall, page[1].ID, 10)
+	assert.NoError(t, err)
+	assert.Len(t, page, 3)
+	assert.Equal(t, messages[2].ID, page[0].ID)
+}
+
+func TestChatRepository_Delete(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+
+	m := model.TestChatMessage(t, model.ChatRoomHall, u.ID)
+	s.Chat().Create(context.Background(), m)
+
+	found, err := s.Chat().Find(context.Background(), m.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, m.Text, found.Text)
+
+	assert.NoError(t, s.Chat().Delete(context.Background(), m.ID))
+	_, err = s.Chat().Find(context.Background(), m.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	assert.EqualError(t, s.Chat().Delete(context.Background(), m.ID), store.ErrRecordNotFound.Error())
+}
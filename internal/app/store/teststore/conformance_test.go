@@ -0,0 +1,15 @@
+package teststore_test
+
+import (
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/storetest"
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) (store.Store, func()) {
+		return teststore.New(), func() {}
+	})
+}
@@ -0,0 +1,257 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// EventRepository object for testing only
+type EventRepository struct {
+	store  *Store
+	events map[int]*model.Event
+	rsvps  map[int][]*model.EventRSVP
+	tokens map[int]*model.CalendarToken
+	lastID int
+}
+
+// Create func. Writing imported event in the map of test events.
+func (r *EventRepository) Create(ctx context.Context, e *model.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := r.store.User().Find(ctx, e.HostID); err != nil {
+		return err
+	}
+
+	if _, err := r.store.Game().Find(ctx, e.GameID); err != nil {
+		return err
+	}
+
+	e.BeforeCreate()
+	r.lastID++
+	e.ID = r.lastID
+	r.events[e.ID] = clone(e).(*model.Event)
+
+	return nil
+}
+
+// Find func. Finding event with the right (id we need) id.
+func (r *EventRepository) Find(ctx context.Context, id int) (*model.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	e, ok := r.events[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return clone(e).(*model.Event), nil
+}
+
+// FindUpcoming func. Finding the page of events which aren't over at
+// the time, soonest first.
+func (r *EventRepository) FindUpcoming(ctx context.Context, now time.Time, q *store.ListQuery) ([]*model.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	events := []*model.Event{}
+	for _, e := range r.events {
+		if !e.Over(now) {
+			events = append(events, e)
+		}
+	}
+	sortEvents(events)
+
+	if q.Offset >= len(events) {
+		return []*model.Event{}, nil
+	}
+	events = events[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(events) {
+		events = events[:q.Limit]
+	}
+
+	return clone(events).([]*model.Event), nil
+}
+
+// FindByAttendee func. Finding events the user goes to, maybe goes to
+// or waits for, soonest first.
+func (r *EventRepository) FindByAttendee(ctx context.Context, userID int) ([]*model.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	events := []*model.Event{}
+	for eventID, rsvps := range r.rsvps {
+		for _, rsvp := range rsvps {
+			if rsvp.UserID == userID && rsvp.Status != model.RSVPDeclined {
+				events = append(events, r.events[eventID])
+			}
+		}
+	}
+	sortEvents(events)
+
+	return clone(events).([]*model.Event), nil
+}
+
+// RSVP func. Applying RSVP of the user with the status to the event.
+func (r *EventRepository) RSVP(ctx context.Context, eventID, userID int, status string) ([]*model.EventRSVP, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	e, ok := r.events[eventID]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	if _, err := r.store.User().Find(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	// Stored RSVPs are changed in place, a new one is added
+	changed, err := e.ApplyRSVP(r.rsvps[eventID], userID, status, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	if !r.has(eventID, userID) {
+		r.rsvps[eventID] = append(r.rsvps[eventID], changed[0])
+	}
+
+	return clone(changed).([]*model.EventRSVP), nil
+}
+
+// RSVPs func. Finding RSVPs to the event in order they were changed.
+func (r *EventRepository) RSVPs(ctx context.Context, eventID int) ([]*model.EventRSVP, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	rsvps := clone(r.rsvps[eventID]).([]*model.EventRSVP)
+	sort.Slice(rsvps, func(i, j int) bool {
+		a, b := rsvps[i], rsvps[j]
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.Before(b.UpdatedAt)
+		}
+		return a.UserID < b.UserID
+	})
+
+	return rsvps, nil
+}
+
+// SetCalendarToken func. Writing calendar token of the user in the map
+// of test tokens instead of the old one.
+func (r *EventRepository) SetCalendarToken(ctx context.Context, t *model.CalendarToken) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if _, err := r.store.User().Find(ctx, t.UserID); err != nil {
+		return err
+	}
+
+	// Only the hash is kept, like in DB
+	stored := clone(t).(*model.CalendarToken)
+	stored.Token = ""
+	r.tokens[t.UserID] = stored
+
+	return nil
+}
+
+// RevokeCalendarToken func. Deleting calendar token of the user.
+func (r *EventRepository) RevokeCalendarToken(ctx context.Context, userID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	delete(r.tokens, userID)
+
+	return nil
+}
+
+// FindCalendarToken func. Finding calendar token with the hash.
+func (r *EventRepository) FindCalendarToken(ctx context.Context, hash string) (*model.CalendarToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	for _, t := range r.tokens {
+		if t.Hash == hash {
+			return clone(t).(*model.CalendarToken), nil
+		}
+	}
+
+	return nil, store.ErrRecordNotFound
+}
+
+// has func. Checks if the user has RSVPed to the event.
+func (r *EventRepository) has(eventID, userID int) bool {
+	for _, rsvp := range r.rsvps[eventID] {
+		if rsvp.UserID == userID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortEvents func. Sorting events soonest first.
+func sortEvents(events []*model.Event) {
+	sort.Slice(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if !a.StartsAt.Equal(b.StartsAt) {
+			return a.StartsAt.Before(b.StartsAt)
+		}
+		return a.ID < b.ID
+	})
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *EventRepository) copyFor(s *Store) *EventRepository {
+	return &EventRepository{
+		store:  s,
+		events: clone(r.events).(map[int]*model.Event),
+		rsvps:  clone(r.rsvps).(map[int][]*model.EventRSVP),
+		tokens: clone(r.tokens).(map[int]*model.CalendarToken),
+		lastID: r.lastID,
+	}
+}
@@ -0,0 +1,257 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// friendshipKey object. Ordered pair of user ids.
+type friendshipKey struct {
+	userID  int
+	otherID int
+}
+
+// FriendshipRepository object for testing only
+type FriendshipRepository struct {
+	store       *Store
+	friendships map[friendshipKey]*model.Friendship
+	blocks      map[friendshipKey]time.Time
+}
+
+// Find func. Finding friendship between two users in any direction.
+func (r *FriendshipRepository) Find(ctx context.Context, userID, otherID int) (*model.Friendship, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	f := r.find(userID, otherID)
+	if f == nil {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return clone(f).(*model.Friendship), nil
+}
+
+// find func. Returns stored friendship between two users in any
+// direction, nil if there's none.
+func (r *FriendshipRepository) find(userID, otherID int) *model.Friendship {
+	if f, ok := r.friendships[friendshipKey{userID, otherID}]; ok {
+		return f
+	}
+
+	return r.friendships[friendshipKey{otherID, userID}]
+}
+
+// Apply func. Applying friendship action of the actor to the friendship
+// with other user. For additional information check
+// friendshiprepository.go documentation in sqlstore dir.
+func (r *FriendshipRepository) Apply(ctx context.Context, actorID, otherID int, action string) (*model.Friendship, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if action == model.FriendshipRequest {
+		if blocked, _ := r.IsBlocked(ctx, actorID, otherID); blocked {
+			return nil, store.ErrRecordNotFound
+		}
+	}
+
+	f := r.find(actorID, otherID)
+	next, err := model.NextFriendship(f, actorID, otherID, action)
+	if err != nil {
+		return nil, err
+	}
+
+	if f == nil {
+		if err := r.checkUsers(ctx, actorID, otherID); err != nil {
+			return nil, err
+		}
+	} else {
+		delete(r.friendships, friendshipKey{f.UserID, f.FriendID})
+	}
+
+	if next != nil {
+		r.friendships[friendshipKey{next.UserID, next.FriendID}] = clone(next).(*model.Friendship)
+	}
+
+	return next, nil
+}
+
+// FindByUser func. Finding all friendships of the user with the status.
+func (r *FriendshipRepository) FindByUser(ctx context.Context, userID int, status string) ([]*model.Friendship, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	friendships := []*model.Friendship{}
+	for k, f := range r.friendships {
+		if (k.userID == userID || k.otherID == userID) && f.Status == status {
+			friendships = append(friendships, f)
+		}
+	}
+
+	sort.Slice(friendships, func(i, j int) bool {
+		return friendships[i].UpdatedAt.After(friendships[j].UpdatedAt)
+	})
+
+	return clone(friendships).([]*model.Friendship), nil
+}
+
+// Mutual func. Finding ids of users who are friends of both users.
+func (r *FriendshipRepository) Mutual(ctx context.Context, userID, otherID int) ([]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	friends := r.friends(userID)
+	ids := []int{}
+	for id := range r.friends(otherID) {
+		if friends[id] {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Ints(ids)
+
+	return ids, nil
+}
+
+// Block func. Writing a block of the blocked user by the blocker and
+// removing any friendship between them.
+func (r *FriendshipRepository) Block(ctx context.Context, blockerID, blockedID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if blockerID == blockedID {
+		return model.ErrInvalidFriendshipTransition
+	}
+
+	if err := r.checkUsers(ctx, blockerID, blockedID); err != nil {
+		return err
+	}
+
+	delete(r.friendships, friendshipKey{blockerID, blockedID})
+	delete(r.friendships, friendshipKey{blockedID, blockerID})
+	if _, ok := r.blocks[friendshipKey{blockerID, blockedID}]; !ok {
+		r.blocks[friendshipKey{blockerID, blockedID}] = time.Now()
+	}
+
+	return nil
+}
+
+// Unblock func. Deleting a block of the blocked user by the blocker.
+func (r *FriendshipRepository) Unblock(ctx context.Context, blockerID, blockedID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if _, ok := r.blocks[friendshipKey{blockerID, blockedID}]; !ok {
+		return store.ErrRecordNotFound
+	}
+
+	delete(r.blocks, friendshipKey{blockerID, blockedID})
+
+	return nil
+}
+
+// Blocked func. Finding ids of users blocked by the blocker.
+func (r *FriendshipRepository) Blocked(ctx context.Context, blockerID int) ([]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	ids := []int{}
+	for k := range r.blocks {
+		if k.userID == blockerID {
+			ids = append(ids, k.otherID)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return r.blocks[friendshipKey{blockerID, ids[i]}].After(r.blocks[friendshipKey{blockerID, ids[j]}])
+	})
+
+	return ids, nil
+}
+
+// IsBlocked func. Checks if any of two users blocked the other one.
+func (r *FriendshipRepository) IsBlocked(ctx context.Context, userID, otherID int) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	_, blocked := r.blocks[friendshipKey{userID, otherID}]
+	_, blockedBack := r.blocks[friendshipKey{otherID, userID}]
+
+	return blocked || blockedBack, nil
+}
+
+// friends func. Returns set of ids of user's friends.
+func (r *FriendshipRepository) friends(userID int) map[int]bool {
+	friends := map[int]bool{}
+	for k, f := range r.friendships {
+		if f.Status != model.FriendshipAccepted {
+			continue
+		}
+
+		switch userID {
+		case k.userID:
+			friends[k.otherID] = true
+		case k.otherID:
+			friends[k.userID] = true
+		}
+	}
+
+	return friends
+}
+
+// checkUsers func. Checks that both users exist, as foreign keys
+// of friendships and blocks tables do.
+func (r *FriendshipRepository) checkUsers(ctx context.Context, userID, otherID int) error {
+	if _, err := r.store.User().Find(ctx, userID); err != nil {
+		return err
+	}
+
+	_, err := r.store.User().Find(ctx, otherID)
+
+	return err
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *FriendshipRepository) copyFor(s *Store) *FriendshipRepository {
+	return &FriendshipRepository{
+		store:       s,
+		friendships: clone(r.friendships).(map[friendshipKey]*model.Friendship),
+		blocks:      clone(r.blocks).(map[friendshipKey]time.Time),
+	}
+}
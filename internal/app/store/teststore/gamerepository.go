@@ -0,0 +1,67 @@
+package teststore
+
+import (
+	"context"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// GameRepository object for testing only. Games are stored and returned
+// as copies, aggregated ratings of stored ones are changed by
+// ReviewRepository.
+type GameRepository struct {
+	store  *Store
+	games  map[int]*model.Game
+	lastID int
+}
+
+// Create func. Writing imported Game in the map of test games.
+// Aggregated rating of the new game starts empty.
+func (r *GameRepository) Create(ctx context.Context, g *model.Game) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	g.BeforeCreate()
+	r.lastID++
+	g.ID = r.lastID
+	r.games[g.ID] = clone(g).(*model.Game)
+
+	return nil
+}
+
+// Find func. Finding game with the right (id we need) id.
+// Function for testing only purposes.
+func (r *GameRepository) Find(ctx context.Context, id int) (*model.Game, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	g, ok := r.games[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return clone(g).(*model.Game), nil
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *GameRepository) copyFor(s *Store) *GameRepository {
+	return &GameRepository{
+		store:  s,
+		games:  clone(r.games).(map[int]*model.Game),
+		lastID: r.lastID,
+	}
+}
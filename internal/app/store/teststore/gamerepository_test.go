@@ -0,0 +1,30 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGameRepository_Create(t *testing.T) {
+	s := teststore.New()
+	g := model.TestGame(t)
+	assert.NoError(t, s.Game().Create(context.Background(), g))
+	assert.NotZero(t, g.ID)
+}
+
+func TestGameRepository_Find(t *testing.T) {
+	s := teststore.New()
+	_, err := s.Game().Find(context.Background(), 1)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	g1 := model.TestGame(t)
+	s.Game().Create(context.Background(), g1)
+	g2, err := s.Game().Find(context.Background(), g1.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, g2)
+}
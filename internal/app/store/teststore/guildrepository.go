@@ -0,0 +1,582 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// guildInviteKey object. Every user has one pending invite per guild.
+type guildInviteKey struct {
+	guildID int
+	userID  int
+}
+
+// guildRoleOrder describes order of members in the list of members
+var guildRoleOrder = map[string]int{
+	model.GuildRoleLeader:  0,
+	model.GuildRoleOfficer: 1,
+	model.GuildRoleMember:  2,
+}
+
+// GuildRepository object for testing only
+type GuildRepository struct {
+	store   *Store
+	guilds  map[int]*model.Guild
+	members map[int]*model.GuildMember
+	invites map[guildInviteKey]*model.GuildInvite
+	lastID  int
+}
+
+// Create func. Writing imported guild in the map of test guilds
+// together with membership of its leader.
+func (r *GuildRepository) Create(ctx context.Context, g *model.Guild, leaderID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	if err := r.checkUnique(g); err != nil {
+		return err
+	}
+
+	if _, err := r.store.User().Find(ctx, leaderID); err != nil {
+		return err
+	}
+
+	if _, ok := r.members[leaderID]; ok {
+		return store.ErrRecordExists
+	}
+
+	g.BeforeCreate()
+	r.lastID++
+	g.ID = r.lastID
+	g.MemberCount = 1
+	r.guilds[g.ID] = clone(g).(*model.Guild)
+	r.members[leaderID] = &model.GuildMember{
+		GuildID:  g.ID,
+		UserID:   leaderID,
+		Role:     model.GuildRoleLeader,
+		JoinedAt: g.CreatedAt,
+	}
+
+	return nil
+}
+
+// Find func. Finding guild with the right (id we need) id.
+func (r *GuildRepository) Find(ctx context.Context, id int) (*model.Guild, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	g, ok := r.guilds[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+	g = clone(g).(*model.Guild)
+	g.MemberCount = r.count(id)
+
+	return g, nil
+}
+
+// List func. Finding the page of guilds ordered by name.
+func (r *GuildRepository) List(ctx context.Context, q *store.ListQuery) ([]*model.Guild, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	guilds := []*model.Guild{}
+	for _, g := range r.guilds {
+		g = clone(g).(*model.Guild)
+		g.MemberCount = r.count(g.ID)
+		guilds = append(guilds, g)
+	}
+
+	sort.Slice(guilds, func(i, j int) bool {
+		a, b := strings.ToLower(guilds[i].Name), strings.ToLower(guilds[j].Name)
+		if a != b {
+			return a < b
+		}
+		return guilds[i].ID < guilds[j].ID
+	})
+
+	if q.Offset >= len(guilds) {
+		return []*model.Guild{}, nil
+	}
+	guilds = guilds[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(guilds) {
+		guilds = guilds[:q.Limit]
+	}
+
+	return guilds, nil
+}
+
+// Update func. Changing name, tag, description and emblem of the guild.
+func (r *GuildRepository) Update(ctx context.Context, g *model.Guild) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	current, ok := r.guilds[g.ID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	if err := r.checkUnique(g); err != nil {
+		return err
+	}
+
+	current.Name = g.Name
+	current.Tag = g.Tag
+	current.Description = g.Description
+	current.EmblemURL = g.EmblemURL
+
+	return nil
+}
+
+// Delete func. Deleting the guild with its members and invites.
+func (r *GuildRepository) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if _, ok := r.guilds[id]; !ok {
+		return store.ErrRecordNotFound
+	}
+
+	delete(r.guilds, id)
+	for userID, m := range r.members {
+		if m.GuildID == id {
+			delete(r.members, userID)
+		}
+	}
+	for k := range r.invites {
+		if k.guildID == id {
+			delete(r.invites, k)
+		}
+	}
+
+	return nil
+}
+
+// Member func. Finding membership of the user in the guild.
+func (r *GuildRepository) Member(ctx context.Context, guildID, userID int) (*model.GuildMember, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	m := r.member(guildID, userID)
+	if m == nil {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return clone(m).(*model.GuildMember), nil
+}
+
+// MemberOf func. Finding membership of the user in any guild.
+func (r *GuildRepository) MemberOf(ctx context.Context, userID int) (*model.GuildMember, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	m, ok := r.members[userID]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return clone(m).(*model.GuildMember), nil
+}
+
+// Members func. Finding members of the guild, the leader first,
+// then officers and members in order of joining.
+func (r *GuildRepository) Members(ctx context.Context, guildID int) ([]*model.GuildMember, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	members := []*model.GuildMember{}
+	for _, m := range r.members {
+		if m.GuildID == guildID {
+			members = append(members, m)
+		}
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		a, b := members[i], members[j]
+		if a.Role != b.Role {
+			return guildRoleOrder[a.Role] < guildRoleOrder[b.Role]
+		}
+		if !a.JoinedAt.Equal(b.JoinedAt) {
+			return a.JoinedAt.Before(b.JoinedAt)
+		}
+		return a.UserID < b.UserID
+	})
+
+	return clone(members).([]*model.GuildMember), nil
+}
+
+// RemoveMember func. Deleting the user from members of the guild. The
+// leader can't be removed.
+func (r *GuildRepository) RemoveMember(ctx context.Context, guildID, userID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	m := r.member(guildID, userID)
+	if m == nil {
+		return store.ErrRecordNotFound
+	}
+
+	if m.Role == model.GuildRoleLeader {
+		return model.ErrGuildLeader
+	}
+	delete(r.members, userID)
+
+	return nil
+}
+
+// SetRole func. Changing role of the member of the guild. The leader
+// can't be demoted.
+func (r *GuildRepository) SetRole(ctx context.Context, m *model.GuildMember) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	current := r.member(m.GuildID, m.UserID)
+	if current == nil {
+		return store.ErrRecordNotFound
+	}
+
+	if current.Role == model.GuildRoleLeader {
+		return model.ErrGuildLeader
+	}
+	current.Role = m.Role
+	m.JoinedAt = current.JoinedAt
+
+	return nil
+}
+
+// Transfer func. Making the member of the guild its leader. The old
+// leader becomes an officer.
+func (r *GuildRepository) Transfer(ctx context.Context, guildID, leaderID, userID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	leader := r.member(guildID, leaderID)
+	if leader == nil || leader.Role != model.GuildRoleLeader {
+		return store.ErrRecordNotFound
+	}
+
+	m := r.member(guildID, userID)
+	if m == nil {
+		return store.ErrRecordNotFound
+	}
+
+	if leaderID == userID {
+		return nil
+	}
+	leader.Role = model.GuildRoleOfficer
+	m.Role = model.GuildRoleLeader
+
+	return nil
+}
+
+// Invite func. Writing imported invite in the map of test invites, or
+// accepting the pending opposite one. The user joins the guild in that
+// case, and the rest of the invites of the user are deleted.
+func (r *GuildRepository) Invite(ctx context.Context, i *model.GuildInvite) (*model.GuildMember, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := i.Validate(); err != nil {
+		return nil, err
+	}
+
+	if _, ok := r.guilds[i.GuildID]; !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	if _, ok := r.members[i.UserID]; ok {
+		return nil, store.ErrRecordExists
+	}
+
+	accepts, err := model.AcceptsGuildInvite(r.invites[guildInviteKey{i.GuildID, i.UserID}], i)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.store.User().Find(ctx, i.UserID); err != nil {
+		return nil, err
+	}
+
+	i.BeforeCreate()
+	if !accepts {
+		r.invites[guildInviteKey{i.GuildID, i.UserID}] = clone(i).(*model.GuildInvite)
+		return nil, nil
+	}
+
+	for k := range r.invites {
+		if k.userID == i.UserID {
+			delete(r.invites, k)
+		}
+	}
+
+	m := &model.GuildMember{
+		GuildID:  i.GuildID,
+		UserID:   i.UserID,
+		Role:     model.GuildRoleMember,
+		JoinedAt: i.CreatedAt,
+	}
+	r.members[i.UserID] = clone(m).(*model.GuildMember)
+
+	return m, nil
+}
+
+// RemoveInvite func. Deleting pending invite or join request of the
+// user to the guild.
+func (r *GuildRepository) RemoveInvite(ctx context.Context, guildID, userID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	k := guildInviteKey{guildID, userID}
+	if _, ok := r.invites[k]; !ok {
+		return store.ErrRecordNotFound
+	}
+	delete(r.invites, k)
+
+	return nil
+}
+
+// Invites func. Finding pending invites of the guild, oldest first.
+func (r *GuildRepository) Invites(ctx context.Context, guildID int) ([]*model.GuildInvite, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	return r.findInvites(func(k guildInviteKey) bool {
+		return k.guildID == guildID
+	}), nil
+}
+
+// InvitesOf func. Finding pending invites of the user, oldest first.
+func (r *GuildRepository) InvitesOf(ctx context.Context, userID int) ([]*model.GuildInvite, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	return r.findInvites(func(k guildInviteKey) bool {
+		return k.userID == userID
+	}), nil
+}
+
+// Leaderboard func. Finding the page of the guild leaderboard of the
+// game during the season.
+func (r *GuildRepository) Leaderboard(ctx context.Context, gameID int, season string, q *store.ListQuery) ([]*model.GuildLeaderboardEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	ranked := r.ranked(gameID, season)
+	entries := []*model.GuildLeaderboardEntry{}
+	for i := q.Offset; i < len(ranked) && (q.Limit <= 0 || i < q.Offset+q.Limit); i++ {
+		entries = append(entries, ranked[i])
+	}
+
+	return entries, nil
+}
+
+// Rank func. Finding the place of the guild in the guild leaderboard
+// of the game during the season.
+func (r *GuildRepository) Rank(ctx context.Context, guildID, gameID int, season string) (*model.GuildLeaderboardEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	for _, e := range r.ranked(gameID, season) {
+		if e.GuildID == guildID {
+			return e, nil
+		}
+	}
+
+	return nil, store.ErrRecordNotFound
+}
+
+// checkUnique func. Checks that no other guild has the name or the tag
+// of the imported one
+func (r *GuildRepository) checkUnique(g *model.Guild) error {
+	for _, other := range r.guilds {
+		if other.ID != g.ID && (strings.EqualFold(other.Name, g.Name) || other.Tag == g.Tag) {
+			return store.ErrRecordExists
+		}
+	}
+
+	return nil
+}
+
+// member func. Returns stored membership of the user in the guild, nil
+// if the user isn't a member of it
+func (r *GuildRepository) member(guildID, userID int) *model.GuildMember {
+	m, ok := r.members[userID]
+	if !ok || m.GuildID != guildID {
+		return nil
+	}
+
+	return m
+}
+
+// count func. Returns number of members of the guild
+func (r *GuildRepository) count(guildID int) int {
+	n := 0
+	for _, m := range r.members {
+		if m.GuildID == guildID {
+			n++
+		}
+	}
+
+	return n
+}
+
+// findInvites func. Returns pending invites matching the filter, oldest first
+func (r *GuildRepository) findInvites(filter func(guildInviteKey) bool) []*model.GuildInvite {
+	invites := []*model.GuildInvite{}
+	for k, i := range r.invites {
+		if filter(k) {
+			invites = append(invites, clone(i).(*model.GuildInvite))
+		}
+	}
+
+	sort.Slice(invites, func(i, j int) bool {
+		a, b := invites[i], invites[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		if a.GuildID != b.GuildID {
+			return a.GuildID < b.GuildID
+		}
+		return a.UserID < b.UserID
+	})
+
+	return invites
+}
+
+// ranked func. Returns guilds with rated members in the game during the
+// season in guild leaderboard order
+func (r *GuildRepository) ranked(gameID int, season string) []*model.GuildLeaderboardEntry {
+	byGuild := make(map[int]*model.GuildLeaderboardEntry)
+	ratings := r.store.Rating().(*RatingRepository).ratings
+	for k, rt := range ratings {
+		m, ok := r.members[k.userID]
+		if !ok || k.gameID != gameID || k.season != season {
+			continue
+		}
+
+		e, ok := byGuild[m.GuildID]
+		if !ok {
+			g := r.guilds[m.GuildID]
+			e = &model.GuildLeaderboardEntry{GuildID: g.ID, Name: g.Name, Tag: g.Tag}
+			byGuild[g.ID] = e
+		}
+		// Summing ratings up, they are turned into means below
+		e.Rating += rt.Rating
+		e.Players++
+	}
+
+	entries := make([]*model.GuildLeaderboardEntry, 0, len(byGuild))
+	for _, e := range byGuild {
+		e.Rating /= float64(e.Players)
+		entries = append(entries, e)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Rating != entries[j].Rating {
+			return entries[i].Rating > entries[j].Rating
+		}
+		return entries[i].GuildID < entries[j].GuildID
+	})
+
+	for i, e := range entries {
+		e.Rank = i + 1
+	}
+
+	return entries
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *GuildRepository) copyFor(s *Store) *GuildRepository {
+	return &GuildRepository{
+		store:   s,
+		guilds:  clone(r.guilds).(map[int]*model.Guild),
+		members: clone(r.members).(map[int]*model.GuildMember),
+		invites: clone(r.invites).(map[guildInviteKey]*model.GuildInvite),
+		lastID:  r.lastID,
+	}
+}
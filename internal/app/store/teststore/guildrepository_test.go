@@ -0,0 +1,173 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuildRepository_Create(t *testing.T) {
+	s := teststore.New()
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+
+	g := model.TestGuild(t)
+	assert.NoError(t, s.Guild().Create(context.Background(), g, u1.ID))
+	assert.NotZero(t, g.ID)
+
+	other := model.TestGuild(t)
+	assert.EqualError(t, s.Guild().Create(context.Background(), other, u2.ID), store.ErrRecordExists.Error())
+
+	other.Name = "Round Table"
+	other.Tag = "RT"
+	assert.EqualError(t, s.Guild().Create(context.Background(), other, u1.ID), store.ErrRecordExists.Error())
+
+	g, err := s.Guild().Find(context.Background(), g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, g.MemberCount)
+
+	m, err := s.Guild().MemberOf(context.Background(), u1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.GuildRoleLeader, m.Role)
+
+	guilds, err := s.Guild().List(context.Background(), &store.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, guilds, 1)
+}
+
+func TestGuildRepository_Invite(t *testing.T) {
+	s := teststore.New()
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+	u3 := model.TestUser(t)
+	u3.Email = "user3@example.org"
+	s.User().Create(context.Background(), u3)
+
+	g := model.TestGuild(t)
+	s.Guild().Create(context.Background(), g, u1.ID)
+
+	invitation := &model.GuildInvite{GuildID: g.ID, UserID: u2.ID, Kind: model.GuildInvitation, InviterID: u1.ID}
+	m, err := s.Guild().Invite(context.Background(), invitation)
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+
+	_, err = s.Guild().Invite(context.Background(), invitation)
+	assert.EqualError(t, err, model.ErrGuildInviteExists.Error())
+
+	_, err = s.Guild().Invite(context.Background(), &model.GuildInvite{GuildID: g.ID, UserID: u1.ID, Kind: model.GuildJoinRequest})
+	assert.EqualError(t, err, store.ErrRecordExists.Error())
+
+	invites, err := s.Guild().InvitesOf(context.Background(), u2.ID)
+	assert.NoError(t, err)
+	assert.Len(t, invites, 1)
+
+	m, err = s.Guild().Invite(context.Background(), &model.GuildInvite{GuildID: g.ID, UserID: u2.ID, Kind: model.GuildJoinRequest})
+	assert.NoError(t, err)
+	assert.Equal(t, model.GuildRoleMember, m.Role)
+
+	invites, err = s.Guild().Invites(context.Background(), g.ID)
+	assert.NoError(t, err)
+	assert.Len(t, invites, 0)
+
+	_, err = s.Guild().Invite(context.Background(), &model.GuildInvite{GuildID: g.ID, UserID: u3.ID, Kind: model.GuildJoinRequest})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Guild().RemoveInvite(context.Background(), g.ID, u3.ID))
+	assert.EqualError(t, s.Guild().RemoveInvite(context.Background(), g.ID, u3.ID), store.ErrRecordNotFound.Error())
+
+	members, err := s.Guild().Members(context.Background(), g.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, members, 2) {
+		assert.Equal(t, u1.ID, members[0].UserID)
+		assert.Equal(t, u2.ID, members[1].UserID)
+	}
+}
+
+func TestGuildRepository_Roles(t *testing.T) {
+	s := teststore.New()
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+
+	g := model.TestGuild(t)
+	s.Guild().Create(context.Background(), g, u1.ID)
+	s.Guild().Invite(context.Background(), &model.GuildInvite{GuildID: g.ID, UserID: u2.ID, Kind: model.GuildJoinRequest})
+	s.Guild().Invite(context.Background(), &model.GuildInvite{GuildID: g.ID, UserID: u2.ID, Kind: model.GuildInvitation, InviterID: u1.ID})
+
+	assert.NoError(t, s.Guild().SetRole(context.Background(), &model.GuildMember{GuildID: g.ID, UserID: u2.ID, Role: model.GuildRoleOfficer}))
+	assert.Error(t, s.Guild().SetRole(context.Background(), &model.GuildMember{GuildID: g.ID, UserID: u2.ID, Role: model.GuildRoleLeader}))
+	assert.EqualError(
+		t,
+		s.Guild().SetRole(context.Background(), &model.GuildMember{GuildID: g.ID, UserID: u1.ID, Role: model.GuildRoleMember}),
+		model.ErrGuildLeader.Error(),
+	)
+	assert.EqualError(t, s.Guild().RemoveMember(context.Background(), g.ID, u1.ID), model.ErrGuildLeader.Error())
+
+	assert.EqualError(t, s.Guild().Transfer(context.Background(), g.ID, u2.ID, u1.ID), store.ErrRecordNotFound.Error())
+	assert.NoError(t, s.Guild().Transfer(context.Background(), g.ID, u1.ID, u2.ID))
+
+	m, err := s.Guild().Member(context.Background(), g.ID, u1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.GuildRoleOfficer, m.Role)
+
+	m, err = s.Guild().Member(context.Background(), g.ID, u2.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.GuildRoleLeader, m.Role)
+
+	assert.NoError(t, s.Guild().RemoveMember(context.Background(), g.ID, u1.ID))
+	_, err = s.Guild().MemberOf(context.Background(), u1.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	assert.NoError(t, s.Guild().Delete(context.Background(), g.ID))
+	_, err = s.Guild().MemberOf(context.Background(), u2.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
+
+func TestGuildRepository_Leaderboard(t *testing.T) {
+	s := teststore.New()
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	g1 := model.TestGuild(t)
+	s.Guild().Create(context.Background(), g1, u1.ID)
+	g2 := model.TestGuild(t)
+	g2.Name = "Round Table"
+	g2.Tag = "RT"
+	s.Guild().Create(context.Background(), g2, u2.ID)
+
+	m := model.TestMatch(t, g.ID, u1.ID, u2.ID)
+	s.Match().Create(context.Background(), m)
+	m.Confirm(u2.ID)
+	s.Match().Update(context.Background(), m)
+
+	entries, err := s.Guild().Leaderboard(context.Background(), g.ID, m.Season, &store.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, g1.ID, entries[0].GuildID)
+		assert.Equal(t, 1, entries[0].Players)
+		assert.Greater(t, entries[0].Rating, entries[1].Rating)
+	}
+
+	e, err := s.Guild().Rank(context.Background(), g2.ID, g.ID, m.Season)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, e.Rank)
+
+	_, err = s.Guild().Rank(context.Background(), g2.ID, g.ID, "2019-Q1")
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
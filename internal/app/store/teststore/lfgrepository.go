@@ -0,0 +1,212 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// LFGRepository object for testing only
+type LFGRepository struct {
+	store    *Store
+	posts    map[int]*model.LFGPost
+	requests map[int][]*model.LFGRequest
+	lastID   int
+}
+
+// Create func. Writing imported LFG post in the map of test posts.
+func (r *LFGRepository) Create(ctx context.Context, p *model.LFGPost) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := r.store.User().Find(ctx, p.UserID); err != nil {
+		return err
+	}
+
+	if _, err := r.store.Game().Find(ctx, p.GameID); err != nil {
+		return err
+	}
+
+	p.BeforeCreate()
+	r.lastID++
+	p.ID = r.lastID
+	r.posts[p.ID] = clone(p).(*model.LFGPost)
+
+	return nil
+}
+
+// Find func. Finding LFG post with the right (id we need) id.
+func (r *LFGRepository) Find(ctx context.Context, id int) (*model.LFGPost, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	p, ok := r.posts[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return clone(p).(*model.LFGPost), nil
+}
+
+// FindOpen func. Finding the page of open posts matching the filter,
+// which haven't expired at the time, soonest games first.
+func (r *LFGRepository) FindOpen(ctx context.Context, f *store.LFGFilter, now time.Time, q *store.ListQuery) ([]*model.LFGPost, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	posts := []*model.LFGPost{}
+	for _, p := range r.posts {
+		switch {
+		case p.Status != model.LFGOpen || p.Expired(now):
+		case f.GameID != 0 && p.GameID != f.GameID:
+		case f.Platform != "" && p.Platform != f.Platform:
+		case f.Language != "" && p.Language != f.Language:
+		case !f.From.IsZero() && p.StartsAt.Before(f.From):
+		case !f.To.IsZero() && p.StartsAt.After(f.To):
+		default:
+			posts = append(posts, p)
+		}
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		a, b := posts[i], posts[j]
+		if !a.StartsAt.Equal(b.StartsAt) {
+			return a.StartsAt.Before(b.StartsAt)
+		}
+		return a.ID < b.ID
+	})
+
+	if q.Offset >= len(posts) {
+		return []*model.LFGPost{}, nil
+	}
+	posts = posts[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(posts) {
+		posts = posts[:q.Limit]
+	}
+
+	return clone(posts).([]*model.LFGPost), nil
+}
+
+// Close func. Closing the open post, so nobody can ask to join it anymore.
+func (r *LFGRepository) Close(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	p, ok := r.posts[id]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	if p.Status != model.LFGOpen {
+		return model.ErrLFGPostClosed
+	}
+	p.Status = model.LFGClosed
+
+	return nil
+}
+
+// Expire func. Marking open posts which expired at the time as expired.
+func (r *LFGRepository) Expire(ctx context.Context, now time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	n := 0
+	for _, p := range r.posts {
+		if p.Status == model.LFGOpen && !now.Before(p.ExpiresAt) {
+			p.Status = model.LFGExpired
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// Request func. Writing request of the user to join the group of the post.
+func (r *LFGRepository) Request(ctx context.Context, req *model.LFGRequest) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	req.BeforeCreate()
+
+	p, ok := r.posts[req.PostID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	if err := p.CanRequest(req.UserID, req.CreatedAt); err != nil {
+		return err
+	}
+
+	for _, other := range r.requests[req.PostID] {
+		if other.UserID == req.UserID {
+			return store.ErrRecordExists
+		}
+	}
+
+	if _, err := r.store.User().Find(ctx, req.UserID); err != nil {
+		return err
+	}
+
+	r.requests[req.PostID] = append(r.requests[req.PostID], clone(req).(*model.LFGRequest))
+
+	return nil
+}
+
+// Requests func. Finding requests to join the group of the post in
+// order they were sent.
+func (r *LFGRepository) Requests(ctx context.Context, postID int) ([]*model.LFGRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	return clone(r.requests[postID]).([]*model.LFGRequest), nil
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *LFGRepository) copyFor(s *Store) *LFGRepository {
+	return &LFGRepository{
+		store:    s,
+		posts:    clone(r.posts).(map[int]*model.LFGPost),
+		requests: clone(r.requests).(map[int][]*model.LFGRequest),
+		lastID:   r.lastID,
+	}
+}
@@ -0,0 +1,107 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLFGRepository_FindOpen(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	assert.EqualError(t, s.LFG().Create(context.Background(), model.TestLFGPost(t, u.ID, g.ID+1)), store.ErrRecordNotFound.Error())
+
+	p1 := model.TestLFGPost(t, u.ID, g.ID)
+	assert.NoError(t, s.LFG().Create(context.Background(), p1))
+	assert.NotZero(t, p1.ID)
+	assert.Equal(t, model.LFGOpen, p1.Status)
+
+	p2 := model.TestLFGPost(t, u.ID, g.ID)
+	p2.Platform = model.PlatformTabletop
+	p2.Language = "de"
+	p2.StartsAt = p1.StartsAt.Add(-30 * time.Minute)
+	s.LFG().Create(context.Background(), p2)
+
+	now := time.Now()
+	q := &store.ListQuery{Limit: 10}
+	posts, err := s.LFG().FindOpen(context.Background(), &store.LFGFilter{}, now, q)
+	assert.NoError(t, err)
+	if assert.Len(t, posts, 2) {
+		assert.Equal(t, p2.ID, posts[0].ID)
+	}
+
+	posts, err = s.LFG().FindOpen(context.Background(), &store.LFGFilter{Platform: model.PlatformPC, Language: "en"}, now, q)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+
+	posts, err = s.LFG().FindOpen(context.Background(), &store.LFGFilter{From: p1.StartsAt.Add(-time.Minute)}, now, q)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+
+	posts, err = s.LFG().FindOpen(context.Background(), &store.LFGFilter{GameID: g.ID}, p1.ExpiresAt, q)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 0)
+}
+
+func TestLFGRepository_Expire(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	p1 := model.TestLFGPost(t, u.ID, g.ID)
+	s.LFG().Create(context.Background(), p1)
+	p2 := model.TestLFGPost(t, u.ID, g.ID)
+	p2.StartsAt = p1.StartsAt.Add(time.Hour)
+	s.LFG().Create(context.Background(), p2)
+
+	n, err := s.LFG().Expire(context.Background(), p1.ExpiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	p1, err = s.LFG().Find(context.Background(), p1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LFGExpired, p1.Status)
+
+	assert.NoError(t, s.LFG().Close(context.Background(), p2.ID))
+	assert.EqualError(t, s.LFG().Close(context.Background(), p2.ID), model.ErrLFGPostClosed.Error())
+
+	n, err = s.LFG().Expire(context.Background(), p2.ExpiresAt)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestLFGRepository_Request(t *testing.T) {
+	s := teststore.New()
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	p := model.TestLFGPost(t, u1.ID, g.ID)
+	s.LFG().Create(context.Background(), p)
+
+	assert.EqualError(t, s.LFG().Request(context.Background(), &model.LFGRequest{PostID: p.ID, UserID: u1.ID}), model.ErrOwnLFGPost.Error())
+	assert.NoError(t, s.LFG().Request(context.Background(), &model.LFGRequest{PostID: p.ID, UserID: u2.ID, Message: "Count me in"}))
+	assert.EqualError(t, s.LFG().Request(context.Background(), &model.LFGRequest{PostID: p.ID, UserID: u2.ID}), store.ErrRecordExists.Error())
+
+	requests, err := s.LFG().Requests(context.Background(), p.ID)
+	assert.NoError(t, err)
+	assert.Len(t, requests, 1)
+
+	s.LFG().Close(context.Background(), p.ID)
+	assert.EqualError(t, s.LFG().Request(context.Background(), &model.LFGRequest{PostID: p.ID, UserID: u2.ID}), model.ErrLFGPostClosed.Error())
+}
@@ -0,0 +1,235 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// libraryKey object. Library entries are keyed by user and game.
+type libraryKey struct {
+	userID int
+	gameID int
+}
+
+// LibraryRepository object for testing only
+type LibraryRepository struct {
+	store      *Store
+	entries    map[libraryKey]*model.LibraryEntry
+	visibility map[int]string
+}
+
+// Add func. Writing imported library entry in the map of test entries.
+// For additional information check libraryrepository.go documentation
+// in sqlstore dir.
+func (r *LibraryRepository) Add(ctx context.Context, e *model.LibraryEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	return r.add(ctx, r.entries, e)
+}
+
+// Update func. Rewriting status, hours played and notes of the
+// library entry with the same user id and game id.
+func (r *LibraryRepository) Update(ctx context.Context, e *model.LibraryEntry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	old, ok := r.entries[libraryKey{e.UserID, e.GameID}]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	e.AddedAt = old.AddedAt
+	r.entries[libraryKey{e.UserID, e.GameID}] = clone(e).(*model.LibraryEntry)
+
+	return nil
+}
+
+// Remove func. Deleting library entry with the right user id and game id.
+func (r *LibraryRepository) Remove(ctx context.Context, userID, gameID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	return r.remove(r.entries, userID, gameID)
+}
+
+// Find func. Finding library entry with the right user id and game id.
+func (r *LibraryRepository) Find(ctx context.Context, userID, gameID int) (*model.LibraryEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	e, ok := r.entries[libraryKey{userID, gameID}]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return clone(e).(*model.LibraryEntry), nil
+}
+
+// FindByUser func. Finding all library entries of the user. If status
+// isn't empty only entries with this status are returned.
+func (r *LibraryRepository) FindByUser(ctx context.Context, userID int, status string) ([]*model.LibraryEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	entries := []*model.LibraryEntry{}
+	for k, e := range r.entries {
+		if k.userID == userID && (status == "" || e.Status == status) {
+			entries = append(entries, e)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].AddedAt.Equal(entries[j].AddedAt) {
+			return entries[i].AddedAt.After(entries[j].AddedAt)
+		}
+		return entries[i].GameID < entries[j].GameID
+	})
+
+	return clone(entries).([]*model.LibraryEntry), nil
+}
+
+// Bulk func. Adding and removing several library entries of the user.
+// Changes are applied to a copy of the entries map, which replaces the
+// original one only if every change succeeded.
+func (r *LibraryRepository) Bulk(ctx context.Context, userID int, add []*model.LibraryEntry, remove []int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	entries := make(map[libraryKey]*model.LibraryEntry, len(r.entries))
+	for k, e := range r.entries {
+		entries[k] = e
+	}
+
+	for _, e := range add {
+		e.UserID = userID
+		if err := r.add(ctx, entries, e); err != nil {
+			return err
+		}
+	}
+
+	for _, gameID := range remove {
+		if err := r.remove(entries, userID, gameID); err != nil {
+			return err
+		}
+	}
+
+	r.entries = entries
+
+	return nil
+}
+
+// Visibility func. Returns library visibility setting of the user.
+// Libraries are public until the user changes the setting.
+func (r *LibraryRepository) Visibility(ctx context.Context, userID int) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	visibility, ok := r.visibility[userID]
+	if !ok {
+		return model.LibraryVisibilityPublic, nil
+	}
+
+	return visibility, nil
+}
+
+// SetVisibility func. Writing library visibility setting of the user.
+func (r *LibraryRepository) SetVisibility(ctx context.Context, userID int, visibility string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := model.ValidateLibraryVisibility(visibility); err != nil {
+		return err
+	}
+
+	r.visibility[userID] = visibility
+
+	return nil
+}
+
+// add func. Validating and writing library entry in entries. Checks
+// the same constraints as library_entries table does.
+func (r *LibraryRepository) add(ctx context.Context, entries map[libraryKey]*model.LibraryEntry, e *model.LibraryEntry) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := r.store.User().Find(ctx, e.UserID); err != nil {
+		return err
+	}
+
+	if _, err := r.store.Game().Find(ctx, e.GameID); err != nil {
+		return err
+	}
+
+	k := libraryKey{e.UserID, e.GameID}
+	if _, ok := entries[k]; ok {
+		return store.ErrRecordExists
+	}
+
+	e.BeforeCreate()
+	entries[k] = clone(e).(*model.LibraryEntry)
+
+	return nil
+}
+
+// remove func. Deleting library entry from entries.
+func (r *LibraryRepository) remove(entries map[libraryKey]*model.LibraryEntry, userID, gameID int) error {
+	k := libraryKey{userID, gameID}
+	if _, ok := entries[k]; !ok {
+		return store.ErrRecordNotFound
+	}
+
+	delete(entries, k)
+
+	return nil
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *LibraryRepository) copyFor(s *Store) *LibraryRepository {
+	return &LibraryRepository{
+		store:      s,
+		entries:    clone(r.entries).(map[libraryKey]*model.LibraryEntry),
+		visibility: clone(r.visibility).(map[int]string),
+	}
+}
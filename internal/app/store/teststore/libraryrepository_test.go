@@ -0,0 +1,106 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLibraryRepository_Add(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	assert.NoError(t, s.Library().Add(context.Background(), model.TestLibraryEntry(t, u.ID, g.ID)))
+	assert.EqualError(t, s.Library().Add(context.Background(), model.TestLibraryEntry(t, u.ID, g.ID)), store.ErrRecordExists.Error())
+	assert.EqualError(t, s.Library().Add(context.Background(), model.TestLibraryEntry(t, u.ID, g.ID+1)), store.ErrRecordNotFound.Error())
+}
+
+func TestLibraryRepository_Update(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	e := model.TestLibraryEntry(t, u.ID, g.ID)
+	assert.EqualError(t, s.Library().Update(context.Background(), e), store.ErrRecordNotFound.Error())
+
+	s.Library().Add(context.Background(), e)
+	e2 := model.TestLibraryEntry(t, u.ID, g.ID)
+	e2.Status = model.LibraryStatusFinished
+	e2.HoursPlayed = 42
+	assert.NoError(t, s.Library().Update(context.Background(), e2))
+
+	e3, err := s.Library().Find(context.Background(), u.ID, g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LibraryStatusFinished, e3.Status)
+	assert.Equal(t, e.AddedAt, e3.AddedAt)
+}
+
+func TestLibraryRepository_FindByUser(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g1 := model.TestGame(t)
+	s.Game().Create(context.Background(), g1)
+	g2 := model.TestGame(t)
+	s.Game().Create(context.Background(), g2)
+
+	s.Library().Add(context.Background(), model.TestLibraryEntry(t, u.ID, g1.ID))
+	e := model.TestLibraryEntry(t, u.ID, g2.ID)
+	e.Status = model.LibraryStatusWishlist
+	s.Library().Add(context.Background(), e)
+
+	entries, err := s.Library().FindByUser(context.Background(), u.ID, "")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	entries, err = s.Library().FindByUser(context.Background(), u.ID, model.LibraryStatusWishlist)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestLibraryRepository_Bulk(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g1 := model.TestGame(t)
+	s.Game().Create(context.Background(), g1)
+	g2 := model.TestGame(t)
+	s.Game().Create(context.Background(), g2)
+	s.Library().Add(context.Background(), model.TestLibraryEntry(t, u.ID, g1.ID))
+
+	err := s.Library().Bulk(context.Background(), u.ID, []*model.LibraryEntry{
+		model.TestLibraryEntry(t, u.ID, g2.ID),
+	}, []int{g2.ID + 1})
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	_, err = s.Library().Find(context.Background(), u.ID, g2.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	err = s.Library().Bulk(context.Background(), u.ID, []*model.LibraryEntry{
+		model.TestLibraryEntry(t, u.ID, g2.ID),
+	}, []int{g1.ID})
+	assert.NoError(t, err)
+	entries, _ := s.Library().FindByUser(context.Background(), u.ID, "")
+	assert.Len(t, entries, 1)
+	assert.Equal(t, g2.ID, entries[0].GameID)
+}
+
+func TestLibraryRepository_Visibility(t *testing.T) {
+	s := teststore.New()
+	v, err := s.Library().Visibility(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, model.LibraryVisibilityPublic, v)
+
+	assert.Error(t, s.Library().SetVisibility(context.Background(), 1, "secret"))
+	assert.NoError(t, s.Library().SetVisibility(context.Background(), 1, model.LibraryVisibilityPrivate))
+	v, _ = s.Library().Visibility(context.Background(), 1)
+	assert.Equal(t, model.LibraryVisibilityPrivate, v)
+}
@@ -0,0 +1,165 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// MatchRepository object for testing only
+type MatchRepository struct {
+	store   *Store
+	matches map[int]*model.Match
+	lastID  int
+}
+
+// Create func. Writing imported match in the map of test matches.
+func (r *MatchRepository) Create(ctx context.Context, m *model.Match) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := r.store.Game().Find(ctx, m.GameID); err != nil {
+		return err
+	}
+
+	if _, err := r.store.User().Find(ctx, m.ReportedBy); err != nil {
+		return err
+	}
+
+	m.BeforeCreate()
+	r.lastID++
+	m.ID = r.lastID
+	r.matches[m.ID] = clone(m).(*model.Match)
+
+	return nil
+}
+
+// Find func. Finding match with the right (id we need) id.
+func (r *MatchRepository) Find(ctx context.Context, id int) (*model.Match, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	m, ok := r.matches[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return clone(m).(*model.Match), nil
+}
+
+// Update func. Writing status and winner of the match and updating
+// ratings of the players once the result is final. For additional
+// information check matchrepository.go documentation in sqlstore dir.
+func (r *MatchRepository) Update(ctx context.Context, m *model.Match) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	stored, ok := r.matches[m.ID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	if !model.ValidMatchTransition(stored.Status, m.Status) {
+		return model.ErrInvalidMatchTransition
+	}
+
+	if !m.Final() {
+		stored.Winner = m.Winner
+		stored.Status = m.Status
+		return nil
+	}
+
+	if stored.RatedAt != nil {
+		return model.ErrInvalidMatchTransition
+	}
+
+	ratings := r.store.Rating().(*RatingRepository)
+	players := make([]*model.Rating, 0, len(m.SideA)+len(m.SideB))
+	for _, id := range m.Players() {
+		if _, err := r.store.User().Find(ctx, id); err != nil {
+			return err
+		}
+
+		rt, err := ratings.Find(ctx, id, m.GameID, m.Season)
+		if err != nil {
+			rt = model.NewRating(id, m.GameID, m.Season)
+		}
+		players = append(players, rt)
+	}
+
+	model.RateMatch(m, players)
+	for _, rt := range players {
+		ratings.ratings[ratingKey{rt.UserID, rt.GameID, rt.Season}] = rt
+	}
+
+	ratedAt := time.Now().UTC()
+	m.RatedAt = &ratedAt
+	stored.Winner = m.Winner
+	stored.Status = m.Status
+	stored.RatedAt = m.RatedAt
+
+	return nil
+}
+
+// FindByStatus func. Finding matches with the status, oldest first.
+func (r *MatchRepository) FindByStatus(ctx context.Context, status string, q *store.ListQuery) ([]*model.Match, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	matches := []*model.Match{}
+	for _, m := range r.matches {
+		if m.Status == status {
+			matches = append(matches, m)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if !matches[i].CreatedAt.Equal(matches[j].CreatedAt) {
+			return matches[i].CreatedAt.Before(matches[j].CreatedAt)
+		}
+		return matches[i].ID < matches[j].ID
+	})
+
+	if q.Offset >= len(matches) {
+		return []*model.Match{}, nil
+	}
+	matches = matches[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(matches) {
+		matches = matches[:q.Limit]
+	}
+
+	return clone(matches).([]*model.Match), nil
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *MatchRepository) copyFor(s *Store) *MatchRepository {
+	return &MatchRepository{
+		store:   s,
+		matches: clone(r.matches).(map[int]*model.Match),
+		lastID:  r.lastID,
+	}
+}
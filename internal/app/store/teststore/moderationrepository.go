@@ -0,0 +1,199 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// ModerationRepository object for testing only
+type ModerationRepository struct {
+	store          *Store
+	reports        map[int]*model.Report
+	sanctions      map[int]*model.Sanction
+	lastReportID   int
+	lastSanctionID int
+}
+
+// CreateReport func. Writing imported report in the map of test reports.
+func (r *ModerationRepository) CreateReport(ctx context.Context, rep *model.Report) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := rep.Validate(); err != nil {
+		return err
+	}
+
+	for _, id := range []int{rep.ReporterID, rep.TargetUserID} {
+		if id == 0 {
+			continue
+		}
+		if _, err := r.store.User().Find(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	for _, other := range r.reports {
+		if rep.ReporterID != 0 && other.Status == model.ReportOpen && other.ReporterID == rep.ReporterID &&
+			other.TargetType == rep.TargetType && other.TargetID == rep.TargetID {
+			return store.ErrRecordExists
+		}
+	}
+
+	rep.BeforeCreate()
+	r.lastReportID++
+	rep.ID = r.lastReportID
+	r.reports[rep.ID] = clone(rep).(*model.Report)
+
+	return nil
+}
+
+// FindReport func. Finding report with the right (id we need) id
+func (r *ModerationRepository) FindReport(ctx context.Context, id int) (*model.Report, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	rep, ok := r.reports[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return clone(rep).(*model.Report), nil
+}
+
+// FindReports func. Finding the page of reports with the status,
+// oldest first
+func (r *ModerationRepository) FindReports(ctx context.Context, status string, q *store.ListQuery) ([]*model.Report, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	reports := []*model.Report{}
+	for _, rep := range r.reports {
+		if rep.Status == status {
+			reports = append(reports, rep)
+		}
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].ID < reports[j].ID
+	})
+
+	if q.Offset >= len(reports) {
+		return []*model.Report{}, nil
+	}
+	reports = reports[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(reports) {
+		reports = reports[:q.Limit]
+	}
+
+	return clone(reports).([]*model.Report), nil
+}
+
+// Resolve func. Resolving the report with the action taken by the
+// moderator and imposing the sanction, if any. Nothing changes if
+// either of them fails.
+func (r *ModerationRepository) Resolve(ctx context.Context, id int, action, reason string, moderatorID int, s *model.Sanction) (*model.Report, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	rep, ok := r.reports[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	resolved := *rep
+	if err := resolved.Resolve(action, reason, moderatorID, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	if s != nil {
+		if err := s.Validate(); err != nil {
+			return nil, err
+		}
+
+		if _, err := r.store.User().Find(ctx, s.UserID); err != nil {
+			return nil, err
+		}
+
+		s.BeforeCreate()
+		s.ReportID = rep.ID
+		r.lastSanctionID++
+		s.ID = r.lastSanctionID
+		r.sanctions[s.ID] = clone(s).(*model.Sanction)
+	}
+	*rep = resolved
+
+	return clone(rep).(*model.Report), nil
+}
+
+// Sanctions func. Finding sanctions imposed on the user, newest first
+func (r *ModerationRepository) Sanctions(ctx context.Context, userID int) ([]*model.Sanction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	sanctions := []*model.Sanction{}
+	for _, s := range r.sanctions {
+		if s.UserID == userID {
+			sanctions = append(sanctions, s)
+		}
+	}
+
+	sort.Slice(sanctions, func(i, j int) bool {
+		return sanctions[i].ID > sanctions[j].ID
+	})
+
+	return clone(sanctions).([]*model.Sanction), nil
+}
+
+// Lift func. Ending the active sanction with the id at the time
+func (r *ModerationRepository) Lift(ctx context.Context, id int, now time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	s, ok := r.sanctions[id]
+	if !ok || !s.Active(now) {
+		return store.ErrRecordNotFound
+	}
+	s.ExpiresAt = &now
+
+	return nil
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *ModerationRepository) copyFor(s *Store) *ModerationRepository {
+	return &ModerationRepository{
+		store:          s,
+		reports:        clone(r.reports).(map[int]*model.Report),
+		sanctions:      clone(r.sanctions).(map[int]*model.Sanction),
+		lastReportID:   r.lastReportID,
+		lastSanctionID: r.lastSanctionID,
+	}
+}
@@ -0,0 +1,184 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// NotificationRepository object for testing only
+type NotificationRepository struct {
+	store         *Store
+	notifications map[int]*model.Notification
+	preferences   map[int]map[string]bool
+	lastID        int
+}
+
+// Create func. Writing imported notification in the map of test notifications.
+func (r *NotificationRepository) Create(ctx context.Context, n *model.Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := n.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := r.store.User().Find(ctx, n.UserID); err != nil {
+		return err
+	}
+
+	n.BeforeCreate()
+	r.lastID++
+	n.ID = r.lastID
+	r.notifications[n.ID] = clone(n).(*model.Notification)
+
+	return nil
+}
+
+// FindByUser func. Finding the page of notifications of the user older
+// than the notification with beforeID, newest first.
+func (r *NotificationRepository) FindByUser(ctx context.Context, userID, beforeID, limit int) ([]*model.Notification, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	notifications := []*model.Notification{}
+	for _, n := range r.notifications {
+		if n.UserID == userID && (beforeID == 0 || n.ID < beforeID) {
+			notifications = append(notifications, n)
+		}
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].ID > notifications[j].ID
+	})
+
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+	}
+
+	return clone(notifications).([]*model.Notification), nil
+}
+
+// CountUnread func. Counting unread notifications of the user.
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	n := 0
+	for _, notification := range r.notifications {
+		if notification.UserID == userID && !notification.Read {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// MarkRead func. Marking the notification of the user read.
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	n, ok := r.notifications[id]
+	if !ok || n.UserID != userID {
+		return store.ErrRecordNotFound
+	}
+	n.Read = true
+
+	return nil
+}
+
+// MarkAllRead func. Marking every notification of the user read,
+// returns the number of notifications that were unread.
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	count := 0
+	for _, n := range r.notifications {
+		if n.UserID == userID && !n.Read {
+			n.Read = true
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// Preferences func. Finding notification preferences of the user.
+func (r *NotificationRepository) Preferences(ctx context.Context, userID int) (map[string]bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	prefs := make(map[string]bool, len(r.preferences[userID]))
+	for t, enabled := range r.preferences[userID] {
+		prefs[t] = enabled
+	}
+
+	return prefs, nil
+}
+
+// SetPreferences func. Writing the notification preferences over the
+// ones of the user, types missing from prefs are left as they are.
+func (r *NotificationRepository) SetPreferences(ctx context.Context, userID int, prefs map[string]bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := model.ValidateNotificationPreferences(prefs); err != nil {
+		return err
+	}
+
+	if _, err := r.store.User().Find(ctx, userID); err != nil {
+		return err
+	}
+
+	if r.preferences[userID] == nil {
+		r.preferences[userID] = make(map[string]bool)
+	}
+	for t, enabled := range prefs {
+		r.preferences[userID][t] = enabled
+	}
+
+	return nil
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *NotificationRepository) copyFor(s *Store) *NotificationRepository {
+	return &NotificationRepository{
+		store:         s,
+		notifications: clone(r.notifications).(map[int]*model.Notification),
+		preferences:   clone(r.preferences).(map[int]map[string]bool),
+		lastID:        r.lastID,
+	}
+}
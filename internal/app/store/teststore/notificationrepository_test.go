@@ -0,0 +1,84 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationRepository_FindByUser(t *testing.T) {
+	s := teststore.New()
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+
+	assert.EqualError(t, s.Notification().Create(context.Background(), model.TestNotification(t, u2.ID+1)), store.ErrRecordNotFound.Error())
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, s.Notification().Create(context.Background(), model.TestNotification(t, u1.ID)))
+	}
+	other := model.TestNotification(t, u2.ID)
+	s.Notification().Create(context.Background(), other)
+
+	notifications, err := s.Notification().FindByUser(context.Background(), u1.ID, 0, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, notifications, 2) {
+		assert.True(t, notifications[0].ID > notifications[1].ID)
+		assert.False(t, notifications[0].Read)
+	}
+
+	older, err := s.Notification().FindByUser(context.Background(), u1.ID, notifications[1].ID, 2)
+	assert.NoError(t, err)
+	assert.Len(t, older, 1)
+
+	assert.EqualError(t, s.Notification().MarkRead(context.Background(), u1.ID, other.ID), store.ErrRecordNotFound.Error())
+	assert.NoError(t, s.Notification().MarkRead(context.Background(), u1.ID, notifications[0].ID))
+
+	unread, err := s.Notification().CountUnread(context.Background(), u1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, unread)
+
+	n, err := s.Notification().MarkAllRead(context.Background(), u1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	unread, err = s.Notification().CountUnread(context.Background(), u1.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, unread)
+
+	unread, err = s.Notification().CountUnread(context.Background(), u2.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, unread)
+}
+
+func TestNotificationRepository_Preferences(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+
+	prefs, err := s.Notification().Preferences(context.Background(), u.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, prefs)
+
+	assert.Error(t, s.Notification().SetPreferences(context.Background(), u.ID, map[string]bool{"newsletter": false}))
+	assert.NoError(t, s.Notification().SetPreferences(context.Background(), u.ID, map[string]bool{
+		model.NotificationAchievement: false,
+		model.NotificationLobbyInvite: false,
+	}))
+	assert.NoError(t, s.Notification().SetPreferences(context.Background(), u.ID, map[string]bool{
+		model.NotificationLobbyInvite: true,
+	}))
+
+	prefs, err = s.Notification().Preferences(context.Background(), u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{
+		model.NotificationAchievement: false,
+		model.NotificationLobbyInvite: true,
+	}, prefs)
+}
@@ -0,0 +1,107 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// ratingKey object. Every user has one rating per game and season.
+type ratingKey struct {
+	userID int
+	gameID int
+	season string
+}
+
+// RatingRepository object for testing only
+type RatingRepository struct {
+	store   *Store
+	ratings map[ratingKey]*model.Rating
+}
+
+// Find func. Finding user's rating in the game during the season.
+func (r *RatingRepository) Find(ctx context.Context, userID, gameID int, season string) (*model.Rating, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	rt, ok := r.ratings[ratingKey{userID, gameID, season}]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return clone(rt).(*model.Rating), nil
+}
+
+// Leaderboard func. Finding the page of the game leaderboard during the season.
+func (r *RatingRepository) Leaderboard(ctx context.Context, gameID int, season string, q *store.ListQuery) ([]*model.LeaderboardEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	ranked := r.ranked(gameID, season)
+	entries := []*model.LeaderboardEntry{}
+	for i := q.Offset; i < len(ranked) && (q.Limit <= 0 || i < q.Offset+q.Limit); i++ {
+		entries = append(entries, &model.LeaderboardEntry{
+			Rank:   i + 1,
+			Rating: clone(ranked[i]).(*model.Rating),
+		})
+	}
+
+	return entries, nil
+}
+
+// Rank func. Finding user's place in the game leaderboard during the season.
+func (r *RatingRepository) Rank(ctx context.Context, userID, gameID int, season string) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	for i, rt := range r.ranked(gameID, season) {
+		if rt.UserID == userID {
+			return i + 1, nil
+		}
+	}
+
+	return 0, store.ErrRecordNotFound
+}
+
+// ranked func. Returns ratings of the game during the season in
+// leaderboard order
+func (r *RatingRepository) ranked(gameID int, season string) []*model.Rating {
+	ratings := []*model.Rating{}
+	for k, rt := range r.ratings {
+		if k.gameID == gameID && k.season == season {
+			ratings = append(ratings, rt)
+		}
+	}
+
+	sort.Slice(ratings, func(i, j int) bool {
+		if ratings[i].Rating != ratings[j].Rating {
+			return ratings[i].Rating > ratings[j].Rating
+		}
+		return ratings[i].UserID < ratings[j].UserID
+	})
+
+	return ratings
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *RatingRepository) copyFor(s *Store) *RatingRepository {
+	return &RatingRepository{
+		store:   s,
+		ratings: clone(r.ratings).(map[ratingKey]*model.Rating),
+	}
+}
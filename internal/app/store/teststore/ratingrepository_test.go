@@ -0,0 +1,71 @@
+package teststore_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRatingRepository_Leaderboard(t *testing.T) {
+	s := teststore.New()
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "opponent@example.org"
+	s.User().Create(context.Background(), u2)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	m := model.TestMatch(t, g.ID, u1.ID, u2.ID)
+	m.Winner = model.MatchWinnerB
+	s.Match().Create(context.Background(), m)
+	m.Confirm(u2.ID)
+	s.Match().Update(context.Background(), m)
+
+	entries, err := s.Rating().Leaderboard(context.Background(), g.ID, m.Season, &store.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, 1, entries[0].Rank)
+	assert.Equal(t, u2.ID, entries[0].UserID)
+	assert.Equal(t, u1.ID, entries[1].UserID)
+
+	entries, err = s.Rating().Leaderboard(context.Background(), g.ID, m.Season, &store.ListQuery{Limit: 10, Offset: 1})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, 2, entries[0].Rank)
+
+	entries, err = s.Rating().Leaderboard(context.Background(), g.ID, "2000-Q1", &store.ListQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, entries, 0)
+}
+
+func TestRatingRepository_Rank(t *testing.T) {
+	s := teststore.New()
+	u1 := model.TestUser(t)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "opponent@example.org"
+	s.User().Create(context.Background(), u2)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+
+	_, err := s.Rating().Rank(context.Background(), u1.ID, g.ID, model.SeasonOf(time.Now()))
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+
+	m := model.TestMatch(t, g.ID, u1.ID, u2.ID)
+	s.Match().Create(context.Background(), m)
+	m.Confirm(u2.ID)
+	s.Match().Update(context.Background(), m)
+
+	rank, err := s.Rating().Rank(context.Background(), u1.ID, g.ID, m.Season)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, rank)
+	rank, err = s.Rating().Rank(context.Background(), u2.ID, g.ID, m.Season)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, rank)
+}
@@ -0,0 +1,273 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// reviewVoteKey object. Every user has one vote per review.
+type reviewVoteKey struct {
+	reviewID int
+	userID   int
+}
+
+// ReviewRepository object for testing only. Reviews are stored and
+// returned as copies.
+type ReviewRepository struct {
+	store   *Store
+	reviews map[int]*model.Review
+	votes   map[reviewVoteKey]bool
+	lastID  int
+}
+
+// Create func. Writing imported review in the map of test reviews and
+// adding its score to aggregated rating of the game. For additional
+// information check reviewrepository.go documentation in sqlstore dir.
+func (r *ReviewRepository) Create(ctx context.Context, rv *model.Review) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := rv.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := r.store.User().Find(ctx, rv.UserID); err != nil {
+		return err
+	}
+
+	g, ok := r.store.gameRepository.games[rv.GameID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	if r.find(rv.UserID, rv.GameID) != nil {
+		return store.ErrRecordExists
+	}
+
+	rv.BeforeCreate()
+	r.lastID++
+	rv.ID = r.lastID
+	r.reviews[rv.ID] = clone(rv).(*model.Review)
+	g.AddRating(rv.Score)
+
+	return nil
+}
+
+// Update func. Rewriting score and text of the user's review of the
+// game and moving its score in aggregated rating of the game.
+func (r *ReviewRepository) Update(ctx context.Context, rv *model.Review) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := rv.Validate(); err != nil {
+		return err
+	}
+
+	old := r.find(rv.UserID, rv.GameID)
+	if old == nil {
+		return store.ErrRecordNotFound
+	}
+
+	g, ok := r.store.gameRepository.games[rv.GameID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	rv.BeforeUpdate()
+	rv.ID = old.ID
+	rv.HelpfulCount = old.HelpfulCount
+	rv.UnhelpfulCount = old.UnhelpfulCount
+	rv.CreatedAt = old.CreatedAt
+	r.reviews[rv.ID] = clone(rv).(*model.Review)
+	g.RemoveRating(old.Score)
+	g.AddRating(rv.Score)
+
+	return nil
+}
+
+// Delete func. Deleting user's review of the game and removing its
+// score from aggregated rating of the game.
+func (r *ReviewRepository) Delete(ctx context.Context, userID, gameID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	rv := r.find(userID, gameID)
+	if rv == nil {
+		return store.ErrRecordNotFound
+	}
+
+	g, ok := r.store.gameRepository.games[gameID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	delete(r.reviews, rv.ID)
+	for k := range r.votes {
+		if k.reviewID == rv.ID {
+			delete(r.votes, k)
+		}
+	}
+	g.RemoveRating(rv.Score)
+
+	return nil
+}
+
+// Find func. Finding review with the right (id we need) id.
+func (r *ReviewRepository) Find(ctx context.Context, id int) (*model.Review, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	rv, ok := r.reviews[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return clone(rv).(*model.Review), nil
+}
+
+// FindByUserAndGame func. Finding user's review of the game.
+func (r *ReviewRepository) FindByUserAndGame(ctx context.Context, userID, gameID int) (*model.Review, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	rv := r.find(userID, gameID)
+	if rv == nil {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return clone(rv).(*model.Review), nil
+}
+
+// find func. Returns stored review of the user of the game, nil if
+// there's none. Has to be called with the store locked.
+func (r *ReviewRepository) find(userID, gameID int) *model.Review {
+	for _, rv := range r.reviews {
+		if rv.UserID == userID && rv.GameID == gameID {
+			return rv
+		}
+	}
+
+	return nil
+}
+
+// FindByGame func. Finding reviews of the game sorted and paginated
+// according to the list query.
+func (r *ReviewRepository) FindByGame(ctx context.Context, gameID int, q *store.ListQuery) ([]*model.Review, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	reviews := []*model.Review{}
+	for _, rv := range r.reviews {
+		if rv.GameID == gameID {
+			reviews = append(reviews, rv)
+		}
+	}
+
+	sort.Slice(reviews, func(i, j int) bool {
+		a, b := reviews[i], reviews[j]
+		if q.Sort == store.SortHelpful {
+			if sa, sb := a.HelpfulCount-a.UnhelpfulCount, b.HelpfulCount-b.UnhelpfulCount; sa != sb {
+				return sa > sb
+			}
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.After(b.CreatedAt)
+		}
+		return a.ID > b.ID
+	})
+
+	return clone(paginate(reviews, q)).([]*model.Review), nil
+}
+
+// Vote func. Writing user's helpful or unhelpful vote for the review.
+func (r *ReviewRepository) Vote(ctx context.Context, v *model.ReviewVote) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	rv, ok := r.reviews[v.ReviewID]
+	if !ok {
+		return store.ErrRecordNotFound
+	}
+
+	if _, err := r.store.User().Find(ctx, v.UserID); err != nil {
+		return err
+	}
+
+	k := reviewVoteKey{v.ReviewID, v.UserID}
+	helpful, voted := r.votes[k]
+	if voted && helpful == v.Helpful {
+		return nil
+	}
+
+	r.votes[k] = v.Helpful
+	if voted {
+		if helpful {
+			rv.HelpfulCount--
+		} else {
+			rv.UnhelpfulCount--
+		}
+	}
+	if v.Helpful {
+		rv.HelpfulCount++
+	} else {
+		rv.UnhelpfulCount++
+	}
+
+	return nil
+}
+
+// paginate func. Cuts the page described by the list query out of reviews
+func paginate(reviews []*model.Review, q *store.ListQuery) []*model.Review {
+	if q.Offset >= len(reviews) {
+		return []*model.Review{}
+	}
+
+	reviews = reviews[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(reviews) {
+		reviews = reviews[:q.Limit]
+	}
+
+	return reviews
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *ReviewRepository) copyFor(s *Store) *ReviewRepository {
+	return &ReviewRepository{
+		store:   s,
+		reviews: clone(r.reviews).(map[int]*model.Review),
+		votes:   clone(r.votes).(map[reviewVoteKey]bool),
+		lastID:  r.lastID,
+	}
+}
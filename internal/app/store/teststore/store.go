@@ -1,33 +1,344 @@
 package teststore
 
 import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+
 	"github.com/GShamian/tavern-of-games/internal/app/model"
 	"github.com/GShamian/tavern-of-games/internal/app/store"
 )
 
-// Store object for tests only
+// lockKey object. Marks context of repository calls holding the lock
+// of the store.
+type lockKey struct{}
+
+// Store object for tests only. Repository calls lock the whole store,
+// calls they make keep the lock.
 type Store struct {
-	userRepository *UserRepository
+	mu                     sync.Mutex
+	tx                     bool
+	userRepository         *UserRepository
+	gameRepository         *GameRepository
+	libraryRepository      *LibraryRepository
+	reviewRepository       *ReviewRepository
+	friendshipRepository   *FriendshipRepository
+	chatRepository         *ChatRepository
+	matchRepository        *MatchRepository
+	ratingRepository       *RatingRepository
+	tournamentRepository   *TournamentRepository
+	achievementRepository  *AchievementRepository
+	guildRepository        *GuildRepository
+	lfgRepository          *LFGRepository
+	eventRepository        *EventRepository
+	notificationRepository *NotificationRepository
+	activityRepository     *ActivityRepository
+	moderationRepository   *ModerationRepository
 }
 
 // New func. Empty constructor (default constructor) for testing
-// only store entities
+// only store entities. The store is safe for concurrent use.
 func New() *Store {
-	return &Store{}
+	s := &Store{}
+	s.userRepository = &UserRepository{
+		store: s,
+		users: make(map[int]*model.User),
+	}
+	s.gameRepository = &GameRepository{
+		store: s,
+		games: make(map[int]*model.Game),
+	}
+	s.libraryRepository = &LibraryRepository{
+		store:      s,
+		entries:    make(map[libraryKey]*model.LibraryEntry),
+		visibility: make(map[int]string),
+	}
+	s.reviewRepository = &ReviewRepository{
+		store:   s,
+		reviews: make(map[int]*model.Review),
+		votes:   make(map[reviewVoteKey]bool),
+	}
+	s.friendshipRepository = &FriendshipRepository{
+		store:       s,
+		friendships: make(map[friendshipKey]*model.Friendship),
+		blocks:      make(map[friendshipKey]time.Time),
+	}
+	s.chatRepository = &ChatRepository{
+		store:    s,
+		messages: make(map[int]*model.ChatMessage),
+	}
+	s.matchRepository = &MatchRepository{
+		store:   s,
+		matches: make(map[int]*model.Match),
+	}
+	s.ratingRepository = &RatingRepository{
+		store:   s,
+		ratings: make(map[ratingKey]*model.Rating),
+	}
+	s.tournamentRepository = &TournamentRepository{
+		store:        s,
+		tournaments:  make(map[int]*model.Tournament),
+		participants: make(map[int][]*model.TournamentParticipant),
+	}
+	s.achievementRepository = &AchievementRepository{
+		store:     s,
+		events:    make(map[int]*model.UserEvent),
+		progress:  make(map[achievementKey]*model.AchievementProgress),
+		evaluated: make(map[string]string),
+	}
+	s.guildRepository = &GuildRepository{
+		store:   s,
+		guilds:  make(map[int]*model.Guild),
+		members: make(map[int]*model.GuildMember),
+		invites: make(map[guildInviteKey]*model.GuildInvite),
+	}
+	s.lfgRepository = &LFGRepository{
+		store:    s,
+		posts:    make(map[int]*model.LFGPost),
+		requests: make(map[int][]*model.LFGRequest),
+	}
+	s.eventRepository = &EventRepository{
+		store:  s,
+		events: make(map[int]*model.Event),
+		rsvps:  make(map[int][]*model.EventRSVP),
+		tokens: make(map[int]*model.CalendarToken),
+	}
+	s.notificationRepository = &NotificationRepository{
+		store:         s,
+		notifications: make(map[int]*model.Notification),
+		preferences:   make(map[int]map[string]bool),
+	}
+	s.activityRepository = &ActivityRepository{
+		store:      s,
+		activities: make(map[int]*model.Activity),
+	}
+	s.moderationRepository = &ModerationRepository{
+		store:     s,
+		reports:   make(map[int]*model.Report),
+		sanctions: make(map[int]*model.Sanction),
+	}
+
+	return s
 }
 
-// User func. If userrepository is nil assigns it with
-// pointer on UserRepository which is initialised
-// with calling store and map of test users.
+// User func. Returns UserRepository of the store
 func (s *Store) User() store.UserRepository {
-	if s.userRepository != nil {
-		return s.userRepository
+	return s.userRepository
+}
+
+// Game func. Returns GameRepository of the store
+func (s *Store) Game() store.GameRepository {
+	return s.gameRepository
+}
+
+// Library func. Returns LibraryRepository of the store
+func (s *Store) Library() store.LibraryRepository {
+	return s.libraryRepository
+}
+
+// Review func. Returns ReviewRepository of the store
+func (s *Store) Review() store.ReviewRepository {
+	return s.reviewRepository
+}
+
+// Friendship func. Returns FriendshipRepository of the store
+func (s *Store) Friendship() store.FriendshipRepository {
+	return s.friendshipRepository
+}
+
+// Chat func. Returns ChatRepository of the store
+func (s *Store) Chat() store.ChatRepository {
+	return s.chatRepository
+}
+
+// Match func. Returns MatchRepository of the store
+func (s *Store) Match() store.MatchRepository {
+	return s.matchRepository
+}
+
+// Rating func. Returns RatingRepository of the store
+func (s *Store) Rating() store.RatingRepository {
+	return s.ratingRepository
+}
+
+// Tournament func. Returns TournamentRepository of the store
+func (s *Store) Tournament() store.TournamentRepository {
+	return s.tournamentRepository
+}
+
+// Achievement func. Returns AchievementRepository of the store
+func (s *Store) Achievement() store.AchievementRepository {
+	return s.achievementRepository
+}
+
+// Guild func. Returns GuildRepository of the store
+func (s *Store) Guild() store.GuildRepository {
+	return s.guildRepository
+}
+
+// LFG func. Returns LFGRepository of the store
+func (s *Store) LFG() store.LFGRepository {
+	return s.lfgRepository
+}
+
+// Event func. Returns EventRepository of the store
+func (s *Store) Event() store.EventRepository {
+	return s.eventRepository
+}
+
+// Notification func. Returns NotificationRepository of the store
+func (s *Store) Notification() store.NotificationRepository {
+	return s.notificationRepository
+}
+
+// Activity func. Returns ActivityRepository of the store
+func (s *Store) Activity() store.ActivityRepository {
+	return s.activityRepository
+}
+
+// Moderation func. Returns ModerationRepository of the store
+func (s *Store) Moderation() store.ModerationRepository {
+	return s.moderationRepository
+}
+
+// WithTx func. Runs fn with the copy of the store, changes fn makes are
+// kept if it succeeds and the context isn't done once it returns. The
+// store is locked until fn returns, so fn must use the store it gets.
+// WithTx inside of fn joins the transaction.
+func (s *Store) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	s.userRepository = &UserRepository{
-		store: s,
-		users: make(map[int]*model.User),
+	if s.tx {
+		return fn(s)
 	}
 
-	return s.userRepository
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := s.copy()
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.adopt(tx)
+
+	return nil
+}
+
+// lock func. Locks the store for the repository call with the context,
+// unless it's made by another call holding the lock. Returns context
+// for calls it makes and the func unlocking the store. Copies of the
+// store for transactions aren't locked: like DB transactions, they're
+// used by one goroutine at a time.
+func (s *Store) lock(ctx context.Context) (context.Context, func()) {
+	if s.tx || ctx.Value(lockKey{}) == s {
+		return ctx, func() {}
+	}
+
+	s.mu.Lock()
+
+	return context.WithValue(ctx, lockKey{}, s), s.mu.Unlock
+}
+
+// copy func. Returns copy of the store for a transaction
+func (s *Store) copy() *Store {
+	tx := &Store{tx: true}
+	tx.userRepository = s.userRepository.copyFor(tx)
+	tx.gameRepository = s.gameRepository.copyFor(tx)
+	tx.libraryRepository = s.libraryRepository.copyFor(tx)
+	tx.reviewRepository = s.reviewRepository.copyFor(tx)
+	tx.friendshipRepository = s.friendshipRepository.copyFor(tx)
+	tx.chatRepository = s.chatRepository.copyFor(tx)
+	tx.matchRepository = s.matchRepository.copyFor(tx)
+	tx.ratingRepository = s.ratingRepository.copyFor(tx)
+	tx.tournamentRepository = s.tournamentRepository.copyFor(tx)
+	tx.achievementRepository = s.achievementRepository.copyFor(tx)
+	tx.guildRepository = s.guildRepository.copyFor(tx)
+	tx.lfgRepository = s.lfgRepository.copyFor(tx)
+	tx.eventRepository = s.eventRepository.copyFor(tx)
+	tx.notificationRepository = s.notificationRepository.copyFor(tx)
+	tx.activityRepository = s.activityRepository.copyFor(tx)
+	tx.moderationRepository = s.moderationRepository.copyFor(tx)
+
+	return tx
+}
+
+// adopt func. Replaces state of every repository with the state of
+// the repository of the transaction
+func (s *Store) adopt(tx *Store) {
+	*s.userRepository = *tx.userRepository.copyFor(s)
+	*s.gameRepository = *tx.gameRepository.copyFor(s)
+	*s.libraryRepository = *tx.libraryRepository.copyFor(s)
+	*s.reviewRepository = *tx.reviewRepository.copyFor(s)
+	*s.friendshipRepository = *tx.friendshipRepository.copyFor(s)
+	*s.chatRepository = *tx.chatRepository.copyFor(s)
+	*s.matchRepository = *tx.matchRepository.copyFor(s)
+	*s.ratingRepository = *tx.ratingRepository.copyFor(s)
+	*s.tournamentRepository = *tx.tournamentRepository.copyFor(s)
+	*s.achievementRepository = *tx.achievementRepository.copyFor(s)
+	*s.guildRepository = *tx.guildRepository.copyFor(s)
+	*s.lfgRepository = *tx.lfgRepository.copyFor(s)
+	*s.eventRepository = *tx.eventRepository.copyFor(s)
+	*s.notificationRepository = *tx.notificationRepository.copyFor(s)
+	*s.activityRepository = *tx.activityRepository.copyFor(s)
+	*s.moderationRepository = *tx.moderationRepository.copyFor(s)
+}
+
+// clone func. Returns deep copy of the imported map, slice or model of
+// a repository: maps, slices and pointers it holds are copied as well,
+// so changing the copy doesn't change the original and vice versa.
+func clone(v interface{}) interface{} {
+	return cloneValue(reflect.ValueOf(v)).Interface()
+}
+
+// cloneValue func. Copies maps, slices, values pointers point to and
+// exported fields of structs. Unexported fields, e.g. ones of time.Time,
+// are copied as they are.
+func cloneValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+		}
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(cloneValue(v.Index(i)))
+		}
+		return c
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(cloneValue(v.Elem()))
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < c.NumField(); i++ {
+			if f := c.Field(i); f.CanSet() {
+				f.Set(cloneValue(f))
+			}
+		}
+		return c
+	}
+
+	return v
 }
@@ -0,0 +1,113 @@
+package teststore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_Context(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	assert.NoError(t, s.User().Create(context.Background(), u))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.User().Find(ctx, u.ID)
+	assert.EqualError(t, err, context.Canceled.Error())
+	assert.EqualError(t, s.Game().Create(ctx, model.TestGame(t)), context.Canceled.Error())
+	_, err = s.Game().Find(context.Background(), 1)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
+
+func TestStore_WithTx(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+	tr := model.TestTournament(t, g.ID, u.ID)
+	s.Tournament().Create(context.Background(), tr)
+
+	errFailed := errors.New("failed")
+	u2 := model.TestUser(t)
+	u2.Email = "other@example.org"
+	err := s.WithTx(context.Background(), func(tx store.Store) error {
+		if err := tx.User().Create(context.Background(), u2); err != nil {
+			return err
+		}
+		if _, err := tx.Tournament().Update(context.Background(), tr.ID, func(tr *model.Tournament) error {
+			tr.Name = "Renamed cup"
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		return errFailed
+	})
+	assert.EqualError(t, err, errFailed.Error())
+	_, err = s.User().Find(context.Background(), u2.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	found, err := s.Tournament().Find(context.Background(), tr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Weekly cup", found.Name)
+
+	err = s.WithTx(context.Background(), func(tx store.Store) error {
+		if err := tx.User().Create(context.Background(), u2); err != nil {
+			return err
+		}
+
+		return tx.WithTx(context.Background(), func(tx store.Store) error {
+			return tx.Game().Create(context.Background(), model.TestGame(t))
+		})
+	})
+	assert.NoError(t, err)
+	_, err = s.User().Find(context.Background(), u2.ID)
+	assert.NoError(t, err)
+	_, err = s.Game().Find(context.Background(), g.ID+1)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = s.WithTx(ctx, func(tx store.Store) error {
+		cancel()
+		return tx.Chat().Create(context.Background(), model.TestChatMessage(t, model.ChatRoomHall, u.ID))
+	})
+	assert.EqualError(t, err, context.Canceled.Error())
+	messages, err := s.Chat().FindByRoom(context.Background(), model.ChatRoomHall, 0, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, messages)
+}
+
+func TestStore_WithTx_RollsBackModels(t *testing.T) {
+	s := teststore.New()
+	u := model.TestUser(t)
+	s.User().Create(context.Background(), u)
+	g := model.TestGame(t)
+	s.Game().Create(context.Background(), g)
+	histogram := append([]int{}, g.RatingHistogram...)
+
+	// Slices of models changed by the rolled back transaction stay as
+	// they were
+	errFailed := errors.New("failed")
+	err := s.WithTx(context.Background(), func(tx store.Store) error {
+		if err := tx.Review().Create(context.Background(), model.TestReview(t, u.ID, g.ID)); err != nil {
+			return err
+		}
+
+		return errFailed
+	})
+	assert.EqualError(t, err, errFailed.Error())
+
+	after, err := s.Game().Find(context.Background(), g.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, histogram, after.RatingHistogram)
+	assert.Equal(t, 0, after.RatingCount)
+	_, err = s.Review().FindByUserAndGame(context.Background(), u.ID, g.ID)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+}
@@ -0,0 +1,216 @@
+package teststore
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// TournamentRepository object for testing only
+type TournamentRepository struct {
+	store        *Store
+	tournaments  map[int]*model.Tournament
+	participants map[int][]*model.TournamentParticipant
+	lastID       int
+}
+
+// Create func. Writing imported tournament in the map of test tournaments.
+func (r *TournamentRepository) Create(ctx context.Context, t *model.Tournament) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	if _, err := r.store.Game().Find(ctx, t.GameID); err != nil {
+		return err
+	}
+
+	if _, err := r.store.User().Find(ctx, t.OrganizerID); err != nil {
+		return err
+	}
+
+	t.BeforeCreate()
+	r.lastID++
+	t.ID = r.lastID
+	r.tournaments[t.ID] = clone(t).(*model.Tournament)
+
+	return nil
+}
+
+// Find func. Finding tournament with the right (id we need) id.
+func (r *TournamentRepository) Find(ctx context.Context, id int) (*model.Tournament, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	t, ok := r.tournaments[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	return clone(t).(*model.Tournament), nil
+}
+
+// FindByStatus func. Finding tournaments with the status, the ones
+// closing registration first go first.
+func (r *TournamentRepository) FindByStatus(ctx context.Context, status string, q *store.ListQuery) ([]*model.Tournament, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	tournaments := []*model.Tournament{}
+	for _, t := range r.tournaments {
+		if t.Status == status {
+			tournaments = append(tournaments, t)
+		}
+	}
+
+	sort.Slice(tournaments, func(i, j int) bool {
+		a, b := tournaments[i], tournaments[j]
+		if !a.RegistrationClosesAt.Equal(b.RegistrationClosesAt) {
+			return a.RegistrationClosesAt.Before(b.RegistrationClosesAt)
+		}
+		return a.ID < b.ID
+	})
+
+	if q.Offset >= len(tournaments) {
+		return []*model.Tournament{}, nil
+	}
+	tournaments = tournaments[q.Offset:]
+	if q.Limit > 0 && q.Limit < len(tournaments) {
+		tournaments = tournaments[:q.Limit]
+	}
+
+	return clone(tournaments).([]*model.Tournament), nil
+}
+
+// Update func. Changing a copy of the tournament with fn and storing
+// the copy only if fn succeeds, like the transaction of sqlstore does.
+func (r *TournamentRepository) Update(ctx context.Context, id int, fn func(*model.Tournament) error) (*model.Tournament, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	t, ok := r.tournaments[id]
+	if !ok {
+		return nil, store.ErrRecordNotFound
+	}
+
+	cp := clone(t).(*model.Tournament)
+	if err := fn(cp); err != nil {
+		return nil, err
+	}
+	r.tournaments[id] = cp
+
+	return clone(cp).(*model.Tournament), nil
+}
+
+// Register func. Writing the user as a participant of the tournament.
+func (r *TournamentRepository) Register(ctx context.Context, tournamentID, userID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	t, err := r.Find(ctx, tournamentID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if err := t.CanRegister(now, len(r.participants[tournamentID])); err != nil {
+		return err
+	}
+
+	for _, p := range r.participants[tournamentID] {
+		if p.UserID == userID {
+			return store.ErrRecordExists
+		}
+	}
+
+	if _, err := r.store.User().Find(ctx, userID); err != nil {
+		return err
+	}
+
+	r.participants[tournamentID] = append(r.participants[tournamentID], &model.TournamentParticipant{
+		TournamentID: tournamentID,
+		UserID:       userID,
+		RegisteredAt: now,
+	})
+
+	return nil
+}
+
+// Unregister func. Deleting the user from participants of the tournament
+// while the registration isn't over.
+func (r *TournamentRepository) Unregister(ctx context.Context, tournamentID, userID int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ctx, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	t, err := r.Find(ctx, tournamentID)
+	if err != nil {
+		return err
+	}
+
+	if t.Status != model.TournamentRegistration {
+		return model.ErrRegistrationClosed
+	}
+
+	participants := r.participants[tournamentID]
+	for i, p := range participants {
+		if p.UserID == userID {
+			r.participants[tournamentID] = append(participants[:i:i], participants[i+1:]...)
+			return nil
+		}
+	}
+
+	return store.ErrRecordNotFound
+}
+
+// Participants func. Finding participants of the tournament in order
+// of registration.
+func (r *TournamentRepository) Participants(ctx context.Context, tournamentID int) ([]*model.TournamentParticipant, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	return clone(r.participants[tournamentID]).([]*model.TournamentParticipant), nil
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *TournamentRepository) copyFor(s *Store) *TournamentRepository {
+	return &TournamentRepository{
+		store:        s,
+		tournaments:  clone(r.tournaments).(map[int]*model.Tournament),
+		participants: clone(r.participants).(map[int][]*model.TournamentParticipant),
+		lastID:       r.lastID,
+	}
+}
@@ -1,21 +1,33 @@
 package teststore
 
 import (
-	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"context"
+	"sort"
+	"time"
 
 	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
 )
 
-// UserRepository object for testing only
+// UserRepository object for testing only. Users are stored and returned
+// as copies, like rows of users table, and their emails are unique.
 type UserRepository struct {
-	store *Store
-	users map[int]*model.User
+	store  *Store
+	users  map[int]*model.User
+	lastID int
 }
 
 // Create func. Writing an email and encrypted password in the fields
 // in DB that match to imported User. For additional information
 // check userrepository.go documentation in sqlstore dir.
-func (r *UserRepository) Create(u *model.User) error {
+func (r *UserRepository) Create(ctx context.Context, u *model.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
 	if err := u.Validate(); err != nil {
 		return err
 	}
@@ -24,18 +36,35 @@ func (r *UserRepository) Create(u *model.User) error {
 		return err
 	}
 
-	u.ID = len(r.users) + 1
-	r.users[u.ID] = u
+	for _, other := range r.users {
+		if other.Email == u.Email {
+			return store.ErrRecordExists
+		}
+	}
+
+	r.lastID++
+	u.ID = r.lastID
+	stored := *u
+	stored.Password = ""
+	r.users[u.ID] = &stored
 
 	return nil
 }
 
 // FindByEmail func. Finding user with the right (email we need) email.
 // Function for testing only purposes.
-func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
 	for _, u := range r.users {
 		if u.Email == email {
-			return u, nil
+			found := *u
+			return &found, nil
 		}
 	}
 
@@ -44,11 +73,50 @@ func (r *UserRepository) FindByEmail(email string) (*model.User, error) {
 
 // Find func. Finding user with the right (id we need) id.
 // Function for testing only purposes.
-func (r *UserRepository) Find(id int) (*model.User, error) {
+func (r *UserRepository) Find(ctx context.Context, id int) (*model.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
 	u, ok := r.users[id]
 	if !ok {
 		return nil, store.ErrRecordNotFound
 	}
+	found := *u
+
+	return &found, nil
+}
+
+// FindRegisteredBefore func. Finding ids of users registered before the time.
+// Function for testing only purposes.
+func (r *UserRepository) FindRegisteredBefore(ctx context.Context, t time.Time) ([]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	_, unlock := r.store.lock(ctx)
+	defer unlock()
+
+	ids := []int{}
+	for _, u := range r.users {
+		if u.CreatedAt.Before(t) {
+			ids = append(ids, u.ID)
+		}
+	}
+	sort.Ints(ids)
 
-	return u, nil
+	return ids, nil
+}
+
+// copyFor func. Returns copy of the repository for the store, changes
+// of the copy don't change the repository
+func (r *UserRepository) copyFor(s *Store) *UserRepository {
+	return &UserRepository{
+		store:  s,
+		users:  clone(r.users).(map[int]*model.User),
+		lastID: r.lastID,
+	}
 }
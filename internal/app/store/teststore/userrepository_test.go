@@ -1,7 +1,9 @@
 package teststore_test
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/GShamian/tavern-of-games/internal/app/model"
 	"github.com/GShamian/tavern-of-games/internal/app/store"
@@ -12,15 +14,15 @@ import (
 func TestUserRepository_Create(t *testing.T) {
 	s := teststore.New()
 	u := model.TestUser(t)
-	assert.NoError(t, s.User().Create(u))
+	assert.NoError(t, s.User().Create(context.Background(), u))
 	assert.NotNil(t, u.ID)
 }
 
 func TestUserRepository_Find(t *testing.T) {
 	s := teststore.New()
 	u1 := model.TestUser(t)
-	s.User().Create(u1)
-	u2, err := s.User().Find(u1.ID)
+	s.User().Create(context.Background(), u1)
+	u2, err := s.User().Find(context.Background(), u1.ID)
 	assert.NoError(t, err)
 	assert.NotNil(t, u2)
 }
@@ -28,11 +30,25 @@ func TestUserRepository_Find(t *testing.T) {
 func TestUserRepository_FindByEmail(t *testing.T) {
 	s := teststore.New()
 	u1 := model.TestUser(t)
-	_, err := s.User().FindByEmail(u1.Email)
+	_, err := s.User().FindByEmail(context.Background(), u1.Email)
 	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
 
-	s.User().Create(u1)
-	u2, err := s.User().FindByEmail(u1.Email)
+	s.User().Create(context.Background(), u1)
+	u2, err := s.User().FindByEmail(context.Background(), u1.Email)
 	assert.NoError(t, err)
 	assert.NotNil(t, u2)
 }
+
+func TestUserRepository_FindRegisteredBefore(t *testing.T) {
+	s := teststore.New()
+	u1 := model.TestUser(t)
+	u1.CreatedAt = time.Now().Add(-48 * time.Hour)
+	s.User().Create(context.Background(), u1)
+	u2 := model.TestUser(t)
+	u2.Email = "user2@example.org"
+	s.User().Create(context.Background(), u2)
+
+	ids, err := s.User().FindRegisteredBefore(context.Background(), time.Now().Add(-24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{u1.ID}, ids)
+}
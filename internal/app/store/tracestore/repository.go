@@ -0,0 +1,1227 @@
+package tracestore
+
+import (
+	"context"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+)
+
+// UserRepository object. Traces calls of the decorated repository
+type UserRepository struct {
+	store *Store
+	inner store.UserRepository
+}
+
+// Create func. Traces UserRepository.Create
+func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
+	ctx, span := r.store.start(ctx, "UserRepository.Create")
+	defer span.End()
+
+	err := r.inner.Create(ctx, user)
+	span.SetError(err)
+
+	return err
+}
+
+// Find func. Traces UserRepository.Find
+func (r *UserRepository) Find(ctx context.Context, id int) (*model.User, error) {
+	ctx, span := r.store.start(ctx, "UserRepository.Find")
+	defer span.End()
+
+	res, err := r.inner.Find(ctx, id)
+	span.SetError(err)
+
+	return res, err
+}
+
+// FindByEmail func. Traces UserRepository.FindByEmail
+func (r *UserRepository) FindByEmail(ctx context.Context, value string) (*model.User, error) {
+	ctx, span := r.store.start(ctx, "UserRepository.FindByEmail")
+	defer span.End()
+
+	res, err := r.inner.FindByEmail(ctx, value)
+	span.SetError(err)
+
+	return res, err
+}
+
+// FindRegisteredBefore func. Traces UserRepository.FindRegisteredBefore
+func (r *UserRepository) FindRegisteredBefore(ctx context.Context, t time.Time) ([]int, error) {
+	ctx, span := r.store.start(ctx, "UserRepository.FindRegisteredBefore")
+	defer span.End()
+
+	res, err := r.inner.FindRegisteredBefore(ctx, t)
+	span.SetError(err)
+
+	return res, err
+}
+
+// GameRepository object. Traces calls of the decorated repository
+type GameRepository struct {
+	store *Store
+	inner store.GameRepository
+}
+
+// Create func. Traces GameRepository.Create
+func (r *GameRepository) Create(ctx context.Context, game *model.Game) error {
+	ctx, span := r.store.start(ctx, "GameRepository.Create")
+	defer span.End()
+
+	err := r.inner.Create(ctx, game)
+	span.SetError(err)
+
+	return err
+}
+
+// Find func. Traces GameRepository.Find
+func (r *GameRepository) Find(ctx context.Context, id int) (*model.Game, error) {
+	ctx, span := r.store.start(ctx, "GameRepository.Find")
+	defer span.End()
+
+	res, err := r.inner.Find(ctx, id)
+	span.SetError(err)
+
+	return res, err
+}
+
+// LibraryRepository object. Traces calls of the decorated repository
+type LibraryRepository struct {
+	store *Store
+	inner store.LibraryRepository
+}
+
+// Add func. Traces LibraryRepository.Add
+func (r *LibraryRepository) Add(ctx context.Context, libraryEntry *model.LibraryEntry) error {
+	ctx, span := r.store.start(ctx, "LibraryRepository.Add")
+	defer span.End()
+
+	err := r.inner.Add(ctx, libraryEntry)
+	span.SetError(err)
+
+	return err
+}
+
+// Update func. Traces LibraryRepository.Update
+func (r *LibraryRepository) Update(ctx context.Context, libraryEntry *model.LibraryEntry) error {
+	ctx, span := r.store.start(ctx, "LibraryRepository.Update")
+	defer span.End()
+
+	err := r.inner.Update(ctx, libraryEntry)
+	span.SetError(err)
+
+	return err
+}
+
+// Remove func. Traces LibraryRepository.Remove
+func (r *LibraryRepository) Remove(ctx context.Context, userID, gameID int) error {
+	ctx, span := r.store.start(ctx, "LibraryRepository.Remove")
+	defer span.End()
+
+	err := r.inner.Remove(ctx, userID, gameID)
+	span.SetError(err)
+
+	return err
+}
+
+// Find func. Traces LibraryRepository.Find
+func (r *LibraryRepository) Find(ctx context.Context, userID, gameID int) (*model.LibraryEntry, error) {
+	ctx, span := r.store.start(ctx, "LibraryRepository.Find")
+	defer span.End()
+
+	res, err := r.inner.Find(ctx, userID, gameID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// FindByUser func. Traces LibraryRepository.FindByUser
+func (r *LibraryRepository) FindByUser(ctx context.Context, userID int, status string) ([]*model.LibraryEntry, error) {
+	ctx, span := r.store.start(ctx, "LibraryRepository.FindByUser")
+	defer span.End()
+
+	res, err := r.inner.FindByUser(ctx, userID, status)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Bulk func. Traces LibraryRepository.Bulk
+func (r *LibraryRepository) Bulk(ctx context.Context, userID int, add []*model.LibraryEntry, remove []int) error {
+	ctx, span := r.store.start(ctx, "LibraryRepository.Bulk")
+	defer span.End()
+
+	err := r.inner.Bulk(ctx, userID, add, remove)
+	span.SetError(err)
+
+	return err
+}
+
+// Visibility func. Traces LibraryRepository.Visibility
+func (r *LibraryRepository) Visibility(ctx context.Context, userID int) (string, error) {
+	ctx, span := r.store.start(ctx, "LibraryRepository.Visibility")
+	defer span.End()
+
+	res, err := r.inner.Visibility(ctx, userID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// SetVisibility func. Traces LibraryRepository.SetVisibility
+func (r *LibraryRepository) SetVisibility(ctx context.Context, userID int, visibility string) error {
+	ctx, span := r.store.start(ctx, "LibraryRepository.SetVisibility")
+	defer span.End()
+
+	err := r.inner.SetVisibility(ctx, userID, visibility)
+	span.SetError(err)
+
+	return err
+}
+
+// ReviewRepository object. Traces calls of the decorated repository
+type ReviewRepository struct {
+	store *Store
+	inner store.ReviewRepository
+}
+
+// Create func. Traces ReviewRepository.Create
+func (r *ReviewRepository) Create(ctx context.Context, review *model.Review) error {
+	ctx, span := r.store.start(ctx, "ReviewRepository.Create")
+	defer span.End()
+
+	err := r.inner.Create(ctx, review)
+	span.SetError(err)
+
+	return err
+}
+
+// Update func. Traces ReviewRepository.Update
+func (r *ReviewRepository) Update(ctx context.Context, review *model.Review) error {
+	ctx, span := r.store.start(ctx, "ReviewRepository.Update")
+	defer span.End()
+
+	err := r.inner.Update(ctx, review)
+	span.SetError(err)
+
+	return err
+}
+
+// Delete func. Traces ReviewRepository.Delete
+func (r *ReviewRepository) Delete(ctx context.Context, userID, gameID int) error {
+	ctx, span := r.store.start(ctx, "ReviewRepository.Delete")
+	defer span.End()
+
+	err := r.inner.Delete(ctx, userID, gameID)
+	span.SetError(err)
+
+	return err
+}
+
+// Find func. Traces ReviewRepository.Find
+func (r *ReviewRepository) Find(ctx context.Context, id int) (*model.Review, error) {
+	ctx, span := r.store.start(ctx, "ReviewRepository.Find")
+	defer span.End()
+
+	res, err := r.inner.Find(ctx, id)
+	span.SetError(err)
+
+	return res, err
+}
+
+// FindByUserAndGame func. Traces ReviewRepository.FindByUserAndGame
+func (r *ReviewRepository) FindByUserAndGame(ctx context.Context, userID, gameID int) (*model.Review, error) {
+	ctx, span := r.store.start(ctx, "ReviewRepository.FindByUserAndGame")
+	defer span.End()
+
+	res, err := r.inner.FindByUserAndGame(ctx, userID, gameID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// FindByGame func. Traces ReviewRepository.FindByGame
+func (r *ReviewRepository) FindByGame(ctx context.Context, gameID int, q *store.ListQuery) ([]*model.Review, error) {
+	ctx, span := r.store.start(ctx, "ReviewRepository.FindByGame")
+	defer span.End()
+
+	res, err := r.inner.FindByGame(ctx, gameID, q)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Vote func. Traces ReviewRepository.Vote
+func (r *ReviewRepository) Vote(ctx context.Context, reviewVote *model.ReviewVote) error {
+	ctx, span := r.store.start(ctx, "ReviewRepository.Vote")
+	defer span.End()
+
+	err := r.inner.Vote(ctx, reviewVote)
+	span.SetError(err)
+
+	return err
+}
+
+// FriendshipRepository object. Traces calls of the decorated repository
+type FriendshipRepository struct {
+	store *Store
+	inner store.FriendshipRepository
+}
+
+// Find func. Traces FriendshipRepository.Find
+func (r *FriendshipRepository) Find(ctx context.Context, userID, otherID int) (*model.Friendship, error) {
+	ctx, span := r.store.start(ctx, "FriendshipRepository.Find")
+	defer span.End()
+
+	res, err := r.inner.Find(ctx, userID, otherID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Apply func. Traces FriendshipRepository.Apply
+func (r *FriendshipRepository) Apply(ctx context.Context, actorID, otherID int, action string) (*model.Friendship, error) {
+	ctx, span := r.store.start(ctx, "FriendshipRepository.Apply")
+	defer span.End()
+
+	res, err := r.inner.Apply(ctx, actorID, otherID, action)
+	span.SetError(err)
+
+	return res, err
+}
+
+// FindByUser func. Traces FriendshipRepository.FindByUser
+func (r *FriendshipRepository) FindByUser(ctx context.Context, userID int, status string) ([]*model.Friendship, error) {
+	ctx, span := r.store.start(ctx, "FriendshipRepository.FindByUser")
+	defer span.End()
+
+	res, err := r.inner.FindByUser(ctx, userID, status)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Mutual func. Traces FriendshipRepository.Mutual
+func (r *FriendshipRepository) Mutual(ctx context.Context, userID, otherID int) ([]int, error) {
+	ctx, span := r.store.start(ctx, "FriendshipRepository.Mutual")
+	defer span.End()
+
+	res, err := r.inner.Mutual(ctx, userID, otherID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Block func. Traces FriendshipRepository.Block
+func (r *FriendshipRepository) Block(ctx context.Context, blockerID, blockedID int) error {
+	ctx, span := r.store.start(ctx, "FriendshipRepository.Block")
+	defer span.End()
+
+	err := r.inner.Block(ctx, blockerID, blockedID)
+	span.SetError(err)
+
+	return err
+}
+
+// Unblock func. Traces FriendshipRepository.Unblock
+func (r *FriendshipRepository) Unblock(ctx context.Context, blockerID, blockedID int) error {
+	ctx, span := r.store.start(ctx, "FriendshipRepository.Unblock")
+	defer span.End()
+
+	err := r.inner.Unblock(ctx, blockerID, blockedID)
+	span.SetError(err)
+
+	return err
+}
+
+// Blocked func. Traces FriendshipRepository.Blocked
+func (r *FriendshipRepository) Blocked(ctx context.Context, blockerID int) ([]int, error) {
+	ctx, span := r.store.start(ctx, "FriendshipRepository.Blocked")
+	defer span.End()
+
+	res, err := r.inner.Blocked(ctx, blockerID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// IsBlocked func. Traces FriendshipRepository.IsBlocked
+func (r *FriendshipRepository) IsBlocked(ctx context.Context, userID, otherID int) (bool, error) {
+	ctx, span := r.store.start(ctx, "FriendshipRepository.IsBlocked")
+	defer span.End()
+
+	res, err := r.inner.IsBlocked(ctx, userID, otherID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// ChatRepository object. Traces calls of the decorated repository
+type ChatRepository struct {
+	store *Store
+	inner store.ChatRepository
+}
+
+// Create func. Traces ChatRepository.Create
+func (r *ChatRepository) Create(ctx context.Context, chatMessage *model.ChatMessage) error {
+	ctx, span := r.store.start(ctx, "ChatRepository.Create")
+	defer span.End()
+
+	err := r.inner.Create(ctx, chatMessage)
+	span.SetError(err)
+
+	return err
+}
+
+// Find func. Traces ChatRepository.Find
+func (r *ChatRepository) Find(ctx context.Context, id int) (*model.ChatMessage, error) {
+	ctx, span := r.store.start(ctx, "ChatRepository.Find")
+	defer span.End()
+
+	res, err := r.inner.Find(ctx, id)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Delete func. Traces ChatRepository.Delete
+func (r *ChatRepository) Delete(ctx context.Context, id int) error {
+	ctx, span := r.store.start(ctx, "ChatRepository.Delete")
+	defer span.End()
+
+	err := r.inner.Delete(ctx, id)
+	span.SetError(err)
+
+	return err
+}
+
+// FindByRoom func. Traces ChatRepository.FindByRoom
+func (r *ChatRepository) FindByRoom(ctx context.Context, room string, beforeID, limit int) ([]*model.ChatMessage, error) {
+	ctx, span := r.store.start(ctx, "ChatRepository.FindByRoom")
+	defer span.End()
+
+	res, err := r.inner.FindByRoom(ctx, room, beforeID, limit)
+	span.SetError(err)
+
+	return res, err
+}
+
+// MatchRepository object. Traces calls of the decorated repository
+type MatchRepository struct {
+	store *Store
+	inner store.MatchRepository
+}
+
+// Create func. Traces MatchRepository.Create
+func (r *MatchRepository) Create(ctx context.Context, match *model.Match) error {
+	ctx, span := r.store.start(ctx, "MatchRepository.Create")
+	defer span.End()
+
+	err := r.inner.Create(ctx, match)
+	span.SetError(err)
+
+	return err
+}
+
+// Find func. Traces MatchRepository.Find
+func (r *MatchRepository) Find(ctx context.Context, id int) (*model.Match, error) {
+	ctx, span := r.store.start(ctx, "MatchRepository.Find")
+	defer span.End()
+
+	res, err := r.inner.Find(ctx, id)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Update func. Traces MatchRepository.Update
+func (r *MatchRepository) Update(ctx context.Context, match *model.Match) error {
+	ctx, span := r.store.start(ctx, "MatchRepository.Update")
+	defer span.End()
+
+	err := r.inner.Update(ctx, match)
+	span.SetError(err)
+
+	return err
+}
+
+// FindByStatus func. Traces MatchRepository.FindByStatus
+func (r *MatchRepository) FindByStatus(ctx context.Context, status string, q *store.ListQuery) ([]*model.Match, error) {
+	ctx, span := r.store.start(ctx, "MatchRepository.FindByStatus")
+	defer span.End()
+
+	res, err := r.inner.FindByStatus(ctx, status, q)
+	span.SetError(err)
+
+	return res, err
+}
+
+// RatingRepository object. Traces calls of the decorated repository
+type RatingRepository struct {
+	store *Store
+	inner store.RatingRepository
+}
+
+// Find func. Traces RatingRepository.Find
+func (r *RatingRepository) Find(ctx context.Context, userID, gameID int, season string) (*model.Rating, error) {
+	ctx, span := r.store.start(ctx, "RatingRepository.Find")
+	defer span.End()
+
+	res, err := r.inner.Find(ctx, userID, gameID, season)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Leaderboard func. Traces RatingRepository.Leaderboard
+func (r *RatingRepository) Leaderboard(ctx context.Context, gameID int, season string, q *store.ListQuery) ([]*model.LeaderboardEntry, error) {
+	ctx, span := r.store.start(ctx, "RatingRepository.Leaderboard")
+	defer span.End()
+
+	res, err := r.inner.Leaderboard(ctx, gameID, season, q)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Rank func. Traces RatingRepository.Rank
+func (r *RatingRepository) Rank(ctx context.Context, userID, gameID int, season string) (int, error) {
+	ctx, span := r.store.start(ctx, "RatingRepository.Rank")
+	defer span.End()
+
+	res, err := r.inner.Rank(ctx, userID, gameID, season)
+	span.SetError(err)
+
+	return res, err
+}
+
+// TournamentRepository object. Traces calls of the decorated repository
+type TournamentRepository struct {
+	store *Store
+	inner store.TournamentRepository
+}
+
+// Create func. Traces TournamentRepository.Create
+func (r *TournamentRepository) Create(ctx context.Context, tournament *model.Tournament) error {
+	ctx, span := r.store.start(ctx, "TournamentRepository.Create")
+	defer span.End()
+
+	err := r.inner.Create(ctx, tournament)
+	span.SetError(err)
+
+	return err
+}
+
+// Find func. Traces TournamentRepository.Find
+func (r *TournamentRepository) Find(ctx context.Context, id int) (*model.Tournament, error) {
+	ctx, span := r.store.start(ctx, "TournamentRepository.Find")
+	defer span.End()
+
+	res, err := r.inner.Find(ctx, id)
+	span.SetError(err)
+
+	return res, err
+}
+
+// FindByStatus func. Traces TournamentRepository.FindByStatus
+func (r *TournamentRepository) FindByStatus(ctx context.Context, status string, q *store.ListQuery) ([]*model.Tournament, error) {
+	ctx, span := r.store.start(ctx, "TournamentRepository.FindByStatus")
+	defer span.End()
+
+	res, err := r.inner.FindByStatus(ctx, status, q)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Update func. Traces TournamentRepository.Update
+func (r *TournamentRepository) Update(ctx context.Context, id int, fn func(*model.Tournament) error) (*model.Tournament, error) {
+	ctx, span := r.store.start(ctx, "TournamentRepository.Update")
+	defer span.End()
+
+	res, err := r.inner.Update(ctx, id, fn)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Register func. Traces TournamentRepository.Register
+func (r *TournamentRepository) Register(ctx context.Context, tournamentID, userID int) error {
+	ctx, span := r.store.start(ctx, "TournamentRepository.Register")
+	defer span.End()
+
+	err := r.inner.Register(ctx, tournamentID, userID)
+	span.SetError(err)
+
+	return err
+}
+
+// Unregister func. Traces TournamentRepository.Unregister
+func (r *TournamentRepository) Unregister(ctx context.Context, tournamentID, userID int) error {
+	ctx, span := r.store.start(ctx, "TournamentRepository.Unregister")
+	defer span.End()
+
+	err := r.inner.Unregister(ctx, tournamentID, userID)
+	span.SetError(err)
+
+	return err
+}
+
+// Participants func. Traces TournamentRepository.Participants
+func (r *TournamentRepository) Participants(ctx context.Context, tournamentID int) ([]*model.TournamentParticipant, error) {
+	ctx, span := r.store.start(ctx, "TournamentRepository.Participants")
+	defer span.End()
+
+	res, err := r.inner.Participants(ctx, tournamentID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// AchievementRepository object. Traces calls of the decorated repository
+type AchievementRepository struct {
+	store *Store
+	inner store.AchievementRepository
+}
+
+// CreateEvent func. Traces AchievementRepository.CreateEvent
+func (r *AchievementRepository) CreateEvent(ctx context.Context, userEvent *model.UserEvent) error {
+	ctx, span := r.store.start(ctx, "AchievementRepository.CreateEvent")
+	defer span.End()
+
+	err := r.inner.CreateEvent(ctx, userEvent)
+	span.SetError(err)
+
+	return err
+}
+
+// CountEvents func. Traces AchievementRepository.CountEvents
+func (r *AchievementRepository) CountEvents(ctx context.Context, userID int, eventType string, gameID int) (int, error) {
+	ctx, span := r.store.start(ctx, "AchievementRepository.CountEvents")
+	defer span.End()
+
+	res, err := r.inner.CountEvents(ctx, userID, eventType, gameID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// CountEventsByUser func. Traces AchievementRepository.CountEventsByUser
+func (r *AchievementRepository) CountEventsByUser(ctx context.Context, eventType string, gameID int) (map[int]int, error) {
+	ctx, span := r.store.start(ctx, "AchievementRepository.CountEventsByUser")
+	defer span.End()
+
+	res, err := r.inner.CountEventsByUser(ctx, eventType, gameID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// SaveProgress func. Traces AchievementRepository.SaveProgress
+func (r *AchievementRepository) SaveProgress(ctx context.Context, achievementProgress *model.AchievementProgress) error {
+	ctx, span := r.store.start(ctx, "AchievementRepository.SaveProgress")
+	defer span.End()
+
+	err := r.inner.SaveProgress(ctx, achievementProgress)
+	span.SetError(err)
+
+	return err
+}
+
+// FindProgress func. Traces AchievementRepository.FindProgress
+func (r *AchievementRepository) FindProgress(ctx context.Context, userID int) ([]*model.AchievementProgress, error) {
+	ctx, span := r.store.start(ctx, "AchievementRepository.FindProgress")
+	defer span.End()
+
+	res, err := r.inner.FindProgress(ctx, userID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Evaluated func. Traces AchievementRepository.Evaluated
+func (r *AchievementRepository) Evaluated(ctx context.Context, code string) (string, error) {
+	ctx, span := r.store.start(ctx, "AchievementRepository.Evaluated")
+	defer span.End()
+
+	res, err := r.inner.Evaluated(ctx, code)
+	span.SetError(err)
+
+	return res, err
+}
+
+// SetEvaluated func. Traces AchievementRepository.SetEvaluated
+func (r *AchievementRepository) SetEvaluated(ctx context.Context, code, fingerprint string) error {
+	ctx, span := r.store.start(ctx, "AchievementRepository.SetEvaluated")
+	defer span.End()
+
+	err := r.inner.SetEvaluated(ctx, code, fingerprint)
+	span.SetError(err)
+
+	return err
+}
+
+// GuildRepository object. Traces calls of the decorated repository
+type GuildRepository struct {
+	store *Store
+	inner store.GuildRepository
+}
+
+// Create func. Traces GuildRepository.Create
+func (r *GuildRepository) Create(ctx context.Context, g *model.Guild, leaderID int) error {
+	ctx, span := r.store.start(ctx, "GuildRepository.Create")
+	defer span.End()
+
+	err := r.inner.Create(ctx, g, leaderID)
+	span.SetError(err)
+
+	return err
+}
+
+// Find func. Traces GuildRepository.Find
+func (r *GuildRepository) Find(ctx context.Context, id int) (*model.Guild, error) {
+	ctx, span := r.store.start(ctx, "GuildRepository.Find")
+	defer span.End()
+
+	res, err := r.inner.Find(ctx, id)
+	span.SetError(err)
+
+	return res, err
+}
+
+// List func. Traces GuildRepository.List
+func (r *GuildRepository) List(ctx context.Context, q *store.ListQuery) ([]*model.Guild, error) {
+	ctx, span := r.store.start(ctx, "GuildRepository.List")
+	defer span.End()
+
+	res, err := r.inner.List(ctx, q)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Update func. Traces GuildRepository.Update
+func (r *GuildRepository) Update(ctx context.Context, guild *model.Guild) error {
+	ctx, span := r.store.start(ctx, "GuildRepository.Update")
+	defer span.End()
+
+	err := r.inner.Update(ctx, guild)
+	span.SetError(err)
+
+	return err
+}
+
+// Delete func. Traces GuildRepository.Delete
+func (r *GuildRepository) Delete(ctx context.Context, id int) error {
+	ctx, span := r.store.start(ctx, "GuildRepository.Delete")
+	defer span.End()
+
+	err := r.inner.Delete(ctx, id)
+	span.SetError(err)
+
+	return err
+}
+
+// Member func. Traces GuildRepository.Member
+func (r *GuildRepository) Member(ctx context.Context, guildID, userID int) (*model.GuildMember, error) {
+	ctx, span := r.store.start(ctx, "GuildRepository.Member")
+	defer span.End()
+
+	res, err := r.inner.Member(ctx, guildID, userID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// MemberOf func. Traces GuildRepository.MemberOf
+func (r *GuildRepository) MemberOf(ctx context.Context, userID int) (*model.GuildMember, error) {
+	ctx, span := r.store.start(ctx, "GuildRepository.MemberOf")
+	defer span.End()
+
+	res, err := r.inner.MemberOf(ctx, userID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Members func. Traces GuildRepository.Members
+func (r *GuildRepository) Members(ctx context.Context, guildID int) ([]*model.GuildMember, error) {
+	ctx, span := r.store.start(ctx, "GuildRepository.Members")
+	defer span.End()
+
+	res, err := r.inner.Members(ctx, guildID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// RemoveMember func. Traces GuildRepository.RemoveMember
+func (r *GuildRepository) RemoveMember(ctx context.Context, guildID, userID int) error {
+	ctx, span := r.store.start(ctx, "GuildRepository.RemoveMember")
+	defer span.End()
+
+	err := r.inner.RemoveMember(ctx, guildID, userID)
+	span.SetError(err)
+
+	return err
+}
+
+// SetRole func. Traces GuildRepository.SetRole
+func (r *GuildRepository) SetRole(ctx context.Context, guildMember *model.GuildMember) error {
+	ctx, span := r.store.start(ctx, "GuildRepository.SetRole")
+	defer span.End()
+
+	err := r.inner.SetRole(ctx, guildMember)
+	span.SetError(err)
+
+	return err
+}
+
+// Transfer func. Traces GuildRepository.Transfer
+func (r *GuildRepository) Transfer(ctx context.Context, guildID, leaderID, userID int) error {
+	ctx, span := r.store.start(ctx, "GuildRepository.Transfer")
+	defer span.End()
+
+	err := r.inner.Transfer(ctx, guildID, leaderID, userID)
+	span.SetError(err)
+
+	return err
+}
+
+// Invite func. Traces GuildRepository.Invite
+func (r *GuildRepository) Invite(ctx context.Context, guildInvite *model.GuildInvite) (*model.GuildMember, error) {
+	ctx, span := r.store.start(ctx, "GuildRepository.Invite")
+	defer span.End()
+
+	res, err := r.inner.Invite(ctx, guildInvite)
+	span.SetError(err)
+
+	return res, err
+}
+
+// RemoveInvite func. Traces GuildRepository.RemoveInvite
+func (r *GuildRepository) RemoveInvite(ctx context.Context, guildID, userID int) error {
+	ctx, span := r.store.start(ctx, "GuildRepository.RemoveInvite")
+	defer span.End()
+
+	err := r.inner.RemoveInvite(ctx, guildID, userID)
+	span.SetError(err)
+
+	return err
+}
+
+// Invites func. Traces GuildRepository.Invites
+func (r *GuildRepository) Invites(ctx context.Context, guildID int) ([]*model.GuildInvite, error) {
+	ctx, span := r.store.start(ctx, "GuildRepository.Invites")
+	defer span.End()
+
+	res, err := r.inner.Invites(ctx, guildID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// InvitesOf func. Traces GuildRepository.InvitesOf
+func (r *GuildRepository) InvitesOf(ctx context.Context, userID int) ([]*model.GuildInvite, error) {
+	ctx, span := r.store.start(ctx, "GuildRepository.InvitesOf")
+	defer span.End()
+
+	res, err := r.inner.InvitesOf(ctx, userID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Leaderboard func. Traces GuildRepository.Leaderboard
+func (r *GuildRepository) Leaderboard(ctx context.Context, gameID int, season string, q *store.ListQuery) ([]*model.GuildLeaderboardEntry, error) {
+	ctx, span := r.store.start(ctx, "GuildRepository.Leaderboard")
+	defer span.End()
+
+	res, err := r.inner.Leaderboard(ctx, gameID, season, q)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Rank func. Traces GuildRepository.Rank
+func (r *GuildRepository) Rank(ctx context.Context, guildID, gameID int, season string) (*model.GuildLeaderboardEntry, error) {
+	ctx, span := r.store.start(ctx, "GuildRepository.Rank")
+	defer span.End()
+
+	res, err := r.inner.Rank(ctx, guildID, gameID, season)
+	span.SetError(err)
+
+	return res, err
+}
+
+// LFGRepository object. Traces calls of the decorated repository
+type LFGRepository struct {
+	store *Store
+	inner store.LFGRepository
+}
+
+// Create func. Traces LFGRepository.Create
+func (r *LFGRepository) Create(ctx context.Context, lfgPost *model.LFGPost) error {
+	ctx, span := r.store.start(ctx, "LFGRepository.Create")
+	defer span.End()
+
+	err := r.inner.Create(ctx, lfgPost)
+	span.SetError(err)
+
+	return err
+}
+
+// Find func. Traces LFGRepository.Find
+func (r *LFGRepository) Find(ctx context.Context, id int) (*model.LFGPost, error) {
+	ctx, span := r.store.start(ctx, "LFGRepository.Find")
+	defer span.End()
+
+	res, err := r.inner.Find(ctx, id)
+	span.SetError(err)
+
+	return res, err
+}
+
+// FindOpen func. Traces LFGRepository.FindOpen
+func (r *LFGRepository) FindOpen(ctx context.Context, f *store.LFGFilter, now time.Time, q *store.ListQuery) ([]*model.LFGPost, error) {
+	ctx, span := r.store.start(ctx, "LFGRepository.FindOpen")
+	defer span.End()
+
+	res, err := r.inner.FindOpen(ctx, f, now, q)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Close func. Traces LFGRepository.Close
+func (r *LFGRepository) Close(ctx context.Context, id int) error {
+	ctx, span := r.store.start(ctx, "LFGRepository.Close")
+	defer span.End()
+
+	err := r.inner.Close(ctx, id)
+	span.SetError(err)
+
+	return err
+}
+
+// Expire func. Traces LFGRepository.Expire
+func (r *LFGRepository) Expire(ctx context.Context, now time.Time) (int, error) {
+	ctx, span := r.store.start(ctx, "LFGRepository.Expire")
+	defer span.End()
+
+	res, err := r.inner.Expire(ctx, now)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Request func. Traces LFGRepository.Request
+func (r *LFGRepository) Request(ctx context.Context, lfgRequest *model.LFGRequest) error {
+	ctx, span := r.store.start(ctx, "LFGRepository.Request")
+	defer span.End()
+
+	err := r.inner.Request(ctx, lfgRequest)
+	span.SetError(err)
+
+	return err
+}
+
+// Requests func. Traces LFGRepository.Requests
+func (r *LFGRepository) Requests(ctx context.Context, postID int) ([]*model.LFGRequest, error) {
+	ctx, span := r.store.start(ctx, "LFGRepository.Requests")
+	defer span.End()
+
+	res, err := r.inner.Requests(ctx, postID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// EventRepository object. Traces calls of the decorated repository
+type EventRepository struct {
+	store *Store
+	inner store.EventRepository
+}
+
+// Create func. Traces EventRepository.Create
+func (r *EventRepository) Create(ctx context.Context, event *model.Event) error {
+	ctx, span := r.store.start(ctx, "EventRepository.Create")
+	defer span.End()
+
+	err := r.inner.Create(ctx, event)
+	span.SetError(err)
+
+	return err
+}
+
+// Find func. Traces EventRepository.Find
+func (r *EventRepository) Find(ctx context.Context, id int) (*model.Event, error) {
+	ctx, span := r.store.start(ctx, "EventRepository.Find")
+	defer span.End()
+
+	res, err := r.inner.Find(ctx, id)
+	span.SetError(err)
+
+	return res, err
+}
+
+// FindUpcoming func. Traces EventRepository.FindUpcoming
+func (r *EventRepository) FindUpcoming(ctx context.Context, now time.Time, q *store.ListQuery) ([]*model.Event, error) {
+	ctx, span := r.store.start(ctx, "EventRepository.FindUpcoming")
+	defer span.End()
+
+	res, err := r.inner.FindUpcoming(ctx, now, q)
+	span.SetError(err)
+
+	return res, err
+}
+
+// FindByAttendee func. Traces EventRepository.FindByAttendee
+func (r *EventRepository) FindByAttendee(ctx context.Context, userID int) ([]*model.Event, error) {
+	ctx, span := r.store.start(ctx, "EventRepository.FindByAttendee")
+	defer span.End()
+
+	res, err := r.inner.FindByAttendee(ctx, userID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// RSVP func. Traces EventRepository.RSVP
+func (r *EventRepository) RSVP(ctx context.Context, eventID, userID int, status string) ([]*model.EventRSVP, error) {
+	ctx, span := r.store.start(ctx, "EventRepository.RSVP")
+	defer span.End()
+
+	res, err := r.inner.RSVP(ctx, eventID, userID, status)
+	span.SetError(err)
+
+	return res, err
+}
+
+// RSVPs func. Traces EventRepository.RSVPs
+func (r *EventRepository) RSVPs(ctx context.Context, eventID int) ([]*model.EventRSVP, error) {
+	ctx, span := r.store.start(ctx, "EventRepository.RSVPs")
+	defer span.End()
+
+	res, err := r.inner.RSVPs(ctx, eventID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// SetCalendarToken func. Traces EventRepository.SetCalendarToken
+func (r *EventRepository) SetCalendarToken(ctx context.Context, token *model.CalendarToken) error {
+	ctx, span := r.store.start(ctx, "EventRepository.SetCalendarToken")
+	defer span.End()
+
+	err := r.inner.SetCalendarToken(ctx, token)
+	span.SetError(err)
+
+	return err
+}
+
+// RevokeCalendarToken func. Traces EventRepository.RevokeCalendarToken
+func (r *EventRepository) RevokeCalendarToken(ctx context.Context, userID int) error {
+	ctx, span := r.store.start(ctx, "EventRepository.RevokeCalendarToken")
+	defer span.End()
+
+	err := r.inner.RevokeCalendarToken(ctx, userID)
+	span.SetError(err)
+
+	return err
+}
+
+// FindCalendarToken func. Traces EventRepository.FindCalendarToken
+func (r *EventRepository) FindCalendarToken(ctx context.Context, hash string) (*model.CalendarToken, error) {
+	ctx, span := r.store.start(ctx, "EventRepository.FindCalendarToken")
+	defer span.End()
+
+	res, err := r.inner.FindCalendarToken(ctx, hash)
+	span.SetError(err)
+
+	return res, err
+}
+
+// NotificationRepository object. Traces calls of the decorated repository
+type NotificationRepository struct {
+	store *Store
+	inner store.NotificationRepository
+}
+
+// Create func. Traces NotificationRepository.Create
+func (r *NotificationRepository) Create(ctx context.Context, notification *model.Notification) error {
+	ctx, span := r.store.start(ctx, "NotificationRepository.Create")
+	defer span.End()
+
+	err := r.inner.Create(ctx, notification)
+	span.SetError(err)
+
+	return err
+}
+
+// FindByUser func. Traces NotificationRepository.FindByUser
+func (r *NotificationRepository) FindByUser(ctx context.Context, userID, beforeID, limit int) ([]*model.Notification, error) {
+	ctx, span := r.store.start(ctx, "NotificationRepository.FindByUser")
+	defer span.End()
+
+	res, err := r.inner.FindByUser(ctx, userID, beforeID, limit)
+	span.SetError(err)
+
+	return res, err
+}
+
+// CountUnread func. Traces NotificationRepository.CountUnread
+func (r *NotificationRepository) CountUnread(ctx context.Context, userID int) (int, error) {
+	ctx, span := r.store.start(ctx, "NotificationRepository.CountUnread")
+	defer span.End()
+
+	res, err := r.inner.CountUnread(ctx, userID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// MarkRead func. Traces NotificationRepository.MarkRead
+func (r *NotificationRepository) MarkRead(ctx context.Context, userID, id int) error {
+	ctx, span := r.store.start(ctx, "NotificationRepository.MarkRead")
+	defer span.End()
+
+	err := r.inner.MarkRead(ctx, userID, id)
+	span.SetError(err)
+
+	return err
+}
+
+// MarkAllRead func. Traces NotificationRepository.MarkAllRead
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID int) (int, error) {
+	ctx, span := r.store.start(ctx, "NotificationRepository.MarkAllRead")
+	defer span.End()
+
+	res, err := r.inner.MarkAllRead(ctx, userID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Preferences func. Traces NotificationRepository.Preferences
+func (r *NotificationRepository) Preferences(ctx context.Context, userID int) (map[string]bool, error) {
+	ctx, span := r.store.start(ctx, "NotificationRepository.Preferences")
+	defer span.End()
+
+	res, err := r.inner.Preferences(ctx, userID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// SetPreferences func. Traces NotificationRepository.SetPreferences
+func (r *NotificationRepository) SetPreferences(ctx context.Context, userID int, prefs map[string]bool) error {
+	ctx, span := r.store.start(ctx, "NotificationRepository.SetPreferences")
+	defer span.End()
+
+	err := r.inner.SetPreferences(ctx, userID, prefs)
+	span.SetError(err)
+
+	return err
+}
+
+// ActivityRepository object. Traces calls of the decorated repository
+type ActivityRepository struct {
+	store *Store
+	inner store.ActivityRepository
+}
+
+// Create func. Traces ActivityRepository.Create
+func (r *ActivityRepository) Create(ctx context.Context, activity *model.Activity) error {
+	ctx, span := r.store.start(ctx, "ActivityRepository.Create")
+	defer span.End()
+
+	err := r.inner.Create(ctx, activity)
+	span.SetError(err)
+
+	return err
+}
+
+// FindByUsers func. Traces ActivityRepository.FindByUsers
+func (r *ActivityRepository) FindByUsers(ctx context.Context, userIDs []int, beforeID, limit int) ([]*model.Activity, error) {
+	ctx, span := r.store.start(ctx, "ActivityRepository.FindByUsers")
+	defer span.End()
+
+	res, err := r.inner.FindByUsers(ctx, userIDs, beforeID, limit)
+	span.SetError(err)
+
+	return res, err
+}
+
+// ModerationRepository object. Traces calls of the decorated repository
+type ModerationRepository struct {
+	store *Store
+	inner store.ModerationRepository
+}
+
+// CreateReport func. Traces ModerationRepository.CreateReport
+func (r *ModerationRepository) CreateReport(ctx context.Context, report *model.Report) error {
+	ctx, span := r.store.start(ctx, "ModerationRepository.CreateReport")
+	defer span.End()
+
+	err := r.inner.CreateReport(ctx, report)
+	span.SetError(err)
+
+	return err
+}
+
+// FindReport func. Traces ModerationRepository.FindReport
+func (r *ModerationRepository) FindReport(ctx context.Context, id int) (*model.Report, error) {
+	ctx, span := r.store.start(ctx, "ModerationRepository.FindReport")
+	defer span.End()
+
+	res, err := r.inner.FindReport(ctx, id)
+	span.SetError(err)
+
+	return res, err
+}
+
+// FindReports func. Traces ModerationRepository.FindReports
+func (r *ModerationRepository) FindReports(ctx context.Context, status string, q *store.ListQuery) ([]*model.Report, error) {
+	ctx, span := r.store.start(ctx, "ModerationRepository.FindReports")
+	defer span.End()
+
+	res, err := r.inner.FindReports(ctx, status, q)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Resolve func. Traces ModerationRepository.Resolve
+func (r *ModerationRepository) Resolve(ctx context.Context, id int, action, reason string, moderatorID int, s *model.Sanction) (*model.Report, error) {
+	ctx, span := r.store.start(ctx, "ModerationRepository.Resolve")
+	defer span.End()
+
+	res, err := r.inner.Resolve(ctx, id, action, reason, moderatorID, s)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Sanctions func. Traces ModerationRepository.Sanctions
+func (r *ModerationRepository) Sanctions(ctx context.Context, userID int) ([]*model.Sanction, error) {
+	ctx, span := r.store.start(ctx, "ModerationRepository.Sanctions")
+	defer span.End()
+
+	res, err := r.inner.Sanctions(ctx, userID)
+	span.SetError(err)
+
+	return res, err
+}
+
+// Lift func. Traces ModerationRepository.Lift
+func (r *ModerationRepository) Lift(ctx context.Context, id int, now time.Time) error {
+	ctx, span := r.store.start(ctx, "ModerationRepository.Lift")
+	defer span.End()
+
+	err := r.inner.Lift(ctx, id, now)
+	span.SetError(err)
+
+	return err
+}
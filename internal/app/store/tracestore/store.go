@@ -0,0 +1,126 @@
+// Package tracestore decorates a store with tracing: every repository
+// call is a span, a child of the span of the request it serves.
+package tracestore
+
+import (
+	"context"
+
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/trace"
+)
+
+// Store object. Decorates the store, calls of its repositories are
+// traced as children of the span of the context of the call. Calls
+// without a span in their context aren't traced.
+type Store struct {
+	inner store.Store
+}
+
+// New func. Constructor for Store
+func New(inner store.Store) *Store {
+	return &Store{
+		inner: inner,
+	}
+}
+
+// WithTx func. Traces the transaction, calls of repositories of the
+// store fn gets are traced as well
+func (s *Store) WithTx(ctx context.Context, fn func(store.Store) error) error {
+	ctx, span := s.start(ctx, "Store.WithTx")
+	defer span.End()
+
+	err := s.inner.WithTx(ctx, func(tx store.Store) error {
+		return fn(New(tx))
+	})
+	span.SetError(err)
+
+	return err
+}
+
+// start func. Starts span of the repository call
+func (s *Store) start(ctx context.Context, name string) (context.Context, *trace.Span) {
+	ctx, span := trace.Start(ctx, name)
+	span.SetAttribute("component", "store")
+
+	return ctx, span
+}
+
+// User func. Returns traced UserRepository
+func (s *Store) User() store.UserRepository {
+	return &UserRepository{store: s, inner: s.inner.User()}
+}
+
+// Game func. Returns traced GameRepository
+func (s *Store) Game() store.GameRepository {
+	return &GameRepository{store: s, inner: s.inner.Game()}
+}
+
+// Library func. Returns traced LibraryRepository
+func (s *Store) Library() store.LibraryRepository {
+	return &LibraryRepository{store: s, inner: s.inner.Library()}
+}
+
+// Review func. Returns traced ReviewRepository
+func (s *Store) Review() store.ReviewRepository {
+	return &ReviewRepository{store: s, inner: s.inner.Review()}
+}
+
+// Friendship func. Returns traced FriendshipRepository
+func (s *Store) Friendship() store.FriendshipRepository {
+	return &FriendshipRepository{store: s, inner: s.inner.Friendship()}
+}
+
+// Chat func. Returns traced ChatRepository
+func (s *Store) Chat() store.ChatRepository {
+	return &ChatRepository{store: s, inner: s.inner.Chat()}
+}
+
+// Match func. Returns traced MatchRepository
+func (s *Store) Match() store.MatchRepository {
+	return &MatchRepository{store: s, inner: s.inner.Match()}
+}
+
+// Rating func. Returns traced RatingRepository
+func (s *Store) Rating() store.RatingRepository {
+	return &RatingRepository{store: s, inner: s.inner.Rating()}
+}
+
+// Tournament func. Returns traced TournamentRepository
+func (s *Store) Tournament() store.TournamentRepository {
+	return &TournamentRepository{store: s, inner: s.inner.Tournament()}
+}
+
+// Achievement func. Returns traced AchievementRepository
+func (s *Store) Achievement() store.AchievementRepository {
+	return &AchievementRepository{store: s, inner: s.inner.Achievement()}
+}
+
+// Guild func. Returns traced GuildRepository
+func (s *Store) Guild() store.GuildRepository {
+	return &GuildRepository{store: s, inner: s.inner.Guild()}
+}
+
+// LFG func. Returns traced LFGRepository
+func (s *Store) LFG() store.LFGRepository {
+	return &LFGRepository{store: s, inner: s.inner.LFG()}
+}
+
+// Event func. Returns traced EventRepository
+func (s *Store) Event() store.EventRepository {
+	return &EventRepository{store: s, inner: s.inner.Event()}
+}
+
+// Notification func. Returns traced NotificationRepository
+func (s *Store) Notification() store.NotificationRepository {
+	return &NotificationRepository{store: s, inner: s.inner.Notification()}
+}
+
+// Activity func. Returns traced ActivityRepository
+func (s *Store) Activity() store.ActivityRepository {
+	return &ActivityRepository{store: s, inner: s.inner.Activity()}
+}
+
+// Moderation func. Returns traced ModerationRepository
+func (s *Store) Moderation() store.ModerationRepository {
+	return &ModerationRepository{store: s, inner: s.inner.Moderation()}
+}
@@ -0,0 +1,67 @@
+package tracestore_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/model"
+	"github.com/GShamian/tavern-of-games/internal/app/store"
+	"github.com/GShamian/tavern-of-games/internal/app/store/teststore"
+	"github.com/GShamian/tavern-of-games/internal/app/store/tracestore"
+	"github.com/GShamian/tavern-of-games/internal/app/trace"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	exporter := trace.NewMemoryExporter()
+	ctx, root := trace.NewTracer(exporter).Start(context.Background(), "request")
+	s := tracestore.New(teststore.New())
+
+	u := model.TestUser(t)
+	assert.NoError(t, s.User().Create(ctx, u))
+	_, err := s.User().Find(ctx, u.ID+1)
+	assert.EqualError(t, err, store.ErrRecordNotFound.Error())
+	root.End()
+
+	spans := exporter.Spans()
+	if !assert.Len(t, spans, 3) {
+		return
+	}
+	assert.Equal(t, "UserRepository.Create", spans[0].Name)
+	assert.Empty(t, spans[0].Error)
+	assert.Equal(t, "UserRepository.Find", spans[1].Name)
+	assert.Equal(t, store.ErrRecordNotFound.Error(), spans[1].Error)
+	for _, span := range spans[:2] {
+		assert.Equal(t, root.Context().SpanID, span.ParentID)
+		assert.Equal(t, "store", span.Attributes["component"])
+	}
+}
+
+func TestStore_WithoutSpan(t *testing.T) {
+	s := tracestore.New(teststore.New())
+	ctx := context.Background()
+	u := model.TestUser(t)
+	assert.NoError(t, s.User().Create(ctx, u))
+	found, err := s.User().Find(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, u.ID, found.ID)
+}
+
+func TestStore_WithTx(t *testing.T) {
+	exporter := trace.NewMemoryExporter()
+	ctx, root := trace.NewTracer(exporter).Start(context.Background(), "request")
+	s := tracestore.New(teststore.New())
+
+	assert.NoError(t, s.WithTx(ctx, func(tx store.Store) error {
+		return tx.User().Create(ctx, model.TestUser(t))
+	}))
+	root.End()
+
+	spans := exporter.Spans()
+	if !assert.Len(t, spans, 3) {
+		return
+	}
+	assert.Equal(t, "UserRepository.Create", spans[0].Name)
+	assert.Equal(t, "Store.WithTx", spans[1].Name)
+	assert.Equal(t, root.Context().SpanID, spans[1].ParentID)
+}
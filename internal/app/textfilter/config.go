@@ -0,0 +1,93 @@
+package textfilter
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Config object. Describes the text filter: word lists, limits of links
+// in text and limits of chat messages of a user. Windows of flood
+// limits are in seconds.
+type Config struct {
+	WordLists []*WordList `toml:"wordlist"`
+	Links     Links       `toml:"links"`
+	Flood     struct {
+		Messages          int `toml:"messages"`
+		WindowSeconds     int `toml:"window_seconds"`
+		DuplicatesSeconds int `toml:"duplicates_seconds"`
+	} `toml:"flood"`
+}
+
+// DefaultConfig func. Returns the config used without a config file:
+// no word lists, a few links and a few messages in a row are fine.
+func DefaultConfig() *Config {
+	c := &Config{
+		Links: Links{Flag: 2, Block: 5},
+	}
+	c.Flood.Messages = 5
+	c.Flood.WindowSeconds = 10
+	c.Flood.DuplicatesSeconds = 30
+
+	return c
+}
+
+// Validate func. Validating config for limits and locales of word lists
+func (c *Config) Validate() error {
+	if err := (validation.Errors{
+		"links.flag":               validation.Validate(c.Links.Flag, validation.Min(0)),
+		"links.block":              validation.Validate(c.Links.Block, validation.Min(0)),
+		"flood.messages":           validation.Validate(c.Flood.Messages, validation.Min(0)),
+		"flood.window_seconds":     validation.Validate(c.Flood.WindowSeconds, validation.Min(0)),
+		"flood.duplicates_seconds": validation.Validate(c.Flood.DuplicatesSeconds, validation.Min(0)),
+	}).Filter(); err != nil {
+		return err
+	}
+
+	for _, l := range c.WordLists {
+		if err := validation.ValidateStruct(l, validation.Field(&l.Locale, validation.Required)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Pipeline func. Returns the pipeline of text of any content: word
+// lists and the link limit
+func (c *Config) Pipeline() Pipeline {
+	links := c.Links
+
+	return Pipeline{NewWords(c.WordLists...), &links}
+}
+
+// NewFlood func. Returns flood detector of chat with limits of the config
+func (c *Config) NewFlood() *Flood {
+	return NewFlood(
+		c.Flood.Messages,
+		time.Duration(c.Flood.WindowSeconds)*time.Second,
+		time.Duration(c.Flood.DuplicatesSeconds)*time.Second,
+	)
+}
+
+// LoadFile func. Decodes the text filter config from the TOML file over
+// the default config and validates it. A missing file means the default
+// config.
+func LoadFile(path string) (*Config, error) {
+	c := DefaultConfig()
+	if _, err := toml.DecodeFile(path, c); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return c, nil
+		}
+		return nil, err
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
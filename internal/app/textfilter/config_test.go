@@ -0,0 +1,57 @@
+package textfilter_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/textfilter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "textfilter")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	write := func(content string) string {
+		path := filepath.Join(dir, "textfilter.toml")
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	c, err := textfilter.LoadFile(filepath.Join(dir, "missing.toml"))
+	assert.NoError(t, err)
+	assert.Equal(t, textfilter.DefaultConfig(), c)
+
+	c, err = textfilter.LoadFile(write(`
+[links]
+flag = 1
+
+[[wordlist]]
+locale = "en"
+block = ["darn"]
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, c.Links.Flag)
+	assert.Equal(t, textfilter.DefaultConfig().Links.Block, c.Links.Block)
+	assert.Equal(t, textfilter.Block, c.Pipeline().Check("darn").Verdict)
+
+	_, err = textfilter.LoadFile(write(`
+[[wordlist]]
+block = ["darn"]
+`))
+	assert.Error(t, err)
+
+	_, err = textfilter.LoadFile(write(`
+[flood]
+messages = -1
+`))
+	assert.Error(t, err)
+}
+
+func TestLoadFile_Configs(t *testing.T) {
+	c, err := textfilter.LoadFile("../../../configs/textfilter.toml")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, c.WordLists)
+}
@@ -0,0 +1,96 @@
+package textfilter
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// sent object. Describes a message the user sent recently.
+type sent struct {
+	text string
+	at   time.Time
+}
+
+// Flood object. Detects users flooding chat: a user can send Messages
+// messages within Window at most, and can't repeat a message sent within
+// Duplicates. Zero limits are off. It's safe for concurrent use.
+type Flood struct {
+	messages   int
+	window     time.Duration
+	duplicates time.Duration
+	mu         sync.Mutex
+	sent       map[int][]sent
+}
+
+// NewFlood func. Constructor for Flood
+func NewFlood(messages int, window, duplicates time.Duration) *Flood {
+	return &Flood{
+		messages:   messages,
+		window:     window,
+		duplicates: duplicates,
+		sent:       make(map[int][]sent),
+	}
+}
+
+// For func. Returns checker of the message the user sends at the time.
+// Messages which aren't blocked count as sent.
+func (f *Flood) For(userID int, now time.Time) Checker {
+	return CheckerFunc(func(text string) Result {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		recent := f.recent(f.sent[userID], now)
+		key := strings.Join(Tokens(text), " ")
+		inWindow := 0
+		for _, s := range recent {
+			if f.duplicates > 0 && s.text == key && now.Sub(s.at) < f.duplicates {
+				f.sent[userID] = recent
+				return Result{Verdict: Block, Reason: "repeats a recent message"}
+			}
+			if now.Sub(s.at) < f.window {
+				inWindow++
+			}
+		}
+
+		if f.messages > 0 && inWindow >= f.messages {
+			f.sent[userID] = recent
+			return Result{Verdict: Block, Reason: "too many messages, slow down"}
+		}
+
+		f.sent[userID] = append(recent, sent{text: key, at: now})
+
+		return Result{Verdict: Allow}
+	})
+}
+
+// Sweep func. Forgets messages too old to matter at the time, so users
+// who stopped writing don't take memory
+func (f *Flood) Sweep(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for userID, messages := range f.sent {
+		recent := f.recent(messages, now)
+		if len(recent) == 0 {
+			delete(f.sent, userID)
+			continue
+		}
+		f.sent[userID] = recent
+	}
+}
+
+// recent func. Leaves out messages older than both limits at the time
+func (f *Flood) recent(messages []sent, now time.Time) []sent {
+	keep := f.window
+	if f.duplicates > keep {
+		keep = f.duplicates
+	}
+
+	i := 0
+	for i < len(messages) && now.Sub(messages[i].at) >= keep {
+		i++
+	}
+
+	return messages[i:]
+}
@@ -0,0 +1,31 @@
+package textfilter_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GShamian/tavern-of-games/internal/app/textfilter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlood(t *testing.T) {
+	f := textfilter.NewFlood(3, 10*time.Second, time.Minute)
+	now := time.Now()
+	check := func(userID int, text string, at time.Duration) textfilter.Verdict {
+		return f.For(userID, now.Add(at)).Check(text).Verdict
+	}
+
+	assert.Equal(t, textfilter.Allow, check(1, "hi", 0))
+	assert.Equal(t, textfilter.Block, check(1, "HI!", time.Second))
+	assert.Equal(t, textfilter.Allow, check(2, "hi", time.Second))
+	assert.Equal(t, textfilter.Allow, check(1, "anyone up?", 2*time.Second))
+	assert.Equal(t, textfilter.Allow, check(1, "for a round", 3*time.Second))
+	assert.Equal(t, textfilter.Block, check(1, "of chess", 4*time.Second))
+	// The window moved on, but the message is still a duplicate
+	assert.Equal(t, textfilter.Allow, check(1, "of chess", 15*time.Second))
+	assert.Equal(t, textfilter.Block, check(1, "hi", 30*time.Second))
+	assert.Equal(t, textfilter.Allow, check(1, "hi", 61*time.Second))
+
+	f.Sweep(now.Add(2 * time.Minute))
+	assert.Equal(t, textfilter.Allow, check(1, "of chess", 2*time.Minute))
+}
@@ -0,0 +1,29 @@
+package textfilter
+
+import (
+	"regexp"
+)
+
+// link matches links with a scheme or starting with www
+var link = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s]+`)
+
+// Links object. Checker that limits links in text: text with more than
+// Flag links is flagged, text with more than Block links is blocked.
+// Zero limits are off.
+type Links struct {
+	Flag  int `toml:"flag"`
+	Block int `toml:"block"`
+}
+
+// Check func. Counts links in the text
+func (l *Links) Check(text string) Result {
+	n := len(link.FindAllStringIndex(text, -1))
+	switch {
+	case l.Block > 0 && n > l.Block:
+		return Result{Verdict: Block, Reason: "contains too many links"}
+	case l.Flag > 0 && n > l.Flag:
+		return Result{Verdict: Flag, Reason: "contains many links"}
+	}
+
+	return Result{Verdict: Allow}
+}
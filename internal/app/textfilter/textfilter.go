@@ -0,0 +1,112 @@
+// Package textfilter implements the moderation pipeline of user-generated
+// text. Every checker gives the text a verdict: allow it, flag it for
+// review by moderators or block it. Checkers run as ozzo-validation
+// rules, so they compose with the other rules of a field.
+package textfilter
+
+import (
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Verdict of a checker, from the mildest one
+type Verdict int
+
+// Verdicts
+const (
+	Allow Verdict = iota
+	Flag
+	Block
+)
+
+// errBlocked is the validation error of blocked text
+var errBlocked = validation.NewError("validation_text_blocked", "is not allowed")
+
+// String func. Returns the name of the verdict
+func (v Verdict) String() string {
+	switch v {
+	case Flag:
+		return "flag"
+	case Block:
+		return "block"
+	}
+
+	return "allow"
+}
+
+// Result object. Describes the verdict of a checker and the reason of
+// it, the reason is empty for allowed text.
+type Result struct {
+	Verdict Verdict
+	Reason  string
+}
+
+// Checker interface. Gives the text a verdict.
+type Checker interface {
+	Check(text string) Result
+}
+
+// CheckerFunc func type. Adapts a function to Checker interface.
+type CheckerFunc func(text string) Result
+
+// Check func. Calls the function with the text
+func (f CheckerFunc) Check(text string) Result {
+	return f(text)
+}
+
+// Pipeline object. Runs its checkers in order and gives the text the
+// worst verdict of them. Blocked text isn't checked any further.
+type Pipeline []Checker
+
+// Check func. Runs the text through the pipeline
+func (p Pipeline) Check(text string) Result {
+	res := Result{Verdict: Allow}
+	for _, c := range p {
+		r := c.Check(text)
+		if r.Verdict > res.Verdict {
+			res = r
+		}
+		if res.Verdict == Block {
+			break
+		}
+	}
+
+	return res
+}
+
+// Rule object. Validation rule that runs string values through the
+// checker. Blocked values fail validation with the reason, flagged ones
+// pass it and are handed to the flag func, so the content can be queued
+// for moderators. Empty values are left for validation.Required.
+type Rule struct {
+	checker Checker
+	flag    func(Result)
+}
+
+// NewRule func. Constructor for Rule. The flag func may be nil.
+func NewRule(checker Checker, flag func(Result)) *Rule {
+	return &Rule{
+		checker: checker,
+		flag:    flag,
+	}
+}
+
+// Validate func. Validates the value with the checker
+func (r *Rule) Validate(value interface{}) error {
+	value, isNil := validation.Indirect(value)
+	text, ok := value.(string)
+	if isNil || !ok || text == "" {
+		return nil
+	}
+
+	res := r.checker.Check(text)
+	switch res.Verdict {
+	case Block:
+		return errBlocked.SetMessage(res.Reason)
+	case Flag:
+		if r.flag != nil {
+			r.flag(res)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package textfilter_test
+
+import (
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/textfilter"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipeline_Check(t *testing.T) {
+	verdict := func(v textfilter.Verdict) textfilter.Checker {
+		return textfilter.CheckerFunc(func(string) textfilter.Result {
+			return textfilter.Result{Verdict: v, Reason: v.String()}
+		})
+	}
+	called := false
+	after := textfilter.CheckerFunc(func(string) textfilter.Result {
+		called = true
+		return textfilter.Result{}
+	})
+
+	assert.Equal(t, textfilter.Allow, textfilter.Pipeline{}.Check("hi").Verdict)
+	assert.Equal(t, textfilter.Flag, textfilter.Pipeline{verdict(textfilter.Allow), verdict(textfilter.Flag)}.Check("hi").Verdict)
+	assert.Equal(t, textfilter.Block, textfilter.Pipeline{verdict(textfilter.Block), after}.Check("hi").Verdict)
+	assert.False(t, called)
+}
+
+func TestRule(t *testing.T) {
+	p := textfilter.Pipeline{
+		textfilter.NewWords(&textfilter.WordList{Locale: "en", Block: []string{"darn"}, Flag: []string{"noob"}}),
+	}
+	flags := []textfilter.Result{}
+	rule := textfilter.NewRule(p, func(res textfilter.Result) {
+		flags = append(flags, res)
+	})
+
+	assert.NoError(t, validation.Validate("good game", validation.Required, rule))
+	assert.NoError(t, validation.Validate("", rule))
+	assert.Error(t, validation.Validate("", validation.Required, rule))
+	assert.EqualError(t, validation.Validate("d4rn it", validation.Required, rule), "contains blocked words")
+	assert.Empty(t, flags)
+
+	text := "what a n00b"
+	assert.NoError(t, validation.Validate(&text, validation.Required, rule))
+	if assert.Len(t, flags, 1) {
+		assert.Equal(t, textfilter.Flag, flags[0].Verdict)
+	}
+}
+
+func TestLinks_Check(t *testing.T) {
+	l := &textfilter.Links{Flag: 1, Block: 2}
+	assert.Equal(t, textfilter.Allow, l.Check("rules at https://example.org/rules").Verdict)
+	assert.Equal(t, textfilter.Flag, l.Check("see www.example.org and http://example.com").Verdict)
+	assert.Equal(t, textfilter.Block, l.Check("http://a.org http://b.org HTTPS://c.org").Verdict)
+
+	off := &textfilter.Links{}
+	assert.Equal(t, textfilter.Allow, off.Check("http://a.org http://b.org http://c.org").Verdict)
+}
@@ -0,0 +1,125 @@
+package textfilter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// leet maps characters used in leetspeak to letters they stand for
+var leet = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'!': 'i',
+	'3': 'e',
+	'4': 'a',
+	'@': 'a',
+	'5': 's',
+	'$': 's',
+	'7': 't',
+	'+': 't',
+	'8': 'b',
+	'9': 'g',
+}
+
+// WordList object. Describes words of the locale which block or flag
+// text containing them.
+type WordList struct {
+	Locale string   `toml:"locale"`
+	Block  []string `toml:"block"`
+	Flag   []string `toml:"flag"`
+}
+
+// Words object. Checker that blocks or flags text containing words of
+// its word lists. Words are compared normalized, so leetspeak, case,
+// repeated letters and spelling words letter by letter don't help.
+type Words struct {
+	block map[string]bool
+	flag  map[string]bool
+}
+
+// NewWords func. Constructor for Words. Words of every list are checked,
+// as users write in any locale.
+func NewWords(lists ...*WordList) *Words {
+	w := &Words{
+		block: make(map[string]bool),
+		flag:  make(map[string]bool),
+	}
+	for _, l := range lists {
+		for _, word := range l.Block {
+			w.block[normalizeWord(word)] = true
+		}
+		for _, word := range l.Flag {
+			w.flag[normalizeWord(word)] = true
+		}
+	}
+
+	return w
+}
+
+// Check func. Blocks text with words of block lists, flags text with
+// words of flag lists.
+func (w *Words) Check(text string) Result {
+	res := Result{Verdict: Allow}
+	for _, word := range Tokens(text) {
+		switch {
+		case w.block[word]:
+			return Result{Verdict: Block, Reason: "contains blocked words"}
+		case w.flag[word]:
+			res = Result{Verdict: Flag, Reason: "contains words to review"}
+		}
+	}
+
+	return res
+}
+
+// Tokens func. Splits normalized text into words. Runs of single
+// letters, e.g. "b a d", are joined into one word.
+func Tokens(text string) []string {
+	fields := strings.FieldsFunc(Normalize(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	tokens := make([]string, 0, len(fields))
+	spelled := ""
+	for _, f := range fields {
+		if len([]rune(f)) == 1 {
+			spelled += f
+			continue
+		}
+		if spelled != "" {
+			tokens = append(tokens, normalizeWord(spelled))
+			spelled = ""
+		}
+		tokens = append(tokens, f)
+	}
+	if spelled != "" {
+		tokens = append(tokens, normalizeWord(spelled))
+	}
+
+	return tokens
+}
+
+// Normalize func. Lowercases the text, replaces leetspeak with letters
+// and collapses repeated letters.
+func Normalize(text string) string {
+	b := strings.Builder{}
+	var prev rune
+	for _, r := range strings.ToLower(text) {
+		if l, ok := leet[r]; ok {
+			r = l
+		}
+		if r == prev && unicode.IsLetter(r) {
+			continue
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+
+	return b.String()
+}
+
+// normalizeWord func. Normalizes the word of a word list the same way
+// as text is normalized
+func normalizeWord(word string) string {
+	return Normalize(strings.TrimSpace(word))
+}
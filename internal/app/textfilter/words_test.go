@@ -0,0 +1,73 @@
+package textfilter_test
+
+import (
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/textfilter"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWords_Check(t *testing.T) {
+	w := textfilter.NewWords(
+		&textfilter.WordList{Locale: "en", Block: []string{"darn"}, Flag: []string{"noob"}},
+		&textfilter.WordList{Locale: "ru", Block: []string{"блин"}},
+	)
+
+	testCases := []struct {
+		name    string
+		text    string
+		verdict textfilter.Verdict
+	}{
+		{
+			name:    "clean",
+			text:    "Good game, well played",
+			verdict: textfilter.Allow,
+		},
+		{
+			name:    "blocked",
+			text:    "Darn it!",
+			verdict: textfilter.Block,
+		},
+		{
+			name:    "leetspeak",
+			text:    "d@rn",
+			verdict: textfilter.Block,
+		},
+		{
+			name:    "repeated letters",
+			text:    "daaaarn",
+			verdict: textfilter.Block,
+		},
+		{
+			name:    "spelled",
+			text:    "d a r n you",
+			verdict: textfilter.Block,
+		},
+		{
+			name:    "part of a word",
+			text:    "darned socks",
+			verdict: textfilter.Allow,
+		},
+		{
+			name:    "flagged",
+			text:    "you n00b",
+			verdict: textfilter.Flag,
+		},
+		{
+			name:    "other locale",
+			text:    "Ну БЛИН",
+			verdict: textfilter.Block,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.verdict, w.Check(tc.text).Verdict)
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "hi there", textfilter.Normalize("H1 THEEERE"))
+	assert.Equal(t, []string{"nice", "game", "nob"}, textfilter.Tokens("N1CE game... n 0 0 b"))
+}
@@ -0,0 +1,431 @@
+// Package tournament implements tournament brackets. Brackets are plain
+// values without IO: the same players and results always produce the
+// same bracket, so it can be stored as JSON and replayed in tests.
+package tournament
+
+import (
+	"errors"
+	"sort"
+)
+
+// Tournament formats
+const (
+	SingleElimination = "single_elimination"
+	DoubleElimination = "double_elimination"
+	RoundRobin        = "round_robin"
+	Swiss             = "swiss"
+)
+
+// Sections of elimination brackets
+const (
+	WinnersSection = "winners"
+	LosersSection  = "losers"
+	FinalSection   = "grand_final"
+)
+
+var (
+	// ErrUnknownFormat error tells us, that the format isn't supported
+	ErrUnknownFormat = errors.New("unknown tournament format")
+	// ErrTooFewPlayers error tells us, that a bracket needs more players
+	ErrTooFewPlayers = errors.New("tournament needs at least two players")
+	// ErrMatchNotFound error tells us, that the bracket has no such match
+	ErrMatchNotFound = errors.New("bracket match not found")
+	// ErrMatchNotReady error tells us, that players of the match aren't
+	// known yet or the match is already played
+	ErrMatchNotReady = errors.New("bracket match isn't ready")
+	// ErrNotInMatch error tells us, that the player doesn't play in the match
+	ErrNotInMatch = errors.New("player doesn't play in the match")
+	// ErrDrawNotAllowed error tells us, that elimination matches need a winner
+	ErrDrawNotAllowed = errors.New("elimination matches can't end in a draw")
+	// ErrNotInBracket error tells us, that the player isn't in the bracket
+	ErrNotInBracket = errors.New("player isn't in the bracket")
+)
+
+// Slot object. Describes one side of a bracket match: either a seeded
+// player or the winner (or the loser) of an earlier match. Zero UserID
+// of a decided slot means nobody, so the other side gets a bye.
+type Slot struct {
+	UserID int  `json:"user_id,omitempty"`
+	From   int  `json:"from,omitempty"`
+	Loser  bool `json:"loser,omitempty"`
+}
+
+// Match object. Describes a single match of the bracket
+type Match struct {
+	ID      int    `json:"id"`
+	Section string `json:"section,omitempty"`
+	Round   int    `json:"round"`
+	A       Slot   `json:"a"`
+	B       Slot   `json:"b"`
+	Winner  int    `json:"winner,omitempty"`
+	Loser   int    `json:"loser,omitempty"`
+	Draw    bool   `json:"draw,omitempty"`
+	Bye     bool   `json:"bye,omitempty"`
+	NoShow  int    `json:"no_show,omitempty"`
+	Done    bool   `json:"done"`
+}
+
+// Ready func. Checks that both players of the match are known and the
+// match isn't played yet
+func (m *Match) Ready() bool {
+	return !m.Done && m.A.UserID != 0 && m.B.UserID != 0
+}
+
+// Bracket object. Players are listed in seed order. Rounds is the
+// number of Swiss rounds, other formats know their rounds upfront.
+type Bracket struct {
+	Format    string   `json:"format"`
+	Players   []int    `json:"players"`
+	Rounds    int      `json:"rounds,omitempty"`
+	Withdrawn []int    `json:"withdrawn,omitempty"`
+	Matches   []*Match `json:"matches"`
+}
+
+// Standing object. Describes results of the player in the bracket.
+// Wins count byes of Swiss tournaments, a draw is worth half a win.
+type Standing struct {
+	UserID int     `json:"user_id"`
+	Wins   int     `json:"wins"`
+	Draws  int     `json:"draws"`
+	Losses int     `json:"losses"`
+	Points float64 `json:"points"`
+}
+
+// New func. Creates the bracket of the format for players listed in
+// seed order. Zero rounds of a Swiss bracket mean as many rounds as
+// it takes to find a single undefeated player.
+func New(format string, players []int, rounds int) (*Bracket, error) {
+	if len(players) < 2 {
+		return nil, ErrTooFewPlayers
+	}
+
+	b := &Bracket{
+		Format:  format,
+		Players: append([]int{}, players...),
+	}
+
+	switch format {
+	case SingleElimination:
+		b.eliminationRounds(false)
+	case DoubleElimination:
+		b.eliminationRounds(true)
+	case RoundRobin:
+		b.roundRobinRounds()
+	case Swiss:
+		b.Rounds = rounds
+		if b.Rounds <= 0 {
+			b.Rounds = log2Ceil(len(players))
+		}
+		if b.Rounds > len(players)-1 {
+			b.Rounds = len(players) - 1
+		}
+		b.pairSwissRound()
+	default:
+		return nil, ErrUnknownFormat
+	}
+
+	b.advance()
+
+	return b, nil
+}
+
+// Match func. Returns the match with the id
+func (b *Bracket) Match(id int) (*Match, error) {
+	if id < 1 || id > len(b.Matches) {
+		return nil, ErrMatchNotFound
+	}
+
+	return b.Matches[id-1], nil
+}
+
+// Report func. Records the winner of the ready match and advances the bracket
+func (b *Bracket) Report(matchID, winnerID int) error {
+	m, err := b.readyMatch(matchID)
+	if err != nil {
+		return err
+	}
+
+	if m.A.UserID != winnerID && m.B.UserID != winnerID {
+		return ErrNotInMatch
+	}
+
+	b.finish(m, winnerID, false)
+	b.advance()
+
+	return nil
+}
+
+// ReportDraw func. Records a draw of the ready match. Only round robin
+// and Swiss matches can end in a draw.
+func (b *Bracket) ReportDraw(matchID int) error {
+	m, err := b.readyMatch(matchID)
+	if err != nil {
+		return err
+	}
+
+	if b.Format != RoundRobin && b.Format != Swiss {
+		return ErrDrawNotAllowed
+	}
+
+	b.finish(m, 0, true)
+	b.advance()
+
+	return nil
+}
+
+// Forfeit func. Records that the player didn't show up for the ready
+// match, so the opponent wins it
+func (b *Bracket) Forfeit(matchID, absentID int) error {
+	m, err := b.readyMatch(matchID)
+	if err != nil {
+		return err
+	}
+
+	if m.A.UserID != absentID && m.B.UserID != absentID {
+		return ErrNotInMatch
+	}
+
+	b.forfeit(m, absentID)
+	b.advance()
+
+	return nil
+}
+
+// Withdraw func. Removes the player from the rest of the tournament.
+// The player forfeits every match that isn't played yet.
+func (b *Bracket) Withdraw(userID int) error {
+	if !contains(b.Players, userID) {
+		return ErrNotInBracket
+	}
+
+	if !contains(b.Withdrawn, userID) {
+		b.Withdrawn = append(b.Withdrawn, userID)
+	}
+	b.advance()
+
+	return nil
+}
+
+// Finished func. Checks that all matches of the bracket are played
+func (b *Bracket) Finished() bool {
+	return b.played() && (b.Format != Swiss || b.round() >= b.Rounds)
+}
+
+// Champion func. Returns the winner of the finished bracket: the winner
+// of the final of elimination brackets or the leader of standings.
+func (b *Bracket) Champion() int {
+	if !b.Finished() {
+		return 0
+	}
+
+	if b.Format == SingleElimination || b.Format == DoubleElimination {
+		return b.Matches[len(b.Matches)-1].Winner
+	}
+
+	return b.Standings()[0].UserID
+}
+
+// Standings func. Returns results of players ordered by points. Ties
+// are broken by seed.
+func (b *Bracket) Standings() []*Standing {
+	standings := make([]*Standing, len(b.Players))
+	byUser := map[int]*Standing{}
+	for i, id := range b.Players {
+		standings[i] = &Standing{UserID: id}
+		byUser[id] = standings[i]
+	}
+
+	for _, m := range b.Matches {
+		if !m.Done || (m.Bye && b.Format != Swiss) {
+			continue
+		}
+		switch {
+		case m.Draw:
+			byUser[m.A.UserID].Draws++
+			byUser[m.B.UserID].Draws++
+		case m.Winner != 0:
+			byUser[m.Winner].Wins++
+			if m.Loser != 0 {
+				byUser[m.Loser].Losses++
+			}
+		}
+	}
+
+	for _, s := range standings {
+		s.Points = float64(s.Wins) + float64(s.Draws)/2
+	}
+	sort.SliceStable(standings, func(i, j int) bool {
+		return standings[i].Points > standings[j].Points
+	})
+
+	return standings
+}
+
+// readyMatch func. Returns the match with the id if it can be played
+func (b *Bracket) readyMatch(id int) (*Match, error) {
+	m, err := b.Match(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.Ready() {
+		return nil, ErrMatchNotReady
+	}
+
+	return m, nil
+}
+
+// finish func. Records result of the match. When the loser bracket
+// champion wins the grand final of a double elimination bracket, both
+// finalists have one loss, so the final is replayed.
+func (b *Bracket) finish(m *Match, winnerID int, draw bool) {
+	m.Done = true
+	m.Draw = draw
+	if draw {
+		return
+	}
+
+	m.Winner = winnerID
+	m.Loser = m.A.UserID
+	if m.Loser == winnerID {
+		m.Loser = m.B.UserID
+	}
+
+	if m.Section == FinalSection && m.Round == 1 && m.Loser != 0 && winnerID == m.B.UserID {
+		b.addMatch(FinalSection, 2, Slot{From: m.ID}, Slot{From: m.ID, Loser: true})
+	}
+}
+
+// forfeit func. Records that the absent player lost the match without
+// playing. If both players are absent, nobody advances.
+func (b *Bracket) forfeit(m *Match, absentID int) {
+	m.NoShow = absentID
+	winnerID := m.A.UserID
+	if winnerID == absentID {
+		winnerID = m.B.UserID
+	}
+
+	if contains(b.Withdrawn, winnerID) {
+		m.Done = true
+		m.Loser = absentID
+		return
+	}
+
+	b.finish(m, winnerID, false)
+}
+
+// advance func. Moves players into matches whose earlier matches are
+// played, gives byes to players without opponents and forfeits matches
+// of withdrawn players. Earlier matches always have smaller ids, so a
+// single pass reaches every match. Swiss brackets pair the next round
+// once the current one is complete.
+func (b *Bracket) advance() {
+	for {
+		for i := 0; i < len(b.Matches); i++ {
+			m := b.Matches[i]
+			if m.Done || !b.decide(&m.A) || !b.decide(&m.B) {
+				continue
+			}
+
+			switch {
+			case m.A.UserID == 0 || m.B.UserID == 0:
+				m.Done = true
+				m.Bye = true
+				m.Winner = m.A.UserID + m.B.UserID
+			case contains(b.Withdrawn, m.A.UserID):
+				b.forfeit(m, m.A.UserID)
+			case contains(b.Withdrawn, m.B.UserID):
+				b.forfeit(m, m.B.UserID)
+			}
+		}
+
+		if b.Format != Swiss || !b.played() || b.round() >= b.Rounds {
+			return
+		}
+		n := len(b.Matches)
+		b.pairSwissRound()
+		// Every player withdrew, so the tournament ends early
+		if len(b.Matches) == n {
+			b.Rounds = b.round()
+			return
+		}
+	}
+}
+
+// decide func. Fills the slot with the player of the earlier match, if
+// it is played. Returns false while the player of the slot is unknown.
+func (b *Bracket) decide(s *Slot) bool {
+	if s.From == 0 {
+		return true
+	}
+
+	from := b.Matches[s.From-1]
+	if !from.Done {
+		return false
+	}
+
+	s.UserID = from.Winner
+	if s.Loser {
+		s.UserID = from.Loser
+	}
+
+	return true
+}
+
+// addMatch func. Appends a match to the bracket and returns it
+func (b *Bracket) addMatch(section string, round int, a, bs Slot) *Match {
+	m := &Match{
+		ID:      len(b.Matches) + 1,
+		Section: section,
+		Round:   round,
+		A:       a,
+		B:       bs,
+	}
+	b.Matches = append(b.Matches, m)
+
+	return m
+}
+
+// played func. Checks that every match created so far is played
+func (b *Bracket) played() bool {
+	for _, m := range b.Matches {
+		if !m.Done {
+			return false
+		}
+	}
+
+	return true
+}
+
+// round func. Returns the last round of the bracket
+func (b *Bracket) round() int {
+	round := 0
+	for _, m := range b.Matches {
+		if m.Round > round {
+			round = m.Round
+		}
+	}
+
+	return round
+}
+
+// contains func. Checks that ids contain the id
+func contains(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// log2Ceil func. Returns the smallest k, so that 2^k >= n
+func log2Ceil(n int) int {
+	k := 0
+	for 1<<uint(k) < n {
+		k++
+	}
+
+	return k
+}
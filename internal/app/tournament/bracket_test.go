@@ -0,0 +1,254 @@
+package tournament_test
+
+import (
+	"testing"
+
+	"github.com/GShamian/tavern-of-games/internal/app/tournament"
+	"github.com/stretchr/testify/assert"
+)
+
+// seeds func. Returns players 1..n, so player ids are their seeds
+func seeds(n int) []int {
+	players := make([]int, n)
+	for i := range players {
+		players[i] = i + 1
+	}
+
+	return players
+}
+
+// play func. Plays every ready match until the bracket is finished.
+// The better seed always wins.
+func play(t *testing.T, b *tournament.Bracket) {
+	t.Helper()
+
+	for !b.Finished() {
+		progress := false
+		for _, m := range b.Matches {
+			if !m.Ready() {
+				continue
+			}
+			winner := m.A.UserID
+			if m.B.UserID < winner {
+				winner = m.B.UserID
+			}
+			assert.NoError(t, b.Report(m.ID, winner))
+			progress = true
+		}
+		if !progress {
+			t.Fatal("bracket is stuck")
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name          string
+		format        string
+		players       int
+		rounds        int
+		expectedErr   error
+		expectedCount int
+		firstRound    [][2]int
+	}{
+		{
+			name:          "single elimination",
+			format:        tournament.SingleElimination,
+			players:       8,
+			expectedCount: 7,
+			firstRound:    [][2]int{{1, 8}, {4, 5}, {2, 7}, {3, 6}},
+		},
+		{
+			name:          "single elimination with byes",
+			format:        tournament.SingleElimination,
+			players:       5,
+			expectedCount: 7,
+			firstRound:    [][2]int{{1, 0}, {4, 5}, {2, 0}, {3, 0}},
+		},
+		{
+			name:          "double elimination",
+			format:        tournament.DoubleElimination,
+			players:       8,
+			expectedCount: 14,
+			firstRound:    [][2]int{{1, 8}, {4, 5}, {2, 7}, {3, 6}},
+		},
+		{
+			name:          "double elimination of two",
+			format:        tournament.DoubleElimination,
+			players:       2,
+			expectedCount: 2,
+			firstRound:    [][2]int{{1, 2}},
+		},
+		{
+			name:          "round robin",
+			format:        tournament.RoundRobin,
+			players:       4,
+			expectedCount: 6,
+			firstRound:    [][2]int{{1, 4}, {2, 3}},
+		},
+		{
+			name:          "round robin with rest",
+			format:        tournament.RoundRobin,
+			players:       5,
+			expectedCount: 10,
+			firstRound:    [][2]int{{2, 5}, {3, 4}},
+		},
+		{
+			name:          "swiss",
+			format:        tournament.Swiss,
+			players:       8,
+			expectedCount: 4,
+			firstRound:    [][2]int{{1, 5}, {2, 6}, {3, 7}, {4, 8}},
+		},
+		{
+			name:          "swiss with bye",
+			format:        tournament.Swiss,
+			players:       5,
+			expectedCount: 3,
+			firstRound:    [][2]int{{5, 0}, {1, 3}, {2, 4}},
+		},
+		{
+			name:        "too few players",
+			format:      tournament.SingleElimination,
+			players:     1,
+			expectedErr: tournament.ErrTooFewPlayers,
+		},
+		{
+			name:        "unknown format",
+			format:      "ladder",
+			players:     4,
+			expectedErr: tournament.ErrUnknownFormat,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := tournament.New(tc.format, seeds(tc.players), tc.rounds)
+			if tc.expectedErr != nil {
+				assert.EqualError(t, err, tc.expectedErr.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, b.Matches, tc.expectedCount)
+			for i, pair := range tc.firstRound {
+				m := b.Matches[i]
+				assert.Equal(t, 1, m.Round)
+				assert.Equal(t, pair, [2]int{m.A.UserID, m.B.UserID})
+			}
+		})
+	}
+}
+
+func TestBracket_Play(t *testing.T) {
+	testCases := []struct {
+		name    string
+		format  string
+		players int
+	}{
+		{name: "single elimination", format: tournament.SingleElimination, players: 8},
+		{name: "single elimination with byes", format: tournament.SingleElimination, players: 6},
+		{name: "double elimination", format: tournament.DoubleElimination, players: 8},
+		{name: "double elimination with byes", format: tournament.DoubleElimination, players: 5},
+		{name: "round robin", format: tournament.RoundRobin, players: 5},
+		{name: "swiss", format: tournament.Swiss, players: 8},
+		{name: "swiss with byes", format: tournament.Swiss, players: 7},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := tournament.New(tc.format, seeds(tc.players), 0)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, b.Champion())
+
+			play(t, b)
+			assert.Equal(t, 1, b.Champion())
+			assert.Equal(t, 1, b.Standings()[0].UserID)
+		})
+	}
+}
+
+func TestBracket_GrandFinalReset(t *testing.T) {
+	b, _ := tournament.New(tournament.DoubleElimination, seeds(2), 0)
+	assert.NoError(t, b.Report(1, 2))
+	assert.NoError(t, b.Report(2, 1))
+	assert.False(t, b.Finished())
+	assert.Len(t, b.Matches, 3)
+
+	reset := b.Matches[2]
+	assert.True(t, reset.Ready())
+	assert.NoError(t, b.Report(reset.ID, 1))
+	assert.Equal(t, 1, b.Champion())
+}
+
+func TestBracket_Report(t *testing.T) {
+	b, _ := tournament.New(tournament.SingleElimination, seeds(4), 0)
+	final := b.Matches[2]
+
+	assert.EqualError(t, b.Report(final.ID, 1), tournament.ErrMatchNotReady.Error())
+	assert.EqualError(t, b.Report(10, 1), tournament.ErrMatchNotFound.Error())
+	assert.EqualError(t, b.Report(1, 2), tournament.ErrNotInMatch.Error())
+	assert.EqualError(t, b.ReportDraw(1), tournament.ErrDrawNotAllowed.Error())
+
+	assert.NoError(t, b.Report(1, 4))
+	assert.EqualError(t, b.Report(1, 1), tournament.ErrMatchNotReady.Error())
+	assert.Equal(t, 4, final.A.UserID)
+	assert.NoError(t, b.Forfeit(2, 2))
+	assert.Equal(t, 3, final.B.UserID)
+	assert.Equal(t, 2, b.Matches[1].NoShow)
+}
+
+func TestBracket_Withdraw(t *testing.T) {
+	b, _ := tournament.New(tournament.RoundRobin, seeds(4), 0)
+	assert.EqualError(t, b.Withdraw(5), tournament.ErrNotInBracket.Error())
+	assert.NoError(t, b.Withdraw(4))
+
+	for _, m := range b.Matches {
+		if m.A.UserID == 4 || m.B.UserID == 4 {
+			assert.True(t, m.Done)
+			assert.Equal(t, 4, m.NoShow)
+		}
+	}
+
+	play(t, b)
+	standings := b.Standings()
+	assert.Equal(t, 4, standings[3].UserID)
+	assert.Equal(t, 3, standings[3].Losses)
+}
+
+func TestBracket_SwissPairing(t *testing.T) {
+	b, _ := tournament.New(tournament.Swiss, seeds(6), 3)
+	assert.NoError(t, b.ReportDraw(1))
+	play(t, b)
+
+	met := map[[2]int]bool{}
+	for _, m := range b.Matches {
+		pair := [2]int{m.A.UserID, m.B.UserID}
+		if pair[0] > pair[1] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		assert.False(t, met[pair], "rematch of %v", pair)
+		met[pair] = true
+	}
+	assert.Len(t, b.Matches, 9)
+}
+
+func TestSeed(t *testing.T) {
+	entrants := []tournament.Entrant{
+		{UserID: 3, Rating: 1500},
+		{UserID: 1, Rating: 1600},
+		{UserID: 2, Rating: 1500},
+	}
+
+	ids, err := tournament.Seed(entrants, tournament.SeedByRating, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, ids)
+
+	first, _ := tournament.Seed(entrants, tournament.SeedRandom, 42)
+	second, _ := tournament.Seed([]tournament.Entrant{entrants[2], entrants[0], entrants[1]}, tournament.SeedRandom, 42)
+	assert.Equal(t, first, second)
+	assert.ElementsMatch(t, []int{1, 2, 3}, first)
+
+	_, err = tournament.Seed(entrants, "alphabetical", 0)
+	assert.EqualError(t, err, tournament.ErrUnknownSeeding.Error())
+}
@@ -0,0 +1,117 @@
+package tournament
+
+// eliminationRounds func. Creates matches of single or double
+// elimination bracket. Bracket size is rounded up to a power of two,
+// missing players are byes of the top seeds.
+func (b *Bracket) eliminationRounds(double bool) {
+	size := 1 << uint(log2Ceil(len(b.Players)))
+	order := seedOrder(size)
+
+	// Winners bracket: seeded first round and winners of earlier rounds
+	rounds := [][]*Match{{}}
+	for i := 0; i < size; i += 2 {
+		rounds[0] = append(rounds[0], b.addMatch(
+			WinnersSection,
+			1,
+			Slot{UserID: b.seeded(order[i])},
+			Slot{UserID: b.seeded(order[i+1])},
+		))
+	}
+	for r := 1; len(rounds[r-1]) > 1; r++ {
+		prev := rounds[r-1]
+		rounds = append(rounds, []*Match{})
+		for i := 0; i < len(prev); i += 2 {
+			rounds[r] = append(rounds[r], b.addMatch(
+				WinnersSection,
+				r+1,
+				Slot{From: prev[i].ID},
+				Slot{From: prev[i+1].ID},
+			))
+		}
+	}
+
+	if !double {
+		return
+	}
+
+	final := rounds[len(rounds)-1][0]
+	champion := Slot{From: final.ID, Loser: true}
+	if len(rounds) > 1 {
+		champion = b.losersRounds(rounds)
+	}
+
+	b.addMatch(FinalSection, 1, Slot{From: final.ID}, champion)
+}
+
+// losersRounds func. Creates matches of the losers bracket of double
+// elimination. The first round pairs losers of the first winners round.
+// Then losers of every next winners round drop in against survivors of
+// the losers bracket, and survivors play each other between the drops.
+// Drops are mirrored every other round to delay rematches. Returns the
+// slot of the losers bracket champion.
+func (b *Bracket) losersRounds(winners [][]*Match) Slot {
+	round := 1
+	survivors := []Slot{}
+	for i := 0; i < len(winners[0]); i += 2 {
+		m := b.addMatch(
+			LosersSection,
+			round,
+			Slot{From: winners[0][i].ID, Loser: true},
+			Slot{From: winners[0][i+1].ID, Loser: true},
+		)
+		survivors = append(survivors, Slot{From: m.ID})
+	}
+
+	for r := 1; r < len(winners); r++ {
+		round++
+		drops := winners[r]
+		next := []Slot{}
+		for i, s := range survivors {
+			j := i
+			if r%2 == 1 {
+				j = len(drops) - 1 - i
+			}
+			m := b.addMatch(LosersSection, round, s, Slot{From: drops[j].ID, Loser: true})
+			next = append(next, Slot{From: m.ID})
+		}
+		survivors = next
+
+		if len(survivors) == 1 {
+			break
+		}
+
+		round++
+		next = []Slot{}
+		for i := 0; i < len(survivors); i += 2 {
+			m := b.addMatch(LosersSection, round, survivors[i], survivors[i+1])
+			next = append(next, Slot{From: m.ID})
+		}
+		survivors = next
+	}
+
+	return survivors[0]
+}
+
+// seeded func. Returns the player with the seed, or zero for a bye
+func (b *Bracket) seeded(seed int) int {
+	if seed > len(b.Players) {
+		return 0
+	}
+
+	return b.Players[seed-1]
+}
+
+// seedOrder func. Returns seeds in bracket positions, so the top seeds
+// can meet only in the latest rounds: 1, 8, 4, 5, 2, 7, 3, 6 for eight.
+func seedOrder(size int) []int {
+	order := []int{1}
+	for n := 2; n <= size; n *= 2 {
+		next := make([]int, 0, n)
+		for _, seed := range order {
+			next = append(next, seed, n+1-seed)
+		}
+		order = next
+	}
+
+	return order
+}
@@ -0,0 +1,27 @@
+package tournament
+
+// roundRobinRounds func. Creates matches of every player against every
+// other one using the circle method: the first player stays in place
+// while the others rotate. With an odd number of players somebody
+// rests every round.
+func (b *Bracket) roundRobinRounds() {
+	circle := append([]int{}, b.Players...)
+	if len(circle)%2 == 1 {
+		circle = append(circle, 0)
+	}
+
+	n := len(circle)
+	for r := 1; r < n; r++ {
+		for i := 0; i < n/2; i++ {
+			a, c := circle[i], circle[n-1-i]
+			if a == 0 || c == 0 {
+				continue
+			}
+			b.addMatch("", r, Slot{UserID: a}, Slot{UserID: c})
+		}
+
+		last := circle[n-1]
+		copy(circle[2:], circle[1:n-1])
+		circle[1] = last
+	}
+}
@@ -0,0 +1,54 @@
+package tournament
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+)
+
+// Seeding methods
+const (
+	SeedByRating = "rating"
+	SeedRandom   = "random"
+)
+
+// ErrUnknownSeeding error tells us, that the seeding method isn't supported
+var ErrUnknownSeeding = errors.New("unknown seeding method")
+
+// Entrant object. Describes a registered player before seeding
+type Entrant struct {
+	UserID int
+	Rating float64
+}
+
+// Seed func. Returns ids of entrants in seed order. Rating seeding puts
+// the highest rated first, ties are broken by user id. Random seeding
+// shuffles entrants with the imported source seed, so the same seed
+// always gives the same order.
+func Seed(entrants []Entrant, method string, seed int64) ([]int, error) {
+	sorted := append([]Entrant{}, entrants...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].UserID < sorted[j].UserID
+	})
+
+	switch method {
+	case SeedByRating:
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return sorted[i].Rating > sorted[j].Rating
+		})
+	case SeedRandom:
+		rnd := rand.New(rand.NewSource(seed))
+		rnd.Shuffle(len(sorted), func(i, j int) {
+			sorted[i], sorted[j] = sorted[j], sorted[i]
+		})
+	default:
+		return nil, ErrUnknownSeeding
+	}
+
+	ids := make([]int, len(sorted))
+	for i, e := range sorted {
+		ids[i] = e.UserID
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,84 @@
+package tournament
+
+// pairSwissRound func. Creates matches of the next Swiss round. Players
+// are ordered by standings and paired with the closest opponent they
+// haven't played yet. With an odd number of players the lowest ranked
+// player without a bye gets one. Withdrawn players aren't paired.
+func (b *Bracket) pairSwissRound() {
+	round := b.round() + 1
+	played := map[[2]int]bool{}
+	byes := map[int]bool{}
+	for _, m := range b.Matches {
+		if m.Bye {
+			byes[m.A.UserID] = true
+			continue
+		}
+		played[[2]int{m.A.UserID, m.B.UserID}] = true
+		played[[2]int{m.B.UserID, m.A.UserID}] = true
+	}
+
+	players := []int{}
+	for _, s := range b.Standings() {
+		if !contains(b.Withdrawn, s.UserID) {
+			players = append(players, s.UserID)
+		}
+	}
+
+	if len(players)%2 == 1 {
+		bye := len(players) - 1
+		for i := len(players) - 1; i >= 0; i-- {
+			if !byes[players[i]] {
+				bye = i
+				break
+			}
+		}
+		m := b.addMatch("", round, Slot{UserID: players[bye]}, Slot{})
+		m.Done = true
+		m.Bye = true
+		m.Winner = players[bye]
+		players = append(players[:bye], players[bye+1:]...)
+	}
+
+	// The first round pairs the top half of seeds with the bottom one
+	if round == 1 {
+		half := len(players) / 2
+		for i := 0; i < half; i++ {
+			b.addMatch("", round, Slot{UserID: players[i]}, Slot{UserID: players[half+i]})
+		}
+		return
+	}
+
+	pairs, ok := pairSwiss(players, played)
+	if !ok {
+		pairs, _ = pairSwiss(players, map[[2]int]bool{})
+	}
+	for i := 0; i < len(pairs); i += 2 {
+		b.addMatch("", round, Slot{UserID: pairs[i]}, Slot{UserID: pairs[i+1]})
+	}
+}
+
+// pairSwiss func. Pairs the first player with the highest ranked
+// opponent they haven't played, backtracking when the rest can't be
+// paired. Returns players in pair order and false if there is no
+// pairing without rematches.
+func pairSwiss(players []int, played map[[2]int]bool) ([]int, bool) {
+	if len(players) == 0 {
+		return []int{}, true
+	}
+
+	first := players[0]
+	for i := 1; i < len(players); i++ {
+		if played[[2]int{first, players[i]}] {
+			continue
+		}
+
+		rest := make([]int, 0, len(players)-2)
+		rest = append(rest, players[1:i]...)
+		rest = append(rest, players[i+1:]...)
+		if pairs, ok := pairSwiss(rest, played); ok {
+			return append([]int{first, players[i]}, pairs...), true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,144 @@
+package trace
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+var (
+	errInvalidTraceparent = errors.New("invalid traceparent header")
+)
+
+// TraceID is the id of a trace, shared by all its spans
+type TraceID [16]byte
+
+// SpanID is the id of a span within its trace
+type SpanID [8]byte
+
+// String func. Returns the id in lowercase hex
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid func. Checks that the id isn't all zeros
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// MarshalText func. Marshals the id in lowercase hex
+func (id TraceID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// String func. Returns the id in lowercase hex
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// IsValid func. Checks that the id isn't all zeros
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// MarshalText func. Marshals the id in lowercase hex, zero ids are empty
+func (id SpanID) MarshalText() ([]byte, error) {
+	if !id.IsValid() {
+		return []byte{}, nil
+	}
+
+	return []byte(id.String()), nil
+}
+
+// SpanContext object. Describes the part of a span which is propagated
+// to other services: ids of the trace and the span and whether the trace
+// is sampled.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid func. Checks that both ids are set
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// Traceparent func. Returns the span context as the W3C Trace Context
+// traceparent header, e.g.
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent func. Parses the W3C Trace Context traceparent header.
+// Headers of future versions are parsed by the fields version 00 has.
+func ParseTraceparent(header string) (SpanContext, error) {
+	sc := SpanContext{}
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 {
+		return sc, errInvalidTraceparent
+	}
+
+	version, err := decodeHex(parts[0], 1)
+	if err != nil || version[0] == 0xff || (version[0] == 0 && len(parts) != 4) {
+		return sc, errInvalidTraceparent
+	}
+
+	traceID, err := decodeHex(parts[1], len(sc.TraceID))
+	if err != nil {
+		return sc, errInvalidTraceparent
+	}
+	spanID, err := decodeHex(parts[2], len(sc.SpanID))
+	if err != nil {
+		return sc, errInvalidTraceparent
+	}
+	flags, err := decodeHex(parts[3], 1)
+	if err != nil {
+		return sc, errInvalidTraceparent
+	}
+
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	sc.Sampled = flags[0]&1 == 1
+	if !sc.IsValid() {
+		return SpanContext{}, errInvalidTraceparent
+	}
+
+	return sc, nil
+}
+
+// decodeHex func. Decodes lowercase hex of exactly n bytes
+func decodeHex(s string, n int) ([]byte, error) {
+	if len(s) != 2*n || strings.ToLower(s) != s {
+		return nil, errInvalidTraceparent
+	}
+
+	return hex.DecodeString(s)
+}
+
+// newTraceID func. Returns a random trace id
+func newTraceID() TraceID {
+	id := TraceID{}
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+
+	return id
+}
+
+// newSpanID func. Returns a random span id
+func newSpanID() SpanID {
+	id := SpanID{}
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+
+	return id
+}
@@ -0,0 +1,81 @@
+package trace
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Exporter interface. Receives ended spans of sampled traces, e.g. to
+// send them to a collector. Export is called by the goroutine ending the
+// span, so it has to be quick and safe for concurrent use.
+type Exporter interface {
+	Export(*SpanData)
+}
+
+// Discard is the exporter that drops spans
+var Discard Exporter = discard{}
+
+// discard object. Exporter that drops spans.
+type discard struct{}
+
+// Export func. Drops the span
+func (discard) Export(*SpanData) {}
+
+// WriterExporter object. Writes spans to the writer as JSON, one span
+// per line, e.g. to stdout.
+type WriterExporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewWriterExporter func. Constructor for WriterExporter
+func NewWriterExporter(w io.Writer) *WriterExporter {
+	return &WriterExporter{
+		enc: json.NewEncoder(w),
+	}
+}
+
+// Export func. Writes the span, failing writes drop it
+func (e *WriterExporter) Export(d *SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.enc.Encode(d)
+}
+
+// MemoryExporter object. Keeps spans in memory in the order they ended,
+// e.g. for tests.
+type MemoryExporter struct {
+	mu    sync.Mutex
+	spans []*SpanData
+}
+
+// NewMemoryExporter func. Constructor for MemoryExporter
+func NewMemoryExporter() *MemoryExporter {
+	return &MemoryExporter{}
+}
+
+// Export func. Keeps the span
+func (e *MemoryExporter) Export(d *SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = append(e.spans, d)
+}
+
+// Spans func. Returns spans kept so far
+func (e *MemoryExporter) Spans() []*SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return append([]*SpanData(nil), e.spans...)
+}
+
+// Reset func. Forgets spans kept so far
+func (e *MemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.spans = nil
+}
@@ -0,0 +1,186 @@
+// Package trace traces requests through the server: spans of
+// middleware, handlers and store calls form a tree per request, which is
+// continued from the W3C Trace Context traceparent header of the caller.
+// Ended spans are handed to an exporter.
+package trace
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type ctxKey int8
+
+const (
+	ctxKeySpan ctxKey = iota
+	ctxKeyRemote
+)
+
+// SpanData object. Describes an ended span the way it's exported.
+type SpanData struct {
+	Name       string                 `json:"name"`
+	TraceID    TraceID                `json:"trace_id"`
+	SpanID     SpanID                 `json:"span_id"`
+	ParentID   SpanID                 `json:"parent_id"`
+	Start      time.Time              `json:"start"`
+	End        time.Time              `json:"end"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// Duration func. Returns how long the span took
+func (d *SpanData) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
+
+// Tracer object. Starts spans and exports them once they end. It is
+// safe for concurrent use.
+type Tracer struct {
+	exporter Exporter
+}
+
+// NewTracer func. Constructor for Tracer. Nil exporter discards spans.
+func NewTracer(exporter Exporter) *Tracer {
+	if exporter == nil {
+		exporter = Discard
+	}
+
+	return &Tracer{
+		exporter: exporter,
+	}
+}
+
+// Start func. Starts span with the name, a child of the span of the
+// context, or of the remote span of the context, or the root of a new
+// trace. Returns copy of the context with the span.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	s := &Span{
+		tracer: t,
+		data: SpanData{
+			Name:   name,
+			SpanID: newSpanID(),
+			Start:  time.Now(),
+		},
+	}
+
+	switch {
+	case SpanFromContext(ctx) != nil:
+		parent := SpanFromContext(ctx).Context()
+		s.data.TraceID, s.data.ParentID, s.sampled = parent.TraceID, parent.SpanID, parent.Sampled
+	case remoteFromContext(ctx).IsValid():
+		remote := remoteFromContext(ctx)
+		s.data.TraceID, s.data.ParentID, s.sampled = remote.TraceID, remote.SpanID, remote.Sampled
+	default:
+		s.data.TraceID, s.sampled = newTraceID(), true
+	}
+
+	return context.WithValue(ctx, ctxKeySpan, s), s
+}
+
+// Start func. Starts span with the name, a child of the span of the
+// context, with the tracer of that span. Without a span in the context
+// nothing is traced: the context is returned as is with a nil span,
+// which is safe to use.
+func Start(ctx context.Context, name string) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+
+	return parent.tracer.Start(ctx, name)
+}
+
+// SpanFromContext func. Returns the span of the context, or nil
+func SpanFromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(ctxKeySpan).(*Span)
+	return s
+}
+
+// ContextWithRemote func. Returns copy of the context with the span
+// context of the caller, spans started from it continue the caller's
+// trace
+func ContextWithRemote(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, ctxKeyRemote, sc)
+}
+
+// remoteFromContext func. Returns the span context of the caller
+func remoteFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(ctxKeyRemote).(SpanContext)
+	return sc
+}
+
+// Span object. Describes an operation in a trace. Methods of a nil span
+// are no-ops, and so are changes of an ended span. It is safe for
+// concurrent use.
+type Span struct {
+	tracer  *Tracer
+	sampled bool
+	mu      sync.Mutex
+	data    SpanData
+	ended   bool
+}
+
+// Context func. Returns the span context to propagate
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+
+	return SpanContext{TraceID: s.data.TraceID, SpanID: s.data.SpanID, Sampled: s.sampled}
+}
+
+// SetAttribute func. Sets the attribute of the span
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ended {
+		return
+	}
+	if s.data.Attributes == nil {
+		s.data.Attributes = make(map[string]interface{})
+	}
+	s.data.Attributes[key] = value
+}
+
+// SetError func. Marks the span failed with the error, nil errors are
+// ignored
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ended {
+		s.data.Error = err.Error()
+	}
+}
+
+// End func. Ends the span and exports it if its trace is sampled. Only
+// the first call counts.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if s.sampled {
+		s.tracer.exporter.Export(&data)
+	}
+}
@@ -0,0 +1,171 @@
+package trace_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/GShamian/tavern-of-games/internal/app/trace"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	testCases := []struct {
+		name    string
+		header  string
+		isValid bool
+		sampled bool
+	}{
+		{
+			name:    "sampled",
+			header:  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			isValid: true,
+			sampled: true,
+		},
+		{
+			name:    "not sampled",
+			header:  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			isValid: true,
+		},
+		{
+			name:    "future version with more fields",
+			header:  "cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-what-the-future-holds",
+			isValid: true,
+			sampled: true,
+		},
+		{
+			name:   "version 00 with more fields",
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+		},
+		{
+			name:   "forbidden version",
+			header: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+		{
+			name:   "zero trace id",
+			header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		},
+		{
+			name:   "zero span id",
+			header: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		},
+		{
+			name:   "uppercase",
+			header: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		},
+		{
+			name:   "short trace id",
+			header: "00-4bf92f3577b34da6-00f067aa0ba902b7-01",
+		},
+		{
+			name:   "garbage",
+			header: "trace me",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sc, err := trace.ParseTraceparent(tc.header)
+			if !tc.isValid {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+			assert.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+			assert.Equal(t, tc.sampled, sc.Sampled)
+		})
+	}
+
+	sc, _ := trace.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.Traceparent())
+}
+
+func TestTracer_Start(t *testing.T) {
+	exporter := trace.NewMemoryExporter()
+	tracer := trace.NewTracer(exporter)
+
+	ctx, root := tracer.Start(context.Background(), "request")
+	childCtx, child := trace.Start(ctx, "handler")
+	_, grandchild := trace.Start(childCtx, "store")
+	grandchild.SetAttribute("table", "users")
+	grandchild.SetError(errors.New("record not found"))
+	grandchild.End()
+	child.SetError(nil)
+	child.End()
+	root.End()
+	root.End()
+	root.SetAttribute("late", true)
+
+	spans := exporter.Spans()
+	if !assert.Len(t, spans, 3) {
+		return
+	}
+	assert.Equal(t, "store", spans[0].Name)
+	assert.Equal(t, "users", spans[0].Attributes["table"])
+	assert.Equal(t, "record not found", spans[0].Error)
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentID)
+	assert.Empty(t, spans[1].Error)
+	assert.Equal(t, spans[2].SpanID, spans[1].ParentID)
+	assert.False(t, spans[2].ParentID.IsValid())
+	assert.Nil(t, spans[2].Attributes)
+	for _, s := range spans {
+		assert.Equal(t, root.Context().TraceID, s.TraceID)
+		assert.True(t, s.Duration() >= 0)
+	}
+
+	// Without a span in the context nothing is traced
+	ctx, span := trace.Start(context.Background(), "orphan")
+	assert.Nil(t, span)
+	assert.Nil(t, trace.SpanFromContext(ctx))
+	span.SetAttribute("key", "value")
+	span.SetError(errors.New("ignored"))
+	span.End()
+	assert.False(t, span.Context().IsValid())
+	assert.Len(t, exporter.Spans(), 3)
+
+	exporter.Reset()
+	assert.Empty(t, exporter.Spans())
+}
+
+func TestTracer_StartRemote(t *testing.T) {
+	exporter := trace.NewMemoryExporter()
+	tracer := trace.NewTracer(exporter)
+
+	remote, _ := trace.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := tracer.Start(trace.ContextWithRemote(context.Background(), remote), "request")
+	span.End()
+	if assert.Len(t, exporter.Spans(), 1) {
+		assert.Equal(t, remote.TraceID, exporter.Spans()[0].TraceID)
+		assert.Equal(t, remote.SpanID, exporter.Spans()[0].ParentID)
+	}
+
+	// Traces the caller didn't sample aren't exported, but propagated
+	remote.Sampled = false
+	_, span = tracer.Start(trace.ContextWithRemote(context.Background(), remote), "request")
+	span.End()
+	assert.Len(t, exporter.Spans(), 1)
+	assert.Equal(t, remote.TraceID, span.Context().TraceID)
+	assert.False(t, span.Context().Sampled)
+}
+
+func TestWriterExporter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	tracer := trace.NewTracer(trace.NewWriterExporter(buf))
+
+	_, span := tracer.Start(context.Background(), "request")
+	span.SetAttribute("http.route", "/games/{id}")
+	span.End()
+
+	data := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &data))
+	assert.Equal(t, "request", data["name"])
+	assert.Equal(t, span.Context().TraceID.String(), data["trace_id"])
+	assert.Equal(t, span.Context().SpanID.String(), data["span_id"])
+	assert.Empty(t, data["parent_id"])
+	assert.Equal(t, "/games/{id}", data["attributes"].(map[string]interface{})["http.route"])
+}
@@ -0,0 +1,4 @@
+CREATE TABLE games (
+    id bigserial not null primary key,
+    title varchar not null
+);
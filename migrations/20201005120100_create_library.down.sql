@@ -0,0 +1,2 @@
+DROP TABLE library_settings;
+DROP TABLE library_entries;
@@ -0,0 +1,14 @@
+CREATE TABLE library_entries (
+    user_id bigint not null references users (id) on delete cascade,
+    game_id bigint not null references games (id) on delete cascade,
+    status varchar not null,
+    hours_played double precision not null default 0,
+    added_at timestamptz not null default now(),
+    notes text not null default '',
+    primary key (user_id, game_id)
+);
+
+CREATE TABLE library_settings (
+    user_id bigint not null primary key references users (id) on delete cascade,
+    visibility varchar not null default 'public'
+);
@@ -0,0 +1,7 @@
+DROP TABLE review_votes;
+DROP TABLE reviews;
+
+ALTER TABLE games
+    DROP COLUMN rating_histogram,
+    DROP COLUMN rating_sum,
+    DROP COLUMN rating_count;
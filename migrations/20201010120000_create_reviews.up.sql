@@ -0,0 +1,24 @@
+ALTER TABLE games
+    ADD COLUMN rating_count integer not null default 0,
+    ADD COLUMN rating_sum integer not null default 0,
+    ADD COLUMN rating_histogram integer[] not null default '{0,0,0,0,0,0,0,0,0,0}';
+
+CREATE TABLE reviews (
+    id bigserial not null primary key,
+    user_id bigint not null references users (id) on delete cascade,
+    game_id bigint not null references games (id) on delete cascade,
+    score integer not null check (score between 1 and 10),
+    text text not null default '',
+    helpful_count integer not null default 0,
+    unhelpful_count integer not null default 0,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    unique (user_id, game_id)
+);
+
+CREATE TABLE review_votes (
+    review_id bigint not null references reviews (id) on delete cascade,
+    user_id bigint not null references users (id) on delete cascade,
+    helpful boolean not null,
+    primary key (review_id, user_id)
+);
@@ -0,0 +1,19 @@
+CREATE TABLE friendships (
+    user_id bigint not null references users (id) on delete cascade,
+    friend_id bigint not null references users (id) on delete cascade,
+    status varchar not null,
+    created_at timestamptz not null default now(),
+    updated_at timestamptz not null default now(),
+    primary key (user_id, friend_id),
+    check (user_id <> friend_id)
+);
+
+CREATE UNIQUE INDEX friendships_pair_idx ON friendships (least(user_id, friend_id), greatest(user_id, friend_id));
+
+CREATE TABLE blocks (
+    blocker_id bigint not null references users (id) on delete cascade,
+    blocked_id bigint not null references users (id) on delete cascade,
+    created_at timestamptz not null default now(),
+    primary key (blocker_id, blocked_id),
+    check (blocker_id <> blocked_id)
+);
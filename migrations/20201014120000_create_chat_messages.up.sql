@@ -0,0 +1,9 @@
+CREATE TABLE chat_messages (
+    id bigserial not null primary key,
+    room varchar not null,
+    user_id bigint not null references users (id) on delete cascade,
+    text text not null,
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX chat_messages_room_idx ON chat_messages (room, id DESC);
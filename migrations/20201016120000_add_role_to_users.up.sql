@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN role varchar not null default 'user';
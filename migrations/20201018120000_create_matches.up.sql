@@ -0,0 +1,29 @@
+CREATE TABLE matches (
+    id bigserial not null primary key,
+    game_id bigint not null references games (id) on delete cascade,
+    lobby_id bigint,
+    side_a bigint[] not null,
+    side_b bigint[] not null,
+    winner varchar not null,
+    status varchar not null,
+    reported_by bigint not null references users (id) on delete cascade,
+    season varchar not null,
+    created_at timestamptz not null default now(),
+    rated_at timestamptz
+);
+
+CREATE INDEX matches_status_idx ON matches (status, created_at);
+
+CREATE TABLE ratings (
+    user_id bigint not null references users (id) on delete cascade,
+    game_id bigint not null references games (id) on delete cascade,
+    season varchar not null,
+    rating double precision not null,
+    rd double precision not null,
+    volatility double precision not null,
+    matches integer not null default 0,
+    updated_at timestamptz not null default now(),
+    primary key (user_id, game_id, season)
+);
+
+CREATE INDEX ratings_leaderboard_idx ON ratings (game_id, season, rating DESC, user_id);
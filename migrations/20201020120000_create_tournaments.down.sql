@@ -0,0 +1,2 @@
+DROP TABLE tournament_participants;
+DROP TABLE tournaments;
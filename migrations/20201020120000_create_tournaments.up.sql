@@ -0,0 +1,24 @@
+CREATE TABLE tournaments (
+    id bigserial not null primary key,
+    game_id bigint not null references games (id) on delete cascade,
+    organizer_id bigint not null references users (id) on delete cascade,
+    name varchar not null,
+    format varchar not null,
+    seeding varchar not null,
+    max_players integer not null,
+    rounds integer not null default 0,
+    registration_opens_at timestamptz not null,
+    registration_closes_at timestamptz not null,
+    status varchar not null,
+    bracket jsonb,
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX tournaments_status_idx ON tournaments (status, registration_closes_at);
+
+CREATE TABLE tournament_participants (
+    tournament_id bigint not null references tournaments (id) on delete cascade,
+    user_id bigint not null references users (id) on delete cascade,
+    registered_at timestamptz not null default now(),
+    primary key (tournament_id, user_id)
+);
@@ -0,0 +1,4 @@
+DROP TABLE achievement_evaluations;
+DROP TABLE achievement_progress;
+DROP TABLE user_events;
+ALTER TABLE users DROP COLUMN created_at;
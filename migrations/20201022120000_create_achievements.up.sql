@@ -0,0 +1,39 @@
+ALTER TABLE users ADD COLUMN created_at timestamptz not null default now();
+
+CREATE TABLE user_events (
+    id bigserial not null primary key,
+    user_id bigint not null references users (id) on delete cascade,
+    type varchar not null,
+    game_id bigint not null default 0,
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX user_events_user_type_idx ON user_events (user_id, type, game_id);
+CREATE INDEX user_events_type_idx ON user_events (type, game_id);
+
+-- Events that happened before achievements existed
+INSERT INTO user_events (user_id, type, game_id, created_at)
+SELECT user_id, 'review_written', game_id, created_at FROM reviews;
+
+INSERT INTO user_events (user_id, type, game_id, created_at)
+SELECT unnest(side_a || side_b), 'match_played', game_id, rated_at FROM matches
+WHERE rated_at IS NOT NULL;
+
+INSERT INTO user_events (user_id, type, game_id, created_at)
+SELECT unnest(CASE winner WHEN 'a' THEN side_a ELSE side_b END), 'match_won', game_id, rated_at
+FROM matches WHERE rated_at IS NOT NULL AND winner <> 'draw';
+
+CREATE TABLE achievement_progress (
+    user_id bigint not null references users (id) on delete cascade,
+    code varchar not null,
+    progress integer not null,
+    target integer not null,
+    unlocked_at timestamptz,
+    primary key (user_id, code)
+);
+
+CREATE TABLE achievement_evaluations (
+    code varchar not null primary key,
+    fingerprint varchar not null,
+    evaluated_at timestamptz not null default now()
+);
@@ -0,0 +1,33 @@
+CREATE TABLE guilds (
+    id bigserial not null primary key,
+    name varchar not null,
+    tag varchar not null,
+    description varchar not null default '',
+    emblem_url varchar not null default '',
+    created_at timestamptz not null default now()
+);
+
+CREATE UNIQUE INDEX guilds_name_idx ON guilds (lower(name));
+CREATE UNIQUE INDEX guilds_tag_idx ON guilds (tag);
+
+CREATE TABLE guild_members (
+    guild_id bigint not null references guilds (id) on delete cascade,
+    user_id bigint not null references users (id) on delete cascade,
+    role varchar not null,
+    joined_at timestamptz not null default now(),
+    primary key (guild_id, user_id)
+);
+
+CREATE UNIQUE INDEX guild_members_user_idx ON guild_members (user_id);
+CREATE UNIQUE INDEX guild_members_leader_idx ON guild_members (guild_id) WHERE role = 'leader';
+
+CREATE TABLE guild_invites (
+    guild_id bigint not null references guilds (id) on delete cascade,
+    user_id bigint not null references users (id) on delete cascade,
+    kind varchar not null,
+    inviter_id bigint references users (id) on delete set null,
+    created_at timestamptz not null default now(),
+    primary key (guild_id, user_id)
+);
+
+CREATE INDEX guild_invites_user_idx ON guild_invites (user_id);
@@ -0,0 +1,2 @@
+DROP TABLE lfg_requests;
+DROP TABLE lfg_posts;
@@ -0,0 +1,26 @@
+CREATE TABLE lfg_posts (
+    id bigserial not null primary key,
+    user_id bigint not null references users (id) on delete cascade,
+    game_id bigint not null references games (id) on delete cascade,
+    platform varchar not null,
+    players integer not null,
+    language varchar not null,
+    skill_min integer not null default 0,
+    skill_max integer not null default 0,
+    voice_chat boolean not null default false,
+    description varchar not null default '',
+    starts_at timestamptz not null,
+    expires_at timestamptz not null,
+    status varchar not null,
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX lfg_posts_open_idx ON lfg_posts (starts_at) WHERE status = 'open';
+
+CREATE TABLE lfg_requests (
+    post_id bigint not null references lfg_posts (id) on delete cascade,
+    user_id bigint not null references users (id) on delete cascade,
+    message varchar not null default '',
+    created_at timestamptz not null default now(),
+    primary key (post_id, user_id)
+);
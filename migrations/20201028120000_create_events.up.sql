@@ -0,0 +1,26 @@
+CREATE TABLE events (
+    id bigserial not null primary key,
+    host_id bigint not null references users (id) on delete cascade,
+    game_id bigint not null references games (id) on delete cascade,
+    title varchar not null,
+    description varchar not null default '',
+    starts_at timestamptz not null,
+    ends_at timestamptz not null,
+    timezone varchar not null,
+    capacity integer not null,
+    recurrence varchar not null default '',
+    repeat_until timestamptz,
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX events_starts_at_idx ON events (starts_at);
+
+CREATE TABLE event_rsvps (
+    event_id bigint not null references events (id) on delete cascade,
+    user_id bigint not null references users (id) on delete cascade,
+    status varchar not null,
+    updated_at timestamptz not null default now(),
+    primary key (event_id, user_id)
+);
+
+CREATE INDEX event_rsvps_user_id_idx ON event_rsvps (user_id);
@@ -0,0 +1,18 @@
+CREATE TABLE notifications (
+    id bigserial not null primary key,
+    user_id bigint not null references users (id) on delete cascade,
+    type varchar not null,
+    text varchar not null,
+    link varchar not null default '',
+    read boolean not null default false,
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id, id DESC);
+
+CREATE TABLE notification_preferences (
+    user_id bigint not null references users (id) on delete cascade,
+    type varchar not null,
+    enabled boolean not null,
+    primary key (user_id, type)
+);
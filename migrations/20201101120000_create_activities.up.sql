@@ -0,0 +1,11 @@
+CREATE TABLE activities (
+    id bigserial not null primary key,
+    user_id bigint not null references users (id) on delete cascade,
+    type varchar not null,
+    game_id bigint references games (id) on delete set null,
+    text varchar not null,
+    link varchar not null default '',
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX activities_user_id_idx ON activities (user_id, id DESC);
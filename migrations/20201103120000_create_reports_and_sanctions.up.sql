@@ -0,0 +1,32 @@
+CREATE TABLE reports (
+    id bigserial not null primary key,
+    reporter_id bigint not null references users (id) on delete cascade,
+    target_type varchar not null,
+    target_id bigint not null,
+    target_user_id bigint not null references users (id) on delete cascade,
+    reason varchar not null,
+    excerpt varchar not null default '',
+    status varchar not null default 'open',
+    action varchar not null default '',
+    resolution varchar not null default '',
+    moderator_id bigint references users (id) on delete set null,
+    resolved_at timestamptz,
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX reports_status_idx ON reports (status, id);
+
+CREATE UNIQUE INDEX reports_open_idx ON reports (reporter_id, target_type, target_id) WHERE status = 'open';
+
+CREATE TABLE sanctions (
+    id bigserial not null primary key,
+    user_id bigint not null references users (id) on delete cascade,
+    kind varchar not null,
+    reason varchar not null,
+    moderator_id bigint references users (id) on delete set null,
+    report_id bigint references reports (id) on delete set null,
+    expires_at timestamptz,
+    created_at timestamptz not null default now()
+);
+
+CREATE INDEX sanctions_user_id_idx ON sanctions (user_id, id DESC);
@@ -0,0 +1,5 @@
+CREATE TABLE calendar_tokens (
+    user_id bigint not null primary key references users (id) on delete cascade,
+    hash varchar not null unique,
+    created_at timestamptz not null default now()
+);
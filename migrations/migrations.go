@@ -0,0 +1,27 @@
+// Package migrations holds golang-migrate style migrations of the DB
+// schema embedded in the binary: postgres ones are in the root, SQLite
+// ones in the sqlite directory.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed *.sql sqlite/*.sql
+var files embed.FS
+
+// Postgres func. Returns migrations of postgres
+func Postgres() fs.FS {
+	return files
+}
+
+// SQLite func. Returns migrations of SQLite
+func SQLite() fs.FS {
+	sub, err := fs.Sub(files, "sqlite")
+	if err != nil {
+		panic(err)
+	}
+
+	return sub
+}
@@ -0,0 +1,28 @@
+DROP TABLE sanctions;
+DROP TABLE reports;
+DROP TABLE activities;
+DROP TABLE notification_preferences;
+DROP TABLE notifications;
+DROP TABLE event_rsvps;
+DROP TABLE events;
+DROP TABLE lfg_requests;
+DROP TABLE lfg_posts;
+DROP TABLE guild_invites;
+DROP TABLE guild_members;
+DROP TABLE guilds;
+DROP TABLE achievement_evaluations;
+DROP TABLE achievement_progress;
+DROP TABLE user_events;
+DROP TABLE tournament_participants;
+DROP TABLE tournaments;
+DROP TABLE ratings;
+DROP TABLE matches;
+DROP TABLE chat_messages;
+DROP TABLE blocks;
+DROP TABLE friendships;
+DROP TABLE review_votes;
+DROP TABLE reviews;
+DROP TABLE library_settings;
+DROP TABLE library_entries;
+DROP TABLE games;
+DROP TABLE users;
@@ -0,0 +1,315 @@
+-- SQLite databases start from the schema Postgres has at this version.
+-- Times are kept as text in UTC, arrays as JSON.
+CREATE TABLE users (
+    id integer not null primary key autoincrement,
+    email text not null unique,
+    encrypted_password text not null,
+    role text not null default 'user',
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE games (
+    id integer not null primary key autoincrement,
+    title text not null,
+    rating_count integer not null default 0,
+    rating_sum integer not null default 0,
+    rating_histogram text not null default '[0,0,0,0,0,0,0,0,0,0]'
+);
+
+CREATE TABLE library_entries (
+    user_id integer not null references users (id) on delete cascade,
+    game_id integer not null references games (id) on delete cascade,
+    status text not null,
+    hours_played real not null default 0,
+    added_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    notes text not null default '',
+    primary key (user_id, game_id)
+);
+
+CREATE TABLE library_settings (
+    user_id integer not null primary key references users (id) on delete cascade,
+    visibility text not null default 'public'
+);
+
+CREATE TABLE reviews (
+    id integer not null primary key autoincrement,
+    user_id integer not null references users (id) on delete cascade,
+    game_id integer not null references games (id) on delete cascade,
+    score integer not null check (score between 1 and 10),
+    text text not null default '',
+    helpful_count integer not null default 0,
+    unhelpful_count integer not null default 0,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    unique (user_id, game_id)
+);
+
+CREATE TABLE review_votes (
+    review_id integer not null references reviews (id) on delete cascade,
+    user_id integer not null references users (id) on delete cascade,
+    helpful boolean not null,
+    primary key (review_id, user_id)
+);
+
+CREATE TABLE friendships (
+    user_id integer not null references users (id) on delete cascade,
+    friend_id integer not null references users (id) on delete cascade,
+    status text not null,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    primary key (user_id, friend_id),
+    check (user_id <> friend_id)
+);
+
+CREATE UNIQUE INDEX friendships_pair_idx ON friendships (min(user_id, friend_id), max(user_id, friend_id));
+
+CREATE TABLE blocks (
+    blocker_id integer not null references users (id) on delete cascade,
+    blocked_id integer not null references users (id) on delete cascade,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    primary key (blocker_id, blocked_id),
+    check (blocker_id <> blocked_id)
+);
+
+CREATE TABLE chat_messages (
+    id integer not null primary key autoincrement,
+    room text not null,
+    user_id integer not null references users (id) on delete cascade,
+    text text not null,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX chat_messages_room_idx ON chat_messages (room, id DESC);
+
+CREATE TABLE matches (
+    id integer not null primary key autoincrement,
+    game_id integer not null references games (id) on delete cascade,
+    lobby_id integer,
+    side_a text not null,
+    side_b text not null,
+    winner text not null,
+    status text not null,
+    reported_by integer not null references users (id) on delete cascade,
+    season text not null,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    rated_at timestamp
+);
+
+CREATE INDEX matches_status_idx ON matches (status, created_at);
+
+CREATE TABLE ratings (
+    user_id integer not null references users (id) on delete cascade,
+    game_id integer not null references games (id) on delete cascade,
+    season text not null,
+    rating real not null,
+    rd real not null,
+    volatility real not null,
+    matches integer not null default 0,
+    updated_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    primary key (user_id, game_id, season)
+);
+
+CREATE INDEX ratings_leaderboard_idx ON ratings (game_id, season, rating DESC, user_id);
+
+CREATE TABLE tournaments (
+    id integer not null primary key autoincrement,
+    game_id integer not null references games (id) on delete cascade,
+    organizer_id integer not null references users (id) on delete cascade,
+    name text not null,
+    format text not null,
+    seeding text not null,
+    max_players integer not null,
+    rounds integer not null default 0,
+    registration_opens_at timestamp not null,
+    registration_closes_at timestamp not null,
+    status text not null,
+    bracket text,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX tournaments_status_idx ON tournaments (status, registration_closes_at);
+
+CREATE TABLE tournament_participants (
+    tournament_id integer not null references tournaments (id) on delete cascade,
+    user_id integer not null references users (id) on delete cascade,
+    registered_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    primary key (tournament_id, user_id)
+);
+
+CREATE TABLE user_events (
+    id integer not null primary key autoincrement,
+    user_id integer not null references users (id) on delete cascade,
+    type text not null,
+    game_id integer not null default 0,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX user_events_user_type_idx ON user_events (user_id, type, game_id);
+CREATE INDEX user_events_type_idx ON user_events (type, game_id);
+
+CREATE TABLE achievement_progress (
+    user_id integer not null references users (id) on delete cascade,
+    code text not null,
+    progress integer not null,
+    target integer not null,
+    unlocked_at timestamp,
+    primary key (user_id, code)
+);
+
+CREATE TABLE achievement_evaluations (
+    code text not null primary key,
+    fingerprint text not null,
+    evaluated_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE TABLE guilds (
+    id integer not null primary key autoincrement,
+    name text not null,
+    tag text not null,
+    description text not null default '',
+    emblem_url text not null default '',
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE UNIQUE INDEX guilds_name_idx ON guilds (lower(name));
+CREATE UNIQUE INDEX guilds_tag_idx ON guilds (tag);
+
+CREATE TABLE guild_members (
+    guild_id integer not null references guilds (id) on delete cascade,
+    user_id integer not null references users (id) on delete cascade,
+    role text not null,
+    joined_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    primary key (guild_id, user_id)
+);
+
+CREATE UNIQUE INDEX guild_members_user_idx ON guild_members (user_id);
+CREATE UNIQUE INDEX guild_members_leader_idx ON guild_members (guild_id) WHERE role = 'leader';
+
+CREATE TABLE guild_invites (
+    guild_id integer not null references guilds (id) on delete cascade,
+    user_id integer not null references users (id) on delete cascade,
+    kind text not null,
+    inviter_id integer references users (id) on delete set null,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    primary key (guild_id, user_id)
+);
+
+CREATE INDEX guild_invites_user_idx ON guild_invites (user_id);
+
+CREATE TABLE lfg_posts (
+    id integer not null primary key autoincrement,
+    user_id integer not null references users (id) on delete cascade,
+    game_id integer not null references games (id) on delete cascade,
+    platform text not null,
+    players integer not null,
+    language text not null,
+    skill_min integer not null default 0,
+    skill_max integer not null default 0,
+    voice_chat boolean not null default false,
+    description text not null default '',
+    starts_at timestamp not null,
+    expires_at timestamp not null,
+    status text not null,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX lfg_posts_open_idx ON lfg_posts (starts_at) WHERE status = 'open';
+
+CREATE TABLE lfg_requests (
+    post_id integer not null references lfg_posts (id) on delete cascade,
+    user_id integer not null references users (id) on delete cascade,
+    message text not null default '',
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    primary key (post_id, user_id)
+);
+
+CREATE TABLE events (
+    id integer not null primary key autoincrement,
+    host_id integer not null references users (id) on delete cascade,
+    game_id integer not null references games (id) on delete cascade,
+    title text not null,
+    description text not null default '',
+    starts_at timestamp not null,
+    ends_at timestamp not null,
+    timezone text not null,
+    capacity integer not null,
+    recurrence text not null default '',
+    repeat_until timestamp,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX events_starts_at_idx ON events (starts_at);
+
+CREATE TABLE event_rsvps (
+    event_id integer not null references events (id) on delete cascade,
+    user_id integer not null references users (id) on delete cascade,
+    status text not null,
+    updated_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    primary key (event_id, user_id)
+);
+
+CREATE INDEX event_rsvps_user_id_idx ON event_rsvps (user_id);
+
+CREATE TABLE notifications (
+    id integer not null primary key autoincrement,
+    user_id integer not null references users (id) on delete cascade,
+    type text not null,
+    text text not null,
+    link text not null default '',
+    read boolean not null default false,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX notifications_user_id_idx ON notifications (user_id, id DESC);
+
+CREATE TABLE notification_preferences (
+    user_id integer not null references users (id) on delete cascade,
+    type text not null,
+    enabled boolean not null,
+    primary key (user_id, type)
+);
+
+CREATE TABLE activities (
+    id integer not null primary key autoincrement,
+    user_id integer not null references users (id) on delete cascade,
+    type text not null,
+    game_id integer references games (id) on delete set null,
+    text text not null,
+    link text not null default '',
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX activities_user_id_idx ON activities (user_id, id DESC);
+
+CREATE TABLE reports (
+    id integer not null primary key autoincrement,
+    reporter_id integer references users (id) on delete cascade,
+    target_type text not null,
+    target_id integer not null,
+    target_user_id integer not null references users (id) on delete cascade,
+    reason text not null,
+    excerpt text not null default '',
+    status text not null default 'open',
+    action text not null default '',
+    resolution text not null default '',
+    moderator_id integer references users (id) on delete set null,
+    resolved_at timestamp,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX reports_status_idx ON reports (status, id);
+
+CREATE UNIQUE INDEX reports_open_idx ON reports (reporter_id, target_type, target_id) WHERE status = 'open';
+
+CREATE TABLE sanctions (
+    id integer not null primary key autoincrement,
+    user_id integer not null references users (id) on delete cascade,
+    kind text not null,
+    reason text not null,
+    moderator_id integer references users (id) on delete set null,
+    report_id integer references reports (id) on delete set null,
+    expires_at timestamp,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE INDEX sanctions_user_id_idx ON sanctions (user_id, id DESC);
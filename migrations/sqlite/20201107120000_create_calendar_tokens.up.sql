@@ -0,0 +1,5 @@
+CREATE TABLE calendar_tokens (
+    user_id integer not null primary key references users (id) on delete cascade,
+    hash text not null unique,
+    created_at timestamp not null default (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);